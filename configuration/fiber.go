@@ -11,5 +11,7 @@ func NewFiberConfiguration() fiber.Config {
 		AppName:     ")϶ lama-backend ϵ(",
 		JSONEncoder: json.Marshal,
 		JSONDecoder: json.Unmarshal,
+		// เอกสารแนบได้ถึง 10 MB เผื่อ multipart overhead
+		BodyLimit: 12 * 1024 * 1024,
 	}
 }
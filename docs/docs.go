@@ -15,46 +15,40 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/users": {
+        "/admin/mfa/roles": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Admin-only endpoint that returns paginated users with an optional role filter.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
-                ],
-                "summary": "list users",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Filter by role (admin, owner, caretaker, doctor)",
-                        "name": "role",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number for pagination",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of users per page",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                    "admin"
                 ],
+                "summary": "list roles that require 2FA",
                 "responses": {
                     "200": {
                         "description": "Request successful",
                         "schema": {
-                            "$ref": "#/definitions/entities.ResponseModel"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/entities.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.MfaRequirementModel"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
@@ -78,43 +72,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/{userID}": {
-            "get": {
+        "/admin/mfa/roles/{role}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "admin fetches user detail by user ID",
+                "description": "Every login as the role must pass 2FA, accounts without it set it up at their next login. Open sessions of the role that did not pass 2FA are closed. Requiring it for admin needs 2FA on the acting admin's account first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "find user by id (admin)",
+                "summary": "require 2FA for a role",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "userID",
+                        "description": "admin, doctor or caretaker",
+                        "name": "role",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Request successful",
+                        "description": "Request successful, data has the requirement and the number of closed sessions",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
-                    "400": {
-                        "description": "Invalid user ID",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
-                        }
-                    },
                     "401": {
                         "description": "Unauthorization Token.",
                         "schema": {
@@ -127,8 +115,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "409": {
+                        "description": "Turn on 2FA for your own account first",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -141,19 +135,19 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "admin delete user by specifying user ID",
+                "description": "Accounts that already have 2FA keep it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "delete user by admin",
+                "summary": "stop requiring 2FA for a role",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "userID",
+                        "description": "admin, doctor or caretaker",
+                        "name": "role",
                         "in": "path",
                         "required": true
                     }
@@ -161,12 +155,6 @@ const docTemplate = `{
                 "responses": {
                     "200": {
                         "description": "Request successful",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseModel"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid user ID",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -183,60 +171,50 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
+                    "404": {
+                        "description": "2FA is not required for the role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/admin/owners/{ownerID}/pets": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "admin update any user by specifying user ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "admin can fetch all pets of an owner",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "pet"
                 ],
-                "summary": "update user by admin",
+                "summary": "get all pets for specified owner",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "userID",
+                        "description": "owner id",
+                        "name": "ownerID",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "update user data",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entities.UpdateUserModel"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "Request successful",
                         "schema": {
-                            "$ref": "#/definitions/entities.UserDataModel"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid request",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
+                            "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "401": {
@@ -252,22 +230,20 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     }
                 }
-            }
-        },
-        "/auth/admin": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "create new admin user",
+                "description": "owner creates a pet under their own ID with POST /pets. Admin creates a pet for a specific owner with POST /admin/owners/{ownerID}/pets.",
                 "consumes": [
                     "application/json"
                 ],
@@ -275,29 +251,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "pet"
                 ],
-                "summary": "create admin",
+                "summary": "create pet",
                 "parameters": [
                     {
-                        "description": "Admin user data",
+                        "type": "string",
+                        "description": "owner id (admin only)",
+                        "name": "ownerID",
+                        "in": "path"
+                    },
+                    {
+                        "description": "pet payload",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entities.CreatedUserModel"
+                            "$ref": "#/definitions/entities.CreatedPetModel"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
+                    "201": {
                         "description": "Request successful",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "400": {
-                        "description": "Invalid json body",
+                        "description": "Invalid json body or missing ownerID for admin",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -323,36 +311,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/login/{role}": {
-            "post": {
-                "security": [],
-                "description": "Login user",
-                "consumes": [
-                    "application/json"
+        "/admin/reassignments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Bookings moved away from unavailable or deleted staff. status defaults to needs_action, the bookings still waiting for an admin. (admin only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "reassignment"
                 ],
-                "summary": "Login",
+                "summary": "Get reassignments",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Role of the user",
-                        "name": "role",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "email and password",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entities.LoginUserRequestModel"
-                        }
+                        "description": "reassigned, needs_action, resolved or dismissed",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -363,13 +342,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid json body",
+                        "description": "Invalid status",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
                     "401": {
-                        "description": "Cannot login user: invalid password or email",
+                        "description": "Unauthorization Token.",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -380,12 +359,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "422": {
-                        "description": "Validation error",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
-                        }
-                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -395,14 +368,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/password": {
+        "/admin/reassignments/{reassignmentID}": {
             "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "reset password with token from email",
+                "description": "Close a needs_action reassignment. With staff_id the booking moves to that staff (they must be free and of the right type), without it the reassignment is dismissed, e.g. after cancelling the booking. (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -410,24 +383,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "reassignment"
                 ],
-                "summary": "reset password",
+                "summary": "Resolve reassignment",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "token from email",
-                        "name": "token",
-                        "in": "query",
+                        "description": "Reassignment ID",
+                        "name": "reassignmentID",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "description": "user new password",
+                        "description": "new staff",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entities.PasswordModel"
+                            "$ref": "#/definitions/entities.ResolveReassignmentRequest"
                         }
                     }
                 ],
@@ -435,11 +408,11 @@ const docTemplate = `{
                     "200": {
                         "description": "Request successful",
                         "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
+                            "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "400": {
-                        "description": "Invalid json body",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -450,55 +423,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "422": {
-                        "description": "Validation error",
+                    "403": {
+                        "description": "Invalid role",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/password/email": {
-            "post": {
-                "security": [],
-                "description": "forgot password and send reset link to email",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "forgot password",
-                "parameters": [
-                    {
-                        "description": "user email and role",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entities.SendEmailModel"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Request successful",
+                    "404": {
+                        "description": "Reassignment or staff not found",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "400": {
-                        "description": "Invalid json body",
+                    "409": {
+                        "description": "Already closed, staff busy or booking no longer waiting",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -518,10 +456,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/register/{role}": {
+        "/admin/staff/{staffID}/reassign": {
             "post": {
-                "security": [],
-                "description": "Register new user except admin",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move the waiting bookings of a caretaker or doctor that start between start_date and end_date (inclusive, no end_date = all upcoming) to other staff, e.g. before their leave. mode auto (default) picks the best rated staff that is free at the same time and flags the bookings nobody can take; mode flag leaves every booking to an admin (GET /admin/reassignments). The owner's price is not changed. (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -529,36 +471,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "reassignment"
                 ],
-                "summary": "Register",
+                "summary": "Reassign staff bookings",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Role of the user except admin",
-                        "name": "role",
+                        "description": "StaffID",
+                        "name": "staffID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "User data",
+                        "description": "date range and mode",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entities.CreatedUserModel"
+                            "$ref": "#/definitions/entities.ReassignBookingsRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Request successful",
+                        "description": "What happened to each booking",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "400": {
-                        "description": "Invalid json body",
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -584,36 +532,4396 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/token": {
+        "/admin/users": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Validates the JWT token passed in the Authorization header and decodes it.",
+                "description": "Admin-only endpoint that returns paginated users with an optional role filter.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "user"
                 ],
-                "summary": "Check JWT token validity",
-                "responses": {
-                    "200": {
-                        "description": "Token is valid",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseModel"
+                "summary": "list users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by role (admin, owner, caretaker, doctor)",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of users per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{userID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "admin fetches user detail by user ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "find user by id (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "admin delete user by specifying user ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "delete user by admin",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "admin update any user by specifying user ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "update user by admin",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update user data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.UpdateUserModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.UserDataModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{userID}/mfa": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "For a user who lost both the authenticator app and the recovery codes. If a role of the user requires 2FA they set it up again at the next login.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "reset 2FA of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{userID}/roles/{role}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "admin adds a role to an existing account, admin included.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "grant role to a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Role to grant (admin, owner, caretaker, doctor)",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "license_number is required for doctor",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.AddRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Account already has this role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/vaccinations/due": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Current doses of every pet due within the next days (overdue ones included), soonest first, with the owner to contact. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vaccination"
+                ],
+                "summary": "Get vaccinations coming due",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "look ahead in days (default 30, max 365)",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid days",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/admin": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create new admin user. To make an existing account an admin use POST /admin/users/{userID}/roles/admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "create admin",
+                "parameters": [
+                    {
+                        "description": "Admin user data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.CreatedUserModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Email is already registered",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "security": [],
+                "description": "Login user. Without a role the account logs in with the role it registered with, use POST /auth/switch-role/{role} to act as its other roles. When the account has 2FA or the role requires it the token has purpose mfa_pending and mfa_enrolled, finish with POST /auth/mfa/verify (or /auth/mfa/setup and /auth/mfa/enable when not enrolled yet).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login",
+                "parameters": [
+                    {
+                        "description": "email and password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.LoginUserRequestModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Cannot login user: invalid password or email",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role or the account does not have it",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/{role}": {
+            "post": {
+                "security": [],
+                "description": "Login user. Without a role the account logs in with the role it registered with, use POST /auth/switch-role/{role} to act as its other roles. When the account has 2FA or the role requires it the token has purpose mfa_pending and mfa_enrolled, finish with POST /auth/mfa/verify (or /auth/mfa/setup and /auth/mfa/enable when not enrolled yet).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role to log in as, must be one of the account's roles",
+                        "name": "role",
+                        "in": "path"
+                    },
+                    {
+                        "description": "email and password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.LoginUserRequestModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Cannot login user: invalid password or email",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role or the account does not have it",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Close the session of this access token, its refresh token stops working too.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout/all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Close every session of the user on every device, this one included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout all sessions",
+                "responses": {
+                    "200": {
+                        "description": "Request successful, data is the number of closed sessions",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Whether 2FA is on for the account, whether one of its roles requires it and how many recovery codes are left.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "2FA status",
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/entities.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entities.MfaStatusModel"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turn 2FA off, not allowed while one of the account's roles requires it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Disable 2FA",
+                "parameters": [
+                    {
+                        "description": "code from the authenticator app or a recovery code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.MfaCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token or wrong code",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "2FA is required for a role of the account",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many wrong codes",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Confirm the secret from POST /auth/mfa/setup with a code from the app. Returns the recovery codes, they are shown only this once. With the mfa_pending token from login the login finishes and the token is returned too.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Enable 2FA",
+                "parameters": [
+                    {
+                        "description": "code from the authenticator app",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.MfaCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful, data has recovery_codes and token",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token or wrong code",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA is already enabled or setup was not started",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many wrong codes",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the recovery codes, the old ones stop working.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "New recovery codes",
+                "parameters": [
+                    {
+                        "description": "code from the authenticator app or a recovery code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.MfaCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful, data is the new recovery codes",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token or wrong code",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many wrong codes",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/setup": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make a new TOTP secret, show otpauth_uri as a QR code for the authenticator app. 2FA is not on until POST /auth/mfa/enable. Takes an access token, or the mfa_pending token from login when the role requires 2FA and the account has none yet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Set up 2FA",
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/entities.ResponseModel"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entities.MfaSetupModel"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "503": {
+                        "description": "2FA is not configured on this server",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Finish a login with the mfa_pending token and a code from the app or a recovery code. Each code works once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Verify 2FA",
+                "parameters": [
+                    {
+                        "description": "code from the authenticator app or a recovery code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.MfaCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token or wrong code",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many wrong codes",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password": {
+            "patch": {
+                "security": [],
+                "description": "reset password with token from email. The token works once and stops working if the password was changed after it was sent. Every session of the user is logged out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "reset password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "token from email",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "user new password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.PasswordModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Reset link is invalid, expired or already used",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/email": {
+            "post": {
+                "security": [],
+                "description": "send a reset link to the email if it has an account. The password is shared by every role of the account. The answer is the same whether the account exists or not. Limited to 3 requests per email and 10 per IP an hour.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "forgot password",
+                "parameters": [
+                    {
+                        "description": "user email",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.SendEmailModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "security": [],
+                "description": "Trade a refresh token for a new access token. The refresh token rotates, the old one stops working. Reusing an old refresh token closes the session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh token",
+                "parameters": [
+                    {
+                        "description": "refresh token from login or the last refresh",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Refresh token is invalid, expired or reused",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register/{role}": {
+            "post": {
+                "security": [],
+                "description": "Register new user except admin. An email can only register once, to get another role on the same account log in and use POST /user/roles/{role}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role of the user except admin",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.CreatedUserModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Email is already registered",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/switch-role/{role}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a new session acting as another role of the same account. The current access and refresh tokens stop working.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Switch role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role to switch to",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role, the account does not have it or the role requires 2FA and this session did not pass it",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/token": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Validates the JWT token passed in the Authorization header and decodes it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Check JWT token validity",
+                "responses": {
+                    "200": {
+                        "description": "Token is valid",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaveday": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Leave requests of the staff in the token. from/to (YYYY-MM-DD) keep requests with a day in the range.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Leaveday"
+                ],
+                "summary": "Get my leave",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, approved, rejected or cancelled",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "from date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "to date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Request leave for every day from start_date to end_date (YYYY-MM-DD, inclusive, at most 60 days). The request is approved right away unless LEAVE_REQUIRES_APPROVAL is set, then it stays pending until an admin reviews it. Days with bookings must be reassigned or cancelled first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Leaveday"
+                ],
+                "summary": "Request leave",
+                "parameters": [
+                    {
+                        "description": "leave range",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.CreateLeaveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "request successfully",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Staff has bookings or leave on the days",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaveday/day/{day}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pending and approved leave of every staff on the day (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Leaveday"
+                ],
+                "summary": "Get leave on a day",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "day (YYYY-MM-DD)",
+                        "name": "day",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaveday/pending": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Leave requests waiting for approval (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Leaveday"
+                ],
+                "summary": "Get pending leave",
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaveday/requests/{requestID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel a pending or approved leave request. Staff can cancel their own leave before its first day, admins any active leave.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Leaveday"
+                ],
+                "summary": "Cancel leave",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "leave request ID",
+                        "name": "requestID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Leave request not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Leave can no longer be cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaveday/requests/{requestID}/approve": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Approve a pending leave request (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Leaveday"
+                ],
+                "summary": "Approve leave",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "leave request ID",
+                        "name": "requestID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Leave request not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Leave is not pending",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaveday/requests/{requestID}/reject": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reject a pending leave request (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Leaveday"
+                ],
+                "summary": "Reject leave",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "leave request ID",
+                        "name": "requestID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Leave request not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Leave is not pending",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaveday/{day}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create Staff leaveday by token and day params (format: YYYY-MM-DD). Same as POST /leaveday with start_date = end_date.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Leaveday"
+                ],
+                "summary": "Create Leaveday",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "leaveday",
+                        "name": "day",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "request successfully",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Staff has bookings or leave on the day",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all payments. Admins can see all payments, while owners can only see their own. Can be filtered by month and year. If only the 'year' query is provided, 'month' will default to 1 (January). Supports pagination.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "Get payments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter payments by month (1-12)",
+                        "name": "month",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter payments by year (e.g. 2025)",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number for pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved payments",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/{paymentID}": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update the status of a payment by its ID. Only admins are authorized to perform this action can update only status type and paydate.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "Update payment status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "paymentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "payment update data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.UpdatePaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated payment status",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment not found.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pets": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "owner creates a pet under their own ID with POST /pets. Admin creates a pet for a specific owner with POST /admin/owners/{ownerID}/pets.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pet"
+                ],
+                "summary": "create pet",
+                "parameters": [
+                    {
+                        "description": "pet payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.CreatedPetModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body or missing ownerID for admin",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pets/owner": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "owner can get their own pets. This endpoint is owner-only and the owner ID is",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pet"
+                ],
+                "summary": "get owner's pets",
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid owner ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pets/{petID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "owner or admin can delete a pet. If role is owner, the pet must belong to the owner.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pet"
+                ],
+                "summary": "delete pet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pet id",
+                        "name": "petID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pet ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role or not owner's pet",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "pet not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "owner or admin can update a pet. If role is owner, the pet must belong to the owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pet"
+                ],
+                "summary": "update pet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pet id",
+                        "name": "petID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "pet payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.UpdatePetModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pet ID or no fields to update",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role or not owner's pet",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "pet not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pets/{petID}/attachments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Files of the pet newest first, documents attached to its services included. Each has a signed URL valid until url_expires_at, list again for fresh ones. Owners see their own pets, doctors the pets they have treated, admins any pet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachment"
+                ],
+                "summary": "Get pet photos and documents",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pet ID",
+                        "name": "petID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "photo or document",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for this pet",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Pet not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a photo to the gallery of a pet (owner, admin) or a document such as a lab result to its files (owner, a doctor who has treated the pet, admin). Photos: jpeg, png or webp up to 5 MB. Documents: pdf, jpeg or png up to 10 MB. The type is read from the file content. The response carries a signed URL that expires.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachment"
+                ],
+                "summary": "Upload pet photo or document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pet ID",
+                        "name": "petID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "photo or document",
+                        "name": "kind",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed for this pet",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Pet not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "415": {
+                        "description": "File type not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pets/{petID}/attachments/{attachmentID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Only who uploaded the file, or an admin, can delete it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachment"
+                ],
+                "summary": "Delete pet photo or document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pet ID",
+                        "name": "petID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "file deleted",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the uploader",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pets/{petID}/medical-records": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Every visit record of the pet, newest first. Owners see their own pets, doctors the pets they have treated, admins any pet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "medical record"
+                ],
+                "summary": "Get medical records of a pet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pet ID",
+                        "name": "petID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pet ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Pet not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pets/{petID}/timeline": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "History of a pet in chronological order: cservice and mservice bookings (finished and upcoming), diagnoses, medicines, reviews, weight changes and vaccinations. Owners can read their own pets, doctors the pets they have treated, admins any pet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pet"
+                ],
+                "summary": "get pet timeline",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pet id",
+                        "name": "petID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cservice",
+                            "mservice",
+                            "diagnosis",
+                            "medicine",
+                            "review",
+                            "weight",
+                            "vaccination"
+                        ],
+                        "type": "string",
+                        "description": "comma separated event types",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pet ID or type",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role or not allowed to read this pet",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "pet not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pets/{petID}/vaccinations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Every vaccine and preventive care dose of the pet, latest first. Superseded doses were replaced by a later dose of the same vaccine. Owners see their own pets, doctors the pets they have treated, admins any pet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vaccination"
+                ],
+                "summary": "Get pet vaccinations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pet ID",
+                        "name": "petID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pet ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Pet not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a vaccine or preventive care dose (deworming, flea/tick) to a pet. Doctors record doses they gave, admins can back-fill one with doctor_id. With service_id the dose comes from that mservice and its doctor. The owner gets an email reminder before next_due_date. Earlier doses of the same vaccine are no longer reminded.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vaccination"
+                ],
+                "summary": "Record vaccination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pet ID",
+                        "name": "petID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "dose",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.CreateVaccinationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role or not the assigned doctor",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Pet or service not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Service is not an mservice",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pets/{petID}/weights": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Weight measurements of a pet over time, from profile updates and from vitals recorded by doctors at visits. With bucket the measurements of each day/week/month are averaged into one point (with min, max and count). Owners can read their own pets, doctors the pets they have treated, admins any pet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pet"
+                ],
+                "summary": "get pet weight history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pet id",
+                        "name": "petID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "first day (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "last day, inclusive (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "day",
+                            "week",
+                            "month"
+                        ],
+                        "type": "string",
+                        "description": "downsample",
+                        "name": "bucket",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pet ID, date or bucket",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role or not owner's pet",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "pet not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin lists every rate card, staff rate, pet surcharge, time multiplier and holiday used to price bookings.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Get pricing rules",
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/holidays/{date}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin marks a date (format: YYYY-MM-DD) as a holiday. Holidays are priced with weekend multipliers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Set holiday",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Holiday date",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "holiday name",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.HolidayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body or date",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin removes a holiday (format: YYYY-MM-DD).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Delete holiday",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Holiday date",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Holiday not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/multipliers": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin adds a price multiplier for hours in [start_hour, end_hour) Thai time. day_type weekend also covers holidays. Only the part above the base rate is charged as an extra line item.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Create time multiplier",
+                "parameters": [
+                    {
+                        "description": "multiplier rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.PriceMultiplierRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/multipliers/{multiplierID}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin replaces a time multiplier rule.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Update time multiplier",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Multiplier ID",
+                        "name": "multiplierID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "multiplier rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.PriceMultiplierRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Multiplier not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin removes a time multiplier rule.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Delete time multiplier",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Multiplier ID",
+                        "name": "multiplierID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Multiplier not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/pet-surcharges": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin adds an hourly surcharge for pets matching a kind and/or weight band [min_weight, max_weight). Leave a field empty to match any value.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Create pet surcharge",
+                "parameters": [
+                    {
+                        "description": "surcharge rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.PetSurchargeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/pet-surcharges/{surchargeID}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin replaces a pet surcharge rule. Optional fields that are left out are cleared.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Update pet surcharge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pet surcharge ID",
+                        "name": "surchargeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "surcharge rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.PetSurchargeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Pet surcharge not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin removes a pet surcharge rule.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Delete pet surcharge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pet surcharge ID",
+                        "name": "surchargeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Pet surcharge not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/rate-cards/{serviceType}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin sets the hourly rate (THB) for a service type. Creates the rate card if it doesn't exist.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Set rate card",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service type (mservice or cservice)",
+                        "name": "serviceType",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "hourly rate",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.RateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin removes the rate card of a service type. Bookings of that type fall back to the default rate.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Delete rate card",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service type (mservice or cservice)",
+                        "name": "serviceType",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Rate card not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing/staff-rates/{staffID}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin sets an hourly rate (THB) for one caretaker or doctor. It overrides the rate card of the service type.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Set staff rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Staff ID",
+                        "name": "staffID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "hourly rate",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.RateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body or staff ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin removes a staff rate override.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing"
+                ],
+                "summary": "Delete staff rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Staff ID",
+                        "name": "staffID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid staff ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Staff rate not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/{staffID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Weekly working hours of a caretaker or doctor (weekday 0 = Sunday). source is weekly when a schedule was set, profile when start/end_working_time is used for every day, default for 08:00-17:00. end_time 00:00 means midnight. One-off changes are listed by GET /schedule/{staffID}/exceptions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Get staff working schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "StaffID",
+                        "name": "staffID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid staff ID",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the weekly working hours of a caretaker or doctor. Several shifts on the same weekday are allowed (split shifts) but must not overlap; end_time 00:00 means midnight. An empty list goes back to start/end_working_time of the profile. Staff can only set their own schedule, admins anyone's.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Set staff working schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "StaffID",
+                        "name": "staffID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "weekly shifts",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.UpdateStaffScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or overlapping shifts",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Staff not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/{staffID}/exceptions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Extra shifts and unavailable periods of a staff that overlap startDate..endDate (at most 92 days). Staff see their own, admins anyone's.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "List schedule exceptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "StaffID",
+                        "name": "staffID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "first day (format: YYYY-MM-DD)",
+                        "name": "startDate",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "last day (format: YYYY-MM-DD)",
+                        "name": "endDate",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a one-off extra shift (kind extra) or a partial-day unavailable period (kind unavailable). Extra shifts can be booked like normal working hours, also on a day off. Unavailable periods block new bookings and checkouts like a leave day does, but only for the given time. Existing bookings are not touched. Staff add their own, admins anyone's.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Add schedule exception",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "StaffID",
+                        "name": "staffID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "exception",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.CreateScheduleExceptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Staff not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/{staffID}/exceptions/{exceptionID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove an extra shift or unavailable period. Staff remove their own, admins anyone's.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Delete schedule exception",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "StaffID",
+                        "name": "staffID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exception ID",
+                        "name": "exceptionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Exception not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/services": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all services for the authenticated user. Admins can see all services. Can be filtered by status, month, and year.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service"
+                ],
+                "summary": "Get services",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter services by status (e.g. all, wait, ongoing, finish)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter services by month (1-12)",
+                        "name": "month",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter services by year (e.g. 2025)",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Owners create their own bookings; admins may create on behalf of an owner by providing owner_id. Use service_type=cservice (caretaker) or mservice (doctor) and supply staff_id plus type-specific fields. this route then create payment (priced from rate cards, staff rates, pet surcharges and time multipliers) and send its line items to stripe to get payment link.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service"
+                ],
+                "summary": "Get stripe payment link to Create caretaker/medical service",
+                "parameters": [
+                    {
+                        "description": "service payload (admins must include owner_id; mservice requires disease)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.CreateServiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorization Token.",
+                    "409": {
+                        "description": "Staff is not available in this time range (booked, on leave or outside working hours)",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "404": {
-                        "description": "User not found.",
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -621,39 +4929,73 @@ const docTemplate = `{
                 }
             }
         },
-        "/leaveday/{day}": {
-            "post": {
+        "/services/quote": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create Staff leaveday by token and day params (format: YYYY-MM-DD)",
+                "description": "Price a booking before checkout and check whether the staff is still free and working for the whole time range. Takes the same fields as POST /services as query params. Nothing is written, no payment or stripe session is created.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Leaveday"
+                    "service"
                 ],
-                "summary": "Create Leaveday",
+                "summary": "Quote a service booking",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "leaveday",
-                        "name": "day",
-                        "in": "path",
+                        "description": "Owner ID (admin only)",
+                        "name": "owner_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pet ID",
+                        "name": "pet_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Staff ID",
+                        "name": "staff_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service type (cservice or mservice)",
+                        "name": "service_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reservation start (RFC3339)",
+                        "name": "reserve_date_start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reservation end (RFC3339)",
+                        "name": "reserve_date_end",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "request successfully",
+                        "description": "Request successful",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid query",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -670,8 +5012,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "404": {
-                        "description": "User not found.",
+                    "422": {
+                        "description": "Validation error",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -685,62 +5027,79 @@ const docTemplate = `{
                 }
             }
         },
-        "/payments": {
-            "get": {
+        "/services/review/{serviceID}": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all payments. Admins can see all payments, while owners can only see their own. Can be filtered by month and year. If only the 'year' query is provided, 'month' will default to 1 (January). Supports pagination.",
+                "description": "Owner-only endpoint to review cservice. The caller must be the owner of the service and the service must be finished. Either ` + "`" + `score` + "`" + ` or ` + "`" + `comment` + "`" + ` (or both) must be provided.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payment"
+                    "service"
                 ],
-                "summary": "Get payments",
+                "summary": "Update cservice score and review",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Filter payments by month (1-12)",
-                        "name": "month",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Filter payments by year (e.g. 2025)",
-                        "name": "year",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number for pagination",
-                        "name": "page",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Service ID",
+                        "name": "serviceID",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 5,
-                        "description": "Number of items per page",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Review payload (score: integer 1-5, comment: optional)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.ReviewRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved payments",
+                        "description": "Request successful",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
+                    "400": {
+                        "description": "Invalid request or missing fields",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorization Token.",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
+                    "403": {
+                        "description": "Invalid role or owner mismatch",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Service not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -750,48 +5109,60 @@ const docTemplate = `{
                 }
             }
         },
-        "/payments/{paymentID}": {
-            "patch": {
+        "/services/staff": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update the status of a payment by its ID. Only admins are authorized to perform this action can update only status type and paydate.",
+                "description": "Retrieve all staff members available for a specific service type on a given day.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payment"
+                    "service"
                 ],
-                "summary": "Update payment status",
+                "summary": "Get available staff",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Payment ID",
-                        "name": "paymentID",
-                        "in": "path",
+                        "description": "Service type to check availability for (cservice or mservice)",
+                        "name": "serviceType",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "description": "payment update data",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entities.UpdatePaymentRequest"
-                        }
+                        "type": "string",
+                        "description": "Service mode (full-day or partial)",
+                        "name": "serviceMode",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "service start date (format: YYYY-MM-DD)",
+                        "name": "startDate",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "service end date (format: YYYY-MM-DD)",
+                        "name": "endDate",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully updated payment status",
+                        "description": "Request successful",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -808,12 +5179,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "404": {
-                        "description": "Payment not found.",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
-                        }
-                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -823,21 +5188,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/pets/owner": {
+        "/services/staff/{staffID}/score": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "owner can get their own pets. This endpoint is owner-only and the owner ID is",
+                "description": "Retrieve average score and list of reviews for a caretaker (staff). Owners and admins can view any caretaker; a caretaker may view their own reviews. If ` + "`" + `staffID` + "`" + ` is omitted and the caller is a caretaker, the handler defaults to the caller's ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "pet"
+                    "service"
+                ],
+                "summary": "Get score and reviews",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Staff ID (caretaker). If omitted and caller is caretaker, defaults to caller's ID",
+                        "name": "staffID",
+                        "in": "path"
+                    }
                 ],
-                "summary": "get owner's pets",
                 "responses": {
                     "200": {
                         "description": "Request successful",
@@ -846,7 +5219,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid owner ID",
+                        "description": "Bad request",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -872,21 +5245,44 @@ const docTemplate = `{
                 }
             }
         },
-        "/pets/{ownerID}": {
+        "/services/staff/{staffID}/slots": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "admin can fetch all pets (provide ownerID in path)",
+                "description": "Per day from startDate to endDate (at most 31 days): the staff's working ranges from their weekly schedule, the busy ranges (bookings, checkout holds, leave days) and the free ranges left to book. Time in the past is never free. Days are in Thai time (UTC+7).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "pet"
+                    "service"
+                ],
+                "summary": "Get free time slot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "first day (format: YYYY-MM-DD)",
+                        "name": "startDate",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "last day (format: YYYY-MM-DD)",
+                        "name": "endDate",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "StaffID",
+                        "name": "staffID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "get all pets for specified owner",
                 "responses": {
                     "200": {
                         "description": "Request successful",
@@ -894,14 +5290,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorization Token.",
+                    "400": {
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "403": {
-                        "description": "Invalid role",
+                    "401": {
+                        "description": "Unauthorization Token.",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -913,50 +5309,62 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/services/staff/{staffID}/time": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "owner can create a pet (only role == \"owner\"). Admin can also create a pet for a specific owner by providing ` + "`" + `ownerID` + "`" + ` in the path.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retrieve all busy time slot for a specific staff on a given day. Only hours inside the staff's working schedule are listed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "pet"
+                    "service"
                 ],
-                "summary": "create pet",
+                "summary": "Get busy time slot",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "owner id (admin only)",
-                        "name": "ownerID",
-                        "in": "path"
+                        "description": "Service type to check availability for (cservice or mservice)",
+                        "name": "serviceType",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "service start date (format: YYYY-MM-DD)",
+                        "name": "startDate",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "service end date (format: YYYY-MM-DD)",
+                        "name": "endDate",
+                        "in": "query",
+                        "required": true
                     },
                     {
-                        "description": "pet payload",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entities.CreatedPetModel"
-                        }
+                        "type": "string",
+                        "description": "StaffID",
+                        "name": "staffID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
+                    "200": {
                         "description": "Request successful",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "400": {
-                        "description": "Invalid json body or missing ownerID for admin",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -973,12 +5381,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "422": {
-                        "description": "Validation error",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
-                        }
-                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -988,39 +5390,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/pets/{petID}": {
+        "/services/{serviceID}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "owner or admin can delete a pet. If role is owner, the pet must belong to the owner.",
+                "description": "owner or admin cancels a booking. Paid bookings are refunded on Stripe by the refund policy: full refund when cancelled at least REFUND_FULL_BEFORE_HOURS before the start, REFUND_LATE_PERCENT after that, nothing once the service is ongoing. Admins can force a full refund.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "pet"
+                    "service"
                 ],
-                "summary": "delete pet",
+                "summary": "cancel service booking",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "pet id",
-                        "name": "petID",
+                        "description": "Service ID",
+                        "name": "serviceID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Cancellation reason",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.CancelServiceRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Request successful",
+                        "description": "Cancel successful",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "400": {
-                        "description": "Invalid pet ID",
+                        "description": "Invalid service ID",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -1032,13 +5445,25 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Invalid role or not owner's pet",
+                        "description": "Invalid role",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
                     "404": {
-                        "description": "pet not found",
+                        "description": "Service not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Service can no longer be cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -1048,6 +5473,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
+                    },
+                    "502": {
+                        "description": "Stripe refund failed",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
                     }
                 }
             },
@@ -1057,7 +5488,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "owner or admin can update a pet. If role is owner, the pet must belong to the owner.",
+                "description": "Admin-only endpoint for adjusting service data. Provide the fields that need to change.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1065,24 +5496,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "pet"
+                    "service"
                 ],
-                "summary": "update pet",
+                "summary": "Update service booking",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "pet id",
-                        "name": "petID",
+                        "description": "Service ID",
+                        "name": "serviceID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "pet payload",
+                        "description": "service update payload",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entities.UpdatePetModel"
+                            "$ref": "#/definitions/entities.UpdateServiceRequest"
                         }
                     }
                 ],
@@ -1094,7 +5525,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid pet ID or no fields to update",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -1106,13 +5537,19 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Invalid role or not owner's pet",
+                        "description": "Invalid role",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
                     "404": {
-                        "description": "pet not found",
+                        "description": "Service not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Staff is not available or status transition not allowed",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -1132,51 +5569,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/services": {
+        "/services/{serviceID}/attachments": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all services for the authenticated user. Admins can see all services. Can be filtered by status, month, and year.",
+                "description": "Documents attached to a service newest first, each with a signed URL valid until url_expires_at.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "service"
+                    "attachment"
                 ],
-                "summary": "Get services",
+                "summary": "Get service documents",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter services by status (e.g. all, wait, ongoing, finish)",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Filter services by month (1-12)",
-                        "name": "month",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Filter services by year (e.g. 2025)",
-                        "name": "year",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number for pagination",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of items per page",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Service ID",
+                        "name": "serviceID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1186,57 +5600,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorization Token.",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
-                        }
-                    }
-                }
-            },
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Owners create their own bookings; admins may create on behalf of an owner by providing owner_id. Use service_type=cservice (caretaker) or mservice (doctor) and supply staff_id plus type-specific fields. this route then create payment and send those to stripe to get payment link.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "service"
-                ],
-                "summary": "Get stripe payment link to Create caretaker/medical service",
-                "parameters": [
-                    {
-                        "description": "service payload (admins must include owner_id; mservice requires disease)",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entities.CreateServiceRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Request successful",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseModel"
-                        }
-                    },
                     "400": {
-                        "description": "Invalid json body",
+                        "description": "Invalid service ID",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -1248,13 +5613,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Invalid role",
+                        "description": "Not allowed for this service",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "422": {
-                        "description": "Validation error",
+                    "404": {
+                        "description": "Service not found",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -1266,26 +5631,24 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/services/review/{serviceID}": {
-            "patch": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Owner-only endpoint to review cservice. The caller must be the owner of the service and the service must be finished. Either ` + "`" + `score` + "`" + ` or ` + "`" + `comment` + "`" + ` (or both) must be provided.",
+                "description": "Attach a document (lab result, referral) to a service. It also shows in the files of the pet. The owner of the pet, the doctor assigned to the mservice and admins can attach. pdf, jpeg or png up to 10 MB.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "service"
+                    "attachment"
                 ],
-                "summary": "Update cservice score and review",
+                "summary": "Attach document to service",
                 "parameters": [
                     {
                         "type": "string",
@@ -1295,24 +5658,22 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Review payload (score: integer 1-5, comment: optional)",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entities.ReviewRequest"
-                        }
+                        "type": "file",
+                        "description": "file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
+                    "201": {
                         "description": "Request successful",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "400": {
-                        "description": "Invalid request or missing fields",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -1324,7 +5685,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Invalid role or owner mismatch",
+                        "description": "Not allowed for this service",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -1335,8 +5696,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
-                    "422": {
-                        "description": "Validation error",
+                    "413": {
+                        "description": "File too large",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "415": {
+                        "description": "File type not allowed",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -1350,62 +5717,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/services/staff": {
+        "/services/{serviceID}/history": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve all staff members available for a specific service type on a given day.",
+                "description": "Every status change of a service with who made it and when, oldest first. changed_by is empty for changes made by the system (Stripe refunds and disputes). Owners see their own services, caretakers and doctors the services assigned to them.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "service"
                 ],
-                "summary": "Get available staff",
+                "summary": "Get service status history",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Service type to check availability for (cservice or mservice)",
-                        "name": "serviceType",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Service mode (full-day or partial)",
-                        "name": "serviceMode",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "service start date (format: YYYY-MM-DD)",
-                        "name": "startDate",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "service end date (format: YYYY-MM-DD)",
-                        "name": "endDate",
-                        "in": "query",
+                        "description": "Service ID",
+                        "name": "serviceID",
+                        "in": "path",
                         "required": true
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "Request successful",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseModel"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid request",
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
                         "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
+                            "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "401": {
@@ -1420,6 +5760,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
+                    "404": {
+                        "description": "Service not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -1429,27 +5775,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/services/staff/{staffID}/score": {
+        "/services/{serviceID}/medical-record": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve average score and list of reviews for a caretaker (staff). Owners and admins can view any caretaker; a caretaker may view their own reviews. If ` + "`" + `staffID` + "`" + ` is omitted and the caller is a caretaker, the handler defaults to the caller's ID.",
+                "description": "Visit notes of an mservice. Owners see their own services, doctors the services assigned to them.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "service"
+                    "medical record"
                 ],
-                "summary": "Get score and reviews",
+                "summary": "Get medical record of a service",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Staff ID (caretaker). If omitted and caller is caretaker, defaults to caller's ID",
-                        "name": "staffID",
-                        "in": "path"
+                        "description": "Service ID",
+                        "name": "serviceID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1459,12 +5806,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
-                        }
-                    },
                     "401": {
                         "description": "Unauthorization Token.",
                         "schema": {
@@ -1477,6 +5818,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
+                    "404": {
+                        "description": "Service or medical record not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -1484,51 +5831,40 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/services/staff/{staffID}/time": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve all busy time slot for a specific staff on a given day.",
+                "description": "Write the visit notes of an mservice: diagnosis, symptoms, vitals (weight kg, temperature °C), prescribed medicines and follow-up. Saving again replaces the whole record, medicines included. Only the doctor assigned to the service, once it is ongoing or finished.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "service"
+                    "medical record"
                 ],
-                "summary": "Get busy time slot",
+                "summary": "Save medical record",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Service type to check availability for (cservice or mservice)",
-                        "name": "serviceType",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "service start date (format: YYYY-MM-DD)",
-                        "name": "startDate",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "service end date (format: YYYY-MM-DD)",
-                        "name": "endDate",
-                        "in": "query",
+                        "description": "Service ID",
+                        "name": "serviceID",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "StaffID",
-                        "name": "staffID",
-                        "in": "path",
-                        "required": true
+                        "description": "visit notes",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entities.SaveMedicalRecordRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -1551,7 +5887,25 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Invalid role",
+                        "description": "Not the assigned doctor",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "404": {
+                        "description": "Service not found",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Not an mservice or visit not started",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -1565,44 +5919,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/services/{serviceID}": {
-            "delete": {
+        "/services/{serviceID}/reassignments": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "owner deletes their service booking by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Staff changes of a service made because the assigned staff became unavailable or was deleted, oldest first. Owners see their own services, caretakers and doctors the services assigned to them.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "service"
                 ],
-                "summary": "delete service booking",
+                "summary": "Get service reassignments",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Service ID",
-                        "name": "id",
+                        "name": "serviceID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Delete successful",
-                        "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid service ID",
+                        "description": "Request successful",
                         "schema": {
-                            "$ref": "#/definitions/entities.ResponseMessage"
+                            "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "401": {
@@ -1630,14 +5975,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/services/{serviceID}/reschedule": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Admin-only endpoint for adjusting service data. Provide the fields that need to change.",
+                "description": "owner or admin moves a waiting booking to a new time range. The new range goes through the same staff availability check as a new booking and is priced with the current rules. If it costs more, an adjustment payment linked to the booking's payment is created and the booking moves once it is paid (stripe_link is returned). If it costs less, the reschedule is saved before the difference is refunded on Stripe and the booking moves right away; if that fails, retrying the same range reuses the saved refund instead of refunding again.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1647,7 +5994,7 @@ const docTemplate = `{
                 "tags": [
                     "service"
                 ],
-                "summary": "Update service booking",
+                "summary": "reschedule service booking",
                 "parameters": [
                     {
                         "type": "string",
@@ -1657,24 +6004,30 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "service update payload",
-                        "name": "body",
+                        "description": "New time range",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/entities.UpdateServiceRequest"
+                            "$ref": "#/definitions/entities.RescheduleServiceRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Request successful",
+                        "description": "Rescheduled",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "202": {
+                        "description": "Waiting for the extra payment",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseModel"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid json body",
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
@@ -1697,6 +6050,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
+                    "409": {
+                        "description": "Staff is not available, service can't be rescheduled or another reschedule is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
                     "422": {
                         "description": "Validation error",
                         "schema": {
@@ -1708,6 +6067,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
+                    },
+                    "502": {
+                        "description": "Stripe refund failed",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
                     }
                 }
             }
@@ -1719,7 +6084,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update the status of a service booking. Allowed roles: admin, caretaker, doctor.",
+                "description": "Move a service booking along wait -\u003e ongoing -\u003e finish, or wait -\u003e no_show. Caretakers and doctors can only update their own services, can't start more than 30 minutes before reserve_date_start, and can't mark no_show until 30 minutes after it. Admins may also move ongoing back to wait. Cancelling goes through DELETE /services/{serviceID}. Every change is recorded in the status history.",
                 "produces": [
                     "application/json"
                 ],
@@ -1739,10 +6104,11 @@ const docTemplate = `{
                         "enum": [
                             "wait",
                             "ongoing",
-                            "finish"
+                            "finish",
+                            "no_show"
                         ],
                         "type": "string",
-                        "description": "service status (wait, ongoing, finish)",
+                        "description": "service status",
                         "name": "status",
                         "in": "path",
                         "required": true
@@ -1779,6 +6145,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
+                    "409": {
+                        "description": "Status transition not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -1922,7 +6294,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "update user picture by id and role from JWT token",
+                "description": "update user picture by id and role from JWT token. jpeg, png or webp up to 5 MB, older pictures are removed once the new one is saved.",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -1961,6 +6333,99 @@ const docTemplate = `{
                             "$ref": "#/definitions/entities.ResponseMessage"
                         }
                     },
+                    "413": {
+                        "description": "Picture larger than 5 MB",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "415": {
+                        "description": "Picture is not jpeg, png or webp",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/roles/{role}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add owner, caretaker or doctor to the logged in account. Email and password stay the same, switch to the new role with POST /auth/switch-role/{role}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "add role to my account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role to add (owner, caretaker, doctor)",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "license_number is required for doctor",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.AddRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Request successful",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseModel"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid json body",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorization Token.",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "409": {
+                        "description": "Account already has this role",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ResponseMessage"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1993,12 +6458,86 @@ const docTemplate = `{
                 "doctor",
                 "caretaker"
             ],
-            "x-enum-varnames": [
-                "RoleAdmin",
-                "RoleOwner",
-                "RoleDoctor",
-                "RoleCaretaker"
-            ]
+            "x-enum-varnames": [
+                "RoleAdmin",
+                "RoleOwner",
+                "RoleDoctor",
+                "RoleCaretaker"
+            ]
+        },
+        "entities.AddRoleRequest": {
+            "type": "object",
+            "properties": {
+                "license_number": {
+                    "description": "doctor only",
+                    "type": "string"
+                },
+                "specialization": {
+                    "description": "caretaker only (optional)",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.CancelServiceRequest": {
+            "type": "object",
+            "properties": {
+                "full_refund": {
+                    "description": "admin only: คืนเต็มจำนวนโดยไม่สน policy (เช่น staff ไม่ว่างเอง)",
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "minLength": 1
+                }
+            }
+        },
+        "entities.CreateLeaveRequest": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "start_date"
+            ],
+            "properties": {
+                "end_date": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.CreateScheduleExceptionRequest": {
+            "type": "object",
+            "required": [
+                "end",
+                "kind",
+                "start"
+            ],
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "extra = กะเพิ่มนอกตาราง, unavailable = ไม่ว่างช่วงนี้",
+                    "type": "string",
+                    "enum": [
+                        "extra",
+                        "unavailable"
+                    ]
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
         },
         "entities.CreateServiceRequest": {
             "type": "object",
@@ -2007,8 +6546,7 @@ const docTemplate = `{
                 "reserve_date_end",
                 "reserve_date_start",
                 "service_type",
-                "staff_id",
-                "status"
+                "staff_id"
             ],
             "properties": {
                 "owner_id": {
@@ -2037,14 +6575,44 @@ const docTemplate = `{
                 },
                 "staff_id": {
                     "type": "string"
+                }
+            }
+        },
+        "entities.CreateVaccinationRequest": {
+            "type": "object",
+            "required": [
+                "given_date",
+                "vaccine"
+            ],
+            "properties": {
+                "doctor_id": {
+                    "description": "admin only, บันทึกย้อนหลังให้หมอ",
+                    "type": "string"
                 },
-                "status": {
+                "given_date": {
+                    "type": "string"
+                },
+                "kind": {
                     "type": "string",
                     "enum": [
-                        "wait",
-                        "ongoing",
-                        "finish"
+                        "vaccine",
+                        "preventive"
                     ]
+                },
+                "next_due_date": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "service_id": {
+                    "description": "mservice ที่ฉีด ถ้ามี หมอที่ฉีดจะมาจาก service นั้น",
+                    "type": "string"
+                },
+                "vaccine": {
+                    "type": "string",
+                    "maxLength": 100
                 }
             }
         },
@@ -2129,6 +6697,17 @@ const docTemplate = `{
                 }
             }
         },
+        "entities.HolidayRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "entities.LoginUserRequestModel": {
             "type": "object",
             "required": [
@@ -2144,6 +6723,90 @@ const docTemplate = `{
                 }
             }
         },
+        "entities.MedicineRequest": {
+            "type": "object",
+            "required": [
+                "dosage",
+                "frequency",
+                "name"
+            ],
+            "properties": {
+                "dosage": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "duration_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "frequency": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "instructions": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "entities.MfaCodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.MfaRequirementModel": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/db.Role"
+                }
+            }
+        },
+        "entities.MfaSetupModel": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.MfaStatusModel": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "locked_until": {
+                    "type": "string"
+                },
+                "recovery_codes_left": {
+                    "type": "integer"
+                },
+                "required": {
+                    "type": "boolean"
+                }
+            }
+        },
         "entities.PasswordModel": {
             "type": "object",
             "required": [
@@ -2155,6 +6818,154 @@ const docTemplate = `{
                 }
             }
         },
+        "entities.PetSurchargeRequest": {
+            "type": "object",
+            "required": [
+                "amount_per_hour",
+                "name"
+            ],
+            "properties": {
+                "amount_per_hour": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "kind": {
+                    "type": "string",
+                    "minLength": 1
+                },
+                "max_weight": {
+                    "type": "number"
+                },
+                "min_weight": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string"
+                },
+                "service_type": {
+                    "type": "string",
+                    "enum": [
+                        "mservice",
+                        "cservice"
+                    ]
+                }
+            }
+        },
+        "entities.PriceMultiplierRequest": {
+            "type": "object",
+            "required": [
+                "day_type",
+                "end_hour",
+                "multiplier",
+                "name"
+            ],
+            "properties": {
+                "day_type": {
+                    "type": "string",
+                    "enum": [
+                        "any",
+                        "weekday",
+                        "weekend"
+                    ]
+                },
+                "end_hour": {
+                    "type": "integer",
+                    "maximum": 24,
+                    "minimum": 1
+                },
+                "multiplier": {
+                    "type": "number",
+                    "maximum": 10
+                },
+                "name": {
+                    "type": "string"
+                },
+                "start_hour": {
+                    "description": "ชั่วโมงตามเวลาไทย ช่วง [start_hour, end_hour)",
+                    "type": "integer",
+                    "maximum": 23,
+                    "minimum": 0
+                }
+            }
+        },
+        "entities.RateRequest": {
+            "type": "object",
+            "required": [
+                "hourly_rate"
+            ],
+            "properties": {
+                "hourly_rate": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "entities.ReassignBookingsRequest": {
+            "type": "object",
+            "required": [
+                "start_date"
+            ],
+            "properties": {
+                "end_date": {
+                    "type": "string"
+                },
+                "mode": {
+                    "description": "auto หา staff แทนตาม rating ที่หาไม่ได้ค่อย flag, flag ให้ admin จัดการเองทั้งหมด",
+                    "type": "string",
+                    "enum": [
+                        "auto",
+                        "flag"
+                    ]
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "start_date": {
+                    "description": "ช่วงวันที่ staff ไม่ว่าง (YYYY-MM-DD) ไม่ใส่ end_date = ทุก booking ตั้งแต่ start_date",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.RescheduleServiceRequest": {
+            "type": "object",
+            "required": [
+                "reserve_date_end",
+                "reserve_date_start"
+            ],
+            "properties": {
+                "reserve_date_end": {
+                    "type": "string"
+                },
+                "reserve_date_start": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.ResolveReassignmentRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "staff_id": {
+                    "type": "string"
+                }
+            }
+        },
         "entities.ResponseMessage": {
             "type": "object",
             "properties": {
@@ -2190,18 +7001,78 @@ const docTemplate = `{
                 }
             }
         },
+        "entities.SaveMedicalRecordRequest": {
+            "type": "object",
+            "required": [
+                "diagnosis",
+                "symptoms"
+            ],
+            "properties": {
+                "diagnosis": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "follow_up": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "follow_up_date": {
+                    "type": "string"
+                },
+                "medicines": {
+                    "type": "array",
+                    "maxItems": 30,
+                    "items": {
+                        "$ref": "#/definitions/entities.MedicineRequest"
+                    }
+                },
+                "symptoms": {
+                    "type": "array",
+                    "maxItems": 30,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "temperature": {
+                    "description": "°C",
+                    "type": "number"
+                },
+                "weight": {
+                    "description": "kg",
+                    "type": "number"
+                }
+            }
+        },
         "entities.SendEmailModel": {
             "type": "object",
             "required": [
-                "email",
-                "role"
+                "email"
             ],
             "properties": {
                 "email": {
                     "type": "string"
+                }
+            }
+        },
+        "entities.StaffShiftRequest": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "start_time",
+                "weekday"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string"
                 },
-                "role": {
-                    "$ref": "#/definitions/db.Role"
+                "start_time": {
+                    "type": "string"
+                },
+                "weekday": {
+                    "description": "0 = Sunday ... 6 = Saturday",
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
                 }
             }
         },
@@ -2217,7 +7088,11 @@ const docTemplate = `{
                     "type": "string",
                     "enum": [
                         "UNPAID",
-                        "PAID"
+                        "PAID",
+                        "EXPIRED",
+                        "FAILED",
+                        "REFUNDED",
+                        "DISPUTED"
                     ]
                 },
                 "type": {
@@ -2289,11 +7164,24 @@ const docTemplate = `{
                     "enum": [
                         "wait",
                         "ongoing",
-                        "finish"
+                        "finish",
+                        "no_show"
                     ]
                 }
             }
         },
+        "entities.UpdateStaffScheduleRequest": {
+            "type": "object",
+            "properties": {
+                "shifts": {
+                    "description": "ส่ง list ว่างเพื่อกลับไปใช้เวลาทำงานใน profile",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.StaffShiftRequest"
+                    }
+                }
+            }
+        },
         "entities.UpdateUserModel": {
             "type": "object",
             "properties": {
@@ -2381,8 +7269,11 @@ const docTemplate = `{
                 "rating": {
                     "type": "number"
                 },
-                "role": {
-                    "$ref": "#/definitions/db.Role"
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.Role"
+                    }
                 },
                 "show_id": {
                     "type": "integer"
@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"io"
+	"lama-backend/domain/prisma/db"
+	"time"
+)
+
+type AttachmentModel struct {
+	ID          string            `json:"id"`
+	PetID       string            `json:"pet_id"`
+	ServiceID   *string           `json:"service_id,omitempty"`
+	Kind        db.AttachmentKind `json:"kind"`
+	FileName    string            `json:"file_name"`
+	ContentType string            `json:"content_type"`
+	Size        int               `json:"size"`
+	StorageKey  string            `json:"-"`
+	UploadedBy  string            `json:"uploaded_by"`
+	CreatedAt   time.Time         `json:"created_at"`
+	// signed url อายุสั้น ขอใหม่ได้ด้วยการดึง list อีกรอบ
+	URL          string    `json:"url"`
+	URLExpiresAt time.Time `json:"url_expires_at"`
+}
+
+// AttachmentUpload is a file from a multipart form, Size is what the client sent.
+type AttachmentUpload struct {
+	FileName string
+	Size     int64
+	Body     io.Reader
+}
@@ -2,10 +2,33 @@ package entities
 
 import (
 	"lama-backend/domain/prisma/db"
+	"time"
 )
 
-type LeavedayModel struct {
-	StaffID   string
-	StaffType string
-	Leaveday  []db.DateTime
+// LeaveRequestModel is one leave request, all of its days share the status.
+type LeaveRequestModel struct {
+	RequestID  string         `json:"request_id"`
+	StaffID    string         `json:"staff_id"`
+	StaffType  string         `json:"staff_type"`
+	StartDate  time.Time      `json:"start_date"`
+	EndDate    time.Time      `json:"end_date"`
+	Days       []time.Time    `json:"days"`
+	Status     db.LeaveStatus `json:"status"`
+	Reason     *string        `json:"reason,omitempty"`
+	ReviewedBy *string        `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time     `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type LeaveFilter struct {
+	StaffID *string
+	Status  *db.LeaveStatus
+	From    *time.Time
+	To      *time.Time
+}
+
+type CreateLeaveRequest struct {
+	StartDate string  `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string  `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Reason    *string `json:"reason" validate:"omitempty,max=255"`
 }
@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"lama-backend/domain/prisma/db"
+	"time"
+)
+
+type MedicineModel struct {
+	Name         string  `json:"name"`
+	Dosage       *string `json:"dosage,omitempty"`
+	Frequency    *string `json:"frequency,omitempty"`
+	DurationDays *int    `json:"duration_days,omitempty"`
+	Instructions *string `json:"instructions,omitempty"`
+}
+
+type MedicalRecordModel struct {
+	ID           string          `json:"id"`
+	ServiceID    string          `json:"service_id"`
+	PetID        string          `json:"pet_id"`
+	DoctorID     string          `json:"doctor_id"`
+	Diagnosis    string          `json:"diagnosis"`
+	Symptoms     []string        `json:"symptoms"`
+	Weight       *db.Decimal     `json:"weight,omitempty"`
+	Temperature  *db.Decimal     `json:"temperature,omitempty"`
+	FollowUp     *string         `json:"follow_up,omitempty"`
+	FollowUpDate *time.Time      `json:"follow_up_date,omitempty"`
+	Medicines    []MedicineModel `json:"medicines"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+type MedicineRequest struct {
+	Name         string  `json:"name" validate:"required,max=100"`
+	Dosage       string  `json:"dosage" validate:"required,max=100"`
+	Frequency    string  `json:"frequency" validate:"required,max=100"`
+	DurationDays *int    `json:"duration_days" validate:"omitempty,min=1,max=365"`
+	Instructions *string `json:"instructions" validate:"omitempty,max=500"`
+}
+
+// SaveMedicalRecordRequest replaces the whole record of the visit, medicines included.
+type SaveMedicalRecordRequest struct {
+	Diagnosis string   `json:"diagnosis" validate:"required,max=500"`
+	Symptoms  []string `json:"symptoms" validate:"omitempty,max=30,dive,required,max=200"`
+	// kg
+	Weight *db.Decimal `json:"weight"`
+	// °C
+	Temperature  *db.Decimal       `json:"temperature"`
+	FollowUp     *string           `json:"follow_up" validate:"omitempty,max=1000"`
+	FollowUpDate *string           `json:"follow_up_date" validate:"omitempty,datetime=2006-01-02"`
+	Medicines    []MedicineRequest `json:"medicines" validate:"omitempty,max=30,dive"`
+}
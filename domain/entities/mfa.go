@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"lama-backend/domain/prisma/db"
+	"time"
+)
+
+type MfaModel struct {
+	UserID            string     `json:"user_id"`
+	Secret            string     `json:"-"` // เข้ารหัสอยู่
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+	LastStep          int        `json:"-"`
+	FailedAttempts    int        `json:"-"`
+	LockedUntil       *time.Time `json:"locked_until,omitempty"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+type MfaRequirementModel struct {
+	Role      db.Role   `json:"role"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Code is the 6 digits from the authenticator app, or one of the recovery codes.
+type MfaCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// OtpauthURI is also what the QR code for the authenticator app encodes.
+type MfaSetupModel struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type MfaStatusModel struct {
+	Enabled           bool       `json:"enabled"`
+	Required          bool       `json:"required"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+	LockedUntil       *time.Time `json:"locked_until,omitempty"`
+}
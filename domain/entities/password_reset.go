@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+type PasswordResetModel struct {
+	ID                  string     `json:"id"`
+	UserID              *string    `json:"user_id,omitempty"`
+	Email               string     `json:"email"`
+	IP                  string     `json:"ip"`
+	PasswordFingerprint string     `json:"-"`
+	CreatedAt           time.Time  `json:"created_at"`
+	ExpiresAt           time.Time  `json:"expires_at"`
+	UsedAt              *time.Time `json:"used_at,omitempty"`
+}
+
+// ResetRequestCount is how many reset requests an email and an IP made inside the window.
+type ResetRequestCount struct {
+	ByEmail int `json:"by_email"`
+	ByIP    int `json:"by_ip"`
+}
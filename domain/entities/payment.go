@@ -6,17 +6,32 @@ import (
 )
 
 type PaymentModel struct {
-	PayID   string           `json:"payment_id"`
-	OwnerID string           `json:"owner_id"`
-	Status  db.PaymentStatus `json:"status"`
-	Price   int              `json:"price"`
-	Type    *string          `json:"type"`
-	PayDate *time.Time       `json:"pay_date,omitempty"`
+	PayID               string                 `json:"payment_id"`
+	OwnerID             string                 `json:"owner_id"`
+	Status              db.PaymentStatus       `json:"status"`
+	Price               int                    `json:"price"`
+	Type                *string                `json:"type"`
+	PayDate             *time.Time             `json:"pay_date,omitempty"`
+	StripePaymentIntent *string                `json:"stripe_payment_intent,omitempty"`
+	RefundedAmount      int                    `json:"refunded_amount"`
+	LineItems           []PaymentLineItemModel `json:"line_items,omitempty"`
+	ParentID            *string                `json:"parent_payment_id,omitempty"`
+	Adjustments         []*PaymentModel        `json:"adjustments,omitempty"`
+}
+
+// PaymentRefundModel is one Stripe refund against one payment of a booking.
+type PaymentRefundModel struct {
+	PaymentID      string `json:"payment_id"`
+	Amount         int    `json:"amount"`
+	StripeRefundID string `json:"stripe_refund_id"`
+	// refunded_amount ของ payment นั้นหลังคืนครั้งนี้
+	RefundedAmount int  `json:"-"`
+	FullyRefunded  bool `json:"-"`
 }
 
 type UpdatePaymentRequest struct {
 	// ล้อตาม enum payment_status ของคุณ
-	Status *string `json:"status" validate:"omitempty,oneof=UNPAID PAID"`
+	Status *string `json:"status" validate:"omitempty,oneof=UNPAID PAID EXPIRED FAILED REFUNDED DISPUTED"`
 
 	// สมมติว่า Type มีได้ 2 แบบ (คุณไปแก้ได้)
 	Type *string `json:"type" validate:"omitempty,min=1"`
@@ -36,3 +51,18 @@ type PaymentCommonModel struct {
 	Type    *string          `json:"type"`
 	PayDate *time.Time       `json:"pay_date,omitempty"`
 }
+
+type StripeEventModel struct {
+	EventID  string               `json:"event_id"`
+	Type     string               `json:"type"`
+	Status   db.StripeEventStatus `json:"status"`
+	Response *string              `json:"response,omitempty"`
+}
+
+// StripePaymentUpdate is what a webhook event wants to write onto a payment.
+type StripePaymentUpdate struct {
+	Status        db.PaymentStatus
+	Type          *string
+	PayDate       *time.Time
+	PaymentIntent *string
+}
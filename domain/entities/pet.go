@@ -44,3 +44,59 @@ type PetCommonModel struct {
 	Kind      string     `json:"kind"`
 	Sex       db.PetSex  `json:"sex"`
 }
+
+// PetTimelineEvent is one line of the pet's history. Only the fields of its type are set.
+type PetTimelineEvent struct {
+	Type        string           `json:"type"`
+	OccurredAt  time.Time        `json:"occurred_at"`
+	ServiceID   string           `json:"service_id"`
+	ServiceType string           `json:"service_type"`
+	Status      db.ServiceStatus `json:"status,omitempty"`
+	Upcoming    bool             `json:"upcoming"`
+	StaffID     string           `json:"staff_id,omitempty"`
+	StaffName   string           `json:"staff_name,omitempty"`
+
+	Diagnosis   *string           `json:"diagnosis,omitempty"`
+	Symptoms    []string          `json:"symptoms,omitempty"`
+	Medicine    *MedicineModel    `json:"medicine,omitempty"`
+	Vaccination *VaccinationModel `json:"vaccination,omitempty"`
+	Score       *int              `json:"score,omitempty"`
+	Comment     *string           `json:"comment,omitempty"`
+	// kg, change คือส่วนต่างจากน้ำหนักที่บันทึกครั้งก่อน
+	Weight       *db.Decimal `json:"weight,omitempty"`
+	WeightChange *db.Decimal `json:"weight_change,omitempty"`
+}
+
+type PetTimelineFilter struct {
+	Types []string
+	Page  int
+	Limit int
+}
+
+type PetWeightModel struct {
+	ID         string             `json:"id"`
+	PetID      string             `json:"pet_id"`
+	Weight     db.Decimal         `json:"weight"`
+	Source     db.PetWeightSource `json:"source"`
+	ServiceID  *string            `json:"service_id,omitempty"`
+	RecordedBy *string            `json:"recorded_by,omitempty"`
+	MeasuredAt time.Time          `json:"measured_at"`
+}
+
+// PetWeightPoint is one point of the weight series. Downsampled points average every
+// measurement of the bucket and start at the beginning of it.
+type PetWeightPoint struct {
+	MeasuredAt time.Time           `json:"measured_at"`
+	Weight     db.Decimal          `json:"weight"`
+	Min        *db.Decimal         `json:"min,omitempty"`
+	Max        *db.Decimal         `json:"max,omitempty"`
+	Count      int                 `json:"count"`
+	Source     *db.PetWeightSource `json:"source,omitempty"`
+	ServiceID  *string             `json:"service_id,omitempty"`
+}
+
+type PetWeightFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Bucket string
+}
@@ -0,0 +1,101 @@
+package entities
+
+import (
+	"lama-backend/domain/prisma/db"
+	"time"
+)
+
+type RateCardModel struct {
+	ServiceType string    `json:"service_type"`
+	HourlyRate  int       `json:"hourly_rate"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type StaffRateModel struct {
+	StaffID    string    `json:"staff_id"`
+	HourlyRate int       `json:"hourly_rate"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type PetSurchargeModel struct {
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	ServiceType   *string     `json:"service_type,omitempty"`
+	Kind          *string     `json:"kind,omitempty"`
+	MinWeight     *db.Decimal `json:"min_weight,omitempty"`
+	MaxWeight     *db.Decimal `json:"max_weight,omitempty"`
+	AmountPerHour int         `json:"amount_per_hour"`
+}
+
+type PriceMultiplierModel struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	DayType    db.PriceDayType `json:"day_type"`
+	StartHour  int             `json:"start_hour"`
+	EndHour    int             `json:"end_hour"`
+	Multiplier db.Decimal      `json:"multiplier"`
+}
+
+type HolidayModel struct {
+	Date time.Time `json:"date"`
+	Name string    `json:"name"`
+}
+
+// PricingConfigModel is everything the admin can configure, used for listing.
+type PricingConfigModel struct {
+	RateCards     []RateCardModel        `json:"rate_cards"`
+	StaffRates    []StaffRateModel       `json:"staff_rates"`
+	PetSurcharges []PetSurchargeModel    `json:"pet_surcharges"`
+	Multipliers   []PriceMultiplierModel `json:"multipliers"`
+	Holidays      []HolidayModel         `json:"holidays"`
+}
+
+// PricingRules are the rules that apply to one booking.
+type PricingRules struct {
+	RateCard      *RateCardModel
+	StaffRate     *StaffRateModel
+	PetSurcharges []PetSurchargeModel
+	Multipliers   []PriceMultiplierModel
+	Holidays      []HolidayModel
+}
+
+type PaymentLineItemModel struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitAmount  int    `json:"unit_amount"`
+	Amount      int    `json:"amount"`
+}
+
+// ServiceQuoteModel is the price of a booking before anything is written.
+type ServiceQuoteModel struct {
+	Price     int                    `json:"price"`
+	LineItems []PaymentLineItemModel `json:"line_items"`
+	Available bool                   `json:"available"`
+}
+
+type RateRequest struct {
+	HourlyRate int `json:"hourly_rate" validate:"required,gte=1"`
+}
+
+type PetSurchargeRequest struct {
+	Name          string   `json:"name" validate:"required"`
+	ServiceType   *string  `json:"service_type,omitempty" validate:"omitempty,oneof=mservice cservice"`
+	Kind          *string  `json:"kind,omitempty" validate:"omitempty,min=1"`
+	MinWeight     *float64 `json:"min_weight,omitempty" validate:"omitempty,gte=0"`
+	MaxWeight     *float64 `json:"max_weight,omitempty" validate:"omitempty,gt=0"`
+	AmountPerHour int      `json:"amount_per_hour" validate:"required,gte=1"`
+}
+
+type PriceMultiplierRequest struct {
+	Name    string `json:"name" validate:"required"`
+	DayType string `json:"day_type" validate:"required,oneof=any weekday weekend"`
+	// ชั่วโมงตามเวลาไทย ช่วง [start_hour, end_hour)
+	StartHour  int     `json:"start_hour" validate:"gte=0,lte=23"`
+	EndHour    int     `json:"end_hour" validate:"required,gte=1,lte=24,gtfield=StartHour"`
+	Multiplier float64 `json:"multiplier" validate:"required,gt=1,lte=10"`
+}
+
+type HolidayRequest struct {
+	Name string `json:"name" validate:"required"`
+}
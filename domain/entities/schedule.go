@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"lama-backend/domain/prisma/db"
+	"time"
+)
+
+// StaffShiftModel is one working period of a weekday, times are "HH:MM".
+type StaffShiftModel struct {
+	Weekday   int    `json:"weekday"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+type StaffScheduleModel struct {
+	StaffID string `json:"staff_id"`
+	// weekly = ตั้งตารางรายสัปดาห์ไว้, profile = ใช้ start/end_working_time ทุกวัน, default = 08:00-17:00
+	Source string            `json:"source"`
+	Shifts []StaffShiftModel `json:"shifts"`
+}
+
+type StaffShiftRequest struct {
+	// 0 = Sunday ... 6 = Saturday
+	Weekday   *int   `json:"weekday" validate:"required,min=0,max=6"`
+	StartTime string `json:"start_time" validate:"required,datetime=15:04"`
+	EndTime   string `json:"end_time" validate:"required,datetime=15:04"`
+}
+
+type UpdateStaffScheduleRequest struct {
+	// ส่ง list ว่างเพื่อกลับไปใช้เวลาทำงานใน profile
+	Shifts []StaffShiftRequest `json:"shifts" validate:"dive"`
+}
+
+type StaffScheduleExceptionModel struct {
+	ID        string                   `json:"id"`
+	StaffID   string                   `json:"staff_id"`
+	Kind      db.ScheduleExceptionKind `json:"kind"`
+	Start     time.Time                `json:"start"`
+	End       time.Time                `json:"end"`
+	Note      *string                  `json:"note,omitempty"`
+	CreatedBy string                   `json:"created_by"`
+	CreatedAt time.Time                `json:"created_at"`
+}
+
+type CreateScheduleExceptionRequest struct {
+	// extra = กะเพิ่มนอกตาราง, unavailable = ไม่ว่างช่วงนี้
+	Kind  string    `json:"kind" validate:"required,oneof=extra unavailable"`
+	Start time.Time `json:"start" validate:"required"`
+	End   time.Time `json:"end" validate:"required,gtfield=Start"`
+	Note  *string   `json:"note" validate:"omitempty,max=255"`
+}
+
+type TimeRangeModel struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type StaffDaySlotsModel struct {
+	Date    string           `json:"date"`
+	Working []TimeRangeModel `json:"working"`
+	Busy    []TimeRangeModel `json:"busy"`
+	Free    []TimeRangeModel `json:"free"`
+}
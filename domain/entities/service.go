@@ -31,16 +31,106 @@ type CreateServiceRequest struct {
 	PaymentID        string    `json:"payment_id,omitempty"` // for backend
 	StaffID          string    `json:"staff_id" validate:"required,uuid4"`
 	ServiceType      string    `json:"service_type" validate:"required,oneof=mservice cservice"`
-	Status           string    `json:"status" validate:"required,oneof=wait ongoing finish"`
 	ReserveDateStart time.Time `json:"reserve_date_start" validate:"required"`
 	ReserveDateEnd   time.Time `json:"reserve_date_end" validate:"required"`
 }
 
+type CancelServiceRequest struct {
+	Reason *string `json:"reason,omitempty" validate:"omitempty,min=1,max=1000"`
+	// admin only: คืนเต็มจำนวนโดยไม่สน policy (เช่น staff ไม่ว่างเอง)
+	FullRefund bool `json:"full_refund,omitempty"`
+}
+
+type ServiceCancellationModel struct {
+	ServiceID       string               `json:"service_id"`
+	PaymentID       string               `json:"payment_id"`
+	OwnerID         string               `json:"-"`
+	FromStatus      db.ServiceStatus     `json:"-"`
+	CancelledBy     string               `json:"cancelled_by"`
+	CancelledByRole db.Role              `json:"cancelled_by_role"`
+	Reason          *string              `json:"reason,omitempty"`
+	RefundPercent   int                  `json:"refund_percent"`
+	RefundAmount    int                  `json:"refund_amount"`
+	Refunds         []PaymentRefundModel `json:"refunds,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+}
+
+type RescheduleServiceRequest struct {
+	ReserveDateStart time.Time `json:"reserve_date_start" validate:"required"`
+	ReserveDateEnd   time.Time `json:"reserve_date_end" validate:"required"`
+}
+
+type ServiceRescheduleModel struct {
+	ID          string    `json:"reschedule_id"`
+	ServiceID   string    `json:"service_id"`
+	RequestedBy string    `json:"requested_by"`
+	OldStart    time.Time `json:"old_start"`
+	OldEnd      time.Time `json:"old_end"`
+	NewStart    time.Time `json:"new_start"`
+	NewEnd      time.Time `json:"new_end"`
+	OldPrice    int       `json:"old_price"`
+	NewPrice    int       `json:"new_price"`
+	// new_price - old_price: > 0 เก็บเพิ่ม, < 0 คืนเงิน
+	PriceDelta int                    `json:"price_delta"`
+	Status     db.RescheduleStatus    `json:"status"`
+	LineItems  []PaymentLineItemModel `json:"line_items,omitempty"`
+	// payment เก็บเงินส่วนต่าง (มีเฉพาะตอนราคาเพิ่ม)
+	AdjustmentPaymentID *string              `json:"adjustment_payment_id,omitempty"`
+	Refunds             []PaymentRefundModel `json:"refunds,omitempty"`
+	CreatedAt           time.Time            `json:"created_at"`
+
+	// booking เดิม ไม่ได้เก็บใน table นี้
+	PaymentID string `json:"-"`
+	OwnerID   string `json:"-"`
+	StaffID   string `json:"-"`
+}
+
+type ServiceStatusHistoryModel struct {
+	ID            string            `json:"id"`
+	ServiceID     string            `json:"service_id"`
+	FromStatus    *db.ServiceStatus `json:"from_status"`
+	ToStatus      db.ServiceStatus  `json:"to_status"`
+	ChangedBy     *string           `json:"changed_by"`
+	ChangedByRole *db.Role          `json:"changed_by_role"`
+	Note          *string           `json:"note,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+type ServiceReassignmentModel struct {
+	ID          string                `json:"id"`
+	ServiceID   string                `json:"service_id"`
+	ServiceType string                `json:"service_type"`
+	FromStaffID string                `json:"from_staff_id"`
+	ToStaffID   *string               `json:"to_staff_id,omitempty"`
+	Reason      db.ReassignmentReason `json:"reason"`
+	Status      db.ReassignmentStatus `json:"status"`
+	Note        *string               `json:"note,omitempty"`
+	CreatedBy   *string               `json:"created_by,omitempty"`
+	ResolvedBy  *string               `json:"resolved_by,omitempty"`
+	ResolvedAt  *time.Time            `json:"resolved_at,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+}
+
+type ReassignBookingsRequest struct {
+	// ช่วงวันที่ staff ไม่ว่าง (YYYY-MM-DD) ไม่ใส่ end_date = ทุก booking ตั้งแต่ start_date
+	StartDate string  `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   *string `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	// auto หา staff แทนตาม rating ที่หาไม่ได้ค่อย flag, flag ให้ admin จัดการเองทั้งหมด
+	Mode string  `json:"mode" validate:"omitempty,oneof=auto flag"`
+	Note *string `json:"note" validate:"omitempty,max=255"`
+}
+
+// ResolveReassignmentRequest: ใส่ staff_id = ย้ายไปหาคนนั้น, ไม่ใส่ = ปิดเรื่องเฉยๆ (เช่นยกเลิก booking ไปแล้ว)
+type ResolveReassignmentRequest struct {
+	StaffID *string `json:"staff_id" validate:"omitempty,uuid"`
+	Note    *string `json:"note" validate:"omitempty,max=255"`
+}
+
 type UpdateServiceRequest struct {
 	OwnerID          *string    `json:"owner_id,omitempty" validate:"omitempty,uuid4"`
 	PetID            *string    `json:"pet_id,omitempty" validate:"omitempty,uuid4"`
 	StaffID          *string    `json:"staff_id,omitempty" validate:"omitempty,uuid4"`
-	Status           *string    `json:"status,omitempty" validate:"omitempty,oneof=wait ongoing finish"`
+	Status           *string    `json:"status,omitempty" validate:"omitempty,oneof=wait ongoing finish no_show"`
 	ReserveDateStart *time.Time `json:"reserve_date_start,omitempty"`
 	ReserveDateEnd   *time.Time `json:"reserve_date_end,omitempty"`
 	Disease          *string    `json:"disease,omitempty" validate:"omitempty,min=1"`
@@ -67,3 +157,11 @@ type SubService struct {
 	Comment   *string `json:"comment,omitempty"`
 	Score     *int    `json:"score,omitempty"`
 }
+
+type StaffHoldModel struct {
+	StaffID          string    `json:"staff_id"`
+	PaymentID        string    `json:"payment_id"`
+	ReserveDateStart time.Time `json:"reserve_date_start"`
+	ReserveDateEnd   time.Time `json:"reserve_date_end"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
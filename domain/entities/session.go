@@ -0,0 +1,24 @@
+package entities
+
+import (
+	"lama-backend/domain/prisma/db"
+	"time"
+)
+
+type SessionModel struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	Role         db.Role    `json:"role"`
+	Device       string     `json:"device,omitempty"`
+	RefreshHash  string     `json:"-"`
+	PreviousHash string     `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   time.Time  `json:"last_used_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	MfaVerified  bool       `json:"mfa_verified"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
@@ -12,7 +12,7 @@ type UserDataModel struct {
 	UpdatedAt       time.Time  `json:"updated_at"`
 	Email           string     `json:"email"`
 	Password        string     `json:"password"`
-	Role            db.Role    `json:"role"`
+	Roles           []db.Role  `json:"roles"`
 	Name            string     `json:"name"`
 	BirthDate       time.Time  `json:"birth_date"`
 	TelephoneNumber string     `json:"telephone_number"`
@@ -38,8 +38,7 @@ type StaffCommonData struct {
 }
 
 type SendEmailModel struct {
-	Email string  `json:"email" validate:"required,email"`
-	Role  db.Role `json:"role" validate:"required"`
+	Email string `json:"email" validate:"required,email"`
 }
 
 type PasswordModel struct {
@@ -52,10 +51,11 @@ type LoginUserRequestModel struct {
 }
 
 type LoginUserResponseModel struct {
-	UserID   string  `json:"user_id"`
-	Email    string  `json:"email"`
-	Password string  `json:"password"`
-	Role     db.Role `json:"role"`
+	UserID   string    `json:"user_id"`
+	Email    string    `json:"email"`
+	Password string    `json:"password"`
+	Role     db.Role   `json:"role"` // role ตอนสมัคร
+	Roles    []db.Role `json:"roles"`
 }
 
 type CreatedUserModel struct {
@@ -69,6 +69,12 @@ type CreatedUserModel struct {
 	Specialization  string    `json:"specialization,omitempty"` // caretaker only (optional)
 }
 
+// ข้อมูลของ role ที่เพิ่มให้บัญชีเดิม ใช้เฉพาะช่องของ role นั้น
+type AddRoleRequest struct {
+	LicenseNumber  string `json:"license_number,omitempty"` // doctor only
+	Specialization string `json:"specialization,omitempty"` // caretaker only (optional)
+}
+
 type UpdateUserModel struct {
 	Email           *string     `json:"email,omitempty" validate:"omitempty,email"`
 	Password        *string     `json:"password,omitempty"`
@@ -92,4 +98,7 @@ type AvailableStaffResponse struct {
 	Profile string `json:"profile,omitempty"`
 	// BusyTimeSlot []int      `json:"busy_time_slot"`
 	Rating db.Decimal `json:"rating,omitempty"`
+	// เวลาทำงานใน profile ใช้ตอนเช็คตารางงาน
+	StartWorkTime time.Time `json:"-"`
+	EndWorkTime   time.Time `json:"-"`
 }
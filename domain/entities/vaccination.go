@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"lama-backend/domain/prisma/db"
+	"time"
+)
+
+type VaccinationModel struct {
+	ID          string             `json:"id"`
+	PetID       string             `json:"pet_id"`
+	ServiceID   *string            `json:"service_id,omitempty"`
+	DoctorID    *string            `json:"doctor_id,omitempty"`
+	Vaccine     string             `json:"vaccine"`
+	Kind        db.VaccinationKind `json:"kind"`
+	GivenDate   time.Time          `json:"given_date"`
+	NextDueDate *time.Time         `json:"next_due_date,omitempty"`
+	Note        *string            `json:"note,omitempty"`
+	Superseded  bool               `json:"superseded"`
+	RemindedAt  *time.Time         `json:"reminded_at,omitempty"`
+	CreatedBy   string             `json:"created_by"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// VaccinationDueModel is a dose coming due with who to remind about it.
+type VaccinationDueModel struct {
+	VaccinationModel
+	PetName    string `json:"pet_name"`
+	OwnerID    string `json:"owner_id"`
+	OwnerName  string `json:"owner_name"`
+	OwnerEmail string `json:"owner_email"`
+}
+
+type CreateVaccinationRequest struct {
+	Vaccine     string  `json:"vaccine" validate:"required,max=100"`
+	Kind        string  `json:"kind" validate:"omitempty,oneof=vaccine preventive"`
+	GivenDate   string  `json:"given_date" validate:"required,datetime=2006-01-02"`
+	NextDueDate *string `json:"next_due_date" validate:"omitempty,datetime=2006-01-02"`
+	// mservice ที่ฉีด ถ้ามี หมอที่ฉีดจะมาจาก service นั้น
+	ServiceID *string `json:"service_id" validate:"omitempty,uuid"`
+	// admin only, บันทึกย้อนหลังให้หมอ
+	DoctorID *string `json:"doctor_id" validate:"omitempty,uuid"`
+	Note     *string `json:"note" validate:"omitempty,max=500"`
+}
@@ -0,0 +1,78 @@
+-- รวมบัญชีที่อีเมลซ้ำกัน (สมัครแยกกันคนละ role) ให้เหลือบัญชีเดียวที่ถือหลาย role
+-- รันครั้งเดียวก่อน `prisma db push` ของ schema ที่ Users.email เป็น unique
+--
+--   psql "$DATABASE_URL" -v ON_ERROR_STOP=1 -f domain/prisma/merge_duplicate_users.sql
+--
+-- บัญชีที่เหลือคือแถวที่สมัครก่อนสุด ใช้รหัสผ่านของแถวที่แก้ล่าสุด
+-- ทุกคนในกลุ่มที่ถูกรวมต้อง login ใหม่ session กับลิงก์ reset ของแถวที่ถูกลบหายไปด้วย
+
+BEGIN;
+
+ALTER TABLE "Users" ADD COLUMN roles "role"[];
+UPDATE "Users" SET roles = ARRAY[role];
+
+-- old_id -> new_id ของทุกแถวที่จะถูกรวมเข้าแถวอื่น
+CREATE TEMP TABLE user_merge ON COMMIT DROP AS
+SELECT id AS old_id, first_value(id) OVER (PARTITION BY email ORDER BY show_id) AS new_id
+FROM "Users";
+DELETE FROM user_merge WHERE old_id = new_id;
+
+UPDATE "Users" u
+SET roles = merged.roles, password = merged.password, updated_at = now()
+FROM (
+    SELECT email,
+           array_agg(role ORDER BY show_id) AS roles,
+           (array_agg(password ORDER BY updated_at DESC))[1] AS password
+    FROM "Users"
+    GROUP BY email
+    HAVING count(*) > 1
+) merged
+WHERE u.email = merged.email
+  AND u.id IN (SELECT new_id FROM user_merge);
+
+-- แถวของ role ย้ายไปบัญชีที่เหลือ Pet/Service/Payment/Cservice/Mservice/Leaveday/Vaccination
+-- ตามไปเองเพราะ foreign key เป็น ON UPDATE CASCADE
+UPDATE "Owner" t SET user_id = m.new_id FROM user_merge m
+WHERE t.user_id = m.old_id AND NOT EXISTS (SELECT 1 FROM "Owner" x WHERE x.user_id = m.new_id);
+UPDATE "Caretaker" t SET user_id = m.new_id FROM user_merge m
+WHERE t.user_id = m.old_id AND NOT EXISTS (SELECT 1 FROM "Caretaker" x WHERE x.user_id = m.new_id);
+UPDATE "Doctor" t SET user_id = m.new_id FROM user_merge m
+WHERE t.user_id = m.old_id AND NOT EXISTS (SELECT 1 FROM "Doctor" x WHERE x.user_id = m.new_id);
+
+-- คอลัมน์ที่เก็บ id ของ user ไว้เฉยๆ ไม่มี relation ต้องแก้เอง
+UPDATE "ServiceCancellation" t SET cancelled_by = m.new_id FROM user_merge m WHERE t.cancelled_by = m.old_id;
+UPDATE "ServiceReschedule" t SET requested_by = m.new_id FROM user_merge m WHERE t.requested_by = m.old_id;
+UPDATE "ServiceStatusHistory" t SET changed_by = m.new_id FROM user_merge m WHERE t.changed_by = m.old_id;
+UPDATE "MedicalRecord" t SET "DID" = m.new_id FROM user_merge m WHERE t."DID" = m.old_id;
+UPDATE "PetWeight" t SET recorded_by = m.new_id FROM user_merge m WHERE t.recorded_by = m.old_id;
+UPDATE "Attachment" t SET uploaded_by = m.new_id FROM user_merge m WHERE t.uploaded_by = m.old_id;
+UPDATE "Vaccination" t SET created_by = m.new_id FROM user_merge m WHERE t.created_by = m.old_id;
+UPDATE "ServiceReassignment" t SET from_staff_id = m.new_id FROM user_merge m WHERE t.from_staff_id = m.old_id;
+UPDATE "ServiceReassignment" t SET to_staff_id = m.new_id FROM user_merge m WHERE t.to_staff_id = m.old_id;
+UPDATE "ServiceReassignment" t SET created_by = m.new_id FROM user_merge m WHERE t.created_by = m.old_id;
+UPDATE "ServiceReassignment" t SET resolved_by = m.new_id FROM user_merge m WHERE t.resolved_by = m.old_id;
+UPDATE "Leaveday" t SET reviewed_by = m.new_id FROM user_merge m WHERE t.reviewed_by = m.old_id;
+UPDATE "StaffHold" t SET staff_id = m.new_id FROM user_merge m WHERE t.staff_id = m.old_id;
+UPDATE "StaffScheduleException" t SET staff_id = m.new_id FROM user_merge m WHERE t.staff_id = m.old_id;
+UPDATE "StaffScheduleException" t SET created_by = m.new_id FROM user_merge m WHERE t.created_by = m.old_id;
+
+-- ตารางงานกับค่าแรงมีได้ชุดเดียวต่อคน ถ้าบัญชีที่เหลือมีอยู่แล้วใช้ของบัญชีที่เหลือ
+DELETE FROM "StaffShift" t USING user_merge m
+WHERE t.staff_id = m.old_id AND EXISTS (SELECT 1 FROM "StaffShift" x WHERE x.staff_id = m.new_id);
+UPDATE "StaffShift" t SET staff_id = m.new_id FROM user_merge m WHERE t.staff_id = m.old_id;
+DELETE FROM "StaffRate" t USING user_merge m
+WHERE t.staff_id = m.old_id AND EXISTS (SELECT 1 FROM "StaffRate" x WHERE x.staff_id = m.new_id);
+UPDATE "StaffRate" t SET staff_id = m.new_id FROM user_merge m WHERE t.staff_id = m.old_id;
+
+-- Session กับ PasswordReset ของแถวเก่าลบตามไปด้วย cascade
+DELETE FROM "Users" WHERE id IN (SELECT old_id FROM user_merge);
+
+-- ให้ตรงกับ schema.prisma แล้ว db push จะไม่ต้องแก้อะไรอีก
+DROP INDEX IF EXISTS "Users_email_role_key";
+DROP INDEX IF EXISTS "Users_telephone_number_role_key";
+DROP INDEX IF EXISTS "Users_email_idx";
+ALTER TABLE "Users" DROP COLUMN role;
+CREATE UNIQUE INDEX "Users_email_key" ON "Users"(email);
+CREATE INDEX "Users_telephone_number_idx" ON "Users"(telephone_number);
+
+COMMIT;
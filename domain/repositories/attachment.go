@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+type attachmentRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IAttachmentRepository interface {
+	Insert(data entities.AttachmentModel) (*entities.AttachmentModel, error)
+	FindByID(attachmentID string) (*entities.AttachmentModel, error)
+	FindByPetID(petID string, kind *db.AttachmentKind) ([]*entities.AttachmentModel, error)
+	FindByServiceID(serviceID string) ([]*entities.AttachmentModel, error)
+	Delete(attachmentID string) error
+}
+
+func NewAttachmentRepository(db *ds.PrismaDB) IAttachmentRepository {
+	return &attachmentRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+func (repo *attachmentRepository) Insert(data entities.AttachmentModel) (*entities.AttachmentModel, error) {
+	params := []db.AttachmentSetParam{}
+	if data.ServiceID != nil {
+		params = append(params, db.Attachment.Service.Link(db.Service.Sid.Equals(*data.ServiceID)))
+	}
+
+	created, err := repo.Collection.Attachment.CreateOne(
+		db.Attachment.Kind.Set(data.Kind),
+		db.Attachment.FileName.Set(data.FileName),
+		db.Attachment.ContentType.Set(data.ContentType),
+		db.Attachment.Size.Set(data.Size),
+		db.Attachment.StorageKey.Set(data.StorageKey),
+		db.Attachment.UploadedBy.Set(data.UploadedBy),
+		db.Attachment.Pet.Link(db.Pet.Petid.Equals(data.PetID)),
+		params...,
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("attachment -> Insert: %v", err)
+	}
+
+	return mapAttachmentModel(created), nil
+}
+
+func (repo *attachmentRepository) FindByID(attachmentID string) (*entities.AttachmentModel, error) {
+	attachment, err := repo.Collection.Attachment.FindUnique(
+		db.Attachment.ID.Equals(attachmentID),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("attachment -> FindByID: %w", err)
+	}
+
+	return mapAttachmentModel(attachment), nil
+}
+
+// FindByPetID returns the files of the pet, including the ones attached to its services,
+// newest first.
+func (repo *attachmentRepository) FindByPetID(petID string, kind *db.AttachmentKind) ([]*entities.AttachmentModel, error) {
+	params := []db.AttachmentWhereParam{
+		db.Attachment.Petid.Equals(petID),
+	}
+	if kind != nil {
+		params = append(params, db.Attachment.Kind.Equals(*kind))
+	}
+
+	rows, err := repo.Collection.Attachment.FindMany(params...).OrderBy(
+		db.Attachment.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("attachment -> FindByPetID: %v", err)
+	}
+
+	return mapAttachmentModels(rows), nil
+}
+
+func (repo *attachmentRepository) FindByServiceID(serviceID string) ([]*entities.AttachmentModel, error) {
+	rows, err := repo.Collection.Attachment.FindMany(
+		db.Attachment.Sid.Equals(serviceID),
+	).OrderBy(
+		db.Attachment.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("attachment -> FindByServiceID: %v", err)
+	}
+
+	return mapAttachmentModels(rows), nil
+}
+
+func (repo *attachmentRepository) Delete(attachmentID string) error {
+	_, err := repo.Collection.Attachment.FindUnique(
+		db.Attachment.ID.Equals(attachmentID),
+	).Delete().Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("attachment -> Delete: %w", err)
+	}
+
+	return nil
+}
+
+func mapAttachmentModels(rows []db.AttachmentModel) []*entities.AttachmentModel {
+	results := make([]*entities.AttachmentModel, 0, len(rows))
+	for i := range rows {
+		results = append(results, mapAttachmentModel(&rows[i]))
+	}
+	return results
+}
+
+func mapAttachmentModel(model *db.AttachmentModel) *entities.AttachmentModel {
+	result := &entities.AttachmentModel{
+		ID:          model.ID,
+		PetID:       model.Petid,
+		Kind:        model.Kind,
+		FileName:    model.FileName,
+		ContentType: model.ContentType,
+		Size:        model.Size,
+		StorageKey:  model.StorageKey,
+		UploadedBy:  model.UploadedBy,
+		CreatedAt:   model.CreatedAt,
+	}
+	if serviceID, ok := model.Sid(); ok {
+		result.ServiceID = &serviceID
+	}
+
+	return result
+}
@@ -143,13 +143,14 @@ func (repo *caretakerRepository) FindAvailableCaretaker(startDate, endDate time.
 	// find Rend < start or Rstart > end
 	caretakers, err := repo.Collection.Caretaker.FindMany(
 		db.Caretaker.Leaveday.None(
+			db.Leaveday.Status.In(activeLeaveStatuses),
 			db.Leaveday.Leaveday.Gte(startDate),
 			db.Leaveday.Leaveday.Lte(endDate),
 		),
 		db.Caretaker.Cservice.None(
 			db.Cservice.Service.Where(
 				db.Service.And(
-					db.Service.Status.Not("finish"),
+					db.Service.Status.NotIn([]db.ServiceStatus{db.ServiceStatusFinish, db.ServiceStatusCancelled, db.ServiceStatusNoShow}),
 					db.Service.And(
 						db.Service.RdateStart.Lte(endDate),
 						db.Service.RdateEnd.Gte(startDate),
@@ -180,6 +181,9 @@ func (repo *caretakerRepository) FindAvailableCaretaker(startDate, endDate time.
 			Name:    user.Name,
 			Profile: profile,
 			Rating:  rating,
+
+			StartWorkTime: c.StartWorkingTime,
+			EndWorkTime:   c.EndWorkingTime,
 		}
 
 		results = append(results, &entity)
@@ -193,6 +197,7 @@ func (repo *caretakerRepository) FindBusyTimeSlot(staffID string, startDate00, s
 		db.Service.Cservice.Where(
 			db.Cservice.Cid.Equals(staffID),
 		),
+		db.Service.Status.Not(db.ServiceStatusCancelled),
 		db.Service.Or(
 			db.Service.And(
 				db.Service.RdateStart.Gt(endDate00),
@@ -16,6 +16,9 @@ type cserviceRepository struct {
 
 type ICServiceRepository interface {
 	Insert(data entities.SubService) (*entities.SubService, error)
+	InsertTx(tx *Tx, data entities.SubService)
+	UpdateStaffTx(tx *Tx, serviceID, staffID string)
+	UpdateReviewTx(tx *Tx, serviceID string, comment *string, score *int)
 	FindByID(serviceID string) (*entities.SubService, error)
 	DeleteByID(serviceID string) (*entities.SubService, error)
 	UpdateByID(data entities.SubService) (*entities.SubService, error)
@@ -42,6 +45,38 @@ func (repo *cserviceRepository) Insert(data entities.SubService) (*entities.SubS
 	return mapCserviceToSubService(createdCService), nil
 }
 
+func (repo *cserviceRepository) InsertTx(tx *Tx, data entities.SubService) {
+	tx.add(repo.Collection.Cservice.CreateOne(
+		db.Cservice.Score.Set(0),
+		db.Cservice.Caretaker.Link(db.Caretaker.UserID.Equals(data.StaffID)),
+		db.Cservice.Service.Link(db.Service.Sid.Equals(data.ServiceID)),
+	).Tx())
+}
+
+func (repo *cserviceRepository) UpdateStaffTx(tx *Tx, serviceID, staffID string) {
+	tx.add(repo.Collection.Cservice.FindUnique(
+		db.Cservice.Sid.Equals(serviceID),
+	).Update(
+		db.Cservice.Caretaker.Link(db.Caretaker.UserID.Equals(staffID)),
+	).Tx())
+}
+
+func (repo *cserviceRepository) UpdateReviewTx(tx *Tx, serviceID string, comment *string, score *int) {
+	updates := []db.CserviceSetParam{}
+	if comment != nil {
+		updates = append(updates, db.Cservice.Comment.Set(*comment))
+	}
+	if score != nil {
+		updates = append(updates, db.Cservice.Score.Set(*score))
+	}
+	if len(updates) == 0 {
+		return
+	}
+	tx.add(repo.Collection.Cservice.FindUnique(
+		db.Cservice.Sid.Equals(serviceID),
+	).Update(updates...).Tx())
+}
+
 func (repo *cserviceRepository) FindByID(serviceID string) (*entities.SubService, error) {
 	cservice, err := repo.Collection.Cservice.FindUnique(
 		db.Cservice.Sid.Equals(serviceID),
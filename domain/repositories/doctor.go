@@ -134,13 +134,14 @@ func (repo *doctorRepository) UpdateByID(userID string, data entities.UpdateUser
 func (repo *doctorRepository) FindAvailableDoctor(startDate, endDate time.Time) ([]*entities.AvailableStaffResponse, error) {
 	doctors, err := repo.Collection.Doctor.FindMany(
 		db.Doctor.Leaveday.None(
+			db.Leaveday.Status.In(activeLeaveStatuses),
 			db.Leaveday.Leaveday.Gte(startDate),
 			db.Leaveday.Leaveday.Lte(endDate),
 		),
 		db.Doctor.Mservice.None(
 			db.Mservice.Service.Where(
 				db.Service.And(
-					db.Service.Status.Not("finish"),
+					db.Service.Status.NotIn([]db.ServiceStatus{db.ServiceStatusFinish, db.ServiceStatusCancelled, db.ServiceStatusNoShow}),
 					db.Service.And(
 						db.Service.RdateStart.Lte(endDate),
 						db.Service.RdateEnd.Gte(startDate),
@@ -167,6 +168,9 @@ func (repo *doctorRepository) FindAvailableDoctor(startDate, endDate time.Time)
 			ID:      d.UserID,
 			Name:    user.Name,
 			Profile: profile,
+
+			StartWorkTime: d.StartWorkingTime,
+			EndWorkTime:   d.EndWorkingTime,
 		}
 
 		results = append(results, &entity)
@@ -180,6 +184,7 @@ func (repo *doctorRepository) FindBusyTimeSlot(staffID string, startDate00, star
 		db.Service.Mservice.Where(
 			db.Mservice.Did.Equals(staffID),
 		),
+		db.Service.Status.Not(db.ServiceStatusCancelled),
 		db.Service.Or(
 			db.Service.And(
 				db.Service.RdateStart.Gt(endDate00),
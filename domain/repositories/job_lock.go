@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/prisma/db"
+	"strings"
+)
+
+// ErrJobLocked is returned by Commit when another instance is already running the job.
+var ErrJobLocked = errors.New("job is already running on another instance")
+
+const jobLockedMarker = "job_locked"
+
+type jobLockRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IJobLockRepository interface {
+	TryLockTx(tx *Tx, jobName string)
+}
+
+func NewJobLockRepository(db *ds.PrismaDB) IJobLockRepository {
+	return &jobLockRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+// TryLockTx must be the first query of the tx. The advisory lock is held until the
+// tx ends, so the job's writes are applied by one instance at a time; if another
+// instance holds it the whole tx aborts instead of waiting.
+func (repo *jobLockRepository) TryLockTx(tx *Tx, jobName string) {
+	tx.add(repo.Collection.Prisma.ExecuteRaw(fmt.Sprintf(`
+		SELECT CAST(
+			CASE WHEN pg_try_advisory_xact_lock(hashtext($1)) THEN '0' ELSE '%s' END
+		AS INTEGER)`, jobLockedMarker),
+		"job:"+jobName,
+	).Tx())
+}
+
+func isJobLockedErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), jobLockedMarker)
+}
@@ -2,25 +2,42 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	ds "lama-backend/domain/datasources"
 	"lama-backend/domain/entities"
 	"lama-backend/domain/prisma/db"
+	"strings"
 	"time"
 
 	"fmt"
 )
 
+var (
+	// ErrLeaveHasBookings: มี booking หรือ hold อยู่ในวันที่ขอลา
+	ErrLeaveHasBookings = errors.New("staff has bookings on the requested leave days")
+	// ErrLeaveOverlap: มีวันลาที่ยัง pending/approved ซ้ำอยู่แล้ว
+	ErrLeaveOverlap = errors.New("leave overlaps another leave request")
+)
+
+const (
+	leaveHasBookingsMarker = "leave_has_bookings"
+	leaveOverlapMarker     = "leave_overlap"
+)
+
+// วันลาที่ยังมีผล ใช้ทั้งตอนเช็ค availability และตอนกันขอลาซ้ำ
+var activeLeaveStatuses = []db.LeaveStatus{db.LeaveStatusPending, db.LeaveStatusApproved}
+
 type leavedayRepository struct {
 	Context    context.Context
 	Collection *db.PrismaClient
 }
 
 type ILeavedayRepository interface {
-	InsertCaretakerLeaveday(cid string, leaveday time.Time) (*entities.LeavedayModel, error)
-	InsertDoctorLeaveday(did string, leaveday time.Time) (*entities.LeavedayModel, error)
-	FindByCaretakerID(cid string) (*entities.LeavedayModel, error)
-	FindByDoctorID(did string) (*entities.LeavedayModel, error)
-	FindByLeaveday(leaveday time.Time) (*[]entities.LeavedayModel, error)
+	GuardRangeTx(tx *Tx, staffID string, startDate, endDate, from, to time.Time)
+	InsertRequestTx(tx *Tx, request entities.LeaveRequestModel)
+	UpdateStatusTx(tx *Tx, requestID string, from []db.LeaveStatus, to db.LeaveStatus, reviewedBy *string, reviewedAt *time.Time)
+	FindRequestByID(requestID string) (*entities.LeaveRequestModel, error)
+	FindRequests(filter entities.LeaveFilter) ([]*entities.LeaveRequestModel, error)
 }
 
 func NewLeavedayRepository(db *ds.PrismaDB) ILeavedayRepository {
@@ -30,117 +47,189 @@ func NewLeavedayRepository(db *ds.PrismaDB) ILeavedayRepository {
 	}
 }
 
-func (repo *leavedayRepository) InsertCaretakerLeaveday(cid string, leaveday time.Time) (*entities.LeavedayModel, error) {
-	createdData, err := repo.Collection.Leaveday.CreateOne(
-		db.Leaveday.Leaveday.Set(leaveday),
-		db.Leaveday.Caretaker.Link(db.Caretaker.UserID.Equals(cid)),
-	).Exec(repo.Context)
+// GuardRangeTx takes the same per-staff lock as StaffHold.GuardSlotTx, then fails the
+// transaction if the days already have bookings, holds or active leave. startDate..endDate
+// are the leave dates, from..to the same days as a time range in business time.
+func (repo *leavedayRepository) GuardRangeTx(tx *Tx, staffID string, startDate, endDate, from, to time.Time) {
+	tx.add(
+		repo.Collection.Prisma.ExecuteRaw(`SELECT pg_advisory_xact_lock(hashtext($1))`, staffID).Tx(),
+		repo.Collection.Prisma.ExecuteRaw(fmt.Sprintf(`
+			SELECT CAST(
+				CASE WHEN EXISTS (
+					SELECT 1 FROM "Service" s
+					LEFT JOIN "Cservice" c ON c."SID" = s."SID"
+					LEFT JOIN "Mservice" m ON m."SID" = s."SID"
+					WHERE (c."CID" = $1::uuid OR m."DID" = $1::uuid)
+					  AND s.status NOT IN ('finish', 'cancelled', 'no_show')
+					  AND s.rdate_start < $3::timestamptz AND s.rdate_end > $2::timestamptz
+					UNION ALL
+					SELECT 1 FROM "StaffHold" h
+					WHERE h.staff_id = $1::uuid
+					  AND h.expires_at > now()
+					  AND h.rdate_start < $3::timestamptz AND h.rdate_end > $2::timestamptz
+				) THEN '%s' ELSE '0' END
+			AS INTEGER)`, leaveHasBookingsMarker),
+			staffID, from, to,
+		).Tx(),
+		repo.Collection.Prisma.ExecuteRaw(fmt.Sprintf(`
+			SELECT CAST(
+				CASE WHEN EXISTS (
+					SELECT 1 FROM "Leaveday" l
+					WHERE (l."CID" = $1::uuid OR l."DID" = $1::uuid)
+					  AND l.status IN ('pending', 'approved')
+					  AND l.leaveday >= $2::date AND l.leaveday <= $3::date
+				) THEN '%s' ELSE '0' END
+			AS INTEGER)`, leaveOverlapMarker),
+			staffID, startDate, endDate,
+		).Tx(),
+	)
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("leaveday -> InsertLeaveday: %v", err)
-	}
+// InsertRequestTx writes one row per day of the request.
+func (repo *leavedayRepository) InsertRequestTx(tx *Tx, request entities.LeaveRequestModel) {
+	for _, day := range request.Days {
+		optional := []db.LeavedaySetParam{
+			db.Leaveday.RequestID.Set(request.RequestID),
+			db.Leaveday.Status.Set(request.Status),
+			db.Leaveday.Reason.SetIfPresent(request.Reason),
+		}
+		switch request.StaffType {
+		case "caretaker":
+			optional = append(optional, db.Leaveday.Caretaker.Link(db.Caretaker.UserID.Equals(request.StaffID)))
+		case "doctor":
+			optional = append(optional, db.Leaveday.Doctor.Link(db.Doctor.UserID.Equals(request.StaffID)))
+		}
 
-	staffId, ok := createdData.Cid()
-	if !ok {
-		return nil, fmt.Errorf("leaveday -> InsertLeaveday: cannot insert/get caretaker_id")
+		tx.add(repo.Collection.Leaveday.CreateOne(
+			db.Leaveday.Leaveday.Set(day),
+			optional...,
+		).Tx())
 	}
+}
 
-	return &entities.LeavedayModel{
-		StaffID:   staffId,
-		StaffType: "caretaker",
-		Leaveday:  []db.DateTime{createdData.Leaveday},
-	}, nil
+// UpdateStatusTx moves every day of the request that is still in one of from.
+func (repo *leavedayRepository) UpdateStatusTx(tx *Tx, requestID string, from []db.LeaveStatus, to db.LeaveStatus, reviewedBy *string, reviewedAt *time.Time) {
+	tx.add(repo.Collection.Leaveday.FindMany(
+		db.Leaveday.RequestID.Equals(requestID),
+		db.Leaveday.Status.In(from),
+	).Update(
+		db.Leaveday.Status.Set(to),
+		db.Leaveday.ReviewedBy.SetIfPresent(reviewedBy),
+		db.Leaveday.ReviewedAt.SetIfPresent(reviewedAt),
+	).Tx())
 }
 
-func (repo *leavedayRepository) InsertDoctorLeaveday(did string, leaveday time.Time) (*entities.LeavedayModel, error) {
-	createdData, err := repo.Collection.Leaveday.CreateOne(
-		db.Leaveday.Leaveday.Set(leaveday),
-		db.Leaveday.Doctor.Link(db.Doctor.UserID.Equals(did)),
+func (repo *leavedayRepository) FindRequestByID(requestID string) (*entities.LeaveRequestModel, error) {
+	rows, err := repo.Collection.Leaveday.FindMany(
+		db.Leaveday.RequestID.Equals(requestID),
+	).OrderBy(
+		db.Leaveday.Leaveday.Order(db.SortOrderAsc),
 	).Exec(repo.Context)
-
 	if err != nil {
-		return nil, fmt.Errorf("leaveday -> InsertLeaveday: %v", err)
+		return nil, fmt.Errorf("leaveday -> FindRequestByID: %v", err)
 	}
-
-	staffId, ok := createdData.Did()
-	if !ok {
-		return nil, fmt.Errorf("leaveday -> InsertLeaveday: cannot insert/get doctor_id")
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("leaveday -> FindRequestByID: %w", db.ErrNotFound)
 	}
 
-	return &entities.LeavedayModel{
-		StaffID:   staffId,
-		StaffType: "doctor",
-		Leaveday:  []db.DateTime{createdData.Leaveday},
-	}, nil
+	return groupLeaveRequests(rows)[0], nil
 }
 
-func (repo *leavedayRepository) FindByCaretakerID(cid string) (*entities.LeavedayModel, error) {
-	data, err := repo.Collection.Leaveday.FindMany(
-		db.Leaveday.Cid.Equals(cid),
-	).Exec(repo.Context)
+// FindRequests returns the requests that have at least one day matching the filter,
+// with all of their days.
+func (repo *leavedayRepository) FindRequests(filter entities.LeaveFilter) ([]*entities.LeaveRequestModel, error) {
+	where := []db.LeavedayWhereParam{}
+	if filter.StaffID != nil {
+		where = append(where, db.Leaveday.Or(
+			db.Leaveday.Cid.Equals(*filter.StaffID),
+			db.Leaveday.Did.Equals(*filter.StaffID),
+		))
+	}
+	if filter.Status != nil {
+		where = append(where, db.Leaveday.Status.Equals(*filter.Status))
+	}
+	if filter.From != nil {
+		where = append(where, db.Leaveday.Leaveday.Gte(*filter.From))
+	}
+	if filter.To != nil {
+		where = append(where, db.Leaveday.Leaveday.Lte(*filter.To))
+	}
 
+	matched, err := repo.Collection.Leaveday.FindMany(where...).Select(
+		db.Leaveday.RequestID.Field(),
+	).Exec(repo.Context)
 	if err != nil {
-		return nil, fmt.Errorf("leaveday -> FindLeavedayByStaffID: %v", err)
+		return nil, fmt.Errorf("leaveday -> FindRequests: %v", err)
 	}
-
-	var leavedays []db.DateTime
-	for i := range data {
-		leavedays = append(leavedays, data[i].Leaveday)
+	if len(matched) == 0 {
+		return []*entities.LeaveRequestModel{}, nil
+	}
+	requestIDs := make([]string, 0, len(matched))
+	for i := range matched {
+		requestIDs = append(requestIDs, matched[i].RequestID)
 	}
 
-	return &entities.LeavedayModel{
-		StaffID:   cid,
-		StaffType: "caretaker",
-		Leaveday:  leavedays,
-	}, nil
-}
-
-func (repo *leavedayRepository) FindByDoctorID(did string) (*entities.LeavedayModel, error) {
-	data, err := repo.Collection.Leaveday.FindMany(
-		db.Leaveday.Did.Equals(did),
+	rows, err := repo.Collection.Leaveday.FindMany(
+		db.Leaveday.RequestID.In(requestIDs),
+	).OrderBy(
+		db.Leaveday.Leaveday.Order(db.SortOrderAsc),
 	).Exec(repo.Context)
-
 	if err != nil {
-		return nil, fmt.Errorf("leaveday -> FindLeavedayByStaffID: %v", err)
+		return nil, fmt.Errorf("leaveday -> FindRequests: %v", err)
 	}
 
-	var leavedays []db.DateTime
-	for i := range data {
-		leavedays = append(leavedays, data[i].Leaveday)
+	return groupLeaveRequests(rows), nil
+}
+
+// rows must be ordered by leaveday; requests come out in the order of their first day.
+func groupLeaveRequests(rows []db.LeavedayModel) []*entities.LeaveRequestModel {
+	requests := []*entities.LeaveRequestModel{}
+	byID := make(map[string]*entities.LeaveRequestModel)
+	for i := range rows {
+		row := &rows[i]
+		request, ok := byID[row.RequestID]
+		if !ok {
+			request = mapLeaveRequestModel(row)
+			byID[row.RequestID] = request
+			requests = append(requests, request)
+		}
+		request.Days = append(request.Days, row.Leaveday)
+		request.EndDate = row.Leaveday
 	}
 
-	return &entities.LeavedayModel{
-		StaffID:   did,
-		StaffType: "doctor",
-		Leaveday:  leavedays,
-	}, nil
+	return requests
 }
 
-func (repo *leavedayRepository) FindByLeaveday(leaveday time.Time) (*[]entities.LeavedayModel, error) {
-	data, err := repo.Collection.Leaveday.FindMany(
-		db.Leaveday.Leaveday.Equals(leaveday),
-	).Exec(repo.Context)
-
-	if err != nil {
-		return nil, fmt.Errorf("leaveday -> FindLeavedayByStaffID: %v", err)
-	}
-
-	var results []entities.LeavedayModel
-	for i := range data {
-		var staffId, staffType string
-		var ok bool
-		if staffId, ok = data[i].Cid(); !ok {
-			staffId, _ = data[i].Did()
-			staffType = "doctor"
-		} else {
-			staffType = "caretaker"
-		}
-		results = append(results, entities.LeavedayModel{
-			StaffID:   staffId,
-			StaffType: staffType,
-			Leaveday:  []db.DateTime{data[i].Leaveday},
-		})
+func mapLeaveRequestModel(model *db.LeavedayModel) *entities.LeaveRequestModel {
+	result := &entities.LeaveRequestModel{
+		RequestID: model.RequestID,
+		StartDate: model.Leaveday,
+		EndDate:   model.Leaveday,
+		Status:    model.Status,
+		CreatedAt: model.CreatedAt,
+	}
+	if cid, ok := model.Cid(); ok {
+		result.StaffID, result.StaffType = cid, "caretaker"
+	} else if did, ok := model.Did(); ok {
+		result.StaffID, result.StaffType = did, "doctor"
+	}
+	if reason, ok := model.Reason(); ok {
+		result.Reason = &reason
+	}
+	if reviewedBy, ok := model.ReviewedBy(); ok {
+		result.ReviewedBy = &reviewedBy
 	}
+	if reviewedAt, ok := model.ReviewedAt(); ok {
+		result.ReviewedAt = &reviewedAt
+	}
+
+	return result
+}
+
+func isLeaveHasBookingsErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), leaveHasBookingsMarker)
+}
 
-	return &results, nil
+func isLeaveOverlapErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), leaveOverlapMarker)
 }
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+type medicalRecordRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IMedicalRecordRepository interface {
+	UpsertTx(tx *Tx, record entities.MedicalRecordModel)
+	FindByServiceID(serviceID string) (*entities.MedicalRecordModel, error)
+	FindByPetID(petID string) ([]*entities.MedicalRecordModel, error)
+}
+
+func NewMedicalRecordRepository(db *ds.PrismaDB) IMedicalRecordRepository {
+	return &medicalRecordRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+// UpsertTx writes the record of the visit, one per service.
+func (repo *medicalRecordRepository) UpsertTx(tx *Tx, record entities.MedicalRecordModel) {
+	tx.add(repo.Collection.MedicalRecord.UpsertOne(
+		db.MedicalRecord.Sid.Equals(record.ServiceID),
+	).Create(
+		db.MedicalRecord.Did.Set(record.DoctorID),
+		db.MedicalRecord.Diagnosis.Set(record.Diagnosis),
+		db.MedicalRecord.Service.Link(db.Service.Sid.Equals(record.ServiceID)),
+		db.MedicalRecord.Pet.Link(db.Pet.Petid.Equals(record.PetID)),
+		db.MedicalRecord.Symptoms.Set(record.Symptoms),
+		db.MedicalRecord.Weight.SetIfPresent(record.Weight),
+		db.MedicalRecord.Temperature.SetIfPresent(record.Temperature),
+		db.MedicalRecord.FollowUp.SetIfPresent(record.FollowUp),
+		db.MedicalRecord.FollowUpDate.SetIfPresent(record.FollowUpDate),
+	).Update(
+		db.MedicalRecord.Diagnosis.Set(record.Diagnosis),
+		db.MedicalRecord.Symptoms.Set(record.Symptoms),
+		db.MedicalRecord.Weight.SetOptional(record.Weight),
+		db.MedicalRecord.Temperature.SetOptional(record.Temperature),
+		db.MedicalRecord.FollowUp.SetOptional(record.FollowUp),
+		db.MedicalRecord.FollowUpDate.SetOptional(record.FollowUpDate),
+	).Tx())
+}
+
+func (repo *medicalRecordRepository) FindByServiceID(serviceID string) (*entities.MedicalRecordModel, error) {
+	record, err := repo.Collection.MedicalRecord.FindUnique(
+		db.MedicalRecord.Sid.Equals(serviceID),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("medical record -> FindByServiceID: %w", err)
+	}
+
+	return mapMedicalRecordModel(record), nil
+}
+
+// FindByPetID returns the records of the pet, newest visit first.
+func (repo *medicalRecordRepository) FindByPetID(petID string) ([]*entities.MedicalRecordModel, error) {
+	rows, err := repo.Collection.MedicalRecord.FindMany(
+		db.MedicalRecord.Petid.Equals(petID),
+	).OrderBy(
+		db.MedicalRecord.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("medical record -> FindByPetID: %v", err)
+	}
+
+	records := make([]*entities.MedicalRecordModel, 0, len(rows))
+	for i := range rows {
+		records = append(records, mapMedicalRecordModel(&rows[i]))
+	}
+	return records, nil
+}
+
+func mapMedicalRecordModel(model *db.MedicalRecordModel) *entities.MedicalRecordModel {
+	result := &entities.MedicalRecordModel{
+		ID:        model.ID,
+		ServiceID: model.Sid,
+		PetID:     model.Petid,
+		DoctorID:  model.Did,
+		Diagnosis: model.Diagnosis,
+		Symptoms:  model.Symptoms,
+		Medicines: []entities.MedicineModel{},
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+	if result.Symptoms == nil {
+		result.Symptoms = []string{}
+	}
+	if weight, ok := model.Weight(); ok {
+		result.Weight = &weight
+	}
+	if temperature, ok := model.Temperature(); ok {
+		result.Temperature = &temperature
+	}
+	if followUp, ok := model.FollowUp(); ok {
+		result.FollowUp = &followUp
+	}
+	if followUpDate, ok := model.FollowUpDate(); ok {
+		result.FollowUpDate = &followUpDate
+	}
+
+	return result
+}
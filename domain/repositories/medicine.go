@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+type medicineRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IMedicineRepository interface {
+	ReplaceTx(tx *Tx, serviceID string, medicines []entities.MedicineModel)
+	FindByServiceID(serviceID string) ([]entities.MedicineModel, error)
+	FindByServiceIDs(serviceIDs []string) (map[string][]entities.MedicineModel, error)
+}
+
+func NewMedicineRepository(db *ds.PrismaDB) IMedicineRepository {
+	return &medicineRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+// ReplaceTx swaps the prescription of the visit for medicines.
+func (repo *medicineRepository) ReplaceTx(tx *Tx, serviceID string, medicines []entities.MedicineModel) {
+	tx.add(repo.Collection.Medicine.FindMany(
+		db.Medicine.Sid.Equals(serviceID),
+	).Delete().Tx())
+
+	for _, medicine := range medicines {
+		tx.add(repo.Collection.Medicine.CreateOne(
+			db.Medicine.Mediname.Set(medicine.Name),
+			db.Medicine.Service.Link(db.Service.Sid.Equals(serviceID)),
+			db.Medicine.Dosage.SetIfPresent(medicine.Dosage),
+			db.Medicine.Frequency.SetIfPresent(medicine.Frequency),
+			db.Medicine.DurationDays.SetIfPresent(medicine.DurationDays),
+			db.Medicine.Instructions.SetIfPresent(medicine.Instructions),
+		).Tx())
+	}
+}
+
+func (repo *medicineRepository) FindByServiceID(serviceID string) ([]entities.MedicineModel, error) {
+	byService, err := repo.FindByServiceIDs([]string{serviceID})
+	if err != nil {
+		return nil, err
+	}
+
+	return byService[serviceID], nil
+}
+
+func (repo *medicineRepository) FindByServiceIDs(serviceIDs []string) (map[string][]entities.MedicineModel, error) {
+	rows, err := repo.Collection.Medicine.FindMany(
+		db.Medicine.Sid.In(serviceIDs),
+	).OrderBy(
+		db.Medicine.Mediname.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("medicine -> FindByServiceIDs: %v", err)
+	}
+
+	result := make(map[string][]entities.MedicineModel)
+	for i := range rows {
+		result[rows[i].Sid] = append(result[rows[i].Sid], mapMedicineModel(&rows[i]))
+	}
+	return result, nil
+}
+
+func mapMedicineModel(model *db.MedicineModel) entities.MedicineModel {
+	result := entities.MedicineModel{Name: model.Mediname}
+	if dosage, ok := model.Dosage(); ok {
+		result.Dosage = &dosage
+	}
+	if frequency, ok := model.Frequency(); ok {
+		result.Frequency = &frequency
+	}
+	if days, ok := model.DurationDays(); ok {
+		result.DurationDays = &days
+	}
+	if instructions, ok := model.Instructions(); ok {
+		result.Instructions = &instructions
+	}
+
+	return result
+}
@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+type mfaRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IMfaRepository interface {
+	FindByUserID(userID string) (*entities.MfaModel, error)
+	SaveSecret(userID, secret string) (*entities.MfaModel, error)
+	EnableTx(tx *Tx, userID string, step int)
+	ReplaceRecoveryCodesTx(tx *Tx, userID string, codeHashes []string)
+	ClaimStep(userID string, step int) (bool, error)
+	UseRecoveryCode(userID, codeHash string) (bool, error)
+	RecordFailure(userID string, maxAttempts int, lockFor time.Duration) error
+	DeleteByUserID(userID string) (bool, error)
+	FindRequirements() ([]*entities.MfaRequirementModel, error)
+	Require(role db.Role, adminID string) (*entities.MfaRequirementModel, error)
+	Unrequire(role db.Role) (bool, error)
+}
+
+func NewMfaRepository(db *ds.PrismaDB) IMfaRepository {
+	return &mfaRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+func (repo *mfaRepository) FindByUserID(userID string) (*entities.MfaModel, error) {
+	mfa, err := repo.Collection.UserMfa.FindUnique(
+		db.UserMfa.UserID.Equals(userID),
+	).With(
+		db.UserMfa.MfaRecoveryCode.Fetch(db.MfaRecoveryCode.UsedAt.IsNull()),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("mfa -> FindByUserID: %w", err)
+	}
+
+	return mapMfaModel(mfa), nil
+}
+
+// SaveSecret starts (or restarts) enrollment, 2FA stays off until EnableTx.
+func (repo *mfaRepository) SaveSecret(userID, secret string) (*entities.MfaModel, error) {
+	mfa, err := repo.Collection.UserMfa.UpsertOne(
+		db.UserMfa.UserID.Equals(userID),
+	).Create(
+		db.UserMfa.Secret.Set(secret),
+		db.UserMfa.Users.Link(db.Users.ID.Equals(userID)),
+	).Update(
+		db.UserMfa.Secret.Set(secret),
+		db.UserMfa.EnabledAt.SetOptional(nil),
+		db.UserMfa.LastStep.Set(0),
+		db.UserMfa.FailedAttempts.Set(0),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("mfa -> SaveSecret: %v", err)
+	}
+
+	return mapMfaModel(mfa), nil
+}
+
+// EnableTx turns 2FA on, step is the code that confirmed the secret so it can't be used again.
+func (repo *mfaRepository) EnableTx(tx *Tx, userID string, step int) {
+	tx.add(repo.Collection.UserMfa.FindUnique(
+		db.UserMfa.UserID.Equals(userID),
+	).Update(
+		db.UserMfa.EnabledAt.Set(time.Now()),
+		db.UserMfa.LastStep.Set(step),
+		db.UserMfa.FailedAttempts.Set(0),
+	).Tx())
+}
+
+// ReplaceRecoveryCodesTx drops every recovery code of the user, used or not, for codeHashes.
+func (repo *mfaRepository) ReplaceRecoveryCodesTx(tx *Tx, userID string, codeHashes []string) {
+	tx.add(repo.Collection.MfaRecoveryCode.FindMany(
+		db.MfaRecoveryCode.UserID.Equals(userID),
+	).Delete().Tx())
+
+	for _, hash := range codeHashes {
+		tx.add(repo.Collection.MfaRecoveryCode.CreateOne(
+			db.MfaRecoveryCode.CodeHash.Set(hash),
+			db.MfaRecoveryCode.UserMfa.Link(db.UserMfa.UserID.Equals(userID)),
+		).Tx())
+	}
+}
+
+// ClaimStep accepts a TOTP code only if it is newer than the last one used, two requests with
+// the same code can't both pass. A good code also clears the failed attempts.
+func (repo *mfaRepository) ClaimStep(userID string, step int) (bool, error) {
+	result, err := repo.Collection.UserMfa.FindMany(
+		db.UserMfa.UserID.Equals(userID),
+		db.UserMfa.LastStep.Lt(step),
+	).Update(
+		db.UserMfa.LastStep.Set(step),
+		db.UserMfa.FailedAttempts.Set(0),
+	).Exec(repo.Context)
+	if err != nil {
+		return false, fmt.Errorf("mfa -> ClaimStep: %v", err)
+	}
+
+	return result.Count > 0, nil
+}
+
+func (repo *mfaRepository) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	result, err := repo.Collection.MfaRecoveryCode.FindMany(
+		db.MfaRecoveryCode.UserID.Equals(userID),
+		db.MfaRecoveryCode.CodeHash.Equals(codeHash),
+		db.MfaRecoveryCode.UsedAt.IsNull(),
+	).Update(
+		db.MfaRecoveryCode.UsedAt.Set(time.Now()),
+	).Exec(repo.Context)
+	if err != nil {
+		return false, fmt.Errorf("mfa -> UseRecoveryCode: %v", err)
+	}
+
+	return result.Count > 0, nil
+}
+
+// RecordFailure counts a wrong code, after maxAttempts in a row the user is locked for lockFor.
+func (repo *mfaRepository) RecordFailure(userID string, maxAttempts int, lockFor time.Duration) error {
+	mfa, err := repo.Collection.UserMfa.FindUnique(
+		db.UserMfa.UserID.Equals(userID),
+	).Update(
+		db.UserMfa.FailedAttempts.Increment(1),
+	).Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("mfa -> RecordFailure: %v", err)
+	}
+	if mfa.FailedAttempts < maxAttempts {
+		return nil
+	}
+
+	_, err = repo.Collection.UserMfa.FindUnique(
+		db.UserMfa.UserID.Equals(userID),
+	).Update(
+		db.UserMfa.FailedAttempts.Set(0),
+		db.UserMfa.LockedUntil.Set(time.Now().Add(lockFor)),
+	).Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("mfa -> RecordFailure: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteByUserID turns 2FA off, the recovery codes go with it.
+func (repo *mfaRepository) DeleteByUserID(userID string) (bool, error) {
+	result, err := repo.Collection.UserMfa.FindMany(
+		db.UserMfa.UserID.Equals(userID),
+	).Delete().Exec(repo.Context)
+	if err != nil {
+		return false, fmt.Errorf("mfa -> DeleteByUserID: %v", err)
+	}
+
+	return result.Count > 0, nil
+}
+
+func (repo *mfaRepository) FindRequirements() ([]*entities.MfaRequirementModel, error) {
+	requirements, err := repo.Collection.MfaRequirement.FindMany().OrderBy(
+		db.MfaRequirement.CreatedAt.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("mfa -> FindRequirements: %v", err)
+	}
+
+	result := make([]*entities.MfaRequirementModel, 0, len(requirements))
+	for i := range requirements {
+		result = append(result, mapMfaRequirementModel(&requirements[i]))
+	}
+
+	return result, nil
+}
+
+func (repo *mfaRepository) Require(role db.Role, adminID string) (*entities.MfaRequirementModel, error) {
+	requirement, err := repo.Collection.MfaRequirement.UpsertOne(
+		db.MfaRequirement.Role.Equals(role),
+	).Create(
+		db.MfaRequirement.Role.Set(role),
+		db.MfaRequirement.CreatedBy.Set(adminID),
+	).Update().Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("mfa -> Require: %v", err)
+	}
+
+	return mapMfaRequirementModel(requirement), nil
+}
+
+func (repo *mfaRepository) Unrequire(role db.Role) (bool, error) {
+	result, err := repo.Collection.MfaRequirement.FindMany(
+		db.MfaRequirement.Role.Equals(role),
+	).Delete().Exec(repo.Context)
+	if err != nil {
+		return false, fmt.Errorf("mfa -> Unrequire: %v", err)
+	}
+
+	return result.Count > 0, nil
+}
+
+func mapMfaModel(model *db.UserMfaModel) *entities.MfaModel {
+	result := &entities.MfaModel{
+		UserID:         model.UserID,
+		Secret:         model.Secret,
+		LastStep:       model.LastStep,
+		FailedAttempts: model.FailedAttempts,
+	}
+	if enabledAt, ok := model.EnabledAt(); ok {
+		result.EnabledAt = &enabledAt
+	}
+	if lockedUntil, ok := model.LockedUntil(); ok {
+		result.LockedUntil = &lockedUntil
+	}
+	// relation ถูก fetch มาเฉพาะตอน FindByUserID
+	if model.RelationsUserMfa.MfaRecoveryCode != nil {
+		result.RecoveryCodesLeft = len(model.MfaRecoveryCode())
+	}
+
+	return result
+}
+
+func mapMfaRequirementModel(model *db.MfaRequirementModel) *entities.MfaRequirementModel {
+	return &entities.MfaRequirementModel{
+		Role:      model.Role,
+		CreatedBy: model.CreatedBy,
+		CreatedAt: model.CreatedAt,
+	}
+}
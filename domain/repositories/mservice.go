@@ -16,6 +16,9 @@ type mserviceRepository struct {
 
 type IMServiceRepository interface {
 	Insert(data entities.SubService) (*entities.SubService, error)
+	InsertTx(tx *Tx, data entities.SubService)
+	UpdateStaffTx(tx *Tx, serviceID, staffID string)
+	UpdateDiseaseTx(tx *Tx, serviceID, disease string)
 	FindByID(serviceID string) (*entities.SubService, error)
 	DeleteByID(serviceID string) (*entities.SubService, error)
 	UpdateByID(data entities.SubService) (*entities.SubService, error)
@@ -42,6 +45,29 @@ func (repo *mserviceRepository) Insert(data entities.SubService) (*entities.SubS
 	return mapMserviceToSubService(createdMService), nil
 }
 
+func (repo *mserviceRepository) InsertTx(tx *Tx, data entities.SubService) {
+	tx.add(repo.Collection.Mservice.CreateOne(
+		db.Mservice.Doctor.Link(db.Doctor.UserID.Equals(data.StaffID)),
+		db.Mservice.Service.Link(db.Service.Sid.Equals(data.ServiceID)),
+	).Tx())
+}
+
+func (repo *mserviceRepository) UpdateStaffTx(tx *Tx, serviceID, staffID string) {
+	tx.add(repo.Collection.Mservice.FindUnique(
+		db.Mservice.Sid.Equals(serviceID),
+	).Update(
+		db.Mservice.Doctor.Link(db.Doctor.UserID.Equals(staffID)),
+	).Tx())
+}
+
+func (repo *mserviceRepository) UpdateDiseaseTx(tx *Tx, serviceID, disease string) {
+	tx.add(repo.Collection.Mservice.FindUnique(
+		db.Mservice.Sid.Equals(serviceID),
+	).Update(
+		db.Mservice.Disease.Set(disease),
+	).Tx())
+}
+
 func (repo *mserviceRepository) FindByID(serviceID string) (*entities.SubService, error) {
 	mservice, err := repo.Collection.Mservice.FindUnique(
 		db.Mservice.Sid.Equals(serviceID),
@@ -19,6 +19,7 @@ type IOwnerRepository interface {
 	FindByID(userID string) (*entities.UserDataModel, error)
 	DeleteByID(userID string) (*entities.UserDataModel, error)
 	UpdateByID(userID string, data entities.UpdateUserModel) (*entities.UserDataModel, error)
+	AddTotalSpendingTx(tx *Tx, userID string, amount db.Decimal)
 }
 
 func NewOwnerRepository(db *ds.PrismaDB) IOwnerRepository {
@@ -106,3 +107,11 @@ func (repo *ownerRepository) UpdateByID(userID string, data entities.UpdateUserM
 		TotalSpending: updatedUser.TotalSpending,
 	}, nil
 }
+
+func (repo *ownerRepository) AddTotalSpendingTx(tx *Tx, userID string, amount db.Decimal) {
+	tx.add(repo.Collection.Owner.FindUnique(
+		db.Owner.UserID.Equals(userID),
+	).Update(
+		db.Owner.TotalSpending.Increment(amount),
+	).Tx())
+}
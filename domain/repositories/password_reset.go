@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+// ErrResetTokenUsed is returned by Commit when another request used the reset token first.
+var ErrResetTokenUsed = errors.New("reset token was already used")
+
+const resetTokenUsedMarker = "reset_token_used"
+
+type passwordResetRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IPasswordResetRepository interface {
+	Insert(data entities.PasswordResetModel) (*entities.PasswordResetModel, error)
+	FindByID(resetID string) (*entities.PasswordResetModel, error)
+	CountSince(email, ip string, since time.Time) (*entities.ResetRequestCount, error)
+	MarkUsedTx(tx *Tx, resetID string)
+	DeleteBeforeTx(tx *Tx, before time.Time)
+}
+
+func NewPasswordResetRepository(db *ds.PrismaDB) IPasswordResetRepository {
+	return &passwordResetRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+func (repo *passwordResetRepository) Insert(data entities.PasswordResetModel) (*entities.PasswordResetModel, error) {
+	params := []db.PasswordResetSetParam{}
+	if data.UserID != nil {
+		params = append(params,
+			db.PasswordReset.Users.Link(db.Users.ID.Equals(*data.UserID)),
+			db.PasswordReset.PasswordFingerprint.Set(data.PasswordFingerprint),
+		)
+	}
+
+	created, err := repo.Collection.PasswordReset.CreateOne(
+		db.PasswordReset.Email.Set(data.Email),
+		db.PasswordReset.IP.Set(data.IP),
+		db.PasswordReset.ExpiresAt.Set(data.ExpiresAt),
+		params...,
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("password reset -> Insert: %v", err)
+	}
+
+	return mapPasswordResetModel(created), nil
+}
+
+func (repo *passwordResetRepository) FindByID(resetID string) (*entities.PasswordResetModel, error) {
+	reset, err := repo.Collection.PasswordReset.FindUnique(
+		db.PasswordReset.ID.Equals(resetID),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("password reset -> FindByID: %w", err)
+	}
+
+	return mapPasswordResetModel(reset), nil
+}
+
+func (repo *passwordResetRepository) CountSince(email, ip string, since time.Time) (*entities.ResetRequestCount, error) {
+	var sqlResult []entities.ResetRequestCount
+	err := repo.Collection.Prisma.QueryRaw(`
+		SELECT
+			CAST(COUNT(*) FILTER (WHERE email = $1) AS INTEGER) AS by_email,
+			CAST(COUNT(*) FILTER (WHERE ip = $2) AS INTEGER) AS by_ip
+		FROM "PasswordReset"
+		WHERE created_at >= $3 AND (email = $1 OR ip = $2)`,
+		email, ip, since,
+	).Exec(repo.Context, &sqlResult)
+	if err != nil {
+		return nil, fmt.Errorf("password reset -> CountSince: %v", err)
+	}
+	if len(sqlResult) == 0 {
+		return &entities.ResetRequestCount{}, nil
+	}
+
+	return &sqlResult[0], nil
+}
+
+// MarkUsedTx claims the token together with the password change. If it was used in the
+// meantime (two resets at once) the whole tx fails with ErrResetTokenUsed.
+func (repo *passwordResetRepository) MarkUsedTx(tx *Tx, resetID string) {
+	tx.add(repo.Collection.Prisma.ExecuteRaw(fmt.Sprintf(`
+		SELECT CAST(
+			CASE WHEN EXISTS (
+				SELECT 1 FROM "PasswordReset" WHERE id = $1::uuid AND used_at IS NOT NULL
+			) THEN '%s' ELSE '0' END
+		AS INTEGER)`, resetTokenUsedMarker),
+		resetID,
+	).Tx())
+	tx.add(repo.Collection.PasswordReset.FindUnique(
+		db.PasswordReset.ID.Equals(resetID),
+	).Update(
+		db.PasswordReset.UsedAt.Set(time.Now()),
+	).Tx())
+}
+
+func (repo *passwordResetRepository) DeleteBeforeTx(tx *Tx, before time.Time) {
+	tx.add(repo.Collection.PasswordReset.FindMany(
+		db.PasswordReset.CreatedAt.Lt(before),
+	).Delete().Tx())
+}
+
+func mapPasswordResetModel(model *db.PasswordResetModel) *entities.PasswordResetModel {
+	result := &entities.PasswordResetModel{
+		ID:        model.ID,
+		Email:     model.Email,
+		IP:        model.IP,
+		CreatedAt: model.CreatedAt,
+		ExpiresAt: model.ExpiresAt,
+	}
+	if userID, ok := model.UserID(); ok {
+		result.UserID = &userID
+	}
+	result.PasswordFingerprint, _ = model.PasswordFingerprint()
+	if usedAt, ok := model.UsedAt(); ok {
+		result.UsedAt = &usedAt
+	}
+
+	return result
+}
+
+func isResetTokenUsedErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), resetTokenUsedMarker)
+}
@@ -16,10 +16,14 @@ type paymentRepository struct {
 }
 
 type IPaymentRepository interface {
-	InsertPayment(user_id string, price int) (*entities.PaymentModel, error)
+	InsertPaymentTx(tx *Tx, paymentID, userID string, price int, lineItems []entities.PaymentLineItemModel)
+	InsertAdjustmentTx(tx *Tx, paymentID, parentID, userID string, price int, lineItems []entities.PaymentLineItemModel)
 	FindByID(payID string) (*entities.PaymentModel, error)
+	FindByStripePaymentIntent(paymentIntentID string) (*entities.PaymentModel, error)
 	DeleteByID(payID string) (*entities.PaymentModel, error)
 	UpdateByID(paymentID string, data entities.PaymentModel) (*entities.PaymentModel, error)
+	UpdateByIDTx(tx *Tx, paymentID string, data entities.PaymentModel) error
+	ExpireAbandonedTx(tx *Tx, createdBefore time.Time)
 	FindAllPayments(month int, year int, offset, limit int) ([]*entities.PaymentModel, int, error)
 	FindPaymentsByOwnerID(ownerID string, month int, year int, offset, limit int) ([]*entities.PaymentModel, int, error)
 }
@@ -31,27 +35,53 @@ func NewPaymentRepository(db *ds.PrismaDB) IPaymentRepository {
 	}
 }
 
-func (repo *paymentRepository) InsertPayment(user_id string, price int) (*entities.PaymentModel, error) {
-	createdData, err := repo.Collection.Payment.CreateOne(
+// InsertPaymentTx creates the payment together with its price breakdown.
+func (repo *paymentRepository) InsertPaymentTx(tx *Tx, paymentID, userID string, price int, lineItems []entities.PaymentLineItemModel) {
+	repo.insertTx(tx, paymentID, userID, price, lineItems)
+}
+
+// InsertAdjustmentTx creates an extra charge for the booking paid by parentID.
+func (repo *paymentRepository) InsertAdjustmentTx(tx *Tx, paymentID, parentID, userID string, price int, lineItems []entities.PaymentLineItemModel) {
+	repo.insertTx(tx, paymentID, userID, price, lineItems,
+		db.Payment.Parent.Link(db.Payment.Payid.Equals(parentID)),
+	)
+}
+
+func (repo *paymentRepository) insertTx(tx *Tx, paymentID, userID string, price int, lineItems []entities.PaymentLineItemModel, optional ...db.PaymentSetParam) {
+	tx.add(repo.Collection.Payment.CreateOne(
 		db.Payment.Price.Set(price),
 		db.Payment.Status.Set(db.PaymentStatusUnpaid),
-		db.Payment.Owner.Link(db.Owner.UserID.Equals(user_id)),
-	).Exec(repo.Context)
-
-	if err != nil {
-		return nil, fmt.Errorf("payment -> InsertPayment: %v", err)
+		db.Payment.Owner.Link(db.Owner.UserID.Equals(userID)),
+		append([]db.PaymentSetParam{db.Payment.Payid.Set(paymentID)}, optional...)...,
+	).Tx())
+
+	for i, item := range lineItems {
+		tx.add(repo.Collection.PaymentLineItem.CreateOne(
+			db.PaymentLineItem.Position.Set(i),
+			db.PaymentLineItem.Kind.Set(item.Kind),
+			db.PaymentLineItem.Description.Set(item.Description),
+			db.PaymentLineItem.Quantity.Set(item.Quantity),
+			db.PaymentLineItem.UnitAmount.Set(item.UnitAmount),
+			db.PaymentLineItem.Amount.Set(item.Amount),
+			db.PaymentLineItem.Payment.Link(db.Payment.Payid.Equals(paymentID)),
+		).Tx())
 	}
-
-	return mapToPaymentModel(createdData), nil
 }
 
 func (repo *paymentRepository) FindByID(payID string) (*entities.PaymentModel, error) {
 	payment, err := repo.Collection.Payment.FindUnique(
 		db.Payment.Payid.Equals(payID),
+	).With(
+		paymentLineItemsFetch(),
+		db.Payment.Adjustments.Fetch().With(
+			paymentLineItemsFetch(),
+		).OrderBy(
+			db.Payment.CreatedAt.Order(db.SortOrderAsc),
+		),
 	).Exec(repo.Context)
 
 	if err != nil {
-		return nil, fmt.Errorf("payment -> FindByID: %v", err)
+		return nil, fmt.Errorf("payment -> FindByID: %w", err)
 	}
 	if payment == nil {
 		return nil, fmt.Errorf("payment -> FindByID: payment data is nil")
@@ -60,6 +90,18 @@ func (repo *paymentRepository) FindByID(payID string) (*entities.PaymentModel, e
 	return mapToPaymentModel(payment), nil
 }
 
+func (repo *paymentRepository) FindByStripePaymentIntent(paymentIntentID string) (*entities.PaymentModel, error) {
+	payment, err := repo.Collection.Payment.FindUnique(
+		db.Payment.StripePaymentIntent.Equals(paymentIntentID),
+	).Exec(repo.Context)
+
+	if err != nil {
+		return nil, fmt.Errorf("payment -> FindByStripePaymentIntent: %w", err)
+	}
+
+	return mapToPaymentModel(payment), nil
+}
+
 func (repo *paymentRepository) DeleteByID(payID string) (*entities.PaymentModel, error) {
 	deletedPayment, err := repo.Collection.Payment.FindUnique(
 		db.Payment.Payid.Equals(payID),
@@ -76,20 +118,7 @@ func (repo *paymentRepository) DeleteByID(payID string) (*entities.PaymentModel,
 }
 
 func (repo *paymentRepository) UpdateByID(paymentID string, data entities.PaymentModel) (*entities.PaymentModel, error) {
-	updates := []db.PaymentSetParam{}
-
-	if data.Status != "" {
-		updates = append(updates, db.Payment.Status.Set(db.PaymentStatus(data.Status)))
-	}
-
-	if data.Type != nil && *data.Type != "" {
-		updates = append(updates, db.Payment.Type.Set(*data.Type))
-	}
-
-	if data.PayDate != nil {
-		updates = append(updates, db.Payment.PayDate.Set(*data.PayDate))
-	}
-
+	updates := paymentSetParams(data)
 	if len(updates) == 0 {
 		return nil, fmt.Errorf("payment -> UpdateByID: no fields to update")
 	}
@@ -110,6 +139,62 @@ func (repo *paymentRepository) UpdateByID(paymentID string, data entities.Paymen
 	return mapToPaymentModel(updatedPayment), nil
 }
 
+func (repo *paymentRepository) UpdateByIDTx(tx *Tx, paymentID string, data entities.PaymentModel) error {
+	updates := paymentSetParams(data)
+	if len(updates) == 0 {
+		return fmt.Errorf("payment -> UpdateByIDTx: no fields to update")
+	}
+
+	tx.add(repo.Collection.Payment.FindUnique(
+		db.Payment.Payid.Equals(paymentID),
+	).Update(updates...).Tx())
+
+	return nil
+}
+
+// ExpireAbandonedTx expires checkouts that were never paid. FAILED is included
+// because the owner could still retry in the same session until it expired.
+func (repo *paymentRepository) ExpireAbandonedTx(tx *Tx, createdBefore time.Time) {
+	tx.add(repo.Collection.Payment.FindMany(
+		db.Payment.Status.In([]db.PaymentStatus{db.PaymentStatusUnpaid, db.PaymentStatusFailed}),
+		db.Payment.CreatedAt.Lt(createdBefore),
+	).Update(
+		db.Payment.Status.Set(db.PaymentStatusExpired),
+	).Tx())
+}
+
+func paymentLineItemsFetch() db.PaymentRelationWith {
+	return db.Payment.PaymentLineItem.Fetch().OrderBy(
+		db.PaymentLineItem.Position.Order(db.SortOrderAsc),
+	)
+}
+
+func paymentSetParams(data entities.PaymentModel) []db.PaymentSetParam {
+	updates := []db.PaymentSetParam{}
+
+	if data.Status != "" {
+		updates = append(updates, db.Payment.Status.Set(db.PaymentStatus(data.Status)))
+	}
+
+	if data.Type != nil && *data.Type != "" {
+		updates = append(updates, db.Payment.Type.Set(*data.Type))
+	}
+
+	if data.PayDate != nil {
+		updates = append(updates, db.Payment.PayDate.Set(*data.PayDate))
+	}
+
+	if data.StripePaymentIntent != nil && *data.StripePaymentIntent != "" {
+		updates = append(updates, db.Payment.StripePaymentIntent.Set(*data.StripePaymentIntent))
+	}
+
+	if data.RefundedAmount > 0 {
+		updates = append(updates, db.Payment.RefundedAmount.Set(data.RefundedAmount))
+	}
+
+	return updates
+}
+
 func mapToPaymentModel(model *db.PaymentModel) *entities.PaymentModel {
 	paymentType, ok := model.Type()
 	if !ok {
@@ -120,14 +205,38 @@ func mapToPaymentModel(model *db.PaymentModel) *entities.PaymentModel {
 		payDate = time.Time{}
 	}
 
-	return &entities.PaymentModel{
+	result := &entities.PaymentModel{
 		PayID:   model.Payid,
 		OwnerID: model.Oid,
 		Status:  model.Status,
 		Price:   model.Price,
 		Type:    &paymentType,
 		PayDate: &payDate,
+
+		RefundedAmount: model.RefundedAmount,
+	}
+	if paymentIntent, ok := model.StripePaymentIntent(); ok {
+		result.StripePaymentIntent = &paymentIntent
+	}
+	if parentID, ok := model.ParentPayid(); ok {
+		result.ParentID = &parentID
 	}
+	if model.RelationsPayment.Adjustments != nil {
+		result.Adjustments = mapToPaymentModels(model.Adjustments())
+	}
+	if model.RelationsPayment.PaymentLineItem != nil {
+		for _, item := range model.PaymentLineItem() {
+			result.LineItems = append(result.LineItems, entities.PaymentLineItemModel{
+				Kind:        item.Kind,
+				Description: item.Description,
+				Quantity:    item.Quantity,
+				UnitAmount:  item.UnitAmount,
+				Amount:      item.Amount,
+			})
+		}
+	}
+
+	return result
 }
 func mapToPaymentModels(models []db.PaymentModel) []*entities.PaymentModel {
 	payments := make([]*entities.PaymentModel, len(models))
@@ -158,7 +267,9 @@ func (repo *paymentRepository) FindAllPayments(month int, year int, offset, limi
 	}
 	total := sqlResult[0].Count
 
-	payments, err := repo.Collection.Payment.FindMany(params...).OrderBy(
+	payments, err := repo.Collection.Payment.FindMany(params...).With(
+		paymentLineItemsFetch(),
+	).OrderBy(
 		db.Payment.PayDate.Order(db.SortOrderAsc),
 	).Skip(offset).Take(limit).Exec(repo.Context)
 	if err != nil {
@@ -191,7 +302,9 @@ func (repo *paymentRepository) FindPaymentsByOwnerID(ownerID string, month int,
 	}
 	total := sqlResult[0].Count
 
-	payments, err := repo.Collection.Payment.FindMany(params...).OrderBy(
+	payments, err := repo.Collection.Payment.FindMany(params...).With(
+		paymentLineItemsFetch(),
+	).OrderBy(
 		db.Payment.PayDate.Order(db.SortOrderAsc),
 	).Skip(offset).Take(limit).Exec(repo.Context)
 	if err != nil {
@@ -7,6 +7,8 @@ import (
 	"lama-backend/domain/prisma/db"
 
 	"fmt"
+
+	"github.com/google/uuid"
 )
 
 type petRepository struct {
@@ -21,6 +23,7 @@ type IPetRepository interface {
 	FindAll() ([]entities.PetDataModel, error)
 	UpdatePet(petID string, data entities.UpdatePetModel) (*entities.PetDataModel, error)
 	DeletePet(petID string) (*entities.PetDataModel, error)
+	UpdateWeightTx(tx *Tx, petID string, weight db.Decimal)
 }
 
 func NewPetRepository(db *ds.PrismaDB) IPetRepository {
@@ -31,7 +34,8 @@ func NewPetRepository(db *ds.PrismaDB) IPetRepository {
 }
 
 func (repo *petRepository) InsertPet(data entities.CreatedPetModel) (*entities.PetDataModel, error) {
-	createdData, err := repo.Collection.Pet.CreateOne(
+	petID := uuid.NewString()
+	createPet := repo.Collection.Pet.CreateOne(
 		db.Pet.Birthdate.Set(data.BirthDate),
 		db.Pet.Weight.Set(data.Weight),
 		db.Pet.Kind.Set(data.Kind),
@@ -41,11 +45,18 @@ func (repo *petRepository) InsertPet(data entities.CreatedPetModel) (*entities.P
 		// optional fields
 		db.Pet.Breed.SetIfPresent(data.Breed),
 		db.Pet.Name.SetIfPresent(data.Name),
-	).Exec(repo.Context)
+		db.Pet.Petid.Set(petID),
+	).Tx()
 
+	// น้ำหนักตอนสร้างเป็นจุดแรกของประวัติ
+	err := repo.Collection.Prisma.Transaction(
+		createPet,
+		repo.insertWeight(petID, data.Weight),
+	).Exec(repo.Context)
 	if err != nil {
 		return nil, fmt.Errorf("pets -> InsertPet: %v", err)
 	}
+	createdData := createPet.Result()
 
 	breed, _ := createdData.Breed()
 	name, _ := createdData.Name()
@@ -173,13 +184,28 @@ func (repo *petRepository) UpdatePet(petID string, data entities.UpdatePetModel)
 		return nil, fmt.Errorf("pets -> UpdatePet: no fields to update")
 	}
 
-	updated, err := repo.Collection.Pet.FindUnique(
+	// weight เปลี่ยนต้องลงประวัติด้วย ไม่งั้นค่าเก่าหายไป
+	weightChanged := false
+	if data.Weight != nil {
+		current, err := repo.FindPetByID(petID)
+		if err != nil {
+			return nil, fmt.Errorf("pets -> UpdatePet: %w", err)
+		}
+		weightChanged = !current.Weight.Equal(*data.Weight)
+	}
+
+	updatePet := repo.Collection.Pet.FindUnique(
 		db.Pet.Petid.Equals(petID),
-	).Update(updates...).Exec(repo.Context)
+	).Update(updates...).Tx()
+	queries := []db.PrismaTransaction{updatePet}
+	if weightChanged {
+		queries = append(queries, repo.insertWeight(petID, *data.Weight))
+	}
 
-	if err != nil {
+	if err := repo.Collection.Prisma.Transaction(queries...).Exec(repo.Context); err != nil {
 		return nil, fmt.Errorf("pets -> UpdatePet: %v", err)
 	}
+	updated := updatePet.Result()
 	if updated == nil {
 		return nil, fmt.Errorf("pets -> UpdatePet: pet not found")
 	}
@@ -225,3 +251,20 @@ func (repo *petRepository) DeletePet(petID string) (*entities.PetDataModel, erro
 		Sex:       deleted.Sex,
 	}, nil
 }
+
+// UpdateWeightTx sets the current weight only, the caller writes the history row.
+func (repo *petRepository) UpdateWeightTx(tx *Tx, petID string, weight db.Decimal) {
+	tx.add(repo.Collection.Pet.FindUnique(
+		db.Pet.Petid.Equals(petID),
+	).Update(
+		db.Pet.Weight.Set(weight),
+	).Tx())
+}
+
+func (repo *petRepository) insertWeight(petID string, weight db.Decimal) db.PrismaTransaction {
+	return repo.Collection.PetWeight.CreateOne(
+		db.PetWeight.Weight.Set(weight),
+		db.PetWeight.Source.Set(db.PetWeightSourceProfile),
+		db.PetWeight.Pet.Link(db.Pet.Petid.Equals(petID)),
+	).Tx()
+}
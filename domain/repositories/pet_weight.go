@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+type petWeightRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IPetWeightRepository interface {
+	UpsertVitalsTx(tx *Tx, data entities.PetWeightModel)
+	DeleteVitalsTx(tx *Tx, serviceID string)
+	FindByPetID(petID string, from, to *time.Time) ([]*entities.PetWeightModel, error)
+	FindLatest(petID string) (*entities.PetWeightModel, error)
+}
+
+func NewPetWeightRepository(db *ds.PrismaDB) IPetWeightRepository {
+	return &petWeightRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+// UpsertVitalsTx writes the weight measured during a visit; saving the visit again
+// replaces it.
+func (repo *petWeightRepository) UpsertVitalsTx(tx *Tx, data entities.PetWeightModel) {
+	tx.add(repo.Collection.PetWeight.UpsertOne(
+		db.PetWeight.Sid.Equals(*data.ServiceID),
+	).Create(
+		db.PetWeight.Weight.Set(data.Weight),
+		db.PetWeight.Source.Set(db.PetWeightSourceVitals),
+		db.PetWeight.Pet.Link(db.Pet.Petid.Equals(data.PetID)),
+		db.PetWeight.Service.Link(db.Service.Sid.Equals(*data.ServiceID)),
+		db.PetWeight.RecordedBy.SetIfPresent(data.RecordedBy),
+		db.PetWeight.MeasuredAt.Set(data.MeasuredAt),
+	).Update(
+		db.PetWeight.Weight.Set(data.Weight),
+		db.PetWeight.RecordedBy.SetIfPresent(data.RecordedBy),
+		db.PetWeight.MeasuredAt.Set(data.MeasuredAt),
+	).Tx())
+}
+
+func (repo *petWeightRepository) DeleteVitalsTx(tx *Tx, serviceID string) {
+	tx.add(repo.Collection.PetWeight.FindMany(
+		db.PetWeight.Sid.Equals(serviceID),
+	).Delete().Tx())
+}
+
+// FindByPetID returns the measurements of the pet between from and to (both optional),
+// oldest first.
+func (repo *petWeightRepository) FindByPetID(petID string, from, to *time.Time) ([]*entities.PetWeightModel, error) {
+	params := []db.PetWeightWhereParam{
+		db.PetWeight.Petid.Equals(petID),
+	}
+	if from != nil {
+		params = append(params, db.PetWeight.MeasuredAt.Gte(*from))
+	}
+	if to != nil {
+		params = append(params, db.PetWeight.MeasuredAt.Lt(*to))
+	}
+
+	rows, err := repo.Collection.PetWeight.FindMany(params...).OrderBy(
+		db.PetWeight.MeasuredAt.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pet weight -> FindByPetID: %v", err)
+	}
+
+	results := make([]*entities.PetWeightModel, 0, len(rows))
+	for i := range rows {
+		results = append(results, mapPetWeightModel(&rows[i]))
+	}
+	return results, nil
+}
+
+// FindLatest returns the newest measurement of the pet, or nil when there is none.
+func (repo *petWeightRepository) FindLatest(petID string) (*entities.PetWeightModel, error) {
+	row, err := repo.Collection.PetWeight.FindFirst(
+		db.PetWeight.Petid.Equals(petID),
+	).OrderBy(
+		db.PetWeight.MeasuredAt.Order(db.SortOrderDesc),
+	).Exec(repo.Context)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pet weight -> FindLatest: %v", err)
+	}
+
+	return mapPetWeightModel(row), nil
+}
+
+func mapPetWeightModel(model *db.PetWeightModel) *entities.PetWeightModel {
+	result := &entities.PetWeightModel{
+		ID:         model.ID,
+		PetID:      model.Petid,
+		Weight:     model.Weight,
+		Source:     model.Source,
+		MeasuredAt: model.MeasuredAt,
+	}
+	if serviceID, ok := model.Sid(); ok {
+		result.ServiceID = &serviceID
+	}
+	if recordedBy, ok := model.RecordedBy(); ok {
+		result.RecordedBy = &recordedBy
+	}
+
+	return result
+}
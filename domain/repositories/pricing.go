@@ -0,0 +1,371 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"time"
+)
+
+type pricingRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IPricingRepository interface {
+	FindRules(serviceType, staffID string, start, end time.Time) (*entities.PricingRules, error)
+	FindAll() (*entities.PricingConfigModel, error)
+	UpsertRateCard(serviceType string, hourlyRate int) (*entities.RateCardModel, error)
+	DeleteRateCard(serviceType string) error
+	UpsertStaffRate(staffID string, hourlyRate int) (*entities.StaffRateModel, error)
+	DeleteStaffRate(staffID string) error
+	InsertPetSurcharge(data entities.PetSurchargeModel) (*entities.PetSurchargeModel, error)
+	UpdatePetSurcharge(id string, data entities.PetSurchargeModel) (*entities.PetSurchargeModel, error)
+	DeletePetSurcharge(id string) error
+	InsertMultiplier(data entities.PriceMultiplierModel) (*entities.PriceMultiplierModel, error)
+	UpdateMultiplier(id string, data entities.PriceMultiplierModel) (*entities.PriceMultiplierModel, error)
+	DeleteMultiplier(id string) error
+	UpsertHoliday(date time.Time, name string) (*entities.HolidayModel, error)
+	DeleteHoliday(date time.Time) error
+}
+
+func NewPricingRepository(db *ds.PrismaDB) IPricingRepository {
+	return &pricingRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+// FindRules loads the rate card and overrides for one booking. Surcharges and
+// multipliers are few, so all of them are returned and matched by the caller.
+func (repo *pricingRepository) FindRules(serviceType, staffID string, start, end time.Time) (*entities.PricingRules, error) {
+	rules := &entities.PricingRules{}
+
+	rateCard, err := repo.Collection.RateCard.FindUnique(
+		db.RateCard.ServiceType.Equals(serviceType),
+	).Exec(repo.Context)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("pricing -> FindRules: %v", err)
+	}
+	if rateCard != nil {
+		rules.RateCard = mapToRateCardModel(rateCard)
+	}
+
+	staffRate, err := repo.Collection.StaffRate.FindUnique(
+		db.StaffRate.StaffID.Equals(staffID),
+	).Exec(repo.Context)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("pricing -> FindRules: %v", err)
+	}
+	if staffRate != nil {
+		rules.StaffRate = mapToStaffRateModel(staffRate)
+	}
+
+	surcharges, err := repo.Collection.PetSurcharge.FindMany().Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> FindRules: %v", err)
+	}
+	for i := range surcharges {
+		rules.PetSurcharges = append(rules.PetSurcharges, *mapToPetSurchargeModel(&surcharges[i]))
+	}
+
+	multipliers, err := repo.Collection.PriceMultiplier.FindMany().Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> FindRules: %v", err)
+	}
+	for i := range multipliers {
+		rules.Multipliers = append(rules.Multipliers, *mapToPriceMultiplierModel(&multipliers[i]))
+	}
+
+	// เผื่อวันละฝั่งเพราะวันหยุดเก็บเป็น date แต่ booking เป็นเวลาไทย
+	holidays, err := repo.Collection.Holiday.FindMany(
+		db.Holiday.Date.Gte(start.AddDate(0, 0, -1)),
+		db.Holiday.Date.Lte(end.AddDate(0, 0, 1)),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> FindRules: %v", err)
+	}
+	for _, holiday := range holidays {
+		rules.Holidays = append(rules.Holidays, entities.HolidayModel{Date: holiday.Date, Name: holiday.Name})
+	}
+
+	return rules, nil
+}
+
+func (repo *pricingRepository) FindAll() (*entities.PricingConfigModel, error) {
+	config := &entities.PricingConfigModel{
+		RateCards:     []entities.RateCardModel{},
+		StaffRates:    []entities.StaffRateModel{},
+		PetSurcharges: []entities.PetSurchargeModel{},
+		Multipliers:   []entities.PriceMultiplierModel{},
+		Holidays:      []entities.HolidayModel{},
+	}
+
+	rateCards, err := repo.Collection.RateCard.FindMany().OrderBy(
+		db.RateCard.ServiceType.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> FindAll: %v", err)
+	}
+	for i := range rateCards {
+		config.RateCards = append(config.RateCards, *mapToRateCardModel(&rateCards[i]))
+	}
+
+	staffRates, err := repo.Collection.StaffRate.FindMany().Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> FindAll: %v", err)
+	}
+	for i := range staffRates {
+		config.StaffRates = append(config.StaffRates, *mapToStaffRateModel(&staffRates[i]))
+	}
+
+	surcharges, err := repo.Collection.PetSurcharge.FindMany().Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> FindAll: %v", err)
+	}
+	for i := range surcharges {
+		config.PetSurcharges = append(config.PetSurcharges, *mapToPetSurchargeModel(&surcharges[i]))
+	}
+
+	multipliers, err := repo.Collection.PriceMultiplier.FindMany().Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> FindAll: %v", err)
+	}
+	for i := range multipliers {
+		config.Multipliers = append(config.Multipliers, *mapToPriceMultiplierModel(&multipliers[i]))
+	}
+
+	holidays, err := repo.Collection.Holiday.FindMany().OrderBy(
+		db.Holiday.Date.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> FindAll: %v", err)
+	}
+	for _, holiday := range holidays {
+		config.Holidays = append(config.Holidays, entities.HolidayModel{Date: holiday.Date, Name: holiday.Name})
+	}
+
+	return config, nil
+}
+
+func (repo *pricingRepository) UpsertRateCard(serviceType string, hourlyRate int) (*entities.RateCardModel, error) {
+	rateCard, err := repo.Collection.RateCard.UpsertOne(
+		db.RateCard.ServiceType.Equals(serviceType),
+	).Create(
+		db.RateCard.ServiceType.Set(serviceType),
+		db.RateCard.HourlyRate.Set(hourlyRate),
+	).Update(
+		db.RateCard.HourlyRate.Set(hourlyRate),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> UpsertRateCard: %v", err)
+	}
+
+	return mapToRateCardModel(rateCard), nil
+}
+
+func (repo *pricingRepository) DeleteRateCard(serviceType string) error {
+	_, err := repo.Collection.RateCard.FindUnique(
+		db.RateCard.ServiceType.Equals(serviceType),
+	).Delete().Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("pricing -> DeleteRateCard: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *pricingRepository) UpsertStaffRate(staffID string, hourlyRate int) (*entities.StaffRateModel, error) {
+	staffRate, err := repo.Collection.StaffRate.UpsertOne(
+		db.StaffRate.StaffID.Equals(staffID),
+	).Create(
+		db.StaffRate.StaffID.Set(staffID),
+		db.StaffRate.HourlyRate.Set(hourlyRate),
+	).Update(
+		db.StaffRate.HourlyRate.Set(hourlyRate),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> UpsertStaffRate: %v", err)
+	}
+
+	return mapToStaffRateModel(staffRate), nil
+}
+
+func (repo *pricingRepository) DeleteStaffRate(staffID string) error {
+	_, err := repo.Collection.StaffRate.FindUnique(
+		db.StaffRate.StaffID.Equals(staffID),
+	).Delete().Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("pricing -> DeleteStaffRate: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *pricingRepository) InsertPetSurcharge(data entities.PetSurchargeModel) (*entities.PetSurchargeModel, error) {
+	surcharge, err := repo.Collection.PetSurcharge.CreateOne(
+		db.PetSurcharge.Name.Set(data.Name),
+		db.PetSurcharge.AmountPerHour.Set(data.AmountPerHour),
+		db.PetSurcharge.ServiceType.SetIfPresent(data.ServiceType),
+		db.PetSurcharge.Kind.SetIfPresent(data.Kind),
+		db.PetSurcharge.MinWeight.SetIfPresent(data.MinWeight),
+		db.PetSurcharge.MaxWeight.SetIfPresent(data.MaxWeight),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> InsertPetSurcharge: %v", err)
+	}
+
+	return mapToPetSurchargeModel(surcharge), nil
+}
+
+// UpdatePetSurcharge replaces the whole rule; optional fields left empty are cleared.
+func (repo *pricingRepository) UpdatePetSurcharge(id string, data entities.PetSurchargeModel) (*entities.PetSurchargeModel, error) {
+	surcharge, err := repo.Collection.PetSurcharge.FindUnique(
+		db.PetSurcharge.ID.Equals(id),
+	).Update(
+		db.PetSurcharge.Name.Set(data.Name),
+		db.PetSurcharge.AmountPerHour.Set(data.AmountPerHour),
+		db.PetSurcharge.ServiceType.SetOptional(data.ServiceType),
+		db.PetSurcharge.Kind.SetOptional(data.Kind),
+		db.PetSurcharge.MinWeight.SetOptional(data.MinWeight),
+		db.PetSurcharge.MaxWeight.SetOptional(data.MaxWeight),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> UpdatePetSurcharge: %w", err)
+	}
+
+	return mapToPetSurchargeModel(surcharge), nil
+}
+
+func (repo *pricingRepository) DeletePetSurcharge(id string) error {
+	_, err := repo.Collection.PetSurcharge.FindUnique(
+		db.PetSurcharge.ID.Equals(id),
+	).Delete().Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("pricing -> DeletePetSurcharge: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *pricingRepository) InsertMultiplier(data entities.PriceMultiplierModel) (*entities.PriceMultiplierModel, error) {
+	multiplier, err := repo.Collection.PriceMultiplier.CreateOne(
+		db.PriceMultiplier.Name.Set(data.Name),
+		db.PriceMultiplier.Multiplier.Set(data.Multiplier),
+		db.PriceMultiplier.DayType.Set(data.DayType),
+		db.PriceMultiplier.StartHour.Set(data.StartHour),
+		db.PriceMultiplier.EndHour.Set(data.EndHour),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> InsertMultiplier: %v", err)
+	}
+
+	return mapToPriceMultiplierModel(multiplier), nil
+}
+
+func (repo *pricingRepository) UpdateMultiplier(id string, data entities.PriceMultiplierModel) (*entities.PriceMultiplierModel, error) {
+	multiplier, err := repo.Collection.PriceMultiplier.FindUnique(
+		db.PriceMultiplier.ID.Equals(id),
+	).Update(
+		db.PriceMultiplier.Name.Set(data.Name),
+		db.PriceMultiplier.Multiplier.Set(data.Multiplier),
+		db.PriceMultiplier.DayType.Set(data.DayType),
+		db.PriceMultiplier.StartHour.Set(data.StartHour),
+		db.PriceMultiplier.EndHour.Set(data.EndHour),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> UpdateMultiplier: %w", err)
+	}
+
+	return mapToPriceMultiplierModel(multiplier), nil
+}
+
+func (repo *pricingRepository) DeleteMultiplier(id string) error {
+	_, err := repo.Collection.PriceMultiplier.FindUnique(
+		db.PriceMultiplier.ID.Equals(id),
+	).Delete().Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("pricing -> DeleteMultiplier: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *pricingRepository) UpsertHoliday(date time.Time, name string) (*entities.HolidayModel, error) {
+	holiday, err := repo.Collection.Holiday.UpsertOne(
+		db.Holiday.Date.Equals(date),
+	).Create(
+		db.Holiday.Date.Set(date),
+		db.Holiday.Name.Set(name),
+	).Update(
+		db.Holiday.Name.Set(name),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("pricing -> UpsertHoliday: %v", err)
+	}
+
+	return &entities.HolidayModel{Date: holiday.Date, Name: holiday.Name}, nil
+}
+
+func (repo *pricingRepository) DeleteHoliday(date time.Time) error {
+	_, err := repo.Collection.Holiday.FindUnique(
+		db.Holiday.Date.Equals(date),
+	).Delete().Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("pricing -> DeleteHoliday: %w", err)
+	}
+
+	return nil
+}
+
+func mapToRateCardModel(model *db.RateCardModel) *entities.RateCardModel {
+	return &entities.RateCardModel{
+		ServiceType: model.ServiceType,
+		HourlyRate:  model.HourlyRate,
+		UpdatedAt:   model.UpdatedAt,
+	}
+}
+
+func mapToStaffRateModel(model *db.StaffRateModel) *entities.StaffRateModel {
+	return &entities.StaffRateModel{
+		StaffID:    model.StaffID,
+		HourlyRate: model.HourlyRate,
+		UpdatedAt:  model.UpdatedAt,
+	}
+}
+
+func mapToPetSurchargeModel(model *db.PetSurchargeModel) *entities.PetSurchargeModel {
+	surcharge := &entities.PetSurchargeModel{
+		ID:            model.ID,
+		Name:          model.Name,
+		AmountPerHour: model.AmountPerHour,
+	}
+	if serviceType, ok := model.ServiceType(); ok {
+		surcharge.ServiceType = &serviceType
+	}
+	if kind, ok := model.Kind(); ok {
+		surcharge.Kind = &kind
+	}
+	if minWeight, ok := model.MinWeight(); ok {
+		surcharge.MinWeight = &minWeight
+	}
+	if maxWeight, ok := model.MaxWeight(); ok {
+		surcharge.MaxWeight = &maxWeight
+	}
+
+	return surcharge
+}
+
+func mapToPriceMultiplierModel(model *db.PriceMultiplierModel) *entities.PriceMultiplierModel {
+	return &entities.PriceMultiplierModel{
+		ID:         model.ID,
+		Name:       model.Name,
+		DayType:    model.DayType,
+		StartHour:  model.StartHour,
+		EndHour:    model.EndHour,
+		Multiplier: model.Multiplier,
+	}
+}
@@ -2,7 +2,9 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	ds "lama-backend/domain/datasources"
@@ -10,6 +12,12 @@ import (
 	"lama-backend/domain/prisma/db"
 )
 
+// ErrStatusChanged is returned by Commit when the service status was changed by
+// someone else between reading it and writing the transition.
+var ErrStatusChanged = errors.New("service status was changed by another request")
+
+const statusChangedMarker = "status_changed"
+
 type serviceRepository struct {
 	Context    context.Context
 	Collection *db.PrismaClient
@@ -17,14 +25,20 @@ type serviceRepository struct {
 
 type IServiceRepository interface {
 	Insert(data entities.CreateServiceRequest) (*entities.ServiceModel, error)
+	InsertTx(tx *Tx, serviceID string, data entities.CreateServiceRequest)
 	FindByID(serviceID string) (*entities.ServiceModel, error)
+	FindByPaymentID(paymentID string) (*entities.ServiceModel, error)
 	DeleteByID(serviceID string) (*entities.ServiceModel, error)
 	UpdateByID(serviceID string, data entities.UpdateServiceRequest) (*entities.ServiceModel, error)
 	FindByOwnerID(ownerID string, status string, month, year int, offset, limit int) ([]*entities.ServiceModel, int, error)
 	FindByDoctorID(doctorID string, status string, month, year int, offset, limit int) ([]*entities.ServiceModel, int, error)
 	FindByCaretakerID(caretakerID string, status string, month, year int, offset, limit int) ([]*entities.ServiceModel, int, error)
 	FindAll(status string, month, year int, offset, limit int) ([]*entities.ServiceModel, int, error)
-	UpdateStatus(serviceID, status string) error
+	UpdateStatusTx(tx *Tx, serviceID string, from, to db.ServiceStatus)
+	UpdateReserveDateTx(tx *Tx, serviceID string, start, end time.Time)
+	UpdateOwnerPetTx(tx *Tx, serviceID string, ownerID, petID *string)
+	FindUpcomingByStaffID(staffID string, from time.Time, to *time.Time) ([]*entities.ServiceModel, error)
+	FindByPetID(petID string, statuses []db.ServiceStatus) ([]*entities.ServiceModel, error)
 }
 
 func NewServiceRepository(db *ds.PrismaDB) IServiceRepository {
@@ -36,7 +50,7 @@ func NewServiceRepository(db *ds.PrismaDB) IServiceRepository {
 
 func (repo *serviceRepository) Insert(data entities.CreateServiceRequest) (*entities.ServiceModel, error) {
 	createdService, err := repo.Collection.Service.CreateOne(
-		db.Service.Status.Set(db.ServiceStatus(data.Status)),
+		db.Service.Status.Set(db.ServiceStatusWait),
 		db.Service.RdateStart.Set(data.ReserveDateStart),
 		db.Service.RdateEnd.Set(data.ReserveDateEnd),
 		db.Service.Owner.Link(db.Owner.UserID.Equals(data.OwnerID)),
@@ -53,6 +67,20 @@ func (repo *serviceRepository) Insert(data entities.CreateServiceRequest) (*enti
 	return result, nil
 }
 
+// InsertTx queues the insert on tx. The id is chosen by the caller so sub-rows
+// in the same transaction can link to it.
+func (repo *serviceRepository) InsertTx(tx *Tx, serviceID string, data entities.CreateServiceRequest) {
+	tx.add(repo.Collection.Service.CreateOne(
+		db.Service.Status.Set(db.ServiceStatusWait),
+		db.Service.RdateStart.Set(data.ReserveDateStart),
+		db.Service.RdateEnd.Set(data.ReserveDateEnd),
+		db.Service.Owner.Link(db.Owner.UserID.Equals(data.OwnerID)),
+		db.Service.Payment.Link(db.Payment.Payid.Equals(data.PaymentID)),
+		db.Service.Pet.Link(db.Pet.Petid.Equals(data.PetID)),
+		db.Service.Sid.Set(serviceID),
+	).Tx())
+}
+
 func (repo *serviceRepository) FindByID(serviceID string) (*entities.ServiceModel, error) {
 	service, err := repo.Collection.Service.FindUnique(
 		db.Service.Sid.Equals(serviceID),
@@ -69,6 +97,20 @@ func (repo *serviceRepository) FindByID(serviceID string) (*entities.ServiceMode
 	return mapServiceModel(service), nil
 }
 
+func (repo *serviceRepository) FindByPaymentID(paymentID string) (*entities.ServiceModel, error) {
+	service, err := repo.Collection.Service.FindUnique(
+		db.Service.Payid.Equals(paymentID),
+	).With(
+		db.Service.Cservice.Fetch(),
+		db.Service.Mservice.Fetch(),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("service -> FindByPaymentID: %w", err)
+	}
+
+	return mapServiceModel(service), nil
+}
+
 func (repo *serviceRepository) DeleteByID(serviceID string) (*entities.ServiceModel, error) {
 	deletedService, err := repo.Collection.Service.FindUnique(
 		db.Service.Sid.Equals(serviceID),
@@ -356,26 +398,114 @@ func (repo *serviceRepository) FindAll(status string, month, year int, offset, l
 	return result, total, err
 }
 
-func (repo *serviceRepository) UpdateStatus(serviceID, status string) error {
-	serviceStatus, ok := toServiceStatus(status)
-	if !ok {
-		return fmt.Errorf("service -> UpdateStatus: invalid status value")
-	}
+// UpdateStatusTx moves the service from -> to. If the status is no longer from when
+// the tx runs (someone else changed it first) the whole tx fails with ErrStatusChanged.
+func (repo *serviceRepository) UpdateStatusTx(tx *Tx, serviceID string, from, to db.ServiceStatus) {
+	tx.add(
+		repo.Collection.Prisma.ExecuteRaw(fmt.Sprintf(`
+			SELECT CAST(
+				CASE WHEN EXISTS (
+					SELECT 1 FROM "Service" WHERE "SID" = $1::uuid AND status = $2::service_status
+				) THEN '0' ELSE '%s' END
+			AS INTEGER)`, statusChangedMarker),
+			serviceID, string(from),
+		).Tx(),
+		repo.Collection.Service.FindUnique(
+			db.Service.Sid.Equals(serviceID),
+		).Update(
+			db.Service.Status.Set(to),
+		).Tx(),
+	)
+}
 
-	updateStatus, err := repo.Collection.Service.FindUnique(
+func (repo *serviceRepository) UpdateReserveDateTx(tx *Tx, serviceID string, start, end time.Time) {
+	tx.add(repo.Collection.Service.FindUnique(
 		db.Service.Sid.Equals(serviceID),
 	).Update(
-		db.Service.Status.Set(serviceStatus),
-	).Exec(repo.Context)
+		db.Service.RdateStart.Set(start),
+		db.Service.RdateEnd.Set(end),
+	).Tx())
+}
 
+func (repo *serviceRepository) UpdateOwnerPetTx(tx *Tx, serviceID string, ownerID, petID *string) {
+	updates := []db.ServiceSetParam{}
+	if ownerID != nil {
+		updates = append(updates, db.Service.Owner.Link(db.Owner.UserID.Equals(*ownerID)))
+	}
+	if petID != nil {
+		updates = append(updates, db.Service.Pet.Link(db.Pet.Petid.Equals(*petID)))
+	}
+	if len(updates) == 0 {
+		return
+	}
+	tx.add(repo.Collection.Service.FindUnique(
+		db.Service.Sid.Equals(serviceID),
+	).Update(updates...).Tx())
+}
+
+// FindUpcomingByStaffID lists wait bookings of the staff that start from from (until to
+// when given), ordered by start.
+func (repo *serviceRepository) FindUpcomingByStaffID(staffID string, from time.Time, to *time.Time) ([]*entities.ServiceModel, error) {
+	params := []db.ServiceWhereParam{
+		db.Service.Or(
+			db.Service.Cservice.Where(db.Cservice.Cid.Equals(staffID)),
+			db.Service.Mservice.Where(db.Mservice.Did.Equals(staffID)),
+		),
+		db.Service.Status.Equals(db.ServiceStatusWait),
+		db.Service.RdateStart.Gte(from),
+	}
+	if to != nil {
+		params = append(params, db.Service.RdateStart.Lt(*to))
+	}
+
+	services, err := repo.Collection.Service.FindMany(params...).With(
+		db.Service.Cservice.Fetch(),
+		db.Service.Mservice.Fetch(),
+	).OrderBy(
+		db.Service.RdateStart.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
 	if err != nil {
-		return fmt.Errorf("service -> UpdateStatus: %v", err)
+		return nil, fmt.Errorf("service -> FindUpcomingByStaffID: %v", err)
 	}
-	if updateStatus == nil {
-		return fmt.Errorf("service -> UpdateStatus: service not found")
+
+	results := make([]*entities.ServiceModel, 0, len(services))
+	for i := range services {
+		results = append(results, mapServiceModel(&services[i]))
 	}
+	return results, nil
+}
 
-	return nil
+// FindByPetID lists every service of the pet in the given statuses, oldest first, with
+// staff details.
+func (repo *serviceRepository) FindByPetID(petID string, statuses []db.ServiceStatus) ([]*entities.ServiceModel, error) {
+	services, err := repo.Collection.Service.FindMany(
+		db.Service.Petid.Equals(petID),
+		db.Service.Status.In(statuses),
+	).With(
+		db.Service.Mservice.Fetch().With(
+			db.Mservice.Doctor.Fetch().With(
+				db.Doctor.Users.Fetch(),
+			),
+		),
+		db.Service.Cservice.Fetch().With(
+			db.Cservice.Caretaker.Fetch().With(
+				db.Caretaker.Users.Fetch(),
+			),
+		),
+		db.Service.Pet.Fetch(),
+		db.Service.Payment.Fetch(),
+	).OrderBy(
+		db.Service.RdateStart.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("service -> FindByPetID: %v", err)
+	}
+
+	results := make([]*entities.ServiceModel, 0, len(services))
+	for i := range services {
+		results = append(results, addServiceAdditionModel(mapServiceModel(&services[i]), &services[i]))
+	}
+	return results, nil
 }
 
 func mapServiceModel(model *db.ServiceModel) *entities.ServiceModel {
@@ -418,7 +548,7 @@ func addServiceAdditionModel(service *entities.ServiceModel, model *db.ServiceMo
 		specialization, _ := caretaker.Specialties()
 		rating, _ := caretaker.Rating()
 		service.Staff = entities.StaffCommonData{
-			Role:            user.Role,
+			Role:            db.RoleCaretaker,
 			Name:            user.Name,
 			TelephoneNumber: user.TelephoneNumber,
 			Profile:         profile,
@@ -431,7 +561,7 @@ func addServiceAdditionModel(service *entities.ServiceModel, model *db.ServiceMo
 
 		profile, _ := user.ProfileImage()
 		service.Staff = entities.StaffCommonData{
-			Role:            user.Role,
+			Role:            db.RoleDoctor,
 			Name:            user.Name,
 			TelephoneNumber: user.TelephoneNumber,
 			Profile:         profile,
@@ -464,6 +594,10 @@ func addServiceAdditionModel(service *entities.ServiceModel, model *db.ServiceMo
 	return service
 }
 
+func isStatusChangedErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), statusChangedMarker)
+}
+
 func toServiceStatus(s string) (db.ServiceStatus, bool) {
 	switch s {
 	case "wait":
@@ -472,6 +606,10 @@ func toServiceStatus(s string) (db.ServiceStatus, bool) {
 		return db.ServiceStatusOngoing, true
 	case "finish":
 		return db.ServiceStatusFinish, true
+	case "cancelled":
+		return db.ServiceStatusCancelled, true
+	case "no_show":
+		return db.ServiceStatusNoShow, true
 	default:
 		return "", false // Return an empty value and false if the string is not a valid status
 	}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+type serviceCancellationRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IServiceCancellationRepository interface {
+	InsertPending(data entities.ServiceCancellationModel) (*entities.ServiceCancellationModel, error)
+	FindPendingByServiceID(serviceID string) (*entities.ServiceCancellationModel, error)
+	HasPendingByPaymentID(paymentID string) (bool, error)
+	CompleteTx(tx *Tx, data entities.ServiceCancellationModel)
+}
+
+func NewServiceCancellationRepository(db *ds.PrismaDB) IServiceCancellationRepository {
+	return &serviceCancellationRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+// InsertPending saves the planned refund before any money moves. SID is unique, so a
+// second cancel of the same service can't plan a different amount.
+func (repo *serviceCancellationRepository) InsertPending(data entities.ServiceCancellationModel) (*entities.ServiceCancellationModel, error) {
+	created, err := repo.Collection.ServiceCancellation.CreateOne(
+		db.ServiceCancellation.CancelledBy.Set(data.CancelledBy),
+		db.ServiceCancellation.CancelledByRole.Set(data.CancelledByRole),
+		db.ServiceCancellation.RefundAmount.Set(data.RefundAmount),
+		db.ServiceCancellation.Service.Link(db.Service.Sid.Equals(data.ServiceID)),
+		db.ServiceCancellation.Reason.SetIfPresent(data.Reason),
+		db.ServiceCancellation.RefundPercent.Set(data.RefundPercent),
+		db.ServiceCancellation.Pending.Set(true),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("service cancellation -> InsertPending: %v", err)
+	}
+
+	result := data
+	result.CreatedAt = created.CreatedAt
+	return &result, nil
+}
+
+func (repo *serviceCancellationRepository) FindPendingByServiceID(serviceID string) (*entities.ServiceCancellationModel, error) {
+	cancellation, err := repo.Collection.ServiceCancellation.FindFirst(
+		db.ServiceCancellation.Sid.Equals(serviceID),
+		db.ServiceCancellation.Pending.Equals(true),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("service cancellation -> FindPendingByServiceID: %w", err)
+	}
+
+	result := &entities.ServiceCancellationModel{
+		ServiceID:       cancellation.Sid,
+		CancelledBy:     cancellation.CancelledBy,
+		CancelledByRole: cancellation.CancelledByRole,
+		RefundPercent:   cancellation.RefundPercent,
+		RefundAmount:    cancellation.RefundAmount,
+		CreatedAt:       cancellation.CreatedAt,
+	}
+	if reason, ok := cancellation.Reason(); ok {
+		result.Reason = &reason
+	}
+	return result, nil
+}
+
+// HasPendingByPaymentID reports whether the booking paid by paymentID, or by the payment
+// an adjustment paymentID belongs to, is in the middle of being cancelled.
+func (repo *serviceCancellationRepository) HasPendingByPaymentID(paymentID string) (bool, error) {
+	var sqlResult []struct {
+		Count int `json:"count"`
+	}
+	err := repo.Collection.Prisma.QueryRaw(`
+		SELECT CAST(COUNT(*) AS INTEGER) AS count
+		FROM "ServiceCancellation" c
+		JOIN "Service" s ON s."SID" = c."SID"
+		JOIN "Payment" p ON s."PAYID" = COALESCE(p.parent_payid, p."PAYID")
+		WHERE c.pending AND p."PAYID" = $1::uuid`,
+		paymentID,
+	).Exec(repo.Context, &sqlResult)
+	if err != nil {
+		return false, fmt.Errorf("service cancellation -> HasPendingByPaymentID: %v", err)
+	}
+
+	return len(sqlResult) > 0 && sqlResult[0].Count > 0, nil
+}
+
+// CompleteTx finishes the pending row once the refunds went through.
+func (repo *serviceCancellationRepository) CompleteTx(tx *Tx, data entities.ServiceCancellationModel) {
+	tx.add(repo.Collection.ServiceCancellation.FindUnique(
+		db.ServiceCancellation.Sid.Equals(data.ServiceID),
+	).Update(
+		db.ServiceCancellation.StripeRefundIds.Set(stripeRefundIDs(data.Refunds)),
+		db.ServiceCancellation.Pending.Set(false),
+		db.ServiceCancellation.CreatedAt.Set(data.CreatedAt),
+	).Tx())
+}
+
+func stripeRefundIDs(refunds []entities.PaymentRefundModel) []string {
+	ids := make([]string, 0, len(refunds))
+	for _, refund := range refunds {
+		ids = append(ids, refund.StripeRefundID)
+	}
+	return ids
+}
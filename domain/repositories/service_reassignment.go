@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+// ErrReassignmentClosed is returned by Commit when another admin resolved or
+// dismissed the reassignment first.
+var ErrReassignmentClosed = errors.New("reassignment was already closed")
+
+const reassignmentClosedMarker = "reassignment_closed"
+
+type serviceReassignmentRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IServiceReassignmentRepository interface {
+	InsertTx(tx *Tx, data entities.ServiceReassignmentModel)
+	CloseTx(tx *Tx, reassignmentID string, to db.ReassignmentStatus, toStaffID *string, resolvedBy string, resolvedAt time.Time, note *string)
+	FindByID(reassignmentID string) (*entities.ServiceReassignmentModel, error)
+	FindByServiceID(serviceID string) ([]*entities.ServiceReassignmentModel, error)
+	FindByStatus(status db.ReassignmentStatus) ([]*entities.ServiceReassignmentModel, error)
+}
+
+func NewServiceReassignmentRepository(db *ds.PrismaDB) IServiceReassignmentRepository {
+	return &serviceReassignmentRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+func (repo *serviceReassignmentRepository) InsertTx(tx *Tx, data entities.ServiceReassignmentModel) {
+	tx.add(repo.Collection.ServiceReassignment.CreateOne(
+		db.ServiceReassignment.ServiceType.Set(data.ServiceType),
+		db.ServiceReassignment.FromStaffID.Set(data.FromStaffID),
+		db.ServiceReassignment.Reason.Set(data.Reason),
+		db.ServiceReassignment.Status.Set(data.Status),
+		db.ServiceReassignment.Service.Link(db.Service.Sid.Equals(data.ServiceID)),
+		db.ServiceReassignment.ID.Set(data.ID),
+		db.ServiceReassignment.ToStaffID.SetIfPresent(data.ToStaffID),
+		db.ServiceReassignment.Note.SetIfPresent(data.Note),
+		db.ServiceReassignment.CreatedBy.SetIfPresent(data.CreatedBy),
+	).Tx())
+}
+
+// CloseTx moves a needs_action row to resolved or dismissed. If it is no longer
+// needs_action when the tx runs the whole tx fails with ErrReassignmentClosed.
+func (repo *serviceReassignmentRepository) CloseTx(tx *Tx, reassignmentID string, to db.ReassignmentStatus, toStaffID *string, resolvedBy string, resolvedAt time.Time, note *string) {
+	tx.add(
+		repo.Collection.Prisma.ExecuteRaw(fmt.Sprintf(`
+			SELECT CAST(
+				CASE WHEN EXISTS (
+					SELECT 1 FROM "ServiceReassignment" WHERE id = $1::uuid AND status = 'needs_action'
+				) THEN '0' ELSE '%s' END
+			AS INTEGER)`, reassignmentClosedMarker),
+			reassignmentID,
+		).Tx(),
+		repo.Collection.ServiceReassignment.FindUnique(
+			db.ServiceReassignment.ID.Equals(reassignmentID),
+		).Update(
+			db.ServiceReassignment.Status.Set(to),
+			db.ServiceReassignment.ResolvedBy.Set(resolvedBy),
+			db.ServiceReassignment.ResolvedAt.Set(resolvedAt),
+			db.ServiceReassignment.ToStaffID.SetIfPresent(toStaffID),
+			db.ServiceReassignment.Note.SetIfPresent(note),
+		).Tx(),
+	)
+}
+
+func (repo *serviceReassignmentRepository) FindByID(reassignmentID string) (*entities.ServiceReassignmentModel, error) {
+	reassignment, err := repo.Collection.ServiceReassignment.FindUnique(
+		db.ServiceReassignment.ID.Equals(reassignmentID),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("service reassignment -> FindByID: %w", err)
+	}
+
+	return mapServiceReassignmentModel(reassignment), nil
+}
+
+func (repo *serviceReassignmentRepository) FindByServiceID(serviceID string) ([]*entities.ServiceReassignmentModel, error) {
+	rows, err := repo.Collection.ServiceReassignment.FindMany(
+		db.ServiceReassignment.Sid.Equals(serviceID),
+	).OrderBy(
+		db.ServiceReassignment.CreatedAt.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("service reassignment -> FindByServiceID: %v", err)
+	}
+
+	return mapServiceReassignmentModels(rows), nil
+}
+
+func (repo *serviceReassignmentRepository) FindByStatus(status db.ReassignmentStatus) ([]*entities.ServiceReassignmentModel, error) {
+	rows, err := repo.Collection.ServiceReassignment.FindMany(
+		db.ServiceReassignment.Status.Equals(status),
+	).OrderBy(
+		db.ServiceReassignment.CreatedAt.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("service reassignment -> FindByStatus: %v", err)
+	}
+
+	return mapServiceReassignmentModels(rows), nil
+}
+
+func mapServiceReassignmentModels(rows []db.ServiceReassignmentModel) []*entities.ServiceReassignmentModel {
+	results := make([]*entities.ServiceReassignmentModel, 0, len(rows))
+	for i := range rows {
+		results = append(results, mapServiceReassignmentModel(&rows[i]))
+	}
+	return results
+}
+
+func mapServiceReassignmentModel(model *db.ServiceReassignmentModel) *entities.ServiceReassignmentModel {
+	result := &entities.ServiceReassignmentModel{
+		ID:          model.ID,
+		ServiceID:   model.Sid,
+		ServiceType: model.ServiceType,
+		FromStaffID: model.FromStaffID,
+		Reason:      model.Reason,
+		Status:      model.Status,
+		CreatedAt:   model.CreatedAt,
+	}
+	if toStaffID, ok := model.ToStaffID(); ok {
+		result.ToStaffID = &toStaffID
+	}
+	if note, ok := model.Note(); ok {
+		result.Note = &note
+	}
+	if createdBy, ok := model.CreatedBy(); ok {
+		result.CreatedBy = &createdBy
+	}
+	if resolvedBy, ok := model.ResolvedBy(); ok {
+		result.ResolvedBy = &resolvedBy
+	}
+	if resolvedAt, ok := model.ResolvedAt(); ok {
+		result.ResolvedAt = &resolvedAt
+	}
+
+	return result
+}
+
+func isReassignmentClosedErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), reassignmentClosedMarker)
+}
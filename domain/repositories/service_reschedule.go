@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+type serviceRescheduleRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IServiceRescheduleRepository interface {
+	InsertTx(tx *Tx, data entities.ServiceRescheduleModel)
+	FindByID(rescheduleID string) (*entities.ServiceRescheduleModel, error)
+	FindPending(serviceID string) (*entities.ServiceRescheduleModel, error)
+	UpdateStatusTx(tx *Tx, rescheduleID string, from, to db.RescheduleStatus)
+	CompleteTx(tx *Tx, data entities.ServiceRescheduleModel)
+}
+
+func NewServiceRescheduleRepository(db *ds.PrismaDB) IServiceRescheduleRepository {
+	return &serviceRescheduleRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+func (repo *serviceRescheduleRepository) InsertTx(tx *Tx, data entities.ServiceRescheduleModel) {
+	optional := []db.ServiceRescheduleSetParam{
+		db.ServiceReschedule.ID.Set(data.ID),
+		db.ServiceReschedule.Status.Set(data.Status),
+		db.ServiceReschedule.StripeRefundIds.Set(stripeRefundIDs(data.Refunds)),
+	}
+	if data.AdjustmentPaymentID != nil {
+		optional = append(optional, db.ServiceReschedule.Payment.Link(db.Payment.Payid.Equals(*data.AdjustmentPaymentID)))
+	}
+
+	tx.add(repo.Collection.ServiceReschedule.CreateOne(
+		db.ServiceReschedule.RequestedBy.Set(data.RequestedBy),
+		db.ServiceReschedule.OldStart.Set(data.OldStart),
+		db.ServiceReschedule.OldEnd.Set(data.OldEnd),
+		db.ServiceReschedule.NewStart.Set(data.NewStart),
+		db.ServiceReschedule.NewEnd.Set(data.NewEnd),
+		db.ServiceReschedule.OldPrice.Set(data.OldPrice),
+		db.ServiceReschedule.NewPrice.Set(data.NewPrice),
+		db.ServiceReschedule.Service.Link(db.Service.Sid.Equals(data.ServiceID)),
+		optional...,
+	).Tx())
+}
+
+func (repo *serviceRescheduleRepository) FindByID(rescheduleID string) (*entities.ServiceRescheduleModel, error) {
+	reschedule, err := repo.Collection.ServiceReschedule.FindUnique(
+		db.ServiceReschedule.ID.Equals(rescheduleID),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("service reschedule -> FindByID: %w", err)
+	}
+
+	return mapServiceRescheduleModel(reschedule), nil
+}
+
+// FindPending returns the reschedule of the service that is still pending, or
+// db.ErrNotFound when there is none.
+func (repo *serviceRescheduleRepository) FindPending(serviceID string) (*entities.ServiceRescheduleModel, error) {
+	reschedule, err := repo.Collection.ServiceReschedule.FindFirst(
+		db.ServiceReschedule.Sid.Equals(serviceID),
+		db.ServiceReschedule.Status.Equals(db.RescheduleStatusPending),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("service reschedule -> FindPending: %w", err)
+	}
+
+	return mapServiceRescheduleModel(reschedule), nil
+}
+
+// UpdateStatusTx only moves rows that are still in from, so a late webhook can't
+// re-apply or resurrect a reschedule.
+func (repo *serviceRescheduleRepository) UpdateStatusTx(tx *Tx, rescheduleID string, from, to db.RescheduleStatus) {
+	tx.add(repo.Collection.ServiceReschedule.FindMany(
+		db.ServiceReschedule.ID.Equals(rescheduleID),
+		db.ServiceReschedule.Status.Equals(from),
+	).Update(
+		db.ServiceReschedule.Status.Set(to),
+	).Tx())
+}
+
+// CompleteTx marks a pending reschedule applied together with its refunds. If the row
+// is no longer pending (another request applied it first) the whole tx fails with
+// ErrStatusChanged, so the refunds can't be written twice.
+func (repo *serviceRescheduleRepository) CompleteTx(tx *Tx, data entities.ServiceRescheduleModel) {
+	tx.add(
+		repo.Collection.Prisma.ExecuteRaw(fmt.Sprintf(`
+			SELECT CAST(
+				CASE WHEN EXISTS (
+					SELECT 1 FROM "ServiceReschedule" WHERE id = $1::uuid AND status = $2::reschedule_status
+				) THEN '0' ELSE '%s' END
+			AS INTEGER)`, statusChangedMarker),
+			data.ID, string(db.RescheduleStatusPending),
+		).Tx(),
+		repo.Collection.ServiceReschedule.FindUnique(
+			db.ServiceReschedule.ID.Equals(data.ID),
+		).Update(
+			db.ServiceReschedule.Status.Set(db.RescheduleStatusApplied),
+			db.ServiceReschedule.StripeRefundIds.Set(stripeRefundIDs(data.Refunds)),
+		).Tx(),
+	)
+}
+
+func mapServiceRescheduleModel(model *db.ServiceRescheduleModel) *entities.ServiceRescheduleModel {
+	result := &entities.ServiceRescheduleModel{
+		ID:          model.ID,
+		ServiceID:   model.Sid,
+		RequestedBy: model.RequestedBy,
+		OldStart:    model.OldStart,
+		OldEnd:      model.OldEnd,
+		NewStart:    model.NewStart,
+		NewEnd:      model.NewEnd,
+		OldPrice:    model.OldPrice,
+		NewPrice:    model.NewPrice,
+		PriceDelta:  model.NewPrice - model.OldPrice,
+		Status:      model.Status,
+		CreatedAt:   model.CreatedAt,
+	}
+	if paymentID, ok := model.Payid(); ok {
+		result.AdjustmentPaymentID = &paymentID
+	}
+
+	return result
+}
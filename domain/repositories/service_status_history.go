@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+type serviceStatusHistoryRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IServiceStatusHistoryRepository interface {
+	InsertTx(tx *Tx, data entities.ServiceStatusHistoryModel)
+	FindByServiceID(serviceID string) ([]*entities.ServiceStatusHistoryModel, error)
+}
+
+func NewServiceStatusHistoryRepository(db *ds.PrismaDB) IServiceStatusHistoryRepository {
+	return &serviceStatusHistoryRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+func (repo *serviceStatusHistoryRepository) InsertTx(tx *Tx, data entities.ServiceStatusHistoryModel) {
+	optional := []db.ServiceStatusHistorySetParam{
+		db.ServiceStatusHistory.ChangedBy.SetIfPresent(data.ChangedBy),
+		db.ServiceStatusHistory.Note.SetIfPresent(data.Note),
+	}
+	if data.FromStatus != nil {
+		optional = append(optional, db.ServiceStatusHistory.FromStatus.Set(*data.FromStatus))
+	}
+	if data.ChangedByRole != nil {
+		optional = append(optional, db.ServiceStatusHistory.ChangedByRole.Set(*data.ChangedByRole))
+	}
+
+	tx.add(repo.Collection.ServiceStatusHistory.CreateOne(
+		db.ServiceStatusHistory.ToStatus.Set(data.ToStatus),
+		db.ServiceStatusHistory.Service.Link(db.Service.Sid.Equals(data.ServiceID)),
+		optional...,
+	).Tx())
+}
+
+func (repo *serviceStatusHistoryRepository) FindByServiceID(serviceID string) ([]*entities.ServiceStatusHistoryModel, error) {
+	rows, err := repo.Collection.ServiceStatusHistory.FindMany(
+		db.ServiceStatusHistory.Sid.Equals(serviceID),
+	).OrderBy(
+		db.ServiceStatusHistory.CreatedAt.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("service status history -> FindByServiceID: %v", err)
+	}
+
+	history := make([]*entities.ServiceStatusHistoryModel, 0, len(rows))
+	for i := range rows {
+		history = append(history, mapServiceStatusHistoryModel(&rows[i]))
+	}
+
+	return history, nil
+}
+
+func mapServiceStatusHistoryModel(model *db.ServiceStatusHistoryModel) *entities.ServiceStatusHistoryModel {
+	result := &entities.ServiceStatusHistoryModel{
+		ID:        model.ID,
+		ServiceID: model.Sid,
+		ToStatus:  model.ToStatus,
+		CreatedAt: model.CreatedAt,
+	}
+	if from, ok := model.FromStatus(); ok {
+		result.FromStatus = &from
+	}
+	if changedBy, ok := model.ChangedBy(); ok {
+		result.ChangedBy = &changedBy
+	}
+	if role, ok := model.ChangedByRole(); ok {
+		result.ChangedByRole = &role
+	}
+	if note, ok := model.Note(); ok {
+		result.Note = &note
+	}
+
+	return result
+}
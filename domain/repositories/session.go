@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+// แถวที่ revoke แล้วเก็บไว้อีกพักนึง เผื่อ token เก่าถูกส่งมาซ้ำจะได้ตอบว่า revoke แล้ว
+const revokedSessionRetention = 24 * time.Hour
+
+type sessionRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type ISessionRepository interface {
+	Insert(data entities.SessionModel) (*entities.SessionModel, error)
+	FindByID(sessionID string) (*entities.SessionModel, error)
+	FindByTokenHash(hash string) (*entities.SessionModel, error)
+	Rotate(sessionID, oldHash, newHash string, expiresAt time.Time) (bool, error)
+	Revoke(sessionID string) error
+	RevokeByUserID(userID string) (int, error)
+	RevokeByUserIDTx(tx *Tx, userID string)
+	RevokeWithoutMfa(role db.Role) (int, error)
+	DeleteExpiredTx(tx *Tx, now time.Time)
+}
+
+func NewSessionRepository(db *ds.PrismaDB) ISessionRepository {
+	return &sessionRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+func (repo *sessionRepository) Insert(data entities.SessionModel) (*entities.SessionModel, error) {
+	params := []db.SessionSetParam{}
+	if data.Device != "" {
+		params = append(params, db.Session.Device.Set(data.Device))
+	}
+
+	created, err := repo.Collection.Session.CreateOne(
+		db.Session.Role.Set(data.Role),
+		db.Session.RefreshHash.Set(data.RefreshHash),
+		db.Session.ExpiresAt.Set(data.ExpiresAt),
+		db.Session.Users.Link(db.Users.ID.Equals(data.UserID)),
+		append(params, db.Session.MfaVerified.Set(data.MfaVerified))...,
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("session -> Insert: %v", err)
+	}
+
+	return mapSessionModel(created), nil
+}
+
+func (repo *sessionRepository) FindByID(sessionID string) (*entities.SessionModel, error) {
+	session, err := repo.Collection.Session.FindUnique(
+		db.Session.ID.Equals(sessionID),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("session -> FindByID: %w", err)
+	}
+
+	return mapSessionModel(session), nil
+}
+
+// FindByTokenHash matches the current refresh token of a session or the one it replaced.
+func (repo *sessionRepository) FindByTokenHash(hash string) (*entities.SessionModel, error) {
+	session, err := repo.Collection.Session.FindFirst(
+		db.Session.Or(
+			db.Session.RefreshHash.Equals(hash),
+			db.Session.PreviousHash.Equals(hash),
+		),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("session -> FindByTokenHash: %w", err)
+	}
+
+	return mapSessionModel(session), nil
+}
+
+// Rotate swaps the refresh token only if oldHash is still the current one, so two requests
+// racing with the same token cannot both get a new one. It reports whether the swap happened.
+func (repo *sessionRepository) Rotate(sessionID, oldHash, newHash string, expiresAt time.Time) (bool, error) {
+	result, err := repo.Collection.Session.FindMany(
+		db.Session.ID.Equals(sessionID),
+		db.Session.RefreshHash.Equals(oldHash),
+		db.Session.RevokedAt.IsNull(),
+	).Update(
+		db.Session.RefreshHash.Set(newHash),
+		db.Session.PreviousHash.Set(oldHash),
+		db.Session.LastUsedAt.Set(time.Now()),
+		db.Session.ExpiresAt.Set(expiresAt),
+	).Exec(repo.Context)
+	if err != nil {
+		return false, fmt.Errorf("session -> Rotate: %v", err)
+	}
+
+	return result.Count > 0, nil
+}
+
+func (repo *sessionRepository) Revoke(sessionID string) error {
+	_, err := repo.Collection.Session.FindMany(
+		db.Session.ID.Equals(sessionID),
+		db.Session.RevokedAt.IsNull(),
+	).Update(
+		db.Session.RevokedAt.Set(time.Now()),
+	).Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("session -> Revoke: %v", err)
+	}
+
+	return nil
+}
+
+func (repo *sessionRepository) RevokeByUserID(userID string) (int, error) {
+	result, err := repo.Collection.Session.FindMany(
+		db.Session.UserID.Equals(userID),
+		db.Session.RevokedAt.IsNull(),
+	).Update(
+		db.Session.RevokedAt.Set(time.Now()),
+	).Exec(repo.Context)
+	if err != nil {
+		return 0, fmt.Errorf("session -> RevokeByUserID: %v", err)
+	}
+
+	return result.Count, nil
+}
+
+func (repo *sessionRepository) RevokeByUserIDTx(tx *Tx, userID string) {
+	tx.add(repo.Collection.Session.FindMany(
+		db.Session.UserID.Equals(userID),
+		db.Session.RevokedAt.IsNull(),
+	).Update(
+		db.Session.RevokedAt.Set(time.Now()),
+	).Tx())
+}
+
+// RevokeWithoutMfa closes the sessions of role that never passed a second factor.
+func (repo *sessionRepository) RevokeWithoutMfa(role db.Role) (int, error) {
+	result, err := repo.Collection.Session.FindMany(
+		db.Session.Role.Equals(role),
+		db.Session.MfaVerified.Equals(false),
+		db.Session.RevokedAt.IsNull(),
+	).Update(
+		db.Session.RevokedAt.Set(time.Now()),
+	).Exec(repo.Context)
+	if err != nil {
+		return 0, fmt.Errorf("session -> RevokeWithoutMfa: %v", err)
+	}
+
+	return result.Count, nil
+}
+
+func (repo *sessionRepository) DeleteExpiredTx(tx *Tx, now time.Time) {
+	tx.add(repo.Collection.Session.FindMany(
+		db.Session.Or(
+			db.Session.ExpiresAt.Lt(now),
+			db.Session.RevokedAt.Lt(now.Add(-revokedSessionRetention)),
+		),
+	).Delete().Tx())
+}
+
+func mapSessionModel(model *db.SessionModel) *entities.SessionModel {
+	result := &entities.SessionModel{
+		ID:          model.ID,
+		UserID:      model.UserID,
+		Role:        model.Role,
+		RefreshHash: model.RefreshHash,
+		CreatedAt:   model.CreatedAt,
+		LastUsedAt:  model.LastUsedAt,
+		ExpiresAt:   model.ExpiresAt,
+		MfaVerified: model.MfaVerified,
+	}
+	result.Device, _ = model.Device()
+	result.PreviousHash, _ = model.PreviousHash()
+	if revokedAt, ok := model.RevokedAt(); ok {
+		result.RevokedAt = &revokedAt
+	}
+
+	return result
+}
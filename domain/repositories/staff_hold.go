@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"strings"
+	"time"
+)
+
+// ErrStaffUnavailable is returned when the staff already has a booking, an active
+// hold, a leave day or an unavailable period inside the requested range.
+var ErrStaffUnavailable = errors.New("staff is not available in this time range")
+
+// the guard query casts this text to integer when it finds a conflict, which aborts
+// the whole transaction; Commit maps it back to ErrStaffUnavailable
+const staffUnavailableMarker = "staff_unavailable"
+
+type staffHoldRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IStaffHoldRepository interface {
+	HasConflict(staffID string, start, end time.Time, paymentID string) (bool, error)
+	FindBusyRanges(staffID string, start, end time.Time) ([]entities.TimeRangeModel, error)
+	GuardSlotTx(tx *Tx, staffID string, start, end time.Time, paymentID string)
+	InsertTx(tx *Tx, hold entities.StaffHoldModel)
+	DeleteByPaymentIDTx(tx *Tx, paymentID string)
+	DeleteByPaymentID(paymentID string) error
+	DeleteExpiredTx(tx *Tx, now time.Time)
+}
+
+func NewStaffHoldRepository(db *ds.PrismaDB) IStaffHoldRepository {
+	return &staffHoldRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+func (repo *staffHoldRepository) HasConflict(staffID string, start, end time.Time, paymentID string) (bool, error) {
+	var sqlResult []entities.CountResult
+	sql := fmt.Sprintf(`SELECT CAST(COUNT(*) AS INTEGER) AS count FROM (%s) conflicts`, staffConflictSQL)
+	err := repo.Collection.Prisma.QueryRaw(sql, staffID, start, end, conflictPaymentID(paymentID)).Exec(repo.Context, &sqlResult)
+	if err != nil {
+		return false, fmt.Errorf("staff hold -> HasConflict: %v", err)
+	}
+
+	return len(sqlResult) > 0 && sqlResult[0].Count > 0, nil
+}
+
+// FindBusyRanges lists everything that blocks the staff between start and end,
+// by the same rules as HasConflict. Ranges may overlap and run past start/end.
+func (repo *staffHoldRepository) FindBusyRanges(staffID string, start, end time.Time) ([]entities.TimeRangeModel, error) {
+	var ranges []entities.TimeRangeModel
+	sql := staffBusySQL + ` ORDER BY "start"`
+	err := repo.Collection.Prisma.QueryRaw(sql, staffID, start, end, conflictPaymentID("")).Exec(repo.Context, &ranges)
+	if err != nil {
+		return nil, fmt.Errorf("staff hold -> FindBusyRanges: %v", err)
+	}
+
+	return ranges, nil
+}
+
+// GuardSlotTx serialises writers for the same staff with an advisory lock and
+// fails the transaction if the range is already taken by someone else.
+func (repo *staffHoldRepository) GuardSlotTx(tx *Tx, staffID string, start, end time.Time, paymentID string) {
+	tx.add(
+		repo.Collection.Prisma.ExecuteRaw(`SELECT pg_advisory_xact_lock(hashtext($1))`, staffID).Tx(),
+		repo.Collection.Prisma.ExecuteRaw(fmt.Sprintf(`
+			SELECT CAST(
+				CASE WHEN EXISTS (%s) THEN '%s' ELSE '0' END
+			AS INTEGER)`, staffConflictSQL, staffUnavailableMarker),
+			staffID, start, end, conflictPaymentID(paymentID),
+		).Tx(),
+	)
+}
+
+func (repo *staffHoldRepository) InsertTx(tx *Tx, hold entities.StaffHoldModel) {
+	// checkout ใหม่ของ payment เดิมให้แทนที่ hold เก่า
+	repo.DeleteByPaymentIDTx(tx, hold.PaymentID)
+	tx.add(repo.Collection.StaffHold.CreateOne(
+		db.StaffHold.StaffID.Set(hold.StaffID),
+		db.StaffHold.RdateStart.Set(hold.ReserveDateStart),
+		db.StaffHold.RdateEnd.Set(hold.ReserveDateEnd),
+		db.StaffHold.ExpiresAt.Set(hold.ExpiresAt),
+		db.StaffHold.Payment.Link(db.Payment.Payid.Equals(hold.PaymentID)),
+	).Tx())
+}
+
+func (repo *staffHoldRepository) DeleteByPaymentIDTx(tx *Tx, paymentID string) {
+	tx.add(repo.Collection.StaffHold.FindMany(
+		db.StaffHold.Payid.Equals(paymentID),
+	).Delete().Tx())
+}
+
+func (repo *staffHoldRepository) DeleteByPaymentID(paymentID string) error {
+	_, err := repo.Collection.StaffHold.FindMany(
+		db.StaffHold.Payid.Equals(paymentID),
+	).Delete().Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("staff hold -> DeleteByPaymentID: %v", err)
+	}
+
+	return nil
+}
+
+// hold ที่หมดอายุไม่มีผลกับ conflict อยู่แล้ว ลบทิ้งไม่ให้ตารางโต
+func (repo *staffHoldRepository) DeleteExpiredTx(tx *Tx, now time.Time) {
+	tx.add(repo.Collection.StaffHold.FindMany(
+		db.StaffHold.ExpiresAt.Lt(now),
+	).Delete().Tx())
+}
+
+// booking ที่ยังไม่มี payment ไม่ต้องยกเว้นอะไร แต่ $4 ต้องเป็น uuid ที่ valid
+func conflictPaymentID(paymentID string) string {
+	if paymentID == "" {
+		return "00000000-0000-0000-0000-000000000000"
+	}
+	return paymentID
+}
+
+func isStaffUnavailableErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), staffUnavailableMarker)
+}
+
+// $1 staff id, $2 start, $3 end, $4 payment id of the booking being checked
+const staffConflictSQL = `SELECT 1 FROM (` + staffBusySQL + `) busy`
+
+// payment ของ booking เดียวกัน: $4 เอง, payment แรกถ้า $4 เป็นส่วนต่าง และส่วนต่างของ $4
+// hold ของ reschedule ที่รอจ่ายถือไว้ในชื่อ payment ส่วนต่าง ต้องไม่นับว่าชนกับ booking ตัวเอง
+const ownPaymentsSQL = `(
+	SELECT $4::uuid
+	UNION SELECT p.parent_payid FROM "Payment" p WHERE p."PAYID" = $4::uuid AND p.parent_payid IS NOT NULL
+	UNION SELECT p."PAYID" FROM "Payment" p WHERE p.parent_payid = $4::uuid
+)`
+
+// ช่วงที่ staff ไม่ว่าง: booking ที่ยังไม่จบ, hold ที่ยังไม่หมดอายุ, วันลา และช่วงที่แจ้งว่าไม่ว่าง
+// parameters เหมือน staffConflictSQL
+const staffBusySQL = `
+	SELECT s.rdate_start AS "start", s.rdate_end AS "end" FROM "Service" s
+	LEFT JOIN "Cservice" c ON c."SID" = s."SID"
+	LEFT JOIN "Mservice" m ON m."SID" = s."SID"
+	WHERE (c."CID" = $1::uuid OR m."DID" = $1::uuid)
+	  AND s.status NOT IN ('finish', 'cancelled', 'no_show')
+	  AND s."PAYID" NOT IN ` + ownPaymentsSQL + `
+	  AND s.rdate_start < $3::timestamptz AND s.rdate_end > $2::timestamptz
+	UNION ALL
+	SELECT h.rdate_start, h.rdate_end FROM "StaffHold" h
+	WHERE h.staff_id = $1::uuid
+	  AND h.expires_at > now()
+	  AND h."PAYID" NOT IN ` + ownPaymentsSQL + `
+	  AND h.rdate_start < $3::timestamptz AND h.rdate_end > $2::timestamptz
+	UNION ALL
+	SELECT l.leaveday::timestamp AT TIME ZONE 'Asia/Bangkok', (l.leaveday + 1)::timestamp AT TIME ZONE 'Asia/Bangkok' FROM "Leaveday" l
+	WHERE (l."CID" = $1::uuid OR l."DID" = $1::uuid)
+	  AND l.status IN ('pending', 'approved')
+	  AND l.leaveday::timestamp AT TIME ZONE 'Asia/Bangkok' < $3::timestamptz
+	  AND (l.leaveday + 1)::timestamp AT TIME ZONE 'Asia/Bangkok' > $2::timestamptz
+	UNION ALL
+	SELECT e.start_at, e.end_at FROM "StaffScheduleException" e
+	WHERE e.staff_id = $1::uuid
+	  AND e.kind = 'unavailable'
+	  AND e.start_at < $3::timestamptz AND e.end_at > $2::timestamptz`
@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"time"
+)
+
+// shift เก็บเป็น column time ส่วนวันที่ไม่มีความหมาย
+const clockLayout = "15:04"
+
+type staffScheduleRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IStaffScheduleRepository interface {
+	FindByStaffID(staffID string) ([]entities.StaffShiftModel, error)
+	FindByStaffIDs(staffIDs []string) (map[string][]entities.StaffShiftModel, error)
+	ReplaceTx(tx *Tx, staffID string, shifts []entities.StaffShiftModel) error
+	InsertException(data entities.StaffScheduleExceptionModel) (*entities.StaffScheduleExceptionModel, error)
+	FindExceptionByID(exceptionID string) (*entities.StaffScheduleExceptionModel, error)
+	FindExceptions(staffIDs []string, start, end time.Time) ([]entities.StaffScheduleExceptionModel, error)
+	DeleteException(exceptionID string) error
+}
+
+func NewStaffScheduleRepository(db *ds.PrismaDB) IStaffScheduleRepository {
+	return &staffScheduleRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+func (repo *staffScheduleRepository) FindByStaffID(staffID string) ([]entities.StaffShiftModel, error) {
+	rows, err := repo.Collection.StaffShift.FindMany(
+		db.StaffShift.StaffID.Equals(staffID),
+	).OrderBy(
+		db.StaffShift.Weekday.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("staff schedule -> FindByStaffID: %v", err)
+	}
+
+	shifts := make([]entities.StaffShiftModel, 0, len(rows))
+	for i := range rows {
+		shifts = append(shifts, mapStaffShiftModel(&rows[i]))
+	}
+
+	return shifts, nil
+}
+
+// FindByStaffIDs loads the weekly schedules of several staff at once. Staff without
+// one are left out of the map.
+func (repo *staffScheduleRepository) FindByStaffIDs(staffIDs []string) (map[string][]entities.StaffShiftModel, error) {
+	rows, err := repo.Collection.StaffShift.FindMany(
+		db.StaffShift.StaffID.In(staffIDs),
+	).OrderBy(
+		db.StaffShift.Weekday.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("staff schedule -> FindByStaffIDs: %v", err)
+	}
+
+	shifts := make(map[string][]entities.StaffShiftModel)
+	for i := range rows {
+		shifts[rows[i].StaffID] = append(shifts[rows[i].StaffID], mapStaffShiftModel(&rows[i]))
+	}
+
+	return shifts, nil
+}
+
+// ReplaceTx swaps the whole weekly schedule of the staff.
+func (repo *staffScheduleRepository) ReplaceTx(tx *Tx, staffID string, shifts []entities.StaffShiftModel) error {
+	tx.add(repo.Collection.StaffShift.FindMany(
+		db.StaffShift.StaffID.Equals(staffID),
+	).Delete().Tx())
+
+	for _, shift := range shifts {
+		start, err := time.Parse(clockLayout, shift.StartTime)
+		if err != nil {
+			return fmt.Errorf("staff schedule -> ReplaceTx: invalid start_time %q", shift.StartTime)
+		}
+		end, err := time.Parse(clockLayout, shift.EndTime)
+		if err != nil {
+			return fmt.Errorf("staff schedule -> ReplaceTx: invalid end_time %q", shift.EndTime)
+		}
+		tx.add(repo.Collection.StaffShift.CreateOne(
+			db.StaffShift.StaffID.Set(staffID),
+			db.StaffShift.Weekday.Set(shift.Weekday),
+			db.StaffShift.StartTime.Set(start),
+			db.StaffShift.EndTime.Set(end),
+		).Tx())
+	}
+
+	return nil
+}
+
+func (repo *staffScheduleRepository) InsertException(data entities.StaffScheduleExceptionModel) (*entities.StaffScheduleExceptionModel, error) {
+	created, err := repo.Collection.StaffScheduleException.CreateOne(
+		db.StaffScheduleException.StaffID.Set(data.StaffID),
+		db.StaffScheduleException.Kind.Set(data.Kind),
+		db.StaffScheduleException.StartAt.Set(data.Start),
+		db.StaffScheduleException.EndAt.Set(data.End),
+		db.StaffScheduleException.CreatedBy.Set(data.CreatedBy),
+		db.StaffScheduleException.Note.SetIfPresent(data.Note),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("staff schedule -> InsertException: %v", err)
+	}
+
+	return mapStaffScheduleExceptionModel(created), nil
+}
+
+func (repo *staffScheduleRepository) FindExceptionByID(exceptionID string) (*entities.StaffScheduleExceptionModel, error) {
+	exception, err := repo.Collection.StaffScheduleException.FindUnique(
+		db.StaffScheduleException.ID.Equals(exceptionID),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("staff schedule -> FindExceptionByID: %w", err)
+	}
+
+	return mapStaffScheduleExceptionModel(exception), nil
+}
+
+// FindExceptions lists the exceptions of the staff that overlap start..end.
+func (repo *staffScheduleRepository) FindExceptions(staffIDs []string, start, end time.Time) ([]entities.StaffScheduleExceptionModel, error) {
+	rows, err := repo.Collection.StaffScheduleException.FindMany(
+		db.StaffScheduleException.StaffID.In(staffIDs),
+		db.StaffScheduleException.StartAt.Lt(end),
+		db.StaffScheduleException.EndAt.Gt(start),
+	).OrderBy(
+		db.StaffScheduleException.StartAt.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("staff schedule -> FindExceptions: %v", err)
+	}
+
+	exceptions := make([]entities.StaffScheduleExceptionModel, 0, len(rows))
+	for i := range rows {
+		exceptions = append(exceptions, *mapStaffScheduleExceptionModel(&rows[i]))
+	}
+
+	return exceptions, nil
+}
+
+func (repo *staffScheduleRepository) DeleteException(exceptionID string) error {
+	_, err := repo.Collection.StaffScheduleException.FindUnique(
+		db.StaffScheduleException.ID.Equals(exceptionID),
+	).Delete().Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("staff schedule -> DeleteException: %w", err)
+	}
+
+	return nil
+}
+
+func mapStaffShiftModel(model *db.StaffShiftModel) entities.StaffShiftModel {
+	return entities.StaffShiftModel{
+		Weekday:   model.Weekday,
+		StartTime: model.StartTime.UTC().Format(clockLayout),
+		EndTime:   model.EndTime.UTC().Format(clockLayout),
+	}
+}
+
+func mapStaffScheduleExceptionModel(model *db.StaffScheduleExceptionModel) *entities.StaffScheduleExceptionModel {
+	result := &entities.StaffScheduleExceptionModel{
+		ID:        model.ID,
+		StaffID:   model.StaffID,
+		Kind:      model.Kind,
+		Start:     model.StartAt,
+		End:       model.EndAt,
+		CreatedBy: model.CreatedBy,
+		CreatedAt: model.CreatedAt,
+	}
+	if note, ok := model.Note(); ok {
+		result.Note = &note
+	}
+
+	return result
+}
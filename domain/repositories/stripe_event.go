@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"time"
+)
+
+// event ที่ค้างสถานะ processing นานกว่านี้ถือว่า process เดิมตายไปแล้ว ให้ retry ถัดไป claim ใหม่ได้
+const stripeEventClaimTTL = 10 * time.Minute
+
+type stripeEventRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IStripeEventRepository interface {
+	Claim(eventID string, eventType string) (*entities.StripeEventModel, bool, error)
+	MarkProcessed(eventID string, response string) (*entities.StripeEventModel, error)
+	Release(eventID string) error
+}
+
+func NewStripeEventRepository(db *ds.PrismaDB) IStripeEventRepository {
+	return &stripeEventRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+// Claim inserts the event into the ledger. It returns claimed=false together
+// with the existing row when the event was already seen.
+func (repo *stripeEventRepository) Claim(eventID string, eventType string) (*entities.StripeEventModel, bool, error) {
+	created, err := repo.Collection.StripeEvent.CreateOne(
+		db.StripeEvent.ID.Set(eventID),
+		db.StripeEvent.Type.Set(eventType),
+	).Exec(repo.Context)
+	if err == nil {
+		return mapToStripeEventModel(created), true, nil
+	}
+	if _, ok := db.IsErrUniqueConstraint(err); !ok {
+		return nil, false, fmt.Errorf("stripe event -> Claim: %v", err)
+	}
+
+	// take over a stale claim; only one caller can win this update
+	result, err := repo.Collection.StripeEvent.FindMany(
+		db.StripeEvent.ID.Equals(eventID),
+		db.StripeEvent.Status.Equals(db.StripeEventStatusProcessing),
+		db.StripeEvent.UpdatedAt.Lt(time.Now().Add(-stripeEventClaimTTL)),
+	).Update(
+		db.StripeEvent.UpdatedAt.Set(time.Now()),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, false, fmt.Errorf("stripe event -> Claim: %v", err)
+	}
+
+	existing, err := repo.Collection.StripeEvent.FindUnique(
+		db.StripeEvent.ID.Equals(eventID),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, false, fmt.Errorf("stripe event -> Claim: %v", err)
+	}
+
+	return mapToStripeEventModel(existing), result.Count == 1, nil
+}
+
+func (repo *stripeEventRepository) MarkProcessed(eventID string, response string) (*entities.StripeEventModel, error) {
+	updated, err := repo.Collection.StripeEvent.FindUnique(
+		db.StripeEvent.ID.Equals(eventID),
+	).Update(
+		db.StripeEvent.Status.Set(db.StripeEventStatusProcessed),
+		db.StripeEvent.Response.Set(response),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("stripe event -> MarkProcessed: %v", err)
+	}
+
+	return mapToStripeEventModel(updated), nil
+}
+
+// Release removes an unfinished claim so Stripe's next retry can process the event again.
+func (repo *stripeEventRepository) Release(eventID string) error {
+	_, err := repo.Collection.StripeEvent.FindMany(
+		db.StripeEvent.ID.Equals(eventID),
+		db.StripeEvent.Status.Equals(db.StripeEventStatusProcessing),
+	).Delete().Exec(repo.Context)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return fmt.Errorf("stripe event -> Release: %v", err)
+	}
+
+	return nil
+}
+
+func mapToStripeEventModel(model *db.StripeEventModel) *entities.StripeEventModel {
+	event := &entities.StripeEventModel{
+		EventID: model.ID,
+		Type:    model.Type,
+		Status:  model.Status,
+	}
+	if response, ok := model.Response(); ok {
+		event.Response = &response
+	}
+
+	return event
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/prisma/db"
+)
+
+// Tx collects writes from several repositories so they commit or roll back together.
+// prisma-client-go only supports batch transactions, so nothing is sent until Commit
+// and a write can't depend on the result of another write in the same Tx.
+type Tx struct {
+	queries []db.PrismaTransaction
+}
+
+func (tx *Tx) add(queries ...db.PrismaTransaction) {
+	tx.queries = append(tx.queries, queries...)
+}
+
+type unitOfWork struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IUnitOfWork interface {
+	Begin() *Tx
+	Commit(tx *Tx) error
+}
+
+func NewUnitOfWork(db *ds.PrismaDB) IUnitOfWork {
+	return &unitOfWork{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+func (uow *unitOfWork) Begin() *Tx {
+	return &Tx{}
+}
+
+func (uow *unitOfWork) Commit(tx *Tx) error {
+	if tx == nil || len(tx.queries) == 0 {
+		return nil
+	}
+	if err := uow.Collection.Prisma.Transaction(tx.queries...).Exec(uow.Context); err != nil {
+		if isStaffUnavailableErr(err) {
+			return ErrStaffUnavailable
+		}
+		if isJobLockedErr(err) {
+			return ErrJobLocked
+		}
+		if isStatusChangedErr(err) {
+			return ErrStatusChanged
+		}
+		if isLeaveHasBookingsErr(err) {
+			return ErrLeaveHasBookings
+		}
+		if isLeaveOverlapErr(err) {
+			return ErrLeaveOverlap
+		}
+		if isReassignmentClosedErr(err) {
+			return ErrReassignmentClosed
+		}
+		if isReminderClaimedErr(err) {
+			return ErrReminderClaimed
+		}
+		if isResetTokenUsedErr(err) {
+			return ErrResetTokenUsed
+		}
+		return fmt.Errorf("unit of work -> Commit: %w", err)
+	}
+
+	return nil
+}
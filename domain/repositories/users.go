@@ -6,10 +6,14 @@ import (
 	"lama-backend/domain/entities"
 	"lama-backend/domain/prisma/db"
 
+	"errors"
 	"fmt"
 	"time"
 )
 
+// email เป็น unique แล้ว คนเดิมจะเพิ่ม role ต้องไปทาง AddRole ไม่ใช่สมัครใหม่
+var ErrEmailTaken = errors.New("email is already registered")
+
 type usersRepository struct {
 	Context    context.Context
 	Collection *db.PrismaClient
@@ -17,11 +21,13 @@ type usersRepository struct {
 
 type IUsersRepository interface {
 	InsertUser(role string, data entities.CreatedUserModel) (*entities.UserDataModel, error)
-	FindByEmailAndRole(email string, role string) (*entities.LoginUserResponseModel, error)
+	FindByEmail(email string) (*entities.LoginUserResponseModel, error)
 	FindByID(userID string) (*entities.UserDataModel, error)
 	FindAll(role string, offset, limit int) ([]*entities.UserDataModel, error)
 	DeleteByID(userID string) (*entities.UserDataModel, error)
 	UpdateByID(userID string, data entities.UpdateUserModel) (*entities.UserDataModel, error)
+	UpdatePasswordTx(tx *Tx, userID, passwordHash string)
+	AddRole(userID string, role db.Role) (bool, error)
 }
 
 func NewUsersRepository(db *ds.PrismaDB) IUsersRepository {
@@ -39,34 +45,41 @@ func (repo *usersRepository) InsertUser(role string, data entities.CreatedUserMo
 		db.Users.Birthdate.Set(data.BirthDate),
 		db.Users.TelephoneNumber.Set(data.TelephoneNumber),
 		db.Users.Address.Set(data.Address),
-		db.Users.Role.Set(db.Role(role)),
+		db.Users.Roles.Set([]db.Role{db.Role(role)}),
 	).Exec(repo.Context)
 
 	if err != nil {
+		if _, ok := db.IsErrUniqueConstraint(err); ok {
+			return nil, fmt.Errorf("users -> InsertUser: %w", ErrEmailTaken)
+		}
 		return nil, fmt.Errorf("users -> InsertUser: %v", err)
 	}
 
 	return MapToEntities(createdData), nil
 }
 
-func (repo *usersRepository) FindByEmailAndRole(email string, role string) (*entities.LoginUserResponseModel, error) {
-	user, err := repo.Collection.Users.FindUnique(
-		db.Users.UsersEmailRoleKey(
-			db.Users.Email.Equals(email),
-			db.Users.Role.Equals(db.Role(role)),
-		),
+// FindByEmail คืน role แรก (role ตอนสมัคร) เป็น Role ไว้ใช้เมื่อ login ไม่ระบุ role
+func (repo *usersRepository) FindByEmail(email string) (*entities.LoginUserResponseModel, error) {
+	// อีเมลเก่าบางแถวเก็บตัวพิมพ์ใหญ่ไว้ตามที่สมัคร เลยเทียบแบบไม่สนตัวพิมพ์
+	user, err := repo.Collection.Users.FindFirst(
+		db.Users.Email.Equals(email),
+		db.Users.Email.Mode(db.QueryModeInsensitive),
 	).Exec(repo.Context)
 	if err != nil {
-		return nil, fmt.Errorf("users -> FindByEmail: %v", err)
+		return nil, fmt.Errorf("users -> FindByEmail: %w", err)
 	}
 	if user == nil {
 		return nil, fmt.Errorf("users -> FindByEmail: user data is nil")
 	}
+	if len(user.Roles) == 0 {
+		return nil, fmt.Errorf("users -> FindByEmail: user has no role")
+	}
 	return &entities.LoginUserResponseModel{
 		UserID:   user.ID,
 		Email:    user.Email,
 		Password: user.Password,
-		Role:     user.Role,
+		Role:     user.Roles[0],
+		Roles:    user.Roles,
 	}, nil
 }
 
@@ -80,7 +93,7 @@ func (repo *usersRepository) FindByID(userID string) (*entities.UserDataModel, e
 	).Exec(repo.Context)
 
 	if err != nil {
-		return nil, fmt.Errorf("users -> FindByID: %v", err)
+		return nil, fmt.Errorf("users -> FindByID: %w", err)
 	}
 	if user == nil {
 		return nil, fmt.Errorf("users -> FindByID: user data is nil")
@@ -92,7 +105,26 @@ func (repo *usersRepository) FindByID(userID string) (*entities.UserDataModel, e
 func (repo *usersRepository) FindAll(role string, offset, limit int) ([]*entities.UserDataModel, error) {
 	params := []db.UsersWhereParam{}
 	if role != "" && role != "all" {
-		params = append(params, db.Users.Role.Equals(db.Role(role)))
+		// prisma go ยังไม่มี has ให้ enum list หา id ด้วย sql ก่อน แบ่งหน้าตรงนี้เลย
+		var rows []struct {
+			ID string `json:"id"`
+		}
+		err := repo.Collection.Prisma.QueryRaw(`
+			SELECT id FROM "Users"
+			WHERE $1::role = ANY(roles)
+			ORDER BY created_at DESC
+			OFFSET $2 LIMIT NULLIF($3, 0)`,
+			role, max(offset, 0), max(limit, 0),
+		).Exec(repo.Context, &rows)
+		if err != nil {
+			return nil, fmt.Errorf("users -> FindAll: %v", err)
+		}
+		ids := make([]string, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+		}
+		params = append(params, db.Users.ID.In(ids))
+		offset, limit = 0, 0
 	}
 
 	query := repo.Collection.Users.FindMany(params...).With(
@@ -183,6 +215,29 @@ func (repo *usersRepository) UpdateByID(userID string, data entities.UpdateUserM
 	return MapToEntities(updatedUser), nil
 }
 
+// AddRole ต่อ role ท้าย array คืน false ถ้ามี role นี้อยู่แล้ว (รวมกรณีอีก request ใส่ไปก่อน)
+func (repo *usersRepository) UpdatePasswordTx(tx *Tx, userID, passwordHash string) {
+	tx.add(repo.Collection.Users.FindUnique(
+		db.Users.ID.Equals(userID),
+	).Update(
+		db.Users.Password.Set(passwordHash),
+	).Tx())
+}
+
+func (repo *usersRepository) AddRole(userID string, role db.Role) (bool, error) {
+	result, err := repo.Collection.Prisma.ExecuteRaw(`
+		UPDATE "Users"
+		SET roles = array_append(roles, $2::role), updated_at = now()
+		WHERE id = $1::uuid AND NOT ($2::role = ANY(roles))`,
+		userID, role,
+	).Exec(repo.Context)
+	if err != nil {
+		return false, fmt.Errorf("users -> AddRole: %v", err)
+	}
+
+	return result.Count > 0, nil
+}
+
 func MapToEntities(user *db.UsersModel) *entities.UserDataModel {
 	var licenseNumber, specialization string
 	var startDate db.DateTime
@@ -216,7 +271,7 @@ func MapToEntities(user *db.UsersModel) *entities.UserDataModel {
 		UpdatedAt:       user.UpdatedAt,
 		Email:           user.Email,
 		Password:        user.Password,
-		Role:            user.Role,
+		Roles:           user.Roles,
 		Name:            user.Name,
 		BirthDate:       user.Birthdate,
 		TelephoneNumber: user.TelephoneNumber,
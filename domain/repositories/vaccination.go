@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ds "lama-backend/domain/datasources"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+)
+
+// ErrReminderClaimed is returned by Commit when another instance already took one of the
+// reminders of the batch, so the caller must not send them again.
+var ErrReminderClaimed = errors.New("vaccination reminder was already sent")
+
+const reminderClaimedMarker = "reminder_claimed"
+
+type vaccinationRepository struct {
+	Context    context.Context
+	Collection *db.PrismaClient
+}
+
+type IVaccinationRepository interface {
+	InsertTx(tx *Tx, data entities.VaccinationModel)
+	SupersedeTx(tx *Tx, vaccinationIDs []string)
+	ClaimRemindersTx(tx *Tx, vaccinationIDs []string, at time.Time)
+	ReleaseReminder(vaccinationID string) error
+	FindByID(vaccinationID string) (*entities.VaccinationModel, error)
+	FindByPetID(petID string) ([]*entities.VaccinationModel, error)
+	FindDue(until time.Time, unremindedOnly bool) ([]*entities.VaccinationDueModel, error)
+}
+
+func NewVaccinationRepository(db *ds.PrismaDB) IVaccinationRepository {
+	return &vaccinationRepository{
+		Context:    db.Context,
+		Collection: db.PrismaDB,
+	}
+}
+
+func (repo *vaccinationRepository) InsertTx(tx *Tx, data entities.VaccinationModel) {
+	params := []db.VaccinationSetParam{
+		db.Vaccination.ID.Set(data.ID),
+		db.Vaccination.Kind.Set(data.Kind),
+		db.Vaccination.NextDueDate.SetIfPresent(data.NextDueDate),
+		db.Vaccination.Note.SetIfPresent(data.Note),
+		db.Vaccination.Superseded.Set(data.Superseded),
+	}
+	if data.ServiceID != nil {
+		params = append(params, db.Vaccination.Service.Link(db.Service.Sid.Equals(*data.ServiceID)))
+	}
+	if data.DoctorID != nil {
+		params = append(params, db.Vaccination.Doctor.Link(db.Doctor.UserID.Equals(*data.DoctorID)))
+	}
+
+	tx.add(repo.Collection.Vaccination.CreateOne(
+		db.Vaccination.Vaccine.Set(data.Vaccine),
+		db.Vaccination.GivenDate.Set(data.GivenDate),
+		db.Vaccination.CreatedBy.Set(data.CreatedBy),
+		db.Vaccination.Pet.Link(db.Pet.Petid.Equals(data.PetID)),
+		params...,
+	).Tx())
+}
+
+// SupersedeTx marks older doses that a new one replaces; they are no longer reminded.
+func (repo *vaccinationRepository) SupersedeTx(tx *Tx, vaccinationIDs []string) {
+	if len(vaccinationIDs) == 0 {
+		return
+	}
+	tx.add(repo.Collection.Vaccination.FindMany(
+		db.Vaccination.ID.In(vaccinationIDs),
+	).Update(
+		db.Vaccination.Superseded.Set(true),
+	).Tx())
+}
+
+// ClaimRemindersTx stamps reminded_at on the batch. If any of them was stamped in the
+// meantime (another instance read the same due list) the whole tx fails with
+// ErrReminderClaimed.
+func (repo *vaccinationRepository) ClaimRemindersTx(tx *Tx, vaccinationIDs []string, at time.Time) {
+	for _, id := range vaccinationIDs {
+		tx.add(repo.Collection.Prisma.ExecuteRaw(fmt.Sprintf(`
+			SELECT CAST(
+				CASE WHEN EXISTS (
+					SELECT 1 FROM "Vaccination" WHERE id = $1::uuid AND reminded_at IS NOT NULL
+				) THEN '%s' ELSE '0' END
+			AS INTEGER)`, reminderClaimedMarker),
+			id,
+		).Tx())
+	}
+	tx.add(repo.Collection.Vaccination.FindMany(
+		db.Vaccination.ID.In(vaccinationIDs),
+	).Update(
+		db.Vaccination.RemindedAt.Set(at),
+	).Tx())
+}
+
+// ReleaseReminder clears reminded_at so the next round tries again, used when sending failed.
+func (repo *vaccinationRepository) ReleaseReminder(vaccinationID string) error {
+	_, err := repo.Collection.Vaccination.FindUnique(
+		db.Vaccination.ID.Equals(vaccinationID),
+	).Update(
+		db.Vaccination.RemindedAt.SetOptional(nil),
+	).Exec(repo.Context)
+	if err != nil {
+		return fmt.Errorf("vaccination -> ReleaseReminder: %v", err)
+	}
+
+	return nil
+}
+
+func (repo *vaccinationRepository) FindByID(vaccinationID string) (*entities.VaccinationModel, error) {
+	vaccination, err := repo.Collection.Vaccination.FindUnique(
+		db.Vaccination.ID.Equals(vaccinationID),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("vaccination -> FindByID: %w", err)
+	}
+
+	return mapVaccinationModel(vaccination), nil
+}
+
+// FindByPetID returns every dose of the pet, latest given first.
+func (repo *vaccinationRepository) FindByPetID(petID string) ([]*entities.VaccinationModel, error) {
+	rows, err := repo.Collection.Vaccination.FindMany(
+		db.Vaccination.Petid.Equals(petID),
+	).OrderBy(
+		db.Vaccination.GivenDate.Order(db.SortOrderDesc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("vaccination -> FindByPetID: %v", err)
+	}
+
+	results := make([]*entities.VaccinationModel, 0, len(rows))
+	for i := range rows {
+		results = append(results, mapVaccinationModel(&rows[i]))
+	}
+	return results, nil
+}
+
+// FindDue lists current doses whose next due date is on or before until, overdue ones
+// included, soonest first.
+func (repo *vaccinationRepository) FindDue(until time.Time, unremindedOnly bool) ([]*entities.VaccinationDueModel, error) {
+	params := []db.VaccinationWhereParam{
+		db.Vaccination.Superseded.Equals(false),
+		db.Vaccination.NextDueDate.Lte(until),
+	}
+	if unremindedOnly {
+		params = append(params, db.Vaccination.RemindedAt.IsNull())
+	}
+
+	rows, err := repo.Collection.Vaccination.FindMany(params...).With(
+		db.Vaccination.Pet.Fetch().With(
+			db.Pet.Owner.Fetch().With(
+				db.Owner.Users.Fetch(),
+			),
+		),
+	).OrderBy(
+		db.Vaccination.NextDueDate.Order(db.SortOrderAsc),
+	).Exec(repo.Context)
+	if err != nil {
+		return nil, fmt.Errorf("vaccination -> FindDue: %v", err)
+	}
+
+	results := make([]*entities.VaccinationDueModel, 0, len(rows))
+	for i := range rows {
+		pet := rows[i].Pet()
+		owner := pet.Owner().Users()
+		name, _ := pet.Name()
+		results = append(results, &entities.VaccinationDueModel{
+			VaccinationModel: *mapVaccinationModel(&rows[i]),
+			PetName:          name,
+			OwnerID:          owner.ID,
+			OwnerName:        owner.Name,
+			OwnerEmail:       owner.Email,
+		})
+	}
+	return results, nil
+}
+
+func mapVaccinationModel(model *db.VaccinationModel) *entities.VaccinationModel {
+	result := &entities.VaccinationModel{
+		ID:         model.ID,
+		PetID:      model.Petid,
+		Vaccine:    model.Vaccine,
+		Kind:       model.Kind,
+		GivenDate:  model.GivenDate,
+		Superseded: model.Superseded,
+		CreatedBy:  model.CreatedBy,
+		CreatedAt:  model.CreatedAt,
+	}
+	if serviceID, ok := model.Sid(); ok {
+		result.ServiceID = &serviceID
+	}
+	if doctorID, ok := model.Did(); ok {
+		result.DoctorID = &doctorID
+	}
+	if nextDue, ok := model.NextDueDate(); ok {
+		result.NextDueDate = &nextDue
+	}
+	if note, ok := model.Note(); ok {
+		result.Note = &note
+	}
+	if remindedAt, ok := model.RemindedAt(); ok {
+		result.RemindedAt = &remindedAt
+	}
+
+	return result
+}
+
+func isReminderClaimedErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), reminderClaimedMarker)
+}
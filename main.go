@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"lama-backend/configuration"
 	ds "lama-backend/domain/datasources"
 	repo "lama-backend/domain/repositories"
 	gw "lama-backend/src/gateways/v1"
 	"lama-backend/src/middlewares"
+	"lama-backend/src/scheduler"
 	sv "lama-backend/src/services"
+	"lama-backend/src/storage"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "lama-backend/docs"
 
@@ -58,26 +64,100 @@ func main() {
 	paymentRepo := repo.NewPaymentRepository(prismadb)
 	leavedayRepo := repo.NewLeavedayRepository(prismadb)
 	petRepo := repo.NewPetRepository(prismadb)
+	stripeEventRepo := repo.NewStripeEventRepository(prismadb)
+	unitOfWork := repo.NewUnitOfWork(prismadb)
+	staffHoldRepo := repo.NewStaffHoldRepository(prismadb)
+	cancellationRepo := repo.NewServiceCancellationRepository(prismadb)
+	rescheduleRepo := repo.NewServiceRescheduleRepository(prismadb)
+	statusHistoryRepo := repo.NewServiceStatusHistoryRepository(prismadb)
+	scheduleRepo := repo.NewStaffScheduleRepository(prismadb)
+	reassignmentRepo := repo.NewServiceReassignmentRepository(prismadb)
+	medicalRecordRepo := repo.NewMedicalRecordRepository(prismadb)
+	medicineRepo := repo.NewMedicineRepository(prismadb)
+	vaccinationRepo := repo.NewVaccinationRepository(prismadb)
+	petWeightRepo := repo.NewPetWeightRepository(prismadb)
+	attachmentRepo := repo.NewAttachmentRepository(prismadb)
+	pricingRepo := repo.NewPricingRepository(prismadb)
+	jobLockRepo := repo.NewJobLockRepository(prismadb)
+	sessionRepo := repo.NewSessionRepository(prismadb)
+	passwordResetRepo := repo.NewPasswordResetRepository(prismadb)
+	mfaRepo := repo.NewMfaRepository(prismadb)
 
-	authService := sv.NewAuthService(usersRepo, ownerRepo, caretakerRepo, doctorRepo)
-	usersService := sv.NewUsersService(usersRepo, ownerRepo, caretakerRepo, doctorRepo)
+	attachmentBucket := os.Getenv("STORAGE_ATTACHMENT_BUCKET")
+	if attachmentBucket == "" {
+		attachmentBucket = "pet-files"
+	}
+	profileBucket := os.Getenv("STORAGE_PROFILE_BUCKET")
+	if profileBucket == "" {
+		profileBucket = "user-profile"
+	}
+	store, err := storage.NewFromEnv(attachmentBucket)
+	if err != nil {
+		log.Fatal(err)
+	}
+	profileStore, err := storage.NewFromEnv(profileBucket)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	authService := sv.NewAuthService(usersRepo, ownerRepo, caretakerRepo, doctorRepo, sessionRepo, passwordResetRepo, mfaRepo, unitOfWork)
+	usersService := sv.NewUsersService(usersRepo, ownerRepo, caretakerRepo, doctorRepo, sessionRepo, profileStore)
 	ownerService := sv.NewOwnerService(ownerRepo)
 	doctorService := sv.NewDoctorService(doctorRepo)
 	caretakerService := sv.NewCaretakerService(caretakerRepo)
-	serviceService := sv.NewServiceService(serviceRepo, usersRepo, caretakerRepo, doctorRepo, mserviceRepo, cserviceRepo, paymentRepo, petRepo)
-	leavedayService := sv.NewLeavedayService(leavedayRepo)
-	petService := sv.NewPetService(petRepo)
-	paymentService := sv.NewPaymentService(paymentRepo)
+	serviceService := sv.NewServiceService(serviceRepo, usersRepo, caretakerRepo, doctorRepo, mserviceRepo, cserviceRepo, paymentRepo, petRepo, ownerRepo, unitOfWork, staffHoldRepo, cancellationRepo, rescheduleRepo, statusHistoryRepo, scheduleRepo, reassignmentRepo)
+	leavedayService := sv.NewLeavedayService(leavedayRepo, unitOfWork)
+	petService := sv.NewPetService(petRepo, serviceRepo, medicalRecordRepo, medicineRepo, vaccinationRepo, petWeightRepo)
+	paymentService := sv.NewPaymentService(paymentRepo, stripeEventRepo, pricingRepo, petRepo, unitOfWork)
+	pricingService := sv.NewPricingService(pricingRepo)
+	medicalRecordService := sv.NewMedicalRecordService(medicalRecordRepo, medicineRepo, serviceRepo, mserviceRepo, petRepo, petWeightRepo, unitOfWork)
+	vaccinationService := sv.NewVaccinationService(vaccinationRepo, petRepo, serviceRepo, unitOfWork)
+	attachmentService := sv.NewAttachmentService(attachmentRepo, petRepo, serviceRepo, store)
 
-	gw.NewHTTPGateway(app, authService, usersService, ownerService, doctorService, caretakerService, serviceService, leavedayService, petService, paymentService)
+	gw.NewHTTPGateway(app, authService, usersService, ownerService, doctorService, caretakerService, serviceService, leavedayService, petService, paymentService, pricingService, medicalRecordService, vaccinationService, attachmentService, store, profileStore)
 
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
+	// งานเบื้องหลัง รันได้หลาย instance เพราะแต่ละ job ล็อกด้วย advisory lock
+	jobs := scheduler.NewScheduler(unitOfWork, jobLockRepo)
+	jobs.Register(scheduler.ExpireAbandonedPayments(paymentService))
+	jobs.Register(scheduler.PurgeExpiredHolds(serviceService))
+	jobs.Register(scheduler.SendVaccinationReminders(vaccinationService))
+	jobs.Register(scheduler.PurgeExpiredSessions(authService))
+	jobs.Register(scheduler.PurgePasswordResets(authService))
+	jobs.Start()
+
 	PORT := os.Getenv("PORT")
 
 	if PORT == "" {
 		PORT = "8080"
 	}
 
-	app.Listen(":" + PORT)
+	// listen พัง (port ถูกใช้อยู่) ต้องปิด job ด้วยแล้วออกไปเลย ไม่ใช่รัน job ต่อโดยไม่มี server
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- app.Listen(":" + PORT)
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	var listenErr error
+	select {
+	case <-quit:
+	case listenErr = <-serverErr:
+		log.Printf("server stopped: %v", listenErr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		log.Printf("server shutdown: %v", err)
+	}
+	if err := jobs.Shutdown(ctx); err != nil {
+		log.Printf("scheduler shutdown: %v", err)
+	}
+	if listenErr != nil {
+		cancel()
+		log.Fatalf("server failed: %v", listenErr)
+	}
 }
@@ -0,0 +1,73 @@
+package gateways
+
+import (
+	"lama-backend/domain/entities"
+	"lama-backend/src/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// hook ของ route ที่ต้องเช็คความเป็นเจ้าของ ต่อท้าย middlewares.Authorize ใน route.go
+
+const serviceLocal = "service"
+
+// ownsPet stops an owner at a pet of someone else. Other roles are left to the matrix.
+func (h *HTTPGateway) ownsPet(param string) middlewares.OwnershipHook {
+	return func(ctx *fiber.Ctx, token *middlewares.TokenDetails) error {
+		if token.Role != "owner" {
+			return nil
+		}
+		pet, err := h.PetService.FindPetByID(ctx.Params(param))
+		if err != nil {
+			return fiber.NewError(fiber.StatusNotFound, "pet not found")
+		}
+		if pet.OwnerID != token.UserID {
+			return fiber.NewError(fiber.StatusForbidden, "You do not own this pet")
+		}
+		return nil
+	}
+}
+
+// serviceParty loads the booking and lets through its owner, its assigned staff and admins.
+// The booking is kept for the handler, see bookedService.
+func (h *HTTPGateway) serviceParty(param string) middlewares.OwnershipHook {
+	return func(ctx *fiber.Ctx, token *middlewares.TokenDetails) error {
+		existing, err := h.ServiceService.FindServiceByID(ctx.Params(param))
+		if err != nil {
+			return fiber.NewError(fiber.StatusNotFound, "service not found")
+		}
+		switch token.Role {
+		case "admin":
+		case "owner":
+			if existing.OwnerID != token.UserID {
+				return fiber.NewError(fiber.StatusForbidden, "You do not own this service")
+			}
+		default:
+			if existing.StaffID != token.UserID {
+				return fiber.NewError(fiber.StatusForbidden, "service is not assigned to you")
+			}
+		}
+		ctx.Locals(serviceLocal, existing)
+		return nil
+	}
+}
+
+// self keeps caretakers and doctors to their own schedule, score and so on. Other roles
+// pass, an empty param is left for the handler to fill in.
+func self(param string) middlewares.OwnershipHook {
+	return func(ctx *fiber.Ctx, token *middlewares.TokenDetails) error {
+		if token.Role != "caretaker" && token.Role != "doctor" {
+			return nil
+		}
+		if id := ctx.Params(param); id != "" && id != token.UserID {
+			return fiber.NewError(fiber.StatusForbidden, "Invalid role")
+		}
+		return nil
+	}
+}
+
+// bookedService is the booking serviceParty loaded for this request.
+func bookedService(ctx *fiber.Ctx) *entities.ServiceModel {
+	existing, _ := ctx.Locals(serviceLocal).(*entities.ServiceModel)
+	return existing
+}
@@ -0,0 +1,274 @@
+package gateways
+
+import (
+	"errors"
+	"strings"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/middlewares"
+	service "lama-backend/src/services"
+	"lama-backend/src/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Upload pet photo or document
+// @Description Add a photo to the gallery of a pet (owner, admin) or a document such as a lab result to its files (owner, a doctor who has treated the pet, admin). Photos: jpeg, png or webp up to 5 MB. Documents: pdf, jpeg or png up to 10 MB. The type is read from the file content. The response carries a signed URL that expires.
+// @Tags attachment
+// @Accept mpfd
+// @Produce json
+// @Param petID path string true "Pet ID"
+// @Param kind formData string true "photo or document"
+// @Param file formData file true "file"
+// @Success 201 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid request"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Not allowed for this pet"
+// @Failure 404 {object} entities.ResponseMessage "Pet not found"
+// @Failure 413 {object} entities.ResponseMessage "File too large"
+// @Failure 415 {object} entities.ResponseMessage "File type not allowed"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pets/{petID}/attachments [post]
+// @Security BearerAuth
+func (h *HTTPGateway) UploadPetAttachment(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	petID := ctx.Params("petID")
+	if err := h.Validator.Var(petID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid pet ID"})
+	}
+	kind := ctx.FormValue("kind")
+	if err := h.Validator.Var(kind, "required,oneof=photo document"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "kind must be photo or document"})
+	}
+
+	upload, closeFile, err := formAttachment(ctx, "file")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	defer closeFile()
+
+	attachment, err := h.AttachmentService.UploadPetAttachment(petID, token.UserID, token.Role, db.AttachmentKind(kind), upload)
+	if err != nil {
+		return attachmentErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{
+		Message: "file uploaded",
+		Data:    attachment,
+		Status:  fiber.StatusCreated,
+	})
+}
+
+// @Summary Get pet photos and documents
+// @Description Files of the pet newest first, documents attached to its services included. Each has a signed URL valid until url_expires_at, list again for fresh ones. Owners see their own pets, doctors the pets they have treated, admins any pet.
+// @Tags attachment
+// @Produce json
+// @Param petID path string true "Pet ID"
+// @Param kind query string false "photo or document"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid request"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Not allowed for this pet"
+// @Failure 404 {object} entities.ResponseMessage "Pet not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pets/{petID}/attachments [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetPetAttachments(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	petID := ctx.Params("petID")
+	if err := h.Validator.Var(petID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid pet ID"})
+	}
+	var kind *db.AttachmentKind
+	if value := ctx.Query("kind"); value != "" {
+		if err := h.Validator.Var(value, "oneof=photo document"); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "kind must be photo or document"})
+		}
+		k := db.AttachmentKind(value)
+		kind = &k
+	}
+
+	attachments, err := h.AttachmentService.FindPetAttachments(petID, token.UserID, token.Role, kind)
+	if err != nil {
+		return attachmentErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "Request successful",
+		Data:    attachments,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Delete pet photo or document
+// @Description Only who uploaded the file, or an admin, can delete it.
+// @Tags attachment
+// @Produce json
+// @Param petID path string true "Pet ID"
+// @Param attachmentID path string true "Attachment ID"
+// @Success 200 {object} entities.ResponseMessage "file deleted"
+// @Failure 400 {object} entities.ResponseMessage "Invalid ID"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Not the uploader"
+// @Failure 404 {object} entities.ResponseMessage "File not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pets/{petID}/attachments/{attachmentID} [delete]
+// @Security BearerAuth
+func (h *HTTPGateway) DeletePetAttachment(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	petID, attachmentID := ctx.Params("petID"), ctx.Params("attachmentID")
+	if h.Validator.Var(petID, "uuid") != nil || h.Validator.Var(attachmentID, "uuid") != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid ID"})
+	}
+
+	if err := h.AttachmentService.DeleteAttachment(petID, attachmentID, token.UserID, token.Role); err != nil {
+		return attachmentErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{Message: "file deleted"})
+}
+
+// @Summary Attach document to service
+// @Description Attach a document (lab result, referral) to a service. It also shows in the files of the pet. The owner of the pet, the doctor assigned to the mservice and admins can attach. pdf, jpeg or png up to 10 MB.
+// @Tags attachment
+// @Accept mpfd
+// @Produce json
+// @Param serviceID path string true "Service ID"
+// @Param file formData file true "file"
+// @Success 201 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid request"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Not allowed for this service"
+// @Failure 404 {object} entities.ResponseMessage "Service not found"
+// @Failure 413 {object} entities.ResponseMessage "File too large"
+// @Failure 415 {object} entities.ResponseMessage "File type not allowed"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /services/{serviceID}/attachments [post]
+// @Security BearerAuth
+func (h *HTTPGateway) UploadServiceAttachment(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	serviceID := ctx.Params("serviceID")
+	if err := h.Validator.Var(serviceID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid service ID"})
+	}
+
+	upload, closeFile, err := formAttachment(ctx, "file")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	defer closeFile()
+
+	attachment, err := h.AttachmentService.UploadServiceAttachment(serviceID, token.UserID, token.Role, upload)
+	if err != nil {
+		return attachmentErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{
+		Message: "file uploaded",
+		Data:    attachment,
+		Status:  fiber.StatusCreated,
+	})
+}
+
+// @Summary Get service documents
+// @Description Documents attached to a service newest first, each with a signed URL valid until url_expires_at.
+// @Tags attachment
+// @Produce json
+// @Param serviceID path string true "Service ID"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid service ID"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Not allowed for this service"
+// @Failure 404 {object} entities.ResponseMessage "Service not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /services/{serviceID}/attachments [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetServiceAttachments(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	serviceID := ctx.Params("serviceID")
+	if err := h.Validator.Var(serviceID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid service ID"})
+	}
+
+	attachments, err := h.AttachmentService.FindServiceAttachments(serviceID, token.UserID, token.Role)
+	if err != nil {
+		return attachmentErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "Request successful",
+		Data:    attachments,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// ServeFile serves the files of the local storage driver. Private buckets need a signed URL,
+// the signature is the access check so there is no token. The profile bucket is public.
+func (h *HTTPGateway) ServeFile(ctx *fiber.Ctx) error {
+	var store *storage.LocalStore
+	for _, candidate := range []storage.ObjectStore{h.Storage, h.ProfileStore} {
+		if local, ok := candidate.(*storage.LocalStore); ok && local.Bucket == ctx.Params("bucket") {
+			store = local
+		}
+	}
+	if store == nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "not found"})
+	}
+
+	key := ctx.Params("*")
+	if storage.ObjectStore(store) != h.ProfileStore {
+		if err := store.Verify(key, ctx.Query("expires"), ctx.Query("signature")); err != nil {
+			return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "invalid or expired link"})
+		}
+	}
+
+	body, object, err := store.Get(ctx.Context(), key)
+	switch {
+	case errors.Is(err, storage.ErrInvalidKey):
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "invalid or expired link"})
+	case errors.Is(err, storage.ErrObjectNotFound):
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "not found"})
+	case err != nil:
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "private, no-store")
+	ctx.Set("X-Content-Type-Options", "nosniff")
+	ctx.Set(fiber.HeaderContentType, object.ContentType)
+	// fasthttp ปิด body ให้เองหลังส่งเสร็จ
+	return ctx.SendStream(body, int(object.Size))
+}
+
+func formAttachment(ctx *fiber.Ctx, field string) (entities.AttachmentUpload, func(), error) {
+	header, err := ctx.FormFile(field)
+	if err != nil {
+		return entities.AttachmentUpload{}, nil, errors.New(field + " file is required")
+	}
+	file, err := header.Open()
+	if err != nil {
+		return entities.AttachmentUpload{}, nil, errors.New("cannot read file")
+	}
+	return entities.AttachmentUpload{
+		FileName: header.Filename,
+		Size:     header.Size,
+		Body:     file,
+	}, func() { file.Close() }, nil
+}
+
+func attachmentErrorResponse(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrAttachmentForbidden):
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		return ctx.Status(fiber.StatusRequestEntityTooLarge).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrUnsupportedFileType):
+		return ctx.Status(fiber.StatusUnsupportedMediaType).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrInvalidAttachment):
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, db.ErrNotFound), strings.Contains(err.Error(), "not found"):
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "not found"})
+	default:
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+}
@@ -1,9 +1,12 @@
 package gateways
 
 import (
+	"errors"
 	"lama-backend/domain/entities"
 	"lama-backend/src/middlewares"
+	service "lama-backend/src/services"
 	"lama-backend/src/utils"
+	"log"
 	"os"
 
 	"time"
@@ -38,7 +41,7 @@ func (h *HTTPGateway) checkToken(ctx *fiber.Ctx) error {
 }
 
 // @Summary Register
-// @Description Register new user except admin
+// @Description Register new user except admin. An email can only register once, to get another role on the same account log in and use POST /user/roles/{role}.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -47,6 +50,7 @@ func (h *HTTPGateway) checkToken(ctx *fiber.Ctx) error {
 // @Success 200 {object} entities.ResponseModel "Request successful"
 // @Failure 403 {object} entities.ResponseMessage "Invalid role"
 // @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 409 {object} entities.ResponseMessage "Email is already registered"
 // @Failure 422 {object} entities.ResponseMessage "Validation error"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
 // @Router /auth/register/{role} [post]
@@ -83,10 +87,13 @@ func (h *HTTPGateway) Register(ctx *fiber.Ctx) error {
 
 	userData, err := h.AuthService.Register(role, bodyData)
 	if err != nil {
+		if errors.Is(err, service.ErrEmailTaken) {
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: "email is already registered, log in and add the " + role + " role to the account instead"})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot insert new user account: " + err.Error()})
 	}
 
-	token, err := middlewares.GenerateJWTToken(userData.UserID, role, "access")
+	token, err := h.AuthService.BeginSession(userData.UserID, role, ctx.Get(fiber.HeaderUserAgent))
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
 			Message: "Failed to generate token",
@@ -101,23 +108,24 @@ func (h *HTTPGateway) Register(ctx *fiber.Ctx) error {
 }
 
 // @Summary Login
-// @Description Login user
+// @Description Login user. Without a role the account logs in with the role it registered with, use POST /auth/switch-role/{role} to act as its other roles. When the account has 2FA or the role requires it the token has purpose mfa_pending and mfa_enrolled, finish with POST /auth/mfa/verify (or /auth/mfa/setup and /auth/mfa/enable when not enrolled yet).
 // @Tags Auth
 // @Accept json
 // @Produce json
-// @Param role path string true "Role of the user"
+// @Param role path string false "Role to log in as, must be one of the account's roles"
 // @Param body body entities.LoginUserRequestModel true "email and password"
 // @Success 200 {object} entities.ResponseModel "Request successful"
-// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 403 {object} entities.ResponseMessage "Invalid role or the account does not have it"
 // @Failure 400 {object} entities.ResponseMessage "Invalid json body"
 // @Failure 422 {object} entities.ResponseMessage "Validation error"
 // @Failure 401 {object} entities.ResponseMessage "Cannot login user: invalid password or email"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /auth/login [post]
 // @Router /auth/login/{role} [post]
 // @Security
 func (h *HTTPGateway) Login(ctx *fiber.Ctx) error {
 	role := ctx.Params("role")
-	if role != "admin" && role != "doctor" && role != "caretaker" && role != "owner" {
+	if role != "" && !validRole(role) {
 		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
 	}
 
@@ -131,10 +139,13 @@ func (h *HTTPGateway) Login(ctx *fiber.Ctx) error {
 
 	userData, err := h.AuthService.Login(role, bodyData)
 	if err != nil {
+		if errors.Is(err, service.ErrRoleNotGranted) {
+			return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
 		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "cannot login user: " + err.Error()})
 	}
 
-	token, err := middlewares.GenerateJWTToken(userData.UserID, role, "access")
+	token, err := h.AuthService.BeginSession(userData.UserID, string(userData.Role), ctx.Get(fiber.HeaderUserAgent))
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
 			Message: "Failed to generate token",
@@ -148,8 +159,124 @@ func (h *HTTPGateway) Login(ctx *fiber.Ctx) error {
 	})
 }
 
+// @Summary Refresh token
+// @Description Trade a refresh token for a new access token. The refresh token rotates, the old one stops working. Reusing an old refresh token closes the session.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body entities.RefreshTokenRequest true "refresh token from login or the last refresh"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Refresh token is invalid, expired or reused"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /auth/refresh [post]
+// @Security
+func (h *HTTPGateway) RefreshToken(ctx *fiber.Ctx) error {
+	bodyData := entities.RefreshTokenRequest{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := validator.New().Struct(bodyData); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	token, err := h.AuthService.RefreshSession(bodyData.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot refresh token: " + err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    token,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Logout
+// @Description Close the session of this access token, its refresh token stops working too.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} entities.ResponseMessage "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /auth/logout [post]
+// @Security BearerAuth
+func (h *HTTPGateway) Logout(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	if err := h.AuthService.Logout(token); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot logout: " + err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{
+		Message: "success",
+	})
+}
+
+// @Summary Logout all sessions
+// @Description Close every session of the user on every device, this one included.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} entities.ResponseModel "Request successful, data is the number of closed sessions"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /auth/logout/all [post]
+// @Security BearerAuth
+func (h *HTTPGateway) LogoutAll(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	closed, err := h.AuthService.LogoutAll(token.UserID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot logout: " + err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    closed,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Switch role
+// @Description Get a new session acting as another role of the same account. The current access and refresh tokens stop working.
+// @Tags Auth
+// @Produce json
+// @Param role path string true "Role to switch to"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role, the account does not have it or the role requires 2FA and this session did not pass it"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /auth/switch-role/{role} [post]
+// @Security BearerAuth
+func (h *HTTPGateway) SwitchRole(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	role := ctx.Params("role")
+	if !validRole(role) {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
+	}
+
+	switched, err := h.AuthService.SwitchRole(token, role, ctx.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		if errors.Is(err, service.ErrRoleNotGranted) || errors.Is(err, service.ErrMfaRequired) {
+			return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot switch role: " + err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    switched,
+		Status:  fiber.StatusOK,
+	})
+}
+
 // @Summary create admin
-// @Description create new admin user
+// @Description create new admin user. To make an existing account an admin use POST /admin/users/{userID}/roles/admin.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -157,19 +284,12 @@ func (h *HTTPGateway) Login(ctx *fiber.Ctx) error {
 // @Success 200 {object} entities.ResponseModel "Request successful"
 // @Failure 400 {object} entities.ResponseMessage "Invalid json body"
 // @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 409 {object} entities.ResponseMessage "Email is already registered"
 // @Failure 422 {object} entities.ResponseMessage "Validation error"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
 // @Router /auth/admin [post]
 // @Security BearerAuth
 func (h *HTTPGateway) CreateAdmin(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
-
 	bodyData := entities.CreatedUserModel{}
 	if err := ctx.BodyParser(&bodyData); err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
@@ -190,6 +310,9 @@ func (h *HTTPGateway) CreateAdmin(ctx *fiber.Ctx) error {
 
 	userData, err := h.AuthService.Register("admin", bodyData)
 	if err != nil {
+		if errors.Is(err, service.ErrEmailTaken) {
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: "email is already registered, grant the admin role to that account instead"})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot insert new user account: " + err.Error()})
 	}
 
@@ -201,14 +324,15 @@ func (h *HTTPGateway) CreateAdmin(ctx *fiber.Ctx) error {
 }
 
 // @Summary forgot password
-// @Description forgot password and send reset link to email
+// @Description send a reset link to the email if it has an account. The password is shared by every role of the account. The answer is the same whether the account exists or not. Limited to 3 requests per email and 10 per IP an hour.
 // @Tags Auth
 // @Accept json
 // @Produce json
-// @Param body body entities.SendEmailModel true "user email and role"
+// @Param body body entities.SendEmailModel true "user email"
 // @Success 200 {object} entities.ResponseMessage "Request successful"
 // @Failure 400 {object} entities.ResponseMessage "Invalid json body"
 // @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 429 {object} entities.ResponseMessage "Too many requests"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
 // @Router /auth/password/email [post]
 // @Security
@@ -221,30 +345,31 @@ func (h *HTTPGateway) ForgotPassword(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
 	}
 
-	userID, err := h.AuthService.ValidateEmailAndRole(&bodyData)
+	token, err := h.AuthService.RequestPasswordReset(bodyData, ctx.IP())
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot send reset password mail: " + err.Error()})
+		if errors.Is(err, service.ErrTooManyResetRequests) {
+			return ctx.Status(fiber.StatusTooManyRequests).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot send reset password mail"})
 	}
 
-	token, err := middlewares.GenerateResetPasswordJWTToken(userID, string(bodyData.Role), "reset_password")
-	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
-			Message: "Failed to generate token",
-		})
-	}
-
-	resetLink := os.Getenv("FORGET_PASSWORD_LINK")
-	if err := utils.SendResetEmail(bodyData.Email, resetLink+*token.Token); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send email: " + err.Error()})
+	// ส่งเมลแยกออกไป ไม่ให้เวลาตอบต่างกันระหว่างอีเมลที่มี/ไม่มีบัญชี
+	if token != nil {
+		resetLink := os.Getenv("FORGET_PASSWORD_LINK") + *token.Token
+		go func(email string) {
+			if err := utils.SendResetEmail(email, resetLink); err != nil {
+				log.Printf("auth -> ForgotPassword: cannot send reset mail: %v", err)
+			}
+		}(bodyData.Email)
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{
-		Message: "success",
+		Message: "if the email has an account, a reset link has been sent",
 	})
 }
 
 // @Summary reset password
-// @Description reset password with token from email
+// @Description reset password with token from email. The token works once and stops working if the password was changed after it was sent. Every session of the user is logged out.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -252,15 +377,14 @@ func (h *HTTPGateway) ForgotPassword(ctx *fiber.Ctx) error {
 // @Param body body entities.PasswordModel true "user new password"
 // @Success 200 {object} entities.ResponseMessage "Request successful"
 // @Failure 400 {object} entities.ResponseMessage "Invalid json body"
-// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 401 {object} entities.ResponseMessage "Reset link is invalid, expired or already used"
 // @Failure 422 {object} entities.ResponseMessage "Validation error"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
 // @Router /auth/password [patch]
-// @Security BearerAuth
+// @Security
 func (h *HTTPGateway) ResetPassword(ctx *fiber.Ctx) error {
 	emailToken := ctx.Query("token")
-	token, err := middlewares.DecodeResetPasswordJWTToken(emailToken)
-	if err != nil {
+	if emailToken == "" {
 		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
 	}
 
@@ -280,13 +404,19 @@ func (h *HTTPGateway) ResetPassword(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "cannot hash password: " + err.Error()})
 	}
 
-	updatedData := entities.UpdateUserModel{}
-	updatedData.Password = &hashPassword
-	if _, err := h.UsersService.UpdateUsersByID(token.UserID, updatedData); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot update password: " + err.Error()})
+	// ใช้ token หลังเช็ครหัสใหม่ผ่านแล้ว รหัสไม่ผ่านจะได้ไม่เสีย link ไปฟรีๆ
+	if err := h.AuthService.ResetPassword(emailToken, hashPassword); err != nil {
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot reset password: " + err.Error()})
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{
 		Message: "success",
 	})
 }
+
+func validRole(role string) bool {
+	return role == "admin" || role == "doctor" || role == "caretaker" || role == "owner"
+}
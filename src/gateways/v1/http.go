@@ -2,6 +2,7 @@ package gateways
 
 import (
 	service "lama-backend/src/services"
+	"lama-backend/src/storage"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
@@ -16,6 +17,12 @@ type HTTPGateway struct {
 	LeavedayService  service.ILeavedayService
 	PetService       service.IPetService
 	PaymentService   service.IPaymentService
+	PricingService   service.IPricingService
+	MedicalRecordService service.IMedicalRecordService
+	VaccinationService service.IVaccinationService
+	AttachmentService service.IAttachmentService
+	Storage          storage.ObjectStore
+	ProfileStore     storage.PublicStore
 	Validator        *validator.Validate
 }
 
@@ -25,7 +32,13 @@ func NewHTTPGateway(app *fiber.App, auth service.IAuthService,
 	service service.IServiceService,
 	leaveday service.ILeavedayService,
 	pet service.IPetService,
-	payment service.IPaymentService,) {
+	payment service.IPaymentService,
+	pricing service.IPricingService,
+	medicalRecord service.IMedicalRecordService,
+	vaccination service.IVaccinationService,
+	attachment service.IAttachmentService,
+	store storage.ObjectStore,
+	profileStore storage.PublicStore,) {
 	gateway := &HTTPGateway{
 		AuthService:      auth,
 		UsersService:     users,
@@ -36,6 +49,12 @@ func NewHTTPGateway(app *fiber.App, auth service.IAuthService,
 		LeavedayService:  leaveday,
 		PetService:       pet,
 		PaymentService:   payment,
+		PricingService:   pricing,
+		MedicalRecordService: medicalRecord,
+		VaccinationService: vaccination,
+		AttachmentService: attachment,
+		Storage:          store,
+		ProfileStore:     profileStore,
 		Validator:      validator.New(),
 	}
 
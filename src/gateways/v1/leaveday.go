@@ -1,57 +1,305 @@
 package gateways
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
 	"lama-backend/src/middlewares"
-	"time"
+	service "lama-backend/src/services"
+	"lama-backend/src/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // @Summary Create Leaveday
-// @Description Create Staff leaveday by token and day params (format: YYYY-MM-DD)
+// @Description Create Staff leaveday by token and day params (format: YYYY-MM-DD). Same as POST /leaveday with start_date = end_date.
 // @Tags Leaveday
 // @Produce json
 // @Param day path string true "leaveday"
-// @Success 200 {object} entities.ResponseModel "request successfully"
+// @Success 201 {object} entities.ResponseModel "request successfully"
 // @Failure 400 {object} entities.ResponseMessage "Invalid request"
 // @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
 // @Failure 403 {object} entities.ResponseMessage "Invalid role"
-// @Failure 404 {object} entities.ResponseMessage "User not found."
+// @Failure 409 {object} entities.ResponseMessage "Staff has bookings or leave on the day"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
 // @Router /leaveday/{day} [post]
 // @Security BearerAuth
 func (h *HTTPGateway) CreateLeaveday(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "caretaker" && token.Role != "doctor" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
+	token := middlewares.AccessToken(ctx)
 
 	leavedayStr := ctx.Params("day")
 	if leavedayStr == "" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid leaveday"})
 	}
+	if _, err := time.Parse("2006-01-02", leavedayStr); err != nil { // <-- for YYYY-MM-DD format
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{
+			Message: "invalid date or date format, expected YYYY-MM-DD",
+		})
+	}
 
-	leaveday, err := time.Parse("2006-01-02", leavedayStr) // <-- for YYYY-MM-DD format
+	leave, err := h.LeavedayService.RequestLeave(token.UserID, token.Role, entities.CreateLeaveRequest{
+		StartDate: leavedayStr,
+		EndDate:   leavedayStr,
+	})
+	if err != nil {
+		return leaveErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{
+		Message: "request successfully",
+		Data:    leave,
+		Status:  fiber.StatusCreated,
+	})
+}
+
+// @Summary Request leave
+// @Description Request leave for every day from start_date to end_date (YYYY-MM-DD, inclusive, at most 60 days). The request is approved right away unless LEAVE_REQUIRES_APPROVAL is set, then it stays pending until an admin reviews it. Days with bookings must be reassigned or cancelled first.
+// @Tags Leaveday
+// @Accept json
+// @Produce json
+// @Param body body entities.CreateLeaveRequest true "leave range"
+// @Success 201 {object} entities.ResponseModel "request successfully"
+// @Failure 400 {object} entities.ResponseMessage "Invalid request"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 409 {object} entities.ResponseMessage "Staff has bookings or leave on the days"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /leaveday [post]
+// @Security BearerAuth
+func (h *HTTPGateway) RequestLeave(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	var req entities.CreateLeaveRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	leave, err := h.LeavedayService.RequestLeave(token.UserID, token.Role, req)
+	if err != nil {
+		return leaveErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{
+		Message: "request successfully",
+		Data:    leave,
+		Status:  fiber.StatusCreated,
+	})
+}
+
+// @Summary Get my leave
+// @Description Leave requests of the staff in the token. from/to (YYYY-MM-DD) keep requests with a day in the range.
+// @Tags Leaveday
+// @Produce json
+// @Param status query string false "pending, approved, rejected or cancelled"
+// @Param from query string false "from date (YYYY-MM-DD)"
+// @Param to query string false "to date (YYYY-MM-DD)"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid query"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /leaveday [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetMyLeave(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	filter := entities.LeaveFilter{StaffID: &token.UserID}
+	if status := ctx.Query("status"); status != "" {
+		if err := h.Validator.Var(status, "oneof=pending approved rejected cancelled"); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid status"})
+		}
+		leaveStatus := db.LeaveStatus(status)
+		filter.Status = &leaveStatus
+	}
+	for query, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := ctx.Query(query)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid " + query + " date, expected YYYY-MM-DD"})
+		}
+		*target = &date
+	}
+
+	leaves, err := h.LeavedayService.FindLeave(filter)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    leaves,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Get leave on a day
+// @Description Pending and approved leave of every staff on the day (admin only)
+// @Tags Leaveday
+// @Produce json
+// @Param day path string true "day (YYYY-MM-DD)"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid date"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /leaveday/day/{day} [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetLeaveByDay(ctx *fiber.Ctx) error {
+	day, err := time.Parse("2006-01-02", ctx.Params("day"))
 	if err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{
 			Message: "invalid date or date format, expected YYYY-MM-DD",
 		})
 	}
 
-	var leavedayData *entities.LeavedayModel
-	if leavedayData, err = h.LeavedayService.InsertLeaveday(token.UserID, token.Role, leaveday); err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
-			Message: err.Error(),
-		})
+	leaves, err := h.LeavedayService.FindLeaveByDay(day)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
 	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    leaves,
+		Status:  fiber.StatusOK,
+	})
+}
 
+// @Summary Get pending leave
+// @Description Leave requests waiting for approval (admin only)
+// @Tags Leaveday
+// @Produce json
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /leaveday/pending [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetPendingLeave(ctx *fiber.Ctx) error {
+	pending := db.LeaveStatusPending
+	leaves, err := h.LeavedayService.FindLeave(entities.LeaveFilter{Status: &pending})
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
-		Message: "request successfully",
-		Data:    &leavedayData,
+		Message: "success",
+		Data:    leaves,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Cancel leave
+// @Description Cancel a pending or approved leave request. Staff can cancel their own leave before its first day, admins any active leave.
+// @Tags Leaveday
+// @Produce json
+// @Param requestID path string true "leave request ID"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid request ID"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Leave request not found"
+// @Failure 409 {object} entities.ResponseMessage "Leave can no longer be cancelled"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /leaveday/requests/{requestID} [delete]
+// @Security BearerAuth
+func (h *HTTPGateway) CancelLeave(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	requestID := ctx.Params("requestID")
+	if err := h.Validator.Var(requestID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid request ID"})
+	}
+
+	leave, err := h.LeavedayService.CancelLeave(requestID, token.UserID, token.Role)
+	if err != nil {
+		return leaveErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "leave cancelled",
+		Data:    leave,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Approve leave
+// @Description Approve a pending leave request (admin only)
+// @Tags Leaveday
+// @Produce json
+// @Param requestID path string true "leave request ID"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid request ID"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Leave request not found"
+// @Failure 409 {object} entities.ResponseMessage "Leave is not pending"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /leaveday/requests/{requestID}/approve [patch]
+// @Security BearerAuth
+func (h *HTTPGateway) ApproveLeave(ctx *fiber.Ctx) error {
+	return h.reviewLeave(ctx, true)
+}
+
+// @Summary Reject leave
+// @Description Reject a pending leave request (admin only)
+// @Tags Leaveday
+// @Produce json
+// @Param requestID path string true "leave request ID"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid request ID"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Leave request not found"
+// @Failure 409 {object} entities.ResponseMessage "Leave is not pending"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /leaveday/requests/{requestID}/reject [patch]
+// @Security BearerAuth
+func (h *HTTPGateway) RejectLeave(ctx *fiber.Ctx) error {
+	return h.reviewLeave(ctx, false)
+}
+
+func (h *HTTPGateway) reviewLeave(ctx *fiber.Ctx, approve bool) error {
+	token := middlewares.AccessToken(ctx)
+
+	requestID := ctx.Params("requestID")
+	if err := h.Validator.Var(requestID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid request ID"})
+	}
+
+	leave, err := h.LeavedayService.ReviewLeave(requestID, token.UserID, approve)
+	if err != nil {
+		return leaveErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    leave,
 		Status:  fiber.StatusOK,
 	})
 }
+
+func leaveErrorResponse(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrLeaveHasBookings):
+		return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{
+			Message: "staff has bookings on the requested days, reassign or cancel them first",
+		})
+	case errors.Is(err, service.ErrLeaveOverlap):
+		return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: "leave already requested on some of the days"})
+	case errors.Is(err, service.ErrLeaveNotPending), errors.Is(err, service.ErrLeaveNotCancellable):
+		return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrInvalidLeave):
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrNotLeaveOwner):
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
+	case errors.Is(err, db.ErrNotFound), strings.Contains(err.Error(), db.ErrNotFound.Error()):
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "leave request not found"})
+	default:
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+}
@@ -0,0 +1,129 @@
+package gateways
+
+import (
+	"errors"
+	"strings"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/middlewares"
+	service "lama-backend/src/services"
+	"lama-backend/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Save medical record
+// @Description Write the visit notes of an mservice: diagnosis, symptoms, vitals (weight kg, temperature °C), prescribed medicines and follow-up. Saving again replaces the whole record, medicines included. Only the doctor assigned to the service, once it is ongoing or finished.
+// @Tags medical record
+// @Accept json
+// @Produce json
+// @Param serviceID path string true "Service ID"
+// @Param body body entities.SaveMedicalRecordRequest true "visit notes"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid request"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Not the assigned doctor"
+// @Failure 404 {object} entities.ResponseMessage "Service not found"
+// @Failure 409 {object} entities.ResponseMessage "Not an mservice or visit not started"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /services/{serviceID}/medical-record [put]
+// @Security BearerAuth
+func (h *HTTPGateway) SaveMedicalRecord(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	serviceID := ctx.Params("serviceID")
+	if err := h.Validator.Var(serviceID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid service ID"})
+	}
+
+	var req entities.SaveMedicalRecordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	record, err := h.MedicalRecordService.SaveMedicalRecord(serviceID, token.UserID, req)
+	if err != nil {
+		return medicalRecordErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "Request successful",
+		Data:    record,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Get medical record of a service
+// @Description Visit notes of an mservice. Owners see their own services, doctors the services assigned to them.
+// @Tags medical record
+// @Produce json
+// @Param serviceID path string true "Service ID"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Service or medical record not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /services/{serviceID}/medical-record [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetMedicalRecord(ctx *fiber.Ctx) error {
+	record, err := h.MedicalRecordService.FindMedicalRecord(bookedService(ctx).Sid)
+	if err != nil {
+		return medicalRecordErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "Request successful",
+		Data:    record,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Get medical records of a pet
+// @Description Every visit record of the pet, newest first. Owners see their own pets, doctors the pets they have treated, admins any pet.
+// @Tags medical record
+// @Produce json
+// @Param petID path string true "Pet ID"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid pet ID"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Pet not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pets/{petID}/medical-records [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetPetMedicalRecords(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	petID := ctx.Params("petID")
+	if err := h.Validator.Var(petID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid pet ID"})
+	}
+
+	records, err := h.MedicalRecordService.FindPetMedicalRecords(petID, token.UserID, token.Role)
+	if err != nil {
+		return medicalRecordErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "Request successful",
+		Data:    records,
+		Status:  fiber.StatusOK,
+	})
+}
+
+func medicalRecordErrorResponse(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotAssignedDoctor), errors.Is(err, service.ErrMedicalRecordForbidden):
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrNotMedicalService), errors.Is(err, service.ErrMedicalRecordNotAllowed):
+		return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrInvalidMedicalRecord):
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, db.ErrNotFound), strings.Contains(err.Error(), "not found"):
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "not found"})
+	default:
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+}
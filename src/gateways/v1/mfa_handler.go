@@ -0,0 +1,338 @@
+package gateways
+
+import (
+	"errors"
+
+	"lama-backend/domain/entities"
+	"lama-backend/src/middlewares"
+	service "lama-backend/src/services"
+	"lama-backend/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary 2FA status
+// @Description Whether 2FA is on for the account, whether one of its roles requires it and how many recovery codes are left.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} entities.ResponseModel{data=entities.MfaStatusModel} "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /auth/mfa [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetMfaStatus(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	status, err := h.AuthService.MfaStatus(token.UserID)
+	if err != nil {
+		return mfaErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    status,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Set up 2FA
+// @Description Make a new TOTP secret, show otpauth_uri as a QR code for the authenticator app. 2FA is not on until POST /auth/mfa/enable. Takes an access token, or the mfa_pending token from login when the role requires 2FA and the account has none yet.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} entities.ResponseModel{data=entities.MfaSetupModel} "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 409 {object} entities.ResponseMessage "2FA is already enabled"
+// @Failure 503 {object} entities.ResponseMessage "2FA is not configured on this server"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /auth/mfa/setup [post]
+// @Security BearerAuth
+func (h *HTTPGateway) SetupMfa(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	setup, err := h.AuthService.SetupMfa(token.UserID)
+	if err != nil {
+		return mfaErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "scan the QR code then confirm with a code from the app",
+		Data:    setup,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Enable 2FA
+// @Description Confirm the secret from POST /auth/mfa/setup with a code from the app. Returns the recovery codes, they are shown only this once. With the mfa_pending token from login the login finishes and the token is returned too.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body entities.MfaCodeRequest true "code from the authenticator app"
+// @Success 200 {object} entities.ResponseModel "Request successful, data has recovery_codes and token"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token or wrong code"
+// @Failure 409 {object} entities.ResponseMessage "2FA is already enabled or setup was not started"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 429 {object} entities.ResponseMessage "Too many wrong codes"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /auth/mfa/enable [post]
+// @Security BearerAuth
+func (h *HTTPGateway) EnableMfa(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	bodyData, err := h.mfaCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	codes, session, err := h.AuthService.EnableMfa(token, bodyData.Code, ctx.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		return mfaErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "2FA enabled, keep the recovery codes somewhere safe",
+		Data:    fiber.Map{"recovery_codes": codes, "token": session},
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Verify 2FA
+// @Description Finish a login with the mfa_pending token and a code from the app or a recovery code. Each code works once.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body entities.MfaCodeRequest true "code from the authenticator app or a recovery code"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token or wrong code"
+// @Failure 409 {object} entities.ResponseMessage "2FA is not enabled"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 429 {object} entities.ResponseMessage "Too many wrong codes"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /auth/mfa/verify [post]
+// @Security BearerAuth
+func (h *HTTPGateway) VerifyMfa(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	bodyData, err := h.mfaCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	session, err := h.AuthService.VerifyMfa(token, bodyData.Code, ctx.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		return mfaErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    session,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Disable 2FA
+// @Description Turn 2FA off, not allowed while one of the account's roles requires it.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body entities.MfaCodeRequest true "code from the authenticator app or a recovery code"
+// @Success 200 {object} entities.ResponseMessage "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token or wrong code"
+// @Failure 403 {object} entities.ResponseMessage "2FA is required for a role of the account"
+// @Failure 409 {object} entities.ResponseMessage "2FA is not enabled"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 429 {object} entities.ResponseMessage "Too many wrong codes"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /auth/mfa/disable [post]
+// @Security BearerAuth
+func (h *HTTPGateway) DisableMfa(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	bodyData, err := h.mfaCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := h.AuthService.DisableMfa(token.UserID, bodyData.Code); err != nil {
+		return mfaErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{Message: "2FA disabled"})
+}
+
+// @Summary New recovery codes
+// @Description Replace the recovery codes, the old ones stop working.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body entities.MfaCodeRequest true "code from the authenticator app or a recovery code"
+// @Success 200 {object} entities.ResponseModel "Request successful, data is the new recovery codes"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token or wrong code"
+// @Failure 409 {object} entities.ResponseMessage "2FA is not enabled"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 429 {object} entities.ResponseMessage "Too many wrong codes"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /auth/mfa/recovery-codes [post]
+// @Security BearerAuth
+func (h *HTTPGateway) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	bodyData, err := h.mfaCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	codes, err := h.AuthService.RegenerateRecoveryCodes(token.UserID, bodyData.Code)
+	if err != nil {
+		return mfaErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    codes,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary list roles that require 2FA
+// @Tags admin
+// @Produce json
+// @Success 200 {object} entities.ResponseModel{data=[]entities.MfaRequirementModel} "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /admin/mfa/roles [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetMfaRequirements(ctx *fiber.Ctx) error {
+	requirements, err := h.AuthService.FindMfaRequirements()
+	if err != nil {
+		return mfaErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    requirements,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary require 2FA for a role
+// @Description Every login as the role must pass 2FA, accounts without it set it up at their next login. Open sessions of the role that did not pass 2FA are closed. Requiring it for admin needs 2FA on the acting admin's account first.
+// @Tags admin
+// @Produce json
+// @Param role path string true "admin, doctor or caretaker"
+// @Success 200 {object} entities.ResponseModel "Request successful, data has the requirement and the number of closed sessions"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 409 {object} entities.ResponseMessage "Turn on 2FA for your own account first"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /admin/mfa/roles/{role} [put]
+// @Security BearerAuth
+func (h *HTTPGateway) RequireMfa(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	role := ctx.Params("role")
+	if !mfaPolicyRole(role) {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
+	}
+
+	requirement, revoked, err := h.AuthService.RequireMfa(role, token.UserID)
+	if err != nil {
+		if errors.Is(err, service.ErrMfaNotEnabled) {
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: "turn on 2FA for your own account first"})
+		}
+		return mfaErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "2FA is now required for " + role,
+		Data:    fiber.Map{"requirement": requirement, "revoked_sessions": revoked},
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary stop requiring 2FA for a role
+// @Description Accounts that already have 2FA keep it.
+// @Tags admin
+// @Produce json
+// @Param role path string true "admin, doctor or caretaker"
+// @Success 200 {object} entities.ResponseMessage "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "2FA is not required for the role"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /admin/mfa/roles/{role} [delete]
+// @Security BearerAuth
+func (h *HTTPGateway) UnrequireMfa(ctx *fiber.Ctx) error {
+	role := ctx.Params("role")
+	if !mfaPolicyRole(role) {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
+	}
+
+	removed, err := h.AuthService.UnrequireMfa(role)
+	if err != nil {
+		return mfaErrorResponse(ctx, err)
+	}
+	if !removed {
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "2FA is not required for " + role})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{Message: "2FA is no longer required for " + role})
+}
+
+// @Summary reset 2FA of a user
+// @Description For a user who lost both the authenticator app and the recovery codes. If a role of the user requires 2FA they set it up again at the next login.
+// @Tags admin
+// @Produce json
+// @Param userID path string true "User ID"
+// @Success 200 {object} entities.ResponseMessage "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 409 {object} entities.ResponseMessage "2FA is not enabled"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /admin/users/{userID}/mfa [delete]
+// @Security BearerAuth
+func (h *HTTPGateway) ResetUserMfa(ctx *fiber.Ctx) error {
+	if err := h.AuthService.ResetMfa(ctx.Params("userID")); err != nil {
+		return mfaErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{Message: "2FA reset"})
+}
+
+// mfaCode parses the body, on failure the response is already written and returned as err.
+func (h *HTTPGateway) mfaCode(ctx *fiber.Ctx) (entities.MfaCodeRequest, error) {
+	bodyData := entities.MfaCodeRequest{}
+	if err := ctx.BodyParser(&bodyData); err != nil {
+		return bodyData, ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(bodyData); err != nil {
+		return bodyData, ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+	return bodyData, nil
+}
+
+// owner ไม่ได้แตะข้อมูลคนอื่น ไม่ต้องบังคับ
+func mfaPolicyRole(role string) bool {
+	return role == "admin" || role == "doctor" || role == "caretaker"
+}
+
+func mfaErrorResponse(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidMfaCode):
+		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrMfaLocked):
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrMfaRequired):
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrMfaAlreadyEnabled), errors.Is(err, service.ErrMfaNotEnabled), errors.Is(err, service.ErrMfaNotStarted):
+		return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrMfaNotConfigured):
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(entities.ResponseMessage{Message: err.Error()})
+	default:
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+}
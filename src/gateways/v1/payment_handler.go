@@ -25,10 +25,7 @@ import (
 // @Failure      500 {object} entities.ResponseMessage "Internal server error"
 // @Router       /payments [get]
 func (h *HTTPGateway) GetMyPayment(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
+	token := middlewares.AccessToken(ctx)
 
 	month := ctx.QueryInt("month")
 	year := ctx.QueryInt("year")
@@ -36,15 +33,13 @@ func (h *HTTPGateway) GetMyPayment(ctx *fiber.Ctx) error {
 	limit := ctx.QueryInt("limit", 5)
 	var payments []*entities.PaymentModel
 	var total int
+	var err error
 
 	switch token.Role {
 	case "admin":
 		payments, total, err = h.PaymentService.FindAllPayments(month, year, page, limit)
 	case "owner":
 		payments, total, err = h.PaymentService.FindPaymentsByOwnerID(token.UserID, month, year, page, limit)
-
-	default:
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token. have to be admin or owner"})
 	}
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
@@ -76,15 +71,6 @@ func (h *HTTPGateway) GetMyPayment(ctx *fiber.Ctx) error {
 // @Router       /payments/{paymentID} [patch]
 func (h *HTTPGateway) UpdatePaymentByID(ctx *fiber.Ctx) error {
 
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-
-	if token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role (Admin only)"})
-	}
-
 	paymentID := ctx.Params("paymentID")
 	if paymentID == "" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid payment ID"})
@@ -1,17 +1,20 @@
 package gateways
 
 import (
+	"errors"
 	"lama-backend/domain/entities"
 	"lama-backend/src/middlewares"
+	service "lama-backend/src/services"
 	"lama-backend/src/utils"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
 // @Summary create pet
-// @Description owner can create a pet (only role == "owner"). Admin can also create a pet for a specific owner by providing `ownerID` in the path.
+// @Description owner creates a pet under their own ID with POST /pets. Admin creates a pet for a specific owner with POST /admin/owners/{ownerID}/pets.
 // @Tags pet
 // @Accept json
 // @Produce json
@@ -23,18 +26,11 @@ import (
 // @Failure 403 {object} entities.ResponseMessage "Invalid role"
 // @Failure 422 {object} entities.ResponseMessage "Validation error"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
-// @Router /pets/{ownerID} [post]
+// @Router /pets [post]
+// @Router /admin/owners/{ownerID}/pets [post]
 // @Security BearerAuth
 func (h *HTTPGateway) CreatePet(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-
-	// only owner or admin can create pets (admin can create for other owners via path param)
-	if token.Role != "owner" && token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
+	token := middlewares.AccessToken(ctx)
 
 	var pet entities.CreatedPetModel
 	if err := ctx.BodyParser(&pet); err != nil {
@@ -84,15 +80,7 @@ func (h *HTTPGateway) CreatePet(ctx *fiber.Ctx) error {
 // @Router /pets/owner [get]
 // @Security BearerAuth
 func (h *HTTPGateway) FindByOwnerID(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-
-	// This endpoint is owner-only: ownerID must come from the JWT token.
-	if token.Role != "owner" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
+	token := middlewares.AccessToken(ctx)
 
 	ownerID := token.UserID
 	if ownerID == "" {
@@ -115,25 +103,17 @@ func (h *HTTPGateway) FindByOwnerID(ctx *fiber.Ctx) error {
 }
 
 // @Summary get all pets for specified owner
-// @Description admin can fetch all pets (provide ownerID in path)
+// @Description admin can fetch all pets of an owner
 // @Tags pet
 // @Produce json
+// @Param ownerID path string true "owner id"
 // @Success 200 {object} entities.ResponseModel "Request successful"
 // @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
 // @Failure 403 {object} entities.ResponseMessage "Invalid role"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
-// @Router /pets/{ownerID} [get]
+// @Router /admin/owners/{ownerID}/pets [get]
 // @Security BearerAuth
 func (h *HTTPGateway) FindAllPets(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-
-	// This endpoint is owner-only: ownerID must come from the JWT token.
-	if token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
 
 	ownerID := ctx.Params("ownerID")
 	if ownerID == "" {
@@ -172,16 +152,6 @@ func (h *HTTPGateway) FindAllPets(ctx *fiber.Ctx) error {
 // @Router /pets/{petID} [patch]
 // @Security BearerAuth
 func (h *HTTPGateway) UpdatePet(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-
-	// only owner or admin can update pets
-	if token.Role != "owner" && token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
-
 	petID := ctx.Params("petID")
 	if petID == "" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid pet ID"})
@@ -201,24 +171,6 @@ func (h *HTTPGateway) UpdatePet(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
 	}
 
-	// If owner, ensure they own the pet
-	if token.Role == "owner" {
-		ownerPets, err := h.PetService.FindByOwnerID(token.UserID)
-		if err != nil {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
-		}
-		owned := false
-		for _, p := range ownerPets {
-			if p.PetID == petID {
-				owned = true
-				break
-			}
-		}
-		if !owned {
-			return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "You do not own this pet"})
-		}
-	}
-
 	updated, err := h.PetService.UpdatePet(petID, req)
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "not found") {
@@ -248,49 +200,144 @@ func (h *HTTPGateway) UpdatePet(ctx *fiber.Ctx) error {
 // @Router /pets/{petID} [delete]
 // @Security BearerAuth
 func (h *HTTPGateway) DeletePet(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
+	petID := ctx.Params("petID")
+	if petID == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid pet ID"})
 	}
 
-	if token.Role != "owner" && token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
+	deleted, err := h.PetService.DeletePet(petID)
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "not found") {
+			return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "pet not found"})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
 	}
 
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "pet deleted",
+		Data:    deleted,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary get pet timeline
+// @Description History of a pet in chronological order: cservice and mservice bookings (finished and upcoming), diagnoses, medicines, reviews, weight changes and vaccinations. Owners can read their own pets, doctors the pets they have treated, admins any pet.
+// @Tags pet
+// @Produce json
+// @Param petID path string true "pet id"
+// @Param type query string false "comma separated event types" Enums(cservice, mservice, diagnosis, medicine, review, weight, vaccination)
+// @Param page query int false "Page number for pagination" [optional default: 1]
+// @Param limit query int false "Number of items per page" [optional default: 20]
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid pet ID or type"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role or not allowed to read this pet"
+// @Failure 404 {object} entities.ResponseMessage "pet not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pets/{petID}/timeline [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetPetTimeline(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
 	petID := ctx.Params("petID")
-	if petID == "" {
+	if err := h.Validator.Var(petID, "uuid"); err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid pet ID"})
 	}
 
-	// If owner role, ensure they own the pet
-	if token.Role == "owner" {
-		ownerPets, err := h.PetService.FindByOwnerID(token.UserID)
-		if err != nil {
-			return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	filter := entities.PetTimelineFilter{
+		Page:  ctx.QueryInt("page", 1),
+		Limit: ctx.QueryInt("limit", 20),
+	}
+	for _, t := range strings.Split(ctx.Query("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			filter.Types = append(filter.Types, t)
 		}
-		owned := false
-		for _, p := range ownerPets {
-			if p.PetID == petID {
-				owned = true
-				break
-			}
+	}
+
+	events, total, err := h.PetService.FindPetTimeline(petID, token.UserID, token.Role, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTimelineType):
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
+		case errors.Is(err, service.ErrPetTimelineForbidden):
+			return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: err.Error()})
+		case strings.Contains(strings.ToLower(err.Error()), "not found"):
+			return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "pet not found"})
 		}
-		if !owned {
-			return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "You do not own this pet"})
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data: fiber.Map{
+			"page":   filter.Page,
+			"amount": total,
+			"events": events,
+		},
+		Status: fiber.StatusOK,
+	})
+}
+
+// @Summary get pet weight history
+// @Description Weight measurements of a pet over time, from profile updates and from vitals recorded by doctors at visits. With bucket the measurements of each day/week/month are averaged into one point (with min, max and count). Owners can read their own pets, doctors the pets they have treated, admins any pet.
+// @Tags pet
+// @Produce json
+// @Param petID path string true "pet id"
+// @Param from query string false "first day (YYYY-MM-DD)"
+// @Param to query string false "last day, inclusive (YYYY-MM-DD)"
+// @Param bucket query string false "downsample" Enums(day, week, month)
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid pet ID, date or bucket"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role or not owner's pet"
+// @Failure 404 {object} entities.ResponseMessage "pet not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pets/{petID}/weights [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetPetWeights(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	petID := ctx.Params("petID")
+	if err := h.Validator.Var(petID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid pet ID"})
+	}
+
+	filter := entities.PetWeightFilter{Bucket: ctx.Query("bucket")}
+	if from := ctx.Query("from"); from != "" {
+		day, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "from must be YYYY-MM-DD"})
+		}
+		filter.From = &day
+	}
+	if to := ctx.Query("to"); to != "" {
+		day, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "to must be YYYY-MM-DD"})
 		}
+		end := day.AddDate(0, 0, 1)
+		filter.To = &end
 	}
 
-	deleted, err := h.PetService.DeletePet(petID)
+	points, err := h.PetService.FindWeightHistory(petID, token.UserID, token.Role, filter)
 	if err != nil {
-		if strings.Contains(strings.ToLower(err.Error()), "not found") {
+		switch {
+		case errors.Is(err, service.ErrInvalidWeightBucket):
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
+		case errors.Is(err, service.ErrPetWeightForbidden):
+			return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: err.Error()})
+		case strings.Contains(strings.ToLower(err.Error()), "not found"):
 			return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "pet not found"})
 		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
-		Message: "pet deleted",
-		Data:    deleted,
-		Status:  fiber.StatusOK,
+		Message: "success",
+		Data: fiber.Map{
+			"amount":  len(points),
+			"weights": points,
+		},
+		Status: fiber.StatusOK,
 	})
 }
@@ -0,0 +1,421 @@
+package gateways
+
+import (
+	"errors"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	service "lama-backend/src/services"
+	"lama-backend/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get pricing rules
+// @Description Admin lists every rate card, staff rate, pet surcharge, time multiplier and holiday used to price bookings.
+// @Tags pricing
+// @Produce json
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetPricing(ctx *fiber.Ctx) error {
+	pricing, err := h.PricingService.GetPricing()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    pricing,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Set rate card
+// @Description Admin sets the hourly rate (THB) for a service type. Creates the rate card if it doesn't exist.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param serviceType path string true "Service type (mservice or cservice)"
+// @Param body body entities.RateRequest true "hourly rate"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/rate-cards/{serviceType} [put]
+// @Security BearerAuth
+func (h *HTTPGateway) UpsertRateCard(ctx *fiber.Ctx) error {
+	var req entities.RateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	rateCard, err := h.PricingService.UpsertRateCard(ctx.Params("serviceType"), req)
+	if err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "rate card saved",
+		Data:    rateCard,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Delete rate card
+// @Description Admin removes the rate card of a service type. Bookings of that type fall back to the default rate.
+// @Tags pricing
+// @Produce json
+// @Param serviceType path string true "Service type (mservice or cservice)"
+// @Success 200 {object} entities.ResponseMessage "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Rate card not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/rate-cards/{serviceType} [delete]
+// @Security BearerAuth
+func (h *HTTPGateway) DeleteRateCard(ctx *fiber.Ctx) error {
+	if err := h.PricingService.DeleteRateCard(ctx.Params("serviceType")); err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{Message: "rate card deleted"})
+}
+
+// @Summary Set staff rate
+// @Description Admin sets an hourly rate (THB) for one caretaker or doctor. It overrides the rate card of the service type.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param staffID path string true "Staff ID"
+// @Param body body entities.RateRequest true "hourly rate"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body or staff ID"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/staff-rates/{staffID} [put]
+// @Security BearerAuth
+func (h *HTTPGateway) UpsertStaffRate(ctx *fiber.Ctx) error {
+	staffID := ctx.Params("staffID")
+	if err := h.Validator.Var(staffID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid staff ID"})
+	}
+
+	var req entities.RateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	staffRate, err := h.PricingService.UpsertStaffRate(staffID, req)
+	if err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "staff rate saved",
+		Data:    staffRate,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Delete staff rate
+// @Description Admin removes a staff rate override.
+// @Tags pricing
+// @Produce json
+// @Param staffID path string true "Staff ID"
+// @Success 200 {object} entities.ResponseMessage "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid staff ID"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Staff rate not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/staff-rates/{staffID} [delete]
+// @Security BearerAuth
+func (h *HTTPGateway) DeleteStaffRate(ctx *fiber.Ctx) error {
+	staffID := ctx.Params("staffID")
+	if err := h.Validator.Var(staffID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid staff ID"})
+	}
+
+	if err := h.PricingService.DeleteStaffRate(staffID); err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{Message: "staff rate deleted"})
+}
+
+// @Summary Create pet surcharge
+// @Description Admin adds an hourly surcharge for pets matching a kind and/or weight band [min_weight, max_weight). Leave a field empty to match any value.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param body body entities.PetSurchargeRequest true "surcharge rule"
+// @Success 201 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/pet-surcharges [post]
+// @Security BearerAuth
+func (h *HTTPGateway) CreatePetSurcharge(ctx *fiber.Ctx) error {
+	var req entities.PetSurchargeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	surcharge, err := h.PricingService.CreatePetSurcharge(req)
+	if err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{
+		Message: "pet surcharge created",
+		Data:    surcharge,
+		Status:  fiber.StatusCreated,
+	})
+}
+
+// @Summary Update pet surcharge
+// @Description Admin replaces a pet surcharge rule. Optional fields that are left out are cleared.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param surchargeID path string true "Pet surcharge ID"
+// @Param body body entities.PetSurchargeRequest true "surcharge rule"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Pet surcharge not found"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/pet-surcharges/{surchargeID} [put]
+// @Security BearerAuth
+func (h *HTTPGateway) UpdatePetSurcharge(ctx *fiber.Ctx) error {
+	var req entities.PetSurchargeRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	surcharge, err := h.PricingService.UpdatePetSurcharge(ctx.Params("surchargeID"), req)
+	if err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "pet surcharge updated",
+		Data:    surcharge,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Delete pet surcharge
+// @Description Admin removes a pet surcharge rule.
+// @Tags pricing
+// @Produce json
+// @Param surchargeID path string true "Pet surcharge ID"
+// @Success 200 {object} entities.ResponseMessage "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Pet surcharge not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/pet-surcharges/{surchargeID} [delete]
+// @Security BearerAuth
+func (h *HTTPGateway) DeletePetSurcharge(ctx *fiber.Ctx) error {
+	if err := h.PricingService.DeletePetSurcharge(ctx.Params("surchargeID")); err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{Message: "pet surcharge deleted"})
+}
+
+// @Summary Create time multiplier
+// @Description Admin adds a price multiplier for hours in [start_hour, end_hour) Thai time. day_type weekend also covers holidays. Only the part above the base rate is charged as an extra line item.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param body body entities.PriceMultiplierRequest true "multiplier rule"
+// @Success 201 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/multipliers [post]
+// @Security BearerAuth
+func (h *HTTPGateway) CreateMultiplier(ctx *fiber.Ctx) error {
+	var req entities.PriceMultiplierRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	multiplier, err := h.PricingService.CreateMultiplier(req)
+	if err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{
+		Message: "multiplier created",
+		Data:    multiplier,
+		Status:  fiber.StatusCreated,
+	})
+}
+
+// @Summary Update time multiplier
+// @Description Admin replaces a time multiplier rule.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param multiplierID path string true "Multiplier ID"
+// @Param body body entities.PriceMultiplierRequest true "multiplier rule"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Multiplier not found"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/multipliers/{multiplierID} [put]
+// @Security BearerAuth
+func (h *HTTPGateway) UpdateMultiplier(ctx *fiber.Ctx) error {
+	var req entities.PriceMultiplierRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	multiplier, err := h.PricingService.UpdateMultiplier(ctx.Params("multiplierID"), req)
+	if err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "multiplier updated",
+		Data:    multiplier,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Delete time multiplier
+// @Description Admin removes a time multiplier rule.
+// @Tags pricing
+// @Produce json
+// @Param multiplierID path string true "Multiplier ID"
+// @Success 200 {object} entities.ResponseMessage "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Multiplier not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/multipliers/{multiplierID} [delete]
+// @Security BearerAuth
+func (h *HTTPGateway) DeleteMultiplier(ctx *fiber.Ctx) error {
+	if err := h.PricingService.DeleteMultiplier(ctx.Params("multiplierID")); err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{Message: "multiplier deleted"})
+}
+
+// @Summary Set holiday
+// @Description Admin marks a date (format: YYYY-MM-DD) as a holiday. Holidays are priced with weekend multipliers.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param date path string true "Holiday date"
+// @Param body body entities.HolidayRequest true "holiday name"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body or date"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/holidays/{date} [put]
+// @Security BearerAuth
+func (h *HTTPGateway) UpsertHoliday(ctx *fiber.Ctx) error {
+	date, err := time.Parse("2006-01-02", ctx.Params("date"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid date or date format, expected YYYY-MM-DD"})
+	}
+
+	var req entities.HolidayRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	holiday, err := h.PricingService.UpsertHoliday(date, req)
+	if err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "holiday saved",
+		Data:    holiday,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Delete holiday
+// @Description Admin removes a holiday (format: YYYY-MM-DD).
+// @Tags pricing
+// @Produce json
+// @Param date path string true "Holiday date"
+// @Success 200 {object} entities.ResponseMessage "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid date"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Holiday not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pricing/holidays/{date} [delete]
+// @Security BearerAuth
+func (h *HTTPGateway) DeleteHoliday(ctx *fiber.Ctx) error {
+	date, err := time.Parse("2006-01-02", ctx.Params("date"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid date or date format, expected YYYY-MM-DD"})
+	}
+
+	if err := h.PricingService.DeleteHoliday(date); err != nil {
+		return pricingErrorResponse(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{Message: "holiday deleted"})
+}
+
+func pricingErrorResponse(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "pricing rule not found"})
+	case errors.Is(err, service.ErrInvalidPricingRule):
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	default:
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+}
@@ -0,0 +1,206 @@
+package gateways
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/middlewares"
+	service "lama-backend/src/services"
+	"lama-backend/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary      Reassign staff bookings
+// @Description  Move the waiting bookings of a caretaker or doctor that start between start_date and end_date (inclusive, no end_date = all upcoming) to other staff, e.g. before their leave. mode auto (default) picks the best rated staff that is free at the same time and flags the bookings nobody can take; mode flag leaves every booking to an admin (GET /admin/reassignments). The owner's price is not changed. (admin only)
+// @Tags         reassignment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        staffID path string true "StaffID"
+// @Param        body body entities.ReassignBookingsRequest true "date range and mode"
+// @Success      200 {object} entities.ResponseModel  "What happened to each booking"
+// @Failure      400 {object} entities.ResponseMessage "Invalid request"
+// @Failure      401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure      403 {object} entities.ResponseMessage "Invalid role"
+// @Failure      422 {object} entities.ResponseMessage "Validation error"
+// @Failure      500 {object} entities.ResponseMessage "Internal server error"
+// @Router       /admin/staff/{staffID}/reassign [post]
+func (h *HTTPGateway) ReassignStaffBookings(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	staffID := ctx.Params("staffID")
+	if err := h.Validator.Var(staffID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid staff ID"})
+	}
+
+	var req entities.ReassignBookingsRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	from, _ := time.Parse("2006-01-02", req.StartDate)
+	// booking ที่เริ่มไปแล้วย้ายไม่ได้
+	from = utils.MaxTime(from, time.Now())
+	var to *time.Time
+	if req.EndDate != nil {
+		endDate, _ := time.Parse("2006-01-02", *req.EndDate)
+		endDate = endDate.AddDate(0, 0, 1)
+		if !endDate.After(from) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "end_date must not be before start_date or in the past"})
+		}
+		to = &endDate
+	}
+
+	results, err := h.ServiceService.ReassignStaffBookings(staffID, from, to, db.ReassignmentReasonStaffUnavailable, req.Mode != "flag", &token.UserID, req.Note)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: reassignmentSummary(results),
+		Data:    results,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary      Get reassignments
+// @Description  Bookings moved away from unavailable or deleted staff. status defaults to needs_action, the bookings still waiting for an admin. (admin only)
+// @Tags         reassignment
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status query string false "reassigned, needs_action, resolved or dismissed"
+// @Success      200 {object} entities.ResponseModel  "Request successful"
+// @Failure      400 {object} entities.ResponseMessage "Invalid status"
+// @Failure      401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure      403 {object} entities.ResponseMessage "Invalid role"
+// @Failure      500 {object} entities.ResponseMessage "Internal server error"
+// @Router       /admin/reassignments [get]
+func (h *HTTPGateway) GetReassignments(ctx *fiber.Ctx) error {
+	status := ctx.Query("status", string(db.ReassignmentStatusNeedsAction))
+	if err := h.Validator.Var(status, "oneof=reassigned needs_action resolved dismissed"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid status"})
+	}
+
+	results, err := h.ServiceService.FindReassignmentsByStatus(db.ReassignmentStatus(status))
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "Request successful",
+		Data:    results,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary      Resolve reassignment
+// @Description  Close a needs_action reassignment. With staff_id the booking moves to that staff (they must be free and of the right type), without it the reassignment is dismissed, e.g. after cancelling the booking. (admin only)
+// @Tags         reassignment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        reassignmentID path string true "Reassignment ID"
+// @Param        body body entities.ResolveReassignmentRequest true "new staff"
+// @Success      200 {object} entities.ResponseModel  "Request successful"
+// @Failure      400 {object} entities.ResponseMessage "Invalid request"
+// @Failure      401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure      403 {object} entities.ResponseMessage "Invalid role"
+// @Failure      404 {object} entities.ResponseMessage "Reassignment or staff not found"
+// @Failure      409 {object} entities.ResponseMessage "Already closed, staff busy or booking no longer waiting"
+// @Failure      422 {object} entities.ResponseMessage "Validation error"
+// @Failure      500 {object} entities.ResponseMessage "Internal server error"
+// @Router       /admin/reassignments/{reassignmentID} [patch]
+func (h *HTTPGateway) ResolveReassignment(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	reassignmentID := ctx.Params("reassignmentID")
+	if err := h.Validator.Var(reassignmentID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid reassignment ID"})
+	}
+
+	var req entities.ResolveReassignmentRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	result, err := h.ServiceService.ResolveReassignment(reassignmentID, token.UserID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrReassignmentClosed), errors.Is(err, service.ErrBookingNotWaiting):
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+		case errors.Is(err, service.ErrStaffUnavailable):
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: "staff is not available at that time"})
+		case errors.Is(err, db.ErrNotFound), strings.Contains(err.Error(), db.ErrNotFound.Error()):
+			return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "reassignment or staff not found"})
+		default:
+			return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "Request successful",
+		Data:    result,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Get service reassignments
+// @Description Staff changes of a service made because the assigned staff became unavailable or was deleted, oldest first. Owners see their own services, caretakers and doctors the services assigned to them.
+// @Tags service
+// @Produce json
+// @Param serviceID path string true "Service ID"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Service not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /services/{serviceID}/reassignments [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetServiceReassignments(ctx *fiber.Ctx) error {
+	results, err := h.ServiceService.FindReassignments(bookedService(ctx).Sid)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "Request successful",
+		Data:    results,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// releaseStaffBookings runs before a caretaker or doctor is deleted, the Cservice/Mservice
+// rows go with them so their upcoming bookings have to be moved (or flagged) first.
+// Any other error stops the delete, only a missing user or a non-staff account has
+// nothing to release.
+func (h *HTTPGateway) releaseStaffBookings(userID string, actorID *string) ([]*entities.ServiceReassignmentModel, error) {
+	user, err := h.UsersService.FindUsersByID(userID)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !service.IsStaff(user) {
+		return nil, nil
+	}
+
+	return h.ServiceService.ReassignStaffBookings(userID, time.Now(), nil, db.ReassignmentReasonStaffDeleted, true, actorID, nil)
+}
+
+func reassignmentSummary(results []*entities.ServiceReassignmentModel) string {
+	flagged := 0
+	for _, r := range results {
+		if r.Status == db.ReassignmentStatusNeedsAction {
+			flagged++
+		}
+	}
+	return fmt.Sprintf("%d bookings reassigned, %d need admin action", len(results)-flagged, flagged)
+}
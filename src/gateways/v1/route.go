@@ -9,54 +9,131 @@ import (
 func GatewayUsers(gateway HTTPGateway, app *fiber.App) {
 
 	api := app.Group("/api/v1")
+	// ทุก route ที่ต้อง login ใช้ตัวนี้ เช็ค session ด้วยว่ายังไม่ถูก logout/revoke
+	jwt := middlewares.SetJWtHeaderHandler(gateway.AuthService.CheckSession)
+	// สิทธิ์ของแต่ละ route ดู accessMatrix ใน middlewares/rbac.go
+	can := middlewares.Authorize
 
 	auth := api.Group("/auth")
 	// check to login with token if not pass go to login with password
-	auth.Get("/token", middlewares.SetJWtHeaderHandler(), gateway.checkToken)
+	auth.Get("/token", jwt, gateway.checkToken)
 	auth.Post("/register/:role", gateway.Register)
+	// ไม่ระบุ role = login ด้วย role ตอนสมัคร
+	auth.Post("/login", gateway.Login)
 	auth.Post("/login/:role", gateway.Login)
-	auth.Post("/admin", middlewares.SetJWtHeaderHandler(), gateway.CreateAdmin)
+	auth.Post("/refresh", gateway.RefreshToken)
+	auth.Post("/logout", jwt, can("account", "logout"), gateway.Logout)
+	auth.Post("/logout/all", jwt, can("account", "logout"), gateway.LogoutAll)
+	auth.Post("/switch-role/:role", jwt, can("account", "switch_role"), gateway.SwitchRole)
+	auth.Post("/admin", jwt, can("user", "create"), gateway.CreateAdmin)
 	auth.Post("/password/email", gateway.ForgotPassword)
 	auth.Patch("/password", gateway.ResetPassword)
+	// setup/enable/verify รับ mfa_pending token จาก login ได้ด้วย ยังไม่มี session ให้ can เช็ค
+	auth.Get("/mfa", jwt, can("account", "mfa"), gateway.GetMfaStatus)
+	auth.Post("/mfa/setup", jwt, middlewares.AuthorizeMfaStep(true), gateway.SetupMfa)
+	auth.Post("/mfa/enable", jwt, middlewares.AuthorizeMfaStep(true), gateway.EnableMfa)
+	auth.Post("/mfa/verify", jwt, middlewares.AuthorizeMfaStep(false), gateway.VerifyMfa)
+	auth.Post("/mfa/disable", jwt, can("account", "mfa"), gateway.DisableMfa)
+	auth.Post("/mfa/recovery-codes", jwt, can("account", "mfa"), gateway.RegenerateRecoveryCodes)
 
-	user := api.Group("/user", middlewares.SetJWtHeaderHandler())
-	user.Get("/", gateway.FindUserByID)
-	user.Patch("/", gateway.UpdateUserByID)
-	user.Patch("/profile", gateway.UpdateUserPicture)
-	user.Delete("/", gateway.DeleteUserByID)
-
-	admin := api.Group("/admin", middlewares.SetJWtHeaderHandler())
-	admin.Get("/users", gateway.GetAllUsers)
-	admin.Get("/users/:userID", gateway.FindUserByAdmin)
-	admin.Delete("/users/:userID", gateway.DeleteUserByAdmin)
-	admin.Patch("/users/:userID", gateway.UpdateUserByAdmin)
-
-	services := api.Group("/services", middlewares.SetJWtHeaderHandler())
-	services.Post("/", gateway.CreateServiceStripe)
-	services.Get("/", gateway.GetMyServices)
-	services.Patch("/:serviceID", gateway.UpdateService)
-	services.Delete("/:serviceID", gateway.DeleteService)
-	services.Get("/staff", gateway.GetAvailableStaff)
-	services.Get("/staff/:staffID/time", gateway.GetBusyTimeSlot)
-	services.Get("/staff/score", gateway.GetScoreAndReview)
-	services.Get("/staff/:staffID/score", gateway.GetScoreAndReview)
-	services.Patch("/review/:serviceID", gateway.Review)
-	services.Patch("/:serviceID/:status", gateway.UpdateStatusService)
-
-	leaveday := api.Group("/leaveday", middlewares.SetJWtHeaderHandler())
-	leaveday.Post("/:day", gateway.CreateLeaveday)
-
-	pets := api.Group("/pets", middlewares.SetJWtHeaderHandler())
-	pets.Post("/", gateway.CreatePet)
-	pets.Post("/:ownerID", gateway.CreatePet)
-	pets.Get("/owner", gateway.FindByOwnerID)
-	pets.Get("/:ownerID", gateway.FindAllPets)
-	pets.Patch("/:petID", gateway.UpdatePet)
-	pets.Delete("/:petID", gateway.DeletePet)
-
-	payment := api.Group("/payments", middlewares.SetJWtHeaderHandler())
-	payment.Get("/", gateway.GetMyPayment)
-	payment.Patch("/:paymentID", gateway.UpdatePaymentByID)
+	// ไฟล์ของ storage แบบ local ใช้ signed url แทน token
+	api.Get("/files/:bucket/*", gateway.ServeFile)
+
+	user := api.Group("/user", jwt)
+	user.Get("/", can("account", "read"), gateway.FindUserByID)
+	user.Patch("/", can("account", "update"), gateway.UpdateUserByID)
+	user.Patch("/profile", can("account", "update"), gateway.UpdateUserPicture)
+	user.Delete("/", can("account", "delete"), gateway.DeleteUserByID)
+	user.Post("/roles/:role", can("account", "add_role"), gateway.AddMyRole)
+
+	admin := api.Group("/admin", jwt)
+	admin.Get("/users", can("user", "list"), gateway.GetAllUsers)
+	admin.Get("/users/:userID", can("user", "read"), gateway.FindUserByAdmin)
+	admin.Delete("/users/:userID", can("user", "delete"), gateway.DeleteUserByAdmin)
+	admin.Patch("/users/:userID", can("user", "update"), gateway.UpdateUserByAdmin)
+	admin.Post("/users/:userID/roles/:role", can("user", "grant_role"), gateway.GrantRole)
+	admin.Delete("/users/:userID/mfa", can("user", "reset_mfa"), gateway.ResetUserMfa)
+	admin.Get("/mfa/roles", can("mfa_policy", "read"), gateway.GetMfaRequirements)
+	admin.Put("/mfa/roles/:role", can("mfa_policy", "manage"), gateway.RequireMfa)
+	admin.Delete("/mfa/roles/:role", can("mfa_policy", "manage"), gateway.UnrequireMfa)
+	admin.Get("/owners/:ownerID/pets", can("pet", "list"), gateway.FindAllPets)
+	admin.Post("/owners/:ownerID/pets", can("pet", "create"), gateway.CreatePet)
+	admin.Post("/staff/:staffID/reassign", can("reassignment", "create"), gateway.ReassignStaffBookings)
+	admin.Get("/reassignments", can("reassignment", "list"), gateway.GetReassignments)
+	admin.Patch("/reassignments/:reassignmentID", can("reassignment", "resolve"), gateway.ResolveReassignment)
+	admin.Get("/vaccinations/due", can("vaccination", "list_due"), gateway.GetDueVaccinations)
+
+	services := api.Group("/services", jwt)
+	services.Post("/", can("service", "create"), gateway.CreateServiceStripe)
+	services.Get("/", can("service", "list"), gateway.GetMyServices)
+	services.Get("/quote", can("service", "create"), gateway.QuoteService)
+	services.Patch("/:serviceID", can("service", "update"), gateway.UpdateService)
+	services.Delete("/:serviceID", can("service", "cancel", gateway.serviceParty("serviceID")), gateway.DeleteService)
+	services.Post("/:serviceID/reschedule", can("service", "reschedule", gateway.serviceParty("serviceID")), gateway.RescheduleService)
+	services.Get("/staff", can("staff", "search"), gateway.GetAvailableStaff)
+	services.Get("/staff/:staffID/time", can("staff", "search"), gateway.GetBusyTimeSlot)
+	services.Get("/staff/:staffID/slots", can("staff", "read_slots"), gateway.GetFreeTimeSlot)
+	services.Get("/staff/score", can("staff", "read_score"), gateway.GetScoreAndReview)
+	services.Get("/staff/:staffID/score", can("staff", "read_score", self("staffID")), gateway.GetScoreAndReview)
+	services.Get("/:serviceID/history", can("service", "read", gateway.serviceParty("serviceID")), gateway.GetServiceStatusHistory)
+	services.Get("/:serviceID/reassignments", can("service", "read", gateway.serviceParty("serviceID")), gateway.GetServiceReassignments)
+	services.Get("/:serviceID/medical-record", can("medical_record", "read", gateway.serviceParty("serviceID")), gateway.GetMedicalRecord)
+	services.Put("/:serviceID/medical-record", can("medical_record", "write"), gateway.SaveMedicalRecord)
+	services.Get("/:serviceID/attachments", can("pet_record", "read"), gateway.GetServiceAttachments)
+	services.Post("/:serviceID/attachments", can("pet_record", "write"), gateway.UploadServiceAttachment)
+	services.Patch("/review/:serviceID", can("service", "review", gateway.serviceParty("serviceID")), gateway.Review)
+	services.Patch("/:serviceID/:status", can("service", "update_status"), gateway.UpdateStatusService)
+
+	schedule := api.Group("/schedule", jwt)
+	schedule.Get("/:staffID", can("schedule", "read"), gateway.GetStaffSchedule)
+	schedule.Put("/:staffID", can("schedule", "manage", self("staffID")), gateway.UpdateStaffSchedule)
+	schedule.Get("/:staffID/exceptions", can("schedule", "manage", self("staffID")), gateway.GetScheduleExceptions)
+	schedule.Post("/:staffID/exceptions", can("schedule", "manage", self("staffID")), gateway.CreateScheduleException)
+	schedule.Delete("/:staffID/exceptions/:exceptionID", can("schedule", "manage", self("staffID")), gateway.DeleteScheduleException)
+
+	leaveday := api.Group("/leaveday", jwt)
+	leaveday.Post("/", can("leave", "request"), gateway.RequestLeave)
+	leaveday.Get("/", can("leave", "list_own"), gateway.GetMyLeave)
+	leaveday.Get("/pending", can("leave", "review"), gateway.GetPendingLeave)
+	leaveday.Get("/day/:day", can("leave", "review"), gateway.GetLeaveByDay)
+	leaveday.Delete("/requests/:requestID", can("leave", "cancel"), gateway.CancelLeave)
+	leaveday.Patch("/requests/:requestID/approve", can("leave", "review"), gateway.ApproveLeave)
+	leaveday.Patch("/requests/:requestID/reject", can("leave", "review"), gateway.RejectLeave)
+	leaveday.Post("/:day", can("leave", "request"), gateway.CreateLeaveday)
+
+	pets := api.Group("/pets", jwt)
+	pets.Post("/", can("pet", "create_own"), gateway.CreatePet)
+	pets.Get("/owner", can("pet", "list_own"), gateway.FindByOwnerID)
+	pets.Get("/:petID/medical-records", can("pet_record", "read"), gateway.GetPetMedicalRecords)
+	pets.Get("/:petID/timeline", can("pet_record", "read"), gateway.GetPetTimeline)
+	pets.Get("/:petID/vaccinations", can("pet_record", "read"), gateway.GetPetVaccinations)
+	pets.Get("/:petID/weights", can("pet_record", "read"), gateway.GetPetWeights)
+	pets.Post("/:petID/vaccinations", can("vaccination", "create"), gateway.CreateVaccination)
+	pets.Get("/:petID/attachments", can("pet_record", "read"), gateway.GetPetAttachments)
+	pets.Post("/:petID/attachments", can("pet_record", "write"), gateway.UploadPetAttachment)
+	pets.Delete("/:petID/attachments/:attachmentID", can("pet_record", "write"), gateway.DeletePetAttachment)
+	pets.Patch("/:petID", can("pet", "update", gateway.ownsPet("petID")), gateway.UpdatePet)
+	pets.Delete("/:petID", can("pet", "delete", gateway.ownsPet("petID")), gateway.DeletePet)
+
+	payment := api.Group("/payments", jwt)
+	payment.Get("/", can("payment", "list"), gateway.GetMyPayment)
+	payment.Patch("/:paymentID", can("payment", "update"), gateway.UpdatePaymentByID)
+
+	pricing := api.Group("/pricing", jwt)
+	managePricing := can("pricing", "manage")
+	pricing.Get("/", can("pricing", "read"), gateway.GetPricing)
+	pricing.Put("/rate-cards/:serviceType", managePricing, gateway.UpsertRateCard)
+	pricing.Delete("/rate-cards/:serviceType", managePricing, gateway.DeleteRateCard)
+	pricing.Put("/staff-rates/:staffID", managePricing, gateway.UpsertStaffRate)
+	pricing.Delete("/staff-rates/:staffID", managePricing, gateway.DeleteStaffRate)
+	pricing.Post("/pet-surcharges", managePricing, gateway.CreatePetSurcharge)
+	pricing.Put("/pet-surcharges/:surchargeID", managePricing, gateway.UpdatePetSurcharge)
+	pricing.Delete("/pet-surcharges/:surchargeID", managePricing, gateway.DeletePetSurcharge)
+	pricing.Post("/multipliers", managePricing, gateway.CreateMultiplier)
+	pricing.Put("/multipliers/:multiplierID", managePricing, gateway.UpdateMultiplier)
+	pricing.Delete("/multipliers/:multiplierID", managePricing, gateway.DeleteMultiplier)
+	pricing.Put("/holidays/:date", managePricing, gateway.UpsertHoliday)
+	pricing.Delete("/holidays/:date", managePricing, gateway.DeleteHoliday)
 
 	stripe := api.Group("/stripe")
 	stripe.Post("/service", gateway.StripeWebhookService)
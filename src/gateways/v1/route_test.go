@@ -0,0 +1,226 @@
+package gateways
+
+import (
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"lama-backend/domain/entities"
+	"lama-backend/src/middlewares"
+	service "lama-backend/src/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+)
+
+// fake service ที่ test นี้ต้องใช้จริงมีแค่ตัวที่ hook เรียก ที่เหลือปล่อย nil ให้ panic
+// แล้ว recover ตอบ 500 ซึ่งนับว่าผ่านด่านสิทธิ์มาแล้ว
+
+type fakeAuth struct{ service.IAuthService }
+
+func (fakeAuth) CheckSession(*middlewares.TokenDetails) error { return nil }
+
+type fakePets struct{ service.IPetService }
+
+func (fakePets) FindPetByID(petID string) (*entities.PetDataModel, error) {
+	return &entities.PetDataModel{PetID: petID, OwnerID: "user-1"}, nil
+}
+
+type fakeServices struct{ service.IServiceService }
+
+func (fakeServices) FindServiceByID(serviceID string) (*entities.ServiceModel, error) {
+	return &entities.ServiceModel{Sid: serviceID, OwnerID: "user-1", StaffID: "user-1"}, nil
+}
+
+func newRouteTestApp(t *testing.T) *fiber.App {
+	t.Setenv("JWT_SECRET_KEY", "route-test-secret")
+
+	app := fiber.New()
+	app.Use(recover.New())
+	GatewayUsers(HTTPGateway{
+		AuthService:    fakeAuth{},
+		PetService:     fakePets{},
+		ServiceService: fakeServices{},
+		Validator:      validator.New(),
+	}, app)
+	return app
+}
+
+func requestAs(t *testing.T, app *fiber.App, userID, role, purpose, method, path string) int {
+	t.Helper()
+	token, err := middlewares.GenerateJWTToken(userID, role, purpose, "sess-1")
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	req := httptest.NewRequest(method, "/api/v1"+path, nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+*token.Token)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	return resp.StatusCode
+}
+
+func TestRoutes_AccessMatrix(t *testing.T) {
+	app := newRouteTestApp(t)
+
+	const (
+		all   = "admin owner doctor caretaker"
+		staff = "doctor caretaker"
+	)
+	routes := []struct {
+		method  string
+		path    string
+		allowed string
+	}{
+		{"GET", "/auth/token", all},
+		{"POST", "/auth/logout", all},
+		{"POST", "/auth/logout/all", all},
+		{"POST", "/auth/switch-role/caretaker", all},
+		{"POST", "/auth/admin", "admin"},
+		{"GET", "/auth/mfa", all},
+		{"POST", "/auth/mfa/setup", all},
+		{"POST", "/auth/mfa/enable", all},
+		{"POST", "/auth/mfa/disable", all},
+		{"POST", "/auth/mfa/recovery-codes", all},
+
+		{"GET", "/user", all},
+		{"PATCH", "/user", all},
+		{"PATCH", "/user/profile", all},
+		{"DELETE", "/user", all},
+		{"POST", "/user/roles/caretaker", all},
+
+		{"GET", "/admin/users", "admin"},
+		{"GET", "/admin/users/user-2", "admin"},
+		{"PATCH", "/admin/users/user-2", "admin"},
+		{"DELETE", "/admin/users/user-2", "admin"},
+		{"POST", "/admin/users/user-2/roles/doctor", "admin"},
+		{"DELETE", "/admin/users/user-2/mfa", "admin"},
+		{"GET", "/admin/mfa/roles", "admin"},
+		{"PUT", "/admin/mfa/roles/doctor", "admin"},
+		{"DELETE", "/admin/mfa/roles/doctor", "admin"},
+		{"GET", "/admin/owners/user-2/pets", "admin"},
+		{"POST", "/admin/owners/user-2/pets", "admin"},
+		{"POST", "/admin/staff/user-2/reassign", "admin"},
+		{"GET", "/admin/reassignments", "admin"},
+		{"PATCH", "/admin/reassignments/r-1", "admin"},
+		{"GET", "/admin/vaccinations/due", "admin"},
+
+		{"POST", "/services", "admin owner"},
+		{"GET", "/services", all},
+		{"GET", "/services/quote", "admin owner"},
+		{"PATCH", "/services/svc-1", "admin"},
+		{"DELETE", "/services/svc-1", "admin owner"},
+		{"POST", "/services/svc-1/reschedule", "admin owner"},
+		{"GET", "/services/staff", "admin owner"},
+		{"GET", "/services/staff/user-2/time", "admin owner"},
+		{"GET", "/services/staff/user-2/slots", all},
+		{"GET", "/services/staff/score", "admin owner caretaker"},
+		{"GET", "/services/staff/user-1/score", "admin owner caretaker"},
+		{"GET", "/services/svc-1/history", all},
+		{"GET", "/services/svc-1/reassignments", all},
+		{"GET", "/services/svc-1/medical-record", "admin owner doctor"},
+		{"PUT", "/services/svc-1/medical-record", "doctor"},
+		{"GET", "/services/svc-1/attachments", "admin owner doctor"},
+		{"POST", "/services/svc-1/attachments", "admin owner doctor"},
+		{"PATCH", "/services/review/svc-1", "owner"},
+		{"PATCH", "/services/svc-1/finish", "admin " + staff},
+
+		{"GET", "/schedule/user-2", all},
+		{"PUT", "/schedule/user-1", "admin " + staff},
+		{"GET", "/schedule/user-1/exceptions", "admin " + staff},
+		{"POST", "/schedule/user-1/exceptions", "admin " + staff},
+		{"DELETE", "/schedule/user-1/exceptions/ex-1", "admin " + staff},
+
+		{"POST", "/leaveday", staff},
+		{"GET", "/leaveday", staff},
+		{"GET", "/leaveday/pending", "admin"},
+		{"GET", "/leaveday/day/2026-01-01", "admin"},
+		{"DELETE", "/leaveday/requests/l-1", "admin " + staff},
+		{"PATCH", "/leaveday/requests/l-1/approve", "admin"},
+		{"PATCH", "/leaveday/requests/l-1/reject", "admin"},
+		{"POST", "/leaveday/2026-01-01", staff},
+
+		{"POST", "/pets", "owner"},
+		{"GET", "/pets/owner", "owner"},
+		{"GET", "/pets/pet-1/medical-records", "admin owner doctor"},
+		{"GET", "/pets/pet-1/timeline", "admin owner doctor"},
+		{"GET", "/pets/pet-1/vaccinations", "admin owner doctor"},
+		{"GET", "/pets/pet-1/weights", "admin owner doctor"},
+		{"POST", "/pets/pet-1/vaccinations", "admin doctor"},
+		{"GET", "/pets/pet-1/attachments", "admin owner doctor"},
+		{"POST", "/pets/pet-1/attachments", "admin owner doctor"},
+		{"DELETE", "/pets/pet-1/attachments/a-1", "admin owner doctor"},
+		{"PATCH", "/pets/pet-1", "admin owner"},
+		{"DELETE", "/pets/pet-1", "admin owner"},
+
+		{"GET", "/payments", "admin owner"},
+		{"PATCH", "/payments/pay-1", "admin"},
+
+		{"GET", "/pricing", "admin"},
+		{"PUT", "/pricing/rate-cards/cservice", "admin"},
+		{"DELETE", "/pricing/rate-cards/cservice", "admin"},
+		{"PUT", "/pricing/staff-rates/user-2", "admin"},
+		{"DELETE", "/pricing/staff-rates/user-2", "admin"},
+		{"POST", "/pricing/pet-surcharges", "admin"},
+		{"PUT", "/pricing/pet-surcharges/s-1", "admin"},
+		{"DELETE", "/pricing/pet-surcharges/s-1", "admin"},
+		{"POST", "/pricing/multipliers", "admin"},
+		{"PUT", "/pricing/multipliers/m-1", "admin"},
+		{"DELETE", "/pricing/multipliers/m-1", "admin"},
+		{"PUT", "/pricing/holidays/2026-01-01", "admin"},
+		{"DELETE", "/pricing/holidays/2026-01-01", "admin"},
+	}
+
+	for _, route := range routes {
+		allowed := strings.Fields(route.allowed)
+		for _, role := range strings.Fields(all) {
+			status := requestAs(t, app, "user-1", role, "access", route.method, route.path)
+			if slices.Contains(allowed, role) {
+				if status == fiber.StatusUnauthorized || status == fiber.StatusForbidden {
+					t.Errorf("%s %s as %s: want allowed, got %d", route.method, route.path, role, status)
+				}
+			} else if status != fiber.StatusForbidden {
+				t.Errorf("%s %s as %s: want 403, got %d", route.method, route.path, role, status)
+			}
+		}
+	}
+}
+
+func TestRoutes_OwnershipHooks(t *testing.T) {
+	app := newRouteTestApp(t)
+
+	// pet-1 และ svc-1 ของ fake เป็นของ user-1
+	tests := []struct {
+		name   string
+		userID string
+		role   string
+		method string
+		path   string
+		want   int
+	}{
+		{"another owner's pet", "user-2", "owner", "PATCH", "/pets/pet-1", fiber.StatusForbidden},
+		{"another owner deletes the pet", "user-2", "owner", "DELETE", "/pets/pet-1", fiber.StatusForbidden},
+		{"another owner's booking", "user-2", "owner", "DELETE", "/services/svc-1", fiber.StatusForbidden},
+		{"staff not assigned to the booking", "user-2", "caretaker", "GET", "/services/svc-1/history", fiber.StatusForbidden},
+		{"doctor not assigned reads the medical record", "user-2", "doctor", "GET", "/services/svc-1/medical-record", fiber.StatusForbidden},
+		{"doctor edits someone else's schedule", "user-2", "doctor", "PUT", "/schedule/user-1", fiber.StatusForbidden},
+		{"caretaker reads someone else's score", "user-2", "caretaker", "GET", "/services/staff/user-1/score", fiber.StatusForbidden},
+		{"reset password token is not an access token", "user-1", "owner", "GET", "/user", fiber.StatusUnauthorized},
+		{"reset password token cannot finish a 2FA login", "user-1", "owner", "POST", "/auth/mfa/verify", fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			purpose := "access"
+			if tt.want == fiber.StatusUnauthorized {
+				purpose = "reset_password"
+			}
+			if got := requestAs(t, app, tt.userID, tt.role, purpose, tt.method, tt.path); got != tt.want {
+				t.Fatalf("want %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
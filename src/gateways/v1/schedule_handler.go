@@ -0,0 +1,211 @@
+package gateways
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/middlewares"
+	service "lama-backend/src/services"
+	"lama-backend/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary      Get staff working schedule
+// @Description  Weekly working hours of a caretaker or doctor (weekday 0 = Sunday). source is weekly when a schedule was set, profile when start/end_working_time is used for every day, default for 08:00-17:00. end_time 00:00 means midnight. One-off changes are listed by GET /schedule/{staffID}/exceptions.
+// @Tags         schedule
+// @Produce      json
+// @Security     BearerAuth
+// @Param        staffID       path  string true   "StaffID"
+// @Success      200 {object} entities.ResponseModel  "Request successful"
+// @Failure      400 {object} entities.ResponseMessage "Invalid staff ID"
+// @Failure      401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure      500 {object} entities.ResponseMessage "Internal server error"
+// @Router       /schedule/{staffID} [get]
+func (h *HTTPGateway) GetStaffSchedule(ctx *fiber.Ctx) error {
+	staffID := ctx.Params("staffID")
+	if err := h.Validator.Var(staffID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid staff ID"})
+	}
+
+	schedule, err := h.ServiceService.FindStaffSchedule(staffID)
+	if err != nil {
+		return scheduleErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    schedule,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary      Set staff working schedule
+// @Description  Replace the weekly working hours of a caretaker or doctor. Several shifts on the same weekday are allowed (split shifts) but must not overlap; end_time 00:00 means midnight. An empty list goes back to start/end_working_time of the profile. Staff can only set their own schedule, admins anyone's.
+// @Tags         schedule
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        staffID       path  string true   "StaffID"
+// @Param        body body entities.UpdateStaffScheduleRequest true "weekly shifts"
+// @Success      200 {object} entities.ResponseModel  "Request successful"
+// @Failure      400 {object} entities.ResponseMessage "Invalid request or overlapping shifts"
+// @Failure      401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure      403 {object} entities.ResponseMessage "Invalid role"
+// @Failure      404 {object} entities.ResponseMessage "Staff not found"
+// @Failure      422 {object} entities.ResponseMessage "Validation error"
+// @Failure      500 {object} entities.ResponseMessage "Internal server error"
+// @Router       /schedule/{staffID} [put]
+func (h *HTTPGateway) UpdateStaffSchedule(ctx *fiber.Ctx) error {
+	staffID := ctx.Params("staffID")
+	if err := h.Validator.Var(staffID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid staff ID"})
+	}
+
+	var req entities.UpdateStaffScheduleRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	schedule, err := h.ServiceService.UpdateStaffSchedule(staffID, req)
+	if err != nil {
+		return scheduleErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "schedule updated",
+		Data:    schedule,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary      List schedule exceptions
+// @Description  Extra shifts and unavailable periods of a staff that overlap startDate..endDate (at most 92 days). Staff see their own, admins anyone's.
+// @Tags         schedule
+// @Produce      json
+// @Security     BearerAuth
+// @Param        staffID       path  string true   "StaffID"
+// @Param        startDate     query string true   "first day (format: YYYY-MM-DD)"
+// @Param        endDate       query string true   "last day (format: YYYY-MM-DD)"
+// @Success      200 {object} entities.ResponseModel  "Request successful"
+// @Failure      400 {object} entities.ResponseMessage "Invalid request"
+// @Failure      401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure      403 {object} entities.ResponseMessage "Invalid role"
+// @Failure      500 {object} entities.ResponseMessage "Internal server error"
+// @Router       /schedule/{staffID}/exceptions [get]
+func (h *HTTPGateway) GetScheduleExceptions(ctx *fiber.Ctx) error {
+	staffID := ctx.Params("staffID")
+	if err := h.Validator.Var(staffID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid staff ID"})
+	}
+
+	from, to, err := utils.GetRDateRange(ctx.Query("startDate"), ctx.Query("endDate"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{
+			Message: "invalid date or date format, expected YYYY-MM-DD",
+		})
+	}
+	if to.Before(from) || to.Sub(from) > 92*24*time.Hour {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{
+			Message: "endDate must be within 92 days after startDate",
+		})
+	}
+
+	exceptions, err := h.ServiceService.FindScheduleExceptions(staffID, from, to)
+	if err != nil {
+		return scheduleErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    exceptions,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary      Add schedule exception
+// @Description  Add a one-off extra shift (kind extra) or a partial-day unavailable period (kind unavailable). Extra shifts can be booked like normal working hours, also on a day off. Unavailable periods block new bookings and checkouts like a leave day does, but only for the given time. Existing bookings are not touched. Staff add their own, admins anyone's.
+// @Tags         schedule
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        staffID       path  string true   "StaffID"
+// @Param        body body entities.CreateScheduleExceptionRequest true "exception"
+// @Success      201 {object} entities.ResponseModel  "Created"
+// @Failure      400 {object} entities.ResponseMessage "Invalid request"
+// @Failure      401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure      403 {object} entities.ResponseMessage "Invalid role"
+// @Failure      404 {object} entities.ResponseMessage "Staff not found"
+// @Failure      422 {object} entities.ResponseMessage "Validation error"
+// @Failure      500 {object} entities.ResponseMessage "Internal server error"
+// @Router       /schedule/{staffID}/exceptions [post]
+func (h *HTTPGateway) CreateScheduleException(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	staffID := ctx.Params("staffID")
+	if err := h.Validator.Var(staffID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid staff ID"})
+	}
+
+	var req entities.CreateScheduleExceptionRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	exception, err := h.ServiceService.CreateScheduleException(staffID, token.UserID, req)
+	if err != nil {
+		return scheduleErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{
+		Message: "schedule exception created",
+		Data:    exception,
+		Status:  fiber.StatusCreated,
+	})
+}
+
+// @Summary      Delete schedule exception
+// @Description  Remove an extra shift or unavailable period. Staff remove their own, admins anyone's.
+// @Tags         schedule
+// @Produce      json
+// @Security     BearerAuth
+// @Param        staffID       path  string true   "StaffID"
+// @Param        exceptionID   path  string true   "Exception ID"
+// @Success      200 {object} entities.ResponseMessage "Request successful"
+// @Failure      400 {object} entities.ResponseMessage "Invalid request"
+// @Failure      401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure      403 {object} entities.ResponseMessage "Invalid role"
+// @Failure      404 {object} entities.ResponseMessage "Exception not found"
+// @Failure      500 {object} entities.ResponseMessage "Internal server error"
+// @Router       /schedule/{staffID}/exceptions/{exceptionID} [delete]
+func (h *HTTPGateway) DeleteScheduleException(ctx *fiber.Ctx) error {
+	staffID := ctx.Params("staffID")
+	exceptionID := ctx.Params("exceptionID")
+	if h.Validator.Var(staffID, "uuid") != nil || h.Validator.Var(exceptionID, "uuid") != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid staff or exception ID"})
+	}
+
+	if err := h.ServiceService.DeleteScheduleException(staffID, exceptionID); err != nil {
+		return scheduleErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseMessage{Message: "schedule exception deleted"})
+}
+
+func scheduleErrorResponse(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidSchedule):
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, db.ErrNotFound):
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "schedule exception not found"})
+	// users -> FindByID ไม่ได้ wrap ErrNotFound มา
+	case errors.Is(err, service.ErrNotStaff), strings.Contains(err.Error(), db.ErrNotFound.Error()):
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "staff not found"})
+	default:
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+}
@@ -8,14 +8,16 @@ import (
 	"lama-backend/domain/entities"
 	"lama-backend/domain/prisma/db"
 	"lama-backend/src/middlewares"
+	service "lama-backend/src/services"
 	"lama-backend/src/utils"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/stripe/stripe-go/v76"
 )
 
 // @Summary Get stripe payment link to Create caretaker/medical service
-// @Description Owners create their own bookings; admins may create on behalf of an owner by providing owner_id. Use service_type=cservice (caretaker) or mservice (doctor) and supply staff_id plus type-specific fields. this route then create payment and send those to stripe to get payment link.
+// @Description Owners create their own bookings; admins may create on behalf of an owner by providing owner_id. Use service_type=cservice (caretaker) or mservice (doctor) and supply staff_id plus type-specific fields. this route then create payment (priced from rate cards, staff rates, pet surcharges and time multipliers) and send its line items to stripe to get payment link.
 // @Tags service
 // @Accept json
 // @Produce json
@@ -24,18 +26,13 @@ import (
 // @Failure 400 {object} entities.ResponseMessage "Invalid json body"
 // @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
 // @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 409 {object} entities.ResponseMessage "Staff is not available in this time range (booked, on leave or outside working hours)"
 // @Failure 422 {object} entities.ResponseMessage "Validation error"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
 // @Router /services [post]
 // @Security BearerAuth
 func (h *HTTPGateway) CreateServiceStripe(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "owner" && token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
+	token := middlewares.AccessToken(ctx)
 
 	var req entities.CreateServiceRequest
 	if err := ctx.BodyParser(&req); err != nil {
@@ -70,8 +67,11 @@ func (h *HTTPGateway) CreateServiceStripe(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "Reservation end date must be after the start date (hour-based)."})
 	}
 
-	payment, err := h.PaymentService.InsertPayment(req.OwnerID, req.ReserveDateEnd, req.ReserveDateStart)
+	payment, err := h.PaymentService.InsertPayment(req)
 	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "pet not found"})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
 			Message: "cannot create payment: " + err.Error(),
 		})
@@ -79,13 +79,26 @@ func (h *HTTPGateway) CreateServiceStripe(ctx *fiber.Ctx) error {
 	req.PaymentID = payment.PayID
 
 	if err := h.ServiceService.ValidateServiceCreation(req, "unpaid"); err != nil {
+		if errors.Is(err, service.ErrStaffUnavailable) {
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
 		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{
 			Message: err.Error(),
 		})
 	}
 
-	stripe_link, err := h.PaymentService.StripeCreatePrice(&req, payment.Price)
+	// จองช่วงเวลาของ staff ไว้จนกว่า checkout session จะหมดอายุ
+	expiresAt, err := h.ServiceService.HoldSlot(req)
+	if err != nil {
+		if errors.Is(err, service.ErrStaffUnavailable) {
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+
+	stripe_link, err := h.PaymentService.StripeCreatePrice(&req, payment, expiresAt)
 	if err != nil {
+		_ = h.ServiceService.ReleaseHold(payment.PayID)
 		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseModel{Message: "Error to get link"})
 	}
 
@@ -99,6 +112,81 @@ func (h *HTTPGateway) CreateServiceStripe(ctx *fiber.Ctx) error {
 	})
 }
 
+// @Summary Quote a service booking
+// @Description Price a booking before checkout and check whether the staff is still free and working for the whole time range. Takes the same fields as POST /services as query params. Nothing is written, no payment or stripe session is created.
+// @Tags service
+// @Produce json
+// @Param owner_id           query string false "Owner ID (admin only)"
+// @Param pet_id             query string true  "Pet ID"
+// @Param staff_id           query string true  "Staff ID"
+// @Param service_type       query string true  "Service type (cservice or mservice)"
+// @Param reserve_date_start query string true  "Reservation start (RFC3339)"
+// @Param reserve_date_end   query string true  "Reservation end (RFC3339)"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid query"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /services/quote [get]
+// @Security BearerAuth
+func (h *HTTPGateway) QuoteService(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	req := entities.CreateServiceRequest{
+		OwnerID:     ctx.Query("owner_id"),
+		PetID:       ctx.Query("pet_id"),
+		StaffID:     ctx.Query("staff_id"),
+		ServiceType: strings.ToLower(strings.TrimSpace(ctx.Query("service_type"))),
+	}
+	switch token.Role {
+	case "owner":
+		req.OwnerID = token.UserID
+	case "admin":
+		if req.OwnerID == "" {
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "owner_id is required for admin"})
+		}
+	}
+
+	var err error
+	req.ReserveDateStart, err = time.Parse(time.RFC3339, ctx.Query("reserve_date_start"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid reserve_date_start, expected RFC3339"})
+	}
+	req.ReserveDateEnd, err = time.Parse(time.RFC3339, ctx.Query("reserve_date_end"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid reserve_date_end, expected RFC3339"})
+	}
+
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+	req.ReserveDateEnd = req.ReserveDateEnd.Truncate(time.Hour)
+	req.ReserveDateStart = req.ReserveDateStart.Truncate(time.Hour)
+	if !req.ReserveDateStart.Before(req.ReserveDateEnd) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "Reservation end date must be after the start date (hour-based)."})
+	}
+
+	quote, err := h.PaymentService.QuotePrice(req)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "pet not found"})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+
+	quote.Available, err = h.ServiceService.CheckAvailability(req)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    quote,
+		Status:  fiber.StatusOK,
+	})
+}
+
 // @Summary Update service booking
 // @Description Admin-only endpoint for adjusting service data. Provide the fields that need to change.
 // @Tags service
@@ -111,18 +199,13 @@ func (h *HTTPGateway) CreateServiceStripe(ctx *fiber.Ctx) error {
 // @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
 // @Failure 403 {object} entities.ResponseMessage "Invalid role"
 // @Failure 404 {object} entities.ResponseMessage "Service not found"
+// @Failure 409 {object} entities.ResponseMessage "Staff is not available or status transition not allowed"
 // @Failure 422 {object} entities.ResponseMessage "Validation error"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
 // @Router /services/{serviceID} [patch]
 // @Security BearerAuth
 func (h *HTTPGateway) UpdateService(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
+	token := middlewares.AccessToken(ctx)
 
 	serviceID := ctx.Params("serviceID")
 	if serviceID == "" {
@@ -168,11 +251,15 @@ func (h *HTTPGateway) UpdateService(ctx *fiber.Ctx) error {
 		})
 	}
 
-	updatedService, err := h.ServiceService.UpdateServiceByID(serviceID, req)
+	updatedService, err := h.ServiceService.UpdateServiceByID(serviceID, token.UserID, req)
 	if err != nil {
 		switch {
 		case errors.Is(err, db.ErrNotFound):
 			return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "service not found"})
+		case errors.Is(err, service.ErrStaffUnavailable),
+			errors.Is(err, service.ErrInvalidStatusTransition),
+			errors.Is(err, service.ErrStatusChanged):
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
 		case strings.Contains(strings.ToLower(err.Error()), "invalid"),
 			strings.Contains(strings.ToLower(err.Error()), "required"):
 			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
@@ -188,70 +275,227 @@ func (h *HTTPGateway) UpdateService(ctx *fiber.Ctx) error {
 	})
 }
 
-// @Summary delete service booking
-// @Description owner deletes their service booking by ID
+// @Summary cancel service booking
+// @Description owner or admin cancels a booking. Paid bookings are refunded on Stripe by the refund policy: full refund when cancelled at least REFUND_FULL_BEFORE_HOURS before the start, REFUND_LATE_PERCENT after that, nothing once the service is ongoing. Admins can force a full refund.
 // @Tags service
 // @Accept json
 // @Produce json
-// @Param id path string true "Service ID"
-// @Success 200 {object} entities.ResponseMessage "Delete successful"
+// @Param serviceID path string true "Service ID"
+// @Param request body entities.CancelServiceRequest false "Cancellation reason"
+// @Success 200 {object} entities.ResponseModel "Cancel successful"
 // @Failure 400 {object} entities.ResponseMessage "Invalid service ID"
 // @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
 // @Failure 403 {object} entities.ResponseMessage "Invalid role"
 // @Failure 404 {object} entities.ResponseMessage "Service not found"
+// @Failure 409 {object} entities.ResponseMessage "Service can no longer be cancelled"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Failure 502 {object} entities.ResponseMessage "Stripe refund failed"
 // @Router /services/{serviceID} [delete]
 // @Security BearerAuth
 func (h *HTTPGateway) DeleteService(ctx *fiber.Ctx) error {
-	// Decode JWT token
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{
-			Message: "Unauthorized token",
+	token := middlewares.AccessToken(ctx)
+	// serviceParty โหลด booking และเช็คเจ้าของมาแล้ว
+	existing := bookedService(ctx)
+	serviceID := existing.Sid
+
+	// body เป็น optional ของเดิมเรียก DELETE เปล่าๆ
+	var req entities.CancelServiceRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+		}
+	}
+	if req.FullRefund && token.Role != "admin" {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "only admin can force a full refund"})
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{
+			Message: utils.FormatValidationError(err),
 		})
 	}
 
-	// Only owner or admin can proceed
-	if token.Role != "owner" && token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{
-			Message: "Invalid role",
+	cancellation, err := h.ServiceService.PlanCancellation(existing, token.UserID, token.Role, req)
+	if err != nil {
+		if errors.Is(err, service.ErrServiceNotCancellable) {
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
+			Message: "cannot cancel service: " + err.Error(),
 		})
 	}
 
-	// Get service ID from path
-	serviceID := ctx.Params("serviceID")
-	if serviceID == "" {
-		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{
-			Message: "invalid service ID",
-		})
+	// ยอดถูกบันทึกไว้ตอน plan แล้ว ถ้า stripe หรือ commit ล้ม ลองใหม่ได้ด้วย key และยอดเดิม
+	if cancellation.RefundAmount > 0 {
+		refunds, err := h.PaymentService.RefundPayment(cancellation.PaymentID, cancellation.RefundAmount, "cancel-"+serviceID)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadGateway).JSON(entities.ResponseMessage{
+				Message: "cannot refund payment: " + err.Error(),
+			})
+		}
+		cancellation.Refunds = refunds
 	}
 
-	// Fetch the service first to check ownership
-	service, err := h.ServiceService.FindServiceByID(serviceID)
+	cancelled, err := h.ServiceService.CancelService(cancellation)
 	if err != nil {
-		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{
-			Message: "service not found",
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
+			Message: "cannot cancel service: " + err.Error(),
 		})
 	}
 
-	// Check ownership for owner role
-	if token.Role == "owner" && service.OwnerID != token.UserID {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{
-			Message: "You do not own this service",
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "service cancelled",
+		Data:    fiber.Map{"service": cancelled, "cancellation": cancellation},
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary reschedule service booking
+// @Description owner or admin moves a waiting booking to a new time range. The new range goes through the same staff availability check as a new booking and is priced with the current rules. If it costs more, an adjustment payment linked to the booking's payment is created and the booking moves once it is paid (stripe_link is returned). If it costs less, the reschedule is saved before the difference is refunded on Stripe and the booking moves right away; if that fails, retrying the same range reuses the saved refund instead of refunding again.
+// @Tags service
+// @Accept json
+// @Produce json
+// @Param serviceID path string true "Service ID"
+// @Param request body entities.RescheduleServiceRequest true "New time range"
+// @Success 200 {object} entities.ResponseModel "Rescheduled"
+// @Success 202 {object} entities.ResponseModel "Waiting for the extra payment"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Service not found"
+// @Failure 409 {object} entities.ResponseMessage "Staff is not available, service can't be rescheduled or another reschedule is in progress"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Failure 502 {object} entities.ResponseMessage "Stripe refund failed"
+// @Router /services/{serviceID}/reschedule [post]
+// @Security BearerAuth
+func (h *HTTPGateway) RescheduleService(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+	existing := bookedService(ctx)
+
+	var req entities.RescheduleServiceRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{
+			Message: utils.FormatValidationError(err),
 		})
 	}
+	req.ReserveDateStart = req.ReserveDateStart.Truncate(time.Hour)
+	req.ReserveDateEnd = req.ReserveDateEnd.Truncate(time.Hour)
+	if !req.ReserveDateStart.Before(req.ReserveDateEnd) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "Reservation end date must be after the start date (hour-based)."})
+	}
+	if !req.ReserveDateStart.After(time.Now()) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "Reservation start date must be in the future."})
+	}
 
-	// Delete the service
-	deletedService, err := h.ServiceService.DeleteServiceByID(serviceID)
+	reschedule, err := h.ServiceService.PlanReschedule(existing, token.UserID, req)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
-			Message: "cannot delete service: " + err.Error(),
+		if errors.Is(err, service.ErrStaffUnavailable) || errors.Is(err, service.ErrServiceNotReschedulable) || errors.Is(err, service.ErrReschedulePending) {
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	// แถวที่บันทึกไว้แล้ว (คืนเงินรอบก่อนไม่จบ) ใช้ราคาเดิม stripe จะได้ยอดเดิมกับ key เดิม
+	saved := !reschedule.CreatedAt.IsZero()
+	if !saved {
+		if err := h.PaymentService.PriceReschedule(existing, reschedule); err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot price reschedule: " + err.Error()})
+		}
+	}
+
+	if reschedule.PriceDelta > 0 {
+		payment, err := h.PaymentService.InsertAdjustment(reschedule)
+		if err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot create payment: " + err.Error()})
+		}
+		reschedule.AdjustmentPaymentID = &payment.PayID
+
+		// รอจ่ายส่วนต่าง ถือ slot ใหม่ในชื่อ payment ส่วนต่าง ไม่ปนกับ reschedule อื่นของ booking
+		// ถ้าถือไม่ได้ payment ส่วนต่างที่ยังไม่จ่ายจะหมดอายุไปเองตาม job
+		expiresAt, err := h.ServiceService.HoldSlot(entities.CreateServiceRequest{
+			PaymentID:        payment.PayID,
+			StaffID:          existing.StaffID,
+			ReserveDateStart: reschedule.NewStart,
+			ReserveDateEnd:   reschedule.NewEnd,
+		})
+		if err != nil {
+			if errors.Is(err, service.ErrStaffUnavailable) {
+				return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+			}
+			return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		if err := h.ServiceService.RequestReschedule(reschedule); err != nil {
+			_ = h.ServiceService.ReleaseHold(payment.PayID)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+
+		stripe_link, err := h.PaymentService.StripeCreateAdjustmentCheckout(reschedule, payment, expiresAt)
+		if err != nil {
+			_ = h.ServiceService.AbandonReschedule(reschedule.ID)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "Error to get link"})
+		}
+
+		return ctx.Status(fiber.StatusAccepted).JSON(entities.ResponseModel{
+			Message: "reschedule waiting for payment",
+			Data: fiber.Map{
+				"reschedule":  reschedule,
+				"payment_id":  payment.PayID,
+				"stripe_link": stripe_link,
+			},
+			Status: fiber.StatusAccepted,
 		})
 	}
 
+	// ไม่ต้องจ่ายเพิ่ม ถือ slot ใหม่ในชื่อ payment ของ booking แค่ระหว่างคืนเงินใน request นี้
+	if _, err := h.ServiceService.HoldSlot(entities.CreateServiceRequest{
+		PaymentID:        existing.PaymentID,
+		StaffID:          existing.StaffID,
+		ReserveDateStart: reschedule.NewStart,
+		ReserveDateEnd:   reschedule.NewEnd,
+	}); err != nil {
+		if errors.Is(err, service.ErrStaffUnavailable) {
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+
+	if reschedule.PriceDelta < 0 {
+		// บันทึก reschedule ก่อนเงินออก key คืนเงินผูกกับแถวนี้ ลองใหม่กี่รอบก็ไม่คืนซ้ำ
+		if !saved {
+			if err := h.ServiceService.RequestReschedule(reschedule); err != nil {
+				_ = h.ServiceService.ReleaseHold(existing.PaymentID)
+				return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+			}
+		}
+
+		refunds, err := h.PaymentService.RefundPayment(existing.PaymentID, -reschedule.PriceDelta, "reschedule-"+reschedule.ID)
+		if err != nil {
+			// stripe ปฏิเสธตั้งแต่ก้อนแรก ไม่มีเงินออก ยกเลิกแถวได้เลย
+			// ถ้าคืนไปบางก้อนแล้วหรือไม่รู้ผล เก็บแถวไว้ให้ลองช่วงเดิมใหม่ด้วย key เดิม
+			var stripeErr *stripe.Error
+			if len(refunds) == 0 && errors.As(err, &stripeErr) {
+				_ = h.ServiceService.AbandonReschedule(reschedule.ID)
+			}
+			_ = h.ServiceService.ReleaseHold(existing.PaymentID)
+			return ctx.Status(fiber.StatusBadGateway).JSON(entities.ResponseMessage{Message: "cannot refund payment: " + err.Error()})
+		}
+		reschedule.Refunds = refunds
+	}
+
+	updated, err := h.ServiceService.ApplyReschedule(reschedule)
+	if err != nil {
+		if errors.Is(err, service.ErrStaffUnavailable) || errors.Is(err, service.ErrStatusChanged) {
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
-		Message: "service deleted successfully",
-		Data:    deletedService,
+		Message: "service rescheduled",
+		Data:    fiber.Map{"service": updated, "reschedule": reschedule},
 		Status:  fiber.StatusOK,
 	})
 }
@@ -271,10 +515,7 @@ func (h *HTTPGateway) DeleteService(ctx *fiber.Ctx) error {
 // @Failure      500 {object} entities.ResponseMessage "Internal server error"
 // @Router       /services [get]
 func (h *HTTPGateway) GetMyServices(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
+	token := middlewares.AccessToken(ctx)
 	statusFilter := ctx.Query("status")
 	month := ctx.QueryInt("month")
 	year := ctx.QueryInt("year")
@@ -282,6 +523,7 @@ func (h *HTTPGateway) GetMyServices(ctx *fiber.Ctx) error {
 	limit := ctx.QueryInt("limit", 5)
 	var services []*entities.ServiceModel
 	var total int
+	var err error
 
 	switch token.Role {
 	case "admin":
@@ -309,42 +551,39 @@ func (h *HTTPGateway) GetMyServices(ctx *fiber.Ctx) error {
 }
 
 // @Summary Update service status
-// @Description Update the status of a service booking. Allowed roles: admin, caretaker, doctor.
+// @Description Move a service booking along wait -> ongoing -> finish, or wait -> no_show. Caretakers and doctors can only update their own services, can't start more than 30 minutes before reserve_date_start, and can't mark no_show until 30 minutes after it. Admins may also move ongoing back to wait. Cancelling goes through DELETE /services/{serviceID}. Every change is recorded in the status history.
 // @Tags service
 // @Produce json
 // @Param serviceID path string true "Service ID"
-// @Param status path string true "service status (wait, ongoing, finish)" Enums(wait, ongoing, finish)
+// @Param status path string true "service status" Enums(wait, ongoing, finish, no_show)
 // @Success 200 {object} entities.ResponseModel "Request successful"
 // @Failure 400 {object} entities.ResponseMessage "Invalid request"
 // @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
 // @Failure 403 {object} entities.ResponseMessage "Invalid role"
 // @Failure 404 {object} entities.ResponseMessage "Service not found"
+// @Failure 409 {object} entities.ResponseMessage "Status transition not allowed"
 // @Failure 500 {object} entities.ResponseMessage "Internal server error"
 // @Router /services/{serviceID}/{status} [patch]
 // @Security BearerAuth
 func (h *HTTPGateway) UpdateStatusService(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "admin" && token.Role != "caretaker" && token.Role != "doctor" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
+	token := middlewares.AccessToken(ctx)
 
 	serviceID := ctx.Params("serviceID")
 	if serviceID == "" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid service ID"})
 	}
 	status := ctx.Params("status")
-	if status != "wait" && status != "ongoing" && status != "finish" {
+	if status != "wait" && status != "ongoing" && status != "finish" && status != "no_show" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid status"})
 	}
 
-	err = h.ServiceService.UpdateStatus(serviceID, status, token.Role, token.UserID)
+	err := h.ServiceService.UpdateStatus(serviceID, status, token.Role, token.UserID)
 	if err != nil {
 		switch {
 		case errors.Is(err, db.ErrNotFound):
 			return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "service not found"})
+		case errors.Is(err, service.ErrInvalidStatusTransition), errors.Is(err, service.ErrStatusChanged):
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
 		case strings.Contains(strings.ToLower(err.Error()), "invalid"),
 			strings.Contains(strings.ToLower(err.Error()), "required"):
 			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
@@ -363,6 +602,31 @@ func (h *HTTPGateway) UpdateStatusService(ctx *fiber.Ctx) error {
 	})
 }
 
+// @Summary Get service status history
+// @Description Every status change of a service with who made it and when, oldest first. changed_by is empty for changes made by the system (Stripe refunds and disputes). Owners see their own services, caretakers and doctors the services assigned to them.
+// @Tags service
+// @Produce json
+// @Param serviceID path string true "Service ID"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Service not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /services/{serviceID}/history [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetServiceStatusHistory(ctx *fiber.Ctx) error {
+	history, err := h.ServiceService.FindStatusHistory(bookedService(ctx).Sid)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "Request successful",
+		Data:    history,
+		Status:  fiber.StatusOK,
+	})
+}
+
 // @Summary      Get available staff
 // @Description  Retrieve all staff members available for a specific service type on a given day.
 // @Tags         service
@@ -379,16 +643,6 @@ func (h *HTTPGateway) UpdateStatusService(ctx *fiber.Ctx) error {
 // @Failure      500 {object} entities.ResponseMessage "Internal server error"
 // @Router       /services/staff [get]
 func (h *HTTPGateway) GetAvailableStaff(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "owner" && token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{
-			Message: "Invalid role",
-		})
-	}
-
 	serviceType := ctx.Query("serviceType")
 	serviceMode := ctx.Query("serviceMode")
 	startDateStr := ctx.Query("startDate")
@@ -444,7 +698,7 @@ func (h *HTTPGateway) GetAvailableStaff(ctx *fiber.Ctx) error {
 }
 
 // @Summary      Get busy time slot
-// @Description  Retrieve all busy time slot for a specific staff on a given day.
+// @Description  Retrieve all busy time slot for a specific staff on a given day. Only hours inside the staff's working schedule are listed.
 // @Tags         service
 // @Produce      json
 // @Security     BearerAuth
@@ -459,16 +713,6 @@ func (h *HTTPGateway) GetAvailableStaff(ctx *fiber.Ctx) error {
 // @Failure      500 {object} entities.ResponseMessage "Internal server error"
 // @Router       /services/staff/{staffID}/time [get]
 func (h *HTTPGateway) GetBusyTimeSlot(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "owner" && token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{
-			Message: "Invalid role",
-		})
-	}
-
 	serviceType := ctx.Query("serviceType")
 	startDateStr := ctx.Query("startDate")
 	endDateStr := ctx.Query("endDate")
@@ -504,6 +748,48 @@ func (h *HTTPGateway) GetBusyTimeSlot(ctx *fiber.Ctx) error {
 	})
 }
 
+// @Summary      Get free time slot
+// @Description  Per day from startDate to endDate (at most 31 days): the staff's working ranges from their weekly schedule, the busy ranges (bookings, checkout holds, leave days) and the free ranges left to book. Time in the past is never free. Days are in Thai time (UTC+7).
+// @Tags         service
+// @Produce      json
+// @Security     BearerAuth
+// @Param        startDate     query string true   "first day (format: YYYY-MM-DD)"
+// @Param        endDate       query string true   "last day (format: YYYY-MM-DD)"
+// @Param        staffID       path  string true   "StaffID"
+// @Success      200 {object} entities.ResponseModel  "Request successful"
+// @Failure      400 {object} entities.ResponseMessage "Invalid request"
+// @Failure      401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure      500 {object} entities.ResponseMessage "Internal server error"
+// @Router       /services/staff/{staffID}/slots [get]
+func (h *HTTPGateway) GetFreeTimeSlot(ctx *fiber.Ctx) error {
+	staffID := ctx.Params("staffID")
+	if err := h.Validator.Var(staffID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid staff ID"})
+	}
+
+	from, to, err := utils.GetRDateRange(ctx.Query("startDate"), ctx.Query("endDate"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{
+			Message: "invalid date or date format, expected YYYY-MM-DD",
+		})
+	}
+	if to.Before(from) || to.Sub(from) > 31*24*time.Hour {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{
+			Message: "endDate must be within 31 days after startDate",
+		})
+	}
+
+	res, err := h.ServiceService.FindFreeTimeSlot(staffID, from, to, time.Now())
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "success",
+		Data:    res,
+		Status:  fiber.StatusOK,
+	})
+}
+
 // @Summary      Get score and reviews
 // @Description  Retrieve average score and list of reviews for a caretaker (staff). Owners and admins can view any caretaker; a caretaker may view their own reviews. If `staffID` is omitted and the caller is a caretaker, the handler defaults to the caller's ID.
 // @Tags         service
@@ -517,13 +803,10 @@ func (h *HTTPGateway) GetBusyTimeSlot(ctx *fiber.Ctx) error {
 // @Failure      500 {object} entities.ResponseMessage "Internal server error"
 // @Router       /services/staff/{staffID}/score [get]
 func (h *HTTPGateway) GetScoreAndReview(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
+	token := middlewares.AccessToken(ctx)
 
-	// Allow owners/admins to view any caretaker reviews. A caretaker may view their
-	// own reviews. If a caretaker supplies no staffID param, default to their ID.
+	// Owners/admins can view any caretaker reviews, a caretaker only their own (see the
+	// self hook in route.go). If a caretaker supplies no staffID param, default to their ID.
 	staffID := ctx.Params("staffID")
 
 	if staffID == "" {
@@ -535,16 +818,6 @@ func (h *HTTPGateway) GetScoreAndReview(ctx *fiber.Ctx) error {
 		}
 	}
 
-	// Only allow caretaker to view their own data
-	if token.Role == "caretaker" && token.UserID != staffID {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "forbidden"})
-	}
-
-	// Owners and admins may view any staff. Other roles are forbidden.
-	if token.Role != "owner" && token.Role != "admin" && token.Role != "caretaker" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
-
 	avg, reviews, err := h.ServiceService.GetScoreAndReviewByCaretakerID(staffID)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
@@ -579,18 +852,9 @@ func (h *HTTPGateway) GetScoreAndReview(ctx *fiber.Ctx) error {
 // @Router /services/review/{serviceID} [patch]
 // @Security BearerAuth
 func (h *HTTPGateway) Review(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "owner" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
-
-	serviceID := ctx.Params("serviceID")
-	if serviceID == "" {
-		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid service ID"})
-	}
+	token := middlewares.AccessToken(ctx)
+	svc := bookedService(ctx)
+	serviceID := svc.Sid
 
 	var rreq entities.ReviewRequest
 	if err := ctx.BodyParser(&rreq); err != nil {
@@ -616,21 +880,6 @@ func (h *HTTPGateway) Review(ctx *fiber.Ctx) error {
 		})
 	}
 
-	svc, err := h.ServiceService.FindServiceByID(serviceID)
-	if err != nil {
-		switch {
-		case errors.Is(err, db.ErrNotFound):
-			return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "service not found"})
-		default:
-			return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
-		}
-	}
-
-	// Owner must be the service owner
-	if svc.OwnerID != token.UserID {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "You do not own this service"})
-	}
-
 	// Only caretaker services can be reviewed here
 	if svc.ServiceType != "cservice" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "only cservice can be reviewed"})
@@ -645,7 +894,7 @@ func (h *HTTPGateway) Review(ctx *fiber.Ctx) error {
 	updReq.Comment = rreq.Comment
 	updReq.Score = rreq.Score
 
-	updatedService, err := h.ServiceService.UpdateServiceByID(serviceID, updReq)
+	updatedService, err := h.ServiceService.UpdateServiceByID(serviceID, token.UserID, updReq)
 	if err != nil {
 		switch {
 		case errors.Is(err, db.ErrNotFound):
@@ -3,11 +3,11 @@ package gateways
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"lama-backend/domain/entities"
 	"time"
 
 	"lama-backend/domain/prisma/db"
+	"lama-backend/src/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stripe/stripe-go/v76"
@@ -15,91 +15,159 @@ import (
 
 // for stripe only
 func (h *HTTPGateway) StripeWebhookService(ctx *fiber.Ctx) error {
-	payload := ctx.Body()
-	event := stripe.Event{}
-	err := json.Unmarshal(payload, &event)
+	event, err := h.PaymentService.ConstructWebhookEvent(ctx.Body(), ctx.Get("Stripe-Signature"))
 	if err != nil {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Webhook."})
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "Invalid webhook signature."})
 	}
 
-	// check stripe payment_status
-	if stripeStatus, ok := event.Data.Object["payment_status"]; !ok || stripeStatus.(string) != "paid" {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "stripe payment_status is not paid"})
+	record, claimed, err := h.PaymentService.ClaimWebhookEvent(event)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	if !claimed {
+		// stripe ส่งซ้ำ: ตอบผลเดิมกลับไปโดยไม่ทำงานซ้ำ
+		if record.Status == db.StripeEventStatusProcessed && record.Response != nil {
+			ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return ctx.Status(fiber.StatusOK).SendString(*record.Response)
+		}
+		return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: "event is being processed"})
 	}
-	status := "PAID"
 
-	// get method and paydate from payment_intent
-	payIntent, ok := event.Data.Object["payment_intent"]
-	if !ok {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot get payment_intent from stripe"})
+	status, body := h.processStripeEvent(event)
+	if status != fiber.StatusOK {
+		if err := h.PaymentService.ReleaseWebhookEvent(event.ID); err != nil {
+			return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		return ctx.Status(status).JSON(body)
 	}
-	method, paydate, err := h.PaymentService.GetMethodAndPaydate(payIntent.(string))
+
+	response, err := json.Marshal(body)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot get method or paydate from payment_intent:" + err.Error()})
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	if err := h.PaymentService.CompleteWebhookEvent(event.ID, response); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
 	}
 
-	// get metadata
-	metadataRaw, ok := event.Data.Object["metadata"]
-	if !ok {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot get metadata from stripe"})
+	ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return ctx.Status(fiber.StatusOK).Send(response)
+}
+
+func (h *HTTPGateway) processStripeEvent(event stripe.Event) (int, interface{}) {
+	switch event.Type {
+	case stripe.EventTypeCheckoutSessionCompleted, stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded:
+		return h.stripeCheckoutPaid(event)
+	case stripe.EventTypeCheckoutSessionExpired:
+		return h.stripeCheckoutClosed(event, db.PaymentStatusExpired)
+	case stripe.EventTypeCheckoutSessionAsyncPaymentFailed:
+		return h.stripeCheckoutClosed(event, db.PaymentStatusFailed)
+	case stripe.EventTypePaymentIntentPaymentFailed:
+		return h.stripePaymentFailed(event)
+	case stripe.EventTypeChargeRefunded:
+		return h.stripeChargeRefunded(event)
+	case stripe.EventTypeChargeDisputeCreated:
+		return h.stripeDisputeCreated(event)
+	default:
+		// ตอบ 2xx ไม่งั้น stripe จะ retry event ที่เราไม่ได้ใช้ไปเรื่อยๆ
+		return fiber.StatusOK, entities.ResponseMessage{Message: "event type ignored: " + string(event.Type)}
 	}
-	metadata, ok := metadataRaw.(map[string]interface{})
-	if !ok {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
-			Message: "metadata type assertion failed",
-		})
+}
+
+func (h *HTTPGateway) stripeCheckoutPaid(event stripe.Event) (int, interface{}) {
+	var session stripe.CheckoutSession
+	if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
+		return fiber.StatusBadRequest, entities.ResponseMessage{Message: "cannot parse checkout session: " + err.Error()}
 	}
 
-	// get user_id and payment_id from metadata
+	// async method (promptpay) จะจ่ายเสร็จทีหลังผ่าน checkout.session.async_payment_succeeded
+	if session.PaymentStatus != stripe.CheckoutSessionPaymentStatusPaid {
+		return fiber.StatusOK, entities.ResponseMessage{Message: "checkout session is awaiting payment"}
+	}
+	if session.PaymentIntent == nil {
+		return fiber.StatusBadRequest, entities.ResponseMessage{Message: "cannot get payment_intent from stripe"}
+	}
+
+	metadata := session.Metadata
 	payId, ok := metadata["payment_id"]
 	if !ok {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "metadata not have pay_id"})
+		return fiber.StatusBadRequest, entities.ResponseMessage{Message: "metadata not have pay_id"}
 	}
 
-	// update payment
-	updateData := entities.UpdatePaymentRequest{
-		Status:  &status,
-		Type:    &method,
-		PayDate: &paydate,
+	// get method and paydate from payment_intent
+	method, paydate, err := h.PaymentService.GetMethodAndPaydate(session.PaymentIntent.ID)
+	if err != nil {
+		return fiber.StatusInternalServerError, entities.ResponseMessage{Message: "cannot get method or paydate from payment_intent:" + err.Error()}
 	}
-
-	updatedPayment, err := h.PaymentService.UpdateByID(payId.(string), updateData)
+	payDate, err := time.Parse(time.RFC3339, paydate)
 	if err != nil {
+		return fiber.StatusInternalServerError, entities.ResponseMessage{Message: "invalid paydate from payment_intent: " + err.Error()}
+	}
 
+	payment, err := h.PaymentService.FindByStripeReference(payId, "")
+	if err != nil {
 		if errors.Is(err, db.ErrNotFound) {
-			return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "payment not found"})
+			return fiber.StatusNotFound, entities.ResponseMessage{Message: "payment not found"}
+		}
+		return fiber.StatusInternalServerError, entities.ResponseMessage{Message: err.Error()}
+	}
+	settlement := entities.StripePaymentUpdate{
+		Status:        db.PaymentStatusPaid,
+		Type:          &method,
+		PayDate:       &payDate,
+		PaymentIntent: &session.PaymentIntent.ID,
+	}
+	if payment.Status == db.PaymentStatusExpired {
+		// sweeper expire ไปแล้ว (async payment มาช้ามาก) slot ไม่ได้กันไว้แล้ว คืนเงิน
+		return h.refundUnfulfilledCheckout(payment.PayID, settlement)
+	}
+	if payment.Status != db.PaymentStatusUnpaid && payment.Status != db.PaymentStatusFailed {
+		return fiber.StatusOK, entities.ResponseModel{
+			Message: "payment already settled as " + string(payment.Status),
+			Data:    fiber.Map{"payment": payment},
+			Status:  fiber.StatusOK,
 		}
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
 	}
 
-	start, err := time.Parse(time.RFC3339, metadata["reserve_date_start"].(string))
+	if rescheduleID := metadata["reschedule_id"]; rescheduleID != "" {
+		return h.stripeReschedulePaid(rescheduleID, payment.PayID, settlement)
+	}
+
+	start, err := time.Parse(time.RFC3339, metadata["reserve_date_start"])
 	if err != nil {
-		return fmt.Errorf("invalid reserve_date_start: %w", err)
+		return fiber.StatusBadRequest, entities.ResponseMessage{Message: "invalid reserve_date_start: " + err.Error()}
 	}
 
-	end, err := time.Parse(time.RFC3339, metadata["reserve_date_end"].(string))
+	end, err := time.Parse(time.RFC3339, metadata["reserve_date_end"])
 	if err != nil {
-		return fmt.Errorf("invalid reserve_date_end: %w", err)
+		return fiber.StatusBadRequest, entities.ResponseMessage{Message: "invalid reserve_date_end: " + err.Error()}
 	}
 	createService := entities.CreateServiceRequest{
-		OwnerID:          metadata["owner_id"].(string),
-		PetID:            metadata["pet_id"].(string),
-		PaymentID:        updatedPayment.PayID,
-		StaffID:          metadata["staff_id"].(string),
-		ServiceType:      metadata["service_type"].(string),
-		Status:           metadata["status"].(string),
+		OwnerID:          metadata["owner_id"],
+		PetID:            metadata["pet_id"],
+		PaymentID:        payment.PayID,
+		StaffID:          metadata["staff_id"],
+		ServiceType:      metadata["service_type"],
 		ReserveDateStart: start,
 		ReserveDateEnd:   end,
 	}
 
-	service, subservice, err := h.ServiceService.CreateService(createService)
+	// payment, service, subservice และ total_spending commit พร้อมกันใน transaction เดียว
+	service, subservice, err := h.ServiceService.CreateService(createService, settlement)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
+		if errors.Is(err, services.ErrStaffUnavailable) {
+			return h.refundUnfulfilledCheckout(payment.PayID, settlement)
+		}
+		return fiber.StatusInternalServerError, entities.ResponseMessage{
 			Message: "cannot create service: " + err.Error(),
-		})
+		}
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+	updatedPayment, err := h.PaymentService.FindByStripeReference(payment.PayID, "")
+	if err != nil {
+		return fiber.StatusInternalServerError, entities.ResponseMessage{Message: err.Error()}
+	}
+
+	return fiber.StatusOK, entities.ResponseModel{
 		Message: "payment updated successfully",
 		Data: fiber.Map{
 			"payment":    updatedPayment,
@@ -107,5 +175,163 @@ func (h *HTTPGateway) StripeWebhookService(ctx *fiber.Ctx) error {
 			"subservice": subservice,
 		},
 		Status: fiber.StatusOK,
-	})
+	}
+}
+
+func (h *HTTPGateway) stripeCheckoutClosed(event stripe.Event, status db.PaymentStatus) (int, interface{}) {
+	var session stripe.CheckoutSession
+	if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
+		return fiber.StatusBadRequest, entities.ResponseMessage{Message: "cannot parse checkout session: " + err.Error()}
+	}
+
+	if rescheduleID := session.Metadata["reschedule_id"]; rescheduleID != "" {
+		// slot ใหม่ถือไว้ในชื่อ payment ส่วนต่างของ reschedule นี้
+		if err := h.ServiceService.AbandonReschedule(rescheduleID); err != nil {
+			return fiber.StatusInternalServerError, entities.ResponseMessage{Message: err.Error()}
+		}
+	} else if paymentID := session.Metadata["payment_id"]; paymentID != "" {
+		if err := h.ServiceService.ReleaseHold(paymentID); err != nil {
+			return fiber.StatusInternalServerError, entities.ResponseMessage{Message: err.Error()}
+		}
+	}
+
+	return h.applyStripePaymentStatus(session.Metadata["payment_id"], "", status, false)
+}
+
+// stripeReschedulePaid moves the booking once the extra charge of a reschedule is
+// paid. If the booking can't move any more the extra charge is refunded.
+func (h *HTTPGateway) stripeReschedulePaid(rescheduleID, paymentID string, settlement entities.StripePaymentUpdate) (int, interface{}) {
+	service, err := h.ServiceService.CompleteReschedule(rescheduleID, settlement)
+	if err != nil {
+		if errors.Is(err, services.ErrStaffUnavailable) || errors.Is(err, services.ErrServiceNotReschedulable) {
+			if err := h.ServiceService.AbandonReschedule(rescheduleID); err != nil {
+				return fiber.StatusInternalServerError, entities.ResponseMessage{Message: err.Error()}
+			}
+			return h.refundUnfulfilledCheckout(paymentID, settlement)
+		}
+		return fiber.StatusInternalServerError, entities.ResponseMessage{
+			Message: "cannot reschedule service: " + err.Error(),
+		}
+	}
+
+	updatedPayment, err := h.PaymentService.FindByStripeReference(paymentID, "")
+	if err != nil {
+		return fiber.StatusInternalServerError, entities.ResponseMessage{Message: err.Error()}
+	}
+
+	return fiber.StatusOK, entities.ResponseModel{
+		Message: "service rescheduled",
+		Data: fiber.Map{
+			"payment": updatedPayment,
+			"service": service,
+		},
+		Status: fiber.StatusOK,
+	}
+}
+
+// refundUnfulfilledCheckout handles a payment that went through after the staff was
+// booked by someone else: the money goes back instead of double-booking the staff.
+func (h *HTTPGateway) refundUnfulfilledCheckout(paymentID string, settlement entities.StripePaymentUpdate) (int, interface{}) {
+	refunded, err := h.PaymentService.RefundUnfulfilledPayment(paymentID, settlement)
+	if err != nil {
+		return fiber.StatusInternalServerError, entities.ResponseMessage{Message: "cannot refund payment: " + err.Error()}
+	}
+	if err := h.ServiceService.ReleaseHold(paymentID); err != nil {
+		return fiber.StatusInternalServerError, entities.ResponseMessage{Message: err.Error()}
+	}
+
+	return fiber.StatusOK, entities.ResponseModel{
+		Message: "staff is no longer available, payment refunded",
+		Data:    fiber.Map{"payment": refunded},
+		Status:  fiber.StatusOK,
+	}
+}
+
+func (h *HTTPGateway) stripePaymentFailed(event stripe.Event) (int, interface{}) {
+	var intent stripe.PaymentIntent
+	if err := json.Unmarshal(event.Data.Raw, &intent); err != nil {
+		return fiber.StatusBadRequest, entities.ResponseMessage{Message: "cannot parse payment_intent: " + err.Error()}
+	}
+
+	return h.applyStripePaymentStatus(intent.Metadata["payment_id"], intent.ID, db.PaymentStatusFailed, false)
+}
+
+func (h *HTTPGateway) stripeChargeRefunded(event stripe.Event) (int, interface{}) {
+	var charge stripe.Charge
+	if err := json.Unmarshal(event.Data.Raw, &charge); err != nil {
+		return fiber.StatusBadRequest, entities.ResponseMessage{Message: "cannot parse charge: " + err.Error()}
+	}
+	// partial refund ยังถือว่าจ่ายอยู่
+	if !charge.Refunded {
+		return fiber.StatusOK, entities.ResponseMessage{Message: "partial refund, payment status unchanged"}
+	}
+
+	return h.applyStripePaymentStatus(charge.Metadata["payment_id"], stripePaymentIntentID(charge.PaymentIntent), db.PaymentStatusRefunded, true)
+}
+
+func (h *HTTPGateway) stripeDisputeCreated(event stripe.Event) (int, interface{}) {
+	var dispute stripe.Dispute
+	if err := json.Unmarshal(event.Data.Raw, &dispute); err != nil {
+		return fiber.StatusBadRequest, entities.ResponseMessage{Message: "cannot parse dispute: " + err.Error()}
+	}
+
+	return h.applyStripePaymentStatus("", stripePaymentIntentID(dispute.PaymentIntent), db.PaymentStatusDisputed, true)
+}
+
+// applyStripePaymentStatus updates the payment matched by paymentID or the Stripe
+// payment_intent and, when cancelService is set, cancels its booking if not started.
+func (h *HTTPGateway) applyStripePaymentStatus(paymentID, paymentIntentID string, status db.PaymentStatus, cancelService bool) (int, interface{}) {
+	payment, err := h.PaymentService.FindByStripeReference(paymentID, paymentIntentID)
+	if err != nil {
+		// ไม่ใช่ payment ของระบบนี้ ตอบรับไปเลยไม่ต้องให้ retry
+		if errors.Is(err, db.ErrNotFound) {
+			return fiber.StatusOK, entities.ResponseMessage{Message: "no matching payment, event ignored"}
+		}
+		return fiber.StatusInternalServerError, entities.ResponseMessage{Message: err.Error()}
+	}
+	if status == db.PaymentStatusRefunded {
+		// refund ของการยกเลิกที่ยังไม่ commit ถ้าเปลี่ยน payment ตรงนี้ ยอดที่คืนได้จะหายไปตอนลองยกเลิกใหม่
+		pending, err := h.ServiceService.CancellationPending(payment.PayID)
+		if err != nil {
+			return fiber.StatusInternalServerError, entities.ResponseMessage{Message: err.Error()}
+		}
+		if pending {
+			return fiber.StatusOK, entities.ResponseMessage{Message: "refund of a cancellation in progress, recorded by the cancellation"}
+		}
+	}
+
+	updatedPayment, applied, err := h.PaymentService.ApplyStripeStatus(payment.PayID, entities.StripePaymentUpdate{Status: status})
+	if err != nil {
+		return fiber.StatusInternalServerError, entities.ResponseMessage{Message: err.Error()}
+	}
+	if !applied {
+		return fiber.StatusOK, entities.ResponseModel{
+			Message: "payment status unchanged",
+			Data:    fiber.Map{"payment": updatedPayment},
+			Status:  fiber.StatusOK,
+		}
+	}
+
+	var service *entities.ServiceModel
+	if cancelService {
+		if service, err = h.ServiceService.CancelByPaymentID(updatedPayment.PayID, updatedPayment.Status); err != nil {
+			return fiber.StatusInternalServerError, entities.ResponseMessage{Message: "cannot cancel service: " + err.Error()}
+		}
+	}
+
+	return fiber.StatusOK, entities.ResponseModel{
+		Message: "payment updated successfully",
+		Data: fiber.Map{
+			"payment": updatedPayment,
+			"service": service,
+		},
+		Status: fiber.StatusOK,
+	}
+}
+
+func stripePaymentIntentID(intent *stripe.PaymentIntent) string {
+	if intent == nil {
+		return ""
+	}
+	return intent.ID
 }
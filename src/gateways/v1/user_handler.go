@@ -1,8 +1,11 @@
 package gateways
 
 import (
+	"errors"
+
 	"lama-backend/domain/entities"
 	"lama-backend/src/middlewares"
+	service "lama-backend/src/services"
 	"lama-backend/src/utils"
 
 	"github.com/go-playground/validator/v10"
@@ -19,10 +22,7 @@ import (
 // @Router /user/ [get]
 // @Security BearerAuth
 func (h *HTTPGateway) FindUserByID(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
+	token := middlewares.AccessToken(ctx)
 
 	user, err := h.UsersService.FindUsersByID(token.UserID)
 	if err != nil {
@@ -48,9 +48,10 @@ func (h *HTTPGateway) FindUserByID(ctx *fiber.Ctx) error {
 // @Router /user/ [delete]
 // @Security BearerAuth
 func (h *HTTPGateway) DeleteUserByID(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
+	token := middlewares.AccessToken(ctx)
+
+	if _, err := h.releaseStaffBookings(token.UserID, &token.UserID); err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
 	}
 
 	deletedUser, err := h.UsersService.DeleteUsersByID(token.UserID)
@@ -80,26 +81,30 @@ func (h *HTTPGateway) DeleteUserByID(ctx *fiber.Ctx) error {
 // @Router /admin/users/{userID} [delete]
 // @Security BearerAuth
 func (h *HTTPGateway) DeleteUserByAdmin(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
+	token := middlewares.AccessToken(ctx)
 
 	userID := ctx.Params("userID")
 	if userID == "" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid user ID"})
 	}
 
+	// booking ที่ยังไม่เริ่มของ staff ต้องย้ายก่อน ไม่งั้นหายไปพร้อม Cservice/Mservice
+	reassignments, err := h.releaseStaffBookings(userID, &token.UserID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+
 	deletedUser, err := h.UsersService.DeleteUsersByID(userID)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
 	}
 
+	message := "user deleted successfully"
+	if len(reassignments) > 0 {
+		message += ", " + reassignmentSummary(reassignments)
+	}
 	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
-		Message: "user deleted successfully",
+		Message: message,
 		Data:    deletedUser,
 		Status:  fiber.StatusOK,
 	})
@@ -118,14 +123,6 @@ func (h *HTTPGateway) DeleteUserByAdmin(ctx *fiber.Ctx) error {
 // @Router /admin/users/{userID} [get]
 // @Security BearerAuth
 func (h *HTTPGateway) FindUserByAdmin(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
-
 	userID := ctx.Params("userID")
 	if userID == "" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid user ID"})
@@ -156,10 +153,7 @@ func (h *HTTPGateway) FindUserByAdmin(ctx *fiber.Ctx) error {
 // @Router /user/ [patch]
 // @Security BearerAuth
 func (h *HTTPGateway) UpdateUserByID(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
+	token := middlewares.AccessToken(ctx)
 
 	var updateData entities.UpdateUserModel
 	if err := ctx.BodyParser(&updateData); err != nil {
@@ -196,14 +190,6 @@ func (h *HTTPGateway) UpdateUserByID(ctx *fiber.Ctx) error {
 // @Router /admin/users/{userID} [patch]
 // @Security BearerAuth
 func (h *HTTPGateway) UpdateUserByAdmin(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
-
 	userID := ctx.Params("userID")
 	if userID == "" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid user ID"})
@@ -226,7 +212,7 @@ func (h *HTTPGateway) UpdateUserByAdmin(ctx *fiber.Ctx) error {
 }
 
 // @Summary update user picture
-// @Description update user picture by id and role from JWT token
+// @Description update user picture by id and role from JWT token. jpeg, png or webp up to 5 MB, older pictures are removed once the new one is saved.
 // @Tags user
 // @Accept multipart/form-data
 // @Produce json
@@ -234,39 +220,37 @@ func (h *HTTPGateway) UpdateUserByAdmin(ctx *fiber.Ctx) error {
 // @Success 200 {object} entities.UserDataModel "Request successful"
 // @Failure 400 {object} entities.ResponseMessage "picture file is required"
 // @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 413 {object} entities.ResponseMessage "Picture larger than 5 MB"
+// @Failure 415 {object} entities.ResponseMessage "Picture is not jpeg, png or webp"
 // @Failure 500 {object} entities.ResponseMessage "Internal Server Error"
 // @Router /user/profile [patch]
 // @Security BearerAuth
 func (h *HTTPGateway) UpdateUserPicture(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
+	token := middlewares.AccessToken(ctx)
 
-	file, err := ctx.FormFile("profile")
+	upload, closeFile, err := formAttachment(ctx, "profile")
 	if err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{
 			Message: "picture file is required",
 		})
 	}
+	defer closeFile()
 
-	pictureString, err := utils.UploadToSupabase(file, token.UserID)
+	updatedUser, err := h.UsersService.UpdateProfilePicture(token.UserID, upload)
 	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAttachmentTooLarge):
+			return ctx.Status(fiber.StatusRequestEntityTooLarge).JSON(entities.ResponseMessage{Message: err.Error()})
+		case errors.Is(err, service.ErrUnsupportedFileType):
+			return ctx.Status(fiber.StatusUnsupportedMediaType).JSON(entities.ResponseMessage{Message: err.Error()})
+		case errors.Is(err, service.ErrInvalidAttachment):
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
 			Message: "failed to upload picture: " + err.Error(),
 		})
 	}
 
-	var updateData entities.UpdateUserModel
-	updateData.Profile = &pictureString
-
-	updatedUser, err := h.UsersService.UpdateUsersByID(token.UserID, updateData)
-	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{
-			Message: err.Error(),
-		})
-	}
-
 	return ctx.Status(fiber.StatusOK).JSON(updatedUser)
 }
 
@@ -284,14 +268,6 @@ func (h *HTTPGateway) UpdateUserPicture(ctx *fiber.Ctx) error {
 // @Router /admin/users [get]
 // @Security BearerAuth
 func (h *HTTPGateway) GetAllUsers(ctx *fiber.Ctx) error {
-	token, err := middlewares.DecodeJWTToken(ctx)
-	if err != nil || token.Purpose != "access" {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
-	}
-	if token.Role != "admin" {
-		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
-	}
-
 	role := ctx.Query("role")
 	page := ctx.QueryInt("page", 1)
 	limit := ctx.QueryInt("limit", 20)
@@ -312,3 +288,85 @@ func (h *HTTPGateway) GetAllUsers(ctx *fiber.Ctx) error {
 		Status: fiber.StatusOK,
 	})
 }
+
+// @Summary add role to my account
+// @Description Add owner, caretaker or doctor to the logged in account. Email and password stay the same, switch to the new role with POST /auth/switch-role/{role}.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param role path string true "Role to add (owner, caretaker, doctor)"
+// @Param body body entities.AddRoleRequest false "license_number is required for doctor"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 409 {object} entities.ResponseMessage "Account already has this role"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal Server Error"
+// @Router /user/roles/{role} [post]
+// @Security BearerAuth
+func (h *HTTPGateway) AddMyRole(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	role := ctx.Params("role")
+	// admin ให้ตัวเองไม่ได้ ต้องให้ admin คนอื่น grant
+	if role != "doctor" && role != "caretaker" && role != "owner" {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
+	}
+	return h.addRole(ctx, token.UserID, role)
+}
+
+// @Summary grant role to a user
+// @Description admin adds a role to an existing account, admin included.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param userID path string true "User ID"
+// @Param role path string true "Role to grant (admin, owner, caretaker, doctor)"
+// @Param body body entities.AddRoleRequest false "license_number is required for doctor"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid json body"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "User not found"
+// @Failure 409 {object} entities.ResponseMessage "Account already has this role"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal Server Error"
+// @Router /admin/users/{userID}/roles/{role} [post]
+// @Security BearerAuth
+func (h *HTTPGateway) GrantRole(ctx *fiber.Ctx) error {
+	role := ctx.Params("role")
+	if !validRole(role) {
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
+	}
+	if _, err := h.UsersService.FindUsersByID(ctx.Params("userID")); err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "user not found"})
+	}
+	return h.addRole(ctx, ctx.Params("userID"), role)
+}
+
+func (h *HTTPGateway) addRole(ctx *fiber.Ctx, userID, role string) error {
+	var bodyData entities.AddRoleRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&bodyData); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+		}
+	}
+	if role == "doctor" && bodyData.LicenseNumber == "" {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: "license_number is required for doctor"})
+	}
+
+	user, err := h.AuthService.AddRole(userID, role, bodyData)
+	if err != nil {
+		if errors.Is(err, service.ErrRoleAlreadyGranted) {
+			return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: "cannot add role: " + err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "role added",
+		Data:    user,
+		Status:  fiber.StatusOK,
+	})
+}
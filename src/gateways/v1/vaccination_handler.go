@@ -0,0 +1,137 @@
+package gateways
+
+import (
+	"errors"
+	"strings"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/middlewares"
+	service "lama-backend/src/services"
+	"lama-backend/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Record vaccination
+// @Description Add a vaccine or preventive care dose (deworming, flea/tick) to a pet. Doctors record doses they gave, admins can back-fill one with doctor_id. With service_id the dose comes from that mservice and its doctor. The owner gets an email reminder before next_due_date. Earlier doses of the same vaccine are no longer reminded.
+// @Tags vaccination
+// @Accept json
+// @Produce json
+// @Param petID path string true "Pet ID"
+// @Param body body entities.CreateVaccinationRequest true "dose"
+// @Success 201 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid request"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role or not the assigned doctor"
+// @Failure 404 {object} entities.ResponseMessage "Pet or service not found"
+// @Failure 409 {object} entities.ResponseMessage "Service is not an mservice"
+// @Failure 422 {object} entities.ResponseMessage "Validation error"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pets/{petID}/vaccinations [post]
+// @Security BearerAuth
+func (h *HTTPGateway) CreateVaccination(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	petID := ctx.Params("petID")
+	if err := h.Validator.Var(petID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid pet ID"})
+	}
+
+	var req entities.CreateVaccinationRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid json body"})
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(entities.ResponseMessage{Message: utils.FormatValidationError(err)})
+	}
+
+	vaccination, err := h.VaccinationService.RecordVaccination(petID, token.UserID, token.Role, req)
+	if err != nil {
+		return vaccinationErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(entities.ResponseModel{
+		Message: "vaccination recorded",
+		Data:    vaccination,
+		Status:  fiber.StatusCreated,
+	})
+}
+
+// @Summary Get pet vaccinations
+// @Description Every vaccine and preventive care dose of the pet, latest first. Superseded doses were replaced by a later dose of the same vaccine. Owners see their own pets, doctors the pets they have treated, admins any pet.
+// @Tags vaccination
+// @Produce json
+// @Param petID path string true "Pet ID"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid pet ID"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 404 {object} entities.ResponseMessage "Pet not found"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /pets/{petID}/vaccinations [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetPetVaccinations(ctx *fiber.Ctx) error {
+	token := middlewares.AccessToken(ctx)
+
+	petID := ctx.Params("petID")
+	if err := h.Validator.Var(petID, "uuid"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "invalid pet ID"})
+	}
+
+	vaccinations, err := h.VaccinationService.FindPetVaccinations(petID, token.UserID, token.Role)
+	if err != nil {
+		return vaccinationErrorResponse(ctx, err)
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "Request successful",
+		Data:    vaccinations,
+		Status:  fiber.StatusOK,
+	})
+}
+
+// @Summary Get vaccinations coming due
+// @Description Current doses of every pet due within the next days (overdue ones included), soonest first, with the owner to contact. Admin only.
+// @Tags vaccination
+// @Produce json
+// @Param days query int false "look ahead in days (default 30, max 365)"
+// @Success 200 {object} entities.ResponseModel "Request successful"
+// @Failure 400 {object} entities.ResponseMessage "Invalid days"
+// @Failure 401 {object} entities.ResponseMessage "Unauthorization Token."
+// @Failure 403 {object} entities.ResponseMessage "Invalid role"
+// @Failure 500 {object} entities.ResponseMessage "Internal server error"
+// @Router /admin/vaccinations/due [get]
+// @Security BearerAuth
+func (h *HTTPGateway) GetDueVaccinations(ctx *fiber.Ctx) error {
+	days := ctx.QueryInt("days", 30)
+	if days < 0 || days > 365 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: "days must be between 0 and 365"})
+	}
+
+	due, err := h.VaccinationService.FindDueVaccinations(days)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(entities.ResponseModel{
+		Message: "Request successful",
+		Data: fiber.Map{
+			"amount":       len(due),
+			"vaccinations": due,
+		},
+		Status: fiber.StatusOK,
+	})
+}
+
+func vaccinationErrorResponse(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrVaccinationForbidden), errors.Is(err, service.ErrNotAssignedDoctor):
+		return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrNotMedicalService):
+		return ctx.Status(fiber.StatusConflict).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, service.ErrInvalidVaccination):
+		return ctx.Status(fiber.StatusBadRequest).JSON(entities.ResponseMessage{Message: err.Error()})
+	case errors.Is(err, db.ErrNotFound), strings.Contains(err.Error(), "not found"):
+		return ctx.Status(fiber.StatusNotFound).JSON(entities.ResponseMessage{Message: "not found"})
+	default:
+		return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+	}
+}
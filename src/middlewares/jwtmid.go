@@ -1,6 +1,11 @@
 package middlewares
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"lama-backend/domain/entities"
 	"log"
@@ -13,7 +18,12 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func SetJWtHeaderHandler() fiber.Handler {
+// SessionChecker tells whether the session behind an access token is still alive.
+type SessionChecker func(td *TokenDetails) error
+
+// SetJWtHeaderHandler verifies the signature and, for access tokens, asks checkSession
+// so a logout or password change takes effect before the token expires.
+func SetJWtHeaderHandler(checkSession SessionChecker) fiber.Handler {
 	return jwtware.New(jwtware.Config{
 		SigningKey: jwtware.SigningKey{
 			//ตัว secret key ดึงมาจาก .env
@@ -24,6 +34,18 @@ func SetJWtHeaderHandler() fiber.Handler {
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			return c.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
 		},
+		SuccessHandler: func(c *fiber.Ctx) error {
+			td, err := DecodeJWTToken(c)
+			if err != nil || td == nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
+			}
+			if td.Purpose == "access" {
+				if err := checkSession(td); err != nil {
+					return c.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
+				}
+			}
+			return c.Next()
+		},
 	})
 }
 
@@ -33,8 +55,22 @@ type TokenDetails struct {
 	Role      string  `json:"role"`
 	Purpose   string  `json:"purpose"`
 	ExpiresIn *int64  `json:"exp"`
+	SessionID string  `json:"session_id,omitempty"`
+	// ได้เฉพาะตอน login/refresh ใช้แลก access token ใบใหม่ที่ /auth/refresh
+	RefreshToken     *string `json:"refresh_token,omitempty"`
+	RefreshExpiresIn *int64  `json:"refresh_exp,omitempty"`
+	// jti ของ reset password token ชี้ไปที่แถว PasswordReset
+	TokenID string `json:"-"`
+	// ได้เฉพาะ mfa_pending token false = ต้องตั้ง 2FA ก่อนถึงจะ login ได้
+	MfaEnrolled *bool `json:"mfa_enrolled,omitempty"`
 }
 
+// ResetPasswordTokenTTL is how long the link in the reset email works.
+const ResetPasswordTokenTTL = 15 * time.Minute
+
+// MfaPendingTokenTTL is how long a login can wait between the password and the second factor.
+const MfaPendingTokenTTL = 10 * time.Minute
+
 func DecodeJWTToken(ctx *fiber.Ctx) (*TokenDetails, error) {
 	td := &TokenDetails{
 		Token: new(string),
@@ -60,12 +96,15 @@ func DecodeJWTToken(ctx *fiber.Ctx) (*TokenDetails, error) {
 		if key == "purpose" {
 			td.Purpose = value.(string)
 		}
+		if key == "sid" {
+			td.SessionID, _ = value.(string)
+		}
 	}
 	*td.Token = token.Raw
 	return td, nil
 }
 
-func GenerateJWTToken(userID string, role string, purpose string) (*TokenDetails, error) {
+func GenerateJWTToken(userID string, role string, purpose string, sessionID string) (*TokenDetails, error) {
 	now := time.Now().UTC()
 
 	td := &TokenDetails{
@@ -79,6 +118,7 @@ func GenerateJWTToken(userID string, role string, purpose string) (*TokenDetails
 	td.UserID = userID
 	td.Role = role
 	td.Purpose = purpose
+	td.SessionID = sessionID
 
 	SigningKey := []byte(os.Getenv("JWT_SECRET_KEY"))
 
@@ -86,6 +126,9 @@ func GenerateJWTToken(userID string, role string, purpose string) (*TokenDetails
 	atClaims["user_id"] = userID
 	atClaims["role"] = role
 	atClaims["purpose"] = purpose
+	if sessionID != "" {
+		atClaims["sid"] = sessionID
+	}
 	atClaims["exp"] = time.Now().Add(time.Hour * 6).Unix()
 	atClaims["iat"] = time.Now().Unix()
 	atClaims["nbf"] = time.Now().Unix()
@@ -101,6 +144,25 @@ func GenerateJWTToken(userID string, role string, purpose string) (*TokenDetails
 	return td, nil
 }
 
+// GenerateRefreshToken returns an opaque refresh token and the hash to store for it.
+// The token itself is never saved.
+func GenerateRefreshToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("create: refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken is what the sessions table stores for a refresh token,
+// an HMAC keyed with JWT_REFESH_SECRET_KEY.
+func HashRefreshToken(token string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_REFESH_SECRET_KEY")))
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 func DecodeResetPasswordJWTToken(tokenString string) (*TokenDetails, error) {
 	td := &TokenDetails{
 		Token: new(string),
@@ -133,13 +195,20 @@ func DecodeResetPasswordJWTToken(tokenString string) (*TokenDetails, error) {
 		if key == "purpose" {
 			td.Purpose = value.(string)
 		}
+		if key == "jti" {
+			td.TokenID, _ = value.(string)
+		}
+	}
+	// access token เซ็นด้วย key เดียวกัน ต้องไม่เอามาใช้ reset ได้
+	if td.Purpose != "reset_password" || td.TokenID == "" {
+		return nil, fmt.Errorf("unauthorized token: not a reset password token")
 	}
 
 	*td.Token = tokenString
 	return td, nil
 }
 
-func GenerateResetPasswordJWTToken(userID string, role string, purpose string) (*TokenDetails, error) {
+func GenerateResetPasswordJWTToken(userID string, role string, purpose string, resetID string) (*TokenDetails, error) {
 	now := time.Now().UTC()
 
 	td := &TokenDetails{
@@ -148,11 +217,12 @@ func GenerateResetPasswordJWTToken(userID string, role string, purpose string) (
 	}
 
 	// expiresIn is set to 15 minutes from now
-	*td.ExpiresIn = now.Add(time.Minute * 15).Unix()
+	*td.ExpiresIn = now.Add(ResetPasswordTokenTTL).Unix()
 
 	td.UserID = userID
 	td.Role = role
 	td.Purpose = purpose
+	td.TokenID = resetID
 
 	SigningKey := []byte(os.Getenv("JWT_SECRET_KEY"))
 
@@ -160,7 +230,8 @@ func GenerateResetPasswordJWTToken(userID string, role string, purpose string) (
 	atClaims["user_id"] = userID
 	atClaims["role"] = role
 	atClaims["purpose"] = purpose
-	atClaims["exp"] = time.Now().Add(time.Minute * 15).Unix()
+	atClaims["jti"] = resetID
+	atClaims["exp"] = time.Now().Add(ResetPasswordTokenTTL).Unix()
 	atClaims["iat"] = time.Now().Unix()
 	atClaims["nbf"] = time.Now().Unix()
 
@@ -174,3 +245,33 @@ func GenerateResetPasswordJWTToken(userID string, role string, purpose string) (
 	*td.Token = token
 	return td, nil
 }
+
+// GenerateMfaPendingJWTToken is handed out instead of an access token when the password was
+// right but the account still has to pass (or set up) 2FA. It has no session behind it and
+// only the /auth/mfa endpoints take it.
+func GenerateMfaPendingJWTToken(userID string, role string, enrolled bool) (*TokenDetails, error) {
+	expiresAt := time.Now().Add(MfaPendingTokenTTL)
+
+	atClaims := make(jwt.MapClaims)
+	atClaims["user_id"] = userID
+	atClaims["role"] = role
+	atClaims["purpose"] = "mfa_pending"
+	atClaims["exp"] = expiresAt.Unix()
+	atClaims["iat"] = time.Now().Unix()
+	atClaims["nbf"] = time.Now().Unix()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, atClaims).SignedString([]byte(os.Getenv("JWT_SECRET_KEY")))
+	if err != nil {
+		return nil, fmt.Errorf("create: sign token: %w", err)
+	}
+
+	expiresIn := expiresAt.Unix()
+	return &TokenDetails{
+		Token:       &token,
+		UserID:      userID,
+		Role:        role,
+		Purpose:     "mfa_pending",
+		ExpiresIn:   &expiresIn,
+		MfaEnrolled: &enrolled,
+	}, nil
+}
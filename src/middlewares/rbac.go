@@ -0,0 +1,125 @@
+package middlewares
+
+import (
+	"errors"
+	"lama-backend/domain/entities"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// accessMatrix is every role's allowed actions per resource. It only answers "may this role
+// do this at all"; whether the pet or booking is theirs is left to the OwnershipHooks of the
+// route, and finer rules (a doctor must have treated the pet) stay in the services.
+var accessMatrix = map[string]map[string][]string{
+	"admin": {
+		"account":        {"read", "update", "delete", "logout", "add_role", "switch_role", "mfa"},
+		"user":           {"create", "list", "read", "update", "delete", "grant_role", "reset_mfa"},
+		"mfa_policy":     {"read", "manage"},
+		"reassignment":   {"create", "list", "resolve"},
+		"service":        {"create", "list", "read", "update", "cancel", "reschedule", "update_status"},
+		"staff":          {"search", "read_slots", "read_score"},
+		"schedule":       {"read", "manage"},
+		"leave":          {"cancel", "review"},
+		"pet":            {"create", "list", "update", "delete"},
+		"pet_record":     {"read", "write"},
+		"medical_record": {"read"},
+		"vaccination":    {"create", "list_due"},
+		"payment":        {"list", "update"},
+		"pricing":        {"read", "manage"},
+	},
+	"owner": {
+		"account":        {"read", "update", "delete", "logout", "add_role", "switch_role", "mfa"},
+		"service":        {"create", "list", "read", "cancel", "reschedule", "review"},
+		"staff":          {"search", "read_slots", "read_score"},
+		"schedule":       {"read"},
+		"pet":            {"create_own", "list_own", "update", "delete"},
+		"pet_record":     {"read", "write"},
+		"medical_record": {"read"},
+		"payment":        {"list"},
+	},
+	"doctor": {
+		"account":        {"read", "update", "delete", "logout", "add_role", "switch_role", "mfa"},
+		"service":        {"list", "read", "update_status"},
+		"staff":          {"read_slots"},
+		"schedule":       {"read", "manage"},
+		"leave":          {"request", "list_own", "cancel"},
+		"pet_record":     {"read", "write"},
+		"medical_record": {"read", "write"},
+		"vaccination":    {"create"},
+	},
+	"caretaker": {
+		"account":  {"read", "update", "delete", "logout", "add_role", "switch_role", "mfa"},
+		"service":  {"list", "read", "update_status"},
+		"staff":    {"read_slots", "read_score"},
+		"schedule": {"read", "manage"},
+		"leave":    {"request", "list_own", "cancel"},
+	},
+}
+
+// Can reports whether the role may perform action on resource.
+func Can(role, resource, action string) bool {
+	return slices.Contains(accessMatrix[role][resource], action)
+}
+
+// OwnershipHook runs after the role check, e.g. "the owner owns this pet". Return a
+// *fiber.Error to answer with its status (403, 404), any other error becomes a 500.
+type OwnershipHook func(ctx *fiber.Ctx, token *TokenDetails) error
+
+const accessTokenKey = "access_token"
+
+// Authorize lets the request through only with an access token whose role may do action
+// on resource and that passes every hook. Put it after SetJWtHeaderHandler.
+func Authorize(resource, action string, hooks ...OwnershipHook) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		token, err := DecodeJWTToken(ctx)
+		if err != nil || token == nil || token.Purpose != "access" {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
+		}
+		if !Can(token.Role, resource, action) {
+			return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
+		}
+		for _, hook := range hooks {
+			if err := hook(ctx, token); err != nil {
+				var fiberErr *fiber.Error
+				if errors.As(err, &fiberErr) {
+					return ctx.Status(fiberErr.Code).JSON(entities.ResponseMessage{Message: fiberErr.Message})
+				}
+				return ctx.Status(fiber.StatusInternalServerError).JSON(entities.ResponseMessage{Message: err.Error()})
+			}
+		}
+
+		ctx.Locals(accessTokenKey, token)
+		return ctx.Next()
+	}
+}
+
+// AuthorizeMfaStep is for the 2FA endpoints a login reaches before it has an access token.
+// It takes the mfa_pending token from the password step, and an access token as well when
+// withAccess is set (turning 2FA on from the account page). AccessToken returns either.
+func AuthorizeMfaStep(withAccess bool) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		token, err := DecodeJWTToken(ctx)
+		if err != nil || token == nil {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
+		}
+		switch {
+		case token.Purpose == "mfa_pending":
+		case token.Purpose == "access" && withAccess:
+			if !Can(token.Role, "account", "mfa") {
+				return ctx.Status(fiber.StatusForbidden).JSON(entities.ResponseMessage{Message: "Invalid role"})
+			}
+		default:
+			return ctx.Status(fiber.StatusUnauthorized).JSON(entities.ResponseMessage{Message: "Unauthorization Token."})
+		}
+
+		ctx.Locals(accessTokenKey, token)
+		return ctx.Next()
+	}
+}
+
+// AccessToken is the token Authorize already checked for this request.
+func AccessToken(ctx *fiber.Ctx) *TokenDetails {
+	token, _ := ctx.Locals(accessTokenKey).(*TokenDetails)
+	return token
+}
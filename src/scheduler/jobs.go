@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/services"
+)
+
+// ExpireAbandonedPayments marks UNPAID/FAILED checkouts whose Stripe session is over as EXPIRED.
+func ExpireAbandonedPayments(payment services.IPaymentService) Job {
+	return Job{
+		Name:     "expire-abandoned-payments",
+		Interval: 5 * time.Minute,
+		Run: func(ctx context.Context, tx *repositories.Tx) error {
+			payment.ExpireAbandonedPaymentsTx(tx, time.Now())
+			return nil
+		},
+	}
+}
+
+// PurgeExpiredHolds deletes staff holds that already stopped blocking the slot.
+func PurgeExpiredHolds(service services.IServiceService) Job {
+	return Job{
+		Name:     "purge-expired-holds",
+		Interval: 15 * time.Minute,
+		Run: func(ctx context.Context, tx *repositories.Tx) error {
+			service.PurgeExpiredHoldsTx(tx, time.Now())
+			return nil
+		},
+	}
+}
+
+// PurgeExpiredSessions deletes login sessions whose refresh token expired or that were
+// revoked a while ago.
+func PurgeExpiredSessions(auth services.IAuthService) Job {
+	return Job{
+		Name:     "purge-expired-sessions",
+		Interval: time.Hour,
+		Run: func(ctx context.Context, tx *repositories.Tx) error {
+			auth.PurgeExpiredSessionsTx(tx, time.Now())
+			return nil
+		},
+	}
+}
+
+// PurgePasswordResets deletes reset password requests that no longer count toward the
+// rate limit.
+func PurgePasswordResets(auth services.IAuthService) Job {
+	return Job{
+		Name:     "purge-password-resets",
+		Interval: time.Hour,
+		Run: func(ctx context.Context, tx *repositories.Tx) error {
+			auth.PurgePasswordResetsTx(tx, time.Now())
+			return nil
+		},
+	}
+}
+
+// SendVaccinationReminders emails owners about doses coming due. The batch is stamped
+// in the round's tx and only mailed once that commit went through.
+func SendVaccinationReminders(vaccination services.IVaccinationService) Job {
+	var due []*entities.VaccinationDueModel
+	return Job{
+		Name:     "vaccination-reminders",
+		Interval: time.Hour,
+		Run: func(ctx context.Context, tx *repositories.Tx) (err error) {
+			due, err = vaccination.ClaimDueRemindersTx(tx, time.Now())
+			return err
+		},
+		AfterCommit: func(ctx context.Context) error {
+			return vaccination.SendReminders(due)
+		},
+	}
+}
@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"lama-backend/domain/repositories"
+)
+
+// Job is one periodic task. Run queues its writes on tx and the scheduler commits
+// them behind the job's advisory lock, so with several instances running only one
+// of them applies a given round. Side effects outside the database (emails) belong
+// in AfterCommit, which only runs on the instance whose round was committed.
+type Job struct {
+	Name        string
+	Interval    time.Duration
+	Run         func(ctx context.Context, tx *repositories.Tx) error
+	AfterCommit func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	unitOfWork repositories.IUnitOfWork
+	jobLock    repositories.IJobLockRepository
+	jobs       []Job
+
+	mu      sync.Mutex
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewScheduler(unitOfWork repositories.IUnitOfWork, jobLock repositories.IJobLockRepository) *Scheduler {
+	return &Scheduler{
+		unitOfWork: unitOfWork,
+		jobLock:    jobLock,
+	}
+}
+
+// Register adds a job. Jobs registered after Start are ignored.
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		log.Printf("scheduler: %s registered after start, ignored", job.Name)
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start runs every job once and then on its interval until Shutdown.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Shutdown stops the tickers and waits for running jobs to finish, or for ctx to expire.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunOnce(ctx, job); err != nil {
+			log.Printf("scheduler: %s: %v", job.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs a single round of the job. Losing the lock to another instance is not an error.
+func (s *Scheduler) RunOnce(ctx context.Context, job Job) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	tx := s.unitOfWork.Begin()
+	s.jobLock.TryLockTx(tx, job.Name)
+	if err := job.Run(ctx, tx); err != nil {
+		return err
+	}
+
+	err := s.unitOfWork.Commit(tx)
+	if errors.Is(err, repositories.ErrJobLocked) {
+		return nil
+	}
+	if err != nil || job.AfterCommit == nil {
+		return err
+	}
+
+	return job.AfterCommit(ctx)
+}
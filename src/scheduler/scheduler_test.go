@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"lama-backend/domain/repositories"
+	"lama-backend/src/services/mocks"
+)
+
+func TestScheduler_RunOnce(t *testing.T) {
+	tests := []struct {
+		name      string
+		commitErr error
+		wantErr   bool
+	}{
+		{name: "lock acquired and writes committed"},
+		{name: "another instance holds the lock", commitErr: repositories.ErrJobLocked},
+		{name: "commit fails", commitErr: errors.New("db down"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUow := mocks.NewMockIUnitOfWork(ctrl)
+			mockLock := mocks.NewMockIJobLockRepository(ctrl)
+			s := NewScheduler(mockUow, mockLock)
+
+			tx := &repositories.Tx{}
+			ran := false
+			job := Job{Name: "sweep", Interval: time.Minute, Run: func(_ context.Context, got *repositories.Tx) error {
+				if got != tx {
+					t.Fatalf("job must write on the locked tx")
+				}
+				ran = true
+				return nil
+			}}
+
+			gomock.InOrder(
+				mockUow.EXPECT().Begin().Return(tx),
+				mockLock.EXPECT().TryLockTx(tx, "sweep"),
+				mockUow.EXPECT().Commit(tx).Return(tt.commitErr),
+			)
+
+			err := s.RunOnce(context.Background(), job)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wantErr %v got %v", tt.wantErr, err)
+			}
+			if !ran {
+				t.Fatalf("job did not run")
+			}
+		})
+	}
+}
+
+func TestScheduler_RunOnce_JobError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUow := mocks.NewMockIUnitOfWork(ctrl)
+	mockLock := mocks.NewMockIJobLockRepository(ctrl)
+	s := NewScheduler(mockUow, mockLock)
+
+	tx := &repositories.Tx{}
+	mockUow.EXPECT().Begin().Return(tx)
+	mockLock.EXPECT().TryLockTx(tx, "broken")
+	// no Commit: a failing job must not apply half of its writes
+
+	job := Job{Name: "broken", Interval: time.Minute, Run: func(context.Context, *repositories.Tx) error {
+		return errors.New("boom")
+	}}
+	if err := s.RunOnce(context.Background(), job); err == nil {
+		t.Fatalf("expected job error")
+	}
+}
+
+func TestScheduler_RunOnce_AfterCommit(t *testing.T) {
+	tests := []struct {
+		name      string
+		commitErr error
+		wantAfter bool
+	}{
+		{name: "runs once the round is committed", wantAfter: true},
+		{name: "skipped when another instance has the round", commitErr: repositories.ErrJobLocked},
+		{name: "skipped when commit fails", commitErr: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUow := mocks.NewMockIUnitOfWork(ctrl)
+			mockLock := mocks.NewMockIJobLockRepository(ctrl)
+			s := NewScheduler(mockUow, mockLock)
+
+			tx := &repositories.Tx{}
+			mockUow.EXPECT().Begin().Return(tx)
+			mockLock.EXPECT().TryLockTx(tx, "notify")
+			mockUow.EXPECT().Commit(tx).Return(tt.commitErr)
+
+			after := false
+			job := Job{
+				Name: "notify", Interval: time.Minute,
+				Run:         func(context.Context, *repositories.Tx) error { return nil },
+				AfterCommit: func(context.Context) error { after = true; return nil },
+			}
+			s.RunOnce(context.Background(), job)
+			if after != tt.wantAfter {
+				t.Fatalf("AfterCommit ran = %v, want %v", after, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestScheduler_Shutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUow := mocks.NewMockIUnitOfWork(ctrl)
+	mockLock := mocks.NewMockIJobLockRepository(ctrl)
+	s := NewScheduler(mockUow, mockLock)
+
+	mockUow.EXPECT().Begin().Return(&repositories.Tx{}).AnyTimes()
+	mockLock.EXPECT().TryLockTx(gomock.Any(), "tick").AnyTimes()
+	mockUow.EXPECT().Commit(gomock.Any()).Return(nil).AnyTimes()
+
+	var runs int32
+	s.Register(Job{Name: "tick", Interval: 10 * time.Millisecond, Run: func(context.Context, *repositories.Tx) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}})
+	s.Start()
+	time.Sleep(35 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	stopped := atomic.LoadInt32(&runs)
+	if stopped == 0 {
+		t.Fatalf("job never ran")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&runs) != stopped {
+		t.Fatalf("job kept running after shutdown")
+	}
+}
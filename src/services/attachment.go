@@ -0,0 +1,318 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/storage"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAttachmentForbidden = errors.New("not allowed to access the files of this pet")
+	ErrInvalidAttachment   = errors.New("invalid attachment")
+	ErrAttachmentTooLarge  = errors.New("file is too large")
+	ErrUnsupportedFileType = errors.New("file type is not allowed")
+)
+
+const (
+	maxPhotoSize    = 5 << 20
+	maxDocumentSize = 10 << 20
+	// signed url อายุเท่านี้ถ้าไม่ได้ตั้ง ATTACHMENT_URL_TTL_MINUTES
+	defaultAttachmentURLTTL = 15 * time.Minute
+	storageTimeout          = time.Minute
+)
+
+// ชนิดไฟล์ดูจากเนื้อไฟล์ ไม่เชื่อ Content-Type ที่ client ส่งมา
+// value คือนามสกุลที่ใช้ตั้งชื่อใน storage
+var attachmentTypes = map[db.AttachmentKind]map[string]string{
+	db.AttachmentKindPhoto: {
+		"image/jpeg": ".jpg",
+		"image/png":  ".png",
+		"image/webp": ".webp",
+	},
+	db.AttachmentKindDocument: {
+		"application/pdf": ".pdf",
+		"image/jpeg":      ".jpg",
+		"image/png":       ".png",
+	},
+}
+
+type AttachmentService struct {
+	Repo        repositories.IAttachmentRepository
+	PetRepo     repositories.IPetRepository
+	ServiceRepo repositories.IServiceRepository
+	Store       storage.ObjectStore
+	URLTTL      time.Duration
+}
+
+type IAttachmentService interface {
+	UploadPetAttachment(petID, userID, role string, kind db.AttachmentKind, file entities.AttachmentUpload) (*entities.AttachmentModel, error)
+	UploadServiceAttachment(serviceID, userID, role string, file entities.AttachmentUpload) (*entities.AttachmentModel, error)
+	FindPetAttachments(petID, userID, role string, kind *db.AttachmentKind) ([]*entities.AttachmentModel, error)
+	FindServiceAttachments(serviceID, userID, role string) ([]*entities.AttachmentModel, error)
+	DeleteAttachment(petID, attachmentID, userID, role string) error
+}
+
+func NewAttachmentService(
+	repo repositories.IAttachmentRepository,
+	petRepo repositories.IPetRepository,
+	serviceRepo repositories.IServiceRepository,
+	store storage.ObjectStore,
+) IAttachmentService {
+	ttl := defaultAttachmentURLTTL
+	if minutes, err := strconv.Atoi(os.Getenv("ATTACHMENT_URL_TTL_MINUTES")); err == nil && minutes > 0 {
+		ttl = time.Duration(minutes) * time.Minute
+	}
+	return &AttachmentService{
+		Repo:        repo,
+		PetRepo:     petRepo,
+		ServiceRepo: serviceRepo,
+		Store:       store,
+		URLTTL:      ttl,
+	}
+}
+
+// UploadPetAttachment adds a photo to the gallery of the pet or a document to its files.
+// Photos come from the owner, documents from the owner or a doctor who has treated the pet.
+// Admins may upload either.
+func (s *AttachmentService) UploadPetAttachment(petID, userID, role string, kind db.AttachmentKind, file entities.AttachmentUpload) (*entities.AttachmentModel, error) {
+	if _, ok := attachmentTypes[kind]; !ok {
+		return nil, fmt.Errorf("attachment -> UploadPetAttachment: %w: unknown kind %q", ErrInvalidAttachment, kind)
+	}
+	pet, err := s.PetRepo.FindPetByID(petID)
+	if err != nil {
+		return nil, err
+	}
+	if kind == db.AttachmentKindPhoto && role == string(db.RoleDoctor) {
+		return nil, fmt.Errorf("attachment -> UploadPetAttachment: %w", ErrAttachmentForbidden)
+	}
+	if err := s.checkPetAccess(pet, userID, role); err != nil {
+		return nil, fmt.Errorf("attachment -> UploadPetAttachment: %w", err)
+	}
+
+	return s.upload(entities.AttachmentModel{
+		PetID:      petID,
+		Kind:       kind,
+		UploadedBy: userID,
+	}, file)
+}
+
+// UploadServiceAttachment attaches a document (lab result, referral) to a service. The owner
+// of the pet and the doctor assigned to the service can attach, admins too.
+func (s *AttachmentService) UploadServiceAttachment(serviceID, userID, role string, file entities.AttachmentUpload) (*entities.AttachmentModel, error) {
+	service, err := s.ServiceRepo.FindByID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkServiceAccess(service, userID, role); err != nil {
+		return nil, fmt.Errorf("attachment -> UploadServiceAttachment: %w", err)
+	}
+
+	return s.upload(entities.AttachmentModel{
+		PetID:      service.PetID,
+		ServiceID:  &service.Sid,
+		Kind:       db.AttachmentKindDocument,
+		UploadedBy: userID,
+	}, file)
+}
+
+// FindPetAttachments lists the files of the pet newest first, the ones attached to its
+// services included, each with a signed URL.
+func (s *AttachmentService) FindPetAttachments(petID, userID, role string, kind *db.AttachmentKind) ([]*entities.AttachmentModel, error) {
+	pet, err := s.PetRepo.FindPetByID(petID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPetAccess(pet, userID, role); err != nil {
+		return nil, fmt.Errorf("attachment -> FindPetAttachments: %w", err)
+	}
+
+	attachments, err := s.Repo.FindByPetID(petID, kind)
+	if err != nil {
+		return nil, err
+	}
+	return s.withURLs(attachments)
+}
+
+func (s *AttachmentService) FindServiceAttachments(serviceID, userID, role string) ([]*entities.AttachmentModel, error) {
+	service, err := s.ServiceRepo.FindByID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkServiceAccess(service, userID, role); err != nil {
+		return nil, fmt.Errorf("attachment -> FindServiceAttachments: %w", err)
+	}
+
+	attachments, err := s.Repo.FindByServiceID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withURLs(attachments)
+}
+
+// DeleteAttachment removes a file. Only who uploaded it, or an admin, can delete.
+func (s *AttachmentService) DeleteAttachment(petID, attachmentID, userID, role string) error {
+	attachment, err := s.Repo.FindByID(attachmentID)
+	if err != nil {
+		return err
+	}
+	if attachment.PetID != petID {
+		return fmt.Errorf("attachment -> DeleteAttachment: %w", db.ErrNotFound)
+	}
+	if role != string(db.RoleAdmin) && attachment.UploadedBy != userID {
+		return fmt.Errorf("attachment -> DeleteAttachment: %w", ErrAttachmentForbidden)
+	}
+
+	if err := s.Repo.Delete(attachmentID); err != nil {
+		return err
+	}
+	// แถวหายแล้วไฟล์ไม่มีใครเข้าถึงได้อีก ลบไม่สำเร็จก็แค่ค้างใน bucket
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	if err := s.Store.Delete(ctx, attachment.StorageKey); err != nil {
+		log.Printf("attachment -> DeleteAttachment: %s left in storage: %v", attachment.StorageKey, err)
+	}
+
+	return nil
+}
+
+func (s *AttachmentService) upload(data entities.AttachmentModel, file entities.AttachmentUpload) (*entities.AttachmentModel, error) {
+	maxSize := int64(maxPhotoSize)
+	if data.Kind == db.AttachmentKindDocument {
+		maxSize = maxDocumentSize
+	}
+	contentType, ext, body, err := checkUpload(file, attachmentTypes[data.Kind], maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("attachment -> upload: %w", err)
+	}
+
+	data.FileName = attachmentFileName(file.FileName, ext)
+	data.ContentType = contentType
+	data.Size = int(file.Size)
+	data.StorageKey = fmt.Sprintf("pets/%s/%s%s", data.PetID, uuid.NewString(), ext)
+
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	if err := s.Store.Put(ctx, data.StorageKey, body, file.Size, contentType); err != nil {
+		return nil, fmt.Errorf("attachment -> upload: %v", err)
+	}
+
+	created, err := s.Repo.Insert(data)
+	if err != nil {
+		if delErr := s.Store.Delete(ctx, data.StorageKey); delErr != nil {
+			log.Printf("attachment -> upload: %s left in storage: %v", data.StorageKey, delErr)
+		}
+		return nil, err
+	}
+	if err := s.signURL(ctx, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *AttachmentService) withURLs(attachments []*entities.AttachmentModel) ([]*entities.AttachmentModel, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	for _, attachment := range attachments {
+		if err := s.signURL(ctx, attachment); err != nil {
+			return nil, err
+		}
+	}
+	return attachments, nil
+}
+
+func (s *AttachmentService) signURL(ctx context.Context, attachment *entities.AttachmentModel) error {
+	url, err := s.Store.SignedURL(ctx, attachment.StorageKey, s.URLTTL)
+	if err != nil {
+		return fmt.Errorf("attachment -> signURL: %v", err)
+	}
+	attachment.URL = url
+	attachment.URLExpiresAt = time.Now().Add(s.URLTTL)
+	return nil
+}
+
+func (s *AttachmentService) checkPetAccess(pet *entities.PetDataModel, userID, role string) error {
+	ok, err := canReadPet(s.ServiceRepo, pet, userID, role)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAttachmentForbidden
+	}
+	return nil
+}
+
+func (s *AttachmentService) checkServiceAccess(service *entities.ServiceModel, userID, role string) error {
+	switch role {
+	case string(db.RoleAdmin):
+		return nil
+	case string(db.RoleOwner):
+		if service.OwnerID == userID {
+			return nil
+		}
+	case string(db.RoleDoctor):
+		if service.ServiceType == "mservice" && service.StaffID == userID {
+			return nil
+		}
+	}
+	return ErrAttachmentForbidden
+}
+
+// checkUpload validates the size and the real type of an uploaded file against allowed
+// (content type -> extension) and returns a body that starts from the beginning again.
+func checkUpload(file entities.AttachmentUpload, allowed map[string]string, maxSize int64) (string, string, io.Reader, error) {
+	if file.Size <= 0 {
+		return "", "", nil, fmt.Errorf("%w: file is empty", ErrInvalidAttachment)
+	}
+	if file.Size > maxSize {
+		return "", "", nil, fmt.Errorf("%w (max %d MB)", ErrAttachmentTooLarge, maxSize>>20)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file.Body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", "", nil, err
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	ext, ok := allowed[contentType]
+	if !ok {
+		return "", "", nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, contentType)
+	}
+
+	var body io.Reader = io.LimitReader(io.MultiReader(bytes.NewReader(head), file.Body), file.Size)
+	// ไฟล์จาก form อ่านซ้ำได้ storage จะได้ส่งใหม่ตอน retry
+	if readerAt, ok := file.Body.(io.ReaderAt); ok {
+		body = io.NewSectionReader(readerAt, 0, file.Size)
+	}
+	return contentType, ext, body, nil
+}
+
+// attachmentFileName keeps the name the user sees, the extension follows the real type.
+func attachmentFileName(name, ext string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		name = "file"
+	}
+	if runes := []rune(name); len(runes) > 200 {
+		name = string(runes[:200])
+	}
+	return name + ext
+}
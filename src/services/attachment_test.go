@@ -0,0 +1,184 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/services/mocks"
+	"lama-backend/src/storage"
+)
+
+func TestAttachmentService_Upload(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 600)...)
+	pdf := append([]byte("%PDF-1.7\n"), bytes.Repeat([]byte("x"), 600)...)
+	file := func(name string, content []byte) entities.AttachmentUpload {
+		return entities.AttachmentUpload{FileName: name, Size: int64(len(content)), Body: bytes.NewReader(content)}
+	}
+	pet := &entities.PetDataModel{PetID: "pet-1", OwnerID: "owner-1"}
+
+	newService := func(t *testing.T, ctrl *gomock.Controller) (*AttachmentService, *mocks.MockIAttachmentRepository, *mocks.MockIPetRepository, *mocks.MockIServiceRepository, string) {
+		root := t.TempDir()
+		mockRepo := mocks.NewMockIAttachmentRepository(ctrl)
+		mockPet := mocks.NewMockIPetRepository(ctrl)
+		mockService := mocks.NewMockIServiceRepository(ctrl)
+		sv := &AttachmentService{
+			Repo: mockRepo, PetRepo: mockPet, ServiceRepo: mockService,
+			Store:  storage.NewLocalStore(root, "pet-files", "http://localhost/api/v1/files", "secret"),
+			URLTTL: 10 * time.Minute,
+		}
+		return sv, mockRepo, mockPet, mockService, root
+	}
+
+	t.Run("owner adds a photo, the type comes from the content", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockRepo, mockPet, _, root := newService(t, ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(pet, nil)
+		mockRepo.EXPECT().Insert(gomock.Any()).DoAndReturn(func(data entities.AttachmentModel) (*entities.AttachmentModel, error) {
+			if data.ContentType != "image/png" || data.FileName != "Whiskers 1.png" || data.Size != len(png) || data.UploadedBy != "owner-1" {
+				t.Fatalf("unexpected attachment %+v", data)
+			}
+			if !strings.HasPrefix(data.StorageKey, "pets/pet-1/") || !strings.HasSuffix(data.StorageKey, ".png") {
+				t.Fatalf("unexpected key %s", data.StorageKey)
+			}
+			data.ID = "a1"
+			return &data, nil
+		})
+
+		got, err := sv.UploadPetAttachment("pet-1", "owner-1", "owner", db.AttachmentKindPhoto, file("Whiskers 1.jpeg", png))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		stored, err := os.ReadFile(filepath.Join(root, "pet-files", filepath.FromSlash(got.StorageKey)))
+		if err != nil || !bytes.Equal(stored, png) {
+			t.Fatalf("file not stored as uploaded: %v", err)
+		}
+		if !strings.Contains(got.URL, "signature=") || got.URLExpiresAt.Before(time.Now().Add(9*time.Minute)) {
+			t.Fatalf("expected a signed url, got %s %v", got.URL, got.URLExpiresAt)
+		}
+	})
+
+	t.Run("pdf renamed as a photo is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, _, mockPet, _, root := newService(t, ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(pet, nil)
+
+		_, err := sv.UploadPetAttachment("pet-1", "owner-1", "owner", db.AttachmentKindPhoto, file("cat.jpg", pdf))
+		if !errors.Is(err, ErrUnsupportedFileType) {
+			t.Fatalf("expected ErrUnsupportedFileType, got %v", err)
+		}
+		if entries, _ := os.ReadDir(root); len(entries) != 0 {
+			t.Fatalf("nothing should be stored, got %v", entries)
+		}
+	})
+
+	t.Run("document over the size limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, _, mockPet, _, _ := newService(t, ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(pet, nil)
+		big := file("lab.pdf", pdf)
+		big.Size = maxDocumentSize + 1
+
+		if _, err := sv.UploadPetAttachment("pet-1", "admin-1", "admin", db.AttachmentKindDocument, big); !errors.Is(err, ErrAttachmentTooLarge) {
+			t.Fatalf("expected ErrAttachmentTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("doctor who never treated the pet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, _, mockPet, mockService, _ := newService(t, ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(pet, nil)
+		mockService.EXPECT().FindByPetID("pet-1", timelineStatuses).Return([]*entities.ServiceModel{
+			{Sid: "s1", ServiceType: "mservice", StaffID: "doc-2", Status: db.ServiceStatusFinish},
+		}, nil)
+
+		if _, err := sv.UploadPetAttachment("pet-1", "doc-1", "doctor", db.AttachmentKindDocument, file("lab.pdf", pdf)); !errors.Is(err, ErrAttachmentForbidden) {
+			t.Fatalf("expected ErrAttachmentForbidden, got %v", err)
+		}
+	})
+
+	t.Run("assigned doctor attaches a lab result to the visit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockRepo, _, mockService, _ := newService(t, ctrl)
+
+		mockService.EXPECT().FindByID("s1").Return(&entities.ServiceModel{
+			Sid: "s1", PetID: "pet-1", OwnerID: "owner-1", ServiceType: "mservice", StaffID: "doc-1",
+		}, nil)
+		mockRepo.EXPECT().Insert(gomock.Any()).DoAndReturn(func(data entities.AttachmentModel) (*entities.AttachmentModel, error) {
+			if data.Kind != db.AttachmentKindDocument || *data.ServiceID != "s1" || data.PetID != "pet-1" || data.ContentType != "application/pdf" {
+				t.Fatalf("unexpected attachment %+v", data)
+			}
+			return &data, nil
+		})
+
+		if _, err := sv.UploadServiceAttachment("s1", "doc-1", "doctor", file("blood test.pdf", pdf)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("stored file is removed when the row cannot be saved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockRepo, mockPet, _, root := newService(t, ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(pet, nil)
+		mockRepo.EXPECT().Insert(gomock.Any()).Return(nil, errors.New("db down"))
+
+		if _, err := sv.UploadPetAttachment("pet-1", "owner-1", "owner", db.AttachmentKindPhoto, file("cat.png", png)); err == nil {
+			t.Fatalf("expected insert error")
+		}
+		files, _ := filepath.Glob(filepath.Join(root, "pet-files", "pets", "pet-1", "*"))
+		if len(files) != 0 {
+			t.Fatalf("expected no files left, got %v", files)
+		}
+	})
+}
+
+func TestAttachmentService_DeleteAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	root := t.TempDir()
+	store := storage.NewLocalStore(root, "pet-files", "http://localhost/api/v1/files", "secret")
+	mockRepo := mocks.NewMockIAttachmentRepository(ctrl)
+	sv := &AttachmentService{Repo: mockRepo, Store: store}
+
+	key := "pets/pet-1/photo.png"
+	if err := store.Put(t.Context(), key, strings.NewReader("png"), 3, "image/png"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	attachment := &entities.AttachmentModel{ID: "a1", PetID: "pet-1", UploadedBy: "owner-1", StorageKey: key}
+	mockRepo.EXPECT().FindByID("a1").Return(attachment, nil).Times(3)
+
+	if err := sv.DeleteAttachment("pet-2", "a1", "owner-1", "owner"); !errors.Is(err, db.ErrNotFound) {
+		t.Fatalf("expected not found for another pet, got %v", err)
+	}
+	if err := sv.DeleteAttachment("pet-1", "a1", "doc-1", "doctor"); !errors.Is(err, ErrAttachmentForbidden) {
+		t.Fatalf("expected ErrAttachmentForbidden, got %v", err)
+	}
+
+	mockRepo.EXPECT().Delete("a1").Return(nil)
+	if err := sv.DeleteAttachment("pet-1", "a1", "owner-1", "owner"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "pet-files", "pets", "pet-1", "photo.png")); !os.IsNotExist(err) {
+		t.Fatalf("expected the file to be removed, got %v", err)
+	}
+}
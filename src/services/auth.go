@@ -1,11 +1,41 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
 	"lama-backend/domain/repositories"
 	"lama-backend/src/middlewares"
 	"lama-backend/src/utils"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken  = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused   = errors.New("refresh token was already used, the session has been closed")
+	ErrSessionRevoked       = errors.New("session has been revoked")
+	ErrInvalidResetToken    = errors.New("reset password link is invalid, expired or already used")
+	ErrTooManyResetRequests = errors.New("too many reset password requests, try again later")
+	ErrRoleNotGranted       = errors.New("account does not have this role")
+	ErrRoleAlreadyGranted   = errors.New("account already has this role")
+	ErrEmailTaken           = repositories.ErrEmailTaken
+)
+
+const (
+	// refresh token อายุเท่านี้ถ้าไม่ได้ตั้ง JWT_REFRESH_TTL_DAYS นับใหม่ทุกครั้งที่ refresh
+	defaultRefreshTTL = 30 * 24 * time.Hour
+	maxDeviceLength   = 200
+	// ขอ reset password ได้ไม่เกินเท่านี้ต่อชั่วโมง นับทั้งอีเมลที่ไม่มีในระบบด้วย
+	resetRequestWindow      = time.Hour
+	maxResetRequestsPerMail = 3
+	maxResetRequestsPerIP   = 10
 )
 
 type authService struct {
@@ -13,21 +43,65 @@ type authService struct {
 	OwnerRepository     repositories.IOwnerRepository
 	CaretakerRepository repositories.ICaretakerRepository
 	DoctorRepository    repositories.IDoctorRepository
+	SessionRepository   repositories.ISessionRepository
+	PasswordResetRepo   repositories.IPasswordResetRepository
+	MfaRepository       repositories.IMfaRepository
+	UnitOfWork          repositories.IUnitOfWork
+	RefreshTTL          time.Duration
+	MfaKey              []byte
+	MfaIssuer           string
 }
 
 type IAuthService interface {
 	CheckToken(td *middlewares.TokenDetails) error
 	Register(role string, data entities.CreatedUserModel) (*entities.UserDataModel, error)
 	Login(role string, data entities.LoginUserRequestModel) (*entities.LoginUserResponseModel, error)
-	ValidateEmailAndRole(data *entities.SendEmailModel) (string, error)
+	AddRole(userID, role string, data entities.AddRoleRequest) (*entities.UserDataModel, error)
+	SwitchRole(td *middlewares.TokenDetails, role, device string) (*middlewares.TokenDetails, error)
+	RequestPasswordReset(data entities.SendEmailModel, ip string) (*middlewares.TokenDetails, error)
+	ResetPassword(token, passwordHash string) error
+	PurgePasswordResetsTx(tx *repositories.Tx, now time.Time)
+	StartSession(userID, role, device string) (*middlewares.TokenDetails, error)
+	BeginSession(userID, role, device string) (*middlewares.TokenDetails, error)
+	MfaStatus(userID string) (*entities.MfaStatusModel, error)
+	SetupMfa(userID string) (*entities.MfaSetupModel, error)
+	EnableMfa(td *middlewares.TokenDetails, code, device string) ([]string, *middlewares.TokenDetails, error)
+	VerifyMfa(td *middlewares.TokenDetails, code, device string) (*middlewares.TokenDetails, error)
+	DisableMfa(userID, code string) error
+	RegenerateRecoveryCodes(userID, code string) ([]string, error)
+	ResetMfa(userID string) error
+	FindMfaRequirements() ([]*entities.MfaRequirementModel, error)
+	RequireMfa(role, adminID string) (*entities.MfaRequirementModel, int, error)
+	UnrequireMfa(role string) (bool, error)
+	RefreshSession(refreshToken string) (*middlewares.TokenDetails, error)
+	CheckSession(td *middlewares.TokenDetails) error
+	Logout(td *middlewares.TokenDetails) error
+	LogoutAll(userID string) (int, error)
+	PurgeExpiredSessionsTx(tx *repositories.Tx, now time.Time)
 }
 
-func NewAuthService(repoUsers repositories.IUsersRepository, repoOwner repositories.IOwnerRepository, repoCaretaker repositories.ICaretakerRepository, repoDoctor repositories.IDoctorRepository) IAuthService {
+func NewAuthService(repoUsers repositories.IUsersRepository, repoOwner repositories.IOwnerRepository, repoCaretaker repositories.ICaretakerRepository, repoDoctor repositories.IDoctorRepository, repoSession repositories.ISessionRepository, repoPasswordReset repositories.IPasswordResetRepository, repoMfa repositories.IMfaRepository, unitOfWork repositories.IUnitOfWork) IAuthService {
+	ttl := defaultRefreshTTL
+	if days, err := strconv.Atoi(os.Getenv("JWT_REFRESH_TTL_DAYS")); err == nil && days > 0 {
+		ttl = time.Duration(days) * 24 * time.Hour
+	}
+	issuer := os.Getenv("MFA_ISSUER")
+	if issuer == "" {
+		issuer = "Lama"
+	}
 	return &authService{
 		UsersRepository:     repoUsers,
 		OwnerRepository:     repoOwner,
 		CaretakerRepository: repoCaretaker,
 		DoctorRepository:    repoDoctor,
+		SessionRepository:   repoSession,
+		PasswordResetRepo:   repoPasswordReset,
+		MfaRepository:       repoMfa,
+		UnitOfWork:          unitOfWork,
+		RefreshTTL:          ttl,
+		// ไม่ตั้งไว้ก็ยัง login ได้ปกติ แค่เปิด 2FA ไม่ได้
+		MfaKey:    []byte(os.Getenv("MFA_SECRET_KEY")),
+		MfaIssuer: issuer,
 	}
 }
 
@@ -44,52 +118,344 @@ func (sv *authService) Register(role string, data entities.CreatedUserModel) (*e
 		return nil, err
 	}
 
+	roleData, err := sv.insertRoleData(userData.UserID, role, entities.AddRoleRequest{
+		LicenseNumber:  data.LicenseNumber,
+		Specialization: data.Specialization,
+	})
+	if err != nil {
+		return nil, err
+	}
+	userData.LicenseNumber = roleData.LicenseNumber
+	userData.Specialization = roleData.Specialization
+	userData.TotalSpending = roleData.TotalSpending
+	return userData, nil
+}
+
+// insertRoleData สร้างแถว Owner/Caretaker/Doctor ของ user admin ไม่มีตารางของตัวเอง
+func (sv *authService) insertRoleData(userID, role string, data entities.AddRoleRequest) (*entities.UserDataModel, error) {
 	roleData := &entities.UserDataModel{}
+	var err error
 	switch role {
 	case "admin":
-		roleData.UserID = userData.UserID
+		roleData.UserID = userID
 	case "doctor":
-		roleData, err = sv.DoctorRepository.InsertDoctor(userData.UserID, data.LicenseNumber)
-		if err != nil {
-			return nil, err
-		}
+		roleData, err = sv.DoctorRepository.InsertDoctor(userID, data.LicenseNumber)
 	case "caretaker":
-		roleData, err = sv.CaretakerRepository.InsertCaretaker(userData.UserID, data.Specialization)
-		if err != nil {
-			return nil, err
-		}
+		roleData, err = sv.CaretakerRepository.InsertCaretaker(userID, data.Specialization)
 	case "owner":
-		roleData, err = sv.OwnerRepository.InsertOwner(userData.UserID)
-		if err != nil {
-			return nil, err
-		}
+		roleData, err = sv.OwnerRepository.InsertOwner(userID)
 	default:
 		return nil, fmt.Errorf("role is required")
 	}
-	if userData.UserID != roleData.UserID {
+	if err != nil {
+		return nil, err
+	}
+	if userID != roleData.UserID {
 		return nil, fmt.Errorf("invalid foreign key user_id")
 	}
-	userData.LicenseNumber = roleData.LicenseNumber
-	userData.Specialization = roleData.Specialization
-	userData.TotalSpending = roleData.TotalSpending
-	return userData, nil
+	return roleData, nil
 }
 
+// hasRoleData บอกว่าแถวของ role มีอยู่แล้วไหม เช่นรอบก่อนสร้างแถวได้แต่ใส่ role ไม่สำเร็จ
+func (sv *authService) hasRoleData(userID, role string) bool {
+	var err error
+	switch role {
+	case "doctor":
+		_, err = sv.DoctorRepository.FindByID(userID)
+	case "caretaker":
+		_, err = sv.CaretakerRepository.FindByID(userID)
+	case "owner":
+		_, err = sv.OwnerRepository.FindByID(userID)
+	default:
+		return false
+	}
+	return err == nil
+}
+
+// Login checks the password once for the whole account. An empty role logs in with the
+// role the account registered with, otherwise the account must hold the role asked for.
 func (sv *authService) Login(role string, data entities.LoginUserRequestModel) (*entities.LoginUserResponseModel, error) {
-	userData, err := sv.UsersRepository.FindByEmailAndRole(data.Email, role)
+	userData, err := sv.UsersRepository.FindByEmail(data.Email)
 	if err != nil {
 		return nil, err
 	}
 	if !utils.CheckPasswordHash(data.Password, userData.Password) {
 		return nil, fmt.Errorf("invalid password")
 	}
+	if role != "" {
+		// เช็คหลังรหัสผ่าน คนไม่รู้รหัสจะได้ไม่รู้ว่าบัญชีนี้มี role อะไรบ้าง
+		if !slices.Contains(userData.Roles, db.Role(role)) {
+			return nil, ErrRoleNotGranted
+		}
+		userData.Role = db.Role(role)
+	}
 	return userData, nil
 }
 
-func (sv *authService) ValidateEmailAndRole(data *entities.SendEmailModel) (string, error) {
-	userData, err := sv.UsersRepository.FindByEmailAndRole(data.Email, string(data.Role))
+// AddRole gives an existing account one more role under the same email and password.
+func (sv *authService) AddRole(userID, role string, data entities.AddRoleRequest) (*entities.UserDataModel, error) {
+	user, err := sv.UsersRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(user.Roles, db.Role(role)) {
+		return nil, ErrRoleAlreadyGranted
+	}
+
+	if !sv.hasRoleData(userID, role) {
+		if _, err := sv.insertRoleData(userID, role, data); err != nil {
+			return nil, err
+		}
+	}
+	added, err := sv.UsersRepository.AddRole(userID, db.Role(role))
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, ErrRoleAlreadyGranted
+	}
+
+	return sv.UsersRepository.FindByID(userID)
+}
+
+// SwitchRole swaps the session of the token for a new one acting as another role of the
+// same account. The old access and refresh tokens stop working.
+func (sv *authService) SwitchRole(td *middlewares.TokenDetails, role, device string) (*middlewares.TokenDetails, error) {
+	user, err := sv.UsersRepository.FindByID(td.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(user.Roles, db.Role(role)) {
+		return nil, ErrRoleNotGranted
+	}
+
+	current, err := sv.SessionRepository.FindByID(td.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !current.MfaVerified {
+		required, err := sv.mfaRequired(db.Role(role))
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			return nil, ErrMfaRequired
+		}
+	}
+
+	// เปิด session ใหม่ก่อนค่อยปิดอันเดิม ถ้าพังกลางทางจะได้ไม่หลุด login
+	token, err := sv.openSession(td.UserID, role, device, current.MfaVerified)
+	if err != nil {
+		return nil, err
+	}
+	if err := sv.Logout(td); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// RequestPasswordReset records the request and returns the token for the reset email.
+// The token is nil when no account matches, callers must answer the same way in both
+// cases so the endpoint cannot be used to find out who has an account.
+func (sv *authService) RequestPasswordReset(data entities.SendEmailModel, ip string) (*middlewares.TokenDetails, error) {
+	email := strings.ToLower(strings.TrimSpace(data.Email))
+	count, err := sv.PasswordResetRepo.CountSince(email, ip, time.Now().Add(-resetRequestWindow))
+	if err != nil {
+		return nil, err
+	}
+	if count.ByEmail >= maxResetRequestsPerMail || count.ByIP >= maxResetRequestsPerIP {
+		return nil, ErrTooManyResetRequests
+	}
+
+	request := entities.PasswordResetModel{
+		Email:     email,
+		IP:        ip,
+		ExpiresAt: time.Now().Add(middlewares.ResetPasswordTokenTTL),
+	}
+	user, err := sv.UsersRepository.FindByEmail(email)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+	if user != nil {
+		request.UserID = &user.UserID
+		request.PasswordFingerprint = passwordFingerprint(user.Password)
+	}
+
+	reset, err := sv.PasswordResetRepo.Insert(request)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	return middlewares.GenerateResetPasswordJWTToken(user.UserID, string(user.Role), "reset_password", reset.ID)
+}
+
+// ResetPassword sets the new password with a reset token. A token only works once, and
+// only while the password is still the one it was issued for. The token is used up in
+// the same tx as the password change and the logout, so a failed update keeps the link.
+func (sv *authService) ResetPassword(token, passwordHash string) error {
+	td, err := middlewares.DecodeResetPasswordJWTToken(token)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	reset, err := sv.PasswordResetRepo.FindByID(td.TokenID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if reset.UserID == nil || *reset.UserID != td.UserID || reset.UsedAt != nil || !reset.ExpiresAt.After(time.Now()) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := sv.UsersRepository.FindByID(td.UserID)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	if passwordFingerprint(user.Password) != reset.PasswordFingerprint {
+		return ErrInvalidResetToken
+	}
+
+	tx := sv.UnitOfWork.Begin()
+	sv.PasswordResetRepo.MarkUsedTx(tx, reset.ID)
+	sv.UsersRepository.UpdatePasswordTx(tx, td.UserID, passwordHash)
+	sv.SessionRepository.RevokeByUserIDTx(tx, td.UserID)
+	if err := sv.UnitOfWork.Commit(tx); err != nil {
+		if errors.Is(err, repositories.ErrResetTokenUsed) {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("auth -> ResetPassword: %w", err)
+	}
+	return nil
+}
+
+// แถวเก่ากว่า window ของ rate limit ไม่ได้ใช้แล้ว token ก็หมดอายุไปนานแล้ว
+func (sv *authService) PurgePasswordResetsTx(tx *repositories.Tx, now time.Time) {
+	sv.PasswordResetRepo.DeleteBeforeTx(tx, now.Add(-resetRequestWindow))
+}
+
+// StartSession opens a session for one device and returns an access token bound to it
+// together with the first refresh token.
+func (sv *authService) StartSession(userID, role, device string) (*middlewares.TokenDetails, error) {
+	return sv.openSession(userID, role, device, false)
+}
+
+// openSession is StartSession for callers that know whether the login passed 2FA.
+func (sv *authService) openSession(userID, role, device string, mfaVerified bool) (*middlewares.TokenDetails, error) {
+	refreshToken, hash, err := middlewares.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if len(device) > maxDeviceLength {
+		device = device[:maxDeviceLength]
+	}
+
+	session, err := sv.SessionRepository.Insert(entities.SessionModel{
+		UserID:      userID,
+		Role:        db.Role(role),
+		Device:      device,
+		RefreshHash: hash,
+		ExpiresAt:   time.Now().Add(sv.RefreshTTL),
+		MfaVerified: mfaVerified,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionToken(session, refreshToken)
+}
+
+// RefreshSession trades a refresh token for a new access token and a new refresh token.
+// Showing the token that was already traded means two parties hold it, so the session is
+// closed for both of them.
+func (sv *authService) RefreshSession(refreshToken string) (*middlewares.TokenDetails, error) {
+	hash := middlewares.HashRefreshToken(refreshToken)
+	session, err := sv.SessionRepository.FindByTokenHash(hash)
 	if err != nil {
-		return "", err
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if session.RevokedAt != nil || !session.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if session.RefreshHash != hash {
+		if err := sv.SessionRepository.Revoke(session.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	nextToken, nextHash, err := middlewares.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	session.ExpiresAt = time.Now().Add(sv.RefreshTTL)
+	rotated, err := sv.SessionRepository.Rotate(session.ID, hash, nextHash, session.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// อีก request หมุน token ไปก่อนแล้ว
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return sessionToken(session, nextToken)
+}
+
+// CheckSession is run for every access token. Tokens without a session (issued before
+// sessions existed) are refused as well.
+func (sv *authService) CheckSession(td *middlewares.TokenDetails) error {
+	if td.SessionID == "" {
+		return ErrSessionRevoked
 	}
-	return userData.UserID, err
+	session, err := sv.SessionRepository.FindByID(td.SessionID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+	if session.RevokedAt != nil || !session.ExpiresAt.After(time.Now()) ||
+		session.UserID != td.UserID || string(session.Role) != td.Role {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+func (sv *authService) Logout(td *middlewares.TokenDetails) error {
+	if td.SessionID == "" {
+		return ErrSessionRevoked
+	}
+	return sv.SessionRepository.Revoke(td.SessionID)
+}
+
+// LogoutAll closes every session of the user, the current one included.
+func (sv *authService) LogoutAll(userID string) (int, error) {
+	return sv.SessionRepository.RevokeByUserID(userID)
+}
+
+func (sv *authService) PurgeExpiredSessionsTx(tx *repositories.Tx, now time.Time) {
+	sv.SessionRepository.DeleteExpiredTx(tx, now)
+}
+
+func sessionToken(session *entities.SessionModel, refreshToken string) (*middlewares.TokenDetails, error) {
+	td, err := middlewares.GenerateJWTToken(session.UserID, string(session.Role), "access", session.ID)
+	if err != nil {
+		return nil, err
+	}
+	refreshExpiresIn := session.ExpiresAt.Unix()
+	td.RefreshToken = &refreshToken
+	td.RefreshExpiresIn = &refreshExpiresIn
+	return td, nil
+}
+
+// เก็บแค่ hash ของ hash รหัสผ่าน พอให้รู้ว่ารหัสผ่านเปลี่ยนไปหรือยัง
+func passwordFingerprint(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(sum[:])
 }
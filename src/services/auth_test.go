@@ -6,6 +6,7 @@ import (
     "time"
 
     "lama-backend/domain/entities"
+    "lama-backend/domain/prisma/db"
     "lama-backend/src/services/mocks"
     "lama-backend/src/utils"
 
@@ -36,39 +37,65 @@ func TestAuthService_Login(t *testing.T) {
         mockErr  error
         wantErr  bool
         wantMsg  string
+        wantRole db.Role
     }{
         {
             name:  "success (owner)", // <-- เปลี่ยนชื่อเล็กน้อย
             email: "a@b.com", role: "owner", pass: plain,
-            mockResp: &entities.LoginUserResponseModel{UserID: "u1", Password: hashed},
-            mockErr:  nil, wantErr: false,
+            mockResp: &entities.LoginUserResponseModel{UserID: "u1", Password: hashed, Role: db.RoleOwner, Roles: []db.Role{db.RoleOwner}},
+            mockErr:  nil, wantErr: false, wantRole: db.RoleOwner,
         },
         
         // --- โค้ดที่เพิ่มเข้ามา ---
         {
             name:  "success (admin)",
             email: "admin@lama.com", role: "admin", pass: plain,
-            mockResp: &entities.LoginUserResponseModel{UserID: "u-admin", Password: hashed},
-            mockErr:  nil, wantErr: false,
+            mockResp: &entities.LoginUserResponseModel{UserID: "u-admin", Password: hashed, Role: db.RoleAdmin, Roles: []db.Role{db.RoleAdmin}},
+            mockErr:  nil, wantErr: false, wantRole: db.RoleAdmin,
         },
         {
             name:  "success (doctor)",
             email: "doc@lama.com", role: "doctor", pass: plain,
-            mockResp: &entities.LoginUserResponseModel{UserID: "u-doc", Password: hashed},
-            mockErr:  nil, wantErr: false,
+            mockResp: &entities.LoginUserResponseModel{UserID: "u-doc", Password: hashed, Role: db.RoleDoctor, Roles: []db.Role{db.RoleDoctor}},
+            mockErr:  nil, wantErr: false, wantRole: db.RoleDoctor,
         },
         {
             name:  "success (caretaker)",
             email: "care@lama.com", role: "caretaker", pass: plain,
-            mockResp: &entities.LoginUserResponseModel{UserID: "u-care", Password: hashed},
-            mockErr:  nil, wantErr: false,
+            mockResp: &entities.LoginUserResponseModel{UserID: "u-care", Password: hashed, Role: db.RoleCaretaker, Roles: []db.Role{db.RoleCaretaker}},
+            mockErr:  nil, wantErr: false, wantRole: db.RoleCaretaker,
         },
         // --- จบส่วนที่เพิ่ม ---
 
+        {
+            name:  "owner who is also a caretaker logs in as caretaker",
+            email: "both@lama.com", role: "caretaker", pass: plain,
+            mockResp: &entities.LoginUserResponseModel{UserID: "u-both", Password: hashed, Role: db.RoleOwner, Roles: []db.Role{db.RoleOwner, db.RoleCaretaker}},
+            wantRole: db.RoleCaretaker,
+        },
+        {
+            name:  "no role logs in with the registered role",
+            email: "both@lama.com", role: "", pass: plain,
+            mockResp: &entities.LoginUserResponseModel{UserID: "u-both", Password: hashed, Role: db.RoleOwner, Roles: []db.Role{db.RoleOwner, db.RoleCaretaker}},
+            wantRole: db.RoleOwner,
+        },
+        {
+            name:  "role the account does not have",
+            email: "a@b.com", role: "doctor", pass: plain,
+            mockResp: &entities.LoginUserResponseModel{UserID: "u1", Password: hashed, Role: db.RoleOwner, Roles: []db.Role{db.RoleOwner}},
+            wantErr: true, wantMsg: ErrRoleNotGranted.Error(),
+        },
+        {
+            name:  "wrong password does not tell which roles exist",
+            email: "a@b.com", role: "doctor", pass: "wrong",
+            mockResp: &entities.LoginUserResponseModel{UserID: "u1", Password: hashed, Role: db.RoleOwner, Roles: []db.Role{db.RoleOwner}},
+            wantErr: true, wantMsg: "invalid password",
+        },
+
         {
             name:  "invalid password",
             email: "a@b.com", role: "owner", pass: "wrong",
-            mockResp: &entities.LoginUserResponseModel{UserID: "u1", Password: hashed},
+            mockResp: &entities.LoginUserResponseModel{UserID: "u1", Password: hashed, Role: db.RoleOwner, Roles: []db.Role{db.RoleOwner}},
             mockErr:  nil, wantErr: true, wantMsg: "invalid password",
         },
         {
@@ -81,9 +108,9 @@ func TestAuthService_Login(t *testing.T) {
     for _, tc := range tests {
         tc := tc
         t.Run(tc.name, func(t *testing.T) {
-            // Mock จะถูกตั้งค่าตามค่าใน tc (เช่น tc.email, tc.role) ของแต่ละรอบ
+            // Mock จะถูกตั้งค่าตามค่าใน tc (เช่น tc.email) ของแต่ละรอบ
             mockUsers.EXPECT().
-                FindByEmailAndRole(tc.email, tc.role).
+                FindByEmail(tc.email).
                 Return(tc.mockResp, tc.mockErr).
                 Times(1)
 
@@ -107,6 +134,9 @@ func TestAuthService_Login(t *testing.T) {
             if got.UserID != tc.mockResp.UserID {
                 t.Fatalf("unexpected user id: want %s got %s", tc.mockResp.UserID, got.UserID)
             }
+            if got.Role != tc.wantRole {
+                t.Fatalf("unexpected role: want %s got %s", tc.wantRole, got.Role)
+            }
         })
     }
 }
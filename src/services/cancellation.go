@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrServiceNotCancellable is returned for bookings that are already finished or cancelled.
+var ErrServiceNotCancellable = errors.New("service can no longer be cancelled")
+
+// RefundPolicy decides how much of a paid booking goes back to the owner on cancellation.
+type RefundPolicy struct {
+	// ยกเลิกก่อนเริ่มอย่างน้อยเท่านี้ได้คืนเต็ม
+	FullRefundBefore time.Duration
+	// ยกเลิกช้ากว่านั้นแต่ยังไม่เริ่มงาน ได้คืนกี่ %
+	LatePercent int
+}
+
+func loadRefundPolicy() RefundPolicy {
+	policy := RefundPolicy{FullRefundBefore: 24 * time.Hour, LatePercent: 50}
+
+	if hours, err := strconv.Atoi(os.Getenv("REFUND_FULL_BEFORE_HOURS")); err == nil && hours >= 0 {
+		policy.FullRefundBefore = time.Duration(hours) * time.Hour
+	}
+	if percent, err := strconv.Atoi(os.Getenv("REFUND_LATE_PERCENT")); err == nil && percent >= 0 && percent <= 100 {
+		policy.LatePercent = percent
+	}
+
+	return policy
+}
+
+// RefundPercent returns the share of the price to refund. Once the staff has
+// started (ongoing) nothing is refunded.
+func (p RefundPolicy) RefundPercent(status db.ServiceStatus, start, now time.Time) int {
+	if status != db.ServiceStatusWait {
+		return 0
+	}
+	if start.Sub(now) >= p.FullRefundBefore {
+		return 100
+	}
+	return p.LatePercent
+}
+
+// PlanCancellation works out the refund for cancelling the booking and saves it as a
+// pending cancellation before any money moves. A retry (Stripe or the commit failed last
+// time) gets the pending amount back instead of a new one, so the refund's idempotency
+// key always goes out with the same amount.
+func (s *ServiceService) PlanCancellation(service *entities.ServiceModel, actorID string, actorRole string, data entities.CancelServiceRequest) (*entities.ServiceCancellationModel, error) {
+	if service.Status != db.ServiceStatusWait && service.Status != db.ServiceStatusOngoing {
+		return nil, fmt.Errorf("service -> PlanCancellation: %w (status %s)", ErrServiceNotCancellable, service.Status)
+	}
+
+	pending, err := s.CancellationRepo.FindPendingByServiceID(service.Sid)
+	if err == nil {
+		pending.PaymentID = service.PaymentID
+		pending.OwnerID = service.OwnerID
+		pending.FromStatus = service.Status
+		return pending, nil
+	}
+	if !errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("service -> PlanCancellation: %w", err)
+	}
+
+	payment, err := s.PaymentRepo.FindByID(service.PaymentID)
+	if err != nil {
+		return nil, fmt.Errorf("service -> PlanCancellation: %w", err)
+	}
+
+	percent := s.RefundPolicy.RefundPercent(service.Status, service.ReserveDateStart, time.Now())
+	if data.FullRefund && actorRole == string(db.RoleAdmin) {
+		percent = 100
+	}
+	// คืนได้เฉพาะเงินที่จ่ายแล้วและยังไม่ได้คืน (รวมส่วนต่างจาก reschedule)
+	balance := paidBalance(payment)
+	if balance == 0 {
+		percent = 0
+	}
+	amount := balance * percent / 100
+
+	cancellation, err := s.CancellationRepo.InsertPending(entities.ServiceCancellationModel{
+		ServiceID:       service.Sid,
+		PaymentID:       service.PaymentID,
+		FromStatus:      service.Status,
+		OwnerID:         service.OwnerID,
+		CancelledBy:     actorID,
+		CancelledByRole: db.Role(actorRole),
+		Reason:          data.Reason,
+		RefundPercent:   percent,
+		RefundAmount:    amount,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service -> PlanCancellation: %w", err)
+	}
+
+	return cancellation, nil
+}
+
+// CancelService finishes the cancellation planned by PlanCancellation once the
+// Stripe refunds (if any) went through. Status, payments, total_spending and the
+// audit row are written in one transaction.
+func (s *ServiceService) CancelService(cancellation *entities.ServiceCancellationModel) (*entities.ServiceModel, error) {
+	cancellation.CreatedAt = time.Now()
+	tx := s.UnitOfWork.Begin()
+	s.CancellationRepo.CompleteTx(tx, *cancellation)
+	// status ต้องยังเป็น FromStatus ถ้ายกเลิกซ้ำพร้อมกัน transaction ที่สองจะล้มทั้งก้อน
+	s.changeStatusTx(tx, cancellation.ServiceID, cancellation.FromStatus, db.ServiceStatusCancelled, &cancellation.CancelledBy, &cancellation.CancelledByRole, cancellation.Reason)
+	if err := s.applyRefundsTx(tx, cancellation.OwnerID, cancellation.Refunds); err != nil {
+		return nil, fmt.Errorf("service -> CancelService: %w", err)
+	}
+	s.StaffHoldRepo.DeleteByPaymentIDTx(tx, cancellation.PaymentID)
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("service -> CancelService: %w", err)
+	}
+
+	return s.FindServiceByID(cancellation.ServiceID)
+}
+
+// CancellationPending reports whether the booking of paymentID (or of the payment the
+// adjustment paymentID belongs to) has a refund planned that CancelService hasn't
+// recorded yet. Stripe events for that refund are left for CancelService to apply.
+func (s *ServiceService) CancellationPending(paymentID string) (bool, error) {
+	return s.CancellationRepo.HasPendingByPaymentID(paymentID)
+}
+
+// applyRefundsTx writes refunds already made on Stripe back onto the payments and
+// takes them off the owner's total_spending.
+func (s *ServiceService) applyRefundsTx(tx *repositories.Tx, ownerID string, refunds []entities.PaymentRefundModel) error {
+	total := 0
+	for _, refund := range refunds {
+		update := entities.PaymentModel{RefundedAmount: refund.RefundedAmount}
+		if refund.FullyRefunded {
+			update.Status = db.PaymentStatusRefunded
+		}
+		if err := s.PaymentRepo.UpdateByIDTx(tx, refund.PaymentID, update); err != nil {
+			return err
+		}
+		total += refund.Amount
+	}
+	if total > 0 {
+		s.OwnerRepo.AddTotalSpendingTx(tx, ownerID, decimal.NewFromInt(int64(-total)))
+	}
+
+	return nil
+}
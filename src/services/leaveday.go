@@ -1,33 +1,185 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
 	"lama-backend/domain/repositories"
+	"lama-backend/src/utils"
+	"os"
+	"strconv"
 	"time"
+
+	"github.com/google/uuid"
+)
+
+// ขอลาได้ยาวสุดกี่วันต่อหนึ่ง request
+const maxLeaveDays = 60
+
+var (
+	ErrInvalidLeave        = errors.New("invalid leave request")
+	ErrLeaveNotPending     = errors.New("leave request is not pending")
+	ErrLeaveNotCancellable = errors.New("leave request can no longer be cancelled")
+	ErrNotLeaveOwner       = errors.New("leave request belongs to another staff")
+	// ErrLeaveHasBookings: ต้อง reassign หรือยกเลิก booking ในวันนั้นก่อนถึงจะลาได้
+	ErrLeaveHasBookings = repositories.ErrLeaveHasBookings
+	ErrLeaveOverlap     = repositories.ErrLeaveOverlap
 )
 
 type leavedayService struct {
-	repo repositories.ILeavedayRepository
+	repo            repositories.ILeavedayRepository
+	unitOfWork      repositories.IUnitOfWork
+	requireApproval bool
 }
 
 type ILeavedayService interface {
-	InsertLeaveday(staffId, role string, leaveday time.Time) (*entities.LeavedayModel, error)
+	RequestLeave(staffID, role string, data entities.CreateLeaveRequest) (*entities.LeaveRequestModel, error)
+	FindLeave(filter entities.LeaveFilter) ([]*entities.LeaveRequestModel, error)
+	FindLeaveByDay(day time.Time) ([]*entities.LeaveRequestModel, error)
+	CancelLeave(requestID, userID, role string) (*entities.LeaveRequestModel, error)
+	ReviewLeave(requestID, adminID string, approve bool) (*entities.LeaveRequestModel, error)
 }
 
-func NewLeavedayService(repo repositories.ILeavedayRepository) ILeavedayService {
+func NewLeavedayService(repo repositories.ILeavedayRepository, unitOfWork repositories.IUnitOfWork) ILeavedayService {
+	// LEAVE_REQUIRES_APPROVAL=true ให้ admin อนุมัติก่อน ไม่งั้นลาได้ทันที
+	requireApproval, _ := strconv.ParseBool(os.Getenv("LEAVE_REQUIRES_APPROVAL"))
 	return &leavedayService{
-		repo: repo,
+		repo:            repo,
+		unitOfWork:      unitOfWork,
+		requireApproval: requireApproval,
 	}
 }
 
-func (sv *leavedayService) InsertLeaveday(staffId, role string, leaveday time.Time) (*entities.LeavedayModel, error) {
-	switch role {
-	case "caretaker":
-		return sv.repo.InsertCaretakerLeaveday(staffId, leaveday)
-	case "doctor":
-		return sv.repo.InsertDoctorLeaveday(staffId, leaveday)
-	default:
+// RequestLeave books every day from start to end date. Days that already have bookings
+// or another pending/approved leave make the whole request fail.
+func (sv *leavedayService) RequestLeave(staffID, role string, data entities.CreateLeaveRequest) (*entities.LeaveRequestModel, error) {
+	if role != "caretaker" && role != "doctor" {
 		return nil, fmt.Errorf("service layer -> invalid role")
 	}
+
+	startDate, err := time.Parse(time.DateOnly, data.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("leaveday -> RequestLeave: %w: invalid start_date", ErrInvalidLeave)
+	}
+	endDate, err := time.Parse(time.DateOnly, data.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("leaveday -> RequestLeave: %w: invalid end_date", ErrInvalidLeave)
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("leaveday -> RequestLeave: %w: end_date is before start_date", ErrInvalidLeave)
+	}
+	if startDate.Before(businessDate(time.Now())) {
+		return nil, fmt.Errorf("leaveday -> RequestLeave: %w: start_date is in the past", ErrInvalidLeave)
+	}
+
+	days := []time.Time{}
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	if len(days) > maxLeaveDays {
+		return nil, fmt.Errorf("leaveday -> RequestLeave: %w: at most %d days per request", ErrInvalidLeave, maxLeaveDays)
+	}
+
+	status := db.LeaveStatusApproved
+	if sv.requireApproval {
+		status = db.LeaveStatusPending
+	}
+	request := entities.LeaveRequestModel{
+		RequestID: uuid.NewString(),
+		StaffID:   staffID,
+		StaffType: role,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Days:      days,
+		Status:    status,
+		Reason:    data.Reason,
+	}
+
+	// booking เทียบกับทั้งวันตามเวลาไทย เหมือนวันลาใน staffBusySQL ไม่ใช่เที่ยงคืน UTC
+	from := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, utils.BusinessLocation)
+	to := time.Date(endDate.Year(), endDate.Month(), endDate.Day()+1, 0, 0, 0, 0, utils.BusinessLocation)
+
+	tx := sv.unitOfWork.Begin()
+	sv.repo.GuardRangeTx(tx, staffID, startDate, endDate, from, to)
+	sv.repo.InsertRequestTx(tx, request)
+	if err := sv.unitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("leaveday -> RequestLeave: %w", err)
+	}
+
+	return sv.repo.FindRequestByID(request.RequestID)
+}
+
+func (sv *leavedayService) FindLeave(filter entities.LeaveFilter) ([]*entities.LeaveRequestModel, error) {
+	return sv.repo.FindRequests(filter)
+}
+
+// FindLeaveByDay lists everyone that is off (or waiting for approval) on the day.
+func (sv *leavedayService) FindLeaveByDay(day time.Time) ([]*entities.LeaveRequestModel, error) {
+	requests, err := sv.repo.FindRequests(entities.LeaveFilter{From: &day, To: &day})
+	if err != nil {
+		return nil, err
+	}
+
+	active := []*entities.LeaveRequestModel{}
+	for _, request := range requests {
+		if request.Status == db.LeaveStatusPending || request.Status == db.LeaveStatusApproved {
+			active = append(active, request)
+		}
+	}
+	return active, nil
+}
+
+// CancelLeave lets staff withdraw their own leave before it starts. Admins can cancel any
+// active leave, e.g. to free the rest of a leave that already started.
+func (sv *leavedayService) CancelLeave(requestID, userID, role string) (*entities.LeaveRequestModel, error) {
+	request, err := sv.repo.FindRequestByID(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != string(db.RoleAdmin) {
+		if request.StaffID != userID {
+			return nil, fmt.Errorf("leaveday -> CancelLeave: %w", ErrNotLeaveOwner)
+		}
+		if request.StartDate.Before(businessDate(time.Now())) {
+			return nil, fmt.Errorf("leaveday -> CancelLeave: %w: leave already started", ErrLeaveNotCancellable)
+		}
+	}
+	if request.Status != db.LeaveStatusPending && request.Status != db.LeaveStatusApproved {
+		return nil, fmt.Errorf("leaveday -> CancelLeave: %w (status %s)", ErrLeaveNotCancellable, request.Status)
+	}
+
+	tx := sv.unitOfWork.Begin()
+	sv.repo.UpdateStatusTx(tx, requestID, []db.LeaveStatus{db.LeaveStatusPending, db.LeaveStatusApproved}, db.LeaveStatusCancelled, nil, nil)
+	if err := sv.unitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("leaveday -> CancelLeave: %w", err)
+	}
+
+	return sv.repo.FindRequestByID(requestID)
+}
+
+// ReviewLeave approves or rejects a pending request. Pending leave already blocks the
+// staff's calendar, so approving doesn't need to check for bookings again.
+func (sv *leavedayService) ReviewLeave(requestID, adminID string, approve bool) (*entities.LeaveRequestModel, error) {
+	request, err := sv.repo.FindRequestByID(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != db.LeaveStatusPending {
+		return nil, fmt.Errorf("leaveday -> ReviewLeave: %w (status %s)", ErrLeaveNotPending, request.Status)
+	}
+
+	status := db.LeaveStatusRejected
+	if approve {
+		status = db.LeaveStatusApproved
+	}
+	now := time.Now()
+	tx := sv.unitOfWork.Begin()
+	sv.repo.UpdateStatusTx(tx, requestID, []db.LeaveStatus{db.LeaveStatusPending}, status, &adminID, &now)
+	if err := sv.unitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("leaveday -> ReviewLeave: %w", err)
+	}
+
+	return sv.repo.FindRequestByID(requestID)
 }
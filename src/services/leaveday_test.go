@@ -0,0 +1,231 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/services/mocks"
+	"lama-backend/src/utils"
+)
+
+func TestLeavedayService_RequestLeave(t *testing.T) {
+	start := time.Now().UTC().AddDate(0, 0, 3)
+	startDate := start.Format(time.DateOnly)
+	endDate := start.AddDate(0, 0, 2).Format(time.DateOnly)
+
+	tests := []struct {
+		name            string
+		requireApproval bool
+		wantStatus      db.LeaveStatus
+	}{
+		{name: "approved right away", wantStatus: db.LeaveStatusApproved},
+		{name: "waits for admin", requireApproval: true, wantStatus: db.LeaveStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockILeavedayRepository(ctrl)
+			mockUow := mocks.NewMockIUnitOfWork(ctrl)
+			sv := &leavedayService{repo: mockRepo, unitOfWork: mockUow, requireApproval: tt.requireApproval}
+
+			tx := &repositories.Tx{}
+			var requestID string
+			mockUow.EXPECT().Begin().Return(tx)
+			mockRepo.EXPECT().GuardRangeTx(tx, "care-1", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			mockRepo.EXPECT().InsertRequestTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, r entities.LeaveRequestModel) {
+				if len(r.Days) != 3 || r.Status != tt.wantStatus || r.StaffType != "caretaker" {
+					t.Fatalf("unexpected request %+v", r)
+				}
+				requestID = r.RequestID
+			})
+			mockUow.EXPECT().Commit(tx).Return(nil)
+			mockRepo.EXPECT().FindRequestByID(gomock.Any()).DoAndReturn(func(id string) (*entities.LeaveRequestModel, error) {
+				if id != requestID {
+					t.Fatalf("want %s got %s", requestID, id)
+				}
+				return &entities.LeaveRequestModel{RequestID: id, Status: tt.wantStatus}, nil
+			})
+
+			leave, err := sv.RequestLeave("care-1", "caretaker", entities.CreateLeaveRequest{StartDate: startDate, EndDate: endDate})
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if leave.Status != tt.wantStatus {
+				t.Fatalf("want %s got %s", tt.wantStatus, leave.Status)
+			}
+		})
+	}
+
+	t.Run("bookings are checked by Thai calendar day", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockILeavedayRepository(ctrl)
+		mockUow := mocks.NewMockIUnitOfWork(ctrl)
+		sv := &leavedayService{repo: mockRepo, unitOfWork: mockUow}
+
+		first, _ := time.Parse(time.DateOnly, startDate)
+		last, _ := time.Parse(time.DateOnly, endDate)
+		tx := &repositories.Tx{}
+		mockUow.EXPECT().Begin().Return(tx)
+		mockRepo.EXPECT().GuardRangeTx(tx, "care-1", first, last, gomock.Any(), gomock.Any()).
+			Do(func(_ *repositories.Tx, _ string, _, _, from, to time.Time) {
+				// 00:00 ที่กรุงเทพ = 17:00 UTC ของวันก่อน booking 03:00 วันแรกต้องติด 03:00 วันถัดจากช่วงต้องไม่ติด
+				if want := first.Add(-7 * time.Hour); !from.Equal(want) {
+					t.Fatalf("from: want %v got %v", want, from)
+				}
+				if want := last.AddDate(0, 0, 1).Add(-7 * time.Hour); !to.Equal(want) {
+					t.Fatalf("to: want %v got %v", want, to)
+				}
+				earlyFirstDay := time.Date(first.Year(), first.Month(), first.Day(), 3, 0, 0, 0, utils.BusinessLocation)
+				earlyDayAfter := time.Date(last.Year(), last.Month(), last.Day()+1, 3, 0, 0, 0, utils.BusinessLocation)
+				if earlyFirstDay.Before(from) || !earlyDayAfter.After(to) {
+					t.Fatalf("window %v..%v should cover %v and not %v", from, to, earlyFirstDay, earlyDayAfter)
+				}
+			})
+		mockRepo.EXPECT().InsertRequestTx(tx, gomock.Any())
+		mockUow.EXPECT().Commit(tx).Return(nil)
+		mockRepo.EXPECT().FindRequestByID(gomock.Any()).Return(&entities.LeaveRequestModel{}, nil)
+
+		if _, err := sv.RequestLeave("care-1", "caretaker", entities.CreateLeaveRequest{StartDate: startDate, EndDate: endDate}); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("days with bookings", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockILeavedayRepository(ctrl)
+		mockUow := mocks.NewMockIUnitOfWork(ctrl)
+		sv := &leavedayService{repo: mockRepo, unitOfWork: mockUow}
+
+		tx := &repositories.Tx{}
+		mockUow.EXPECT().Begin().Return(tx)
+		mockRepo.EXPECT().GuardRangeTx(tx, "doc-1", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+		mockRepo.EXPECT().InsertRequestTx(tx, gomock.Any())
+		mockUow.EXPECT().Commit(tx).Return(repositories.ErrLeaveHasBookings)
+
+		_, err := sv.RequestLeave("doc-1", "doctor", entities.CreateLeaveRequest{StartDate: startDate, EndDate: endDate})
+		if !errors.Is(err, ErrLeaveHasBookings) {
+			t.Fatalf("want ErrLeaveHasBookings got %v", err)
+		}
+	})
+
+	invalid := []struct {
+		name string
+		data entities.CreateLeaveRequest
+	}{
+		{name: "end before start", data: entities.CreateLeaveRequest{StartDate: endDate, EndDate: startDate}},
+		{name: "in the past", data: entities.CreateLeaveRequest{StartDate: "2020-01-01", EndDate: "2020-01-02"}},
+		{name: "too long", data: entities.CreateLeaveRequest{StartDate: startDate, EndDate: start.AddDate(0, 0, maxLeaveDays).Format(time.DateOnly)}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			sv := &leavedayService{}
+			if _, err := sv.RequestLeave("care-1", "caretaker", tt.data); !errors.Is(err, ErrInvalidLeave) {
+				t.Fatalf("want ErrInvalidLeave got %v", err)
+			}
+		})
+	}
+}
+
+func TestLeavedayService_CancelLeave(t *testing.T) {
+	future := startOfDay(time.Now()).AddDate(0, 0, 5)
+	past := startOfDay(time.Now()).AddDate(0, 0, -1)
+
+	tests := []struct {
+		name    string
+		leave   entities.LeaveRequestModel
+		userID  string
+		role    string
+		wantErr error
+	}{
+		{name: "own future leave", leave: entities.LeaveRequestModel{StaffID: "care-1", StartDate: future, Status: db.LeaveStatusApproved}, userID: "care-1", role: "caretaker"},
+		{name: "someone else's leave", leave: entities.LeaveRequestModel{StaffID: "care-2", StartDate: future, Status: db.LeaveStatusApproved}, userID: "care-1", role: "caretaker", wantErr: ErrNotLeaveOwner},
+		{name: "already started", leave: entities.LeaveRequestModel{StaffID: "care-1", StartDate: past, Status: db.LeaveStatusApproved}, userID: "care-1", role: "caretaker", wantErr: ErrLeaveNotCancellable},
+		{name: "admin cancels started leave", leave: entities.LeaveRequestModel{StaffID: "care-1", StartDate: past, Status: db.LeaveStatusPending}, userID: "admin-1", role: "admin"},
+		{name: "already rejected", leave: entities.LeaveRequestModel{StaffID: "care-1", StartDate: future, Status: db.LeaveStatusRejected}, userID: "care-1", role: "caretaker", wantErr: ErrLeaveNotCancellable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockILeavedayRepository(ctrl)
+			mockUow := mocks.NewMockIUnitOfWork(ctrl)
+			sv := &leavedayService{repo: mockRepo, unitOfWork: mockUow}
+
+			leave := tt.leave
+			mockRepo.EXPECT().FindRequestByID("r1").Return(&leave, nil)
+			if tt.wantErr == nil {
+				tx := &repositories.Tx{}
+				mockUow.EXPECT().Begin().Return(tx)
+				mockRepo.EXPECT().UpdateStatusTx(tx, "r1", []db.LeaveStatus{db.LeaveStatusPending, db.LeaveStatusApproved}, db.LeaveStatusCancelled, nil, nil)
+				mockUow.EXPECT().Commit(tx).Return(nil)
+				mockRepo.EXPECT().FindRequestByID("r1").Return(&entities.LeaveRequestModel{Status: db.LeaveStatusCancelled}, nil)
+			}
+
+			_, err := sv.CancelLeave("r1", tt.userID, tt.role)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want %v got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		})
+	}
+}
+
+func TestLeavedayService_ReviewLeave(t *testing.T) {
+	t.Run("approve pending", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockILeavedayRepository(ctrl)
+		mockUow := mocks.NewMockIUnitOfWork(ctrl)
+		sv := &leavedayService{repo: mockRepo, unitOfWork: mockUow}
+
+		tx := &repositories.Tx{}
+		mockRepo.EXPECT().FindRequestByID("r1").Return(&entities.LeaveRequestModel{Status: db.LeaveStatusPending}, nil)
+		mockUow.EXPECT().Begin().Return(tx)
+		mockRepo.EXPECT().UpdateStatusTx(tx, "r1", []db.LeaveStatus{db.LeaveStatusPending}, db.LeaveStatusApproved, gomock.Any(), gomock.Any()).
+			Do(func(_ *repositories.Tx, _ string, _ []db.LeaveStatus, _ db.LeaveStatus, reviewedBy *string, reviewedAt *time.Time) {
+				if reviewedBy == nil || *reviewedBy != "admin-1" || reviewedAt == nil {
+					t.Fatalf("reviewer not recorded")
+				}
+			})
+		mockUow.EXPECT().Commit(tx).Return(nil)
+		mockRepo.EXPECT().FindRequestByID("r1").Return(&entities.LeaveRequestModel{Status: db.LeaveStatusApproved}, nil)
+
+		if _, err := sv.ReviewLeave("r1", "admin-1", true); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("already reviewed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockILeavedayRepository(ctrl)
+		sv := &leavedayService{repo: mockRepo}
+
+		mockRepo.EXPECT().FindRequestByID("r1").Return(&entities.LeaveRequestModel{Status: db.LeaveStatusApproved}, nil)
+		if _, err := sv.ReviewLeave("r1", "admin-1", false); !errors.Is(err, ErrLeaveNotPending) {
+			t.Fatalf("want ErrLeaveNotPending got %v", err)
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNotMedicalService = errors.New("medical records are only kept for mservice")
+	ErrNotAssignedDoctor = errors.New("service is not assigned to you")
+	// เขียนได้ตั้งแต่หมอเริ่มตรวจ (ongoing) จนจบ ยกเลิก/ไม่มาไม่มีอะไรให้บันทึก
+	ErrMedicalRecordNotAllowed = errors.New("medical record can only be written for an ongoing or finished visit")
+	ErrInvalidMedicalRecord    = errors.New("invalid medical record")
+	ErrMedicalRecordForbidden  = errors.New("not allowed to read the medical records of this pet")
+)
+
+var (
+	maxPetWeight   = decimal.NewFromInt(1000)
+	minTemperature = decimal.NewFromInt(25)
+	maxTemperature = decimal.NewFromInt(45)
+)
+
+type MedicalRecordService struct {
+	RecordRepo   repositories.IMedicalRecordRepository
+	MedicineRepo repositories.IMedicineRepository
+	ServiceRepo  repositories.IServiceRepository
+	MserviceRepo repositories.IMServiceRepository
+	PetRepo      repositories.IPetRepository
+	WeightRepo   repositories.IPetWeightRepository
+	UnitOfWork   repositories.IUnitOfWork
+}
+
+type IMedicalRecordService interface {
+	SaveMedicalRecord(serviceID, doctorID string, data entities.SaveMedicalRecordRequest) (*entities.MedicalRecordModel, error)
+	FindMedicalRecord(serviceID string) (*entities.MedicalRecordModel, error)
+	FindPetMedicalRecords(petID, userID, role string) ([]*entities.MedicalRecordModel, error)
+}
+
+func NewMedicalRecordService(
+	recordRepo repositories.IMedicalRecordRepository,
+	medicineRepo repositories.IMedicineRepository,
+	serviceRepo repositories.IServiceRepository,
+	mserviceRepo repositories.IMServiceRepository,
+	petRepo repositories.IPetRepository,
+	weightRepo repositories.IPetWeightRepository,
+	unitOfWork repositories.IUnitOfWork,
+) IMedicalRecordService {
+	return &MedicalRecordService{
+		RecordRepo:   recordRepo,
+		MedicineRepo: medicineRepo,
+		ServiceRepo:  serviceRepo,
+		MserviceRepo: mserviceRepo,
+		PetRepo:      petRepo,
+		WeightRepo:   weightRepo,
+		UnitOfWork:   unitOfWork,
+	}
+}
+
+// SaveMedicalRecord writes (or rewrites) the visit notes of an mservice. Only the doctor
+// assigned to the service can write them, once the visit is ongoing or finished. The
+// diagnosis is copied to Mservice.disease, which older clients still read, and the weight
+// goes to the pet's weight history.
+func (s *MedicalRecordService) SaveMedicalRecord(serviceID, doctorID string, data entities.SaveMedicalRecordRequest) (*entities.MedicalRecordModel, error) {
+	service, err := s.ServiceRepo.FindByID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if service.ServiceType != "mservice" {
+		return nil, fmt.Errorf("medical record -> SaveMedicalRecord: %w", ErrNotMedicalService)
+	}
+	if service.StaffID != doctorID {
+		return nil, fmt.Errorf("medical record -> SaveMedicalRecord: %w", ErrNotAssignedDoctor)
+	}
+	if service.Status != db.ServiceStatusOngoing && service.Status != db.ServiceStatusFinish {
+		return nil, fmt.Errorf("medical record -> SaveMedicalRecord: %w (status %s)", ErrMedicalRecordNotAllowed, service.Status)
+	}
+
+	record, err := buildMedicalRecord(service, doctorID, data)
+	if err != nil {
+		return nil, fmt.Errorf("medical record -> SaveMedicalRecord: %w", err)
+	}
+
+	tx := s.UnitOfWork.Begin()
+	s.RecordRepo.UpsertTx(tx, *record)
+	s.MedicineRepo.ReplaceTx(tx, serviceID, record.Medicines)
+	s.MserviceRepo.UpdateDiseaseTx(tx, serviceID, record.Diagnosis)
+	if err := s.recordVitalsWeightTx(tx, service, doctorID, record.Weight); err != nil {
+		return nil, fmt.Errorf("medical record -> SaveMedicalRecord: %w", err)
+	}
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("medical record -> SaveMedicalRecord: %w", err)
+	}
+
+	return s.FindMedicalRecord(serviceID)
+}
+
+// recordVitalsWeightTx keeps one weight measurement per visit, dated at the visit. Pet.weight
+// follows it unless a newer measurement exists (an old record being edited).
+func (s *MedicalRecordService) recordVitalsWeightTx(tx *repositories.Tx, service *entities.ServiceModel, doctorID string, weight *db.Decimal) error {
+	if weight == nil {
+		s.WeightRepo.DeleteVitalsTx(tx, service.Sid)
+		return nil
+	}
+
+	latest, err := s.WeightRepo.FindLatest(service.PetID)
+	if err != nil {
+		return err
+	}
+	s.WeightRepo.UpsertVitalsTx(tx, entities.PetWeightModel{
+		PetID:      service.PetID,
+		Weight:     *weight,
+		Source:     db.PetWeightSourceVitals,
+		ServiceID:  &service.Sid,
+		RecordedBy: &doctorID,
+		MeasuredAt: service.ReserveDateStart,
+	})
+	if latest == nil || !latest.MeasuredAt.After(service.ReserveDateStart) ||
+		(latest.ServiceID != nil && *latest.ServiceID == service.Sid) {
+		s.PetRepo.UpdateWeightTx(tx, service.PetID, *weight)
+	}
+	return nil
+}
+
+func buildMedicalRecord(service *entities.ServiceModel, doctorID string, data entities.SaveMedicalRecordRequest) (*entities.MedicalRecordModel, error) {
+	record := &entities.MedicalRecordModel{
+		ServiceID:   service.Sid,
+		PetID:       service.PetID,
+		DoctorID:    doctorID,
+		Diagnosis:   strings.TrimSpace(data.Diagnosis),
+		Symptoms:    []string{},
+		Weight:      data.Weight,
+		Temperature: data.Temperature,
+		FollowUp:    data.FollowUp,
+		Medicines:   []entities.MedicineModel{},
+	}
+	if record.Diagnosis == "" {
+		return nil, fmt.Errorf("%w: diagnosis is empty", ErrInvalidMedicalRecord)
+	}
+	for _, symptom := range data.Symptoms {
+		if symptom = strings.TrimSpace(symptom); symptom != "" {
+			record.Symptoms = append(record.Symptoms, symptom)
+		}
+	}
+	if w := data.Weight; w != nil && (!w.IsPositive() || w.GreaterThanOrEqual(maxPetWeight)) {
+		return nil, fmt.Errorf("%w: weight must be between 0 and 1000 kg", ErrInvalidMedicalRecord)
+	}
+	if t := data.Temperature; t != nil && (t.LessThan(minTemperature) || t.GreaterThan(maxTemperature)) {
+		return nil, fmt.Errorf("%w: temperature must be between 25 and 45 °C", ErrInvalidMedicalRecord)
+	}
+	if data.FollowUpDate != nil {
+		followUpDate, err := time.Parse(time.DateOnly, *data.FollowUpDate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid follow_up_date", ErrInvalidMedicalRecord)
+		}
+		if followUpDate.Before(businessDate(service.ReserveDateStart)) {
+			return nil, fmt.Errorf("%w: follow_up_date is before the visit", ErrInvalidMedicalRecord)
+		}
+		record.FollowUpDate = &followUpDate
+	}
+
+	// ชื่อยาเป็น key ร่วมกับ SID ห้ามซ้ำในการรักษาเดียวกัน
+	seen := make(map[string]bool)
+	for _, medicine := range data.Medicines {
+		name := strings.TrimSpace(medicine.Name)
+		if name == "" || seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%w: medicine %q is empty or listed twice", ErrInvalidMedicalRecord, medicine.Name)
+		}
+		seen[strings.ToLower(name)] = true
+
+		dosage, frequency := medicine.Dosage, medicine.Frequency
+		record.Medicines = append(record.Medicines, entities.MedicineModel{
+			Name:         name,
+			Dosage:       &dosage,
+			Frequency:    &frequency,
+			DurationDays: medicine.DurationDays,
+			Instructions: medicine.Instructions,
+		})
+	}
+
+	return record, nil
+}
+
+func (s *MedicalRecordService) FindMedicalRecord(serviceID string) (*entities.MedicalRecordModel, error) {
+	record, err := s.RecordRepo.FindByServiceID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	medicines, err := s.MedicineRepo.FindByServiceID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if medicines != nil {
+		record.Medicines = medicines
+	}
+
+	return record, nil
+}
+
+// FindPetMedicalRecords lists the visits of a pet, newest first. Owners read their own
+// pets, doctors the pets they have treated, admins any pet.
+func (s *MedicalRecordService) FindPetMedicalRecords(petID, userID, role string) ([]*entities.MedicalRecordModel, error) {
+	pet, err := s.PetRepo.FindPetByID(petID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := canReadPet(s.ServiceRepo, pet, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("medical record -> FindPetMedicalRecords: %w", ErrMedicalRecordForbidden)
+	}
+
+	records, err := s.RecordRepo.FindByPetID(petID)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return records, nil
+	}
+
+	serviceIDs := make([]string, 0, len(records))
+	for _, record := range records {
+		serviceIDs = append(serviceIDs, record.ServiceID)
+	}
+	medicines, err := s.MedicineRepo.FindByServiceIDs(serviceIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if m, ok := medicines[record.ServiceID]; ok {
+			record.Medicines = m
+		}
+	}
+
+	return records, nil
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/services/mocks"
+)
+
+func TestMedicalRecordService_SaveMedicalRecord(t *testing.T) {
+	start := clockOn(scheduleMonday, 10, 0)
+	visit := func(status db.ServiceStatus) *entities.ServiceModel {
+		return &entities.ServiceModel{
+			Sid: "s1", PetID: "pet-1", StaffID: "doc-1", ServiceType: "mservice", Status: status,
+			ReserveDateStart: start, ReserveDateEnd: start.Add(time.Hour),
+		}
+	}
+	temperature := decimal.RequireFromString("38.5")
+	followUp := "2026-03-09"
+	req := entities.SaveMedicalRecordRequest{
+		Diagnosis:    " ear infection ",
+		Symptoms:     []string{"scratching", " "},
+		Temperature:  &temperature,
+		FollowUpDate: &followUp,
+		Medicines: []entities.MedicineRequest{
+			{Name: "Otomax", Dosage: "5 drops", Frequency: "twice a day"},
+		},
+	}
+
+	newService := func(ctrl *gomock.Controller) (*MedicalRecordService, *mocks.MockIServiceRepository, *mocks.MockIMedicalRecordRepository, *mocks.MockIMedicineRepository, *mocks.MockIMServiceRepository, *mocks.MockIUnitOfWork) {
+		mockService := mocks.NewMockIServiceRepository(ctrl)
+		mockRecord := mocks.NewMockIMedicalRecordRepository(ctrl)
+		mockMedicine := mocks.NewMockIMedicineRepository(ctrl)
+		mockMservice := mocks.NewMockIMServiceRepository(ctrl)
+		mockUow := mocks.NewMockIUnitOfWork(ctrl)
+		sv := &MedicalRecordService{
+			RecordRepo: mockRecord, MedicineRepo: mockMedicine, ServiceRepo: mockService,
+			MserviceRepo: mockMservice, UnitOfWork: mockUow,
+		}
+		return sv, mockService, mockRecord, mockMedicine, mockMservice, mockUow
+	}
+
+	t.Run("assigned doctor saves an ongoing visit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockService, mockRecord, mockMedicine, mockMservice, mockUow := newService(ctrl)
+
+		tx := &repositories.Tx{}
+		mockService.EXPECT().FindByID("s1").Return(visit(db.ServiceStatusOngoing), nil)
+		mockUow.EXPECT().Begin().Return(tx)
+		mockRecord.EXPECT().UpsertTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, record entities.MedicalRecordModel) {
+			if record.Diagnosis != "ear infection" || len(record.Symptoms) != 1 || record.PetID != "pet-1" {
+				t.Fatalf("unexpected record %+v", record)
+			}
+		})
+		mockMedicine.EXPECT().ReplaceTx(tx, "s1", gomock.Len(1))
+		mockMservice.EXPECT().UpdateDiseaseTx(tx, "s1", "ear infection")
+		// no weight this time, a weight saved earlier for the visit is dropped
+		mockWeight := mocks.NewMockIPetWeightRepository(ctrl)
+		sv.WeightRepo = mockWeight
+		mockWeight.EXPECT().DeleteVitalsTx(tx, "s1")
+		mockUow.EXPECT().Commit(tx).Return(nil)
+		mockRecord.EXPECT().FindByServiceID("s1").Return(&entities.MedicalRecordModel{ServiceID: "s1", Diagnosis: "ear infection"}, nil)
+		mockMedicine.EXPECT().FindByServiceID("s1").Return([]entities.MedicineModel{{Name: "Otomax"}}, nil)
+
+		record, err := sv.SaveMedicalRecord("s1", "doc-1", req)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(record.Medicines) != 1 {
+			t.Fatalf("expected medicines in the response, got %+v", record)
+		}
+	})
+
+	t.Run("vitals weight goes to the weight history", func(t *testing.T) {
+		weight := decimal.RequireFromString("4.35")
+		withWeight := req
+		withWeight.Weight = &weight
+		older, newer := start.Add(-72*time.Hour), start.Add(72*time.Hour)
+
+		cases := []struct {
+			name          string
+			latest        *entities.PetWeightModel
+			updateCurrent bool
+		}{
+			{name: "first measurement", updateCurrent: true},
+			{name: "newest measurement", latest: &entities.PetWeightModel{MeasuredAt: older}, updateCurrent: true},
+			{name: "editing an older visit", latest: &entities.PetWeightModel{MeasuredAt: newer}},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+				sv, mockService, mockRecord, mockMedicine, mockMservice, mockUow := newService(ctrl)
+				mockWeight := mocks.NewMockIPetWeightRepository(ctrl)
+				mockPet := mocks.NewMockIPetRepository(ctrl)
+				sv.WeightRepo, sv.PetRepo = mockWeight, mockPet
+
+				tx := &repositories.Tx{}
+				mockService.EXPECT().FindByID("s1").Return(visit(db.ServiceStatusFinish), nil)
+				mockUow.EXPECT().Begin().Return(tx)
+				mockRecord.EXPECT().UpsertTx(tx, gomock.Any())
+				mockMedicine.EXPECT().ReplaceTx(tx, "s1", gomock.Any())
+				mockMservice.EXPECT().UpdateDiseaseTx(tx, "s1", gomock.Any())
+				mockWeight.EXPECT().FindLatest("pet-1").Return(tc.latest, nil)
+				mockWeight.EXPECT().UpsertVitalsTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, data entities.PetWeightModel) {
+					if !data.Weight.Equal(weight) || *data.ServiceID != "s1" || !data.MeasuredAt.Equal(start) || *data.RecordedBy != "doc-1" {
+						t.Fatalf("unexpected measurement %+v", data)
+					}
+				})
+				if tc.updateCurrent {
+					mockPet.EXPECT().UpdateWeightTx(tx, "pet-1", weight)
+				}
+				mockUow.EXPECT().Commit(tx).Return(nil)
+				mockRecord.EXPECT().FindByServiceID("s1").Return(&entities.MedicalRecordModel{ServiceID: "s1"}, nil)
+				mockMedicine.EXPECT().FindByServiceID("s1").Return(nil, nil)
+
+				if _, err := sv.SaveMedicalRecord("s1", "doc-1", withWeight); err != nil {
+					t.Fatalf("unexpected err: %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("another doctor cannot write", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockService, _, _, _, _ := newService(ctrl)
+
+		mockService.EXPECT().FindByID("s1").Return(visit(db.ServiceStatusOngoing), nil)
+
+		if _, err := sv.SaveMedicalRecord("s1", "doc-2", req); !errors.Is(err, ErrNotAssignedDoctor) {
+			t.Fatalf("expected ErrNotAssignedDoctor, got %v", err)
+		}
+	})
+
+	t.Run("visit not started yet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockService, _, _, _, _ := newService(ctrl)
+
+		mockService.EXPECT().FindByID("s1").Return(visit(db.ServiceStatusWait), nil)
+
+		if _, err := sv.SaveMedicalRecord("s1", "doc-1", req); !errors.Is(err, ErrMedicalRecordNotAllowed) {
+			t.Fatalf("expected ErrMedicalRecordNotAllowed, got %v", err)
+		}
+	})
+
+	t.Run("invalid notes are rejected before writing", func(t *testing.T) {
+		hot := decimal.NewFromInt(50)
+		cases := map[string]entities.SaveMedicalRecordRequest{
+			"duplicate medicine": {Diagnosis: "flu", Medicines: []entities.MedicineRequest{
+				{Name: "Amoxicillin", Dosage: "1 tab", Frequency: "daily"},
+				{Name: "amoxicillin ", Dosage: "1 tab", Frequency: "daily"},
+			}},
+			"temperature out of range": {Diagnosis: "flu", Temperature: &hot},
+		}
+		for name, bad := range cases {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+				sv, mockService, _, _, _, _ := newService(ctrl)
+
+				mockService.EXPECT().FindByID("s1").Return(visit(db.ServiceStatusFinish), nil)
+
+				if _, err := sv.SaveMedicalRecord("s1", "doc-1", bad); !errors.Is(err, ErrInvalidMedicalRecord) {
+					t.Fatalf("expected ErrInvalidMedicalRecord, got %v", err)
+				}
+			})
+		}
+	})
+}
+
+func TestMedicalRecordService_FindPetMedicalRecords(t *testing.T) {
+	t.Run("owner cannot read another owner's pet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPet := mocks.NewMockIPetRepository(ctrl)
+		sv := &MedicalRecordService{PetRepo: mockPet}
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{OwnerID: "owner-2"}, nil)
+
+		if _, err := sv.FindPetMedicalRecords("pet-1", "owner-1", "owner"); !errors.Is(err, ErrMedicalRecordForbidden) {
+			t.Fatalf("expected ErrMedicalRecordForbidden, got %v", err)
+		}
+	})
+
+	t.Run("doctor who never treated the pet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPet := mocks.NewMockIPetRepository(ctrl)
+		mockService := mocks.NewMockIServiceRepository(ctrl)
+		sv := &MedicalRecordService{PetRepo: mockPet, ServiceRepo: mockService}
+
+		// จองไว้แต่ยังไม่ได้ตรวจ ยังไม่นับ
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1", OwnerID: "owner-1"}, nil)
+		mockService.EXPECT().FindByPetID("pet-1", timelineStatuses).Return([]*entities.ServiceModel{
+			{Sid: "s3", ServiceType: "mservice", StaffID: "doc-1", Status: db.ServiceStatusWait},
+		}, nil)
+
+		if _, err := sv.FindPetMedicalRecords("pet-1", "doc-1", "doctor"); !errors.Is(err, ErrMedicalRecordForbidden) {
+			t.Fatalf("expected ErrMedicalRecordForbidden, got %v", err)
+		}
+	})
+
+	t.Run("doctor reads history with prescriptions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPet := mocks.NewMockIPetRepository(ctrl)
+		mockService := mocks.NewMockIServiceRepository(ctrl)
+		mockRecord := mocks.NewMockIMedicalRecordRepository(ctrl)
+		mockMedicine := mocks.NewMockIMedicineRepository(ctrl)
+		sv := &MedicalRecordService{PetRepo: mockPet, ServiceRepo: mockService, RecordRepo: mockRecord, MedicineRepo: mockMedicine}
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1", OwnerID: "owner-1"}, nil)
+		mockService.EXPECT().FindByPetID("pet-1", timelineStatuses).Return([]*entities.ServiceModel{
+			{Sid: "s1", ServiceType: "mservice", StaffID: "doc-1", Status: db.ServiceStatusFinish},
+		}, nil)
+		mockRecord.EXPECT().FindByPetID("pet-1").Return([]*entities.MedicalRecordModel{
+			{ServiceID: "s2"}, {ServiceID: "s1"},
+		}, nil)
+		mockMedicine.EXPECT().FindByServiceIDs([]string{"s2", "s1"}).Return(map[string][]entities.MedicineModel{
+			"s1": {{Name: "Otomax"}},
+		}, nil)
+
+		records, err := sv.FindPetMedicalRecords("pet-1", "doc-1", "doctor")
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(records) != 2 || len(records[1].Medicines) != 1 || records[0].Medicines != nil {
+			t.Fatalf("unexpected records %+v", records)
+		}
+	})
+}
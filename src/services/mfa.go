@@ -0,0 +1,470 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/middlewares"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMfaNotConfigured  = errors.New("2FA is not configured on this server")
+	ErrMfaNotStarted     = errors.New("start 2FA setup first")
+	ErrMfaAlreadyEnabled = errors.New("2FA is already enabled")
+	ErrMfaNotEnabled     = errors.New("2FA is not enabled")
+	ErrInvalidMfaCode    = errors.New("2FA code is invalid or was already used")
+	ErrMfaLocked         = errors.New("too many wrong 2FA codes, try again later")
+	ErrMfaRequired       = errors.New("2FA is required for this role")
+)
+
+const (
+	// RFC 6238 ค่าที่ authenticator app ทุกตัวรองรับ
+	totpPeriod = 30
+	totpDigits = 6
+	// ยอมให้นาฬิกาเครื่อง user คลาดได้หนึ่ง step ทั้งก่อนและหลัง
+	totpSkew          = 1
+	totpSecretSize    = 20
+	maxMfaAttempts    = 5
+	mfaLockout        = 15 * time.Minute
+	recoveryCodeCount = 10
+)
+
+// BeginSession runs once the password (or a new registration) checks out. It opens the session
+// right away, or returns an mfa_pending token when the account has 2FA on or its role requires it.
+func (sv *authService) BeginSession(userID, role, device string) (*middlewares.TokenDetails, error) {
+	enabled, err := sv.mfaEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := sv.mfaRequired(db.Role(role))
+	if err != nil {
+		return nil, err
+	}
+	if !enabled && !required {
+		return sv.StartSession(userID, role, device)
+	}
+
+	return middlewares.GenerateMfaPendingJWTToken(userID, role, enabled)
+}
+
+func (sv *authService) MfaStatus(userID string) (*entities.MfaStatusModel, error) {
+	user, err := sv.UsersRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := sv.mfaRequired(user.Roles...)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &entities.MfaStatusModel{Required: required}
+	mfa, err := sv.MfaRepository.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return status, nil
+		}
+		return nil, err
+	}
+	if mfa.EnabledAt != nil {
+		status.Enabled = true
+		status.RecoveryCodesLeft = mfa.RecoveryCodesLeft
+	}
+	if mfa.LockedUntil != nil && mfa.LockedUntil.After(time.Now()) {
+		status.LockedUntil = mfa.LockedUntil
+	}
+	return status, nil
+}
+
+// SetupMfa makes a new secret for the authenticator app. 2FA stays off until EnableMfa gets a
+// code made from it, calling this again before that just replaces the secret.
+func (sv *authService) SetupMfa(userID string) (*entities.MfaSetupModel, error) {
+	if len(sv.MfaKey) == 0 {
+		return nil, ErrMfaNotConfigured
+	}
+	enabled, err := sv.mfaEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrMfaAlreadyEnabled
+	}
+	user, err := sv.UsersRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("mfa -> SetupMfa: %v", err)
+	}
+	sealed, err := sv.sealMfaSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := sv.MfaRepository.SaveSecret(userID, sealed); err != nil {
+		return nil, err
+	}
+
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+	return &entities.MfaSetupModel{
+		Secret:     encoded,
+		OtpauthURI: otpauthURI(sv.MfaIssuer, user.Email, encoded),
+	}, nil
+}
+
+// EnableMfa confirms the secret from SetupMfa with a code from the app and returns the
+// recovery codes, the only time they are shown. When td is the mfa_pending token of a login
+// that had to set up 2FA first, the login goes on and a session is returned as well.
+func (sv *authService) EnableMfa(td *middlewares.TokenDetails, code, device string) ([]string, *middlewares.TokenDetails, error) {
+	mfa, err := sv.MfaRepository.FindByUserID(td.UserID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, nil, ErrMfaNotStarted
+		}
+		return nil, nil, err
+	}
+	if mfa.EnabledAt != nil {
+		return nil, nil, ErrMfaAlreadyEnabled
+	}
+	if mfa.LockedUntil != nil && mfa.LockedUntil.After(time.Now()) {
+		return nil, nil, ErrMfaLocked
+	}
+	secret, err := sv.openMfaSecret(mfa.Secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	step, ok := matchTOTP(secret, code, time.Now())
+	if !ok {
+		if err := sv.MfaRepository.RecordFailure(td.UserID, maxMfaAttempts, mfaLockout); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrInvalidMfaCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+	tx := sv.UnitOfWork.Begin()
+	sv.MfaRepository.EnableTx(tx, td.UserID, step)
+	sv.MfaRepository.ReplaceRecoveryCodesTx(tx, td.UserID, hashes)
+	if err := sv.UnitOfWork.Commit(tx); err != nil {
+		return nil, nil, err
+	}
+
+	if td.Purpose != "mfa_pending" {
+		return codes, nil, nil
+	}
+	session, err := sv.openSession(td.UserID, td.Role, device, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return codes, session, nil
+}
+
+// VerifyMfa finishes a login that stopped at the mfa_pending token.
+func (sv *authService) VerifyMfa(td *middlewares.TokenDetails, code, device string) (*middlewares.TokenDetails, error) {
+	mfa, err := sv.enabledMfa(td.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := sv.checkMfaCode(mfa, code); err != nil {
+		return nil, err
+	}
+
+	return sv.openSession(td.UserID, td.Role, device, true)
+}
+
+// DisableMfa turns 2FA off, not allowed while one of the account's roles requires it.
+func (sv *authService) DisableMfa(userID, code string) error {
+	mfa, err := sv.enabledMfa(userID)
+	if err != nil {
+		return err
+	}
+	user, err := sv.UsersRepository.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	required, err := sv.mfaRequired(user.Roles...)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMfaRequired
+	}
+	if err := sv.checkMfaCode(mfa, code); err != nil {
+		return err
+	}
+
+	_, err = sv.MfaRepository.DeleteByUserID(userID)
+	return err
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes, the old ones stop working.
+func (sv *authService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	mfa, err := sv.enabledMfa(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := sv.checkMfaCode(mfa, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	tx := sv.UnitOfWork.Begin()
+	sv.MfaRepository.ReplaceRecoveryCodesTx(tx, userID, hashes)
+	if err := sv.UnitOfWork.Commit(tx); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// ResetMfa is for an admin helping a user who lost both the app and the recovery codes.
+// If a role of the user requires 2FA they set it up again at the next login.
+func (sv *authService) ResetMfa(userID string) error {
+	deleted, err := sv.MfaRepository.DeleteByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrMfaNotEnabled
+	}
+	return nil
+}
+
+func (sv *authService) FindMfaRequirements() ([]*entities.MfaRequirementModel, error) {
+	return sv.MfaRepository.FindRequirements()
+}
+
+// RequireMfa makes 2FA mandatory for role. Sessions of that role that did not pass 2FA are
+// closed, so it applies right away instead of when their refresh tokens run out. It returns
+// how many sessions were closed.
+func (sv *authService) RequireMfa(role, adminID string) (*entities.MfaRequirementModel, int, error) {
+	if role == "admin" {
+		// ไม่งั้น admin ที่กดจะหลุด login ไปพร้อมคนอื่นโดยยังไม่มี 2FA
+		enabled, err := sv.mfaEnabled(adminID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !enabled {
+			return nil, 0, ErrMfaNotEnabled
+		}
+	}
+
+	requirement, err := sv.MfaRepository.Require(db.Role(role), adminID)
+	if err != nil {
+		return nil, 0, err
+	}
+	revoked, err := sv.SessionRepository.RevokeWithoutMfa(db.Role(role))
+	if err != nil {
+		return nil, 0, err
+	}
+	return requirement, revoked, nil
+}
+
+func (sv *authService) UnrequireMfa(role string) (bool, error) {
+	return sv.MfaRepository.Unrequire(db.Role(role))
+}
+
+func (sv *authService) mfaEnabled(userID string) (bool, error) {
+	mfa, err := sv.MfaRepository.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return mfa.EnabledAt != nil, nil
+}
+
+func (sv *authService) enabledMfa(userID string) (*entities.MfaModel, error) {
+	mfa, err := sv.MfaRepository.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrMfaNotEnabled
+		}
+		return nil, err
+	}
+	if mfa.EnabledAt == nil {
+		return nil, ErrMfaNotEnabled
+	}
+	return mfa, nil
+}
+
+// mfaRequired บอกว่ามี role ไหนใน roles ถูกบังคับ 2FA ไหม
+func (sv *authService) mfaRequired(roles ...db.Role) (bool, error) {
+	requirements, err := sv.MfaRepository.FindRequirements()
+	if err != nil {
+		return false, err
+	}
+	for _, requirement := range requirements {
+		if slices.Contains(roles, requirement.Role) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkMfaCode takes a TOTP code or an unused recovery code. Every wrong code counts
+// towards the lockout.
+func (sv *authService) checkMfaCode(mfa *entities.MfaModel, code string) error {
+	if mfa.LockedUntil != nil && mfa.LockedUntil.After(time.Now()) {
+		return ErrMfaLocked
+	}
+	secret, err := sv.openMfaSecret(mfa.Secret)
+	if err != nil {
+		return err
+	}
+
+	if step, ok := matchTOTP(secret, code, time.Now()); ok {
+		claimed, err := sv.MfaRepository.ClaimStep(mfa.UserID, step)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			return nil
+		}
+	} else if normalized := normalizeRecoveryCode(code); normalized != "" {
+		used, err := sv.MfaRepository.UseRecoveryCode(mfa.UserID, hashRecoveryCode(normalized))
+		if err != nil {
+			return err
+		}
+		if used {
+			return nil
+		}
+	}
+
+	if err := sv.MfaRepository.RecordFailure(mfa.UserID, maxMfaAttempts, mfaLockout); err != nil {
+		return err
+	}
+	return ErrInvalidMfaCode
+}
+
+// secret ของ TOTP ต้องเอากลับมาใช้ได้ hash ไม่ได้ เลยเข้ารหัสด้วย AES-GCM แทน
+func (sv *authService) sealMfaSecret(secret []byte) (string, error) {
+	aead, err := mfaCipher(sv.MfaKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("mfa -> seal secret: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, secret, nil)), nil
+}
+
+func (sv *authService) openMfaSecret(sealed string) ([]byte, error) {
+	if len(sv.MfaKey) == 0 {
+		return nil, ErrMfaNotConfigured
+	}
+	aead, err := mfaCipher(sv.MfaKey)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < aead.NonceSize() {
+		return nil, fmt.Errorf("mfa -> open secret: malformed secret")
+	}
+	secret, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], nil)
+	if err != nil {
+		// MFA_SECRET_KEY ถูกเปลี่ยน secret เดิมทั้งหมดใช้ไม่ได้ ต้องให้ admin reset
+		return nil, fmt.Errorf("mfa -> open secret: %v", err)
+	}
+	return secret, nil
+}
+
+func mfaCipher(key []byte) (cipher.AEAD, error) {
+	sum := sha256.Sum256(key)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("mfa -> cipher: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// totpCode is the HOTP value (RFC 4226) for the counter step, which TOTP takes from the clock.
+func totpCode(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// matchTOTP returns the step the code belongs to, looking totpSkew steps around now.
+func matchTOTP(secret []byte, code string, now time.Time) (int, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, step)), []byte(code)) == 1 {
+			return int(step), true
+		}
+	}
+	return 0, false
+}
+
+func otpauthURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+	return "otpauth://totp/" + url.PathEscape(issuer) + ":" + url.PathEscape(account) + "?" + query.Encode()
+}
+
+// newRecoveryCodes returns the codes to show once and the hashes to keep.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("mfa -> recovery codes: %v", err)
+		}
+		code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// recovery code พิมพ์มาแบบมีขีด/ไม่มีขีด ตัวใหญ่ตัวเล็กก็ได้
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(code) != 10 {
+		return ""
+	}
+	return code
+}
+
+func hashRecoveryCode(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/middlewares"
+	"lama-backend/src/services/mocks"
+)
+
+func TestTotpCode(t *testing.T) {
+	// RFC 6238 appendix B (SHA1) ตัดเหลือ 6 หลัก
+	secret := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		if got := totpCode(secret, tt.unix/totpPeriod); got != tt.want {
+			t.Errorf("T=%d: want %s got %s", tt.unix, tt.want, got)
+		}
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / totpPeriod
+
+	if got, ok := matchTOTP(secret, totpCode(secret, step-1), now); !ok || got != int(step-1) {
+		t.Fatalf("code from the previous step: got %d %v", got, ok)
+	}
+	if _, ok := matchTOTP(secret, totpCode(secret, step+2), now); ok {
+		t.Fatal("code two steps ahead should not match")
+	}
+	if _, ok := matchTOTP(secret, "12345", now); ok {
+		t.Fatal("short code should not match")
+	}
+}
+
+func TestAuthService_BeginSession(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", "test")
+
+	tests := []struct {
+		name        string
+		mfa         *entities.MfaModel
+		required    bool
+		wantPending bool
+	}{
+		{name: "no 2FA, not required", wantPending: false},
+		{name: "2FA enabled", mfa: &entities.MfaModel{UserID: "user-1", EnabledAt: new(time.Time)}, wantPending: true},
+		{name: "required but not enrolled", required: true, wantPending: true},
+		// ตั้งไว้ครึ่งทางแล้วไม่ได้ยืนยัน ยังนับว่าไม่ได้เปิด
+		{name: "setup never confirmed", mfa: &entities.MfaModel{UserID: "user-1"}, wantPending: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockMfa := mocks.NewMockIMfaRepository(ctrl)
+			mockSession := mocks.NewMockISessionRepository(ctrl)
+			sv := &authService{MfaRepository: mockMfa, SessionRepository: mockSession, RefreshTTL: time.Hour}
+
+			if tt.mfa != nil {
+				mockMfa.EXPECT().FindByUserID("user-1").Return(tt.mfa, nil)
+			} else {
+				mockMfa.EXPECT().FindByUserID("user-1").Return(nil, db.ErrNotFound)
+			}
+			var requirements []*entities.MfaRequirementModel
+			if tt.required {
+				requirements = append(requirements, &entities.MfaRequirementModel{Role: db.RoleDoctor})
+			}
+			mockMfa.EXPECT().FindRequirements().Return(requirements, nil)
+			if !tt.wantPending {
+				mockSession.EXPECT().Insert(gomock.Any()).DoAndReturn(func(data entities.SessionModel) (*entities.SessionModel, error) {
+					created := data
+					created.ID = "sess-1"
+					return &created, nil
+				})
+			}
+
+			td, err := sv.BeginSession("user-1", "doctor", "curl/8.0")
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if pending := td.Purpose == "mfa_pending"; pending != tt.wantPending {
+				t.Fatalf("want pending %v got token %+v", tt.wantPending, td)
+			}
+			if tt.wantPending && (td.SessionID != "" || td.RefreshToken != nil || *td.MfaEnrolled != (tt.mfa != nil)) {
+				t.Fatalf("unexpected pending token %+v", td)
+			}
+		})
+	}
+}
+
+func TestAuthService_VerifyMfa(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", "test")
+
+	secret := []byte("12345678901234567890")
+	pending := &middlewares.TokenDetails{UserID: "user-1", Role: "doctor", Purpose: "mfa_pending"}
+	code := totpCode(secret, time.Now().Unix()/totpPeriod)
+	locked := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name    string
+		code    string
+		locked  bool
+		expect  func(m *mocks.MockIMfaRepository)
+		wantErr error
+	}{
+		{
+			name: "code from the app",
+			code: code,
+			expect: func(m *mocks.MockIMfaRepository) {
+				m.EXPECT().ClaimStep("user-1", gomock.Any()).Return(true, nil)
+			},
+		},
+		{
+			name: "same code again",
+			code: code,
+			expect: func(m *mocks.MockIMfaRepository) {
+				m.EXPECT().ClaimStep("user-1", gomock.Any()).Return(false, nil)
+				m.EXPECT().RecordFailure("user-1", maxMfaAttempts, mfaLockout).Return(nil)
+			},
+			wantErr: ErrInvalidMfaCode,
+		},
+		{
+			name: "recovery code typed without the dash",
+			code: "ABCDE FGHIJ",
+			expect: func(m *mocks.MockIMfaRepository) {
+				m.EXPECT().UseRecoveryCode("user-1", hashRecoveryCode("abcdefghij")).Return(true, nil)
+			},
+		},
+		{
+			name: "used recovery code",
+			code: "abcde-fghij",
+			expect: func(m *mocks.MockIMfaRepository) {
+				m.EXPECT().UseRecoveryCode("user-1", hashRecoveryCode("abcdefghij")).Return(false, nil)
+				m.EXPECT().RecordFailure("user-1", maxMfaAttempts, mfaLockout).Return(nil)
+			},
+			wantErr: ErrInvalidMfaCode,
+		},
+		{
+			name:    "locked after too many wrong codes",
+			code:    code,
+			locked:  true,
+			expect:  func(m *mocks.MockIMfaRepository) {},
+			wantErr: ErrMfaLocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockMfa := mocks.NewMockIMfaRepository(ctrl)
+			mockSession := mocks.NewMockISessionRepository(ctrl)
+			sv := &authService{MfaRepository: mockMfa, SessionRepository: mockSession, RefreshTTL: time.Hour, MfaKey: []byte("mfa-test-key")}
+
+			sealed, err := sv.sealMfaSecret(secret)
+			if err != nil {
+				t.Fatalf("seal: %v", err)
+			}
+			mfa := &entities.MfaModel{UserID: "user-1", Secret: sealed, EnabledAt: new(time.Time)}
+			if tt.locked {
+				mfa.LockedUntil = &locked
+			}
+			mockMfa.EXPECT().FindByUserID("user-1").Return(mfa, nil)
+			tt.expect(mockMfa)
+			if tt.wantErr == nil {
+				mockSession.EXPECT().Insert(gomock.Any()).DoAndReturn(func(data entities.SessionModel) (*entities.SessionModel, error) {
+					if !data.MfaVerified {
+						t.Fatal("session should be marked as passed 2FA")
+					}
+					created := data
+					created.ID = "sess-1"
+					return &created, nil
+				})
+			}
+
+			td, err := sv.VerifyMfa(pending, tt.code, "curl/8.0")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want %v got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && (td.Purpose != "access" || td.SessionID != "sess-1") {
+				t.Fatalf("unexpected token %+v", td)
+			}
+		})
+	}
+}
+
+func TestAuthService_MfaPolicy(t *testing.T) {
+	t.Run("switching to a role that requires 2FA", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUsers := mocks.NewMockIUsersRepository(ctrl)
+		mockSession := mocks.NewMockISessionRepository(ctrl)
+		mockMfa := mocks.NewMockIMfaRepository(ctrl)
+		sv := &authService{UsersRepository: mockUsers, SessionRepository: mockSession, MfaRepository: mockMfa}
+
+		mockUsers.EXPECT().FindByID("user-1").Return(&entities.UserDataModel{UserID: "user-1", Roles: []db.Role{db.RoleOwner, db.RoleDoctor}}, nil)
+		mockSession.EXPECT().FindByID("sess-1").Return(&entities.SessionModel{ID: "sess-1", UserID: "user-1", Role: db.RoleOwner}, nil)
+		mockMfa.EXPECT().FindRequirements().Return([]*entities.MfaRequirementModel{{Role: db.RoleDoctor}}, nil)
+
+		current := &middlewares.TokenDetails{UserID: "user-1", Role: "owner", Purpose: "access", SessionID: "sess-1"}
+		if _, err := sv.SwitchRole(current, "doctor", "curl/8.0"); !errors.Is(err, ErrMfaRequired) {
+			t.Fatalf("want ErrMfaRequired got %v", err)
+		}
+	})
+
+	t.Run("requiring it closes sessions that skipped 2FA", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockMfa := mocks.NewMockIMfaRepository(ctrl)
+		mockSession := mocks.NewMockISessionRepository(ctrl)
+		sv := &authService{MfaRepository: mockMfa, SessionRepository: mockSession}
+
+		mockMfa.EXPECT().Require(db.RoleDoctor, "admin-1").Return(&entities.MfaRequirementModel{Role: db.RoleDoctor, CreatedBy: "admin-1"}, nil)
+		mockSession.EXPECT().RevokeWithoutMfa(db.RoleDoctor).Return(3, nil)
+
+		_, revoked, err := sv.RequireMfa("doctor", "admin-1")
+		if err != nil || revoked != 3 {
+			t.Fatalf("want 3 revoked got %d, %v", revoked, err)
+		}
+	})
+
+	t.Run("admin without 2FA cannot require it for admins", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockMfa := mocks.NewMockIMfaRepository(ctrl)
+		sv := &authService{MfaRepository: mockMfa}
+
+		mockMfa.EXPECT().FindByUserID("admin-1").Return(nil, db.ErrNotFound)
+
+		if _, _, err := sv.RequireMfa("admin", "admin-1"); !errors.Is(err, ErrMfaNotEnabled) {
+			t.Fatalf("want ErrMfaNotEnabled got %v", err)
+		}
+	})
+
+	t.Run("cannot turn off 2FA the role requires", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUsers := mocks.NewMockIUsersRepository(ctrl)
+		mockMfa := mocks.NewMockIMfaRepository(ctrl)
+		sv := &authService{UsersRepository: mockUsers, MfaRepository: mockMfa}
+
+		mockMfa.EXPECT().FindByUserID("user-1").Return(&entities.MfaModel{UserID: "user-1", EnabledAt: new(time.Time)}, nil)
+		mockUsers.EXPECT().FindByID("user-1").Return(&entities.UserDataModel{UserID: "user-1", Roles: []db.Role{db.RoleOwner, db.RoleCaretaker}}, nil)
+		mockMfa.EXPECT().FindRequirements().Return([]*entities.MfaRequirementModel{{Role: db.RoleCaretaker}}, nil)
+
+		if err := sv.DisableMfa("user-1", "123456"); !errors.Is(err, ErrMfaRequired) {
+			t.Fatalf("want ErrMfaRequired got %v", err)
+		}
+	})
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: lama-backend/domain/repositories (interfaces: IUsersRepository,IOwnerRepository,ICaretakerRepository,IDoctorRepository,IPetRepository)
+// Source: lama-backend/domain/repositories (interfaces: IUsersRepository,IOwnerRepository,ICaretakerRepository,IDoctorRepository,IPetRepository,IPaymentRepository,IStripeEventRepository,IServiceRepository,ICServiceRepository,IMServiceRepository,IUnitOfWork,IStaffHoldRepository,IPricingRepository,IJobLockRepository,IServiceCancellationRepository,IServiceRescheduleRepository,IServiceStatusHistoryRepository,IStaffScheduleRepository,ILeavedayRepository,IServiceReassignmentRepository,IMedicalRecordRepository,IMedicineRepository,IVaccinationRepository,IPetWeightRepository,IAttachmentRepository,ISessionRepository,IPasswordResetRepository,IMfaRepository)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -7,10 +7,12 @@ package mocks
 import (
 	entities "lama-backend/domain/entities"
 	db "lama-backend/domain/prisma/db"
+	repositories "lama-backend/domain/repositories"
 	reflect "reflect"
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	decimal "github.com/shopspring/decimal"
 )
 
 // MockIUsersRepository is a mock of IUsersRepository interface.
@@ -36,6 +38,21 @@ func (m *MockIUsersRepository) EXPECT() *MockIUsersRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddRole mocks base method.
+func (m *MockIUsersRepository) AddRole(arg0 string, arg1 db.Role) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRole", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddRole indicates an expected call of AddRole.
+func (mr *MockIUsersRepositoryMockRecorder) AddRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRole", reflect.TypeOf((*MockIUsersRepository)(nil).AddRole), arg0, arg1)
+}
+
 // DeleteByID mocks base method.
 func (m *MockIUsersRepository) DeleteByID(arg0 string) (*entities.UserDataModel, error) {
 	m.ctrl.T.Helper()
@@ -51,49 +68,49 @@ func (mr *MockIUsersRepositoryMockRecorder) DeleteByID(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockIUsersRepository)(nil).DeleteByID), arg0)
 }
 
-// FindByEmailAndRole mocks base method.
-func (m *MockIUsersRepository) FindByEmailAndRole(arg0, arg1 string) (*entities.LoginUserResponseModel, error) {
+// FindAll mocks base method.
+func (m *MockIUsersRepository) FindAll(arg0 string, arg1, arg2 int) ([]*entities.UserDataModel, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByEmailAndRole", arg0, arg1)
-	ret0, _ := ret[0].(*entities.LoginUserResponseModel)
+	ret := m.ctrl.Call(m, "FindAll", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.UserDataModel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindByEmailAndRole indicates an expected call of FindByEmailAndRole.
-func (mr *MockIUsersRepositoryMockRecorder) FindByEmailAndRole(arg0, arg1 interface{}) *gomock.Call {
+// FindAll indicates an expected call of FindAll.
+func (mr *MockIUsersRepositoryMockRecorder) FindAll(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmailAndRole", reflect.TypeOf((*MockIUsersRepository)(nil).FindByEmailAndRole), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockIUsersRepository)(nil).FindAll), arg0, arg1, arg2)
 }
 
-// FindByID mocks base method.
-func (m *MockIUsersRepository) FindByID(arg0 string) (*entities.UserDataModel, error) {
+// FindByEmail mocks base method.
+func (m *MockIUsersRepository) FindByEmail(arg0 string) (*entities.LoginUserResponseModel, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByID", arg0)
-	ret0, _ := ret[0].(*entities.UserDataModel)
+	ret := m.ctrl.Call(m, "FindByEmail", arg0)
+	ret0, _ := ret[0].(*entities.LoginUserResponseModel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindByID indicates an expected call of FindByID.
-func (mr *MockIUsersRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+// FindByEmail indicates an expected call of FindByEmail.
+func (mr *MockIUsersRepositoryMockRecorder) FindByEmail(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockIUsersRepository)(nil).FindByID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockIUsersRepository)(nil).FindByEmail), arg0)
 }
 
-// FindAll mocks base method.
-func (m *MockIUsersRepository) FindAll(arg0 string, arg1, arg2 int) ([]*entities.UserDataModel, error) {
+// FindByID mocks base method.
+func (m *MockIUsersRepository) FindByID(arg0 string) (*entities.UserDataModel, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAll", arg0, arg1, arg2)
-	ret0, _ := ret[0].([]*entities.UserDataModel)
+	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret0, _ := ret[0].(*entities.UserDataModel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindAll indicates an expected call of FindAll.
-func (mr *MockIUsersRepositoryMockRecorder) FindAll(arg0, arg1, arg2 interface{}) *gomock.Call {
+// FindByID indicates an expected call of FindByID.
+func (mr *MockIUsersRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockIUsersRepository)(nil).FindAll), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockIUsersRepository)(nil).FindByID), arg0)
 }
 
 // InsertUser mocks base method.
@@ -126,6 +143,18 @@ func (mr *MockIUsersRepositoryMockRecorder) UpdateByID(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockIUsersRepository)(nil).UpdateByID), arg0, arg1)
 }
 
+// UpdatePasswordTx mocks base method.
+func (m *MockIUsersRepository) UpdatePasswordTx(arg0 *repositories.Tx, arg1, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatePasswordTx", arg0, arg1, arg2)
+}
+
+// UpdatePasswordTx indicates an expected call of UpdatePasswordTx.
+func (mr *MockIUsersRepositoryMockRecorder) UpdatePasswordTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordTx", reflect.TypeOf((*MockIUsersRepository)(nil).UpdatePasswordTx), arg0, arg1, arg2)
+}
+
 // MockIOwnerRepository is a mock of IOwnerRepository interface.
 type MockIOwnerRepository struct {
 	ctrl     *gomock.Controller
@@ -149,6 +178,18 @@ func (m *MockIOwnerRepository) EXPECT() *MockIOwnerRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddTotalSpendingTx mocks base method.
+func (m *MockIOwnerRepository) AddTotalSpendingTx(arg0 *repositories.Tx, arg1 string, arg2 decimal.Decimal) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddTotalSpendingTx", arg0, arg1, arg2)
+}
+
+// AddTotalSpendingTx indicates an expected call of AddTotalSpendingTx.
+func (mr *MockIOwnerRepositoryMockRecorder) AddTotalSpendingTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTotalSpendingTx", reflect.TypeOf((*MockIOwnerRepository)(nil).AddTotalSpendingTx), arg0, arg1, arg2)
+}
+
 // DeleteByID mocks base method.
 func (m *MockIOwnerRepository) DeleteByID(arg0 string) (*entities.UserDataModel, error) {
 	m.ctrl.T.Helper()
@@ -503,6 +544,21 @@ func (mr *MockIPetRepositoryMockRecorder) FindByOwnerID(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByOwnerID", reflect.TypeOf((*MockIPetRepository)(nil).FindByOwnerID), arg0)
 }
 
+// FindPetByID mocks base method.
+func (m *MockIPetRepository) FindPetByID(arg0 string) (*entities.PetDataModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPetByID", arg0)
+	ret0, _ := ret[0].(*entities.PetDataModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPetByID indicates an expected call of FindPetByID.
+func (mr *MockIPetRepositoryMockRecorder) FindPetByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPetByID", reflect.TypeOf((*MockIPetRepository)(nil).FindPetByID), arg0)
+}
+
 // InsertPet mocks base method.
 func (m *MockIPetRepository) InsertPet(arg0 entities.CreatedPetModel) (*entities.PetDataModel, error) {
 	m.ctrl.T.Helper()
@@ -532,3 +588,2587 @@ func (mr *MockIPetRepositoryMockRecorder) UpdatePet(arg0, arg1 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePet", reflect.TypeOf((*MockIPetRepository)(nil).UpdatePet), arg0, arg1)
 }
+
+// UpdateWeightTx mocks base method.
+func (m *MockIPetRepository) UpdateWeightTx(arg0 *repositories.Tx, arg1 string, arg2 decimal.Decimal) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateWeightTx", arg0, arg1, arg2)
+}
+
+// UpdateWeightTx indicates an expected call of UpdateWeightTx.
+func (mr *MockIPetRepositoryMockRecorder) UpdateWeightTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWeightTx", reflect.TypeOf((*MockIPetRepository)(nil).UpdateWeightTx), arg0, arg1, arg2)
+}
+
+// MockIPaymentRepository is a mock of IPaymentRepository interface.
+type MockIPaymentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPaymentRepositoryMockRecorder
+}
+
+// MockIPaymentRepositoryMockRecorder is the mock recorder for MockIPaymentRepository.
+type MockIPaymentRepositoryMockRecorder struct {
+	mock *MockIPaymentRepository
+}
+
+// NewMockIPaymentRepository creates a new mock instance.
+func NewMockIPaymentRepository(ctrl *gomock.Controller) *MockIPaymentRepository {
+	mock := &MockIPaymentRepository{ctrl: ctrl}
+	mock.recorder = &MockIPaymentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPaymentRepository) EXPECT() *MockIPaymentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteByID mocks base method.
+func (m *MockIPaymentRepository) DeleteByID(arg0 string) (*entities.PaymentModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByID", arg0)
+	ret0, _ := ret[0].(*entities.PaymentModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByID indicates an expected call of DeleteByID.
+func (mr *MockIPaymentRepositoryMockRecorder) DeleteByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockIPaymentRepository)(nil).DeleteByID), arg0)
+}
+
+// ExpireAbandonedTx mocks base method.
+func (m *MockIPaymentRepository) ExpireAbandonedTx(arg0 *repositories.Tx, arg1 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ExpireAbandonedTx", arg0, arg1)
+}
+
+// ExpireAbandonedTx indicates an expected call of ExpireAbandonedTx.
+func (mr *MockIPaymentRepositoryMockRecorder) ExpireAbandonedTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireAbandonedTx", reflect.TypeOf((*MockIPaymentRepository)(nil).ExpireAbandonedTx), arg0, arg1)
+}
+
+// FindAllPayments mocks base method.
+func (m *MockIPaymentRepository) FindAllPayments(arg0, arg1, arg2, arg3 int) ([]*entities.PaymentModel, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllPayments", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*entities.PaymentModel)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAllPayments indicates an expected call of FindAllPayments.
+func (mr *MockIPaymentRepositoryMockRecorder) FindAllPayments(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllPayments", reflect.TypeOf((*MockIPaymentRepository)(nil).FindAllPayments), arg0, arg1, arg2, arg3)
+}
+
+// FindByID mocks base method.
+func (m *MockIPaymentRepository) FindByID(arg0 string) (*entities.PaymentModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret0, _ := ret[0].(*entities.PaymentModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockIPaymentRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockIPaymentRepository)(nil).FindByID), arg0)
+}
+
+// FindByStripePaymentIntent mocks base method.
+func (m *MockIPaymentRepository) FindByStripePaymentIntent(arg0 string) (*entities.PaymentModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByStripePaymentIntent", arg0)
+	ret0, _ := ret[0].(*entities.PaymentModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByStripePaymentIntent indicates an expected call of FindByStripePaymentIntent.
+func (mr *MockIPaymentRepositoryMockRecorder) FindByStripePaymentIntent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStripePaymentIntent", reflect.TypeOf((*MockIPaymentRepository)(nil).FindByStripePaymentIntent), arg0)
+}
+
+// FindPaymentsByOwnerID mocks base method.
+func (m *MockIPaymentRepository) FindPaymentsByOwnerID(arg0 string, arg1, arg2, arg3, arg4 int) ([]*entities.PaymentModel, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPaymentsByOwnerID", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]*entities.PaymentModel)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindPaymentsByOwnerID indicates an expected call of FindPaymentsByOwnerID.
+func (mr *MockIPaymentRepositoryMockRecorder) FindPaymentsByOwnerID(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPaymentsByOwnerID", reflect.TypeOf((*MockIPaymentRepository)(nil).FindPaymentsByOwnerID), arg0, arg1, arg2, arg3, arg4)
+}
+
+// InsertAdjustmentTx mocks base method.
+func (m *MockIPaymentRepository) InsertAdjustmentTx(arg0 *repositories.Tx, arg1, arg2, arg3 string, arg4 int, arg5 []entities.PaymentLineItemModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InsertAdjustmentTx", arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// InsertAdjustmentTx indicates an expected call of InsertAdjustmentTx.
+func (mr *MockIPaymentRepositoryMockRecorder) InsertAdjustmentTx(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAdjustmentTx", reflect.TypeOf((*MockIPaymentRepository)(nil).InsertAdjustmentTx), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// InsertPaymentTx mocks base method.
+func (m *MockIPaymentRepository) InsertPaymentTx(arg0 *repositories.Tx, arg1, arg2 string, arg3 int, arg4 []entities.PaymentLineItemModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InsertPaymentTx", arg0, arg1, arg2, arg3, arg4)
+}
+
+// InsertPaymentTx indicates an expected call of InsertPaymentTx.
+func (mr *MockIPaymentRepositoryMockRecorder) InsertPaymentTx(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPaymentTx", reflect.TypeOf((*MockIPaymentRepository)(nil).InsertPaymentTx), arg0, arg1, arg2, arg3, arg4)
+}
+
+// UpdateByID mocks base method.
+func (m *MockIPaymentRepository) UpdateByID(arg0 string, arg1 entities.PaymentModel) (*entities.PaymentModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByID", arg0, arg1)
+	ret0, _ := ret[0].(*entities.PaymentModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateByID indicates an expected call of UpdateByID.
+func (mr *MockIPaymentRepositoryMockRecorder) UpdateByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockIPaymentRepository)(nil).UpdateByID), arg0, arg1)
+}
+
+// UpdateByIDTx mocks base method.
+func (m *MockIPaymentRepository) UpdateByIDTx(arg0 *repositories.Tx, arg1 string, arg2 entities.PaymentModel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByIDTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateByIDTx indicates an expected call of UpdateByIDTx.
+func (mr *MockIPaymentRepositoryMockRecorder) UpdateByIDTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByIDTx", reflect.TypeOf((*MockIPaymentRepository)(nil).UpdateByIDTx), arg0, arg1, arg2)
+}
+
+// MockIStripeEventRepository is a mock of IStripeEventRepository interface.
+type MockIStripeEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIStripeEventRepositoryMockRecorder
+}
+
+// MockIStripeEventRepositoryMockRecorder is the mock recorder for MockIStripeEventRepository.
+type MockIStripeEventRepositoryMockRecorder struct {
+	mock *MockIStripeEventRepository
+}
+
+// NewMockIStripeEventRepository creates a new mock instance.
+func NewMockIStripeEventRepository(ctrl *gomock.Controller) *MockIStripeEventRepository {
+	mock := &MockIStripeEventRepository{ctrl: ctrl}
+	mock.recorder = &MockIStripeEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIStripeEventRepository) EXPECT() *MockIStripeEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Claim mocks base method.
+func (m *MockIStripeEventRepository) Claim(arg0, arg1 string) (*entities.StripeEventModel, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", arg0, arg1)
+	ret0, _ := ret[0].(*entities.StripeEventModel)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockIStripeEventRepositoryMockRecorder) Claim(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockIStripeEventRepository)(nil).Claim), arg0, arg1)
+}
+
+// MarkProcessed mocks base method.
+func (m *MockIStripeEventRepository) MarkProcessed(arg0, arg1 string) (*entities.StripeEventModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkProcessed", arg0, arg1)
+	ret0, _ := ret[0].(*entities.StripeEventModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkProcessed indicates an expected call of MarkProcessed.
+func (mr *MockIStripeEventRepositoryMockRecorder) MarkProcessed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkProcessed", reflect.TypeOf((*MockIStripeEventRepository)(nil).MarkProcessed), arg0, arg1)
+}
+
+// Release mocks base method.
+func (m *MockIStripeEventRepository) Release(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIStripeEventRepositoryMockRecorder) Release(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIStripeEventRepository)(nil).Release), arg0)
+}
+
+// MockIServiceRepository is a mock of IServiceRepository interface.
+type MockIServiceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIServiceRepositoryMockRecorder
+}
+
+// MockIServiceRepositoryMockRecorder is the mock recorder for MockIServiceRepository.
+type MockIServiceRepositoryMockRecorder struct {
+	mock *MockIServiceRepository
+}
+
+// NewMockIServiceRepository creates a new mock instance.
+func NewMockIServiceRepository(ctrl *gomock.Controller) *MockIServiceRepository {
+	mock := &MockIServiceRepository{ctrl: ctrl}
+	mock.recorder = &MockIServiceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIServiceRepository) EXPECT() *MockIServiceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteByID mocks base method.
+func (m *MockIServiceRepository) DeleteByID(arg0 string) (*entities.ServiceModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByID", arg0)
+	ret0, _ := ret[0].(*entities.ServiceModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByID indicates an expected call of DeleteByID.
+func (mr *MockIServiceRepositoryMockRecorder) DeleteByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockIServiceRepository)(nil).DeleteByID), arg0)
+}
+
+// FindAll mocks base method.
+func (m *MockIServiceRepository) FindAll(arg0 string, arg1, arg2, arg3, arg4 int) ([]*entities.ServiceModel, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]*entities.ServiceModel)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockIServiceRepositoryMockRecorder) FindAll(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockIServiceRepository)(nil).FindAll), arg0, arg1, arg2, arg3, arg4)
+}
+
+// FindByCaretakerID mocks base method.
+func (m *MockIServiceRepository) FindByCaretakerID(arg0, arg1 string, arg2, arg3, arg4, arg5 int) ([]*entities.ServiceModel, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByCaretakerID", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]*entities.ServiceModel)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindByCaretakerID indicates an expected call of FindByCaretakerID.
+func (mr *MockIServiceRepositoryMockRecorder) FindByCaretakerID(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCaretakerID", reflect.TypeOf((*MockIServiceRepository)(nil).FindByCaretakerID), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// FindByDoctorID mocks base method.
+func (m *MockIServiceRepository) FindByDoctorID(arg0, arg1 string, arg2, arg3, arg4, arg5 int) ([]*entities.ServiceModel, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByDoctorID", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]*entities.ServiceModel)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindByDoctorID indicates an expected call of FindByDoctorID.
+func (mr *MockIServiceRepositoryMockRecorder) FindByDoctorID(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByDoctorID", reflect.TypeOf((*MockIServiceRepository)(nil).FindByDoctorID), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// FindByID mocks base method.
+func (m *MockIServiceRepository) FindByID(arg0 string) (*entities.ServiceModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret0, _ := ret[0].(*entities.ServiceModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockIServiceRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockIServiceRepository)(nil).FindByID), arg0)
+}
+
+// FindByOwnerID mocks base method.
+func (m *MockIServiceRepository) FindByOwnerID(arg0, arg1 string, arg2, arg3, arg4, arg5 int) ([]*entities.ServiceModel, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByOwnerID", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]*entities.ServiceModel)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindByOwnerID indicates an expected call of FindByOwnerID.
+func (mr *MockIServiceRepositoryMockRecorder) FindByOwnerID(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByOwnerID", reflect.TypeOf((*MockIServiceRepository)(nil).FindByOwnerID), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// FindByPaymentID mocks base method.
+func (m *MockIServiceRepository) FindByPaymentID(arg0 string) (*entities.ServiceModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPaymentID", arg0)
+	ret0, _ := ret[0].(*entities.ServiceModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPaymentID indicates an expected call of FindByPaymentID.
+func (mr *MockIServiceRepositoryMockRecorder) FindByPaymentID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPaymentID", reflect.TypeOf((*MockIServiceRepository)(nil).FindByPaymentID), arg0)
+}
+
+// FindByPetID mocks base method.
+func (m *MockIServiceRepository) FindByPetID(arg0 string, arg1 []db.ServiceStatus) ([]*entities.ServiceModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPetID", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.ServiceModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPetID indicates an expected call of FindByPetID.
+func (mr *MockIServiceRepositoryMockRecorder) FindByPetID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPetID", reflect.TypeOf((*MockIServiceRepository)(nil).FindByPetID), arg0, arg1)
+}
+
+// FindUpcomingByStaffID mocks base method.
+func (m *MockIServiceRepository) FindUpcomingByStaffID(arg0 string, arg1 time.Time, arg2 *time.Time) ([]*entities.ServiceModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUpcomingByStaffID", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.ServiceModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUpcomingByStaffID indicates an expected call of FindUpcomingByStaffID.
+func (mr *MockIServiceRepositoryMockRecorder) FindUpcomingByStaffID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUpcomingByStaffID", reflect.TypeOf((*MockIServiceRepository)(nil).FindUpcomingByStaffID), arg0, arg1, arg2)
+}
+
+// Insert mocks base method.
+func (m *MockIServiceRepository) Insert(arg0 entities.CreateServiceRequest) (*entities.ServiceModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", arg0)
+	ret0, _ := ret[0].(*entities.ServiceModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockIServiceRepositoryMockRecorder) Insert(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockIServiceRepository)(nil).Insert), arg0)
+}
+
+// InsertTx mocks base method.
+func (m *MockIServiceRepository) InsertTx(arg0 *repositories.Tx, arg1 string, arg2 entities.CreateServiceRequest) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InsertTx", arg0, arg1, arg2)
+}
+
+// InsertTx indicates an expected call of InsertTx.
+func (mr *MockIServiceRepositoryMockRecorder) InsertTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTx", reflect.TypeOf((*MockIServiceRepository)(nil).InsertTx), arg0, arg1, arg2)
+}
+
+// UpdateByID mocks base method.
+func (m *MockIServiceRepository) UpdateByID(arg0 string, arg1 entities.UpdateServiceRequest) (*entities.ServiceModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByID", arg0, arg1)
+	ret0, _ := ret[0].(*entities.ServiceModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateByID indicates an expected call of UpdateByID.
+func (mr *MockIServiceRepositoryMockRecorder) UpdateByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockIServiceRepository)(nil).UpdateByID), arg0, arg1)
+}
+
+// UpdateOwnerPetTx mocks base method.
+func (m *MockIServiceRepository) UpdateOwnerPetTx(arg0 *repositories.Tx, arg1 string, arg2, arg3 *string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateOwnerPetTx", arg0, arg1, arg2, arg3)
+}
+
+// UpdateOwnerPetTx indicates an expected call of UpdateOwnerPetTx.
+func (mr *MockIServiceRepositoryMockRecorder) UpdateOwnerPetTx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOwnerPetTx", reflect.TypeOf((*MockIServiceRepository)(nil).UpdateOwnerPetTx), arg0, arg1, arg2, arg3)
+}
+
+// UpdateReserveDateTx mocks base method.
+func (m *MockIServiceRepository) UpdateReserveDateTx(arg0 *repositories.Tx, arg1 string, arg2, arg3 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateReserveDateTx", arg0, arg1, arg2, arg3)
+}
+
+// UpdateReserveDateTx indicates an expected call of UpdateReserveDateTx.
+func (mr *MockIServiceRepositoryMockRecorder) UpdateReserveDateTx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReserveDateTx", reflect.TypeOf((*MockIServiceRepository)(nil).UpdateReserveDateTx), arg0, arg1, arg2, arg3)
+}
+
+// UpdateStatusTx mocks base method.
+func (m *MockIServiceRepository) UpdateStatusTx(arg0 *repositories.Tx, arg1 string, arg2, arg3 db.ServiceStatus) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateStatusTx", arg0, arg1, arg2, arg3)
+}
+
+// UpdateStatusTx indicates an expected call of UpdateStatusTx.
+func (mr *MockIServiceRepositoryMockRecorder) UpdateStatusTx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusTx", reflect.TypeOf((*MockIServiceRepository)(nil).UpdateStatusTx), arg0, arg1, arg2, arg3)
+}
+
+// MockICServiceRepository is a mock of ICServiceRepository interface.
+type MockICServiceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockICServiceRepositoryMockRecorder
+}
+
+// MockICServiceRepositoryMockRecorder is the mock recorder for MockICServiceRepository.
+type MockICServiceRepositoryMockRecorder struct {
+	mock *MockICServiceRepository
+}
+
+// NewMockICServiceRepository creates a new mock instance.
+func NewMockICServiceRepository(ctrl *gomock.Controller) *MockICServiceRepository {
+	mock := &MockICServiceRepository{ctrl: ctrl}
+	mock.recorder = &MockICServiceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockICServiceRepository) EXPECT() *MockICServiceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteByID mocks base method.
+func (m *MockICServiceRepository) DeleteByID(arg0 string) (*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByID", arg0)
+	ret0, _ := ret[0].(*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByID indicates an expected call of DeleteByID.
+func (mr *MockICServiceRepositoryMockRecorder) DeleteByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockICServiceRepository)(nil).DeleteByID), arg0)
+}
+
+// FindAll mocks base method.
+func (m *MockICServiceRepository) FindAll() ([]*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll")
+	ret0, _ := ret[0].([]*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockICServiceRepositoryMockRecorder) FindAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockICServiceRepository)(nil).FindAll))
+}
+
+// FindByCaretakerID mocks base method.
+func (m *MockICServiceRepository) FindByCaretakerID(arg0 string) ([]*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByCaretakerID", arg0)
+	ret0, _ := ret[0].([]*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByCaretakerID indicates an expected call of FindByCaretakerID.
+func (mr *MockICServiceRepositoryMockRecorder) FindByCaretakerID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCaretakerID", reflect.TypeOf((*MockICServiceRepository)(nil).FindByCaretakerID), arg0)
+}
+
+// FindByID mocks base method.
+func (m *MockICServiceRepository) FindByID(arg0 string) (*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret0, _ := ret[0].(*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockICServiceRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockICServiceRepository)(nil).FindByID), arg0)
+}
+
+// Insert mocks base method.
+func (m *MockICServiceRepository) Insert(arg0 entities.SubService) (*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", arg0)
+	ret0, _ := ret[0].(*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockICServiceRepositoryMockRecorder) Insert(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockICServiceRepository)(nil).Insert), arg0)
+}
+
+// InsertTx mocks base method.
+func (m *MockICServiceRepository) InsertTx(arg0 *repositories.Tx, arg1 entities.SubService) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InsertTx", arg0, arg1)
+}
+
+// InsertTx indicates an expected call of InsertTx.
+func (mr *MockICServiceRepositoryMockRecorder) InsertTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTx", reflect.TypeOf((*MockICServiceRepository)(nil).InsertTx), arg0, arg1)
+}
+
+// UpdateByID mocks base method.
+func (m *MockICServiceRepository) UpdateByID(arg0 entities.SubService) (*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByID", arg0)
+	ret0, _ := ret[0].(*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateByID indicates an expected call of UpdateByID.
+func (mr *MockICServiceRepositoryMockRecorder) UpdateByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockICServiceRepository)(nil).UpdateByID), arg0)
+}
+
+// UpdateReviewTx mocks base method.
+func (m *MockICServiceRepository) UpdateReviewTx(arg0 *repositories.Tx, arg1 string, arg2 *string, arg3 *int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateReviewTx", arg0, arg1, arg2, arg3)
+}
+
+// UpdateReviewTx indicates an expected call of UpdateReviewTx.
+func (mr *MockICServiceRepositoryMockRecorder) UpdateReviewTx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReviewTx", reflect.TypeOf((*MockICServiceRepository)(nil).UpdateReviewTx), arg0, arg1, arg2, arg3)
+}
+
+// UpdateStaffTx mocks base method.
+func (m *MockICServiceRepository) UpdateStaffTx(arg0 *repositories.Tx, arg1, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateStaffTx", arg0, arg1, arg2)
+}
+
+// UpdateStaffTx indicates an expected call of UpdateStaffTx.
+func (mr *MockICServiceRepositoryMockRecorder) UpdateStaffTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStaffTx", reflect.TypeOf((*MockICServiceRepository)(nil).UpdateStaffTx), arg0, arg1, arg2)
+}
+
+// MockIMServiceRepository is a mock of IMServiceRepository interface.
+type MockIMServiceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIMServiceRepositoryMockRecorder
+}
+
+// MockIMServiceRepositoryMockRecorder is the mock recorder for MockIMServiceRepository.
+type MockIMServiceRepositoryMockRecorder struct {
+	mock *MockIMServiceRepository
+}
+
+// NewMockIMServiceRepository creates a new mock instance.
+func NewMockIMServiceRepository(ctrl *gomock.Controller) *MockIMServiceRepository {
+	mock := &MockIMServiceRepository{ctrl: ctrl}
+	mock.recorder = &MockIMServiceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIMServiceRepository) EXPECT() *MockIMServiceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteByID mocks base method.
+func (m *MockIMServiceRepository) DeleteByID(arg0 string) (*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByID", arg0)
+	ret0, _ := ret[0].(*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByID indicates an expected call of DeleteByID.
+func (mr *MockIMServiceRepositoryMockRecorder) DeleteByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockIMServiceRepository)(nil).DeleteByID), arg0)
+}
+
+// FindAll mocks base method.
+func (m *MockIMServiceRepository) FindAll() ([]*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll")
+	ret0, _ := ret[0].([]*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockIMServiceRepositoryMockRecorder) FindAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockIMServiceRepository)(nil).FindAll))
+}
+
+// FindByDoctorID mocks base method.
+func (m *MockIMServiceRepository) FindByDoctorID(arg0 string) ([]*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByDoctorID", arg0)
+	ret0, _ := ret[0].([]*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByDoctorID indicates an expected call of FindByDoctorID.
+func (mr *MockIMServiceRepositoryMockRecorder) FindByDoctorID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByDoctorID", reflect.TypeOf((*MockIMServiceRepository)(nil).FindByDoctorID), arg0)
+}
+
+// FindByID mocks base method.
+func (m *MockIMServiceRepository) FindByID(arg0 string) (*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret0, _ := ret[0].(*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockIMServiceRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockIMServiceRepository)(nil).FindByID), arg0)
+}
+
+// Insert mocks base method.
+func (m *MockIMServiceRepository) Insert(arg0 entities.SubService) (*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", arg0)
+	ret0, _ := ret[0].(*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockIMServiceRepositoryMockRecorder) Insert(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockIMServiceRepository)(nil).Insert), arg0)
+}
+
+// InsertTx mocks base method.
+func (m *MockIMServiceRepository) InsertTx(arg0 *repositories.Tx, arg1 entities.SubService) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InsertTx", arg0, arg1)
+}
+
+// InsertTx indicates an expected call of InsertTx.
+func (mr *MockIMServiceRepositoryMockRecorder) InsertTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTx", reflect.TypeOf((*MockIMServiceRepository)(nil).InsertTx), arg0, arg1)
+}
+
+// UpdateByID mocks base method.
+func (m *MockIMServiceRepository) UpdateByID(arg0 entities.SubService) (*entities.SubService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByID", arg0)
+	ret0, _ := ret[0].(*entities.SubService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateByID indicates an expected call of UpdateByID.
+func (mr *MockIMServiceRepositoryMockRecorder) UpdateByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByID", reflect.TypeOf((*MockIMServiceRepository)(nil).UpdateByID), arg0)
+}
+
+// UpdateDiseaseTx mocks base method.
+func (m *MockIMServiceRepository) UpdateDiseaseTx(arg0 *repositories.Tx, arg1, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateDiseaseTx", arg0, arg1, arg2)
+}
+
+// UpdateDiseaseTx indicates an expected call of UpdateDiseaseTx.
+func (mr *MockIMServiceRepositoryMockRecorder) UpdateDiseaseTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDiseaseTx", reflect.TypeOf((*MockIMServiceRepository)(nil).UpdateDiseaseTx), arg0, arg1, arg2)
+}
+
+// UpdateStaffTx mocks base method.
+func (m *MockIMServiceRepository) UpdateStaffTx(arg0 *repositories.Tx, arg1, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateStaffTx", arg0, arg1, arg2)
+}
+
+// UpdateStaffTx indicates an expected call of UpdateStaffTx.
+func (mr *MockIMServiceRepositoryMockRecorder) UpdateStaffTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStaffTx", reflect.TypeOf((*MockIMServiceRepository)(nil).UpdateStaffTx), arg0, arg1, arg2)
+}
+
+// MockIUnitOfWork is a mock of IUnitOfWork interface.
+type MockIUnitOfWork struct {
+	ctrl     *gomock.Controller
+	recorder *MockIUnitOfWorkMockRecorder
+}
+
+// MockIUnitOfWorkMockRecorder is the mock recorder for MockIUnitOfWork.
+type MockIUnitOfWorkMockRecorder struct {
+	mock *MockIUnitOfWork
+}
+
+// NewMockIUnitOfWork creates a new mock instance.
+func NewMockIUnitOfWork(ctrl *gomock.Controller) *MockIUnitOfWork {
+	mock := &MockIUnitOfWork{ctrl: ctrl}
+	mock.recorder = &MockIUnitOfWorkMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIUnitOfWork) EXPECT() *MockIUnitOfWorkMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockIUnitOfWork) Begin() *repositories.Tx {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin")
+	ret0, _ := ret[0].(*repositories.Tx)
+	return ret0
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockIUnitOfWorkMockRecorder) Begin() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIUnitOfWork)(nil).Begin))
+}
+
+// Commit mocks base method.
+func (m *MockIUnitOfWork) Commit(arg0 *repositories.Tx) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockIUnitOfWorkMockRecorder) Commit(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockIUnitOfWork)(nil).Commit), arg0)
+}
+
+// MockIStaffHoldRepository is a mock of IStaffHoldRepository interface.
+type MockIStaffHoldRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIStaffHoldRepositoryMockRecorder
+}
+
+// MockIStaffHoldRepositoryMockRecorder is the mock recorder for MockIStaffHoldRepository.
+type MockIStaffHoldRepositoryMockRecorder struct {
+	mock *MockIStaffHoldRepository
+}
+
+// NewMockIStaffHoldRepository creates a new mock instance.
+func NewMockIStaffHoldRepository(ctrl *gomock.Controller) *MockIStaffHoldRepository {
+	mock := &MockIStaffHoldRepository{ctrl: ctrl}
+	mock.recorder = &MockIStaffHoldRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIStaffHoldRepository) EXPECT() *MockIStaffHoldRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteByPaymentID mocks base method.
+func (m *MockIStaffHoldRepository) DeleteByPaymentID(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByPaymentID", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByPaymentID indicates an expected call of DeleteByPaymentID.
+func (mr *MockIStaffHoldRepositoryMockRecorder) DeleteByPaymentID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByPaymentID", reflect.TypeOf((*MockIStaffHoldRepository)(nil).DeleteByPaymentID), arg0)
+}
+
+// DeleteByPaymentIDTx mocks base method.
+func (m *MockIStaffHoldRepository) DeleteByPaymentIDTx(arg0 *repositories.Tx, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteByPaymentIDTx", arg0, arg1)
+}
+
+// DeleteByPaymentIDTx indicates an expected call of DeleteByPaymentIDTx.
+func (mr *MockIStaffHoldRepositoryMockRecorder) DeleteByPaymentIDTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByPaymentIDTx", reflect.TypeOf((*MockIStaffHoldRepository)(nil).DeleteByPaymentIDTx), arg0, arg1)
+}
+
+// DeleteExpiredTx mocks base method.
+func (m *MockIStaffHoldRepository) DeleteExpiredTx(arg0 *repositories.Tx, arg1 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteExpiredTx", arg0, arg1)
+}
+
+// DeleteExpiredTx indicates an expected call of DeleteExpiredTx.
+func (mr *MockIStaffHoldRepositoryMockRecorder) DeleteExpiredTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredTx", reflect.TypeOf((*MockIStaffHoldRepository)(nil).DeleteExpiredTx), arg0, arg1)
+}
+
+// FindBusyRanges mocks base method.
+func (m *MockIStaffHoldRepository) FindBusyRanges(arg0 string, arg1, arg2 time.Time) ([]entities.TimeRangeModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBusyRanges", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entities.TimeRangeModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBusyRanges indicates an expected call of FindBusyRanges.
+func (mr *MockIStaffHoldRepositoryMockRecorder) FindBusyRanges(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBusyRanges", reflect.TypeOf((*MockIStaffHoldRepository)(nil).FindBusyRanges), arg0, arg1, arg2)
+}
+
+// GuardSlotTx mocks base method.
+func (m *MockIStaffHoldRepository) GuardSlotTx(arg0 *repositories.Tx, arg1 string, arg2, arg3 time.Time, arg4 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GuardSlotTx", arg0, arg1, arg2, arg3, arg4)
+}
+
+// GuardSlotTx indicates an expected call of GuardSlotTx.
+func (mr *MockIStaffHoldRepositoryMockRecorder) GuardSlotTx(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GuardSlotTx", reflect.TypeOf((*MockIStaffHoldRepository)(nil).GuardSlotTx), arg0, arg1, arg2, arg3, arg4)
+}
+
+// HasConflict mocks base method.
+func (m *MockIStaffHoldRepository) HasConflict(arg0 string, arg1, arg2 time.Time, arg3 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasConflict", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasConflict indicates an expected call of HasConflict.
+func (mr *MockIStaffHoldRepositoryMockRecorder) HasConflict(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasConflict", reflect.TypeOf((*MockIStaffHoldRepository)(nil).HasConflict), arg0, arg1, arg2, arg3)
+}
+
+// InsertTx mocks base method.
+func (m *MockIStaffHoldRepository) InsertTx(arg0 *repositories.Tx, arg1 entities.StaffHoldModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InsertTx", arg0, arg1)
+}
+
+// InsertTx indicates an expected call of InsertTx.
+func (mr *MockIStaffHoldRepositoryMockRecorder) InsertTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTx", reflect.TypeOf((*MockIStaffHoldRepository)(nil).InsertTx), arg0, arg1)
+}
+
+// MockIPricingRepository is a mock of IPricingRepository interface.
+type MockIPricingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPricingRepositoryMockRecorder
+}
+
+// MockIPricingRepositoryMockRecorder is the mock recorder for MockIPricingRepository.
+type MockIPricingRepositoryMockRecorder struct {
+	mock *MockIPricingRepository
+}
+
+// NewMockIPricingRepository creates a new mock instance.
+func NewMockIPricingRepository(ctrl *gomock.Controller) *MockIPricingRepository {
+	mock := &MockIPricingRepository{ctrl: ctrl}
+	mock.recorder = &MockIPricingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPricingRepository) EXPECT() *MockIPricingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteHoliday mocks base method.
+func (m *MockIPricingRepository) DeleteHoliday(arg0 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHoliday", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHoliday indicates an expected call of DeleteHoliday.
+func (mr *MockIPricingRepositoryMockRecorder) DeleteHoliday(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHoliday", reflect.TypeOf((*MockIPricingRepository)(nil).DeleteHoliday), arg0)
+}
+
+// DeleteMultiplier mocks base method.
+func (m *MockIPricingRepository) DeleteMultiplier(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMultiplier", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMultiplier indicates an expected call of DeleteMultiplier.
+func (mr *MockIPricingRepositoryMockRecorder) DeleteMultiplier(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMultiplier", reflect.TypeOf((*MockIPricingRepository)(nil).DeleteMultiplier), arg0)
+}
+
+// DeletePetSurcharge mocks base method.
+func (m *MockIPricingRepository) DeletePetSurcharge(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePetSurcharge", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePetSurcharge indicates an expected call of DeletePetSurcharge.
+func (mr *MockIPricingRepositoryMockRecorder) DeletePetSurcharge(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePetSurcharge", reflect.TypeOf((*MockIPricingRepository)(nil).DeletePetSurcharge), arg0)
+}
+
+// DeleteRateCard mocks base method.
+func (m *MockIPricingRepository) DeleteRateCard(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRateCard", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRateCard indicates an expected call of DeleteRateCard.
+func (mr *MockIPricingRepositoryMockRecorder) DeleteRateCard(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRateCard", reflect.TypeOf((*MockIPricingRepository)(nil).DeleteRateCard), arg0)
+}
+
+// DeleteStaffRate mocks base method.
+func (m *MockIPricingRepository) DeleteStaffRate(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaffRate", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStaffRate indicates an expected call of DeleteStaffRate.
+func (mr *MockIPricingRepositoryMockRecorder) DeleteStaffRate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaffRate", reflect.TypeOf((*MockIPricingRepository)(nil).DeleteStaffRate), arg0)
+}
+
+// FindAll mocks base method.
+func (m *MockIPricingRepository) FindAll() (*entities.PricingConfigModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll")
+	ret0, _ := ret[0].(*entities.PricingConfigModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll.
+func (mr *MockIPricingRepositoryMockRecorder) FindAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockIPricingRepository)(nil).FindAll))
+}
+
+// FindRules mocks base method.
+func (m *MockIPricingRepository) FindRules(arg0, arg1 string, arg2, arg3 time.Time) (*entities.PricingRules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRules", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*entities.PricingRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRules indicates an expected call of FindRules.
+func (mr *MockIPricingRepositoryMockRecorder) FindRules(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRules", reflect.TypeOf((*MockIPricingRepository)(nil).FindRules), arg0, arg1, arg2, arg3)
+}
+
+// InsertMultiplier mocks base method.
+func (m *MockIPricingRepository) InsertMultiplier(arg0 entities.PriceMultiplierModel) (*entities.PriceMultiplierModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertMultiplier", arg0)
+	ret0, _ := ret[0].(*entities.PriceMultiplierModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertMultiplier indicates an expected call of InsertMultiplier.
+func (mr *MockIPricingRepositoryMockRecorder) InsertMultiplier(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertMultiplier", reflect.TypeOf((*MockIPricingRepository)(nil).InsertMultiplier), arg0)
+}
+
+// InsertPetSurcharge mocks base method.
+func (m *MockIPricingRepository) InsertPetSurcharge(arg0 entities.PetSurchargeModel) (*entities.PetSurchargeModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPetSurcharge", arg0)
+	ret0, _ := ret[0].(*entities.PetSurchargeModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertPetSurcharge indicates an expected call of InsertPetSurcharge.
+func (mr *MockIPricingRepositoryMockRecorder) InsertPetSurcharge(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPetSurcharge", reflect.TypeOf((*MockIPricingRepository)(nil).InsertPetSurcharge), arg0)
+}
+
+// UpdateMultiplier mocks base method.
+func (m *MockIPricingRepository) UpdateMultiplier(arg0 string, arg1 entities.PriceMultiplierModel) (*entities.PriceMultiplierModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMultiplier", arg0, arg1)
+	ret0, _ := ret[0].(*entities.PriceMultiplierModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMultiplier indicates an expected call of UpdateMultiplier.
+func (mr *MockIPricingRepositoryMockRecorder) UpdateMultiplier(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMultiplier", reflect.TypeOf((*MockIPricingRepository)(nil).UpdateMultiplier), arg0, arg1)
+}
+
+// UpdatePetSurcharge mocks base method.
+func (m *MockIPricingRepository) UpdatePetSurcharge(arg0 string, arg1 entities.PetSurchargeModel) (*entities.PetSurchargeModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePetSurcharge", arg0, arg1)
+	ret0, _ := ret[0].(*entities.PetSurchargeModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePetSurcharge indicates an expected call of UpdatePetSurcharge.
+func (mr *MockIPricingRepositoryMockRecorder) UpdatePetSurcharge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePetSurcharge", reflect.TypeOf((*MockIPricingRepository)(nil).UpdatePetSurcharge), arg0, arg1)
+}
+
+// UpsertHoliday mocks base method.
+func (m *MockIPricingRepository) UpsertHoliday(arg0 time.Time, arg1 string) (*entities.HolidayModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertHoliday", arg0, arg1)
+	ret0, _ := ret[0].(*entities.HolidayModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertHoliday indicates an expected call of UpsertHoliday.
+func (mr *MockIPricingRepositoryMockRecorder) UpsertHoliday(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertHoliday", reflect.TypeOf((*MockIPricingRepository)(nil).UpsertHoliday), arg0, arg1)
+}
+
+// UpsertRateCard mocks base method.
+func (m *MockIPricingRepository) UpsertRateCard(arg0 string, arg1 int) (*entities.RateCardModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertRateCard", arg0, arg1)
+	ret0, _ := ret[0].(*entities.RateCardModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertRateCard indicates an expected call of UpsertRateCard.
+func (mr *MockIPricingRepositoryMockRecorder) UpsertRateCard(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertRateCard", reflect.TypeOf((*MockIPricingRepository)(nil).UpsertRateCard), arg0, arg1)
+}
+
+// UpsertStaffRate mocks base method.
+func (m *MockIPricingRepository) UpsertStaffRate(arg0 string, arg1 int) (*entities.StaffRateModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertStaffRate", arg0, arg1)
+	ret0, _ := ret[0].(*entities.StaffRateModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertStaffRate indicates an expected call of UpsertStaffRate.
+func (mr *MockIPricingRepositoryMockRecorder) UpsertStaffRate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertStaffRate", reflect.TypeOf((*MockIPricingRepository)(nil).UpsertStaffRate), arg0, arg1)
+}
+
+// MockIJobLockRepository is a mock of IJobLockRepository interface.
+type MockIJobLockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIJobLockRepositoryMockRecorder
+}
+
+// MockIJobLockRepositoryMockRecorder is the mock recorder for MockIJobLockRepository.
+type MockIJobLockRepositoryMockRecorder struct {
+	mock *MockIJobLockRepository
+}
+
+// NewMockIJobLockRepository creates a new mock instance.
+func NewMockIJobLockRepository(ctrl *gomock.Controller) *MockIJobLockRepository {
+	mock := &MockIJobLockRepository{ctrl: ctrl}
+	mock.recorder = &MockIJobLockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIJobLockRepository) EXPECT() *MockIJobLockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// TryLockTx mocks base method.
+func (m *MockIJobLockRepository) TryLockTx(arg0 *repositories.Tx, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "TryLockTx", arg0, arg1)
+}
+
+// TryLockTx indicates an expected call of TryLockTx.
+func (mr *MockIJobLockRepositoryMockRecorder) TryLockTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryLockTx", reflect.TypeOf((*MockIJobLockRepository)(nil).TryLockTx), arg0, arg1)
+}
+
+// MockIServiceCancellationRepository is a mock of IServiceCancellationRepository interface.
+type MockIServiceCancellationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIServiceCancellationRepositoryMockRecorder
+}
+
+// MockIServiceCancellationRepositoryMockRecorder is the mock recorder for MockIServiceCancellationRepository.
+type MockIServiceCancellationRepositoryMockRecorder struct {
+	mock *MockIServiceCancellationRepository
+}
+
+// NewMockIServiceCancellationRepository creates a new mock instance.
+func NewMockIServiceCancellationRepository(ctrl *gomock.Controller) *MockIServiceCancellationRepository {
+	mock := &MockIServiceCancellationRepository{ctrl: ctrl}
+	mock.recorder = &MockIServiceCancellationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIServiceCancellationRepository) EXPECT() *MockIServiceCancellationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CompleteTx mocks base method.
+func (m *MockIServiceCancellationRepository) CompleteTx(arg0 *repositories.Tx, arg1 entities.ServiceCancellationModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CompleteTx", arg0, arg1)
+}
+
+// CompleteTx indicates an expected call of CompleteTx.
+func (mr *MockIServiceCancellationRepositoryMockRecorder) CompleteTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTx", reflect.TypeOf((*MockIServiceCancellationRepository)(nil).CompleteTx), arg0, arg1)
+}
+
+// FindPendingByServiceID mocks base method.
+func (m *MockIServiceCancellationRepository) FindPendingByServiceID(arg0 string) (*entities.ServiceCancellationModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPendingByServiceID", arg0)
+	ret0, _ := ret[0].(*entities.ServiceCancellationModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPendingByServiceID indicates an expected call of FindPendingByServiceID.
+func (mr *MockIServiceCancellationRepositoryMockRecorder) FindPendingByServiceID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPendingByServiceID", reflect.TypeOf((*MockIServiceCancellationRepository)(nil).FindPendingByServiceID), arg0)
+}
+
+// HasPendingByPaymentID mocks base method.
+func (m *MockIServiceCancellationRepository) HasPendingByPaymentID(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPendingByPaymentID", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasPendingByPaymentID indicates an expected call of HasPendingByPaymentID.
+func (mr *MockIServiceCancellationRepositoryMockRecorder) HasPendingByPaymentID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPendingByPaymentID", reflect.TypeOf((*MockIServiceCancellationRepository)(nil).HasPendingByPaymentID), arg0)
+}
+
+// InsertPending mocks base method.
+func (m *MockIServiceCancellationRepository) InsertPending(arg0 entities.ServiceCancellationModel) (*entities.ServiceCancellationModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPending", arg0)
+	ret0, _ := ret[0].(*entities.ServiceCancellationModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertPending indicates an expected call of InsertPending.
+func (mr *MockIServiceCancellationRepositoryMockRecorder) InsertPending(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPending", reflect.TypeOf((*MockIServiceCancellationRepository)(nil).InsertPending), arg0)
+}
+
+// MockIServiceRescheduleRepository is a mock of IServiceRescheduleRepository interface.
+type MockIServiceRescheduleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIServiceRescheduleRepositoryMockRecorder
+}
+
+// MockIServiceRescheduleRepositoryMockRecorder is the mock recorder for MockIServiceRescheduleRepository.
+type MockIServiceRescheduleRepositoryMockRecorder struct {
+	mock *MockIServiceRescheduleRepository
+}
+
+// NewMockIServiceRescheduleRepository creates a new mock instance.
+func NewMockIServiceRescheduleRepository(ctrl *gomock.Controller) *MockIServiceRescheduleRepository {
+	mock := &MockIServiceRescheduleRepository{ctrl: ctrl}
+	mock.recorder = &MockIServiceRescheduleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIServiceRescheduleRepository) EXPECT() *MockIServiceRescheduleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CompleteTx mocks base method.
+func (m *MockIServiceRescheduleRepository) CompleteTx(arg0 *repositories.Tx, arg1 entities.ServiceRescheduleModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CompleteTx", arg0, arg1)
+}
+
+// CompleteTx indicates an expected call of CompleteTx.
+func (mr *MockIServiceRescheduleRepositoryMockRecorder) CompleteTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTx", reflect.TypeOf((*MockIServiceRescheduleRepository)(nil).CompleteTx), arg0, arg1)
+}
+
+// FindByID mocks base method.
+func (m *MockIServiceRescheduleRepository) FindByID(arg0 string) (*entities.ServiceRescheduleModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret0, _ := ret[0].(*entities.ServiceRescheduleModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockIServiceRescheduleRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockIServiceRescheduleRepository)(nil).FindByID), arg0)
+}
+
+// FindPending mocks base method.
+func (m *MockIServiceRescheduleRepository) FindPending(arg0 string) (*entities.ServiceRescheduleModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPending", arg0)
+	ret0, _ := ret[0].(*entities.ServiceRescheduleModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPending indicates an expected call of FindPending.
+func (mr *MockIServiceRescheduleRepositoryMockRecorder) FindPending(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPending", reflect.TypeOf((*MockIServiceRescheduleRepository)(nil).FindPending), arg0)
+}
+
+// InsertTx mocks base method.
+func (m *MockIServiceRescheduleRepository) InsertTx(arg0 *repositories.Tx, arg1 entities.ServiceRescheduleModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InsertTx", arg0, arg1)
+}
+
+// InsertTx indicates an expected call of InsertTx.
+func (mr *MockIServiceRescheduleRepositoryMockRecorder) InsertTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTx", reflect.TypeOf((*MockIServiceRescheduleRepository)(nil).InsertTx), arg0, arg1)
+}
+
+// UpdateStatusTx mocks base method.
+func (m *MockIServiceRescheduleRepository) UpdateStatusTx(arg0 *repositories.Tx, arg1 string, arg2, arg3 db.RescheduleStatus) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateStatusTx", arg0, arg1, arg2, arg3)
+}
+
+// UpdateStatusTx indicates an expected call of UpdateStatusTx.
+func (mr *MockIServiceRescheduleRepositoryMockRecorder) UpdateStatusTx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusTx", reflect.TypeOf((*MockIServiceRescheduleRepository)(nil).UpdateStatusTx), arg0, arg1, arg2, arg3)
+}
+
+// MockIServiceStatusHistoryRepository is a mock of IServiceStatusHistoryRepository interface.
+type MockIServiceStatusHistoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIServiceStatusHistoryRepositoryMockRecorder
+}
+
+// MockIServiceStatusHistoryRepositoryMockRecorder is the mock recorder for MockIServiceStatusHistoryRepository.
+type MockIServiceStatusHistoryRepositoryMockRecorder struct {
+	mock *MockIServiceStatusHistoryRepository
+}
+
+// NewMockIServiceStatusHistoryRepository creates a new mock instance.
+func NewMockIServiceStatusHistoryRepository(ctrl *gomock.Controller) *MockIServiceStatusHistoryRepository {
+	mock := &MockIServiceStatusHistoryRepository{ctrl: ctrl}
+	mock.recorder = &MockIServiceStatusHistoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIServiceStatusHistoryRepository) EXPECT() *MockIServiceStatusHistoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByServiceID mocks base method.
+func (m *MockIServiceStatusHistoryRepository) FindByServiceID(arg0 string) ([]*entities.ServiceStatusHistoryModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByServiceID", arg0)
+	ret0, _ := ret[0].([]*entities.ServiceStatusHistoryModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByServiceID indicates an expected call of FindByServiceID.
+func (mr *MockIServiceStatusHistoryRepositoryMockRecorder) FindByServiceID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByServiceID", reflect.TypeOf((*MockIServiceStatusHistoryRepository)(nil).FindByServiceID), arg0)
+}
+
+// InsertTx mocks base method.
+func (m *MockIServiceStatusHistoryRepository) InsertTx(arg0 *repositories.Tx, arg1 entities.ServiceStatusHistoryModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InsertTx", arg0, arg1)
+}
+
+// InsertTx indicates an expected call of InsertTx.
+func (mr *MockIServiceStatusHistoryRepositoryMockRecorder) InsertTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTx", reflect.TypeOf((*MockIServiceStatusHistoryRepository)(nil).InsertTx), arg0, arg1)
+}
+
+// MockIStaffScheduleRepository is a mock of IStaffScheduleRepository interface.
+type MockIStaffScheduleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIStaffScheduleRepositoryMockRecorder
+}
+
+// MockIStaffScheduleRepositoryMockRecorder is the mock recorder for MockIStaffScheduleRepository.
+type MockIStaffScheduleRepositoryMockRecorder struct {
+	mock *MockIStaffScheduleRepository
+}
+
+// NewMockIStaffScheduleRepository creates a new mock instance.
+func NewMockIStaffScheduleRepository(ctrl *gomock.Controller) *MockIStaffScheduleRepository {
+	mock := &MockIStaffScheduleRepository{ctrl: ctrl}
+	mock.recorder = &MockIStaffScheduleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIStaffScheduleRepository) EXPECT() *MockIStaffScheduleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteException mocks base method.
+func (m *MockIStaffScheduleRepository) DeleteException(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteException", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteException indicates an expected call of DeleteException.
+func (mr *MockIStaffScheduleRepositoryMockRecorder) DeleteException(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteException", reflect.TypeOf((*MockIStaffScheduleRepository)(nil).DeleteException), arg0)
+}
+
+// FindByStaffID mocks base method.
+func (m *MockIStaffScheduleRepository) FindByStaffID(arg0 string) ([]entities.StaffShiftModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByStaffID", arg0)
+	ret0, _ := ret[0].([]entities.StaffShiftModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByStaffID indicates an expected call of FindByStaffID.
+func (mr *MockIStaffScheduleRepositoryMockRecorder) FindByStaffID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStaffID", reflect.TypeOf((*MockIStaffScheduleRepository)(nil).FindByStaffID), arg0)
+}
+
+// FindByStaffIDs mocks base method.
+func (m *MockIStaffScheduleRepository) FindByStaffIDs(arg0 []string) (map[string][]entities.StaffShiftModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByStaffIDs", arg0)
+	ret0, _ := ret[0].(map[string][]entities.StaffShiftModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByStaffIDs indicates an expected call of FindByStaffIDs.
+func (mr *MockIStaffScheduleRepositoryMockRecorder) FindByStaffIDs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStaffIDs", reflect.TypeOf((*MockIStaffScheduleRepository)(nil).FindByStaffIDs), arg0)
+}
+
+// FindExceptionByID mocks base method.
+func (m *MockIStaffScheduleRepository) FindExceptionByID(arg0 string) (*entities.StaffScheduleExceptionModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindExceptionByID", arg0)
+	ret0, _ := ret[0].(*entities.StaffScheduleExceptionModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindExceptionByID indicates an expected call of FindExceptionByID.
+func (mr *MockIStaffScheduleRepositoryMockRecorder) FindExceptionByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindExceptionByID", reflect.TypeOf((*MockIStaffScheduleRepository)(nil).FindExceptionByID), arg0)
+}
+
+// FindExceptions mocks base method.
+func (m *MockIStaffScheduleRepository) FindExceptions(arg0 []string, arg1, arg2 time.Time) ([]entities.StaffScheduleExceptionModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindExceptions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entities.StaffScheduleExceptionModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindExceptions indicates an expected call of FindExceptions.
+func (mr *MockIStaffScheduleRepositoryMockRecorder) FindExceptions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindExceptions", reflect.TypeOf((*MockIStaffScheduleRepository)(nil).FindExceptions), arg0, arg1, arg2)
+}
+
+// InsertException mocks base method.
+func (m *MockIStaffScheduleRepository) InsertException(arg0 entities.StaffScheduleExceptionModel) (*entities.StaffScheduleExceptionModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertException", arg0)
+	ret0, _ := ret[0].(*entities.StaffScheduleExceptionModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertException indicates an expected call of InsertException.
+func (mr *MockIStaffScheduleRepositoryMockRecorder) InsertException(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertException", reflect.TypeOf((*MockIStaffScheduleRepository)(nil).InsertException), arg0)
+}
+
+// ReplaceTx mocks base method.
+func (m *MockIStaffScheduleRepository) ReplaceTx(arg0 *repositories.Tx, arg1 string, arg2 []entities.StaffShiftModel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceTx indicates an expected call of ReplaceTx.
+func (mr *MockIStaffScheduleRepositoryMockRecorder) ReplaceTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceTx", reflect.TypeOf((*MockIStaffScheduleRepository)(nil).ReplaceTx), arg0, arg1, arg2)
+}
+
+// MockILeavedayRepository is a mock of ILeavedayRepository interface.
+type MockILeavedayRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockILeavedayRepositoryMockRecorder
+}
+
+// MockILeavedayRepositoryMockRecorder is the mock recorder for MockILeavedayRepository.
+type MockILeavedayRepositoryMockRecorder struct {
+	mock *MockILeavedayRepository
+}
+
+// NewMockILeavedayRepository creates a new mock instance.
+func NewMockILeavedayRepository(ctrl *gomock.Controller) *MockILeavedayRepository {
+	mock := &MockILeavedayRepository{ctrl: ctrl}
+	mock.recorder = &MockILeavedayRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockILeavedayRepository) EXPECT() *MockILeavedayRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindRequestByID mocks base method.
+func (m *MockILeavedayRepository) FindRequestByID(arg0 string) (*entities.LeaveRequestModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRequestByID", arg0)
+	ret0, _ := ret[0].(*entities.LeaveRequestModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRequestByID indicates an expected call of FindRequestByID.
+func (mr *MockILeavedayRepositoryMockRecorder) FindRequestByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRequestByID", reflect.TypeOf((*MockILeavedayRepository)(nil).FindRequestByID), arg0)
+}
+
+// FindRequests mocks base method.
+func (m *MockILeavedayRepository) FindRequests(arg0 entities.LeaveFilter) ([]*entities.LeaveRequestModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRequests", arg0)
+	ret0, _ := ret[0].([]*entities.LeaveRequestModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRequests indicates an expected call of FindRequests.
+func (mr *MockILeavedayRepositoryMockRecorder) FindRequests(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRequests", reflect.TypeOf((*MockILeavedayRepository)(nil).FindRequests), arg0)
+}
+
+// GuardRangeTx mocks base method.
+func (m *MockILeavedayRepository) GuardRangeTx(arg0 *repositories.Tx, arg1 string, arg2, arg3, arg4, arg5 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GuardRangeTx", arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// GuardRangeTx indicates an expected call of GuardRangeTx.
+func (mr *MockILeavedayRepositoryMockRecorder) GuardRangeTx(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GuardRangeTx", reflect.TypeOf((*MockILeavedayRepository)(nil).GuardRangeTx), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// InsertRequestTx mocks base method.
+func (m *MockILeavedayRepository) InsertRequestTx(arg0 *repositories.Tx, arg1 entities.LeaveRequestModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InsertRequestTx", arg0, arg1)
+}
+
+// InsertRequestTx indicates an expected call of InsertRequestTx.
+func (mr *MockILeavedayRepositoryMockRecorder) InsertRequestTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRequestTx", reflect.TypeOf((*MockILeavedayRepository)(nil).InsertRequestTx), arg0, arg1)
+}
+
+// UpdateStatusTx mocks base method.
+func (m *MockILeavedayRepository) UpdateStatusTx(arg0 *repositories.Tx, arg1 string, arg2 []db.LeaveStatus, arg3 db.LeaveStatus, arg4 *string, arg5 *time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateStatusTx", arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// UpdateStatusTx indicates an expected call of UpdateStatusTx.
+func (mr *MockILeavedayRepositoryMockRecorder) UpdateStatusTx(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusTx", reflect.TypeOf((*MockILeavedayRepository)(nil).UpdateStatusTx), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// MockIServiceReassignmentRepository is a mock of IServiceReassignmentRepository interface.
+type MockIServiceReassignmentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIServiceReassignmentRepositoryMockRecorder
+}
+
+// MockIServiceReassignmentRepositoryMockRecorder is the mock recorder for MockIServiceReassignmentRepository.
+type MockIServiceReassignmentRepositoryMockRecorder struct {
+	mock *MockIServiceReassignmentRepository
+}
+
+// NewMockIServiceReassignmentRepository creates a new mock instance.
+func NewMockIServiceReassignmentRepository(ctrl *gomock.Controller) *MockIServiceReassignmentRepository {
+	mock := &MockIServiceReassignmentRepository{ctrl: ctrl}
+	mock.recorder = &MockIServiceReassignmentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIServiceReassignmentRepository) EXPECT() *MockIServiceReassignmentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CloseTx mocks base method.
+func (m *MockIServiceReassignmentRepository) CloseTx(arg0 *repositories.Tx, arg1 string, arg2 db.ReassignmentStatus, arg3 *string, arg4 string, arg5 time.Time, arg6 *string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CloseTx", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+// CloseTx indicates an expected call of CloseTx.
+func (mr *MockIServiceReassignmentRepositoryMockRecorder) CloseTx(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseTx", reflect.TypeOf((*MockIServiceReassignmentRepository)(nil).CloseTx), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+// FindByID mocks base method.
+func (m *MockIServiceReassignmentRepository) FindByID(arg0 string) (*entities.ServiceReassignmentModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret0, _ := ret[0].(*entities.ServiceReassignmentModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockIServiceReassignmentRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockIServiceReassignmentRepository)(nil).FindByID), arg0)
+}
+
+// FindByServiceID mocks base method.
+func (m *MockIServiceReassignmentRepository) FindByServiceID(arg0 string) ([]*entities.ServiceReassignmentModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByServiceID", arg0)
+	ret0, _ := ret[0].([]*entities.ServiceReassignmentModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByServiceID indicates an expected call of FindByServiceID.
+func (mr *MockIServiceReassignmentRepositoryMockRecorder) FindByServiceID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByServiceID", reflect.TypeOf((*MockIServiceReassignmentRepository)(nil).FindByServiceID), arg0)
+}
+
+// FindByStatus mocks base method.
+func (m *MockIServiceReassignmentRepository) FindByStatus(arg0 db.ReassignmentStatus) ([]*entities.ServiceReassignmentModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByStatus", arg0)
+	ret0, _ := ret[0].([]*entities.ServiceReassignmentModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByStatus indicates an expected call of FindByStatus.
+func (mr *MockIServiceReassignmentRepositoryMockRecorder) FindByStatus(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStatus", reflect.TypeOf((*MockIServiceReassignmentRepository)(nil).FindByStatus), arg0)
+}
+
+// InsertTx mocks base method.
+func (m *MockIServiceReassignmentRepository) InsertTx(arg0 *repositories.Tx, arg1 entities.ServiceReassignmentModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InsertTx", arg0, arg1)
+}
+
+// InsertTx indicates an expected call of InsertTx.
+func (mr *MockIServiceReassignmentRepositoryMockRecorder) InsertTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTx", reflect.TypeOf((*MockIServiceReassignmentRepository)(nil).InsertTx), arg0, arg1)
+}
+
+// MockIMedicalRecordRepository is a mock of IMedicalRecordRepository interface.
+type MockIMedicalRecordRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIMedicalRecordRepositoryMockRecorder
+}
+
+// MockIMedicalRecordRepositoryMockRecorder is the mock recorder for MockIMedicalRecordRepository.
+type MockIMedicalRecordRepositoryMockRecorder struct {
+	mock *MockIMedicalRecordRepository
+}
+
+// NewMockIMedicalRecordRepository creates a new mock instance.
+func NewMockIMedicalRecordRepository(ctrl *gomock.Controller) *MockIMedicalRecordRepository {
+	mock := &MockIMedicalRecordRepository{ctrl: ctrl}
+	mock.recorder = &MockIMedicalRecordRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIMedicalRecordRepository) EXPECT() *MockIMedicalRecordRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByPetID mocks base method.
+func (m *MockIMedicalRecordRepository) FindByPetID(arg0 string) ([]*entities.MedicalRecordModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPetID", arg0)
+	ret0, _ := ret[0].([]*entities.MedicalRecordModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPetID indicates an expected call of FindByPetID.
+func (mr *MockIMedicalRecordRepositoryMockRecorder) FindByPetID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPetID", reflect.TypeOf((*MockIMedicalRecordRepository)(nil).FindByPetID), arg0)
+}
+
+// FindByServiceID mocks base method.
+func (m *MockIMedicalRecordRepository) FindByServiceID(arg0 string) (*entities.MedicalRecordModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByServiceID", arg0)
+	ret0, _ := ret[0].(*entities.MedicalRecordModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByServiceID indicates an expected call of FindByServiceID.
+func (mr *MockIMedicalRecordRepositoryMockRecorder) FindByServiceID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByServiceID", reflect.TypeOf((*MockIMedicalRecordRepository)(nil).FindByServiceID), arg0)
+}
+
+// UpsertTx mocks base method.
+func (m *MockIMedicalRecordRepository) UpsertTx(arg0 *repositories.Tx, arg1 entities.MedicalRecordModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpsertTx", arg0, arg1)
+}
+
+// UpsertTx indicates an expected call of UpsertTx.
+func (mr *MockIMedicalRecordRepositoryMockRecorder) UpsertTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTx", reflect.TypeOf((*MockIMedicalRecordRepository)(nil).UpsertTx), arg0, arg1)
+}
+
+// MockIMedicineRepository is a mock of IMedicineRepository interface.
+type MockIMedicineRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIMedicineRepositoryMockRecorder
+}
+
+// MockIMedicineRepositoryMockRecorder is the mock recorder for MockIMedicineRepository.
+type MockIMedicineRepositoryMockRecorder struct {
+	mock *MockIMedicineRepository
+}
+
+// NewMockIMedicineRepository creates a new mock instance.
+func NewMockIMedicineRepository(ctrl *gomock.Controller) *MockIMedicineRepository {
+	mock := &MockIMedicineRepository{ctrl: ctrl}
+	mock.recorder = &MockIMedicineRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIMedicineRepository) EXPECT() *MockIMedicineRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByServiceID mocks base method.
+func (m *MockIMedicineRepository) FindByServiceID(arg0 string) ([]entities.MedicineModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByServiceID", arg0)
+	ret0, _ := ret[0].([]entities.MedicineModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByServiceID indicates an expected call of FindByServiceID.
+func (mr *MockIMedicineRepositoryMockRecorder) FindByServiceID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByServiceID", reflect.TypeOf((*MockIMedicineRepository)(nil).FindByServiceID), arg0)
+}
+
+// FindByServiceIDs mocks base method.
+func (m *MockIMedicineRepository) FindByServiceIDs(arg0 []string) (map[string][]entities.MedicineModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByServiceIDs", arg0)
+	ret0, _ := ret[0].(map[string][]entities.MedicineModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByServiceIDs indicates an expected call of FindByServiceIDs.
+func (mr *MockIMedicineRepositoryMockRecorder) FindByServiceIDs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByServiceIDs", reflect.TypeOf((*MockIMedicineRepository)(nil).FindByServiceIDs), arg0)
+}
+
+// ReplaceTx mocks base method.
+func (m *MockIMedicineRepository) ReplaceTx(arg0 *repositories.Tx, arg1 string, arg2 []entities.MedicineModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReplaceTx", arg0, arg1, arg2)
+}
+
+// ReplaceTx indicates an expected call of ReplaceTx.
+func (mr *MockIMedicineRepositoryMockRecorder) ReplaceTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceTx", reflect.TypeOf((*MockIMedicineRepository)(nil).ReplaceTx), arg0, arg1, arg2)
+}
+
+// MockIVaccinationRepository is a mock of IVaccinationRepository interface.
+type MockIVaccinationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIVaccinationRepositoryMockRecorder
+}
+
+// MockIVaccinationRepositoryMockRecorder is the mock recorder for MockIVaccinationRepository.
+type MockIVaccinationRepositoryMockRecorder struct {
+	mock *MockIVaccinationRepository
+}
+
+// NewMockIVaccinationRepository creates a new mock instance.
+func NewMockIVaccinationRepository(ctrl *gomock.Controller) *MockIVaccinationRepository {
+	mock := &MockIVaccinationRepository{ctrl: ctrl}
+	mock.recorder = &MockIVaccinationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIVaccinationRepository) EXPECT() *MockIVaccinationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimRemindersTx mocks base method.
+func (m *MockIVaccinationRepository) ClaimRemindersTx(arg0 *repositories.Tx, arg1 []string, arg2 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ClaimRemindersTx", arg0, arg1, arg2)
+}
+
+// ClaimRemindersTx indicates an expected call of ClaimRemindersTx.
+func (mr *MockIVaccinationRepositoryMockRecorder) ClaimRemindersTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimRemindersTx", reflect.TypeOf((*MockIVaccinationRepository)(nil).ClaimRemindersTx), arg0, arg1, arg2)
+}
+
+// FindByID mocks base method.
+func (m *MockIVaccinationRepository) FindByID(arg0 string) (*entities.VaccinationModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret0, _ := ret[0].(*entities.VaccinationModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockIVaccinationRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockIVaccinationRepository)(nil).FindByID), arg0)
+}
+
+// FindByPetID mocks base method.
+func (m *MockIVaccinationRepository) FindByPetID(arg0 string) ([]*entities.VaccinationModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPetID", arg0)
+	ret0, _ := ret[0].([]*entities.VaccinationModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPetID indicates an expected call of FindByPetID.
+func (mr *MockIVaccinationRepositoryMockRecorder) FindByPetID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPetID", reflect.TypeOf((*MockIVaccinationRepository)(nil).FindByPetID), arg0)
+}
+
+// FindDue mocks base method.
+func (m *MockIVaccinationRepository) FindDue(arg0 time.Time, arg1 bool) ([]*entities.VaccinationDueModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDue", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.VaccinationDueModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDue indicates an expected call of FindDue.
+func (mr *MockIVaccinationRepositoryMockRecorder) FindDue(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDue", reflect.TypeOf((*MockIVaccinationRepository)(nil).FindDue), arg0, arg1)
+}
+
+// InsertTx mocks base method.
+func (m *MockIVaccinationRepository) InsertTx(arg0 *repositories.Tx, arg1 entities.VaccinationModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InsertTx", arg0, arg1)
+}
+
+// InsertTx indicates an expected call of InsertTx.
+func (mr *MockIVaccinationRepositoryMockRecorder) InsertTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTx", reflect.TypeOf((*MockIVaccinationRepository)(nil).InsertTx), arg0, arg1)
+}
+
+// ReleaseReminder mocks base method.
+func (m *MockIVaccinationRepository) ReleaseReminder(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseReminder", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseReminder indicates an expected call of ReleaseReminder.
+func (mr *MockIVaccinationRepositoryMockRecorder) ReleaseReminder(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseReminder", reflect.TypeOf((*MockIVaccinationRepository)(nil).ReleaseReminder), arg0)
+}
+
+// SupersedeTx mocks base method.
+func (m *MockIVaccinationRepository) SupersedeTx(arg0 *repositories.Tx, arg1 []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SupersedeTx", arg0, arg1)
+}
+
+// SupersedeTx indicates an expected call of SupersedeTx.
+func (mr *MockIVaccinationRepositoryMockRecorder) SupersedeTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SupersedeTx", reflect.TypeOf((*MockIVaccinationRepository)(nil).SupersedeTx), arg0, arg1)
+}
+
+// MockIPetWeightRepository is a mock of IPetWeightRepository interface.
+type MockIPetWeightRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPetWeightRepositoryMockRecorder
+}
+
+// MockIPetWeightRepositoryMockRecorder is the mock recorder for MockIPetWeightRepository.
+type MockIPetWeightRepositoryMockRecorder struct {
+	mock *MockIPetWeightRepository
+}
+
+// NewMockIPetWeightRepository creates a new mock instance.
+func NewMockIPetWeightRepository(ctrl *gomock.Controller) *MockIPetWeightRepository {
+	mock := &MockIPetWeightRepository{ctrl: ctrl}
+	mock.recorder = &MockIPetWeightRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPetWeightRepository) EXPECT() *MockIPetWeightRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteVitalsTx mocks base method.
+func (m *MockIPetWeightRepository) DeleteVitalsTx(arg0 *repositories.Tx, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteVitalsTx", arg0, arg1)
+}
+
+// DeleteVitalsTx indicates an expected call of DeleteVitalsTx.
+func (mr *MockIPetWeightRepositoryMockRecorder) DeleteVitalsTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVitalsTx", reflect.TypeOf((*MockIPetWeightRepository)(nil).DeleteVitalsTx), arg0, arg1)
+}
+
+// FindByPetID mocks base method.
+func (m *MockIPetWeightRepository) FindByPetID(arg0 string, arg1, arg2 *time.Time) ([]*entities.PetWeightModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPetID", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.PetWeightModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPetID indicates an expected call of FindByPetID.
+func (mr *MockIPetWeightRepositoryMockRecorder) FindByPetID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPetID", reflect.TypeOf((*MockIPetWeightRepository)(nil).FindByPetID), arg0, arg1, arg2)
+}
+
+// FindLatest mocks base method.
+func (m *MockIPetWeightRepository) FindLatest(arg0 string) (*entities.PetWeightModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLatest", arg0)
+	ret0, _ := ret[0].(*entities.PetWeightModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLatest indicates an expected call of FindLatest.
+func (mr *MockIPetWeightRepositoryMockRecorder) FindLatest(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatest", reflect.TypeOf((*MockIPetWeightRepository)(nil).FindLatest), arg0)
+}
+
+// UpsertVitalsTx mocks base method.
+func (m *MockIPetWeightRepository) UpsertVitalsTx(arg0 *repositories.Tx, arg1 entities.PetWeightModel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpsertVitalsTx", arg0, arg1)
+}
+
+// UpsertVitalsTx indicates an expected call of UpsertVitalsTx.
+func (mr *MockIPetWeightRepositoryMockRecorder) UpsertVitalsTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertVitalsTx", reflect.TypeOf((*MockIPetWeightRepository)(nil).UpsertVitalsTx), arg0, arg1)
+}
+
+// MockIAttachmentRepository is a mock of IAttachmentRepository interface.
+type MockIAttachmentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAttachmentRepositoryMockRecorder
+}
+
+// MockIAttachmentRepositoryMockRecorder is the mock recorder for MockIAttachmentRepository.
+type MockIAttachmentRepositoryMockRecorder struct {
+	mock *MockIAttachmentRepository
+}
+
+// NewMockIAttachmentRepository creates a new mock instance.
+func NewMockIAttachmentRepository(ctrl *gomock.Controller) *MockIAttachmentRepository {
+	mock := &MockIAttachmentRepository{ctrl: ctrl}
+	mock.recorder = &MockIAttachmentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAttachmentRepository) EXPECT() *MockIAttachmentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockIAttachmentRepository) Delete(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIAttachmentRepositoryMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIAttachmentRepository)(nil).Delete), arg0)
+}
+
+// FindByID mocks base method.
+func (m *MockIAttachmentRepository) FindByID(arg0 string) (*entities.AttachmentModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret0, _ := ret[0].(*entities.AttachmentModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockIAttachmentRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockIAttachmentRepository)(nil).FindByID), arg0)
+}
+
+// FindByPetID mocks base method.
+func (m *MockIAttachmentRepository) FindByPetID(arg0 string, arg1 *db.AttachmentKind) ([]*entities.AttachmentModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPetID", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.AttachmentModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPetID indicates an expected call of FindByPetID.
+func (mr *MockIAttachmentRepositoryMockRecorder) FindByPetID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPetID", reflect.TypeOf((*MockIAttachmentRepository)(nil).FindByPetID), arg0, arg1)
+}
+
+// FindByServiceID mocks base method.
+func (m *MockIAttachmentRepository) FindByServiceID(arg0 string) ([]*entities.AttachmentModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByServiceID", arg0)
+	ret0, _ := ret[0].([]*entities.AttachmentModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByServiceID indicates an expected call of FindByServiceID.
+func (mr *MockIAttachmentRepositoryMockRecorder) FindByServiceID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByServiceID", reflect.TypeOf((*MockIAttachmentRepository)(nil).FindByServiceID), arg0)
+}
+
+// Insert mocks base method.
+func (m *MockIAttachmentRepository) Insert(arg0 entities.AttachmentModel) (*entities.AttachmentModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", arg0)
+	ret0, _ := ret[0].(*entities.AttachmentModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockIAttachmentRepositoryMockRecorder) Insert(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockIAttachmentRepository)(nil).Insert), arg0)
+}
+
+// MockISessionRepository is a mock of ISessionRepository interface.
+type MockISessionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockISessionRepositoryMockRecorder
+}
+
+// MockISessionRepositoryMockRecorder is the mock recorder for MockISessionRepository.
+type MockISessionRepositoryMockRecorder struct {
+	mock *MockISessionRepository
+}
+
+// NewMockISessionRepository creates a new mock instance.
+func NewMockISessionRepository(ctrl *gomock.Controller) *MockISessionRepository {
+	mock := &MockISessionRepository{ctrl: ctrl}
+	mock.recorder = &MockISessionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISessionRepository) EXPECT() *MockISessionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpiredTx mocks base method.
+func (m *MockISessionRepository) DeleteExpiredTx(arg0 *repositories.Tx, arg1 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteExpiredTx", arg0, arg1)
+}
+
+// DeleteExpiredTx indicates an expected call of DeleteExpiredTx.
+func (mr *MockISessionRepositoryMockRecorder) DeleteExpiredTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredTx", reflect.TypeOf((*MockISessionRepository)(nil).DeleteExpiredTx), arg0, arg1)
+}
+
+// FindByID mocks base method.
+func (m *MockISessionRepository) FindByID(arg0 string) (*entities.SessionModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret0, _ := ret[0].(*entities.SessionModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockISessionRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockISessionRepository)(nil).FindByID), arg0)
+}
+
+// FindByTokenHash mocks base method.
+func (m *MockISessionRepository) FindByTokenHash(arg0 string) (*entities.SessionModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByTokenHash", arg0)
+	ret0, _ := ret[0].(*entities.SessionModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByTokenHash indicates an expected call of FindByTokenHash.
+func (mr *MockISessionRepositoryMockRecorder) FindByTokenHash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByTokenHash", reflect.TypeOf((*MockISessionRepository)(nil).FindByTokenHash), arg0)
+}
+
+// Insert mocks base method.
+func (m *MockISessionRepository) Insert(arg0 entities.SessionModel) (*entities.SessionModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", arg0)
+	ret0, _ := ret[0].(*entities.SessionModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockISessionRepositoryMockRecorder) Insert(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockISessionRepository)(nil).Insert), arg0)
+}
+
+// Revoke mocks base method.
+func (m *MockISessionRepository) Revoke(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockISessionRepositoryMockRecorder) Revoke(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockISessionRepository)(nil).Revoke), arg0)
+}
+
+// RevokeByUserID mocks base method.
+func (m *MockISessionRepository) RevokeByUserID(arg0 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeByUserID", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeByUserID indicates an expected call of RevokeByUserID.
+func (mr *MockISessionRepositoryMockRecorder) RevokeByUserID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeByUserID", reflect.TypeOf((*MockISessionRepository)(nil).RevokeByUserID), arg0)
+}
+
+// RevokeByUserIDTx mocks base method.
+func (m *MockISessionRepository) RevokeByUserIDTx(arg0 *repositories.Tx, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeByUserIDTx", arg0, arg1)
+}
+
+// RevokeByUserIDTx indicates an expected call of RevokeByUserIDTx.
+func (mr *MockISessionRepositoryMockRecorder) RevokeByUserIDTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeByUserIDTx", reflect.TypeOf((*MockISessionRepository)(nil).RevokeByUserIDTx), arg0, arg1)
+}
+
+// RevokeWithoutMfa mocks base method.
+func (m *MockISessionRepository) RevokeWithoutMfa(arg0 db.Role) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeWithoutMfa", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeWithoutMfa indicates an expected call of RevokeWithoutMfa.
+func (mr *MockISessionRepositoryMockRecorder) RevokeWithoutMfa(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeWithoutMfa", reflect.TypeOf((*MockISessionRepository)(nil).RevokeWithoutMfa), arg0)
+}
+
+// Rotate mocks base method.
+func (m *MockISessionRepository) Rotate(arg0, arg1, arg2 string, arg3 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *MockISessionRepositoryMockRecorder) Rotate(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*MockISessionRepository)(nil).Rotate), arg0, arg1, arg2, arg3)
+}
+
+// MockIPasswordResetRepository is a mock of IPasswordResetRepository interface.
+type MockIPasswordResetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPasswordResetRepositoryMockRecorder
+}
+
+// MockIPasswordResetRepositoryMockRecorder is the mock recorder for MockIPasswordResetRepository.
+type MockIPasswordResetRepositoryMockRecorder struct {
+	mock *MockIPasswordResetRepository
+}
+
+// NewMockIPasswordResetRepository creates a new mock instance.
+func NewMockIPasswordResetRepository(ctrl *gomock.Controller) *MockIPasswordResetRepository {
+	mock := &MockIPasswordResetRepository{ctrl: ctrl}
+	mock.recorder = &MockIPasswordResetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPasswordResetRepository) EXPECT() *MockIPasswordResetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountSince mocks base method.
+func (m *MockIPasswordResetRepository) CountSince(arg0, arg1 string, arg2 time.Time) (*entities.ResetRequestCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSince", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.ResetRequestCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSince indicates an expected call of CountSince.
+func (mr *MockIPasswordResetRepositoryMockRecorder) CountSince(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSince", reflect.TypeOf((*MockIPasswordResetRepository)(nil).CountSince), arg0, arg1, arg2)
+}
+
+// DeleteBeforeTx mocks base method.
+func (m *MockIPasswordResetRepository) DeleteBeforeTx(arg0 *repositories.Tx, arg1 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteBeforeTx", arg0, arg1)
+}
+
+// DeleteBeforeTx indicates an expected call of DeleteBeforeTx.
+func (mr *MockIPasswordResetRepositoryMockRecorder) DeleteBeforeTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBeforeTx", reflect.TypeOf((*MockIPasswordResetRepository)(nil).DeleteBeforeTx), arg0, arg1)
+}
+
+// FindByID mocks base method.
+func (m *MockIPasswordResetRepository) FindByID(arg0 string) (*entities.PasswordResetModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", arg0)
+	ret0, _ := ret[0].(*entities.PasswordResetModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockIPasswordResetRepositoryMockRecorder) FindByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockIPasswordResetRepository)(nil).FindByID), arg0)
+}
+
+// Insert mocks base method.
+func (m *MockIPasswordResetRepository) Insert(arg0 entities.PasswordResetModel) (*entities.PasswordResetModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", arg0)
+	ret0, _ := ret[0].(*entities.PasswordResetModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockIPasswordResetRepositoryMockRecorder) Insert(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockIPasswordResetRepository)(nil).Insert), arg0)
+}
+
+// MarkUsedTx mocks base method.
+func (m *MockIPasswordResetRepository) MarkUsedTx(arg0 *repositories.Tx, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MarkUsedTx", arg0, arg1)
+}
+
+// MarkUsedTx indicates an expected call of MarkUsedTx.
+func (mr *MockIPasswordResetRepositoryMockRecorder) MarkUsedTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsedTx", reflect.TypeOf((*MockIPasswordResetRepository)(nil).MarkUsedTx), arg0, arg1)
+}
+
+// MockIMfaRepository is a mock of IMfaRepository interface.
+type MockIMfaRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIMfaRepositoryMockRecorder
+}
+
+// MockIMfaRepositoryMockRecorder is the mock recorder for MockIMfaRepository.
+type MockIMfaRepositoryMockRecorder struct {
+	mock *MockIMfaRepository
+}
+
+// NewMockIMfaRepository creates a new mock instance.
+func NewMockIMfaRepository(ctrl *gomock.Controller) *MockIMfaRepository {
+	mock := &MockIMfaRepository{ctrl: ctrl}
+	mock.recorder = &MockIMfaRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIMfaRepository) EXPECT() *MockIMfaRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimStep mocks base method.
+func (m *MockIMfaRepository) ClaimStep(arg0 string, arg1 int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimStep", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimStep indicates an expected call of ClaimStep.
+func (mr *MockIMfaRepositoryMockRecorder) ClaimStep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimStep", reflect.TypeOf((*MockIMfaRepository)(nil).ClaimStep), arg0, arg1)
+}
+
+// DeleteByUserID mocks base method.
+func (m *MockIMfaRepository) DeleteByUserID(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID.
+func (mr *MockIMfaRepositoryMockRecorder) DeleteByUserID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockIMfaRepository)(nil).DeleteByUserID), arg0)
+}
+
+// EnableTx mocks base method.
+func (m *MockIMfaRepository) EnableTx(arg0 *repositories.Tx, arg1 string, arg2 int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "EnableTx", arg0, arg1, arg2)
+}
+
+// EnableTx indicates an expected call of EnableTx.
+func (mr *MockIMfaRepositoryMockRecorder) EnableTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTx", reflect.TypeOf((*MockIMfaRepository)(nil).EnableTx), arg0, arg1, arg2)
+}
+
+// FindByUserID mocks base method.
+func (m *MockIMfaRepository) FindByUserID(arg0 string) (*entities.MfaModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserID", arg0)
+	ret0, _ := ret[0].(*entities.MfaModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserID indicates an expected call of FindByUserID.
+func (mr *MockIMfaRepositoryMockRecorder) FindByUserID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserID", reflect.TypeOf((*MockIMfaRepository)(nil).FindByUserID), arg0)
+}
+
+// FindRequirements mocks base method.
+func (m *MockIMfaRepository) FindRequirements() ([]*entities.MfaRequirementModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRequirements")
+	ret0, _ := ret[0].([]*entities.MfaRequirementModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRequirements indicates an expected call of FindRequirements.
+func (mr *MockIMfaRepositoryMockRecorder) FindRequirements() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRequirements", reflect.TypeOf((*MockIMfaRepository)(nil).FindRequirements))
+}
+
+// RecordFailure mocks base method.
+func (m *MockIMfaRepository) RecordFailure(arg0 string, arg1 int, arg2 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailure", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordFailure indicates an expected call of RecordFailure.
+func (mr *MockIMfaRepositoryMockRecorder) RecordFailure(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockIMfaRepository)(nil).RecordFailure), arg0, arg1, arg2)
+}
+
+// ReplaceRecoveryCodesTx mocks base method.
+func (m *MockIMfaRepository) ReplaceRecoveryCodesTx(arg0 *repositories.Tx, arg1 string, arg2 []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReplaceRecoveryCodesTx", arg0, arg1, arg2)
+}
+
+// ReplaceRecoveryCodesTx indicates an expected call of ReplaceRecoveryCodesTx.
+func (mr *MockIMfaRepositoryMockRecorder) ReplaceRecoveryCodesTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodesTx", reflect.TypeOf((*MockIMfaRepository)(nil).ReplaceRecoveryCodesTx), arg0, arg1, arg2)
+}
+
+// Require mocks base method.
+func (m *MockIMfaRepository) Require(arg0 db.Role, arg1 string) (*entities.MfaRequirementModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Require", arg0, arg1)
+	ret0, _ := ret[0].(*entities.MfaRequirementModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Require indicates an expected call of Require.
+func (mr *MockIMfaRepositoryMockRecorder) Require(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Require", reflect.TypeOf((*MockIMfaRepository)(nil).Require), arg0, arg1)
+}
+
+// SaveSecret mocks base method.
+func (m *MockIMfaRepository) SaveSecret(arg0, arg1 string) (*entities.MfaModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecret", arg0, arg1)
+	ret0, _ := ret[0].(*entities.MfaModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveSecret indicates an expected call of SaveSecret.
+func (mr *MockIMfaRepositoryMockRecorder) SaveSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecret", reflect.TypeOf((*MockIMfaRepository)(nil).SaveSecret), arg0, arg1)
+}
+
+// Unrequire mocks base method.
+func (m *MockIMfaRepository) Unrequire(arg0 db.Role) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unrequire", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unrequire indicates an expected call of Unrequire.
+func (mr *MockIMfaRepositoryMockRecorder) Unrequire(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unrequire", reflect.TypeOf((*MockIMfaRepository)(nil).Unrequire), arg0)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockIMfaRepository) UseRecoveryCode(arg0, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockIMfaRepositoryMockRecorder) UseRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockIMfaRepository)(nil).UseRecoveryCode), arg0, arg1)
+}
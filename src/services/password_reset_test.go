@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/middlewares"
+	"lama-backend/src/services/mocks"
+)
+
+func TestAuthService_RequestPasswordReset(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", "test")
+	req := entities.SendEmailModel{Email: " Owner@Lama.com "}
+
+	newService := func(ctrl *gomock.Controller) (*authService, *mocks.MockIUsersRepository, *mocks.MockIPasswordResetRepository) {
+		mockUsers := mocks.NewMockIUsersRepository(ctrl)
+		mockReset := mocks.NewMockIPasswordResetRepository(ctrl)
+		return &authService{UsersRepository: mockUsers, PasswordResetRepo: mockReset}, mockUsers, mockReset
+	}
+
+	t.Run("mixed-case email with spaces reaches the account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockUsers, mockReset := newService(ctrl)
+
+		mockReset.EXPECT().CountSince("owner@lama.com", "10.0.0.1", gomock.Any()).Return(&entities.ResetRequestCount{ByEmail: 2, ByIP: 2}, nil)
+		mockUsers.EXPECT().FindByEmail("owner@lama.com").Return(&entities.LoginUserResponseModel{UserID: "user-1", Password: "hash-1", Role: db.RoleOwner}, nil)
+		mockReset.EXPECT().Insert(gomock.Any()).DoAndReturn(func(data entities.PasswordResetModel) (*entities.PasswordResetModel, error) {
+			if data.Email != "owner@lama.com" || *data.UserID != "user-1" || data.PasswordFingerprint != passwordFingerprint("hash-1") || data.IP != "10.0.0.1" {
+				t.Fatalf("unexpected request %+v", data)
+			}
+			return &entities.PasswordResetModel{ID: "reset-1"}, nil
+		})
+
+		token, err := sv.RequestPasswordReset(req, "10.0.0.1")
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		decoded, err := middlewares.DecodeResetPasswordJWTToken(*token.Token)
+		if err != nil || decoded.TokenID != "reset-1" || decoded.UserID != "user-1" {
+			t.Fatalf("unexpected token %+v, err %v", decoded, err)
+		}
+	})
+
+	t.Run("unknown email is recorded but gets no token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockUsers, mockReset := newService(ctrl)
+
+		mockReset.EXPECT().CountSince(gomock.Any(), gomock.Any(), gomock.Any()).Return(&entities.ResetRequestCount{}, nil)
+		mockUsers.EXPECT().FindByEmail(gomock.Any()).Return(nil, db.ErrNotFound)
+		mockReset.EXPECT().Insert(gomock.Any()).DoAndReturn(func(data entities.PasswordResetModel) (*entities.PasswordResetModel, error) {
+			if data.UserID != nil {
+				t.Fatalf("expected no user, got %+v", data)
+			}
+			return &entities.PasswordResetModel{ID: "reset-2"}, nil
+		})
+
+		token, err := sv.RequestPasswordReset(req, "10.0.0.1")
+		if err != nil || token != nil {
+			t.Fatalf("expected no token and no error, got %+v, %v", token, err)
+		}
+	})
+
+	t.Run("throttled per email and per ip", func(t *testing.T) {
+		for name, count := range map[string]entities.ResetRequestCount{
+			"email": {ByEmail: maxResetRequestsPerMail},
+			"ip":    {ByIP: maxResetRequestsPerIP},
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+				sv, _, mockReset := newService(ctrl)
+
+				mockReset.EXPECT().CountSince(gomock.Any(), gomock.Any(), gomock.Any()).Return(&count, nil)
+
+				if _, err := sv.RequestPasswordReset(req, "10.0.0.1"); !errors.Is(err, ErrTooManyResetRequests) {
+					t.Fatalf("expected ErrTooManyResetRequests, got %v", err)
+				}
+			})
+		}
+	})
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", "test")
+	userID := "user-1"
+	resetToken, err := middlewares.GenerateResetPasswordJWTToken(userID, "owner", "reset_password", "reset-1")
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	reset := func() *entities.PasswordResetModel {
+		return &entities.PasswordResetModel{
+			ID: "reset-1", UserID: &userID, PasswordFingerprint: passwordFingerprint("hash-1"),
+			ExpiresAt: time.Now().Add(10 * time.Minute),
+		}
+	}
+
+	type deps struct {
+		users   *mocks.MockIUsersRepository
+		reset   *mocks.MockIPasswordResetRepository
+		session *mocks.MockISessionRepository
+		uow     *mocks.MockIUnitOfWork
+	}
+	newService := func(ctrl *gomock.Controller) (*authService, deps) {
+		d := deps{
+			users:   mocks.NewMockIUsersRepository(ctrl),
+			reset:   mocks.NewMockIPasswordResetRepository(ctrl),
+			session: mocks.NewMockISessionRepository(ctrl),
+			uow:     mocks.NewMockIUnitOfWork(ctrl),
+		}
+		return &authService{UsersRepository: d.users, PasswordResetRepo: d.reset, SessionRepository: d.session, UnitOfWork: d.uow}, d
+	}
+	// token, รหัสใหม่ และ logout ต้องอยู่ใน tx เดียวกัน
+	expectReset := func(d deps, commitErr error) {
+		tx := &repositories.Tx{}
+		d.uow.EXPECT().Begin().Return(tx)
+		d.reset.EXPECT().MarkUsedTx(tx, "reset-1")
+		d.users.EXPECT().UpdatePasswordTx(tx, userID, "hash-new")
+		d.session.EXPECT().RevokeByUserIDTx(tx, userID)
+		d.uow.EXPECT().Commit(tx).Return(commitErr)
+	}
+
+	t.Run("first use changes the password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, d := newService(ctrl)
+
+		d.reset.EXPECT().FindByID("reset-1").Return(reset(), nil)
+		d.users.EXPECT().FindByID(userID).Return(&entities.UserDataModel{UserID: userID, Password: "hash-1"}, nil)
+		expectReset(d, nil)
+
+		if err := sv.ResetPassword(*resetToken.Token, "hash-new"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("failed update keeps the link", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, d := newService(ctrl)
+
+		d.reset.EXPECT().FindByID("reset-1").Return(reset(), nil)
+		d.users.EXPECT().FindByID(userID).Return(&entities.UserDataModel{UserID: userID, Password: "hash-1"}, nil)
+		expectReset(d, errors.New("connection reset"))
+
+		// ไม่ใช่ ErrInvalidResetToken ผู้ใช้ลองใหม่ด้วย link เดิมได้
+		if err := sv.ResetPassword(*resetToken.Token, "hash-new"); err == nil || errors.Is(err, ErrInvalidResetToken) {
+			t.Fatalf("expected a retryable error, got %v", err)
+		}
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		usedAt := time.Now()
+		used := reset()
+		used.UsedAt = &usedAt
+
+		cases := []struct {
+			name      string
+			reset     *entities.PasswordResetModel
+			password  string
+			commitErr error
+		}{
+			{name: "already used", reset: used},
+			{name: "password changed since", reset: reset(), password: "hash-2"},
+			{name: "used by a concurrent request", reset: reset(), password: "hash-1", commitErr: repositories.ErrResetTokenUsed},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+				sv, d := newService(ctrl)
+
+				d.reset.EXPECT().FindByID("reset-1").Return(tc.reset, nil)
+				if tc.password != "" {
+					d.users.EXPECT().FindByID(userID).Return(&entities.UserDataModel{UserID: userID, Password: tc.password}, nil)
+				}
+				if tc.commitErr != nil {
+					expectReset(d, tc.commitErr)
+				}
+
+				if err := sv.ResetPassword(*resetToken.Token, "hash-new"); !errors.Is(err, ErrInvalidResetToken) {
+					t.Fatalf("expected ErrInvalidResetToken, got %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("access token is not a reset token", func(t *testing.T) {
+		access, err := middlewares.GenerateJWTToken(userID, "owner", "access", "sess-1")
+		if err != nil {
+			t.Fatalf("token: %v", err)
+		}
+		sv := &authService{}
+		if err := sv.ResetPassword(*access.Token, "hash-new"); !errors.Is(err, ErrInvalidResetToken) {
+			t.Fatalf("expected ErrInvalidResetToken, got %v", err)
+		}
+	})
+}
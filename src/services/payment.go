@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"lama-backend/domain/entities"
 	"lama-backend/domain/prisma/db"
@@ -10,38 +11,120 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stripe/stripe-go/v76"
 	"github.com/stripe/stripe-go/v76/checkout/session"
 	"github.com/stripe/stripe-go/v76/paymentintent"
+	"github.com/stripe/stripe-go/v76/refund"
+	"github.com/stripe/stripe-go/v76/webhook"
 )
 
 type PaymentService struct {
-	repo repositories.IPaymentRepository
+	repo            repositories.IPaymentRepository
+	stripeEventRepo repositories.IStripeEventRepository
+	pricingRepo     repositories.IPricingRepository
+	petRepo         repositories.IPetRepository
+	unitOfWork      repositories.IUnitOfWork
+	webhookSecret   string
 }
 
 type IPaymentService interface {
-	InsertPayment(userID string, reserve_date_end, reserve_date_start time.Time) (*entities.PaymentModel, error)
+	InsertPayment(data entities.CreateServiceRequest) (*entities.PaymentModel, error)
+	QuotePrice(data entities.CreateServiceRequest) (*entities.ServiceQuoteModel, error)
 	FindAllPayments(month int, year int, page int, limit int) ([]*entities.PaymentModel, int, error)
 	FindPaymentsByOwnerID(ownerID string, month int, year int, page int, limit int) ([]*entities.PaymentModel, int, error)
 	UpdateByID(paymentID string, data entities.UpdatePaymentRequest) (*entities.PaymentModel, error)
-	StripeCreatePrice(service *entities.CreateServiceRequest, price int) (string, error)
+	StripeCreatePrice(service *entities.CreateServiceRequest, payment *entities.PaymentModel, expiresAt time.Time) (string, error)
 	GetMethodAndPaydate(payIntent string) (string, string, error)
+	ConstructWebhookEvent(payload []byte, signature string) (stripe.Event, error)
+	ClaimWebhookEvent(event stripe.Event) (*entities.StripeEventModel, bool, error)
+	CompleteWebhookEvent(eventID string, response []byte) error
+	ReleaseWebhookEvent(eventID string) error
+	FindByStripeReference(paymentID, paymentIntentID string) (*entities.PaymentModel, error)
+	ApplyStripeStatus(paymentID string, data entities.StripePaymentUpdate) (*entities.PaymentModel, bool, error)
+	RefundUnfulfilledPayment(paymentID string, settlement entities.StripePaymentUpdate) (*entities.PaymentModel, error)
+	RefundPayment(paymentID string, amount int, idempotencyKey string) ([]entities.PaymentRefundModel, error)
+	PriceReschedule(service *entities.ServiceModel, reschedule *entities.ServiceRescheduleModel) error
+	InsertAdjustment(reschedule *entities.ServiceRescheduleModel) (*entities.PaymentModel, error)
+	StripeCreateAdjustmentCheckout(reschedule *entities.ServiceRescheduleModel, payment *entities.PaymentModel, expiresAt time.Time) (string, error)
+	ExpireAbandonedPaymentsTx(tx *repositories.Tx, now time.Time)
 }
 
-func NewPaymentService(repo repositories.IPaymentRepository) IPaymentService {
+// สถานะปลายทาง -> สถานะเดิมที่ยอมให้เปลี่ยนมาได้ (กัน event ที่มาช้าเขียนทับสถานะที่ใหม่กว่า)
+var stripePaymentTransitions = map[db.PaymentStatus][]db.PaymentStatus{
+	db.PaymentStatusPaid:     {db.PaymentStatusUnpaid, db.PaymentStatusFailed},
+	db.PaymentStatusFailed:   {db.PaymentStatusUnpaid, db.PaymentStatusFailed},
+	db.PaymentStatusExpired:  {db.PaymentStatusUnpaid, db.PaymentStatusFailed},
+	db.PaymentStatusRefunded: {db.PaymentStatusPaid, db.PaymentStatusDisputed},
+	db.PaymentStatusDisputed: {db.PaymentStatusPaid},
+}
+
+func NewPaymentService(
+	repo repositories.IPaymentRepository,
+	stripeEventRepo repositories.IStripeEventRepository,
+	pricingRepo repositories.IPricingRepository,
+	petRepo repositories.IPetRepository,
+	unitOfWork repositories.IUnitOfWork,
+) IPaymentService {
 	return &PaymentService{
-		repo: repo,
+		repo:            repo,
+		stripeEventRepo: stripeEventRepo,
+		pricingRepo:     pricingRepo,
+		petRepo:         petRepo,
+		unitOfWork:      unitOfWork,
+		webhookSecret:   os.Getenv("STRIPE_WEBHOOK_SECRET"),
 	}
 }
 
-func CalPrice(reserve_date_end, reserve_date_start time.Time) int {
-	durationHours := reserve_date_end.Sub(reserve_date_start).Hours()
-	return int(durationHours * 100)
+// InsertPayment prices the booking with the current pricing rules and stores
+// the payment with its line items.
+func (s *PaymentService) InsertPayment(data entities.CreateServiceRequest) (*entities.PaymentModel, error) {
+	lineItems, price, err := s.priceBooking(data)
+	if err != nil {
+		return nil, fmt.Errorf("payment service -> InsertPayment: %w", err)
+	}
+
+	paymentID := uuid.NewString()
+	tx := s.unitOfWork.Begin()
+	s.repo.InsertPaymentTx(tx, paymentID, data.OwnerID, price, lineItems)
+	if err := s.unitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("payment service -> InsertPayment: %v", err)
+	}
+
+	return s.repo.FindByID(paymentID)
 }
 
-func (s *PaymentService) InsertPayment(userID string, reserve_date_end, reserve_date_start time.Time) (*entities.PaymentModel, error) {
-	price := CalPrice(reserve_date_end, reserve_date_start)
-	return s.repo.InsertPayment(userID, price)
+// QuotePrice prices the booking the same way InsertPayment does but only reads.
+func (s *PaymentService) QuotePrice(data entities.CreateServiceRequest) (*entities.ServiceQuoteModel, error) {
+	lineItems, price, err := s.priceBooking(data)
+	if err != nil {
+		return nil, fmt.Errorf("payment service -> QuotePrice: %w", err)
+	}
+
+	return &entities.ServiceQuoteModel{Price: price, LineItems: lineItems}, nil
+}
+
+func (s *PaymentService) priceBooking(data entities.CreateServiceRequest) ([]entities.PaymentLineItemModel, int, error) {
+	pet, err := s.petRepo.FindPetByID(data.PetID)
+	if err != nil {
+		return nil, 0, err
+	}
+	// ราคาขึ้นกับน้ำหนัก/ชนิดของสัตว์ ห้ามใช้สัตว์ของคนอื่นมาคิด
+	if pet.OwnerID != data.OwnerID {
+		return nil, 0, fmt.Errorf("pet does not belong to owner: %w", db.ErrNotFound)
+	}
+
+	rules, err := s.pricingRepo.FindRules(data.ServiceType, data.StaffID, data.ReserveDateStart, data.ReserveDateEnd)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	lineItems, price := calculatePrice(rules, data.ServiceType, pet, data.ReserveDateStart, data.ReserveDateEnd)
+	if price <= 0 {
+		return nil, 0, errors.New("booking has no billable hours")
+	}
+
+	return lineItems, price, nil
 }
 
 func (s *PaymentService) FindAllPayments(month int, year int, page int, limit int) ([]*entities.PaymentModel, int, error) {
@@ -101,49 +184,55 @@ func (s *PaymentService) UpdateByID(paymentID string, data entities.UpdatePaymen
 }
 
 // CreateCheckoutSession creates a Stripe Checkout Session
-func (s *PaymentService) StripeCreatePrice(service *entities.CreateServiceRequest, price int) (string, error) {
-	// prepare data - price, currenct, method (price already in pass)
-	currency := "thb"
-	paymentMethod := []string{"card", "promptpay"}
-	stripe.Key = os.Getenv("STRIPE_KEY")
-	url := os.Getenv("STRIPE_REDIRECT")
-
-	var unitPrice int32
-	name := fmt.Sprintf("pack %v", price)
-	unitPrice = int32(price * 100)
+func (s *PaymentService) StripeCreatePrice(service *entities.CreateServiceRequest, payment *entities.PaymentModel, expiresAt time.Time) (string, error) {
 	metaData := map[string]string{
 		"owner_id":           service.OwnerID,
 		"pet_id":             service.PetID,
 		"payment_id":         service.PaymentID,
 		"staff_id":           service.StaffID,
 		"service_type":       service.ServiceType,
-		"status":             string(service.Status),
 		"reserve_date_start": service.ReserveDateStart.Format(time.RFC3339),
 		"reserve_date_end":   service.ReserveDateEnd.Format(time.RFC3339),
 	}
 
+	return newCheckoutSession(service.OwnerID, payment, metaData, expiresAt)
+}
+
+// StripeCreateAdjustmentCheckout charges the extra amount of a reschedule. The
+// webhook applies the reschedule once it is paid.
+func (s *PaymentService) StripeCreateAdjustmentCheckout(reschedule *entities.ServiceRescheduleModel, payment *entities.PaymentModel, expiresAt time.Time) (string, error) {
+	metaData := map[string]string{
+		"owner_id":      reschedule.OwnerID,
+		"payment_id":    payment.PayID,
+		"service_id":    reschedule.ServiceID,
+		"reschedule_id": reschedule.ID,
+	}
+
+	return newCheckoutSession(reschedule.OwnerID, payment, metaData, expiresAt)
+}
+
+func newCheckoutSession(ownerID string, payment *entities.PaymentModel, metaData map[string]string, expiresAt time.Time) (string, error) {
+	// prepare data - price, currenct, method (price already in pass)
+	currency := "thb"
+	paymentMethod := []string{"card", "promptpay"}
+	stripe.Key = os.Getenv("STRIPE_KEY")
+	url := os.Getenv("STRIPE_REDIRECT")
+
 	params := &stripe.CheckoutSessionParams{
 		PaymentMethodTypes: stripe.StringSlice(paymentMethod),
 
-		LineItems: []*stripe.CheckoutSessionLineItemParams{
-			{
-				PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
-					Currency: stripe.String(currency),
-					ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
-						Name: stripe.String(name),
-					},
-					UnitAmount: stripe.Int64(int64(unitPrice)),
-				},
-				Quantity: stripe.Int64(1),
-			},
-		},
+		LineItems:           stripeLineItems(payment, currency),
 		Mode:                stripe.String(string(stripe.CheckoutSessionModePayment)),
-		ClientReferenceID:   stripe.String(service.OwnerID),
+		ClientReferenceID:   stripe.String(ownerID),
 		SuccessURL:          stripe.String(url),
 		CancelURL:           stripe.String(os.Getenv("FRONT_REDIRECT_URL_STRIPE")),
 		AllowPromotionCodes: stripe.Bool(true),
-		ExpiresAt:           stripe.Int64(time.Now().Add(60 * time.Minute).Unix()),
+		ExpiresAt:           stripe.Int64(expiresAt.Unix()),
 		Metadata:            metaData, // blank - don't have package and salescode
+		// payment_intent / charge events don't carry the session metadata
+		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
+			Metadata: map[string]string{"payment_id": payment.PayID},
+		},
 	}
 	a, err := session.New(params)
 	if err != nil {
@@ -152,6 +241,31 @@ func (s *PaymentService) StripeCreatePrice(service *entities.CreateServiceReques
 	return a.URL, nil
 }
 
+// stripeLineItems turns the stored breakdown into checkout line items (amounts in satang).
+func stripeLineItems(payment *entities.PaymentModel, currency string) []*stripe.CheckoutSessionLineItemParams {
+	lineItems := payment.LineItems
+	if len(lineItems) == 0 {
+		// payment เก่าที่ไม่มี breakdown
+		lineItems = []entities.PaymentLineItemModel{newLineItem(lineItemBase, fmt.Sprintf("pack %v", payment.Price), 1, payment.Price)}
+	}
+
+	params := make([]*stripe.CheckoutSessionLineItemParams, 0, len(lineItems))
+	for _, item := range lineItems {
+		params = append(params, &stripe.CheckoutSessionLineItemParams{
+			PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
+				Currency: stripe.String(currency),
+				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+					Name: stripe.String(item.Description),
+				},
+				UnitAmount: stripe.Int64(int64(item.UnitAmount) * 100),
+			},
+			Quantity: stripe.Int64(int64(item.Quantity)),
+		})
+	}
+
+	return params
+}
+
 func (s *PaymentService) GetMethodAndPaydate(payIntent string) (string, string, error) {
 	pi, err := paymentintent.Get(payIntent, nil)
 	if err != nil {
@@ -162,3 +276,236 @@ func (s *PaymentService) GetMethodAndPaydate(payIntent string) (string, string,
 
 	return pi.PaymentMethodTypes[0], payDate, nil
 }
+
+// ConstructWebhookEvent checks the Stripe-Signature header against the webhook
+// secret before trusting anything in the payload.
+func (s *PaymentService) ConstructWebhookEvent(payload []byte, signature string) (stripe.Event, error) {
+	if s.webhookSecret == "" {
+		return stripe.Event{}, errors.New("payment service -> ConstructWebhookEvent: webhook secret is not configured")
+	}
+	if signature == "" {
+		return stripe.Event{}, errors.New("payment service -> ConstructWebhookEvent: missing Stripe-Signature header")
+	}
+
+	event, err := webhook.ConstructEventWithOptions(payload, signature, s.webhookSecret, webhook.ConstructEventOptions{
+		IgnoreAPIVersionMismatch: true,
+	})
+	if err != nil {
+		return stripe.Event{}, fmt.Errorf("payment service -> ConstructWebhookEvent: %v", err)
+	}
+	if event.ID == "" {
+		return stripe.Event{}, errors.New("payment service -> ConstructWebhookEvent: event has no id")
+	}
+
+	return event, nil
+}
+
+func (s *PaymentService) ClaimWebhookEvent(event stripe.Event) (*entities.StripeEventModel, bool, error) {
+	return s.stripeEventRepo.Claim(event.ID, string(event.Type))
+}
+
+func (s *PaymentService) CompleteWebhookEvent(eventID string, response []byte) error {
+	_, err := s.stripeEventRepo.MarkProcessed(eventID, string(response))
+	return err
+}
+
+func (s *PaymentService) ReleaseWebhookEvent(eventID string) error {
+	return s.stripeEventRepo.Release(eventID)
+}
+
+// FindByStripeReference looks a payment up by our own id first (from event
+// metadata) and falls back to the Stripe payment_intent recorded at payment time.
+func (s *PaymentService) FindByStripeReference(paymentID, paymentIntentID string) (*entities.PaymentModel, error) {
+	if paymentID != "" {
+		return s.repo.FindByID(paymentID)
+	}
+	if paymentIntentID != "" {
+		return s.repo.FindByStripePaymentIntent(paymentIntentID)
+	}
+	return nil, fmt.Errorf("payment service -> FindByStripeReference: %w", db.ErrNotFound)
+}
+
+// ApplyStripeStatus moves a payment to the status reported by Stripe. It
+// returns applied=false without writing when the current status doesn't allow it.
+func (s *PaymentService) ApplyStripeStatus(paymentID string, data entities.StripePaymentUpdate) (*entities.PaymentModel, bool, error) {
+	current, err := s.repo.FindByID(paymentID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	allowed := false
+	for _, from := range stripePaymentTransitions[data.Status] {
+		if current.Status == from {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return current, false, nil
+	}
+
+	updated, err := s.repo.UpdateByID(paymentID, entities.PaymentModel{
+		Status:              data.Status,
+		Type:                data.Type,
+		PayDate:             data.PayDate,
+		StripePaymentIntent: data.PaymentIntent,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return updated, true, nil
+}
+
+// RefundUnfulfilledPayment gives the money back for a checkout that was paid but
+// couldn't be turned into a booking (e.g. the staff got booked in the meantime).
+func (s *PaymentService) RefundUnfulfilledPayment(paymentID string, settlement entities.StripePaymentUpdate) (*entities.PaymentModel, error) {
+	if settlement.PaymentIntent == nil || *settlement.PaymentIntent == "" {
+		return nil, errors.New("payment service -> RefundUnfulfilledPayment: missing payment_intent")
+	}
+
+	stripe.Key = os.Getenv("STRIPE_KEY")
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(*settlement.PaymentIntent),
+		Metadata:      map[string]string{"payment_id": paymentID, "reason": "staff_unavailable"},
+	}
+	// stripe retry webhook ได้ ต้องไม่ refund ซ้ำ
+	params.SetIdempotencyKey("refund-unfulfilled-" + paymentID)
+	if _, err := refund.New(params); err != nil {
+		return nil, fmt.Errorf("payment service -> RefundUnfulfilledPayment: %v", err)
+	}
+
+	return s.repo.UpdateByID(paymentID, entities.PaymentModel{
+		Status:              db.PaymentStatusRefunded,
+		Type:                settlement.Type,
+		PayDate:             settlement.PayDate,
+		StripePaymentIntent: settlement.PaymentIntent,
+	})
+}
+
+// RefundPayment refunds amount (baht) of a booking on Stripe. The money may sit on the
+// original payment and on its paid adjustments, so the newest charge is refunded
+// first. The caller writes refunded_amount together with whatever caused the refund.
+// If Stripe fails part way, the refunds already made come back with the error.
+func (s *PaymentService) RefundPayment(paymentID string, amount int, idempotencyKey string) ([]entities.PaymentRefundModel, error) {
+	payment, err := s.repo.FindByID(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("payment service -> RefundPayment: %w", err)
+	}
+
+	charges := paidCharges(payment)
+	if balance := paidBalance(payment); amount > balance {
+		return nil, fmt.Errorf("payment service -> RefundPayment: refund %d is more than the paid balance %d", amount, balance)
+	}
+
+	stripe.Key = os.Getenv("STRIPE_KEY")
+	refunds := []entities.PaymentRefundModel{}
+	for i := len(charges) - 1; i >= 0 && amount > 0; i-- {
+		charge := charges[i]
+		take := min(amount, charge.Price-charge.RefundedAmount)
+		if take <= 0 {
+			continue
+		}
+		if charge.StripePaymentIntent == nil || *charge.StripePaymentIntent == "" {
+			return nil, fmt.Errorf("payment service -> RefundPayment: payment %s has no payment_intent", charge.PayID)
+		}
+
+		params := &stripe.RefundParams{
+			PaymentIntent: charge.StripePaymentIntent,
+			Amount:        stripe.Int64(int64(take) * 100),
+			Metadata:      map[string]string{"payment_id": charge.PayID},
+		}
+		params.SetIdempotencyKey(idempotencyKey + "-" + charge.PayID)
+		created, err := refund.New(params)
+		if err != nil {
+			return refunds, fmt.Errorf("payment service -> RefundPayment: %w", err)
+		}
+
+		refunds = append(refunds, entities.PaymentRefundModel{
+			PaymentID:      charge.PayID,
+			Amount:         take,
+			StripeRefundID: created.ID,
+			RefundedAmount: charge.RefundedAmount + take,
+			FullyRefunded:  charge.RefundedAmount+take >= charge.Price,
+		})
+		amount -= take
+	}
+
+	return refunds, nil
+}
+
+// PriceReschedule prices the new window with the current rules and compares it
+// with what the owner has paid so far for the booking.
+func (s *PaymentService) PriceReschedule(service *entities.ServiceModel, reschedule *entities.ServiceRescheduleModel) error {
+	payment, err := s.repo.FindByID(service.PaymentID)
+	if err != nil {
+		return fmt.Errorf("payment service -> PriceReschedule: %w", err)
+	}
+
+	lineItems, price, err := s.priceBooking(entities.CreateServiceRequest{
+		OwnerID:          service.OwnerID,
+		PetID:            service.PetID,
+		StaffID:          service.StaffID,
+		ServiceType:      service.ServiceType,
+		ReserveDateStart: reschedule.NewStart,
+		ReserveDateEnd:   reschedule.NewEnd,
+	})
+	if err != nil {
+		return fmt.Errorf("payment service -> PriceReschedule: %w", err)
+	}
+
+	reschedule.OldPrice = paidBalance(payment)
+	reschedule.NewPrice = price
+	reschedule.PriceDelta = price - reschedule.OldPrice
+	reschedule.LineItems = lineItems
+
+	return nil
+}
+
+// InsertAdjustment creates the unpaid extra charge of a reschedule, linked to the booking's payment.
+func (s *PaymentService) InsertAdjustment(reschedule *entities.ServiceRescheduleModel) (*entities.PaymentModel, error) {
+	if reschedule.PriceDelta <= 0 {
+		return nil, errors.New("payment service -> InsertAdjustment: nothing to charge")
+	}
+
+	description := fmt.Sprintf("Reschedule to %s - %s",
+		reschedule.NewStart.In(utils.BusinessLocation).Format("2006-01-02 15:04"),
+		reschedule.NewEnd.In(utils.BusinessLocation).Format("15:04"),
+	)
+	lineItems := []entities.PaymentLineItemModel{newLineItem(lineItemAdjustment, description, 1, reschedule.PriceDelta)}
+
+	paymentID := uuid.NewString()
+	tx := s.unitOfWork.Begin()
+	s.repo.InsertAdjustmentTx(tx, paymentID, reschedule.PaymentID, reschedule.OwnerID, reschedule.PriceDelta, lineItems)
+	if err := s.unitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("payment service -> InsertAdjustment: %v", err)
+	}
+
+	return s.repo.FindByID(paymentID)
+}
+
+// paidCharges is the booking's payment and its adjustments that actually took money.
+func paidCharges(payment *entities.PaymentModel) []*entities.PaymentModel {
+	charges := []*entities.PaymentModel{}
+	for _, charge := range append([]*entities.PaymentModel{payment}, payment.Adjustments...) {
+		if charge.Status == db.PaymentStatusPaid {
+			charges = append(charges, charge)
+		}
+	}
+	return charges
+}
+
+// paidBalance is what the owner has paid for the booking minus what was refunded.
+func paidBalance(payment *entities.PaymentModel) int {
+	balance := 0
+	for _, charge := range paidCharges(payment) {
+		balance += charge.Price - charge.RefundedAmount
+	}
+	return balance
+}
+
+// ExpireAbandonedPaymentsTx queues expiry of checkouts whose Stripe session (and
+// staff hold) is already over, so late payment events can't race it.
+func (s *PaymentService) ExpireAbandonedPaymentsTx(tx *repositories.Tx, now time.Time) {
+	s.repo.ExpireAbandonedTx(tx, now.Add(-(checkoutHoldDuration + checkoutHoldGrace)))
+}
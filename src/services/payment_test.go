@@ -0,0 +1,238 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/webhook"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/services/mocks"
+)
+
+const testWebhookSecret = "whsec_test_secret"
+
+func signedStripePayload(t *testing.T, secret string, payload string, ts time.Time) ([]byte, string) {
+	t.Helper()
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload:   []byte(payload),
+		Secret:    secret,
+		Timestamp: ts,
+	})
+	return signed.Payload, signed.Header
+}
+
+func TestPaymentService_ConstructWebhookEvent(t *testing.T) {
+	body := `{"id":"evt_1","object":"event","type":"checkout.session.completed","data":{"object":{"payment_status":"paid"}}}`
+
+	validPayload, validHeader := signedStripePayload(t, testWebhookSecret, body, time.Now())
+	otherPayload, otherHeader := signedStripePayload(t, "whsec_other", body, time.Now())
+	oldPayload, oldHeader := signedStripePayload(t, testWebhookSecret, body, time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name    string
+		secret  string
+		payload []byte
+		header  string
+		wantErr bool
+	}{
+		{name: "valid signature", secret: testWebhookSecret, payload: validPayload, header: validHeader},
+		{name: "signed with another secret", secret: testWebhookSecret, payload: otherPayload, header: otherHeader, wantErr: true},
+		{name: "tampered payload", secret: testWebhookSecret, payload: []byte(`{"id":"evt_2"}`), header: validHeader, wantErr: true},
+		{name: "timestamp too old", secret: testWebhookSecret, payload: oldPayload, header: oldHeader, wantErr: true},
+		{name: "missing header", secret: testWebhookSecret, payload: validPayload, header: "", wantErr: true},
+		{name: "secret not configured", secret: "", payload: validPayload, header: validHeader, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sv := &PaymentService{webhookSecret: tt.secret}
+
+			event, err := sv.ConstructWebhookEvent(tt.payload, tt.header)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got event %q", event.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if event.ID != "evt_1" || event.Type != "checkout.session.completed" {
+				t.Fatalf("unexpected event: %+v", event)
+			}
+		})
+	}
+}
+
+func TestPaymentService_ClaimWebhookEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEvents := mocks.NewMockIStripeEventRepository(ctrl)
+	sv := &PaymentService{stripeEventRepo: mockEvents}
+
+	stored := `{"message":"payment updated successfully"}`
+	event := stripe.Event{ID: "evt_1", Type: "checkout.session.completed"}
+
+	tests := []struct {
+		name        string
+		mockRecord  *entities.StripeEventModel
+		mockClaimed bool
+		mockErr     error
+		wantClaimed bool
+		wantErr     bool
+	}{
+		{
+			name:        "first delivery",
+			mockRecord:  &entities.StripeEventModel{EventID: "evt_1", Status: db.StripeEventStatusProcessing},
+			mockClaimed: true, wantClaimed: true,
+		},
+		{
+			name:        "replayed after success",
+			mockRecord:  &entities.StripeEventModel{EventID: "evt_1", Status: db.StripeEventStatusProcessed, Response: &stored},
+			mockClaimed: false, wantClaimed: false,
+		},
+		{
+			name:    "repo error",
+			mockErr: errors.New("db error"), wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockEvents.EXPECT().
+				Claim("evt_1", "checkout.session.completed").
+				Return(tt.mockRecord, tt.mockClaimed, tt.mockErr)
+
+			record, claimed, err := sv.ClaimWebhookEvent(event)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if claimed != tt.wantClaimed {
+				t.Fatalf("claimed: want %v got %v", tt.wantClaimed, claimed)
+			}
+			if !claimed && (record.Response == nil || *record.Response != stored) {
+				t.Fatalf("replay should carry the original response, got %+v", record)
+			}
+		})
+	}
+}
+
+func TestPaymentService_CompleteWebhookEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEvents := mocks.NewMockIStripeEventRepository(ctrl)
+	sv := &PaymentService{stripeEventRepo: mockEvents}
+
+	mockEvents.EXPECT().
+		MarkProcessed("evt_1", `{"ok":true}`).
+		Return(&entities.StripeEventModel{EventID: "evt_1", Status: db.StripeEventStatusProcessed}, nil)
+
+	if err := sv.CompleteWebhookEvent("evt_1", []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestPaymentService_ApplyStripeStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIPaymentRepository(ctrl)
+	sv := &PaymentService{repo: mockRepo}
+
+	tests := []struct {
+		name        string
+		current     db.PaymentStatus
+		target      db.PaymentStatus
+		wantApplied bool
+	}{
+		{name: "unpaid -> paid", current: db.PaymentStatusUnpaid, target: db.PaymentStatusPaid, wantApplied: true},
+		{name: "failed attempt then paid", current: db.PaymentStatusFailed, target: db.PaymentStatusPaid, wantApplied: true},
+		{name: "unpaid -> expired", current: db.PaymentStatusUnpaid, target: db.PaymentStatusExpired, wantApplied: true},
+		{name: "late expiry after payment", current: db.PaymentStatusPaid, target: db.PaymentStatusExpired, wantApplied: false},
+		{name: "late failure after payment", current: db.PaymentStatusPaid, target: db.PaymentStatusFailed, wantApplied: false},
+		{name: "paid -> refunded", current: db.PaymentStatusPaid, target: db.PaymentStatusRefunded, wantApplied: true},
+		{name: "disputed -> refunded", current: db.PaymentStatusDisputed, target: db.PaymentStatusRefunded, wantApplied: true},
+		{name: "refund of unpaid", current: db.PaymentStatusUnpaid, target: db.PaymentStatusRefunded, wantApplied: false},
+		{name: "paid -> disputed", current: db.PaymentStatusPaid, target: db.PaymentStatusDisputed, wantApplied: true},
+		{name: "paid after expiry", current: db.PaymentStatusExpired, target: db.PaymentStatusPaid, wantApplied: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.EXPECT().
+				FindByID("pay-1").
+				Return(&entities.PaymentModel{PayID: "pay-1", Status: tt.current}, nil)
+			if tt.wantApplied {
+				mockRepo.EXPECT().
+					UpdateByID("pay-1", entities.PaymentModel{Status: tt.target}).
+					Return(&entities.PaymentModel{PayID: "pay-1", Status: tt.target}, nil)
+			}
+
+			got, applied, err := sv.ApplyStripeStatus("pay-1", entities.StripePaymentUpdate{Status: tt.target})
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if applied != tt.wantApplied {
+				t.Fatalf("applied: want %v got %v", tt.wantApplied, applied)
+			}
+			want := tt.current
+			if tt.wantApplied {
+				want = tt.target
+			}
+			if got.Status != want {
+				t.Fatalf("status: want %s got %s", want, got.Status)
+			}
+		})
+	}
+}
+
+func TestPaymentService_FindByStripeReference(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIPaymentRepository(ctrl)
+	sv := &PaymentService{repo: mockRepo}
+
+	mockRepo.EXPECT().FindByID("pay-1").Return(&entities.PaymentModel{PayID: "pay-1"}, nil)
+	if got, err := sv.FindByStripeReference("pay-1", "pi_1"); err != nil || got.PayID != "pay-1" {
+		t.Fatalf("metadata lookup: got %+v, %v", got, err)
+	}
+
+	mockRepo.EXPECT().FindByStripePaymentIntent("pi_1").Return(&entities.PaymentModel{PayID: "pay-2"}, nil)
+	if got, err := sv.FindByStripeReference("", "pi_1"); err != nil || got.PayID != "pay-2" {
+		t.Fatalf("payment_intent lookup: got %+v, %v", got, err)
+	}
+
+	if _, err := sv.FindByStripeReference("", ""); !errors.Is(err, db.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestPaymentService_ExpireAbandonedPaymentsTx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIPaymentRepository(ctrl)
+	sv := &PaymentService{repo: mockRepo}
+
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	tx := &repositories.Tx{}
+	// ต้องรอให้ทั้ง checkout session และ hold หมดอายุก่อน
+	mockRepo.EXPECT().ExpireAbandonedTx(tx, now.Add(-(checkoutHoldDuration + checkoutHoldGrace)))
+
+	sv.ExpireAbandonedPaymentsTx(tx, now)
+}
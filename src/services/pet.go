@@ -6,20 +6,40 @@ import (
 )
 
 type PetService struct {
-	PetRepository repositories.IPetRepository
+	PetRepository   repositories.IPetRepository
+	ServiceRepo     repositories.IServiceRepository
+	RecordRepo      repositories.IMedicalRecordRepository
+	MedicineRepo    repositories.IMedicineRepository
+	VaccinationRepo repositories.IVaccinationRepository
+	WeightRepo      repositories.IPetWeightRepository
 }
 
 type IPetService interface {
 	InsertPet(data entities.CreatedPetModel) (*entities.PetDataModel, error)
+	FindPetByID(petID string) (*entities.PetDataModel, error)
 	FindByOwnerID(ownerID string) ([]entities.PetDataModel, error)
 	FindAll() ([]entities.PetDataModel, error)
 	UpdatePet(petID string, data entities.UpdatePetModel) (*entities.PetDataModel, error)
 	DeletePet(petID string) (*entities.PetDataModel, error)
+	FindPetTimeline(petID, userID, role string, filter entities.PetTimelineFilter) ([]entities.PetTimelineEvent, int, error)
+	FindWeightHistory(petID, userID, role string, filter entities.PetWeightFilter) ([]entities.PetWeightPoint, error)
 }
 
-func NewPetService(petRepo repositories.IPetRepository) IPetService {
+func NewPetService(
+	petRepo repositories.IPetRepository,
+	serviceRepo repositories.IServiceRepository,
+	recordRepo repositories.IMedicalRecordRepository,
+	medicineRepo repositories.IMedicineRepository,
+	vaccinationRepo repositories.IVaccinationRepository,
+	weightRepo repositories.IPetWeightRepository,
+) IPetService {
 	return &PetService{
-		PetRepository: petRepo,
+		PetRepository:   petRepo,
+		ServiceRepo:     serviceRepo,
+		RecordRepo:      recordRepo,
+		MedicineRepo:    medicineRepo,
+		VaccinationRepo: vaccinationRepo,
+		WeightRepo:      weightRepo,
 	}
 }
 
@@ -27,6 +47,10 @@ func (s *PetService) InsertPet(data entities.CreatedPetModel) (*entities.PetData
 	return s.PetRepository.InsertPet(data)
 }
 
+func (s *PetService) FindPetByID(petID string) (*entities.PetDataModel, error) {
+	return s.PetRepository.FindPetByID(petID)
+}
+
 func (s *PetService) FindByOwnerID(ownerID string) ([]entities.PetDataModel, error) {
 	return s.PetRepository.FindByOwnerID(ownerID)
 }
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"slices"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+)
+
+// ประวัติของสัตว์ (timeline, การตรวจ, วัคซีน, น้ำหนัก, ไฟล์) อ่านได้แค่ owner ของสัตว์,
+// หมอที่เคยตรวจตัวนี้ และ admin ทุกที่ต้องเช็คผ่าน petReadable/canReadPet
+
+// hasTreated: หมอเคยตรวจสัตว์ตัวนี้ (งานที่เริ่มแล้วหรือจบแล้ว) ถึงจะดูประวัติได้
+func hasTreated(services []*entities.ServiceModel, doctorID string) bool {
+	return slices.ContainsFunc(services, func(service *entities.ServiceModel) bool {
+		return service.ServiceType == "mservice" && service.StaffID == doctorID &&
+			(service.Status == db.ServiceStatusOngoing || service.Status == db.ServiceStatusFinish)
+	})
+}
+
+// petReadable checks the rule against the services of the pet the caller already has.
+func petReadable(pet *entities.PetDataModel, services []*entities.ServiceModel, userID, role string) bool {
+	switch role {
+	case string(db.RoleAdmin):
+		return true
+	case string(db.RoleOwner):
+		return pet.OwnerID == userID
+	case string(db.RoleDoctor):
+		return hasTreated(services, userID)
+	}
+	return false
+}
+
+// canReadPet loads the services of the pet only when the rule needs them (doctors).
+func canReadPet(serviceRepo repositories.IServiceRepository, pet *entities.PetDataModel, userID, role string) (bool, error) {
+	var services []*entities.ServiceModel
+	if role == string(db.RoleDoctor) {
+		var err error
+		if services, err = serviceRepo.FindByPetID(pet.PetID, timelineStatuses); err != nil {
+			return false, err
+		}
+	}
+	return petReadable(pet, services, userID, role), nil
+}
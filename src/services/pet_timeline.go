@@ -0,0 +1,186 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/utils"
+)
+
+var (
+	ErrPetTimelineForbidden = errors.New("not allowed to read the timeline of this pet")
+	ErrInvalidTimelineType  = errors.New("invalid timeline type")
+)
+
+const (
+	TimelineCservice    = "cservice"
+	TimelineMservice    = "mservice"
+	TimelineDiagnosis   = "diagnosis"
+	TimelineMedicine    = "medicine"
+	TimelineReview      = "review"
+	TimelineWeight      = "weight"
+	TimelineVaccination = "vaccination"
+)
+
+var timelineTypes = []string{TimelineCservice, TimelineMservice, TimelineDiagnosis, TimelineMedicine, TimelineReview, TimelineWeight, TimelineVaccination}
+
+// งานที่ยกเลิก/ไม่มาไม่ได้เกิดขึ้นจริงกับสัตว์ ไม่ต้องขึ้น timeline
+var timelineStatuses = []db.ServiceStatus{db.ServiceStatusWait, db.ServiceStatusOngoing, db.ServiceStatusFinish}
+
+// FindPetTimeline merges the services of a pet with what happened during them (diagnoses,
+// medicines, reviews), its weight history and vaccinations in chronological order. Owners read their own pets, doctors
+// the pets they have treated, admins any pet.
+func (s *PetService) FindPetTimeline(petID, userID, role string, filter entities.PetTimelineFilter) ([]entities.PetTimelineEvent, int, error) {
+	for _, t := range filter.Types {
+		if !slices.Contains(timelineTypes, t) {
+			return nil, 0, fmt.Errorf("pet -> FindPetTimeline: %w %q", ErrInvalidTimelineType, t)
+		}
+	}
+
+	pet, err := s.PetRepository.FindPetByID(petID)
+	if err != nil {
+		return nil, 0, err
+	}
+	services, err := s.ServiceRepo.FindByPetID(petID, timelineStatuses)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !petReadable(pet, services, userID, role) {
+		return nil, 0, fmt.Errorf("pet -> FindPetTimeline: %w", ErrPetTimelineForbidden)
+	}
+
+	records, err := s.RecordRepo.FindByPetID(petID)
+	if err != nil {
+		return nil, 0, err
+	}
+	recordByService := make(map[string]*entities.MedicalRecordModel, len(records))
+	serviceIDs := make([]string, 0, len(records))
+	for _, record := range records {
+		recordByService[record.ServiceID] = record
+		serviceIDs = append(serviceIDs, record.ServiceID)
+	}
+	medicines := map[string][]entities.MedicineModel{}
+	if len(serviceIDs) > 0 {
+		if medicines, err = s.MedicineRepo.FindByServiceIDs(serviceIDs); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	vaccinations, err := s.VaccinationRepo.FindByPetID(petID)
+	if err != nil {
+		return nil, 0, err
+	}
+	weights, err := s.WeightRepo.FindByPetID(petID, nil, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	events := buildPetTimeline(services, recordByService, medicines, time.Now())
+	for i, weight := range weights {
+		event := entities.PetTimelineEvent{
+			Type:       TimelineWeight,
+			OccurredAt: weight.MeasuredAt,
+			Weight:     &weights[i].Weight,
+		}
+		if i > 0 {
+			change := weight.Weight.Sub(weights[i-1].Weight)
+			event.WeightChange = &change
+		}
+		if weight.ServiceID != nil {
+			event.ServiceID = *weight.ServiceID
+			event.ServiceType = "mservice"
+		}
+		events = append(events, event)
+	}
+	serviceStart := make(map[string]time.Time, len(services))
+	for _, service := range services {
+		serviceStart[service.Sid] = service.ReserveDateStart
+	}
+	for _, vaccination := range vaccinations {
+		event := entities.PetTimelineEvent{
+			Type:        TimelineVaccination,
+			OccurredAt:  vaccination.GivenDate,
+			Vaccination: vaccination,
+		}
+		if vaccination.ServiceID != nil {
+			event.ServiceID = *vaccination.ServiceID
+			event.ServiceType = "mservice"
+			// given_date เป็นแค่วันที่ ให้ไปต่อท้ายการตรวจครั้งนั้นแทนที่จะขึ้นก่อน
+			if start, ok := serviceStart[*vaccination.ServiceID]; ok {
+				event.OccurredAt = start
+			}
+		}
+		events = append(events, event)
+	}
+	// รีวิวลงเวลาจบงาน และวัคซีนลงวันที่ฉีด อาจแทรกอยู่ระหว่างงานอื่น
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].OccurredAt.Before(events[j].OccurredAt)
+	})
+	if len(filter.Types) > 0 {
+		events = slices.DeleteFunc(events, func(event entities.PetTimelineEvent) bool {
+			return !slices.Contains(filter.Types, event.Type)
+		})
+	}
+
+	total := len(events)
+	offset, limit := utils.CalDefaultOffsetEnd(filter.Page, filter.Limit)
+	if offset >= total {
+		return []entities.PetTimelineEvent{}, total, nil
+	}
+	return events[offset:min(offset+limit, total)], total, nil
+}
+
+// buildPetTimeline emits the events of every service in service order, the caller sorts
+// the result by time.
+func buildPetTimeline(services []*entities.ServiceModel, records map[string]*entities.MedicalRecordModel, medicines map[string][]entities.MedicineModel, now time.Time) []entities.PetTimelineEvent {
+	events := []entities.PetTimelineEvent{}
+
+	for _, service := range services {
+		base := entities.PetTimelineEvent{
+			OccurredAt:  service.ReserveDateStart,
+			ServiceID:   service.Sid,
+			ServiceType: service.ServiceType,
+			Status:      service.Status,
+			StaffID:     service.StaffID,
+			StaffName:   service.Staff.Name,
+		}
+
+		event := base
+		event.Type = service.ServiceType
+		event.Upcoming = service.Status == db.ServiceStatusWait && service.ReserveDateStart.After(now)
+		events = append(events, event)
+
+		if record, ok := records[service.Sid]; ok {
+			event = base
+			event.Type = TimelineDiagnosis
+			event.Diagnosis = &record.Diagnosis
+			event.Symptoms = record.Symptoms
+			events = append(events, event)
+
+			for i := range medicines[service.Sid] {
+				event = base
+				event.Type = TimelineMedicine
+				event.Medicine = &medicines[service.Sid][i]
+				events = append(events, event)
+			}
+		}
+
+		// ยังไม่มีคะแนน (0) และไม่มีคอมเมนต์ = ยังไม่ได้รีวิว
+		if service.ServiceType == "cservice" && ((service.Score != nil && *service.Score > 0) || service.Comment != nil) {
+			event = base
+			event.Type = TimelineReview
+			event.OccurredAt = service.ReserveDateEnd
+			event.Score = service.Score
+			event.Comment = service.Comment
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
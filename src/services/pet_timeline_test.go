@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/services/mocks"
+)
+
+func TestPetService_FindPetTimeline(t *testing.T) {
+	past := clockOn(scheduleMonday, 9, 0)
+	score, comment := 5, "very gentle"
+	boarding := &entities.ServiceModel{
+		Sid: "s1", PetID: "pet-1", ServiceType: "cservice", StaffID: "care-1", Status: db.ServiceStatusFinish,
+		ReserveDateStart: past, ReserveDateEnd: past.Add(48 * time.Hour), Score: &score, Comment: &comment,
+	}
+	checkup := &entities.ServiceModel{
+		Sid: "s2", PetID: "pet-1", ServiceType: "mservice", StaffID: "doc-1", Status: db.ServiceStatusFinish,
+		ReserveDateStart: past.Add(24 * time.Hour), ReserveDateEnd: past.Add(25 * time.Hour),
+	}
+	followUp := &entities.ServiceModel{
+		Sid: "s3", PetID: "pet-1", ServiceType: "mservice", StaffID: "doc-1", Status: db.ServiceStatusWait,
+		ReserveDateStart: time.Now().Add(72 * time.Hour), ReserveDateEnd: time.Now().Add(73 * time.Hour),
+	}
+	checkupID := "s2"
+	firstWeight, secondWeight := decimal.RequireFromString("4.20"), decimal.RequireFromString("4.50")
+	earlier := &entities.ServiceModel{
+		Sid: "s0", PetID: "pet-1", ServiceType: "mservice", StaffID: "doc-2", Status: db.ServiceStatusFinish,
+		ReserveDateStart: past.Add(-24 * time.Hour), ReserveDateEnd: past.Add(-23 * time.Hour),
+	}
+	services := []*entities.ServiceModel{earlier, boarding, checkup, followUp}
+	records := []*entities.MedicalRecordModel{
+		{ServiceID: "s2", Diagnosis: "otitis", Weight: &secondWeight},
+		{ServiceID: "s0", Diagnosis: "vaccination", Weight: &firstWeight},
+	}
+	earlierID := "s0"
+	weights := []*entities.PetWeightModel{
+		{Weight: firstWeight, Source: db.PetWeightSourceVitals, ServiceID: &earlierID, MeasuredAt: earlier.ReserveDateStart},
+		{Weight: secondWeight, Source: db.PetWeightSourceVitals, ServiceID: &checkupID, MeasuredAt: checkup.ReserveDateStart},
+	}
+
+	vaccinations := []*entities.VaccinationModel{
+		// given_date is a plain date, linked doses follow the visit they came from
+		{ID: "v1", Vaccine: "Rabies", ServiceID: &checkupID, GivenDate: startOfDay(checkup.ReserveDateStart)},
+	}
+
+	newService := func(ctrl *gomock.Controller) (*PetService, *mocks.MockIPetRepository, *mocks.MockIServiceRepository, *mocks.MockIMedicalRecordRepository, *mocks.MockIMedicineRepository, *mocks.MockIVaccinationRepository, *mocks.MockIPetWeightRepository) {
+		mockPet := mocks.NewMockIPetRepository(ctrl)
+		mockService := mocks.NewMockIServiceRepository(ctrl)
+		mockRecord := mocks.NewMockIMedicalRecordRepository(ctrl)
+		mockMedicine := mocks.NewMockIMedicineRepository(ctrl)
+		mockVaccination := mocks.NewMockIVaccinationRepository(ctrl)
+		mockWeight := mocks.NewMockIPetWeightRepository(ctrl)
+		sv := &PetService{
+			PetRepository: mockPet, ServiceRepo: mockService, RecordRepo: mockRecord, MedicineRepo: mockMedicine,
+			VaccinationRepo: mockVaccination, WeightRepo: mockWeight,
+		}
+		return sv, mockPet, mockService, mockRecord, mockMedicine, mockVaccination, mockWeight
+	}
+
+	t.Run("owner gets events in chronological order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockPet, mockService, mockRecord, mockMedicine, mockVaccination, mockWeight := newService(ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1", OwnerID: "owner-1"}, nil)
+		mockService.EXPECT().FindByPetID("pet-1", timelineStatuses).Return(services, nil)
+		mockRecord.EXPECT().FindByPetID("pet-1").Return(records, nil)
+		mockMedicine.EXPECT().FindByServiceIDs([]string{"s2", "s0"}).Return(map[string][]entities.MedicineModel{
+			"s2": {{Name: "Otomax"}},
+		}, nil)
+		mockVaccination.EXPECT().FindByPetID("pet-1").Return(vaccinations, nil)
+		mockWeight.EXPECT().FindByPetID("pet-1", nil, nil).Return(weights, nil)
+
+		events, total, err := sv.FindPetTimeline("pet-1", "owner-1", "owner", entities.PetTimelineFilter{Page: 1, Limit: 20})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		want := []string{
+			"mservice", "diagnosis", "weight", // s0
+			"cservice",                                                   // s1
+			"mservice", "diagnosis", "medicine", "weight", "vaccination", // s2
+			"review",   // s1 ends after s2 started
+			"mservice", // s3
+		}
+		if total != len(want) {
+			t.Fatalf("expected %d events, got %d: %+v", len(want), total, events)
+		}
+		for i, typ := range want {
+			if events[i].Type != typ {
+				t.Fatalf("event %d: expected %s, got %s", i, typ, events[i].Type)
+			}
+		}
+		if events[2].WeightChange != nil {
+			t.Fatalf("first weight should have no change, got %v", events[2].WeightChange)
+		}
+		if !events[7].WeightChange.Equal(decimal.RequireFromString("0.30")) {
+			t.Fatalf("expected +0.30 kg, got %v", events[7].WeightChange)
+		}
+		if events[8].Vaccination.ID != "v1" || events[8].ServiceID != "s2" {
+			t.Fatalf("unexpected vaccination event %+v", events[8])
+		}
+		if !events[10].Upcoming || events[4].Upcoming {
+			t.Fatalf("only the wait booking in the future is upcoming")
+		}
+	})
+
+	t.Run("type filter and pagination", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockPet, mockService, mockRecord, mockMedicine, mockVaccination, mockWeight := newService(ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1", OwnerID: "owner-1"}, nil)
+		mockService.EXPECT().FindByPetID("pet-1", timelineStatuses).Return(services, nil)
+		mockRecord.EXPECT().FindByPetID("pet-1").Return(records, nil)
+		mockMedicine.EXPECT().FindByServiceIDs(gomock.Any()).Return(nil, nil)
+		mockVaccination.EXPECT().FindByPetID("pet-1").Return(vaccinations, nil)
+		mockWeight.EXPECT().FindByPetID("pet-1", nil, nil).Return(weights, nil)
+
+		events, total, err := sv.FindPetTimeline("pet-1", "admin-1", "admin", entities.PetTimelineFilter{
+			Types: []string{"mservice", "cservice"}, Page: 2, Limit: 3,
+		})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if total != 4 || len(events) != 1 || events[0].ServiceID != "s3" {
+			t.Fatalf("unexpected page: total=%d %+v", total, events)
+		}
+	})
+
+	t.Run("doctor who never treated the pet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockPet, mockService, _, _, _, _ := newService(ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1", OwnerID: "owner-1"}, nil)
+		// doc-3 มีนัดที่ยังไม่ได้ตรวจเท่านั้น
+		booked := *followUp
+		booked.StaffID = "doc-3"
+		mockService.EXPECT().FindByPetID("pet-1", timelineStatuses).Return([]*entities.ServiceModel{earlier, &booked}, nil)
+
+		if _, _, err := sv.FindPetTimeline("pet-1", "doc-3", "doctor", entities.PetTimelineFilter{}); !errors.Is(err, ErrPetTimelineForbidden) {
+			t.Fatalf("expected ErrPetTimelineForbidden, got %v", err)
+		}
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		sv := &PetService{}
+		if _, _, err := sv.FindPetTimeline("pet-1", "owner-1", "owner", entities.PetTimelineFilter{Types: []string{"photo"}}); !errors.Is(err, ErrInvalidTimelineType) {
+			t.Fatalf("expected ErrInvalidTimelineType, got %v", err)
+		}
+	})
+}
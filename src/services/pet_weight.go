@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/src/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidWeightBucket = errors.New("bucket must be day, week or month")
+	ErrPetWeightForbidden  = errors.New("not allowed to read the weight history of this pet")
+)
+
+// FindWeightHistory returns the weight series of the pet, oldest first. With a bucket
+// (day, week, month) the measurements in each bucket are averaged into one point. Owners
+// read their own pets, doctors the pets they have treated, admins any pet.
+func (s *PetService) FindWeightHistory(petID, userID, role string, filter entities.PetWeightFilter) ([]entities.PetWeightPoint, error) {
+	if filter.Bucket != "" && filter.Bucket != "day" && filter.Bucket != "week" && filter.Bucket != "month" {
+		return nil, fmt.Errorf("pet -> FindWeightHistory: %w", ErrInvalidWeightBucket)
+	}
+
+	pet, err := s.PetRepository.FindPetByID(petID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := canReadPet(s.ServiceRepo, pet, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("pet -> FindWeightHistory: %w", ErrPetWeightForbidden)
+	}
+
+	weights, err := s.WeightRepo.FindByPetID(petID, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Bucket == "" {
+		points := make([]entities.PetWeightPoint, 0, len(weights))
+		for _, w := range weights {
+			points = append(points, entities.PetWeightPoint{
+				MeasuredAt: w.MeasuredAt,
+				Weight:     w.Weight,
+				Count:      1,
+				Source:     &w.Source,
+				ServiceID:  w.ServiceID,
+			})
+		}
+		return points, nil
+	}
+	return downsampleWeights(weights, filter.Bucket), nil
+}
+
+// weights must be ordered by measured_at.
+func downsampleWeights(weights []*entities.PetWeightModel, bucket string) []entities.PetWeightPoint {
+	points := []entities.PetWeightPoint{}
+	var sum decimal.Decimal
+	for _, w := range weights {
+		start := weightBucketStart(w.MeasuredAt, bucket)
+
+		last := len(points) - 1
+		if last < 0 || !points[last].MeasuredAt.Equal(start) {
+			if last >= 0 {
+				points[last].Weight = sum.Div(decimal.NewFromInt(int64(points[last].Count))).Round(2)
+			}
+			low, high := w.Weight, w.Weight
+			points = append(points, entities.PetWeightPoint{MeasuredAt: start, Min: &low, Max: &high})
+			last++
+			sum = decimal.Zero
+		}
+
+		p := &points[last]
+		p.Count++
+		sum = sum.Add(w.Weight)
+		if w.Weight.LessThan(*p.Min) {
+			*p.Min = w.Weight
+		}
+		if w.Weight.GreaterThan(*p.Max) {
+			*p.Max = w.Weight
+		}
+	}
+	if last := len(points) - 1; last >= 0 {
+		points[last].Weight = sum.Div(decimal.NewFromInt(int64(points[last].Count))).Round(2)
+	}
+
+	return points
+}
+
+// สัปดาห์เริ่มวันจันทร์ วันอิงเวลาไทยเหมือนที่อื่นในระบบ
+func weightBucketStart(t time.Time, bucket string) time.Time {
+	day := startOfDay(t)
+	switch bucket {
+	case "week":
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "month":
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, utils.BusinessLocation)
+	default:
+		return day
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/services/mocks"
+	"lama-backend/src/utils"
+)
+
+func TestPetService_FindWeightHistory(t *testing.T) {
+	kg := decimal.RequireFromString
+	serviceID := "s1"
+	// scheduleMonday เป็นวันจันทร์ ใช้เป็นต้นสัปดาห์ได้เลย
+	weights := []*entities.PetWeightModel{
+		{Weight: kg("4.00"), Source: db.PetWeightSourceProfile, MeasuredAt: clockOn(scheduleMonday, 9, 0)},
+		{Weight: kg("4.30"), Source: db.PetWeightSourceVitals, ServiceID: &serviceID, MeasuredAt: clockOn(scheduleMonday.AddDate(0, 0, 4), 10, 0)},
+		{Weight: kg("4.45"), Source: db.PetWeightSourceProfile, MeasuredAt: clockOn(scheduleMonday.AddDate(0, 0, 6), 18, 0)},
+		{Weight: kg("4.60"), Source: db.PetWeightSourceProfile, MeasuredAt: clockOn(scheduleMonday.AddDate(0, 0, 7), 8, 0)},
+	}
+
+	newService := func(ctrl *gomock.Controller) (*PetService, *mocks.MockIPetRepository, *mocks.MockIPetWeightRepository, *mocks.MockIServiceRepository) {
+		mockPet := mocks.NewMockIPetRepository(ctrl)
+		mockWeight := mocks.NewMockIPetWeightRepository(ctrl)
+		mockService := mocks.NewMockIServiceRepository(ctrl)
+		return &PetService{PetRepository: mockPet, WeightRepo: mockWeight, ServiceRepo: mockService}, mockPet, mockWeight, mockService
+	}
+
+	t.Run("owner reads the raw series", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockPet, mockWeight, _ := newService(ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{OwnerID: "owner-1"}, nil)
+		mockWeight.EXPECT().FindByPetID("pet-1", nil, nil).Return(weights, nil)
+
+		points, err := sv.FindWeightHistory("pet-1", "owner-1", "owner", entities.PetWeightFilter{})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(points) != 4 || points[1].Count != 1 || *points[1].Source != db.PetWeightSourceVitals || *points[1].ServiceID != "s1" {
+			t.Fatalf("unexpected points %+v", points)
+		}
+		if points[0].Min != nil {
+			t.Fatalf("raw points have no min/max, got %+v", points[0])
+		}
+	})
+
+	t.Run("weekly buckets average with min and max", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockPet, mockWeight, mockService := newService(ctrl)
+
+		from := scheduleMonday
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1", OwnerID: "owner-1"}, nil)
+		mockService.EXPECT().FindByPetID("pet-1", timelineStatuses).Return([]*entities.ServiceModel{
+			{Sid: "s1", ServiceType: "mservice", StaffID: "doc-1", Status: db.ServiceStatusFinish},
+		}, nil)
+		mockWeight.EXPECT().FindByPetID("pet-1", &from, nil).Return(weights, nil)
+
+		points, err := sv.FindWeightHistory("pet-1", "doc-1", "doctor", entities.PetWeightFilter{From: &from, Bucket: "week"})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(points) != 2 {
+			t.Fatalf("expected 2 weeks, got %+v", points)
+		}
+		first, second := points[0], points[1]
+		if !first.MeasuredAt.Equal(scheduleMonday) || first.Count != 3 || !first.Weight.Equal(kg("4.25")) ||
+			!first.Min.Equal(kg("4.00")) || !first.Max.Equal(kg("4.45")) || first.Source != nil {
+			t.Fatalf("unexpected first week %+v", first)
+		}
+		if !second.MeasuredAt.Equal(scheduleMonday.AddDate(0, 0, 7)) || second.Count != 1 || !second.Weight.Equal(kg("4.60")) {
+			t.Fatalf("unexpected second week %+v", second)
+		}
+	})
+
+	t.Run("monthly buckets start on the first", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockPet, mockWeight, _ := newService(ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{OwnerID: "owner-1"}, nil)
+		mockWeight.EXPECT().FindByPetID("pet-1", nil, nil).Return(weights, nil)
+
+		points, err := sv.FindWeightHistory("pet-1", "admin-1", "admin", entities.PetWeightFilter{Bucket: "month"})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		march := time.Date(2026, 3, 1, 0, 0, 0, 0, utils.BusinessLocation)
+		if len(points) != 1 || !points[0].MeasuredAt.Equal(march) || points[0].Count != 4 || !points[0].Weight.Equal(kg("4.34")) {
+			t.Fatalf("unexpected points %+v", points)
+		}
+	})
+
+	t.Run("owner of another pet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockPet, _, _ := newService(ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{OwnerID: "owner-2"}, nil)
+
+		if _, err := sv.FindWeightHistory("pet-1", "owner-1", "owner", entities.PetWeightFilter{}); !errors.Is(err, ErrPetWeightForbidden) {
+			t.Fatalf("expected ErrPetWeightForbidden, got %v", err)
+		}
+	})
+
+	t.Run("doctor who never treated the pet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockPet, _, mockService := newService(ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1", OwnerID: "owner-1"}, nil)
+		mockService.EXPECT().FindByPetID("pet-1", timelineStatuses).Return(nil, nil)
+
+		if _, err := sv.FindWeightHistory("pet-1", "doc-2", "doctor", entities.PetWeightFilter{}); !errors.Is(err, ErrPetWeightForbidden) {
+			t.Fatalf("expected ErrPetWeightForbidden, got %v", err)
+		}
+	})
+
+	t.Run("unknown bucket", func(t *testing.T) {
+		sv := &PetService{}
+		if _, err := sv.FindWeightHistory("pet-1", "owner-1", "owner", entities.PetWeightFilter{Bucket: "year"}); !errors.Is(err, ErrInvalidWeightBucket) {
+			t.Fatalf("expected ErrInvalidWeightBucket, got %v", err)
+		}
+	})
+}
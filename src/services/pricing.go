@@ -0,0 +1,265 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+type PricingService struct {
+	repo repositories.IPricingRepository
+}
+
+type IPricingService interface {
+	GetPricing() (*entities.PricingConfigModel, error)
+	UpsertRateCard(serviceType string, data entities.RateRequest) (*entities.RateCardModel, error)
+	DeleteRateCard(serviceType string) error
+	UpsertStaffRate(staffID string, data entities.RateRequest) (*entities.StaffRateModel, error)
+	DeleteStaffRate(staffID string) error
+	CreatePetSurcharge(data entities.PetSurchargeRequest) (*entities.PetSurchargeModel, error)
+	UpdatePetSurcharge(id string, data entities.PetSurchargeRequest) (*entities.PetSurchargeModel, error)
+	DeletePetSurcharge(id string) error
+	CreateMultiplier(data entities.PriceMultiplierRequest) (*entities.PriceMultiplierModel, error)
+	UpdateMultiplier(id string, data entities.PriceMultiplierRequest) (*entities.PriceMultiplierModel, error)
+	DeleteMultiplier(id string) error
+	UpsertHoliday(date time.Time, data entities.HolidayRequest) (*entities.HolidayModel, error)
+	DeleteHoliday(date time.Time) error
+}
+
+// ErrInvalidPricingRule is returned for rules that pass field validation but don't make sense together.
+var ErrInvalidPricingRule = errors.New("invalid pricing rule")
+
+const (
+	// ราคาเดิมของ CalPrice ใช้เมื่อยังไม่ได้ตั้ง rate card
+	defaultHourlyRate = 100
+
+	lineItemBase         = "base"
+	lineItemPetSurcharge = "pet_surcharge"
+	lineItemMultiplier   = "multiplier"
+	lineItemAdjustment   = "adjustment"
+)
+
+func NewPricingService(repo repositories.IPricingRepository) IPricingService {
+	return &PricingService{
+		repo: repo,
+	}
+}
+
+func (s *PricingService) GetPricing() (*entities.PricingConfigModel, error) {
+	return s.repo.FindAll()
+}
+
+func (s *PricingService) UpsertRateCard(serviceType string, data entities.RateRequest) (*entities.RateCardModel, error) {
+	if serviceType != "mservice" && serviceType != "cservice" {
+		return nil, fmt.Errorf("%w: service type must be mservice or cservice", ErrInvalidPricingRule)
+	}
+	return s.repo.UpsertRateCard(serviceType, data.HourlyRate)
+}
+
+func (s *PricingService) DeleteRateCard(serviceType string) error {
+	return s.repo.DeleteRateCard(serviceType)
+}
+
+func (s *PricingService) UpsertStaffRate(staffID string, data entities.RateRequest) (*entities.StaffRateModel, error) {
+	return s.repo.UpsertStaffRate(staffID, data.HourlyRate)
+}
+
+func (s *PricingService) DeleteStaffRate(staffID string) error {
+	return s.repo.DeleteStaffRate(staffID)
+}
+
+func (s *PricingService) CreatePetSurcharge(data entities.PetSurchargeRequest) (*entities.PetSurchargeModel, error) {
+	surcharge, err := toPetSurchargeModel(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.InsertPetSurcharge(surcharge)
+}
+
+func (s *PricingService) UpdatePetSurcharge(id string, data entities.PetSurchargeRequest) (*entities.PetSurchargeModel, error) {
+	surcharge, err := toPetSurchargeModel(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.UpdatePetSurcharge(id, surcharge)
+}
+
+func (s *PricingService) DeletePetSurcharge(id string) error {
+	return s.repo.DeletePetSurcharge(id)
+}
+
+func (s *PricingService) CreateMultiplier(data entities.PriceMultiplierRequest) (*entities.PriceMultiplierModel, error) {
+	return s.repo.InsertMultiplier(toPriceMultiplierModel(data))
+}
+
+func (s *PricingService) UpdateMultiplier(id string, data entities.PriceMultiplierRequest) (*entities.PriceMultiplierModel, error) {
+	return s.repo.UpdateMultiplier(id, toPriceMultiplierModel(data))
+}
+
+func (s *PricingService) DeleteMultiplier(id string) error {
+	return s.repo.DeleteMultiplier(id)
+}
+
+func (s *PricingService) UpsertHoliday(date time.Time, data entities.HolidayRequest) (*entities.HolidayModel, error) {
+	return s.repo.UpsertHoliday(date, strings.TrimSpace(data.Name))
+}
+
+func (s *PricingService) DeleteHoliday(date time.Time) error {
+	return s.repo.DeleteHoliday(date)
+}
+
+func toPetSurchargeModel(data entities.PetSurchargeRequest) (entities.PetSurchargeModel, error) {
+	surcharge := entities.PetSurchargeModel{
+		Name:          strings.TrimSpace(data.Name),
+		ServiceType:   data.ServiceType,
+		AmountPerHour: data.AmountPerHour,
+	}
+	if data.Kind != nil {
+		kind := strings.ToLower(strings.TrimSpace(*data.Kind))
+		surcharge.Kind = &kind
+	}
+	if data.MinWeight != nil {
+		minWeight := decimal.NewFromFloat(*data.MinWeight)
+		surcharge.MinWeight = &minWeight
+	}
+	if data.MaxWeight != nil {
+		maxWeight := decimal.NewFromFloat(*data.MaxWeight)
+		surcharge.MaxWeight = &maxWeight
+	}
+	if surcharge.MinWeight != nil && surcharge.MaxWeight != nil && !surcharge.MinWeight.LessThan(*surcharge.MaxWeight) {
+		return surcharge, fmt.Errorf("%w: min_weight must be less than max_weight", ErrInvalidPricingRule)
+	}
+
+	return surcharge, nil
+}
+
+func toPriceMultiplierModel(data entities.PriceMultiplierRequest) entities.PriceMultiplierModel {
+	return entities.PriceMultiplierModel{
+		Name:       strings.TrimSpace(data.Name),
+		DayType:    db.PriceDayType(data.DayType),
+		StartHour:  data.StartHour,
+		EndHour:    data.EndHour,
+		Multiplier: decimal.NewFromFloat(data.Multiplier).Round(2),
+	}
+}
+
+// calculatePrice breaks a booking down into line items. Every line keeps
+// amount = quantity * unit_amount so it can be sent to Stripe as is.
+func calculatePrice(rules *entities.PricingRules, serviceType string, pet *entities.PetDataModel, start, end time.Time) ([]entities.PaymentLineItemModel, int) {
+	hours := int(math.Ceil(end.Sub(start).Hours()))
+	if hours <= 0 {
+		return nil, 0
+	}
+
+	rate := defaultHourlyRate
+	if rules.StaffRate != nil {
+		rate = rules.StaffRate.HourlyRate
+	} else if rules.RateCard != nil {
+		rate = rules.RateCard.HourlyRate
+	}
+
+	lineItems := []entities.PaymentLineItemModel{
+		newLineItem(lineItemBase, fmt.Sprintf("%s (%d hr)", serviceTypeLabel(serviceType), hours), hours, rate),
+	}
+
+	if pet != nil {
+		for _, surcharge := range rules.PetSurcharges {
+			if !petSurchargeApplies(surcharge, serviceType, pet) {
+				continue
+			}
+			lineItems = append(lineItems, newLineItem(lineItemPetSurcharge, surcharge.Name, hours, surcharge.AmountPerHour))
+		}
+	}
+
+	holidays := map[string]bool{}
+	for _, holiday := range rules.Holidays {
+		holidays[holiday.Date.UTC().Format(time.DateOnly)] = true
+	}
+	one := decimal.NewFromInt(1)
+	for _, multiplier := range rules.Multipliers {
+		// คิดเฉพาะส่วนที่เพิ่มจากราคาฐาน ต่อชั่วโมงที่ตรงเงื่อนไข
+		extra := int(decimal.NewFromInt(int64(rate)).Mul(multiplier.Multiplier.Sub(one)).Round(0).IntPart())
+		if extra <= 0 {
+			continue
+		}
+		matched := 0
+		for h := 0; h < hours; h++ {
+			if multiplierApplies(multiplier, start.Add(time.Duration(h)*time.Hour).In(utils.BusinessLocation), holidays) {
+				matched++
+			}
+		}
+		if matched > 0 {
+			lineItems = append(lineItems, newLineItem(lineItemMultiplier, fmt.Sprintf("%s (x%s)", multiplier.Name, multiplier.Multiplier.String()), matched, extra))
+		}
+	}
+
+	total := 0
+	for _, item := range lineItems {
+		total += item.Amount
+	}
+
+	return lineItems, total
+}
+
+func newLineItem(kind, description string, quantity, unitAmount int) entities.PaymentLineItemModel {
+	return entities.PaymentLineItemModel{
+		Kind:        kind,
+		Description: description,
+		Quantity:    quantity,
+		UnitAmount:  unitAmount,
+		Amount:      quantity * unitAmount,
+	}
+}
+
+func serviceTypeLabel(serviceType string) string {
+	switch serviceType {
+	case "mservice":
+		return "Doctor service"
+	case "cservice":
+		return "Caretaker service"
+	default:
+		return "Service"
+	}
+}
+
+// weight band คือ [min_weight, max_weight)
+func petSurchargeApplies(surcharge entities.PetSurchargeModel, serviceType string, pet *entities.PetDataModel) bool {
+	if surcharge.ServiceType != nil && *surcharge.ServiceType != serviceType {
+		return false
+	}
+	if surcharge.Kind != nil && !strings.EqualFold(*surcharge.Kind, strings.TrimSpace(pet.Kind)) {
+		return false
+	}
+	if surcharge.MinWeight != nil && pet.Weight.LessThan(*surcharge.MinWeight) {
+		return false
+	}
+	if surcharge.MaxWeight != nil && !pet.Weight.LessThan(*surcharge.MaxWeight) {
+		return false
+	}
+	return true
+}
+
+// วันหยุดนักขัตฤกษ์คิดเหมือนวันเสาร์อาทิตย์
+func multiplierApplies(multiplier entities.PriceMultiplierModel, at time.Time, holidays map[string]bool) bool {
+	weekend := at.Weekday() == time.Saturday || at.Weekday() == time.Sunday || holidays[at.Format(time.DateOnly)]
+	switch multiplier.DayType {
+	case db.PriceDayTypeWeekday:
+		if weekend {
+			return false
+		}
+	case db.PriceDayTypeWeekend:
+		if !weekend {
+			return false
+		}
+	}
+	return at.Hour() >= multiplier.StartHour && at.Hour() < multiplier.EndHour
+}
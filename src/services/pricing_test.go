@@ -0,0 +1,284 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/services/mocks"
+	"lama-backend/src/utils"
+)
+
+func ictTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, utils.BusinessLocation)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestCalculatePrice(t *testing.T) {
+	cat := &entities.PetDataModel{Kind: "Cat", Weight: decimal.NewFromFloat(4.5)}
+	bigDog := &entities.PetDataModel{Kind: "dog", Weight: decimal.NewFromInt(30)}
+
+	dog := "dog"
+	cservice := "cservice"
+	twenty := decimal.NewFromInt(20)
+
+	rules := &entities.PricingRules{
+		RateCard: &entities.RateCardModel{ServiceType: "cservice", HourlyRate: 150},
+		PetSurcharges: []entities.PetSurchargeModel{
+			{Name: "Large dog", Kind: &dog, MinWeight: &twenty, AmountPerHour: 50},
+			{Name: "Caretaker only", ServiceType: &cservice, AmountPerHour: 10},
+		},
+		Multipliers: []entities.PriceMultiplierModel{
+			{Name: "Night", DayType: db.PriceDayTypeAny, StartHour: 20, EndHour: 24, Multiplier: decimal.NewFromFloat(1.5)},
+			{Name: "Weekend", DayType: db.PriceDayTypeWeekend, StartHour: 0, EndHour: 24, Multiplier: decimal.NewFromFloat(1.2)},
+		},
+		Holidays: []entities.HolidayModel{
+			{Date: time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC), Name: "Songkran"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		rules       *entities.PricingRules
+		serviceType string
+		pet         *entities.PetDataModel
+		start, end  string
+		wantTotal   int
+		wantKinds   []string
+	}{
+		{
+			name:        "no rules falls back to the old flat rate",
+			rules:       &entities.PricingRules{},
+			serviceType: "mservice", pet: cat,
+			start: "2025-04-09 10:00", end: "2025-04-09 13:00",
+			wantTotal: 300, wantKinds: []string{lineItemBase},
+		},
+		{
+			name:        "staff rate overrides rate card",
+			rules:       &entities.PricingRules{RateCard: rules.RateCard, StaffRate: &entities.StaffRateModel{HourlyRate: 200}},
+			serviceType: "cservice", pet: cat,
+			start: "2025-04-09 10:00", end: "2025-04-09 12:00",
+			wantTotal: 400, wantKinds: []string{lineItemBase},
+		},
+		{
+			name:        "weekday daytime with caretaker surcharge",
+			rules:       rules,
+			serviceType: "cservice", pet: cat,
+			start: "2025-04-09 10:00", end: "2025-04-09 12:00",
+			// 2*150 + 2*10
+			wantTotal: 320, wantKinds: []string{lineItemBase, lineItemPetSurcharge},
+		},
+		{
+			name:        "large dog into the night",
+			rules:       rules,
+			serviceType: "cservice", pet: bigDog,
+			start: "2025-04-09 19:00", end: "2025-04-09 22:00",
+			// 3*150 + 3*50 + 3*10 + 2 night hours * 75
+			wantTotal: 780, wantKinds: []string{lineItemBase, lineItemPetSurcharge, lineItemPetSurcharge, lineItemMultiplier},
+		},
+		{
+			name:        "holiday counts as weekend",
+			rules:       rules,
+			serviceType: "cservice", pet: cat,
+			start: "2025-04-14 09:00", end: "2025-04-14 11:00",
+			// 2*150 + 2*10 + 2*30
+			wantTotal: 380, wantKinds: []string{lineItemBase, lineItemPetSurcharge, lineItemMultiplier},
+		},
+		{
+			name:        "multipliers use thai time regardless of input zone",
+			rules:       &entities.PricingRules{Multipliers: rules.Multipliers[:1]},
+			serviceType: "mservice", pet: cat,
+			// 13:00-15:00 UTC is 20:00-22:00 ICT on a Wednesday
+			start: "2025-04-09 20:00", end: "2025-04-09 22:00",
+			wantTotal: 300, wantKinds: []string{lineItemBase, lineItemMultiplier},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := ictTime(t, tt.start).UTC(), ictTime(t, tt.end).UTC()
+
+			items, total := calculatePrice(tt.rules, tt.serviceType, tt.pet, start, end)
+			if total != tt.wantTotal {
+				t.Fatalf("total: want %d got %d (%+v)", tt.wantTotal, total, items)
+			}
+			if len(items) != len(tt.wantKinds) {
+				t.Fatalf("line items: want %v got %+v", tt.wantKinds, items)
+			}
+			sum := 0
+			for i, item := range items {
+				if item.Kind != tt.wantKinds[i] {
+					t.Fatalf("line %d kind: want %s got %s", i, tt.wantKinds[i], item.Kind)
+				}
+				if item.Amount != item.Quantity*item.UnitAmount {
+					t.Fatalf("line %d amount %d != %d x %d", i, item.Amount, item.Quantity, item.UnitAmount)
+				}
+				sum += item.Amount
+			}
+			if sum != total {
+				t.Fatalf("line items sum %d, total %d", sum, total)
+			}
+		})
+	}
+}
+
+func TestPricingService_CreatePetSurcharge_InvalidBand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sv := &PricingService{repo: mocks.NewMockIPricingRepository(ctrl)}
+	minWeight, maxWeight := 20.0, 10.0
+
+	_, err := sv.CreatePetSurcharge(entities.PetSurchargeRequest{Name: "band", MinWeight: &minWeight, MaxWeight: &maxWeight, AmountPerHour: 10})
+	if !errors.Is(err, ErrInvalidPricingRule) {
+		t.Fatalf("expected ErrInvalidPricingRule, got %v", err)
+	}
+}
+
+func TestPricingService_UpsertRateCard_UnknownType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sv := &PricingService{repo: mocks.NewMockIPricingRepository(ctrl)}
+
+	if _, err := sv.UpsertRateCard("grooming", entities.RateRequest{HourlyRate: 100}); !errors.Is(err, ErrInvalidPricingRule) {
+		t.Fatalf("expected ErrInvalidPricingRule, got %v", err)
+	}
+}
+
+func TestPaymentService_InsertPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIPaymentRepository(ctrl)
+	mockPricing := mocks.NewMockIPricingRepository(ctrl)
+	mockPet := mocks.NewMockIPetRepository(ctrl)
+	mockUow := mocks.NewMockIUnitOfWork(ctrl)
+	sv := &PaymentService{repo: mockRepo, pricingRepo: mockPricing, petRepo: mockPet, unitOfWork: mockUow}
+
+	req := entities.CreateServiceRequest{
+		OwnerID:          "owner-1",
+		PetID:            "pet-1",
+		StaffID:          "staff-1",
+		ServiceType:      "cservice",
+		ReserveDateStart: ictTime(t, "2025-04-09 10:00"),
+		ReserveDateEnd:   ictTime(t, "2025-04-09 12:00"),
+	}
+
+	tests := []struct {
+		name      string
+		commitErr error
+		wantErr   bool
+	}{
+		{name: "payment and line items committed together"},
+		{name: "commit fails", commitErr: errors.New("db down"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &repositories.Tx{}
+			var paymentID string
+
+			mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1", OwnerID: "owner-1", Kind: "cat", Weight: decimal.NewFromInt(4)}, nil)
+			mockPricing.EXPECT().
+				FindRules("cservice", "staff-1", req.ReserveDateStart, req.ReserveDateEnd).
+				Return(&entities.PricingRules{RateCard: &entities.RateCardModel{ServiceType: "cservice", HourlyRate: 150}}, nil)
+			mockUow.EXPECT().Begin().Return(tx)
+			mockRepo.EXPECT().
+				InsertPaymentTx(tx, gomock.Any(), "owner-1", 300, gomock.Len(1)).
+				Do(func(_ *repositories.Tx, id, _ string, _ int, _ []entities.PaymentLineItemModel) { paymentID = id })
+			mockUow.EXPECT().Commit(tx).Return(tt.commitErr)
+			if !tt.wantErr {
+				mockRepo.EXPECT().FindByID(gomock.Any()).DoAndReturn(func(id string) (*entities.PaymentModel, error) {
+					if id != paymentID {
+						t.Fatalf("FindByID(%q), inserted %q", id, paymentID)
+					}
+					return &entities.PaymentModel{PayID: id, Price: 300}, nil
+				})
+			}
+
+			payment, err := sv.InsertPayment(req)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if payment.Price != 300 {
+				t.Fatalf("price: want 300 got %d", payment.Price)
+			}
+		})
+	}
+}
+
+func TestPaymentService_QuotePrice(t *testing.T) {
+	req := entities.CreateServiceRequest{
+		OwnerID:          "owner-1",
+		PetID:            "pet-1",
+		StaffID:          "staff-1",
+		ServiceType:      "mservice",
+		ReserveDateStart: ictTime(t, "2025-04-09 10:00"),
+		ReserveDateEnd:   ictTime(t, "2025-04-09 11:00"),
+	}
+
+	tests := []struct {
+		name      string
+		petOwner  string
+		wantPrice int
+		wantErr   error
+	}{
+		{name: "priced without writing", petOwner: "owner-1", wantPrice: 100},
+		{name: "someone else's pet", petOwner: "owner-2", wantErr: db.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// no expectations on the payment repo or unit of work: any write fails the test
+			mockPricing := mocks.NewMockIPricingRepository(ctrl)
+			mockPet := mocks.NewMockIPetRepository(ctrl)
+			sv := &PaymentService{
+				repo:        mocks.NewMockIPaymentRepository(ctrl),
+				pricingRepo: mockPricing,
+				petRepo:     mockPet,
+				unitOfWork:  mocks.NewMockIUnitOfWork(ctrl),
+			}
+
+			mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1", OwnerID: tt.petOwner, Kind: "cat", Weight: decimal.NewFromInt(4)}, nil)
+			if tt.wantErr == nil {
+				mockPricing.EXPECT().
+					FindRules("mservice", "staff-1", req.ReserveDateStart, req.ReserveDateEnd).
+					Return(&entities.PricingRules{}, nil)
+			}
+
+			quote, err := sv.QuotePrice(req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want %v got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if quote.Price != tt.wantPrice || len(quote.LineItems) != 1 {
+				t.Fatalf("unexpected quote: %+v", quote)
+			}
+		})
+	}
+}
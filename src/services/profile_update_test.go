@@ -2,7 +2,9 @@
 package services
 
 import (
+	"bytes"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"lama-backend/domain/entities"
 	"lama-backend/domain/prisma/db"
 	"lama-backend/src/services/mocks"
+	"lama-backend/src/storage"
 )
 
 func TestOwnerService_UpdateOwnerByID(t *testing.T) {
@@ -143,6 +146,67 @@ func TestUsersService_UpdateUsersByID(t *testing.T) {
 	}
 }
 
+func TestUsersService_UpdateProfilePicture(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+	upload := func(content []byte) entities.AttachmentUpload {
+		return entities.AttachmentUpload{FileName: "me.png", Size: int64(len(content)), Body: bytes.NewReader(content)}
+	}
+	newService := func(t *testing.T, ctrl *gomock.Controller) (*UsersService, *mocks.MockIUsersRepository, *storage.LocalStore) {
+		store := storage.NewLocalStore(t.TempDir(), "user-profile", "http://localhost:8080/api/v1/files", "secret")
+		if err := store.Put(t.Context(), "user-1/old.png", bytes.NewReader(png), int64(len(png)), "image/png"); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+		mockUsers := mocks.NewMockIUsersRepository(ctrl)
+		return &UsersService{UsersRepository: mockUsers, ProfileStore: store}, mockUsers, store
+	}
+
+	t.Run("new picture replaces the old one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		svc, mockUsers, store := newService(t, ctrl)
+
+		var profile string
+		mockUsers.EXPECT().UpdateByID("user-1", gomock.Any()).DoAndReturn(func(_ string, data entities.UpdateUserModel) (*entities.UserDataModel, error) {
+			profile = *data.Profile
+			return &entities.UserDataModel{UserID: "user-1", Profile: profile}, nil
+		})
+
+		if _, err := svc.UpdateProfilePicture("user-1", upload(png)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		objects, _ := store.List(t.Context(), "user-1/")
+		if len(objects) != 1 || profile != store.PublicURL(objects[0].Key) || !strings.HasSuffix(profile, ".png") {
+			t.Fatalf("expected only the new picture, got %+v (profile %s)", objects, profile)
+		}
+	})
+
+	t.Run("old picture stays when saving the user fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		svc, mockUsers, store := newService(t, ctrl)
+
+		mockUsers.EXPECT().UpdateByID("user-1", gomock.Any()).Return(nil, errors.New("db down"))
+
+		if _, err := svc.UpdateProfilePicture("user-1", upload(png)); err == nil {
+			t.Fatalf("expected error")
+		}
+		objects, _ := store.List(t.Context(), "user-1/")
+		if len(objects) != 1 || objects[0].Key != "user-1/old.png" {
+			t.Fatalf("expected only the old picture, got %+v", objects)
+		}
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		svc, _, _ := newService(t, ctrl)
+
+		if _, err := svc.UpdateProfilePicture("user-1", upload([]byte("%PDF-1.7 not a picture"))); !errors.Is(err, ErrUnsupportedFileType) {
+			t.Fatalf("expected ErrUnsupportedFileType, got %v", err)
+		}
+	})
+}
+
 
 func TestPetService_UpdatePet(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrReassignmentClosed: admin คนอื่นจัดการไปแล้ว หรือเป็นเรื่องที่ย้ายอัตโนมัติไปแล้ว
+	ErrReassignmentClosed = repositories.ErrReassignmentClosed
+	ErrBookingNotWaiting  = errors.New("only waiting bookings can be reassigned")
+)
+
+// ลอง staff ที่ว่างกี่คนก่อนยอมแพ้ให้ admin จัดการ (คนแรกๆ อาจโดนจองตัดหน้าหรือติดวันลา)
+const reassignCandidates = 3
+
+// ReassignStaffBookings moves the staff's waiting bookings that start from from (until to
+// when given) away from them. With autoAssign each booking goes to the best rated staff that
+// is free at that time, otherwise or when nobody is free it is flagged as needs_action for an
+// admin. Every booking gets a ServiceReassignment row. The owner keeps the price they paid.
+func (s *ServiceService) ReassignStaffBookings(staffID string, from time.Time, to *time.Time, reason db.ReassignmentReason, autoAssign bool, actorID *string, note *string) ([]*entities.ServiceReassignmentModel, error) {
+	bookings, err := s.Repo.FindUpcomingByStaffID(staffID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("service -> ReassignStaffBookings: %w", err)
+	}
+
+	results := make([]*entities.ServiceReassignmentModel, 0, len(bookings))
+	for _, booking := range bookings {
+		record := entities.ServiceReassignmentModel{
+			ID:          uuid.NewString(),
+			ServiceID:   booking.Sid,
+			ServiceType: booking.ServiceType,
+			FromStaffID: staffID,
+			Reason:      reason,
+			Status:      db.ReassignmentStatusNeedsAction,
+			Note:        note,
+			CreatedBy:   actorID,
+			CreatedAt:   time.Now(),
+		}
+
+		if autoAssign {
+			assigned, err := s.autoReassign(booking, &record)
+			if err != nil {
+				return results, fmt.Errorf("service -> ReassignStaffBookings: %w", err)
+			}
+			if assigned {
+				results = append(results, &record)
+				continue
+			}
+		}
+
+		tx := s.UnitOfWork.Begin()
+		s.ReassignmentRepo.InsertTx(tx, record)
+		if err := s.UnitOfWork.Commit(tx); err != nil {
+			return results, fmt.Errorf("service -> ReassignStaffBookings: %w", err)
+		}
+		results = append(results, &record)
+	}
+
+	return results, nil
+}
+
+// autoReassign tries the free staff from the highest rating down. The guard in the tx
+// catches holds, leave and bookings made after FindAvailableStaff ran.
+func (s *ServiceService) autoReassign(booking *entities.ServiceModel, record *entities.ServiceReassignmentModel) (bool, error) {
+	candidates, err := s.FindAvailableStaff(booking.ServiceType, booking.ReserveDateStart, booking.ReserveDateEnd)
+	if err != nil {
+		return false, err
+	}
+	slices.SortStableFunc(candidates, func(a, b *entities.AvailableStaffResponse) int {
+		return b.Rating.Cmp(a.Rating)
+	})
+
+	tried := 0
+	for _, candidate := range candidates {
+		if candidate.ID == record.FromStaffID {
+			continue
+		}
+		if tried == reassignCandidates {
+			break
+		}
+		// FindAvailableStaff ดูแค่ว่าวันนั้นมีเวลาทำงาน ต้องเช็คชั่วโมงของ booking อีกที
+		if err := s.checkWorkingHours(candidate.ID, booking.ReserveDateStart, booking.ReserveDateEnd); err != nil {
+			if errors.Is(err, ErrStaffUnavailable) {
+				continue
+			}
+			return false, err
+		}
+		tried++
+
+		record.Status = db.ReassignmentStatusReassigned
+		record.ToStaffID = &candidate.ID
+		tx := s.UnitOfWork.Begin()
+		s.moveBookingTx(tx, booking, candidate.ID)
+		s.ReassignmentRepo.InsertTx(tx, *record)
+		err := s.UnitOfWork.Commit(tx)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, ErrStaffUnavailable) {
+			return false, err
+		}
+	}
+
+	record.Status = db.ReassignmentStatusNeedsAction
+	record.ToStaffID = nil
+	return false, nil
+}
+
+// moveBookingTx puts the booking on staffID. The Cservice/Mservice row is gone when the
+// previous staff was deleted, then a new one is created.
+func (s *ServiceService) moveBookingTx(tx *repositories.Tx, booking *entities.ServiceModel, staffID string) {
+	s.StaffHoldRepo.GuardSlotTx(tx, staffID, booking.ReserveDateStart, booking.ReserveDateEnd, booking.PaymentID)
+	sub := entities.SubService{ServiceID: booking.Sid, StaffID: staffID}
+	switch booking.ServiceType {
+	case "cservice":
+		if booking.StaffID == "" {
+			s.CserviceRepo.InsertTx(tx, sub)
+		} else {
+			s.CserviceRepo.UpdateStaffTx(tx, booking.Sid, staffID)
+		}
+	case "mservice":
+		if booking.StaffID == "" {
+			s.MserviceRepo.InsertTx(tx, sub)
+		} else {
+			s.MserviceRepo.UpdateStaffTx(tx, booking.Sid, staffID)
+		}
+	}
+}
+
+// ResolveReassignment closes a needs_action reassignment. With a staff_id the booking is
+// moved to that staff, without it the reassignment is just dismissed (e.g. the booking was
+// cancelled instead).
+func (s *ServiceService) ResolveReassignment(reassignmentID, adminID string, data entities.ResolveReassignmentRequest) (*entities.ServiceReassignmentModel, error) {
+	reassignment, err := s.ReassignmentRepo.FindByID(reassignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if reassignment.Status != db.ReassignmentStatusNeedsAction {
+		return nil, fmt.Errorf("service -> ResolveReassignment: %w", ErrReassignmentClosed)
+	}
+
+	var booking *entities.ServiceModel
+	if data.StaffID != nil {
+		if booking, err = s.Repo.FindByID(reassignment.ServiceID); err != nil {
+			return nil, err
+		}
+		if booking.Status != db.ServiceStatusWait {
+			return nil, fmt.Errorf("service -> ResolveReassignment: %w (status %s)", ErrBookingNotWaiting, booking.Status)
+		}
+		// staff ถูกลบไปแล้ว booking จะไม่มี type ให้ใช้ของที่บันทึกไว้
+		booking.ServiceType = reassignment.ServiceType
+		switch booking.ServiceType {
+		case "cservice":
+			if _, err := s.CaretakerRepo.FindByID(*data.StaffID); err != nil {
+				return nil, fmt.Errorf("service -> ResolveReassignment: caretaker not found: %w", err)
+			}
+		case "mservice":
+			if _, err := s.DoctorRepo.FindByID(*data.StaffID); err != nil {
+				return nil, fmt.Errorf("service -> ResolveReassignment: doctor not found: %w", err)
+			}
+		}
+		if err := s.checkWorkingHours(*data.StaffID, booking.ReserveDateStart, booking.ReserveDateEnd); err != nil {
+			return nil, fmt.Errorf("service -> ResolveReassignment: %w", err)
+		}
+	}
+
+	now := time.Now()
+	tx := s.UnitOfWork.Begin()
+	if booking == nil {
+		s.ReassignmentRepo.CloseTx(tx, reassignmentID, db.ReassignmentStatusDismissed, nil, adminID, now, data.Note)
+	} else {
+		s.moveBookingTx(tx, booking, *data.StaffID)
+		s.ReassignmentRepo.CloseTx(tx, reassignmentID, db.ReassignmentStatusResolved, data.StaffID, adminID, now, data.Note)
+	}
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("service -> ResolveReassignment: %w", err)
+	}
+
+	return s.ReassignmentRepo.FindByID(reassignmentID)
+}
+
+func (s *ServiceService) FindReassignments(serviceID string) ([]*entities.ServiceReassignmentModel, error) {
+	return s.ReassignmentRepo.FindByServiceID(serviceID)
+}
+
+func (s *ServiceService) FindReassignmentsByStatus(status db.ReassignmentStatus) ([]*entities.ServiceReassignmentModel, error) {
+	return s.ReassignmentRepo.FindByStatus(status)
+}
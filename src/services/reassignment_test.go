@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/services/mocks"
+)
+
+func TestServiceService_ReassignStaffBookings(t *testing.T) {
+	start := clockOn(scheduleMonday, 10, 0)
+	booking := &entities.ServiceModel{
+		Sid: "s1", PaymentID: "p1", StaffID: "care-1", ServiceType: "cservice", Status: db.ServiceStatusWait,
+		ReserveDateStart: start, ReserveDateEnd: start.Add(2 * time.Hour),
+	}
+
+	t.Run("best rated free staff takes the booking", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockIServiceRepository(ctrl)
+		mockCaretaker := mocks.NewMockICaretakerRepository(ctrl)
+		mockCservice := mocks.NewMockICServiceRepository(ctrl)
+		mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+		mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+		mockReassign := mocks.NewMockIServiceReassignmentRepository(ctrl)
+		mockUow := mocks.NewMockIUnitOfWork(ctrl)
+		sv := &ServiceService{
+			Repo: mockRepo, CaretakerRepo: mockCaretaker, CserviceRepo: mockCservice, ScheduleRepo: mockSchedule,
+			StaffHoldRepo: mockHold, ReassignmentRepo: mockReassign, UnitOfWork: mockUow,
+		}
+
+		mockRepo.EXPECT().FindUpcomingByStaffID("care-1", scheduleMonday, nil).Return([]*entities.ServiceModel{booking}, nil)
+		mockCaretaker.EXPECT().FindAvailableCaretaker(booking.ReserveDateStart, booking.ReserveDateEnd).Return([]*entities.AvailableStaffResponse{
+			{ID: "care-low", Rating: decimal.NewFromInt(3)},
+			{ID: "care-top", Rating: decimal.NewFromInt(5)},
+			{ID: "care-mid", Rating: decimal.NewFromInt(4)},
+		}, nil)
+		mockSchedule.EXPECT().FindByStaffIDs(gomock.Any()).Return(nil, nil)
+		mockSchedule.EXPECT().FindExceptions(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		// care-top โดนจองตัดหน้าไปแล้ว ต้องไปลอง care-mid ต่อ
+		topTx, midTx := &repositories.Tx{}, &repositories.Tx{}
+		gomock.InOrder(
+			mockUow.EXPECT().Begin().Return(topTx),
+			mockUow.EXPECT().Begin().Return(midTx),
+		)
+		expectShifts(mockSchedule, "care-top", allDayShifts())
+		expectShifts(mockSchedule, "care-mid", allDayShifts())
+		mockHold.EXPECT().GuardSlotTx(topTx, "care-top", booking.ReserveDateStart, booking.ReserveDateEnd, "p1")
+		mockCservice.EXPECT().UpdateStaffTx(topTx, "s1", "care-top")
+		mockReassign.EXPECT().InsertTx(topTx, gomock.Any())
+		mockUow.EXPECT().Commit(topTx).Return(repositories.ErrStaffUnavailable)
+
+		mockHold.EXPECT().GuardSlotTx(midTx, "care-mid", booking.ReserveDateStart, booking.ReserveDateEnd, "p1")
+		mockCservice.EXPECT().UpdateStaffTx(midTx, "s1", "care-mid")
+		mockReassign.EXPECT().InsertTx(midTx, gomock.Any())
+		mockUow.EXPECT().Commit(midTx).Return(nil)
+
+		admin := "admin-1"
+		results, err := sv.ReassignStaffBookings("care-1", scheduleMonday, nil, db.ReassignmentReasonStaffUnavailable, true, &admin, nil)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(results) != 1 || results[0].Status != db.ReassignmentStatusReassigned || *results[0].ToStaffID != "care-mid" {
+			t.Fatalf("unexpected results %+v", results[0])
+		}
+	})
+
+	t.Run("staff off at that hour is skipped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockIServiceRepository(ctrl)
+		mockCaretaker := mocks.NewMockICaretakerRepository(ctrl)
+		mockCservice := mocks.NewMockICServiceRepository(ctrl)
+		mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+		mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+		mockReassign := mocks.NewMockIServiceReassignmentRepository(ctrl)
+		mockUow := mocks.NewMockIUnitOfWork(ctrl)
+		sv := &ServiceService{
+			Repo: mockRepo, CaretakerRepo: mockCaretaker, CserviceRepo: mockCservice, ScheduleRepo: mockSchedule,
+			StaffHoldRepo: mockHold, ReassignmentRepo: mockReassign, UnitOfWork: mockUow,
+		}
+
+		mockRepo.EXPECT().FindUpcomingByStaffID("care-1", scheduleMonday, nil).Return([]*entities.ServiceModel{booking}, nil)
+		mockCaretaker.EXPECT().FindAvailableCaretaker(booking.ReserveDateStart, booking.ReserveDateEnd).Return([]*entities.AvailableStaffResponse{
+			{ID: "care-top", Rating: decimal.NewFromInt(5)},
+			{ID: "care-mid", Rating: decimal.NewFromInt(4)},
+		}, nil)
+		mockSchedule.EXPECT().FindByStaffIDs(gomock.Any()).Return(nil, nil)
+		mockSchedule.EXPECT().FindExceptions(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		// care-top มีเวลาทำงานวันจันทร์แต่เริ่มบ่าย booking 10:00-12:00 เลยไม่ได้
+		expectShifts(mockSchedule, "care-top", []entities.StaffShiftModel{{Weekday: 1, StartTime: "13:00", EndTime: "20:00"}})
+		expectShifts(mockSchedule, "care-mid", splitShiftMonday())
+
+		tx := &repositories.Tx{}
+		mockUow.EXPECT().Begin().Return(tx)
+		mockHold.EXPECT().GuardSlotTx(tx, "care-mid", booking.ReserveDateStart, booking.ReserveDateEnd, "p1")
+		mockCservice.EXPECT().UpdateStaffTx(tx, "s1", "care-mid")
+		mockReassign.EXPECT().InsertTx(tx, gomock.Any())
+		mockUow.EXPECT().Commit(tx).Return(nil)
+
+		results, err := sv.ReassignStaffBookings("care-1", scheduleMonday, nil, db.ReassignmentReasonStaffUnavailable, true, nil, nil)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(results) != 1 || results[0].ToStaffID == nil || *results[0].ToStaffID != "care-mid" {
+			t.Fatalf("unexpected results %+v", results)
+		}
+	})
+
+	t.Run("nobody free flags the booking", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockIServiceRepository(ctrl)
+		mockCaretaker := mocks.NewMockICaretakerRepository(ctrl)
+		mockReassign := mocks.NewMockIServiceReassignmentRepository(ctrl)
+		mockUow := mocks.NewMockIUnitOfWork(ctrl)
+		sv := &ServiceService{Repo: mockRepo, CaretakerRepo: mockCaretaker, ReassignmentRepo: mockReassign, UnitOfWork: mockUow}
+
+		tx := &repositories.Tx{}
+		mockRepo.EXPECT().FindUpcomingByStaffID("care-1", scheduleMonday, nil).Return([]*entities.ServiceModel{booking}, nil)
+		mockCaretaker.EXPECT().FindAvailableCaretaker(booking.ReserveDateStart, booking.ReserveDateEnd).Return(nil, nil)
+		mockUow.EXPECT().Begin().Return(tx)
+		mockReassign.EXPECT().InsertTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, r entities.ServiceReassignmentModel) {
+			if r.Status != db.ReassignmentStatusNeedsAction || r.ToStaffID != nil || r.Reason != db.ReassignmentReasonStaffDeleted {
+				t.Fatalf("unexpected record %+v", r)
+			}
+		})
+		mockUow.EXPECT().Commit(tx).Return(nil)
+
+		results, err := sv.ReassignStaffBookings("care-1", scheduleMonday, nil, db.ReassignmentReasonStaffDeleted, true, nil, nil)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(results) != 1 || results[0].Status != db.ReassignmentStatusNeedsAction {
+			t.Fatalf("unexpected results %+v", results)
+		}
+	})
+}
+
+func TestServiceService_ResolveReassignment(t *testing.T) {
+	start := clockOn(scheduleMonday, 10, 0)
+
+	t.Run("booking of deleted staff gets a new caretaker", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockIServiceRepository(ctrl)
+		mockCaretaker := mocks.NewMockICaretakerRepository(ctrl)
+		mockCservice := mocks.NewMockICServiceRepository(ctrl)
+		mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+		mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+		mockReassign := mocks.NewMockIServiceReassignmentRepository(ctrl)
+		mockUow := mocks.NewMockIUnitOfWork(ctrl)
+		sv := &ServiceService{
+			Repo: mockRepo, CaretakerRepo: mockCaretaker, CserviceRepo: mockCservice, ScheduleRepo: mockSchedule,
+			StaffHoldRepo: mockHold, ReassignmentRepo: mockReassign, UnitOfWork: mockUow,
+		}
+
+		tx := &repositories.Tx{}
+		newStaff := "care-2"
+		mockReassign.EXPECT().FindByID("r1").Return(&entities.ServiceReassignmentModel{
+			ID: "r1", ServiceID: "s1", ServiceType: "cservice", FromStaffID: "care-1", Status: db.ReassignmentStatusNeedsAction,
+		}, nil)
+		// Cservice หายไปพร้อม staff แล้ว booking เลยไม่มี type/staff
+		mockRepo.EXPECT().FindByID("s1").Return(&entities.ServiceModel{
+			Sid: "s1", PaymentID: "p1", Status: db.ServiceStatusWait, ReserveDateStart: start, ReserveDateEnd: start.Add(time.Hour),
+		}, nil)
+		mockCaretaker.EXPECT().FindByID(newStaff).Return(&entities.UserDataModel{}, nil)
+		expectShifts(mockSchedule, newStaff, allDayShifts())
+		mockUow.EXPECT().Begin().Return(tx)
+		mockHold.EXPECT().GuardSlotTx(tx, newStaff, start, start.Add(time.Hour), "p1")
+		mockCservice.EXPECT().InsertTx(tx, entities.SubService{ServiceID: "s1", StaffID: newStaff})
+		mockReassign.EXPECT().CloseTx(tx, "r1", db.ReassignmentStatusResolved, &newStaff, "admin-1", gomock.Any(), nil)
+		mockUow.EXPECT().Commit(tx).Return(nil)
+		mockReassign.EXPECT().FindByID("r1").Return(&entities.ServiceReassignmentModel{Status: db.ReassignmentStatusResolved}, nil)
+
+		if _, err := sv.ResolveReassignment("r1", "admin-1", entities.ResolveReassignmentRequest{StaffID: &newStaff}); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("already closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockReassign := mocks.NewMockIServiceReassignmentRepository(ctrl)
+		sv := &ServiceService{ReassignmentRepo: mockReassign}
+
+		mockReassign.EXPECT().FindByID("r1").Return(&entities.ServiceReassignmentModel{Status: db.ReassignmentStatusReassigned}, nil)
+		if _, err := sv.ResolveReassignment("r1", "admin-1", entities.ResolveReassignmentRequest{}); !errors.Is(err, ErrReassignmentClosed) {
+			t.Fatalf("want ErrReassignmentClosed got %v", err)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrServiceNotReschedulable: ย้ายได้เฉพาะ booking ที่ยังไม่เริ่ม
+	ErrServiceNotReschedulable = errors.New("only waiting services can be rescheduled")
+	// ErrReschedulePending: มี reschedule ค้างอยู่ (รอจ่ายส่วนต่าง หรือคืนเงินแล้วแต่ยังย้ายไม่สำเร็จ)
+	ErrReschedulePending = errors.New("service already has a reschedule in progress")
+)
+
+// PlanReschedule checks that the booking can move to the new window, using the
+// same rules (bookings, holds, leave days, working hours) as a new booking. The booking's
+// own slot doesn't count as a conflict. Prices are filled in by PaymentService.
+//
+// A pending refund reschedule to the same window (Stripe or the commit failed last time)
+// is returned as saved, prices included, so the retry refunds with the same idempotency
+// key and amount instead of making a second refund.
+func (s *ServiceService) PlanReschedule(service *entities.ServiceModel, actorID string, data entities.RescheduleServiceRequest) (*entities.ServiceRescheduleModel, error) {
+	if service.Status != db.ServiceStatusWait {
+		return nil, fmt.Errorf("service -> PlanReschedule: %w (status %s)", ErrServiceNotReschedulable, service.Status)
+	}
+
+	pending, err := s.RescheduleRepo.FindPending(service.Sid)
+	if err == nil {
+		if pending.AdjustmentPaymentID != nil || !pending.NewStart.Equal(data.ReserveDateStart) || !pending.NewEnd.Equal(data.ReserveDateEnd) {
+			return nil, fmt.Errorf("service -> PlanReschedule: %w", ErrReschedulePending)
+		}
+		pending.PaymentID = service.PaymentID
+		pending.OwnerID = service.OwnerID
+		pending.StaffID = service.StaffID
+		return pending, nil
+	}
+	if !errors.Is(err, db.ErrNotFound) {
+		return nil, fmt.Errorf("service -> PlanReschedule: %w", err)
+	}
+
+	// เช็คก่อนคิดราคาเฉยๆ ตัวกันจองซ้อนจริงคือ GuardSlotTx ตอน HoldSlot/Apply/Complete
+	taken, err := s.StaffHoldRepo.HasConflict(service.StaffID, data.ReserveDateStart, data.ReserveDateEnd, service.PaymentID)
+	if err != nil {
+		return nil, fmt.Errorf("service -> PlanReschedule: %w", err)
+	}
+	if taken {
+		return nil, fmt.Errorf("service -> PlanReschedule: %w", ErrStaffUnavailable)
+	}
+	if err := s.checkWorkingHours(service.StaffID, data.ReserveDateStart, data.ReserveDateEnd); err != nil {
+		return nil, fmt.Errorf("service -> PlanReschedule: %w", err)
+	}
+
+	return &entities.ServiceRescheduleModel{
+		ID:          uuid.NewString(),
+		ServiceID:   service.Sid,
+		RequestedBy: actorID,
+		OldStart:    service.ReserveDateStart,
+		OldEnd:      service.ReserveDateEnd,
+		NewStart:    data.ReserveDateStart,
+		NewEnd:      data.ReserveDateEnd,
+		Status:      db.RescheduleStatusPending,
+		PaymentID:   service.PaymentID,
+		OwnerID:     service.OwnerID,
+		StaffID:     service.StaffID,
+	}, nil
+}
+
+// ApplyReschedule moves a booking whose new price is not higher. Any refund must
+// already be made on Stripe; it is written here together with the new window. A
+// price-down reschedule finishes the pending row saved by RequestReschedule before
+// the refund, the others are inserted as applied.
+func (s *ServiceService) ApplyReschedule(reschedule *entities.ServiceRescheduleModel) (*entities.ServiceModel, error) {
+	tx := s.UnitOfWork.Begin()
+	s.StaffHoldRepo.GuardSlotTx(tx, reschedule.StaffID, reschedule.NewStart, reschedule.NewEnd, reschedule.PaymentID)
+	s.Repo.UpdateReserveDateTx(tx, reschedule.ServiceID, reschedule.NewStart, reschedule.NewEnd)
+	if reschedule.PriceDelta < 0 {
+		s.RescheduleRepo.CompleteTx(tx, *reschedule)
+	} else {
+		reschedule.Status = db.RescheduleStatusApplied
+		s.RescheduleRepo.InsertTx(tx, *reschedule)
+	}
+	if err := s.applyRefundsTx(tx, reschedule.OwnerID, reschedule.Refunds); err != nil {
+		return nil, fmt.Errorf("service -> ApplyReschedule: %w", err)
+	}
+	s.StaffHoldRepo.DeleteByPaymentIDTx(tx, reschedule.PaymentID)
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("service -> ApplyReschedule: %w", err)
+	}
+	reschedule.Status = db.RescheduleStatusApplied
+
+	return s.FindServiceByID(reschedule.ServiceID)
+}
+
+// RequestReschedule stores a reschedule that waits for its adjustment payment, or for
+// its refund to go through on Stripe. The row's ID is the refund's idempotency key.
+func (s *ServiceService) RequestReschedule(reschedule *entities.ServiceRescheduleModel) error {
+	reschedule.Status = db.RescheduleStatusPending
+
+	tx := s.UnitOfWork.Begin()
+	s.RescheduleRepo.InsertTx(tx, *reschedule)
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return fmt.Errorf("service -> RequestReschedule: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteReschedule applies a pending reschedule once its adjustment is paid. If the
+// booking can't move any more the caller should refund the adjustment.
+func (s *ServiceService) CompleteReschedule(rescheduleID string, settlement entities.StripePaymentUpdate) (*entities.ServiceModel, error) {
+	reschedule, err := s.RescheduleRepo.FindByID(rescheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("service -> CompleteReschedule: %w", err)
+	}
+	if reschedule.Status != db.RescheduleStatusPending || reschedule.AdjustmentPaymentID == nil {
+		return nil, fmt.Errorf("service -> CompleteReschedule: %w (reschedule %s)", ErrServiceNotReschedulable, reschedule.Status)
+	}
+
+	service, err := s.Repo.FindByID(reschedule.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("service -> CompleteReschedule: %w", err)
+	}
+	if service.Status != db.ServiceStatusWait {
+		return nil, fmt.Errorf("service -> CompleteReschedule: %w (status %s)", ErrServiceNotReschedulable, service.Status)
+	}
+
+	tx := s.UnitOfWork.Begin()
+	s.StaffHoldRepo.GuardSlotTx(tx, service.StaffID, reschedule.NewStart, reschedule.NewEnd, service.PaymentID)
+	s.Repo.UpdateReserveDateTx(tx, service.Sid, reschedule.NewStart, reschedule.NewEnd)
+	s.RescheduleRepo.UpdateStatusTx(tx, reschedule.ID, db.RescheduleStatusPending, db.RescheduleStatusApplied)
+	err = s.PaymentRepo.UpdateByIDTx(tx, *reschedule.AdjustmentPaymentID, entities.PaymentModel{
+		Status:              db.PaymentStatusPaid,
+		Type:                settlement.Type,
+		PayDate:             settlement.PayDate,
+		StripePaymentIntent: settlement.PaymentIntent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service -> CompleteReschedule: %w", err)
+	}
+	s.OwnerRepo.AddTotalSpendingTx(tx, service.OwnerID, decimal.NewFromInt(int64(reschedule.PriceDelta)))
+	s.StaffHoldRepo.DeleteByPaymentIDTx(tx, *reschedule.AdjustmentPaymentID)
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("service -> CompleteReschedule: %w", err)
+	}
+
+	return s.FindServiceByID(service.Sid)
+}
+
+// AbandonReschedule cancels a pending reschedule whose checkout closed (or whose
+// refund Stripe turned down) and frees the slot held for it. The hold is the one of
+// its adjustment payment, so a late event of an old reschedule can't free the slot of
+// a newer one.
+func (s *ServiceService) AbandonReschedule(rescheduleID string) error {
+	reschedule, err := s.RescheduleRepo.FindByID(rescheduleID)
+	if err != nil {
+		return fmt.Errorf("service -> AbandonReschedule: %w", err)
+	}
+
+	tx := s.UnitOfWork.Begin()
+	s.RescheduleRepo.UpdateStatusTx(tx, reschedule.ID, db.RescheduleStatusPending, db.RescheduleStatusCancelled)
+	if reschedule.AdjustmentPaymentID != nil {
+		s.StaffHoldRepo.DeleteByPaymentIDTx(tx, *reschedule.AdjustmentPaymentID)
+	}
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return fmt.Errorf("service -> AbandonReschedule: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/services/mocks"
+)
+
+func TestServiceService_PlanReschedule(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	req := entities.RescheduleServiceRequest{ReserveDateStart: start.Add(24 * time.Hour), ReserveDateEnd: start.Add(26 * time.Hour)}
+	adjustmentID := "adj-1"
+	savedAt := start.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		status  db.ServiceStatus
+		pending *entities.ServiceRescheduleModel
+		taken   bool
+		shifts  []entities.StaffShiftModel
+		wantErr error
+	}{
+		{name: "free slot", status: db.ServiceStatusWait},
+		{name: "staff is off that day", status: db.ServiceStatusWait, shifts: splitShiftMonday(), wantErr: ErrStaffUnavailable},
+		{name: "already started", status: db.ServiceStatusOngoing, wantErr: ErrServiceNotReschedulable},
+		{
+			name: "reschedule waiting for payment", status: db.ServiceStatusWait,
+			pending: &entities.ServiceRescheduleModel{ID: "r-adj", NewStart: req.ReserveDateStart, NewEnd: req.ReserveDateEnd, PriceDelta: 100, AdjustmentPaymentID: &adjustmentID, CreatedAt: savedAt},
+			wantErr: ErrReschedulePending,
+		},
+		{
+			name: "unfinished refund to another range", status: db.ServiceStatusWait,
+			pending: &entities.ServiceRescheduleModel{ID: "r-refund", NewStart: req.ReserveDateStart.Add(time.Hour), NewEnd: req.ReserveDateEnd.Add(time.Hour), PriceDelta: -50, CreatedAt: savedAt},
+			wantErr: ErrReschedulePending,
+		},
+		{
+			name: "retry of an unfinished refund resumes the saved row", status: db.ServiceStatusWait,
+			pending: &entities.ServiceRescheduleModel{ID: "r-refund", NewStart: req.ReserveDateStart, NewEnd: req.ReserveDateEnd, OldPrice: 200, NewPrice: 150, PriceDelta: -50, CreatedAt: savedAt},
+		},
+		{name: "staff busy", status: db.ServiceStatusWait, taken: true, wantErr: ErrStaffUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+			mockReschedule := mocks.NewMockIServiceRescheduleRepository(ctrl)
+			mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+			sv := &ServiceService{StaffHoldRepo: mockHold, RescheduleRepo: mockReschedule, ScheduleRepo: mockSchedule}
+
+			existing := &entities.ServiceModel{
+				Sid: "s1", OwnerID: "owner-1", PaymentID: "pay-1", StaffID: "staff-1", Status: tt.status,
+				ReserveDateStart: start, ReserveDateEnd: start.Add(2 * time.Hour),
+			}
+			if tt.status == db.ServiceStatusWait {
+				if tt.pending != nil {
+					mockReschedule.EXPECT().FindPending("s1").Return(tt.pending, nil)
+				} else {
+					mockReschedule.EXPECT().FindPending("s1").Return(nil, db.ErrNotFound)
+				}
+			}
+			if tt.status == db.ServiceStatusWait && tt.pending == nil {
+				// the booking's own payment is excluded so moving inside its own slot is fine
+				mockHold.EXPECT().HasConflict("staff-1", req.ReserveDateStart, req.ReserveDateEnd, "pay-1").Return(tt.taken, nil)
+			}
+			if tt.status == db.ServiceStatusWait && tt.pending == nil && !tt.taken {
+				shifts := tt.shifts
+				if shifts == nil {
+					shifts = allDayShifts()
+				}
+				expectShifts(mockSchedule, "staff-1", shifts)
+			}
+
+			got, err := sv.PlanReschedule(existing, "owner-1", req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want %v got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if tt.pending != nil {
+				// แถวเดิมทั้ง id และราคา key คืนเงินกับยอดจะได้เหมือนรอบก่อน
+				if got.ID != tt.pending.ID || got.PriceDelta != tt.pending.PriceDelta || got.CreatedAt.IsZero() || got.PaymentID != "pay-1" || got.StaffID != "staff-1" {
+					t.Fatalf("saved reschedule not resumed: %+v", got)
+				}
+				return
+			}
+			if got.ID == "" || !got.OldStart.Equal(start) || !got.NewStart.Equal(req.ReserveDateStart) || got.PaymentID != "pay-1" || !got.CreatedAt.IsZero() {
+				t.Fatalf("unexpected plan: %+v", got)
+			}
+		})
+	}
+}
+
+func TestServiceService_ApplyReschedule_RetryAfterRefund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIServiceRepository(ctrl)
+	mockPayment := mocks.NewMockIPaymentRepository(ctrl)
+	mockOwner := mocks.NewMockIOwnerRepository(ctrl)
+	mockUser := mocks.NewMockIUsersRepository(ctrl)
+	mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+	mockReschedule := mocks.NewMockIServiceRescheduleRepository(ctrl)
+	mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+	mockUow := mocks.NewMockIUnitOfWork(ctrl)
+	sv := &ServiceService{
+		Repo: mockRepo, PaymentRepo: mockPayment, OwnerRepo: mockOwner, UserRepo: mockUser,
+		StaffHoldRepo: mockHold, RescheduleRepo: mockReschedule, ScheduleRepo: mockSchedule, UnitOfWork: mockUow,
+	}
+
+	start := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	newStart, newEnd := start.Add(48*time.Hour), start.Add(49*time.Hour)
+	existing := &entities.ServiceModel{
+		Sid: "s1", OwnerID: "owner-1", PaymentID: "pay-1", StaffID: "staff-1", Status: db.ServiceStatusWait,
+		ReserveDateStart: start, ReserveDateEnd: start.Add(2 * time.Hour),
+	}
+	req := entities.RescheduleServiceRequest{ReserveDateStart: newStart, ReserveDateEnd: newEnd}
+	refunds := []entities.PaymentRefundModel{{PaymentID: "pay-1", Amount: 100, StripeRefundID: "re_1", RefundedAmount: 100}}
+
+	expectApply := func(rescheduleID string, commitErr error) {
+		tx := &repositories.Tx{}
+		mockUow.EXPECT().Begin().Return(tx)
+		mockHold.EXPECT().GuardSlotTx(tx, "staff-1", newStart, newEnd, "pay-1")
+		mockRepo.EXPECT().UpdateReserveDateTx(tx, "s1", newStart, newEnd)
+		// ปิดแถวที่บันทึกไว้ ไม่ insert แถวใหม่
+		mockReschedule.EXPECT().CompleteTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, data entities.ServiceRescheduleModel) {
+			if data.ID != rescheduleID || len(data.Refunds) != 1 || data.Refunds[0].StripeRefundID != "re_1" {
+				t.Fatalf("unexpected completed reschedule: %+v", data)
+			}
+		})
+		mockPayment.EXPECT().UpdateByIDTx(tx, "pay-1", entities.PaymentModel{RefundedAmount: 100}).Return(nil)
+		mockOwner.EXPECT().AddTotalSpendingTx(tx, "owner-1", decimal.NewFromInt(-100))
+		mockHold.EXPECT().DeleteByPaymentIDTx(tx, "pay-1")
+		mockUow.EXPECT().Commit(tx).Return(commitErr)
+	}
+
+	// รอบแรก: แผนใหม่ บันทึกแถว pending ก่อนเรียก stripe
+	mockReschedule.EXPECT().FindPending("s1").Return(nil, db.ErrNotFound)
+	mockHold.EXPECT().HasConflict("staff-1", newStart, newEnd, "pay-1").Return(false, nil)
+	expectShifts(mockSchedule, "staff-1", allDayShifts())
+	first, err := sv.PlanReschedule(existing, "owner-1", req)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	first.OldPrice, first.NewPrice, first.PriceDelta = 200, 100, -100
+
+	var saved entities.ServiceRescheduleModel
+	insertTx := &repositories.Tx{}
+	mockUow.EXPECT().Begin().Return(insertTx)
+	mockReschedule.EXPECT().InsertTx(insertTx, gomock.Any()).Do(func(_ *repositories.Tx, data entities.ServiceRescheduleModel) {
+		saved = data
+	})
+	mockUow.EXPECT().Commit(insertTx).Return(nil)
+	if err := sv.RequestReschedule(first); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if saved.Status != db.RescheduleStatusPending || saved.NewPrice != 100 {
+		t.Fatalf("refund not saved as pending: %+v", saved)
+	}
+
+	// stripe คืนเงินแล้ว แต่ย้าย booking ล้ม
+	first.Refunds = refunds
+	expectApply(first.ID, errors.New("connection reset"))
+	if _, err := sv.ApplyReschedule(first); err == nil {
+		t.Fatalf("expected the first apply to fail")
+	}
+	if first.Status != db.RescheduleStatusPending {
+		t.Fatalf("failed apply must leave the reschedule pending, got %s", first.Status)
+	}
+
+	// ลองใหม่ช่วงเดิม ต้องได้แถวเดิม key คืนเงิน ("reschedule-"+id) กับยอดเลยเหมือนเดิม stripe ไม่คืนซ้ำ
+	// แถวที่อ่านกลับจาก db มี created_at และ price_delta จาก mapper
+	saved.CreatedAt = start
+	saved.PriceDelta = saved.NewPrice - saved.OldPrice
+	mockReschedule.EXPECT().FindPending("s1").Return(&saved, nil)
+	retry, err := sv.PlanReschedule(existing, "owner-1", req)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if retry.ID != first.ID || retry.PriceDelta != first.PriceDelta || retry.CreatedAt.IsZero() {
+		t.Fatalf("retry must reuse the saved refund: first %+v retry %+v", first, retry)
+	}
+
+	retry.Refunds = refunds
+	expectApply(first.ID, nil)
+	mockRepo.EXPECT().FindByID("s1").Return(&entities.ServiceModel{Sid: "s1", StaffID: "staff-1", ReserveDateStart: newStart}, nil)
+	mockUser.EXPECT().FindByID("staff-1").Return(&entities.UserDataModel{UserID: "staff-1"}, nil)
+	got, err := sv.ApplyReschedule(retry)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !got.ReserveDateStart.Equal(newStart) || retry.Status != db.RescheduleStatusApplied {
+		t.Fatalf("service not moved: %+v %s", got, retry.Status)
+	}
+}
+
+func TestServiceService_CompleteReschedule(t *testing.T) {
+	start := time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC)
+	adjustmentID := "adj-1"
+	method := "card"
+	intent := "pi_adj"
+	settlement := entities.StripePaymentUpdate{Status: db.PaymentStatusPaid, Type: &method, PaymentIntent: &intent}
+
+	tests := []struct {
+		name      string
+		status    db.RescheduleStatus
+		commitErr error
+		wantErr   error
+	}{
+		{name: "paid adjustment moves the booking", status: db.RescheduleStatusPending},
+		{name: "slot taken after the hold expired", status: db.RescheduleStatusPending, commitErr: ErrStaffUnavailable, wantErr: ErrStaffUnavailable},
+		{name: "abandoned reschedule", status: db.RescheduleStatusCancelled, wantErr: ErrServiceNotReschedulable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIServiceRepository(ctrl)
+			mockPayment := mocks.NewMockIPaymentRepository(ctrl)
+			mockOwner := mocks.NewMockIOwnerRepository(ctrl)
+			mockUser := mocks.NewMockIUsersRepository(ctrl)
+			mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+			mockReschedule := mocks.NewMockIServiceRescheduleRepository(ctrl)
+			mockUow := mocks.NewMockIUnitOfWork(ctrl)
+			sv := &ServiceService{
+				Repo: mockRepo, PaymentRepo: mockPayment, OwnerRepo: mockOwner, UserRepo: mockUser,
+				StaffHoldRepo: mockHold, RescheduleRepo: mockReschedule, UnitOfWork: mockUow,
+			}
+
+			mockReschedule.EXPECT().FindByID("r1").Return(&entities.ServiceRescheduleModel{
+				ID: "r1", ServiceID: "s1", NewStart: start, NewEnd: start.Add(3 * time.Hour),
+				OldPrice: 200, NewPrice: 300, PriceDelta: 100, Status: tt.status, AdjustmentPaymentID: &adjustmentID,
+			}, nil)
+			if tt.status == db.RescheduleStatusPending {
+				tx := &repositories.Tx{}
+				mockRepo.EXPECT().FindByID("s1").Return(&entities.ServiceModel{
+					Sid: "s1", OwnerID: "owner-1", PaymentID: "pay-1", StaffID: "staff-1", Status: db.ServiceStatusWait,
+				}, nil)
+				mockUow.EXPECT().Begin().Return(tx)
+				mockHold.EXPECT().GuardSlotTx(tx, "staff-1", start, start.Add(3*time.Hour), "pay-1")
+				mockRepo.EXPECT().UpdateReserveDateTx(tx, "s1", start, start.Add(3*time.Hour))
+				mockReschedule.EXPECT().UpdateStatusTx(tx, "r1", db.RescheduleStatusPending, db.RescheduleStatusApplied)
+				mockPayment.EXPECT().UpdateByIDTx(tx, "adj-1", entities.PaymentModel{
+					Status: db.PaymentStatusPaid, Type: &method, StripePaymentIntent: &intent,
+				}).Return(nil)
+				mockOwner.EXPECT().AddTotalSpendingTx(tx, "owner-1", decimal.NewFromInt(100))
+				mockHold.EXPECT().DeleteByPaymentIDTx(tx, "adj-1")
+				mockUow.EXPECT().Commit(tx).Return(tt.commitErr)
+				if tt.commitErr == nil {
+					mockRepo.EXPECT().FindByID("s1").Return(&entities.ServiceModel{Sid: "s1", StaffID: "staff-1", ReserveDateStart: start}, nil)
+					mockUser.EXPECT().FindByID("staff-1").Return(&entities.UserDataModel{UserID: "staff-1"}, nil)
+				}
+			}
+
+			got, err := sv.CompleteReschedule("r1", settlement)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want %v got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if !got.ReserveDateStart.Equal(start) {
+				t.Fatalf("service not moved: %+v", got)
+			}
+		})
+	}
+}
+
+func TestServiceService_AbandonReschedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+	mockReschedule := mocks.NewMockIServiceRescheduleRepository(ctrl)
+	mockUow := mocks.NewMockIUnitOfWork(ctrl)
+	sv := &ServiceService{StaffHoldRepo: mockHold, RescheduleRepo: mockReschedule, UnitOfWork: mockUow}
+
+	// reschedule เก่าที่จ่ายช้า ต้องปล่อยแค่ hold ของ payment ส่วนต่างตัวเอง ไม่ใช่ของ booking
+	adjustmentID := "adj-old"
+	tx := &repositories.Tx{}
+	mockReschedule.EXPECT().FindByID("r-old").Return(&entities.ServiceRescheduleModel{
+		ID: "r-old", ServiceID: "s1", PaymentID: "pay-1", AdjustmentPaymentID: &adjustmentID,
+	}, nil)
+	mockUow.EXPECT().Begin().Return(tx)
+	mockReschedule.EXPECT().UpdateStatusTx(tx, "r-old", db.RescheduleStatusPending, db.RescheduleStatusCancelled)
+	mockHold.EXPECT().DeleteByPaymentIDTx(tx, "adj-old")
+	mockUow.EXPECT().Commit(tx).Return(nil)
+
+	if err := sv.AbandonReschedule("r-old"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestPaymentService_PriceReschedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIPaymentRepository(ctrl)
+	mockPricing := mocks.NewMockIPricingRepository(ctrl)
+	mockPet := mocks.NewMockIPetRepository(ctrl)
+	sv := &PaymentService{repo: mockRepo, pricingRepo: mockPricing, petRepo: mockPet}
+
+	existing := &entities.ServiceModel{Sid: "s1", OwnerID: "owner-1", PetID: "pet-1", PaymentID: "pay-1", StaffID: "staff-1", ServiceType: "mservice"}
+	reschedule := &entities.ServiceRescheduleModel{NewStart: ictTime(t, "2025-04-09 10:00"), NewEnd: ictTime(t, "2025-04-09 11:00")}
+
+	// paid 300, got 50 back from an earlier reschedule: 250 on the booking
+	mockRepo.EXPECT().FindByID("pay-1").Return(&entities.PaymentModel{
+		PayID: "pay-1", Price: 300, RefundedAmount: 50, Status: db.PaymentStatusPaid,
+		Adjustments: []*entities.PaymentModel{{PayID: "adj-old", Price: 80, Status: db.PaymentStatusExpired}},
+	}, nil)
+	mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1", OwnerID: "owner-1", Kind: "cat", Weight: decimal.NewFromInt(4)}, nil)
+	mockPricing.EXPECT().FindRules("mservice", "staff-1", reschedule.NewStart, reschedule.NewEnd).Return(&entities.PricingRules{}, nil)
+
+	if err := sv.PriceReschedule(existing, reschedule); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if reschedule.OldPrice != 250 || reschedule.NewPrice != 100 || reschedule.PriceDelta != -150 {
+		t.Fatalf("unexpected prices: old %d new %d delta %d", reschedule.OldPrice, reschedule.NewPrice, reschedule.PriceDelta)
+	}
+}
+
+func TestPaymentService_RefundPayment_MoreThanPaid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIPaymentRepository(ctrl)
+	sv := &PaymentService{repo: mockRepo}
+
+	mockRepo.EXPECT().FindByID("pay-1").Return(&entities.PaymentModel{PayID: "pay-1", Price: 300, RefundedAmount: 100, Status: db.PaymentStatusPaid}, nil)
+
+	// ต้องล้มก่อนเรียก stripe
+	if _, err := sv.RefundPayment("pay-1", 250, "key"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/middlewares"
+	"lama-backend/src/services/mocks"
+)
+
+func TestAuthService_AddRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   []db.Role
+		hasRow  bool
+		added   bool
+		wantErr error
+	}{
+		{name: "owner becomes a caretaker", roles: []db.Role{db.RoleOwner}, added: true},
+		{name: "already has the role", roles: []db.Role{db.RoleOwner, db.RoleCaretaker}, wantErr: ErrRoleAlreadyGranted},
+		// รอบก่อนสร้างแถว Caretaker ได้แต่ใส่ role ไม่ทัน
+		{name: "retry keeps the existing caretaker row", roles: []db.Role{db.RoleOwner}, hasRow: true, added: true},
+		{name: "another request added it first", roles: []db.Role{db.RoleOwner}, added: false, wantErr: ErrRoleAlreadyGranted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsers := mocks.NewMockIUsersRepository(ctrl)
+			mockCaretaker := mocks.NewMockICaretakerRepository(ctrl)
+			sv := &authService{UsersRepository: mockUsers, CaretakerRepository: mockCaretaker}
+
+			mockUsers.EXPECT().FindByID("user-1").Return(&entities.UserDataModel{UserID: "user-1", Roles: tt.roles}, nil)
+			if !slices.Contains(tt.roles, db.RoleCaretaker) {
+				if tt.hasRow {
+					mockCaretaker.EXPECT().FindByID("user-1").Return(&entities.UserDataModel{UserID: "user-1"}, nil)
+				} else {
+					mockCaretaker.EXPECT().FindByID("user-1").Return(nil, errors.New("users -> FindByID: not found"))
+					mockCaretaker.EXPECT().InsertCaretaker("user-1", "grooming").Return(&entities.UserDataModel{UserID: "user-1", Specialization: "grooming"}, nil)
+				}
+				mockUsers.EXPECT().AddRole("user-1", db.RoleCaretaker).Return(tt.added, nil)
+			}
+			if tt.wantErr == nil {
+				mockUsers.EXPECT().FindByID("user-1").Return(&entities.UserDataModel{UserID: "user-1", Roles: append(tt.roles, db.RoleCaretaker)}, nil)
+			}
+
+			user, err := sv.AddRole("user-1", "caretaker", entities.AddRoleRequest{Specialization: "grooming"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want %v got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && len(user.Roles) != len(tt.roles)+1 {
+				t.Fatalf("unexpected roles %v", user.Roles)
+			}
+		})
+	}
+}
+
+func TestAuthService_SwitchRole(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", "test")
+
+	current := &middlewares.TokenDetails{UserID: "user-1", Role: "owner", Purpose: "access", SessionID: "sess-1"}
+
+	t.Run("opens a session for the new role and closes the old one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUsers := mocks.NewMockIUsersRepository(ctrl)
+		mockSession := mocks.NewMockISessionRepository(ctrl)
+		mockMfa := mocks.NewMockIMfaRepository(ctrl)
+		sv := &authService{UsersRepository: mockUsers, SessionRepository: mockSession, MfaRepository: mockMfa, RefreshTTL: time.Hour}
+
+		mockUsers.EXPECT().FindByID("user-1").Return(&entities.UserDataModel{UserID: "user-1", Roles: []db.Role{db.RoleOwner, db.RoleCaretaker}}, nil)
+		mockMfa.EXPECT().FindRequirements().Return(nil, nil)
+		gomock.InOrder(
+			mockSession.EXPECT().FindByID("sess-1").Return(&entities.SessionModel{ID: "sess-1", UserID: "user-1", Role: db.RoleOwner}, nil),
+			mockSession.EXPECT().Insert(gomock.Any()).DoAndReturn(func(data entities.SessionModel) (*entities.SessionModel, error) {
+				if data.Role != db.RoleCaretaker {
+					t.Fatalf("want caretaker session got %s", data.Role)
+				}
+				created := data
+				created.ID = "sess-2"
+				return &created, nil
+			}),
+			mockSession.EXPECT().Revoke("sess-1").Return(nil),
+		)
+
+		td, err := sv.SwitchRole(current, "caretaker", "curl/8.0")
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if td.Role != "caretaker" || td.SessionID != "sess-2" || td.RefreshToken == nil {
+			t.Fatalf("unexpected token %+v", td)
+		}
+	})
+
+	t.Run("role the account does not have", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUsers := mocks.NewMockIUsersRepository(ctrl)
+		sv := &authService{UsersRepository: mockUsers}
+
+		mockUsers.EXPECT().FindByID("user-1").Return(&entities.UserDataModel{UserID: "user-1", Roles: []db.Role{db.RoleOwner}}, nil)
+
+		if _, err := sv.SwitchRole(current, "admin", "curl/8.0"); !errors.Is(err, ErrRoleNotGranted) {
+			t.Fatalf("want ErrRoleNotGranted got %v", err)
+		}
+	})
+}
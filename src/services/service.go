@@ -1,8 +1,10 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"time"
 
@@ -10,6 +12,9 @@ import (
 	"lama-backend/domain/prisma/db"
 	"lama-backend/domain/repositories"
 	"lama-backend/src/utils"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type ServiceService struct {
@@ -21,13 +26,33 @@ type ServiceService struct {
 	CserviceRepo  repositories.ICServiceRepository
 	PaymentRepo   repositories.IPaymentRepository
 	PetRepo       repositories.IPetRepository
+	OwnerRepo     repositories.IOwnerRepository
+	UnitOfWork    repositories.IUnitOfWork
+	StaffHoldRepo repositories.IStaffHoldRepository
+
+	CancellationRepo repositories.IServiceCancellationRepository
+	RescheduleRepo   repositories.IServiceRescheduleRepository
+	RefundPolicy     RefundPolicy
+
+	StatusHistoryRepo repositories.IServiceStatusHistoryRepository
+	ScheduleRepo      repositories.IStaffScheduleRepository
+	ReassignmentRepo  repositories.IServiceReassignmentRepository
 }
 
+// ErrStaffUnavailable: staff มี booking, hold หรือวันลาทับช่วงเวลาที่ขอ
+var ErrStaffUnavailable = repositories.ErrStaffUnavailable
+
+const (
+	// ต้องตรงกับอายุ stripe checkout session
+	checkoutHoldDuration = 60 * time.Minute
+	// เผื่อ webhook มาช้ากว่าเวลาหมดอายุของ session นิดหน่อย
+	checkoutHoldGrace = 10 * time.Minute
+)
+
 type IServiceService interface {
 	ValidateServiceCreation(data entities.CreateServiceRequest, payment_status string) error
-	CreateService(data entities.CreateServiceRequest) (*entities.ServiceModel, *entities.SubService, error)
-	UpdateServiceByID(serviceID string, data entities.UpdateServiceRequest) (*entities.ServiceModel, error)
-	DeleteServiceByID(serviceID string) (*entities.ServiceModel, error)
+	CreateService(data entities.CreateServiceRequest, settlement entities.StripePaymentUpdate) (*entities.ServiceModel, *entities.SubService, error)
+	UpdateServiceByID(serviceID string, actorID string, data entities.UpdateServiceRequest) (*entities.ServiceModel, error)
 	FindServiceByID(serviceID string) (*entities.ServiceModel, error)
 	FindServicesByOwnerID(ownerID string, status string, month, year, page int, limit int) ([]*entities.ServiceModel, int, error)
 	FindServicesByDoctorID(ownerID string, status string, month, year, page int, limit int) ([]*entities.ServiceModel, int, error)
@@ -37,6 +62,30 @@ type IServiceService interface {
 	FindAvailableStaff(serviceType string, startDate, endDate time.Time) ([]*entities.AvailableStaffResponse, error)
 	FindBusyTimeSlot(serviceType string, staffID string, startDate00, startDate23, endDate00, endDate23 time.Time) (map[string][]string, error)
 	GetScoreAndReviewByCaretakerID(caretakerID string) (float64, []*entities.SubService, error)
+	CancelByPaymentID(paymentID string, paymentStatus db.PaymentStatus) (*entities.ServiceModel, error)
+	CheckAvailability(data entities.CreateServiceRequest) (bool, error)
+	HoldSlot(data entities.CreateServiceRequest) (time.Time, error)
+	ReleaseHold(paymentID string) error
+	PurgeExpiredHoldsTx(tx *repositories.Tx, now time.Time)
+	PlanCancellation(service *entities.ServiceModel, actorID string, actorRole string, data entities.CancelServiceRequest) (*entities.ServiceCancellationModel, error)
+	CancelService(cancellation *entities.ServiceCancellationModel) (*entities.ServiceModel, error)
+	CancellationPending(paymentID string) (bool, error)
+	PlanReschedule(service *entities.ServiceModel, actorID string, data entities.RescheduleServiceRequest) (*entities.ServiceRescheduleModel, error)
+	ApplyReschedule(reschedule *entities.ServiceRescheduleModel) (*entities.ServiceModel, error)
+	RequestReschedule(reschedule *entities.ServiceRescheduleModel) error
+	CompleteReschedule(rescheduleID string, settlement entities.StripePaymentUpdate) (*entities.ServiceModel, error)
+	AbandonReschedule(rescheduleID string) error
+	FindStatusHistory(serviceID string) ([]*entities.ServiceStatusHistoryModel, error)
+	FindStaffSchedule(staffID string) (*entities.StaffScheduleModel, error)
+	UpdateStaffSchedule(staffID string, data entities.UpdateStaffScheduleRequest) (*entities.StaffScheduleModel, error)
+	FindFreeTimeSlot(staffID string, from, to, now time.Time) ([]*entities.StaffDaySlotsModel, error)
+	CreateScheduleException(staffID, actorID string, data entities.CreateScheduleExceptionRequest) (*entities.StaffScheduleExceptionModel, error)
+	FindScheduleExceptions(staffID string, from, to time.Time) ([]entities.StaffScheduleExceptionModel, error)
+	DeleteScheduleException(staffID, exceptionID string) error
+	ReassignStaffBookings(staffID string, from time.Time, to *time.Time, reason db.ReassignmentReason, autoAssign bool, actorID *string, note *string) ([]*entities.ServiceReassignmentModel, error)
+	ResolveReassignment(reassignmentID, adminID string, data entities.ResolveReassignmentRequest) (*entities.ServiceReassignmentModel, error)
+	FindReassignments(serviceID string) ([]*entities.ServiceReassignmentModel, error)
+	FindReassignmentsByStatus(status db.ReassignmentStatus) ([]*entities.ServiceReassignmentModel, error)
 }
 
 func NewServiceService(
@@ -48,6 +97,14 @@ func NewServiceService(
 	cserviceRepo repositories.ICServiceRepository,
 	paymentRepo repositories.IPaymentRepository,
 	petRepo repositories.IPetRepository,
+	ownerRepo repositories.IOwnerRepository,
+	unitOfWork repositories.IUnitOfWork,
+	staffHoldRepo repositories.IStaffHoldRepository,
+	cancellationRepo repositories.IServiceCancellationRepository,
+	rescheduleRepo repositories.IServiceRescheduleRepository,
+	statusHistoryRepo repositories.IServiceStatusHistoryRepository,
+	scheduleRepo repositories.IStaffScheduleRepository,
+	reassignmentRepo repositories.IServiceReassignmentRepository,
 ) IServiceService {
 	return &ServiceService{
 		Repo:          repo,
@@ -58,33 +115,24 @@ func NewServiceService(
 		CserviceRepo:  cserviceRepo,
 		PaymentRepo:   paymentRepo,
 		PetRepo:       petRepo,
+		OwnerRepo:     ownerRepo,
+		UnitOfWork:    unitOfWork,
+		StaffHoldRepo: staffHoldRepo,
+
+		CancellationRepo: cancellationRepo,
+		RescheduleRepo:   rescheduleRepo,
+		RefundPolicy:     loadRefundPolicy(),
+
+		StatusHistoryRepo: statusHistoryRepo,
+		ScheduleRepo:      scheduleRepo,
+		ReassignmentRepo:  reassignmentRepo,
 	}
 }
 
 func (s *ServiceService) ValidateServiceCreation(data entities.CreateServiceRequest, payment_status string) error {
-	// status exist
-	status := db.ServiceStatus(data.Status)
-	validStatuses := map[db.ServiceStatus]bool{
-		db.ServiceStatusWait:    true,
-		db.ServiceStatusOngoing: true,
-		db.ServiceStatusFinish:  true,
-	}
-	if !validStatuses[status] {
-		return fmt.Errorf("service -> CreateServiceStripe: invalid status %q", data.Status)
-	}
-
 	// staff exist
-	switch data.ServiceType {
-	case "cservice":
-		if _, err := s.CaretakerRepo.FindByID(data.StaffID); err != nil {
-			return fmt.Errorf("service -> CreateServiceStripe: caretaker not found: %w", err)
-		}
-	case "mservice":
-		if _, err := s.DoctorRepo.FindByID(data.StaffID); err != nil {
-			return fmt.Errorf("service -> CreateServiceStripe: doctor not found: %w", err)
-		}
-	default:
-		return fmt.Errorf("service -> CreateServiceStripe: invalid service_type %q", data.ServiceType)
+	if err := s.checkStaffExists(data.ServiceType, data.StaffID); err != nil {
+		return fmt.Errorf("service -> CreateServiceStripe: %w", err)
 	}
 
 	// payment exist
@@ -99,7 +147,8 @@ func (s *ServiceService) ValidateServiceCreation(data entities.CreateServiceRequ
 	// payment status correct
 	switch payment_status {
 	case "unpaid":
-		if payment.Status != db.PaymentStatusUnpaid {
+		// FAILED = ลองจ่ายแล้วไม่ผ่าน ยังจ่ายใหม่ใน session เดิมได้
+		if payment.Status != db.PaymentStatusUnpaid && payment.Status != db.PaymentStatusFailed {
 			return fmt.Errorf("service -> CreateServiceStripe: payment must be UnPaid before pay")
 		}
 	case "paid":
@@ -110,102 +159,225 @@ func (s *ServiceService) ValidateServiceCreation(data entities.CreateServiceRequ
 		return fmt.Errorf("service -> CreateServiceStripe: invalid payment status %q", payment_status)
 	}
 
+	// staff ว่างในช่วงนี้ (ไม่นับ hold ของ payment ตัวเอง)
+	taken, err := s.StaffHoldRepo.HasConflict(data.StaffID, data.ReserveDateStart, data.ReserveDateEnd, data.PaymentID)
+	if err != nil {
+		return fmt.Errorf("service -> CreateServiceStripe: %w", err)
+	}
+	if taken {
+		return fmt.Errorf("service -> CreateServiceStripe: %w", ErrStaffUnavailable)
+	}
+	if err := s.checkWorkingHours(data.StaffID, data.ReserveDateStart, data.ReserveDateEnd); err != nil {
+		return fmt.Errorf("service -> CreateServiceStripe: %w", err)
+	}
+
 	return nil
 }
 
-func (s *ServiceService) CreateService(data entities.CreateServiceRequest) (*entities.ServiceModel, *entities.SubService, error) {
-	if err := s.ValidateServiceCreation(data, "paid"); err != nil {
-		return nil, nil, err
+// CheckAvailability tells whether the staff is working and free for the booking right
+// now, counting other owners' checkout holds. Nothing is reserved.
+func (s *ServiceService) CheckAvailability(data entities.CreateServiceRequest) (bool, error) {
+	if err := s.checkStaffExists(data.ServiceType, data.StaffID); err != nil {
+		return false, fmt.Errorf("service -> CheckAvailability: %w", err)
 	}
 
-	var service *entities.ServiceModel
-	var subService *entities.SubService
-	var err error
-	switch data.ServiceType {
-	case "cservice":
-		// insert service
-		if service, err = s.Repo.Insert(data); err != nil {
-			return nil, nil, fmt.Errorf("service -> CreateService: failed to create service: %w", err)
+	taken, err := s.StaffHoldRepo.HasConflict(data.StaffID, data.ReserveDateStart, data.ReserveDateEnd, data.PaymentID)
+	if err != nil {
+		return false, fmt.Errorf("service -> CheckAvailability: %w", err)
+	}
+	if taken {
+		return false, nil
+	}
+	if err := s.checkWorkingHours(data.StaffID, data.ReserveDateStart, data.ReserveDateEnd); err != nil {
+		if errors.Is(err, ErrStaffUnavailable) {
+			return false, nil
 		}
+		return false, fmt.Errorf("service -> CheckAvailability: %w", err)
+	}
 
-		// insert subservice
-		if subService, err = s.CserviceRepo.Insert(*mapToSubService(*service)); err != nil {
-			return nil, nil, fmt.Errorf("service -> CreateService: failed to create cservice: %w", err)
+	return true, nil
+}
+
+func (s *ServiceService) checkStaffExists(serviceType, staffID string) error {
+	switch serviceType {
+	case "cservice":
+		if _, err := s.CaretakerRepo.FindByID(staffID); err != nil {
+			return fmt.Errorf("caretaker not found: %w", err)
 		}
 	case "mservice":
-		if service, err = s.Repo.Insert(data); err != nil {
-			return nil, nil, fmt.Errorf("service -> CreateService: failed to create service: %w", err)
+		if _, err := s.DoctorRepo.FindByID(staffID); err != nil {
+			return fmt.Errorf("doctor not found: %w", err)
 		}
+	default:
+		return fmt.Errorf("invalid service_type %q", serviceType)
+	}
 
-		if subService, err = s.MserviceRepo.Insert(*mapToSubService(*service)); err != nil {
-			return nil, nil, fmt.Errorf("service -> CreateService: failed to create mservice: %w", err)
-		}
+	return nil
+}
+
+// HoldSlot reserves the staff's time range for the payment while the owner is at
+// Stripe checkout. It returns when the checkout session should expire.
+func (s *ServiceService) HoldSlot(data entities.CreateServiceRequest) (time.Time, error) {
+	if err := s.checkWorkingHours(data.StaffID, data.ReserveDateStart, data.ReserveDateEnd); err != nil {
+		return time.Time{}, fmt.Errorf("service -> HoldSlot: %w", err)
+	}
+	expiresAt := time.Now().Add(checkoutHoldDuration)
+
+	tx := s.UnitOfWork.Begin()
+	s.StaffHoldRepo.GuardSlotTx(tx, data.StaffID, data.ReserveDateStart, data.ReserveDateEnd, data.PaymentID)
+	s.StaffHoldRepo.InsertTx(tx, entities.StaffHoldModel{
+		StaffID:          data.StaffID,
+		PaymentID:        data.PaymentID,
+		ReserveDateStart: data.ReserveDateStart,
+		ReserveDateEnd:   data.ReserveDateEnd,
+		ExpiresAt:        expiresAt.Add(checkoutHoldGrace),
+	})
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return time.Time{}, fmt.Errorf("service -> HoldSlot: %w", err)
+	}
+
+	return expiresAt, nil
+}
+
+func (s *ServiceService) ReleaseHold(paymentID string) error {
+	return s.StaffHoldRepo.DeleteByPaymentID(paymentID)
+}
+
+func (s *ServiceService) PurgeExpiredHoldsTx(tx *repositories.Tx, now time.Time) {
+	s.StaffHoldRepo.DeleteExpiredTx(tx, now)
+}
+
+// CreateService settles the payment and creates the booking with its cservice/mservice
+// row in one transaction, bumping the owner's total_spending along the way. If the
+// slot was taken in the meantime the transaction fails with ErrStaffUnavailable.
+func (s *ServiceService) CreateService(data entities.CreateServiceRequest, settlement entities.StripePaymentUpdate) (*entities.ServiceModel, *entities.SubService, error) {
+	if err := s.ValidateServiceCreation(data, "unpaid"); err != nil {
+		return nil, nil, err
+	}
+	payment, err := s.PaymentRepo.FindByID(data.PaymentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("service -> CreateService: %w", err)
+	}
+
+	serviceID := uuid.NewString()
+	subService := &entities.SubService{ServiceID: serviceID, StaffID: data.StaffID}
+
+	tx := s.UnitOfWork.Begin()
+	s.StaffHoldRepo.GuardSlotTx(tx, data.StaffID, data.ReserveDateStart, data.ReserveDateEnd, data.PaymentID)
+	err = s.PaymentRepo.UpdateByIDTx(tx, data.PaymentID, entities.PaymentModel{
+		Status:              db.PaymentStatusPaid,
+		Type:                settlement.Type,
+		PayDate:             settlement.PayDate,
+		StripePaymentIntent: settlement.PaymentIntent,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("service -> CreateService: %w", err)
+	}
+	s.Repo.InsertTx(tx, serviceID, data)
+	switch data.ServiceType {
+	case "cservice":
+		s.CserviceRepo.InsertTx(tx, *subService)
+	case "mservice":
+		s.MserviceRepo.InsertTx(tx, *subService)
 	default:
 		return nil, nil, fmt.Errorf("service -> CreateService: invalid service_type %q", data.ServiceType)
 	}
+	ownerRole := db.RoleOwner
+	s.StatusHistoryRepo.InsertTx(tx, entities.ServiceStatusHistoryModel{
+		ServiceID:     serviceID,
+		ToStatus:      db.ServiceStatusWait,
+		ChangedBy:     &data.OwnerID,
+		ChangedByRole: &ownerRole,
+	})
+	s.StaffHoldRepo.DeleteByPaymentIDTx(tx, data.PaymentID)
+	s.OwnerRepo.AddTotalSpendingTx(tx, data.OwnerID, decimal.NewFromInt(int64(payment.Price)))
+
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return nil, nil, fmt.Errorf("service -> CreateService: %w", err)
+	}
 
+	service, err := s.Repo.FindByID(serviceID)
+	if err != nil {
+		return nil, nil, err
+	}
 	if _, err = s.addStaffCommonData(service); err != nil {
 		return nil, nil, err
 	}
 	return service, subService, nil
 }
 
-func (s *ServiceService) UpdateServiceByID(serviceID string, data entities.UpdateServiceRequest) (*entities.ServiceModel, error) {
-	if data.Status != nil {
-		status := db.ServiceStatus(*data.Status)
-		switch status {
-		case db.ServiceStatusWait, db.ServiceStatusOngoing, db.ServiceStatusFinish:
-		default:
-			return nil, fmt.Errorf("service -> UpdateServiceByID: invalid status %q", *data.Status)
-		}
-	}
-
+func (s *ServiceService) UpdateServiceByID(serviceID string, actorID string, data entities.UpdateServiceRequest) (*entities.ServiceModel, error) {
 	currentService, err := s.Repo.FindByID(serviceID)
 	if err != nil {
 		return nil, err
 	}
+	if currentService.ServiceType != "cservice" && currentService.ServiceType != "mservice" {
+		return nil, fmt.Errorf("service -> UpdateServiceByID: invalid target service type")
+	}
 
-	var result *entities.ServiceModel
-	switch currentService.ServiceType {
-	case "cservice":
-		if data.StaffID != nil {
-			if _, err := s.CaretakerRepo.FindByID(*data.StaffID); err != nil {
-				return nil, fmt.Errorf("service -> UpdateServiceByID: caretaker not found: %w", err)
+	// เช็คให้ครบก่อน แล้วค่อยเขียนทุกอย่างใน tx เดียว ถ้าอะไรไม่ผ่านจะไม่มีอะไรเปลี่ยนเลย
+	// status ต้องผ่าน state machine เหมือน PATCH /services/:id/:status ไม่เขียนตรงๆ
+	var newStatus *db.ServiceStatus
+	if data.Status != nil {
+		status := db.ServiceStatus(*data.Status)
+		if status != currentService.Status {
+			if err := checkStatusTransition(currentService, status, "admin", time.Now()); err != nil {
+				return nil, fmt.Errorf("service -> UpdateServiceByID: %w", err)
 			}
+			newStatus = &status
+		}
+	}
+
+	// admin ย้ายเวลา/เปลี่ยน staff เองก็ต้องไม่ชนกับคิวอื่น (ราคาไม่คิดใหม่ owner ใช้ reschedule)
+	// กันด้วย GuardSlotTx ใน tx เดียวกับที่ย้าย เหมือน checkout จะได้ไม่จองซ้อนกันตอน commit พร้อมกัน
+	move := data.ReserveDateStart != nil || data.ReserveDateEnd != nil || data.StaffID != nil
+	moved, staffID := *currentService, currentService.StaffID
+	if move {
+		if data.StaffID != nil {
+			staffID = *data.StaffID
 		}
-		if result, err = s.Repo.UpdateByID(serviceID, data); err != nil {
-			return nil, fmt.Errorf("service -> CreateService: failed to update service: %w", err)
+		if data.ReserveDateStart != nil {
+			moved.ReserveDateStart = *data.ReserveDateStart
 		}
-		subResult, err := s.CserviceRepo.UpdateByID(*mapToSubService(*result))
-		if err != nil {
-			return nil, fmt.Errorf("service -> CreateService: failed to update cservice: %w", err)
+		if data.ReserveDateEnd != nil {
+			moved.ReserveDateEnd = *data.ReserveDateEnd
+		}
+		if !moved.ReserveDateStart.Before(moved.ReserveDateEnd) {
+			return nil, fmt.Errorf("service -> UpdateServiceByID: invalid reserve date range")
 		}
-		result.ServiceType = "cservice"
-		result.StaffID = subResult.StaffID
-	case "mservice":
 		if data.StaffID != nil {
-			if _, err := s.DoctorRepo.FindByID(*data.StaffID); err != nil {
-				return nil, fmt.Errorf("service -> UpdateServiceByID: doctor not found: %w", err)
+			if err := s.checkStaffExists(currentService.ServiceType, staffID); err != nil {
+				return nil, fmt.Errorf("service -> UpdateServiceByID: %w", err)
 			}
 		}
-		if result, err = s.Repo.UpdateByID(serviceID, data); err != nil {
-			return nil, fmt.Errorf("service -> CreateService: failed to update service: %w", err)
+		if err := s.checkWorkingHours(staffID, moved.ReserveDateStart, moved.ReserveDateEnd); err != nil {
+			return nil, fmt.Errorf("service -> UpdateServiceByID: %w", err)
 		}
-		subResult, err := s.MserviceRepo.UpdateByID(*mapToSubService(*result))
-		if err != nil {
-			return nil, fmt.Errorf("service -> CreateService: failed to update mservice: %w", err)
-		}
-		result.ServiceType = "mservice"
-		result.StaffID = subResult.StaffID
-	default:
-		return nil, fmt.Errorf("service -> UpdateServiceByID: invalid target service type")
 	}
 
-	return s.addStaffCommonData(result)
-}
+	tx := s.UnitOfWork.Begin()
+	if newStatus != nil {
+		role := db.RoleAdmin
+		s.changeStatusTx(tx, serviceID, currentService.Status, *newStatus, &actorID, &role, nil)
+	}
+	if move {
+		s.moveBookingTx(tx, &moved, staffID)
+		s.Repo.UpdateReserveDateTx(tx, serviceID, moved.ReserveDateStart, moved.ReserveDateEnd)
+	}
+	s.Repo.UpdateOwnerPetTx(tx, serviceID, data.OwnerID, data.PetID)
+	switch currentService.ServiceType {
+	case "cservice":
+		s.CserviceRepo.UpdateReviewTx(tx, serviceID, data.Comment, data.Score)
+	case "mservice":
+		if data.Disease != nil {
+			s.MserviceRepo.UpdateDiseaseTx(tx, serviceID, *data.Disease)
+		}
+	}
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("service -> UpdateServiceByID: %w", err)
+	}
 
-func (s *ServiceService) DeleteServiceByID(serviceID string) (*entities.ServiceModel, error) {
-	return s.Repo.DeleteByID(serviceID)
+	return s.FindServiceByID(serviceID)
 }
 
 func (s *ServiceService) FindServiceByID(serviceID string) (*entities.ServiceModel, error) {
@@ -282,7 +454,53 @@ func (s *ServiceService) UpdateStatus(serviceID, status, role, userID string) er
 	default:
 		return fmt.Errorf("service -> UpdateStatus: invalid role %q", role)
 	}
-	return s.Repo.UpdateStatus(serviceID, status)
+
+	to := db.ServiceStatus(status)
+	if err := checkStatusTransition(service, to, role, time.Now()); err != nil {
+		return fmt.Errorf("service -> UpdateStatus: %w", err)
+	}
+
+	actorRole := db.Role(role)
+	tx := s.UnitOfWork.Begin()
+	s.changeStatusTx(tx, serviceID, service.Status, to, &userID, &actorRole, nil)
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return fmt.Errorf("service -> UpdateStatus: %w", err)
+	}
+
+	return nil
+}
+
+// CancelByPaymentID cancels the booking paid by paymentID if it hasn't started yet.
+// It returns nil when the payment has no booking.
+func (s *ServiceService) CancelByPaymentID(paymentID string, paymentStatus db.PaymentStatus) (*entities.ServiceModel, error) {
+	service, err := s.Repo.FindByPaymentID(paymentID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if service.Status != db.ServiceStatusWait {
+		return service, nil
+	}
+	// owner/admin กำลังยกเลิกอยู่ ให้ CancelService เป็นคนเปลี่ยน status พร้อมบันทึกเงินคืน
+	pending, err := s.CancellationRepo.HasPendingByPaymentID(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("service -> CancelByPaymentID: %w", err)
+	}
+	if pending {
+		return service, nil
+	}
+
+	note := "payment " + strings.ToLower(string(paymentStatus)) + " on stripe"
+	tx := s.UnitOfWork.Begin()
+	s.changeStatusTx(tx, service.Sid, service.Status, db.ServiceStatusCancelled, nil, nil, &note)
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("service -> CancelByPaymentID: %w", err)
+	}
+	service.Status = db.ServiceStatusCancelled
+
+	return service, nil
 }
 
 func (s *ServiceService) FindAvailableStaff(serviceType string, startDate, endDate time.Time) ([]*entities.AvailableStaffResponse, error) {
@@ -302,7 +520,7 @@ func (s *ServiceService) FindAvailableStaff(serviceType string, startDate, endDa
 	default:
 		return nil, nil
 	}
-	return staff, nil
+	return s.filterBySchedule(staff, startDate, endDate)
 }
 
 func (s *ServiceService) FindBusyTimeSlot(
@@ -325,47 +543,40 @@ func (s *ServiceService) FindBusyTimeSlot(
 		return nil, err
 	}
 
-	workingStart := 8
-	workingEnd := 17
-
-	result := make(map[string][]string)
+	calendar, err := s.loadStaffCalendar(staffID, startDate00, endDate23)
+	if err != nil {
+		return nil, err
+	}
 
+	// booking กับช่วงที่แจ้งไม่ว่างนับเป็น busy เหมือนกัน
+	busy := slices.Clone(calendar.unavailable)
 	for _, svc := range *services {
-		svcStart := svc.RdateStart
-		svcEnd := svc.RdateEnd
+		busy = append(busy, entities.TimeRangeModel{Start: svc.RdateStart, End: svc.RdateEnd})
+	}
+
+	result := make(map[string][]string)
 
-		// clip service time within request date range
-		busyStart := utils.MaxTime(svcStart, startDate00)
-		busyEnd := utils.MinTime(svcEnd, endDate23)
+	for _, r := range mergeRanges(busy) {
+		// clip busy time within request date range
+		busyStart := utils.MaxTime(r.Start, startDate00)
+		busyEnd := utils.MinTime(r.End, endDate23)
 
 		if !busyStart.Before(busyEnd) {
 			continue // no overlap
 		}
 
-		// iterate each day ONLY within request range
-		for day := busyStart; day.Before(busyEnd); day = day.Add(24 * time.Hour) {
-
+		for day := startOfDay(busyStart); day.Before(busyEnd); day = day.Add(dayLength) {
 			dateKey := day.Format("2006-01-02")
 
-			// skip days that are outside request range
-			if day.Before(startDate00) || day.After(endDate23) {
-				continue
-			}
-
-			// working hour limits
-			dayStart := time.Date(day.Year(), day.Month(), day.Day(), workingStart, 0, 0, 0, day.Location())
-			dayEnd := time.Date(day.Year(), day.Month(), day.Day(), workingEnd, 0, 0, 0, day.Location())
+			// only the staff's working ranges of that day (weekly + extra shifts) count
+			for _, working := range calendar.working(day) {
+				realStart := utils.MaxTime(busyStart, working.Start)
+				realEnd := utils.MinTime(busyEnd, working.End)
 
-			realStart := utils.MaxTime(busyStart, dayStart)
-			realEnd := utils.MinTime(busyEnd, dayEnd)
-
-			if !realStart.Before(realEnd) {
-				continue
-			}
-
-			// add hourly busy slots
-			for t := realStart; t.Before(realEnd); t = t.Add(time.Hour) {
-				result[dateKey] = append(result[dateKey], fmt.Sprintf("%02d:00", t.Hour()))
+				// add hourly busy slots
+				for t := realStart; t.Before(realEnd); t = t.Add(time.Hour) {
+					result[dateKey] = append(result[dateKey], fmt.Sprintf("%02d:00", t.In(utils.BusinessLocation).Hour()))
+				}
 			}
 		}
 	}
@@ -428,8 +639,13 @@ func (s *ServiceService) addStaffCommonData(service *entities.ServiceModel) (*en
 	if err != nil {
 		return nil, fmt.Errorf("service -> FindServiceByID: %v", err)
 	}
+	// staff คนเดียวอาจเป็นทั้ง caretaker และ doctor ใช้ role ตามประเภทของ service
+	role := db.RoleCaretaker
+	if service.ServiceType == "mservice" {
+		role = db.RoleDoctor
+	}
 	staffData = entities.StaffCommonData{
-		Role:            user.Role,
+		Role:            role,
 		Name:            user.Name,
 		TelephoneNumber: user.TelephoneNumber,
 		Profile:         user.Profile,
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid service status transition")
+	// ErrStatusChanged: มีคนเปลี่ยน status ไปก่อนระหว่างที่เราอ่านอยู่
+	ErrStatusChanged = repositories.ErrStatusChanged
+)
+
+// staff กดเริ่มงานก่อนเวลาจองได้ไม่เกินนี้ และต้องรอเกินเวลาเริ่มเท่านี้ถึงจะกด no_show ได้
+const statusStartGrace = 30 * time.Minute
+
+type statusTransition struct {
+	from []db.ServiceStatus
+	// role ที่เปลี่ยนผ่าน PATCH /services/:id/:status ได้
+	roles []string
+}
+
+// สถานะปลายทาง -> สถานะเดิมที่เปลี่ยนมาได้ และใครเปลี่ยนได้
+var serviceStatusTransitions = map[db.ServiceStatus]statusTransition{
+	db.ServiceStatusOngoing: {from: []db.ServiceStatus{db.ServiceStatusWait}, roles: []string{"admin", "caretaker", "doctor"}},
+	db.ServiceStatusFinish:  {from: []db.ServiceStatus{db.ServiceStatusOngoing}, roles: []string{"admin", "caretaker", "doctor"}},
+	db.ServiceStatusNoShow:  {from: []db.ServiceStatus{db.ServiceStatusWait}, roles: []string{"admin", "caretaker", "doctor"}},
+	// admin แก้กรณีกดเริ่มงานผิด
+	db.ServiceStatusWait: {from: []db.ServiceStatus{db.ServiceStatusOngoing}, roles: []string{"admin"}},
+	// ยกเลิกผ่าน DELETE /services/:id (คิดเงินคืน) หรือ webhook เท่านั้น
+	db.ServiceStatusCancelled: {from: []db.ServiceStatus{db.ServiceStatusWait, db.ServiceStatusOngoing}},
+}
+
+func canTransition(from, to db.ServiceStatus) bool {
+	transition, ok := serviceStatusTransitions[to]
+	return ok && slices.Contains(transition.from, from)
+}
+
+// checkStatusTransition applies the graph, role and time guards for a status change
+// requested by a user. Admins skip the time guards.
+func checkStatusTransition(service *entities.ServiceModel, to db.ServiceStatus, role string, now time.Time) error {
+	if !canTransition(service.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, service.Status, to)
+	}
+	if !slices.Contains(serviceStatusTransitions[to].roles, role) {
+		return fmt.Errorf("%w: %s cannot set %s", ErrInvalidStatusTransition, role, to)
+	}
+	if role == "admin" {
+		return nil
+	}
+
+	switch to {
+	case db.ServiceStatusOngoing:
+		if now.Before(service.ReserveDateStart.Add(-statusStartGrace)) {
+			return fmt.Errorf("%w: service starts at %s", ErrInvalidStatusTransition, service.ReserveDateStart.Format(time.RFC3339))
+		}
+	case db.ServiceStatusFinish:
+		if now.Before(service.ReserveDateStart) {
+			return fmt.Errorf("%w: service starts at %s", ErrInvalidStatusTransition, service.ReserveDateStart.Format(time.RFC3339))
+		}
+	case db.ServiceStatusNoShow:
+		if now.Before(service.ReserveDateStart.Add(statusStartGrace)) {
+			return fmt.Errorf("%w: too early to mark no_show", ErrInvalidStatusTransition)
+		}
+	}
+
+	return nil
+}
+
+// changeStatusTx queues the transition and its history row. actorID nil means the
+// system (e.g. a Stripe webhook).
+func (s *ServiceService) changeStatusTx(tx *repositories.Tx, serviceID string, from, to db.ServiceStatus, actorID *string, actorRole *db.Role, note *string) {
+	s.Repo.UpdateStatusTx(tx, serviceID, from, to)
+	s.StatusHistoryRepo.InsertTx(tx, entities.ServiceStatusHistoryModel{
+		ServiceID:     serviceID,
+		FromStatus:    &from,
+		ToStatus:      to,
+		ChangedBy:     actorID,
+		ChangedByRole: actorRole,
+		Note:          note,
+	})
+}
+
+func (s *ServiceService) FindStatusHistory(serviceID string) ([]*entities.ServiceStatusHistoryModel, error) {
+	return s.StatusHistoryRepo.FindByServiceID(serviceID)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/services/mocks"
+)
+
+func TestCheckStatusTransition(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		from    db.ServiceStatus
+		to      db.ServiceStatus
+		role    string
+		now     time.Time
+		wantErr bool
+	}{
+		{name: "staff starts on time", from: db.ServiceStatusWait, to: db.ServiceStatusOngoing, role: "caretaker", now: start},
+		{name: "staff starts within grace", from: db.ServiceStatusWait, to: db.ServiceStatusOngoing, role: "doctor", now: start.Add(-20 * time.Minute)},
+		{name: "staff starts too early", from: db.ServiceStatusWait, to: db.ServiceStatusOngoing, role: "caretaker", now: start.Add(-2 * time.Hour), wantErr: true},
+		{name: "admin starts early", from: db.ServiceStatusWait, to: db.ServiceStatusOngoing, role: "admin", now: start.Add(-2 * time.Hour)},
+		{name: "finish after start", from: db.ServiceStatusOngoing, to: db.ServiceStatusFinish, role: "caretaker", now: start.Add(time.Hour)},
+		{name: "finish before start", from: db.ServiceStatusOngoing, to: db.ServiceStatusFinish, role: "caretaker", now: start.Add(-10 * time.Minute), wantErr: true},
+		{name: "finish skips ongoing", from: db.ServiceStatusWait, to: db.ServiceStatusFinish, role: "admin", now: start.Add(time.Hour), wantErr: true},
+		{name: "no_show after grace", from: db.ServiceStatusWait, to: db.ServiceStatusNoShow, role: "doctor", now: start.Add(time.Hour)},
+		{name: "no_show too early", from: db.ServiceStatusWait, to: db.ServiceStatusNoShow, role: "doctor", now: start.Add(10 * time.Minute), wantErr: true},
+		{name: "admin reverts start", from: db.ServiceStatusOngoing, to: db.ServiceStatusWait, role: "admin", now: start},
+		{name: "staff can't revert start", from: db.ServiceStatusOngoing, to: db.ServiceStatusWait, role: "caretaker", now: start, wantErr: true},
+		{name: "finished stays finished", from: db.ServiceStatusFinish, to: db.ServiceStatusOngoing, role: "admin", now: start, wantErr: true},
+		{name: "cancelled can't come back", from: db.ServiceStatusCancelled, to: db.ServiceStatusWait, role: "admin", now: start, wantErr: true},
+		{name: "cancel goes through DELETE", from: db.ServiceStatusWait, to: db.ServiceStatusCancelled, role: "admin", now: start, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &entities.ServiceModel{Status: tt.from, ReserveDateStart: start, ReserveDateEnd: start.Add(2 * time.Hour)}
+			err := checkStatusTransition(service, tt.to, tt.role, tt.now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidStatusTransition) {
+					t.Fatalf("want ErrInvalidStatusTransition got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		})
+	}
+}
+
+func TestServiceService_UpdateStatus(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	booking := &entities.ServiceModel{
+		Sid: "s1", StaffID: "care-1", ServiceType: "cservice", Status: db.ServiceStatusWait,
+		ReserveDateStart: start, ReserveDateEnd: start.Add(2 * time.Hour),
+	}
+
+	t.Run("writes status and history together", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockIServiceRepository(ctrl)
+		mockHistory := mocks.NewMockIServiceStatusHistoryRepository(ctrl)
+		mockUow := mocks.NewMockIUnitOfWork(ctrl)
+		sv := &ServiceService{Repo: mockRepo, StatusHistoryRepo: mockHistory, UnitOfWork: mockUow}
+
+		tx := &repositories.Tx{}
+		mockRepo.EXPECT().FindByID("s1").Return(booking, nil)
+		mockUow.EXPECT().Begin().Return(tx)
+		mockRepo.EXPECT().UpdateStatusTx(tx, "s1", db.ServiceStatusWait, db.ServiceStatusOngoing)
+		mockHistory.EXPECT().InsertTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, h entities.ServiceStatusHistoryModel) {
+			if *h.FromStatus != db.ServiceStatusWait || h.ToStatus != db.ServiceStatusOngoing ||
+				*h.ChangedBy != "care-1" || *h.ChangedByRole != db.RoleCaretaker {
+				t.Fatalf("unexpected history row %+v", h)
+			}
+		})
+		mockUow.EXPECT().Commit(tx).Return(nil)
+
+		if err := sv.UpdateStatus("s1", "ongoing", "caretaker", "care-1"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("concurrent change loses", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockIServiceRepository(ctrl)
+		mockHistory := mocks.NewMockIServiceStatusHistoryRepository(ctrl)
+		mockUow := mocks.NewMockIUnitOfWork(ctrl)
+		sv := &ServiceService{Repo: mockRepo, StatusHistoryRepo: mockHistory, UnitOfWork: mockUow}
+
+		tx := &repositories.Tx{}
+		mockRepo.EXPECT().FindByID("s1").Return(booking, nil)
+		mockUow.EXPECT().Begin().Return(tx)
+		mockRepo.EXPECT().UpdateStatusTx(tx, "s1", db.ServiceStatusWait, db.ServiceStatusNoShow)
+		mockHistory.EXPECT().InsertTx(tx, gomock.Any())
+		mockUow.EXPECT().Commit(tx).Return(repositories.ErrStatusChanged)
+
+		err := sv.UpdateStatus("s1", "no_show", "caretaker", "care-1")
+		if !errors.Is(err, ErrStatusChanged) {
+			t.Fatalf("want ErrStatusChanged got %v", err)
+		}
+	})
+
+	t.Run("other staff's booking", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockIServiceRepository(ctrl)
+		sv := &ServiceService{Repo: mockRepo}
+
+		mockRepo.EXPECT().FindByID("s1").Return(booking, nil)
+		if err := sv.UpdateStatus("s1", "ongoing", "caretaker", "care-2"); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+}
@@ -0,0 +1,636 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/services/mocks"
+)
+
+func TestServiceService_CancelByPaymentID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIServiceRepository(ctrl)
+	mockHistory := mocks.NewMockIServiceStatusHistoryRepository(ctrl)
+	mockUow := mocks.NewMockIUnitOfWork(ctrl)
+	mockCancel := mocks.NewMockIServiceCancellationRepository(ctrl)
+	sv := &ServiceService{Repo: mockRepo, StatusHistoryRepo: mockHistory, UnitOfWork: mockUow, CancellationRepo: mockCancel}
+
+	t.Run("waiting booking is cancelled", func(t *testing.T) {
+		tx := &repositories.Tx{}
+		mockRepo.EXPECT().FindByPaymentID("pay-1").
+			Return(&entities.ServiceModel{Sid: "s1", Status: db.ServiceStatusWait}, nil)
+		mockCancel.EXPECT().HasPendingByPaymentID("pay-1").Return(false, nil)
+		mockUow.EXPECT().Begin().Return(tx)
+		mockRepo.EXPECT().UpdateStatusTx(tx, "s1", db.ServiceStatusWait, db.ServiceStatusCancelled)
+		mockHistory.EXPECT().InsertTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, h entities.ServiceStatusHistoryModel) {
+			if h.ChangedBy != nil || h.Note == nil || *h.Note != "payment refunded on stripe" {
+				t.Fatalf("webhook change should be recorded as system with a note, got %+v", h)
+			}
+		})
+		mockUow.EXPECT().Commit(tx).Return(nil)
+
+		got, err := sv.CancelByPaymentID("pay-1", db.PaymentStatusRefunded)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if got.Status != db.ServiceStatusCancelled {
+			t.Fatalf("want cancelled got %s", got.Status)
+		}
+	})
+
+	t.Run("started booking is left alone", func(t *testing.T) {
+		mockRepo.EXPECT().FindByPaymentID("pay-1").
+			Return(&entities.ServiceModel{Sid: "s1", Status: db.ServiceStatusOngoing}, nil)
+
+		got, err := sv.CancelByPaymentID("pay-1", db.PaymentStatusRefunded)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if got.Status != db.ServiceStatusOngoing {
+			t.Fatalf("want ongoing got %s", got.Status)
+		}
+	})
+
+	// refund ที่ CancelService ยิงเองแล้ว webhook มาถึงก่อน commit
+	t.Run("booking being cancelled by the owner is left to CancelService", func(t *testing.T) {
+		mockRepo.EXPECT().FindByPaymentID("pay-1").
+			Return(&entities.ServiceModel{Sid: "s1", Status: db.ServiceStatusWait}, nil)
+		mockCancel.EXPECT().HasPendingByPaymentID("pay-1").Return(true, nil)
+
+		got, err := sv.CancelByPaymentID("pay-1", db.PaymentStatusRefunded)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if got.Status != db.ServiceStatusWait {
+			t.Fatalf("want wait got %s", got.Status)
+		}
+	})
+
+	t.Run("payment without booking", func(t *testing.T) {
+		mockRepo.EXPECT().FindByPaymentID("pay-2").
+			Return(nil, fmt.Errorf("service -> FindByPaymentID: %w", db.ErrNotFound))
+
+		got, err := sv.CancelByPaymentID("pay-2", db.PaymentStatusRefunded)
+		if err != nil || got != nil {
+			t.Fatalf("want nil, nil got %+v, %v", got, err)
+		}
+	})
+}
+
+func TestServiceService_CreateService(t *testing.T) {
+	start := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	// client ส่ง status มาก็ไม่มีผล booking ใหม่ต้องเริ่มที่ wait เสมอ
+	var req entities.CreateServiceRequest
+	body := `{"owner_id":"owner-1","pet_id":"pet-1","payment_id":"pay-1","staff_id":"care-1","service_type":"cservice","status":"finish",
+		"reserve_date_start":"2026-01-10T09:00:00Z","reserve_date_end":"2026-01-10T11:00:00Z"}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	method := "card"
+	settlement := entities.StripePaymentUpdate{Status: db.PaymentStatusPaid, Type: &method, PayDate: &start}
+
+	tests := []struct {
+		name      string
+		commitErr error
+		wantErr   error
+	}{
+		{name: "all writes commit together"},
+		{name: "rollback leaves nothing behind", commitErr: errors.New("pql error"), wantErr: errors.New("pql error")},
+		{name: "slot taken while paying", commitErr: ErrStaffUnavailable, wantErr: ErrStaffUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIServiceRepository(ctrl)
+			mockCaretaker := mocks.NewMockICaretakerRepository(ctrl)
+			mockCservice := mocks.NewMockICServiceRepository(ctrl)
+			mockPayment := mocks.NewMockIPaymentRepository(ctrl)
+			mockOwner := mocks.NewMockIOwnerRepository(ctrl)
+			mockUsers := mocks.NewMockIUsersRepository(ctrl)
+			mockUow := mocks.NewMockIUnitOfWork(ctrl)
+			mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+			mockHistory := mocks.NewMockIServiceStatusHistoryRepository(ctrl)
+			mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+			sv := &ServiceService{
+				Repo:              mockRepo,
+				UserRepo:          mockUsers,
+				CaretakerRepo:     mockCaretaker,
+				CserviceRepo:      mockCservice,
+				PaymentRepo:       mockPayment,
+				OwnerRepo:         mockOwner,
+				UnitOfWork:        mockUow,
+				StaffHoldRepo:     mockHold,
+				StatusHistoryRepo: mockHistory,
+				ScheduleRepo:      mockSchedule,
+			}
+
+			tx := &repositories.Tx{}
+			var serviceID string
+			mockCaretaker.EXPECT().FindByID("care-1").Return(&entities.UserDataModel{UserID: "care-1"}, nil)
+			mockPayment.EXPECT().FindByID("pay-1").
+				Return(&entities.PaymentModel{PayID: "pay-1", OwnerID: "owner-1", Status: db.PaymentStatusUnpaid, Price: 200}, nil).
+				Times(2)
+			mockHold.EXPECT().HasConflict("care-1", req.ReserveDateStart, req.ReserveDateEnd, "pay-1").Return(false, nil)
+			expectShifts(mockSchedule, "care-1", allDayShifts())
+			mockUow.EXPECT().Begin().Return(tx)
+			mockHold.EXPECT().GuardSlotTx(tx, "care-1", req.ReserveDateStart, req.ReserveDateEnd, "pay-1")
+			mockHold.EXPECT().DeleteByPaymentIDTx(tx, "pay-1")
+			mockPayment.EXPECT().
+				UpdateByIDTx(tx, "pay-1", entities.PaymentModel{Status: db.PaymentStatusPaid, Type: &method, PayDate: &start}).
+				Return(nil)
+			mockRepo.EXPECT().InsertTx(tx, gomock.Any(), req).Do(func(_ *repositories.Tx, id string, _ entities.CreateServiceRequest) {
+				serviceID = id
+			})
+			mockCservice.EXPECT().InsertTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, sub entities.SubService) {
+				if sub.ServiceID != serviceID || sub.StaffID != "care-1" {
+					t.Fatalf("cservice must link to the new service, got %+v (service %s)", sub, serviceID)
+				}
+			})
+			mockHistory.EXPECT().InsertTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, h entities.ServiceStatusHistoryModel) {
+				if h.ServiceID != serviceID || h.FromStatus != nil || h.ToStatus != db.ServiceStatusWait {
+					t.Fatalf("want first history row nil -> wait for %s, got %+v", serviceID, h)
+				}
+			})
+			mockOwner.EXPECT().AddTotalSpendingTx(tx, "owner-1", gomock.Any()).Do(func(_ *repositories.Tx, _ string, amount decimal.Decimal) {
+				if !amount.Equal(decimal.NewFromInt(200)) {
+					t.Fatalf("want spending +200 got %s", amount)
+				}
+			})
+			mockUow.EXPECT().Commit(tx).Return(tt.commitErr)
+			if tt.wantErr == nil {
+				mockRepo.EXPECT().FindByID(gomock.Any()).DoAndReturn(func(id string) (*entities.ServiceModel, error) {
+					return &entities.ServiceModel{Sid: id, StaffID: "care-1", ServiceType: "cservice"}, nil
+				})
+				mockUsers.EXPECT().FindByID("care-1").Return(&entities.UserDataModel{UserID: "care-1", Name: "Care"}, nil)
+			}
+
+			service, sub, err := sv.CreateService(req, settlement)
+			if tt.wantErr != nil {
+				if err == nil || (errors.Is(tt.wantErr, ErrStaffUnavailable) && !errors.Is(err, ErrStaffUnavailable)) {
+					t.Fatalf("want %v got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if service.Sid != serviceID || sub.ServiceID != serviceID {
+				t.Fatalf("returned ids don't match inserted id %s: %+v %+v", serviceID, service, sub)
+			}
+		})
+	}
+}
+
+func TestServiceService_ValidateServiceCreation_Conflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCaretaker := mocks.NewMockICaretakerRepository(ctrl)
+	mockPayment := mocks.NewMockIPaymentRepository(ctrl)
+	mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+	sv := &ServiceService{CaretakerRepo: mockCaretaker, PaymentRepo: mockPayment, StaffHoldRepo: mockHold}
+
+	start := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	req := entities.CreateServiceRequest{
+		OwnerID: "owner-1", PaymentID: "pay-2", StaffID: "care-1", ServiceType: "cservice",
+		ReserveDateStart: start, ReserveDateEnd: start.Add(time.Hour),
+	}
+
+	mockCaretaker.EXPECT().FindByID("care-1").Return(&entities.UserDataModel{UserID: "care-1"}, nil)
+	mockPayment.EXPECT().FindByID("pay-2").
+		Return(&entities.PaymentModel{PayID: "pay-2", OwnerID: "owner-1", Status: db.PaymentStatusUnpaid}, nil)
+	mockHold.EXPECT().HasConflict("care-1", req.ReserveDateStart, req.ReserveDateEnd, "pay-2").Return(true, nil)
+
+	if err := sv.ValidateServiceCreation(req, "unpaid"); !errors.Is(err, ErrStaffUnavailable) {
+		t.Fatalf("want ErrStaffUnavailable got %v", err)
+	}
+}
+
+func TestServiceService_HoldSlot(t *testing.T) {
+	start := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	req := entities.CreateServiceRequest{
+		PaymentID: "pay-1", StaffID: "care-1",
+		ReserveDateStart: start, ReserveDateEnd: start.Add(time.Hour),
+	}
+
+	tests := []struct {
+		name      string
+		offDuty   bool
+		commitErr error
+	}{
+		{name: "slot is held"},
+		{name: "someone else holds it", commitErr: ErrStaffUnavailable},
+		// 2026-01-10 เป็นวันเสาร์ ตาราง split shift ทำแค่วันจันทร์
+		{name: "outside working hours", offDuty: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUow := mocks.NewMockIUnitOfWork(ctrl)
+			mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+			mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+			sv := &ServiceService{UnitOfWork: mockUow, StaffHoldRepo: mockHold, ScheduleRepo: mockSchedule}
+
+			if tt.offDuty {
+				expectShifts(mockSchedule, "care-1", splitShiftMonday())
+				if _, err := sv.HoldSlot(req); !errors.Is(err, ErrStaffUnavailable) {
+					t.Fatalf("want ErrStaffUnavailable got %v", err)
+				}
+				return
+			}
+			expectShifts(mockSchedule, "care-1", allDayShifts())
+
+			tx := &repositories.Tx{}
+			before := time.Now()
+			mockUow.EXPECT().Begin().Return(tx)
+			mockHold.EXPECT().GuardSlotTx(tx, "care-1", req.ReserveDateStart, req.ReserveDateEnd, "pay-1")
+			mockHold.EXPECT().InsertTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, hold entities.StaffHoldModel) {
+				if hold.PaymentID != "pay-1" || !hold.ExpiresAt.After(before.Add(checkoutHoldDuration)) {
+					t.Fatalf("hold must outlive the checkout session: %+v", hold)
+				}
+			})
+			mockUow.EXPECT().Commit(tx).Return(tt.commitErr)
+
+			expiresAt, err := sv.HoldSlot(req)
+			if tt.commitErr != nil {
+				if !errors.Is(err, ErrStaffUnavailable) {
+					t.Fatalf("want ErrStaffUnavailable got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if expiresAt.Before(before.Add(checkoutHoldDuration)) {
+				t.Fatalf("checkout expiry too early: %v", expiresAt)
+			}
+		})
+	}
+}
+
+func TestServiceService_CheckAvailability(t *testing.T) {
+	tuesday := scheduleMonday.Add(dayLength)
+	extra := func(start, end time.Time) []entities.StaffScheduleExceptionModel {
+		return []entities.StaffScheduleExceptionModel{{StaffID: "doc-1", Kind: db.ScheduleExceptionKindExtra, Start: start, End: end}}
+	}
+
+	tests := []struct {
+		name          string
+		start, end    time.Time
+		exceptions    []entities.StaffScheduleExceptionModel
+		taken         bool
+		wantAvailable bool
+	}{
+		{name: "free", start: clockOn(scheduleMonday, 9, 0), end: clockOn(scheduleMonday, 11, 0), wantAvailable: true},
+		{name: "booked or held by someone else", start: clockOn(scheduleMonday, 9, 0), end: clockOn(scheduleMonday, 11, 0), taken: true, wantAvailable: false},
+		{name: "02:00 on a working day", start: clockOn(scheduleMonday, 2, 0), end: clockOn(scheduleMonday, 3, 0), wantAvailable: false},
+		{name: "runs into the lunch break", start: clockOn(scheduleMonday, 11, 0), end: clockOn(scheduleMonday, 14, 0), wantAvailable: false},
+		{name: "day off", start: clockOn(tuesday, 9, 0), end: clockOn(tuesday, 11, 0), wantAvailable: false},
+		{
+			name: "extra shift on a day off", start: clockOn(tuesday, 9, 0), end: clockOn(tuesday, 11, 0),
+			exceptions: extra(clockOn(tuesday, 8, 0), clockOn(tuesday, 12, 0)), wantAvailable: true,
+		},
+		{
+			// ต่อจากเวลาปกติ 13-17 ได้ ช่วงที่จองคร่อมทั้งสองช่วง
+			name: "extra shift right after hours", start: clockOn(scheduleMonday, 16, 0), end: clockOn(scheduleMonday, 19, 0),
+			exceptions: extra(clockOn(scheduleMonday, 17, 0), clockOn(scheduleMonday, 20, 0)), wantAvailable: true,
+		},
+		{
+			name: "extra shift too short", start: clockOn(tuesday, 9, 0), end: clockOn(tuesday, 11, 0),
+			exceptions: extra(clockOn(tuesday, 9, 0), clockOn(tuesday, 10, 0)), wantAvailable: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDoctor := mocks.NewMockIDoctorRepository(ctrl)
+			mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+			mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+			sv := &ServiceService{DoctorRepo: mockDoctor, StaffHoldRepo: mockHold, ScheduleRepo: mockSchedule}
+
+			req := entities.CreateServiceRequest{StaffID: "doc-1", ServiceType: "mservice", ReserveDateStart: tt.start, ReserveDateEnd: tt.end}
+			mockDoctor.EXPECT().FindByID("doc-1").Return(&entities.UserDataModel{UserID: "doc-1"}, nil)
+			mockHold.EXPECT().HasConflict("doc-1", req.ReserveDateStart, req.ReserveDateEnd, "").Return(tt.taken, nil)
+			if !tt.taken {
+				expectShifts(mockSchedule, "doc-1", splitShiftMonday(), tt.exceptions...)
+			}
+
+			available, err := sv.CheckAvailability(req)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if available != tt.wantAvailable {
+				t.Fatalf("available: want %v got %v", tt.wantAvailable, available)
+			}
+		})
+	}
+}
+
+func TestServiceService_PlanCancellation(t *testing.T) {
+	now := time.Now()
+	policy := RefundPolicy{FullRefundBefore: 24 * time.Hour, LatePercent: 50}
+
+	tests := []struct {
+		name          string
+		status        db.ServiceStatus
+		startIn       time.Duration
+		paymentStatus db.PaymentStatus
+		refunded      int
+		adjustment    int
+		role          string
+		fullRefund    bool
+		wantPercent   int
+		wantAmount    int
+		wantErr       error
+	}{
+		{name: "early cancel gets everything back", status: db.ServiceStatusWait, startIn: 48 * time.Hour, paymentStatus: db.PaymentStatusPaid, role: "owner", wantPercent: 100, wantAmount: 400},
+		{name: "late cancel gets partial refund", status: db.ServiceStatusWait, startIn: 3 * time.Hour, paymentStatus: db.PaymentStatusPaid, role: "owner", wantPercent: 50, wantAmount: 200},
+		{name: "ongoing gets nothing", status: db.ServiceStatusOngoing, startIn: -time.Hour, paymentStatus: db.PaymentStatusPaid, role: "owner", wantPercent: 0, wantAmount: 0},
+		{name: "admin can force full refund", status: db.ServiceStatusOngoing, startIn: -time.Hour, paymentStatus: db.PaymentStatusPaid, role: "admin", fullRefund: true, wantPercent: 100, wantAmount: 400},
+		{name: "owner cannot force full refund", status: db.ServiceStatusWait, startIn: time.Hour, paymentStatus: db.PaymentStatusPaid, role: "owner", fullRefund: true, wantPercent: 50, wantAmount: 200},
+		{name: "already refunded part is not refunded again", status: db.ServiceStatusWait, startIn: 48 * time.Hour, paymentStatus: db.PaymentStatusPaid, refunded: 150, role: "owner", wantPercent: 100, wantAmount: 250},
+		{name: "paid reschedule adjustment is refunded too", status: db.ServiceStatusWait, startIn: 48 * time.Hour, paymentStatus: db.PaymentStatusPaid, adjustment: 100, role: "owner", wantPercent: 100, wantAmount: 500},
+		{name: "unpaid booking has nothing to refund", status: db.ServiceStatusWait, startIn: 48 * time.Hour, paymentStatus: db.PaymentStatusUnpaid, role: "owner", wantPercent: 0, wantAmount: 0},
+		{name: "finished booking", status: db.ServiceStatusFinish, role: "owner", wantErr: ErrServiceNotCancellable},
+		{name: "already cancelled", status: db.ServiceStatusCancelled, role: "admin", wantErr: ErrServiceNotCancellable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPayment := mocks.NewMockIPaymentRepository(ctrl)
+			mockCancel := mocks.NewMockIServiceCancellationRepository(ctrl)
+			sv := &ServiceService{PaymentRepo: mockPayment, CancellationRepo: mockCancel, RefundPolicy: policy}
+
+			existing := &entities.ServiceModel{
+				Sid: "s1", OwnerID: "owner-1", PaymentID: "pay-1",
+				Status: tt.status, ReserveDateStart: now.Add(tt.startIn),
+			}
+			if tt.wantErr == nil {
+				payment := &entities.PaymentModel{PayID: "pay-1", Price: 400, Status: tt.paymentStatus, RefundedAmount: tt.refunded}
+				if tt.adjustment > 0 {
+					payment.Adjustments = []*entities.PaymentModel{{PayID: "adj-1", Price: tt.adjustment, Status: db.PaymentStatusPaid}}
+				}
+				mockCancel.EXPECT().FindPendingByServiceID("s1").Return(nil, fmt.Errorf("service cancellation -> FindPendingByServiceID: %w", db.ErrNotFound))
+				mockPayment.EXPECT().FindByID("pay-1").Return(payment, nil)
+				mockCancel.EXPECT().InsertPending(gomock.Any()).DoAndReturn(func(data entities.ServiceCancellationModel) (*entities.ServiceCancellationModel, error) {
+					return &data, nil
+				})
+			}
+
+			got, err := sv.PlanCancellation(existing, "actor-1", tt.role, entities.CancelServiceRequest{FullRefund: tt.fullRefund})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want %v got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got.RefundPercent != tt.wantPercent || got.RefundAmount != tt.wantAmount {
+				t.Fatalf("refund: want %d%% (%d) got %d%% (%d)", tt.wantPercent, tt.wantAmount, got.RefundPercent, got.RefundAmount)
+			}
+			if got.CancelledBy != "actor-1" || got.OwnerID != "owner-1" {
+				t.Fatalf("unexpected cancellation: %+v", got)
+			}
+		})
+	}
+}
+
+func TestServiceService_PlanCancellation_Retry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCancel := mocks.NewMockIServiceCancellationRepository(ctrl)
+	sv := &ServiceService{CancellationRepo: mockCancel, RefundPolicy: RefundPolicy{FullRefundBefore: 24 * time.Hour, LatePercent: 50}}
+
+	// รอบแรกยกเลิกก่อน 24 ชม. ได้ 100% ลองใหม่ตอนเหลือ 3 ชม. ต้องได้ยอดเดิม ไม่ใช่ 50%
+	mockCancel.EXPECT().FindPendingByServiceID("s1").Return(&entities.ServiceCancellationModel{
+		ServiceID: "s1", CancelledBy: "owner-1", CancelledByRole: db.RoleOwner, RefundPercent: 100, RefundAmount: 400,
+	}, nil)
+
+	existing := &entities.ServiceModel{
+		Sid: "s1", OwnerID: "owner-1", PaymentID: "pay-1",
+		Status: db.ServiceStatusWait, ReserveDateStart: time.Now().Add(3 * time.Hour),
+	}
+	got, err := sv.PlanCancellation(existing, "owner-1", "owner", entities.CancelServiceRequest{})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if got.RefundAmount != 400 || got.RefundPercent != 100 || got.PaymentID != "pay-1" || got.FromStatus != db.ServiceStatusWait {
+		t.Fatalf("retry should reuse the pending cancellation, got %+v", got)
+	}
+}
+
+func TestServiceService_CancelService(t *testing.T) {
+	tests := []struct {
+		name      string
+		refunds   []entities.PaymentRefundModel
+		commitErr error
+	}{
+		{
+			name:    "full refund marks payment refunded",
+			refunds: []entities.PaymentRefundModel{{PaymentID: "pay-1", Amount: 400, RefundedAmount: 400, FullyRefunded: true}},
+		},
+		{
+			name:    "partial refund keeps payment paid",
+			refunds: []entities.PaymentRefundModel{{PaymentID: "pay-1", Amount: 200, RefundedAmount: 200}},
+		},
+		{
+			name: "refund spread over the adjustment and the original payment",
+			refunds: []entities.PaymentRefundModel{
+				{PaymentID: "adj-1", Amount: 100, RefundedAmount: 100, FullyRefunded: true},
+				{PaymentID: "pay-1", Amount: 50, RefundedAmount: 50},
+			},
+		},
+		{name: "no refund only cancels"},
+		{name: "double cancel fails the tx", commitErr: errors.New("service status changed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIServiceRepository(ctrl)
+			mockPayment := mocks.NewMockIPaymentRepository(ctrl)
+			mockOwner := mocks.NewMockIOwnerRepository(ctrl)
+			mockUser := mocks.NewMockIUsersRepository(ctrl)
+			mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+			mockCancel := mocks.NewMockIServiceCancellationRepository(ctrl)
+			mockUow := mocks.NewMockIUnitOfWork(ctrl)
+			mockHistory := mocks.NewMockIServiceStatusHistoryRepository(ctrl)
+			sv := &ServiceService{
+				Repo: mockRepo, PaymentRepo: mockPayment, OwnerRepo: mockOwner, UserRepo: mockUser,
+				StaffHoldRepo: mockHold, CancellationRepo: mockCancel, UnitOfWork: mockUow,
+				StatusHistoryRepo: mockHistory,
+			}
+
+			total := 0
+			for _, refund := range tt.refunds {
+				total += refund.Amount
+			}
+			cancellation := &entities.ServiceCancellationModel{
+				ServiceID: "s1", PaymentID: "pay-1", OwnerID: "owner-1", FromStatus: db.ServiceStatusWait,
+				CancelledBy: "owner-1", CancelledByRole: db.RoleOwner, RefundAmount: total, Refunds: tt.refunds,
+			}
+			tx := &repositories.Tx{}
+
+			mockUow.EXPECT().Begin().Return(tx)
+			mockCancel.EXPECT().CompleteTx(tx, gomock.Any())
+			mockRepo.EXPECT().UpdateStatusTx(tx, "s1", db.ServiceStatusWait, db.ServiceStatusCancelled)
+			mockHistory.EXPECT().InsertTx(tx, gomock.Any())
+			for _, refund := range tt.refunds {
+				want := entities.PaymentModel{RefundedAmount: refund.RefundedAmount}
+				if refund.FullyRefunded {
+					want.Status = db.PaymentStatusRefunded
+				}
+				mockPayment.EXPECT().UpdateByIDTx(tx, refund.PaymentID, want).Return(nil)
+			}
+			if total > 0 {
+				mockOwner.EXPECT().AddTotalSpendingTx(tx, "owner-1", decimal.NewFromInt(int64(-total)))
+			}
+			mockHold.EXPECT().DeleteByPaymentIDTx(tx, "pay-1")
+			mockUow.EXPECT().Commit(tx).Return(tt.commitErr)
+			if tt.commitErr == nil {
+				mockRepo.EXPECT().FindByID("s1").Return(&entities.ServiceModel{Sid: "s1", StaffID: "staff-1", Status: db.ServiceStatusCancelled}, nil)
+				mockUser.EXPECT().FindByID("staff-1").Return(&entities.UserDataModel{UserID: "staff-1"}, nil)
+			}
+
+			got, err := sv.CancelService(cancellation)
+			if tt.commitErr != nil {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got.Status != db.ServiceStatusCancelled {
+				t.Fatalf("status: want cancelled got %s", got.Status)
+			}
+		})
+	}
+}
+
+func TestServiceService_UpdateServiceByID(t *testing.T) {
+	start := time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC)
+	newStart := start.Add(24 * time.Hour)
+	newEnd := newStart.Add(2 * time.Hour)
+	staffID := "staff-2"
+	ongoing := string(db.ServiceStatusOngoing)
+
+	tests := []struct {
+		name      string
+		data      entities.UpdateServiceRequest
+		staffErr  error
+		commitErr error
+		wantErr   error
+	}{
+		{
+			name: "status and move commit together",
+			data: entities.UpdateServiceRequest{Status: &ongoing, StaffID: &staffID, ReserveDateStart: &newStart, ReserveDateEnd: &newEnd},
+		},
+		{
+			// slot ชนตอน commit ต้องไม่มี status เปลี่ยนค้างไว้
+			name:      "slot taken rolls back the status too",
+			data:      entities.UpdateServiceRequest{Status: &ongoing, ReserveDateStart: &newStart, ReserveDateEnd: &newEnd},
+			commitErr: repositories.ErrStaffUnavailable,
+			wantErr:   ErrStaffUnavailable,
+		},
+		{
+			name:     "unknown staff writes nothing",
+			data:     entities.UpdateServiceRequest{Status: &ongoing, StaffID: &staffID},
+			staffErr: db.ErrNotFound,
+			wantErr:  db.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIServiceRepository(ctrl)
+			mockCservice := mocks.NewMockICServiceRepository(ctrl)
+			mockCaretaker := mocks.NewMockICaretakerRepository(ctrl)
+			mockUser := mocks.NewMockIUsersRepository(ctrl)
+			mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+			mockHistory := mocks.NewMockIServiceStatusHistoryRepository(ctrl)
+			mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+			mockUow := mocks.NewMockIUnitOfWork(ctrl)
+			sv := &ServiceService{
+				Repo: mockRepo, CserviceRepo: mockCservice, CaretakerRepo: mockCaretaker, UserRepo: mockUser,
+				StaffHoldRepo: mockHold, StatusHistoryRepo: mockHistory, ScheduleRepo: mockSchedule, UnitOfWork: mockUow,
+			}
+
+			current := &entities.ServiceModel{
+				Sid: "s1", PaymentID: "pay-1", StaffID: "staff-1", ServiceType: "cservice",
+				Status: db.ServiceStatusWait, ReserveDateStart: start, ReserveDateEnd: start.Add(2 * time.Hour),
+			}
+			mockRepo.EXPECT().FindByID("s1").Return(current, nil)
+			if tt.data.StaffID != nil {
+				mockCaretaker.EXPECT().FindByID(staffID).Return(&entities.UserDataModel{UserID: staffID}, tt.staffErr)
+			}
+			if tt.staffErr == nil {
+				wantStaff := current.StaffID
+				if tt.data.StaffID != nil {
+					wantStaff = staffID
+				}
+				expectShifts(mockSchedule, wantStaff, allDayShifts())
+				tx := &repositories.Tx{}
+				mockUow.EXPECT().Begin().Return(tx)
+				mockRepo.EXPECT().UpdateStatusTx(tx, "s1", db.ServiceStatusWait, db.ServiceStatusOngoing)
+				mockHistory.EXPECT().InsertTx(tx, gomock.Any())
+				mockHold.EXPECT().GuardSlotTx(tx, wantStaff, newStart, newEnd, "pay-1")
+				mockCservice.EXPECT().UpdateStaffTx(tx, "s1", wantStaff)
+				mockRepo.EXPECT().UpdateReserveDateTx(tx, "s1", newStart, newEnd)
+				mockRepo.EXPECT().UpdateOwnerPetTx(tx, "s1", nil, nil)
+				mockCservice.EXPECT().UpdateReviewTx(tx, "s1", nil, nil)
+				mockUow.EXPECT().Commit(tx).Return(tt.commitErr)
+				if tt.commitErr == nil {
+					mockRepo.EXPECT().FindByID("s1").Return(&entities.ServiceModel{Sid: "s1", StaffID: wantStaff, Status: db.ServiceStatusOngoing}, nil)
+					mockUser.EXPECT().FindByID(wantStaff).Return(&entities.UserDataModel{UserID: wantStaff}, nil)
+				}
+			}
+
+			got, err := sv.UpdateServiceByID("s1", "admin-1", tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want %v got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got.Status != db.ServiceStatusOngoing || got.StaffID != staffID {
+				t.Fatalf("unexpected service %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/middlewares"
+	"lama-backend/src/services/mocks"
+)
+
+func TestAuthService_Sessions(t *testing.T) {
+	t.Setenv("JWT_SECRET_KEY", "test")
+	t.Setenv("JWT_REFESH_SECRET_KEY", "test-refresh")
+
+	newService := func(ctrl *gomock.Controller) (*authService, *mocks.MockISessionRepository) {
+		mockSession := mocks.NewMockISessionRepository(ctrl)
+		return &authService{SessionRepository: mockSession, RefreshTTL: 24 * time.Hour}, mockSession
+	}
+	session := func(hash string) *entities.SessionModel {
+		return &entities.SessionModel{
+			ID: "sess-1", UserID: "user-1", Role: db.RoleOwner,
+			RefreshHash: hash, ExpiresAt: time.Now().Add(time.Hour),
+		}
+	}
+
+	t.Run("login stores only the hash", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockSession := newService(ctrl)
+
+		var stored entities.SessionModel
+		mockSession.EXPECT().Insert(gomock.Any()).DoAndReturn(func(data entities.SessionModel) (*entities.SessionModel, error) {
+			stored = data
+			created := data
+			created.ID = "sess-1"
+			return &created, nil
+		})
+
+		td, err := sv.StartSession("user-1", "owner", "curl/8.0")
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if td.SessionID != "sess-1" || td.RefreshToken == nil || td.Purpose != "access" {
+			t.Fatalf("unexpected token %+v", td)
+		}
+		if stored.RefreshHash == *td.RefreshToken || stored.RefreshHash != middlewares.HashRefreshToken(*td.RefreshToken) {
+			t.Fatalf("expected the hash of the refresh token to be stored, got %q", stored.RefreshHash)
+		}
+		if stored.Device != "curl/8.0" || stored.Role != db.RoleOwner {
+			t.Fatalf("unexpected session %+v", stored)
+		}
+	})
+
+	t.Run("refresh rotates the token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockSession := newService(ctrl)
+
+		hash := middlewares.HashRefreshToken("old-token")
+		mockSession.EXPECT().FindByTokenHash(hash).Return(session(hash), nil)
+		var nextHash string
+		mockSession.EXPECT().Rotate("sess-1", hash, gomock.Any(), gomock.Any()).DoAndReturn(func(_, _, newHash string, _ time.Time) (bool, error) {
+			nextHash = newHash
+			return true, nil
+		})
+
+		td, err := sv.RefreshSession("old-token")
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if td.SessionID != "sess-1" || td.Role != "owner" || *td.RefreshToken == "old-token" {
+			t.Fatalf("unexpected token %+v", td)
+		}
+		if nextHash != middlewares.HashRefreshToken(*td.RefreshToken) {
+			t.Fatalf("rotated hash does not match the new refresh token")
+		}
+	})
+
+	t.Run("reusing the previous token closes the session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockSession := newService(ctrl)
+
+		hash := middlewares.HashRefreshToken("old-token")
+		current := session(middlewares.HashRefreshToken("new-token"))
+		current.PreviousHash = hash
+		mockSession.EXPECT().FindByTokenHash(hash).Return(current, nil)
+		mockSession.EXPECT().Revoke("sess-1")
+
+		if _, err := sv.RefreshSession("old-token"); !errors.Is(err, ErrRefreshTokenReused) {
+			t.Fatalf("expected ErrRefreshTokenReused, got %v", err)
+		}
+	})
+
+	t.Run("refresh is refused", func(t *testing.T) {
+		hash := middlewares.HashRefreshToken("token")
+		revokedAt := time.Now().Add(-time.Minute)
+		revoked := session(hash)
+		revoked.RevokedAt = &revokedAt
+		expired := session(hash)
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+		cases := []struct {
+			name    string
+			found   *entities.SessionModel
+			findErr error
+			rotate  bool
+		}{
+			{name: "unknown token", findErr: db.ErrNotFound},
+			{name: "revoked session", found: revoked},
+			{name: "expired session", found: expired},
+			{name: "lost the race to another refresh", found: session(hash), rotate: true},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+				sv, mockSession := newService(ctrl)
+
+				mockSession.EXPECT().FindByTokenHash(hash).Return(tc.found, tc.findErr)
+				if tc.rotate {
+					mockSession.EXPECT().Rotate("sess-1", hash, gomock.Any(), gomock.Any()).Return(false, nil)
+				}
+
+				if _, err := sv.RefreshSession("token"); !errors.Is(err, ErrInvalidRefreshToken) {
+					t.Fatalf("expected ErrInvalidRefreshToken, got %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("access token follows its session", func(t *testing.T) {
+		revokedAt := time.Now()
+		revoked := session("h")
+		revoked.RevokedAt = &revokedAt
+		access := &middlewares.TokenDetails{UserID: "user-1", Role: "owner", Purpose: "access", SessionID: "sess-1"}
+
+		cases := []struct {
+			name    string
+			token   *middlewares.TokenDetails
+			found   *entities.SessionModel
+			findErr error
+			wantErr error
+		}{
+			{name: "open session", token: access, found: session("h")},
+			{name: "logged out", token: access, found: revoked, wantErr: ErrSessionRevoked},
+			{name: "user deleted", token: access, findErr: db.ErrNotFound, wantErr: ErrSessionRevoked},
+			{name: "session of another role", token: &middlewares.TokenDetails{UserID: "user-1", Role: "admin", SessionID: "sess-1"}, found: session("h"), wantErr: ErrSessionRevoked},
+			{name: "token without a session", token: &middlewares.TokenDetails{UserID: "user-1", Role: "owner"}, wantErr: ErrSessionRevoked},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+				sv, mockSession := newService(ctrl)
+
+				if tc.token.SessionID != "" {
+					mockSession.EXPECT().FindByID(tc.token.SessionID).Return(tc.found, tc.findErr)
+				}
+
+				if err := sv.CheckSession(tc.token); !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+			})
+		}
+	})
+}
+
+func TestUsersService_PasswordChangeRevokesSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsers := mocks.NewMockIUsersRepository(ctrl)
+	mockSession := mocks.NewMockISessionRepository(ctrl)
+	svc := &UsersService{UsersRepository: mockUsers, SessionRepository: mockSession}
+
+	hash := "new-hash"
+	req := entities.UpdateUserModel{Password: &hash}
+	gomock.InOrder(
+		mockUsers.EXPECT().UpdateByID("user-1", req).Return(&entities.UserDataModel{UserID: "user-1"}, nil),
+		mockSession.EXPECT().RevokeByUserID("user-1").Return(3, nil),
+	)
+
+	if _, err := svc.UpdateUsersByID("user-1", req); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
@@ -0,0 +1,482 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/utils"
+)
+
+var (
+	ErrInvalidSchedule = errors.New("invalid working schedule")
+	ErrNotStaff        = errors.New("user is not a caretaker or doctor")
+)
+
+const (
+	dayLength   = 24 * time.Hour
+	clockLayout = "15:04"
+
+	scheduleSourceWeekly  = "weekly"
+	scheduleSourceProfile = "profile"
+	scheduleSourceDefault = "default"
+)
+
+// clockRange is a working period as offsets from midnight. end can be dayLength.
+type clockRange struct {
+	start, end time.Duration
+}
+
+// indexed by time.Weekday
+type weeklyHours [7][]clockRange
+
+// เวลาทำงานเดิมที่เคย hardcode ไว้ ใช้กับ staff ที่ยังไม่ได้ตั้งอะไรเลย
+var defaultWorkingHours = clockRange{start: 8 * time.Hour, end: 17 * time.Hour}
+
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse(clockLayout, value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatClock(d time.Duration) string {
+	d %= dayLength
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// clockOf is the time of day of t in business time.
+func clockOf(t time.Time) time.Duration {
+	t = t.In(utils.BusinessLocation)
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
+
+// profileHours turns start/end_working_time into ranges. An end before the start
+// crosses midnight.
+func profileHours(start, end time.Duration) []clockRange {
+	if end == 0 {
+		end = dayLength
+	}
+	if start < end {
+		return []clockRange{{start: start, end: end}}
+	}
+	return []clockRange{{start: 0, end: end}, {start: start, end: dayLength}}
+}
+
+// staffWeeklyHours picks the weekly schedule if there is one, else the working
+// time on the caretaker/doctor profile, else the old 08:00-17:00.
+func (s *ServiceService) staffWeeklyHours(staffID string) (string, weeklyHours, error) {
+	shifts, err := s.ScheduleRepo.FindByStaffID(staffID)
+	if err != nil {
+		return "", weeklyHours{}, err
+	}
+	if len(shifts) > 0 {
+		return weeklyHoursFrom(shifts, time.Time{}, time.Time{})
+	}
+
+	staff, err := s.UserRepo.FindByID(staffID)
+	if err != nil {
+		return "", weeklyHours{}, err
+	}
+
+	return weeklyHoursFrom(nil, staff.StartWorkTime, staff.EndWorkTime)
+}
+
+func weeklyHoursFrom(shifts []entities.StaffShiftModel, profileStart, profileEnd time.Time) (string, weeklyHours, error) {
+	var hours weeklyHours
+
+	if len(shifts) > 0 {
+		for _, shift := range shifts {
+			r, err := shiftRange(shift)
+			if err != nil {
+				return "", hours, err
+			}
+			hours[shift.Weekday] = append(hours[shift.Weekday], r)
+		}
+		for day := range hours {
+			slices.SortFunc(hours[day], func(a, b clockRange) int { return int(a.start - b.start) })
+		}
+		return scheduleSourceWeekly, hours, nil
+	}
+
+	source, ranges := scheduleSourceDefault, []clockRange{defaultWorkingHours}
+	if start, end := clockOf(profileStart), clockOf(profileEnd); start != end {
+		source, ranges = scheduleSourceProfile, profileHours(start, end)
+	}
+	for day := range hours {
+		hours[day] = ranges
+	}
+
+	return source, hours, nil
+}
+
+// staffCalendar is the weekly hours of one staff together with the exceptions of
+// the period being looked at.
+type staffCalendar struct {
+	weekly      weeklyHours
+	extra       []entities.TimeRangeModel
+	unavailable []entities.TimeRangeModel
+}
+
+func newStaffCalendar(weekly weeklyHours, exceptions []entities.StaffScheduleExceptionModel) staffCalendar {
+	calendar := staffCalendar{weekly: weekly}
+	for _, exception := range exceptions {
+		r := entities.TimeRangeModel{Start: exception.Start, End: exception.End}
+		switch exception.Kind {
+		case db.ScheduleExceptionKindExtra:
+			calendar.extra = append(calendar.extra, r)
+		case db.ScheduleExceptionKindUnavailable:
+			calendar.unavailable = append(calendar.unavailable, r)
+		}
+	}
+	calendar.unavailable = mergeRanges(calendar.unavailable)
+	return calendar
+}
+
+// working is the weekly hours of the day plus any extra shift on it.
+func (c staffCalendar) working(day time.Time) []entities.TimeRangeModel {
+	day = startOfDay(day)
+	return mergeRanges(append(workingRanges(day, c.weekly), clipRanges(c.extra, day, day.Add(dayLength))...))
+}
+
+// covers tells whether all of start..end is working time. Ranges of consecutive days
+// are merged first, so a booking can run past midnight into the next day's shift.
+func (c staffCalendar) covers(start, end time.Time) bool {
+	var working []entities.TimeRangeModel
+	for day := startOfDay(start); day.Before(end); day = day.Add(dayLength) {
+		working = append(working, c.working(day)...)
+	}
+	for _, r := range mergeRanges(working) {
+		if !r.Start.After(start) && !r.End.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWorkingHours fails with ErrStaffUnavailable when the booking falls outside the
+// staff's working time. An extra shift counts as working time, so it can be booked even
+// on a day off. Bookings, leave and unavailable periods are checked by the repository
+// (HasConflict/GuardSlotTx), this only covers the weekly hours and extra shifts.
+func (s *ServiceService) checkWorkingHours(staffID string, start, end time.Time) error {
+	calendar, err := s.loadStaffCalendar(staffID, startOfDay(start), startOfDay(end).Add(dayLength))
+	if err != nil {
+		return err
+	}
+	if !calendar.covers(start, end) {
+		return fmt.Errorf("%w: outside working hours", ErrStaffUnavailable)
+	}
+	return nil
+}
+
+func (s *ServiceService) loadStaffCalendar(staffID string, from, to time.Time) (staffCalendar, error) {
+	_, weekly, err := s.staffWeeklyHours(staffID)
+	if err != nil {
+		return staffCalendar{}, err
+	}
+	exceptions, err := s.ScheduleRepo.FindExceptions([]string{staffID}, from, to)
+	if err != nil {
+		return staffCalendar{}, err
+	}
+
+	return newStaffCalendar(weekly, exceptions), nil
+}
+
+// shiftRange parses a weekly shift. end_time "00:00" means midnight at the end of the day.
+func shiftRange(shift entities.StaffShiftModel) (clockRange, error) {
+	if shift.Weekday < 0 || shift.Weekday > 6 {
+		return clockRange{}, fmt.Errorf("%w: weekday %d", ErrInvalidSchedule, shift.Weekday)
+	}
+	start, err := parseClock(shift.StartTime)
+	if err != nil {
+		return clockRange{}, fmt.Errorf("%w: start_time %q", ErrInvalidSchedule, shift.StartTime)
+	}
+	end, err := parseClock(shift.EndTime)
+	if err != nil {
+		return clockRange{}, fmt.Errorf("%w: end_time %q", ErrInvalidSchedule, shift.EndTime)
+	}
+	if end == 0 {
+		end = dayLength
+	}
+	if start >= end {
+		return clockRange{}, fmt.Errorf("%w: %s-%s ends before it starts", ErrInvalidSchedule, shift.StartTime, shift.EndTime)
+	}
+
+	return clockRange{start: start, end: end}, nil
+}
+
+func (s *ServiceService) FindStaffSchedule(staffID string) (*entities.StaffScheduleModel, error) {
+	source, hours, err := s.staffWeeklyHours(staffID)
+	if err != nil {
+		return nil, fmt.Errorf("service -> FindStaffSchedule: %w", err)
+	}
+
+	schedule := &entities.StaffScheduleModel{StaffID: staffID, Source: source, Shifts: []entities.StaffShiftModel{}}
+	for weekday, ranges := range hours {
+		for _, r := range ranges {
+			schedule.Shifts = append(schedule.Shifts, entities.StaffShiftModel{
+				Weekday:   weekday,
+				StartTime: formatClock(r.start),
+				EndTime:   formatClock(r.end),
+			})
+		}
+	}
+
+	return schedule, nil
+}
+
+// UpdateStaffSchedule replaces the weekly schedule. Shifts of the same weekday
+// must not overlap; an empty list falls back to the profile working time.
+func (s *ServiceService) UpdateStaffSchedule(staffID string, data entities.UpdateStaffScheduleRequest) (*entities.StaffScheduleModel, error) {
+	staff, err := s.UserRepo.FindByID(staffID)
+	if err != nil {
+		return nil, fmt.Errorf("service -> UpdateStaffSchedule: %w", err)
+	}
+	if !IsStaff(staff) {
+		return nil, fmt.Errorf("service -> UpdateStaffSchedule: %w", ErrNotStaff)
+	}
+
+	var hours weeklyHours
+	shifts := make([]entities.StaffShiftModel, 0, len(data.Shifts))
+	for _, req := range data.Shifts {
+		shift := entities.StaffShiftModel{Weekday: *req.Weekday, StartTime: req.StartTime, EndTime: req.EndTime}
+		r, err := shiftRange(shift)
+		if err != nil {
+			return nil, fmt.Errorf("service -> UpdateStaffSchedule: %w", err)
+		}
+		for _, other := range hours[shift.Weekday] {
+			if r.start < other.end && other.start < r.end {
+				return nil, fmt.Errorf("service -> UpdateStaffSchedule: %w: shifts overlap on weekday %d", ErrInvalidSchedule, shift.Weekday)
+			}
+		}
+		hours[shift.Weekday] = append(hours[shift.Weekday], r)
+		shifts = append(shifts, shift)
+	}
+
+	tx := s.UnitOfWork.Begin()
+	if err := s.ScheduleRepo.ReplaceTx(tx, staffID, shifts); err != nil {
+		return nil, fmt.Errorf("service -> UpdateStaffSchedule: %w", err)
+	}
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("service -> UpdateStaffSchedule: %w", err)
+	}
+
+	return s.FindStaffSchedule(staffID)
+}
+
+// startOfDay is midnight (business time) of the day t falls on.
+func startOfDay(t time.Time) time.Time {
+	t = t.In(utils.BusinessLocation)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, utils.BusinessLocation)
+}
+
+// businessDate is the business day of t in the form of a @db.Date column (midnight UTC),
+// for comparing with leave days, follow-up and vaccination dates.
+func businessDate(t time.Time) time.Time {
+	t = t.In(utils.BusinessLocation)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// workingRanges places the weekly hours on a calendar day in business time.
+func workingRanges(day time.Time, hours weeklyHours) []entities.TimeRangeModel {
+	day = startOfDay(day)
+	ranges := make([]entities.TimeRangeModel, 0, len(hours[day.Weekday()]))
+	for _, r := range hours[day.Weekday()] {
+		ranges = append(ranges, entities.TimeRangeModel{Start: day.Add(r.start), End: day.Add(r.end)})
+	}
+	return ranges
+}
+
+// mergeRanges sorts ranges and joins the ones that overlap or touch.
+func mergeRanges(ranges []entities.TimeRangeModel) []entities.TimeRangeModel {
+	sorted := slices.Clone(ranges)
+	slices.SortFunc(sorted, func(a, b entities.TimeRangeModel) int { return a.Start.Compare(b.Start) })
+
+	merged := []entities.TimeRangeModel{}
+	for _, r := range sorted {
+		if !r.Start.Before(r.End) {
+			continue
+		}
+		if last := len(merged) - 1; last >= 0 && !r.Start.After(merged[last].End) {
+			if r.End.After(merged[last].End) {
+				merged[last].End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// clipRanges keeps the parts of ranges inside [start, end).
+func clipRanges(ranges []entities.TimeRangeModel, start, end time.Time) []entities.TimeRangeModel {
+	clipped := []entities.TimeRangeModel{}
+	for _, r := range ranges {
+		r.Start, r.End = utils.MaxTime(r.Start, start), utils.MinTime(r.End, end)
+		if r.Start.Before(r.End) {
+			clipped = append(clipped, r)
+		}
+	}
+	return clipped
+}
+
+// subtractRanges removes busy (merged) from each of the working ranges.
+func subtractRanges(working, busy []entities.TimeRangeModel) []entities.TimeRangeModel {
+	free := []entities.TimeRangeModel{}
+	for _, w := range working {
+		cursor := w.Start
+		for _, b := range busy {
+			if !b.End.After(cursor) || !b.Start.Before(w.End) {
+				continue
+			}
+			if b.Start.After(cursor) {
+				free = append(free, entities.TimeRangeModel{Start: cursor, End: b.Start})
+			}
+			cursor = b.End
+		}
+		if cursor.Before(w.End) {
+			free = append(free, entities.TimeRangeModel{Start: cursor, End: w.End})
+		}
+	}
+	return free
+}
+
+// FindFreeTimeSlot lays out each day between from and to: the staff's working
+// ranges (weekly hours and extra shifts), what blocks them (bookings, holds, leave
+// days, unavailable periods) and what is left to book. Time before now is never free.
+func (s *ServiceService) FindFreeTimeSlot(staffID string, from, to, now time.Time) ([]*entities.StaffDaySlotsModel, error) {
+	first, last := startOfDay(from), startOfDay(to).Add(dayLength)
+	calendar, err := s.loadStaffCalendar(staffID, first, last)
+	if err != nil {
+		return nil, fmt.Errorf("service -> FindFreeTimeSlot: %w", err)
+	}
+	busy, err := s.StaffHoldRepo.FindBusyRanges(staffID, first, last)
+	if err != nil {
+		return nil, fmt.Errorf("service -> FindFreeTimeSlot: %w", err)
+	}
+	busy = mergeRanges(busy)
+
+	days := []*entities.StaffDaySlotsModel{}
+	for day := first; day.Before(last); day = day.Add(dayLength) {
+		working := calendar.working(day)
+		free := subtractRanges(working, busy)
+		days = append(days, &entities.StaffDaySlotsModel{
+			Date:    day.Format("2006-01-02"),
+			Working: working,
+			Busy:    clipRanges(busy, day, day.Add(dayLength)),
+			Free:    clipRanges(free, now, day.Add(dayLength)),
+		})
+	}
+
+	return days, nil
+}
+
+// filterBySchedule drops staff that have no bookable working time left on one of
+// the days between start and end, because of their weekly hours or an unavailable
+// period. Bookings and leave days were already filtered by the repository.
+func (s *ServiceService) filterBySchedule(staff []*entities.AvailableStaffResponse, start, end time.Time) ([]*entities.AvailableStaffResponse, error) {
+	if len(staff) == 0 {
+		return staff, nil
+	}
+
+	ids := make([]string, 0, len(staff))
+	for _, st := range staff {
+		ids = append(ids, st.ID)
+	}
+	// partial ส่ง start เป็นตอนจบวันแรก ซึ่งอาจมากกว่า end
+	first, last := startOfDay(utils.MinTime(start, end)), startOfDay(utils.MaxTime(start, end)).Add(dayLength)
+
+	shifts, err := s.ScheduleRepo.FindByStaffIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := s.ScheduleRepo.FindExceptions(ids, first, last)
+	if err != nil {
+		return nil, err
+	}
+	byStaff := make(map[string][]entities.StaffScheduleExceptionModel)
+	for _, exception := range exceptions {
+		byStaff[exception.StaffID] = append(byStaff[exception.StaffID], exception)
+	}
+
+	available := make([]*entities.AvailableStaffResponse, 0, len(staff))
+	for _, st := range staff {
+		_, weekly, err := weeklyHoursFrom(shifts[st.ID], st.StartWorkTime, st.EndWorkTime)
+		if err != nil {
+			return nil, err
+		}
+		calendar := newStaffCalendar(weekly, byStaff[st.ID])
+
+		ok := true
+		for day := first; ok && day.Before(last); day = day.Add(dayLength) {
+			ok = len(subtractRanges(calendar.working(day), calendar.unavailable)) > 0
+		}
+		if ok {
+			available = append(available, st)
+		}
+	}
+
+	return available, nil
+}
+
+// CreateScheduleException adds an extra shift or an unavailable period for the staff.
+func (s *ServiceService) CreateScheduleException(staffID, actorID string, data entities.CreateScheduleExceptionRequest) (*entities.StaffScheduleExceptionModel, error) {
+	staff, err := s.UserRepo.FindByID(staffID)
+	if err != nil {
+		return nil, fmt.Errorf("service -> CreateScheduleException: %w", err)
+	}
+	if !IsStaff(staff) {
+		return nil, fmt.Errorf("service -> CreateScheduleException: %w", ErrNotStaff)
+	}
+	if !data.Start.Before(data.End) {
+		return nil, fmt.Errorf("service -> CreateScheduleException: %w: end must be after start", ErrInvalidSchedule)
+	}
+
+	exception, err := s.ScheduleRepo.InsertException(entities.StaffScheduleExceptionModel{
+		StaffID:   staffID,
+		Kind:      db.ScheduleExceptionKind(data.Kind),
+		Start:     data.Start,
+		End:       data.End,
+		Note:      data.Note,
+		CreatedBy: actorID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service -> CreateScheduleException: %w", err)
+	}
+
+	return exception, nil
+}
+
+func (s *ServiceService) FindScheduleExceptions(staffID string, from, to time.Time) ([]entities.StaffScheduleExceptionModel, error) {
+	exceptions, err := s.ScheduleRepo.FindExceptions([]string{staffID}, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("service -> FindScheduleExceptions: %w", err)
+	}
+	return exceptions, nil
+}
+
+// DeleteScheduleException removes an exception of the staff. Another staff's
+// exception is reported as not found.
+func (s *ServiceService) DeleteScheduleException(staffID, exceptionID string) error {
+	exception, err := s.ScheduleRepo.FindExceptionByID(exceptionID)
+	if err != nil {
+		return fmt.Errorf("service -> DeleteScheduleException: %w", err)
+	}
+	if exception.StaffID != staffID {
+		return fmt.Errorf("service -> DeleteScheduleException: %w", db.ErrNotFound)
+	}
+
+	if err := s.ScheduleRepo.DeleteException(exceptionID); err != nil {
+		return fmt.Errorf("service -> DeleteScheduleException: %w", err)
+	}
+	return nil
+}
+
+// IsStaff บอกว่าบัญชีนี้เป็น caretaker หรือ doctor อยู่ด้วยไหม (อาจเป็น owner ด้วยก็ได้)
+func IsStaff(user *entities.UserDataModel) bool {
+	return slices.Contains(user.Roles, db.RoleCaretaker) || slices.Contains(user.Roles, db.RoleDoctor)
+}
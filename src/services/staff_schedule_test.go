@@ -0,0 +1,288 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/src/services/mocks"
+	"lama-backend/src/utils"
+)
+
+// 2026-03-02 is a Monday
+var scheduleMonday = time.Date(2026, 3, 2, 0, 0, 0, 0, utils.BusinessLocation)
+
+func clockOn(day time.Time, hour, minute int) time.Time {
+	return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+func splitShiftMonday() []entities.StaffShiftModel {
+	return []entities.StaffShiftModel{
+		{Weekday: 1, StartTime: "13:00", EndTime: "17:00"},
+		{Weekday: 1, StartTime: "08:00", EndTime: "12:00"},
+	}
+}
+
+// allDayShifts works every day around the clock, for tests that are not about working hours.
+func allDayShifts() []entities.StaffShiftModel {
+	shifts := make([]entities.StaffShiftModel, 7)
+	for day := range shifts {
+		shifts[day] = entities.StaffShiftModel{Weekday: day, StartTime: "00:00", EndTime: "00:00"}
+	}
+	return shifts
+}
+
+// expectShifts answers the lookups of checkWorkingHours for one staff.
+func expectShifts(m *mocks.MockIStaffScheduleRepository, staffID string, shifts []entities.StaffShiftModel, exceptions ...entities.StaffScheduleExceptionModel) {
+	m.EXPECT().FindByStaffID(staffID).Return(shifts, nil)
+	m.EXPECT().FindExceptions([]string{staffID}, gomock.Any(), gomock.Any()).Return(exceptions, nil)
+}
+
+func TestServiceService_FindFreeTimeSlot(t *testing.T) {
+	monday, tuesday := scheduleMonday, scheduleMonday.Add(dayLength)
+	busy := []entities.TimeRangeModel{
+		{Start: clockOn(monday, 11, 30), End: clockOn(monday, 13, 30)},
+		{Start: clockOn(monday, 9, 0), End: clockOn(monday, 10, 0)},
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		wantFree []entities.TimeRangeModel
+	}{
+		{
+			name: "free is working minus busy",
+			now:  monday.Add(-dayLength),
+			wantFree: []entities.TimeRangeModel{
+				{Start: clockOn(monday, 8, 0), End: clockOn(monday, 9, 0)},
+				{Start: clockOn(monday, 10, 0), End: clockOn(monday, 11, 30)},
+				{Start: clockOn(monday, 13, 30), End: clockOn(monday, 17, 0)},
+			},
+		},
+		{
+			name: "past time is not free",
+			now:  clockOn(monday, 10, 30),
+			wantFree: []entities.TimeRangeModel{
+				{Start: clockOn(monday, 10, 30), End: clockOn(monday, 11, 30)},
+				{Start: clockOn(monday, 13, 30), End: clockOn(monday, 17, 0)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+			mockHold := mocks.NewMockIStaffHoldRepository(ctrl)
+			sv := &ServiceService{ScheduleRepo: mockSchedule, StaffHoldRepo: mockHold}
+
+			mockSchedule.EXPECT().FindByStaffID("care-1").Return(splitShiftMonday(), nil)
+			mockSchedule.EXPECT().FindExceptions([]string{"care-1"}, monday, tuesday.Add(dayLength)).
+				Return([]entities.StaffScheduleExceptionModel{
+					{StaffID: "care-1", Kind: db.ScheduleExceptionKindExtra, Start: clockOn(tuesday, 10, 0), End: clockOn(tuesday, 12, 0)},
+				}, nil)
+			mockHold.EXPECT().FindBusyRanges("care-1", monday, tuesday.Add(dayLength)).Return(busy, nil)
+
+			days, err := sv.FindFreeTimeSlot("care-1", monday, clockOn(tuesday, 23, 59), tt.now)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if len(days) != 2 || days[0].Date != "2026-03-02" || days[1].Date != "2026-03-03" {
+				t.Fatalf("want monday and tuesday got %+v", days)
+			}
+			if !reflect.DeepEqual(days[0].Free, tt.wantFree) {
+				t.Fatalf("want free %+v got %+v", tt.wantFree, days[0].Free)
+			}
+			wantWorking := []entities.TimeRangeModel{
+				{Start: clockOn(monday, 8, 0), End: clockOn(monday, 12, 0)},
+				{Start: clockOn(monday, 13, 0), End: clockOn(monday, 17, 0)},
+			}
+			if !reflect.DeepEqual(days[0].Working, wantWorking) {
+				t.Fatalf("want working %+v got %+v", wantWorking, days[0].Working)
+			}
+			// อังคารไม่มีกะประจำ มีแต่กะเพิ่ม
+			wantExtra := []entities.TimeRangeModel{{Start: clockOn(tuesday, 10, 0), End: clockOn(tuesday, 12, 0)}}
+			if !reflect.DeepEqual(days[1].Working, wantExtra) || !reflect.DeepEqual(days[1].Free, wantExtra) {
+				t.Fatalf("tuesday should only have the extra shift, got %+v", days[1])
+			}
+		})
+	}
+}
+
+func TestServiceService_FindStaffSchedule_Fallback(t *testing.T) {
+	clock := func(hour int) time.Time { return time.Date(1970, 1, 1, hour, 0, 0, 0, utils.BusinessLocation) }
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		wantSource string
+		wantDay    []entities.StaffShiftModel
+	}{
+		{
+			name: "profile hours every day", start: clock(9), end: clock(18), wantSource: "profile",
+			wantDay: []entities.StaffShiftModel{{StartTime: "09:00", EndTime: "18:00"}},
+		},
+		{
+			name: "profile night shift crosses midnight", start: clock(22), end: clock(6), wantSource: "profile",
+			wantDay: []entities.StaffShiftModel{{StartTime: "00:00", EndTime: "06:00"}, {StartTime: "22:00", EndTime: "00:00"}},
+		},
+		{
+			name: "nothing set keeps 8-17", start: clock(0), end: clock(0), wantSource: "default",
+			wantDay: []entities.StaffShiftModel{{StartTime: "08:00", EndTime: "17:00"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+			mockUsers := mocks.NewMockIUsersRepository(ctrl)
+			sv := &ServiceService{ScheduleRepo: mockSchedule, UserRepo: mockUsers}
+
+			mockSchedule.EXPECT().FindByStaffID("doc-1").Return([]entities.StaffShiftModel{}, nil)
+			mockUsers.EXPECT().FindByID("doc-1").
+				Return(&entities.UserDataModel{UserID: "doc-1", Roles: []db.Role{db.RoleDoctor}, StartWorkTime: tt.start, EndWorkTime: tt.end}, nil)
+
+			schedule, err := sv.FindStaffSchedule("doc-1")
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if schedule.Source != tt.wantSource {
+				t.Fatalf("want source %s got %s", tt.wantSource, schedule.Source)
+			}
+			if len(schedule.Shifts) != 7*len(tt.wantDay) {
+				t.Fatalf("want %d shifts got %+v", 7*len(tt.wantDay), schedule.Shifts)
+			}
+			for i, want := range tt.wantDay {
+				got := schedule.Shifts[i]
+				if got.Weekday != 0 || got.StartTime != want.StartTime || got.EndTime != want.EndTime {
+					t.Fatalf("want sunday %+v got %+v", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestServiceService_UpdateStaffSchedule_Invalid(t *testing.T) {
+	monday := 1
+	tests := []struct {
+		name    string
+		roles   []db.Role
+		shifts  []entities.StaffShiftRequest
+		wantErr error
+	}{
+		{
+			name: "overlapping shifts", roles: []db.Role{db.RoleCaretaker}, wantErr: ErrInvalidSchedule,
+			shifts: []entities.StaffShiftRequest{
+				{Weekday: &monday, StartTime: "08:00", EndTime: "12:00"},
+				{Weekday: &monday, StartTime: "11:00", EndTime: "15:00"},
+			},
+		},
+		{
+			name: "ends before it starts", roles: []db.Role{db.RoleCaretaker}, wantErr: ErrInvalidSchedule,
+			shifts: []entities.StaffShiftRequest{{Weekday: &monday, StartTime: "17:00", EndTime: "08:00"}},
+		},
+		{
+			name: "owner has no schedule", roles: []db.Role{db.RoleOwner}, wantErr: ErrNotStaff,
+			shifts: []entities.StaffShiftRequest{{Weekday: &monday, StartTime: "08:00", EndTime: "12:00"}},
+		},
+		{
+			// ผ่านด่าน staff แล้วไปตกที่กะเวลา
+			name: "owner who is also a caretaker", roles: []db.Role{db.RoleOwner, db.RoleCaretaker}, wantErr: ErrInvalidSchedule,
+			shifts: []entities.StaffShiftRequest{{Weekday: &monday, StartTime: "17:00", EndTime: "08:00"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsers := mocks.NewMockIUsersRepository(ctrl)
+			sv := &ServiceService{UserRepo: mockUsers}
+
+			mockUsers.EXPECT().FindByID("u1").Return(&entities.UserDataModel{UserID: "u1", Roles: tt.roles}, nil)
+
+			_, err := sv.UpdateStaffSchedule("u1", entities.UpdateStaffScheduleRequest{Shifts: tt.shifts})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want %v got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestServiceService_FindBusyTimeSlot_WorkingHours(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCaretaker := mocks.NewMockICaretakerRepository(ctrl)
+	mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+	sv := &ServiceService{CaretakerRepo: mockCaretaker, ScheduleRepo: mockSchedule}
+
+	dayEnd := clockOn(scheduleMonday, 23, 59)
+	mockCaretaker.EXPECT().FindBusyTimeSlot("care-1", scheduleMonday, dayEnd, scheduleMonday, dayEnd).
+		Return(&[]db.ServiceModel{{InnerService: db.InnerService{RdateStart: clockOn(scheduleMonday, 7, 0), RdateEnd: clockOn(scheduleMonday, 14, 0)}}}, nil)
+	mockSchedule.EXPECT().FindByStaffID("care-1").Return(splitShiftMonday(), nil)
+	mockSchedule.EXPECT().FindExceptions([]string{"care-1"}, scheduleMonday, dayEnd).
+		Return([]entities.StaffScheduleExceptionModel{
+			{StaffID: "care-1", Kind: db.ScheduleExceptionKindUnavailable, Start: clockOn(scheduleMonday, 15, 0), End: clockOn(scheduleMonday, 16, 0)},
+		}, nil)
+
+	got, err := sv.FindBusyTimeSlot("cservice", "care-1", scheduleMonday, dayEnd, scheduleMonday, dayEnd)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := map[string][]string{"2026-03-02": {"08:00", "09:00", "10:00", "11:00", "13:00", "15:00"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v got %v", want, got)
+	}
+}
+
+func TestServiceService_FindAvailableStaff_Schedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDoctor := mocks.NewMockIDoctorRepository(ctrl)
+	mockSchedule := mocks.NewMockIStaffScheduleRepository(ctrl)
+	sv := &ServiceService{DoctorRepo: mockDoctor, ScheduleRepo: mockSchedule}
+
+	start, end := scheduleMonday, clockOn(scheduleMonday, 23, 59)
+	nine, five := time.Date(1970, 1, 1, 9, 0, 0, 0, utils.BusinessLocation), time.Date(1970, 1, 1, 17, 0, 0, 0, utils.BusinessLocation)
+	mockDoctor.EXPECT().FindAvailableDoctor(start, end).Return([]*entities.AvailableStaffResponse{
+		{ID: "works-monday"},
+		{ID: "off-monday"},
+		{ID: "unavailable-all-shift", StartWorkTime: nine, EndWorkTime: five},
+		{ID: "unavailable-morning", StartWorkTime: nine, EndWorkTime: five},
+	}, nil)
+	mockSchedule.EXPECT().FindByStaffIDs([]string{"works-monday", "off-monday", "unavailable-all-shift", "unavailable-morning"}).
+		Return(map[string][]entities.StaffShiftModel{
+			"works-monday": splitShiftMonday(),
+			"off-monday":   {{Weekday: 2, StartTime: "08:00", EndTime: "17:00"}},
+		}, nil)
+	mockSchedule.EXPECT().FindExceptions(gomock.Any(), scheduleMonday, scheduleMonday.Add(dayLength)).
+		Return([]entities.StaffScheduleExceptionModel{
+			{StaffID: "unavailable-all-shift", Kind: db.ScheduleExceptionKindUnavailable, Start: clockOn(scheduleMonday, 8, 0), End: clockOn(scheduleMonday, 18, 0)},
+			{StaffID: "unavailable-morning", Kind: db.ScheduleExceptionKindUnavailable, Start: clockOn(scheduleMonday, 9, 0), End: clockOn(scheduleMonday, 12, 0)},
+		}, nil)
+
+	staff, err := sv.FindAvailableStaff("mservice", start, end)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	var got []string
+	for _, st := range staff {
+		got = append(got, st.ID)
+	}
+	if want := []string{"works-monday", "unavailable-morning"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v got %v", want, got)
+	}
+}
@@ -1,8 +1,16 @@
 package services
 
 import (
+	"context"
+	"fmt"
+	"log"
+
 	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
 	"lama-backend/domain/repositories"
+	"lama-backend/src/storage"
+
+	"github.com/google/uuid"
 )
 
 type UsersService struct {
@@ -10,6 +18,9 @@ type UsersService struct {
 	OwnerRepository     repositories.IOwnerRepository
 	CaretakerRepository repositories.ICaretakerRepository
 	DoctorRepository    repositories.IDoctorRepository
+	SessionRepository   repositories.ISessionRepository
+	// bucket รูปโปรไฟล์ เป็น public เพราะลิงก์เก็บลง users.profile ตรงๆ
+	ProfileStore storage.PublicStore
 }
 
 type IUsersService interface {
@@ -17,14 +28,17 @@ type IUsersService interface {
 	FindAllUsers(role string, page, limit int) ([]*entities.UserDataModel, error)
 	DeleteUsersByID(id string) (*entities.UserDataModel, error)
 	UpdateUsersByID(id string, data entities.UpdateUserModel) (*entities.UserDataModel, error)
+	UpdateProfilePicture(id string, file entities.AttachmentUpload) (*entities.UserDataModel, error)
 }
 
-func NewUsersService(repoUsers repositories.IUsersRepository, repoOwner repositories.IOwnerRepository, repoCaretaker repositories.ICaretakerRepository, repoDoctor repositories.IDoctorRepository) IUsersService {
+func NewUsersService(repoUsers repositories.IUsersRepository, repoOwner repositories.IOwnerRepository, repoCaretaker repositories.ICaretakerRepository, repoDoctor repositories.IDoctorRepository, repoSession repositories.ISessionRepository, profileStore storage.PublicStore) IUsersService {
 	return &UsersService{
 		UsersRepository:     repoUsers,
 		OwnerRepository:     repoOwner,
 		CaretakerRepository: repoCaretaker,
 		DoctorRepository:    repoDoctor,
+		SessionRepository:   repoSession,
+		ProfileStore:        profileStore,
 	}
 }
 
@@ -49,7 +63,58 @@ func (s *UsersService) DeleteUsersByID(id string) (*entities.UserDataModel, erro
 	return s.UsersRepository.DeleteByID(id)
 }
 
+// UpdateUsersByID also logs the user out everywhere when the password changes.
 func (s *UsersService) UpdateUsersByID(id string, data entities.UpdateUserModel) (*entities.UserDataModel, error) {
-	// You could add validation, logging, or pre-checks here
-	return s.UsersRepository.UpdateByID(id, data)
+	user, err := s.UsersRepository.UpdateByID(id, data)
+	if err != nil {
+		return nil, err
+	}
+	if data.Password != nil {
+		if _, err := s.SessionRepository.RevokeByUserID(id); err != nil {
+			return nil, fmt.Errorf("users -> UpdateUsersByID: password changed but sessions are still open: %v", err)
+		}
+	}
+	return user, nil
+}
+
+// UpdateProfilePicture stores a new picture (jpeg, png or webp up to 5 MB) and links it to
+// the user. Older pictures are removed only after the new one is saved, so a failed upload
+// keeps the current picture.
+func (s *UsersService) UpdateProfilePicture(id string, file entities.AttachmentUpload) (*entities.UserDataModel, error) {
+	contentType, ext, body, err := checkUpload(file, attachmentTypes[db.AttachmentKindPhoto], maxPhotoSize)
+	if err != nil {
+		return nil, fmt.Errorf("users -> UpdateProfilePicture: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	key := fmt.Sprintf("%s/%s%s", id, uuid.NewString(), ext)
+	if err := s.ProfileStore.Put(ctx, key, body, file.Size, contentType); err != nil {
+		return nil, fmt.Errorf("users -> UpdateProfilePicture: %v", err)
+	}
+
+	profile := s.ProfileStore.PublicURL(key)
+	user, err := s.UsersRepository.UpdateByID(id, entities.UpdateUserModel{Profile: &profile})
+	if err != nil {
+		if delErr := s.ProfileStore.Delete(ctx, key); delErr != nil {
+			log.Printf("users -> UpdateProfilePicture: %s left in storage: %v", key, delErr)
+		}
+		return nil, err
+	}
+
+	old, err := s.ProfileStore.List(ctx, id+"/")
+	if err != nil {
+		log.Printf("users -> UpdateProfilePicture: cannot list old pictures of %s: %v", id, err)
+		return user, nil
+	}
+	for _, object := range old {
+		if object.Key == key {
+			continue
+		}
+		if err := s.ProfileStore.Delete(ctx, object.Key); err != nil {
+			log.Printf("users -> UpdateProfilePicture: %s left in storage: %v", object.Key, err)
+		}
+	}
+
+	return user, nil
 }
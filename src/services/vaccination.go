@@ -0,0 +1,231 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidVaccination   = errors.New("invalid vaccination")
+	ErrVaccinationForbidden = errors.New("not allowed to access the vaccinations of this pet")
+	// ErrReminderClaimed: อีก instance ส่งเตือนชุดนี้ไปแล้ว
+	ErrReminderClaimed = repositories.ErrReminderClaimed
+)
+
+// เตือนล่วงหน้ากี่วันก่อนถึงกำหนด ถ้าไม่ได้ตั้ง VACCINATION_REMINDER_DAYS
+const defaultReminderDays = 7
+
+type VaccinationService struct {
+	Repo        repositories.IVaccinationRepository
+	PetRepo     repositories.IPetRepository
+	ServiceRepo repositories.IServiceRepository
+	UnitOfWork  repositories.IUnitOfWork
+	// doses due within RemindDays from today get a reminder
+	RemindDays   int
+	SendReminder func(due *entities.VaccinationDueModel) error
+}
+
+type IVaccinationService interface {
+	RecordVaccination(petID, userID, role string, data entities.CreateVaccinationRequest) (*entities.VaccinationModel, error)
+	FindPetVaccinations(petID, userID, role string) ([]*entities.VaccinationModel, error)
+	FindDueVaccinations(days int) ([]*entities.VaccinationDueModel, error)
+	ClaimDueRemindersTx(tx *repositories.Tx, now time.Time) ([]*entities.VaccinationDueModel, error)
+	SendReminders(due []*entities.VaccinationDueModel) error
+}
+
+func NewVaccinationService(
+	repo repositories.IVaccinationRepository,
+	petRepo repositories.IPetRepository,
+	serviceRepo repositories.IServiceRepository,
+	unitOfWork repositories.IUnitOfWork,
+) IVaccinationService {
+	remindDays := defaultReminderDays
+	if days, err := strconv.Atoi(os.Getenv("VACCINATION_REMINDER_DAYS")); err == nil && days > 0 {
+		remindDays = days
+	}
+	return &VaccinationService{
+		Repo:        repo,
+		PetRepo:     petRepo,
+		ServiceRepo: serviceRepo,
+		UnitOfWork:  unitOfWork,
+		RemindDays:  remindDays,
+		SendReminder: func(due *entities.VaccinationDueModel) error {
+			return utils.SendVaccinationReminderEmail(due.OwnerEmail, due.OwnerName, due.PetName, due.Vaccine, *due.NextDueDate)
+		},
+	}
+}
+
+// RecordVaccination adds a dose to the pet. Doctors record their own doses, admins may
+// back-fill one for a doctor. When it comes from an mservice the doctor of that service is
+// the one who gave it. Earlier doses of the same vaccine stop being reminded.
+func (s *VaccinationService) RecordVaccination(petID, userID, role string, data entities.CreateVaccinationRequest) (*entities.VaccinationModel, error) {
+	if role != string(db.RoleDoctor) && role != string(db.RoleAdmin) {
+		return nil, fmt.Errorf("vaccination -> RecordVaccination: %w", ErrVaccinationForbidden)
+	}
+	if _, err := s.PetRepo.FindPetByID(petID); err != nil {
+		return nil, err
+	}
+
+	vaccination, err := buildVaccination(petID, userID, data)
+	if err != nil {
+		return nil, fmt.Errorf("vaccination -> RecordVaccination: %w", err)
+	}
+	if role == string(db.RoleDoctor) {
+		vaccination.DoctorID = &userID
+	}
+
+	if data.ServiceID != nil {
+		service, err := s.ServiceRepo.FindByID(*data.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case service.ServiceType != "mservice":
+			return nil, fmt.Errorf("vaccination -> RecordVaccination: %w", ErrNotMedicalService)
+		case service.PetID != petID:
+			return nil, fmt.Errorf("vaccination -> RecordVaccination: %w: service belongs to another pet", ErrInvalidVaccination)
+		case role == string(db.RoleDoctor) && service.StaffID != userID:
+			return nil, fmt.Errorf("vaccination -> RecordVaccination: %w", ErrNotAssignedDoctor)
+		case service.Status != db.ServiceStatusOngoing && service.Status != db.ServiceStatusFinish:
+			return nil, fmt.Errorf("vaccination -> RecordVaccination: %w: visit has not started", ErrInvalidVaccination)
+		}
+		vaccination.DoctorID = &service.StaffID
+	}
+
+	existing, err := s.Repo.FindByPetID(petID)
+	if err != nil {
+		return nil, err
+	}
+	var superseded []string
+	for _, dose := range existing {
+		if dose.Superseded || !strings.EqualFold(dose.Vaccine, vaccination.Vaccine) {
+			continue
+		}
+		// บันทึกย้อนหลังเข็มที่เก่ากว่า เข็มปัจจุบันยังเป็นตัวที่ใช้เตือน
+		if dose.GivenDate.After(vaccination.GivenDate) {
+			vaccination.Superseded = true
+			continue
+		}
+		superseded = append(superseded, dose.ID)
+	}
+
+	tx := s.UnitOfWork.Begin()
+	s.Repo.SupersedeTx(tx, superseded)
+	s.Repo.InsertTx(tx, *vaccination)
+	if err := s.UnitOfWork.Commit(tx); err != nil {
+		return nil, fmt.Errorf("vaccination -> RecordVaccination: %w", err)
+	}
+
+	return s.Repo.FindByID(vaccination.ID)
+}
+
+func buildVaccination(petID, userID string, data entities.CreateVaccinationRequest) (*entities.VaccinationModel, error) {
+	vaccination := &entities.VaccinationModel{
+		ID:        uuid.NewString(),
+		PetID:     petID,
+		ServiceID: data.ServiceID,
+		DoctorID:  data.DoctorID,
+		Vaccine:   strings.TrimSpace(data.Vaccine),
+		Kind:      db.VaccinationKindVaccine,
+		Note:      data.Note,
+		CreatedBy: userID,
+	}
+	if vaccination.Vaccine == "" {
+		return nil, fmt.Errorf("%w: vaccine is empty", ErrInvalidVaccination)
+	}
+	if data.Kind != "" {
+		vaccination.Kind = db.VaccinationKind(data.Kind)
+	}
+
+	givenDate, err := time.Parse(time.DateOnly, data.GivenDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid given_date", ErrInvalidVaccination)
+	}
+	if givenDate.After(businessDate(time.Now())) {
+		return nil, fmt.Errorf("%w: given_date is in the future", ErrInvalidVaccination)
+	}
+	vaccination.GivenDate = givenDate
+
+	if data.NextDueDate != nil {
+		nextDue, err := time.Parse(time.DateOnly, *data.NextDueDate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid next_due_date", ErrInvalidVaccination)
+		}
+		if !nextDue.After(givenDate) {
+			return nil, fmt.Errorf("%w: next_due_date must be after given_date", ErrInvalidVaccination)
+		}
+		vaccination.NextDueDate = &nextDue
+	}
+
+	return vaccination, nil
+}
+
+// FindPetVaccinations lists every dose of the pet, latest first. Owners read their own
+// pets, doctors the pets they have treated, admins any pet.
+func (s *VaccinationService) FindPetVaccinations(petID, userID, role string) ([]*entities.VaccinationModel, error) {
+	pet, err := s.PetRepo.FindPetByID(petID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := canReadPet(s.ServiceRepo, pet, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("vaccination -> FindPetVaccinations: %w", ErrVaccinationForbidden)
+	}
+
+	return s.Repo.FindByPetID(petID)
+}
+
+// FindDueVaccinations lists current doses due within days from today, overdue included.
+func (s *VaccinationService) FindDueVaccinations(days int) ([]*entities.VaccinationDueModel, error) {
+	return s.Repo.FindDue(businessDate(time.Now()).AddDate(0, 0, days), false)
+}
+
+// ClaimDueRemindersTx picks the doses that should be reminded now and stamps them on tx.
+// The emails go out with SendReminders only after tx is committed.
+func (s *VaccinationService) ClaimDueRemindersTx(tx *repositories.Tx, now time.Time) ([]*entities.VaccinationDueModel, error) {
+	due, err := s.Repo.FindDue(businessDate(now).AddDate(0, 0, s.RemindDays), true)
+	if err != nil {
+		return nil, err
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(due))
+	for _, d := range due {
+		ids = append(ids, d.ID)
+	}
+	s.Repo.ClaimRemindersTx(tx, ids, now)
+	return due, nil
+}
+
+// SendReminders emails the owners. A reminder that fails to send is released so the next
+// round tries again.
+func (s *VaccinationService) SendReminders(due []*entities.VaccinationDueModel) error {
+	var errs []error
+	for _, d := range due {
+		if err := s.SendReminder(d); err != nil {
+			errs = append(errs, fmt.Errorf("vaccination %s: %w", d.ID, err))
+			if err := s.Repo.ReleaseReminder(d.ID); err != nil {
+				log.Printf("vaccination -> SendReminders: %v", err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
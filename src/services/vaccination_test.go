@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"lama-backend/domain/entities"
+	"lama-backend/domain/prisma/db"
+	"lama-backend/domain/repositories"
+	"lama-backend/src/services/mocks"
+)
+
+func TestVaccinationService_RecordVaccination(t *testing.T) {
+	visit := &entities.ServiceModel{
+		Sid: "s1", PetID: "pet-1", StaffID: "doc-1", ServiceType: "mservice", Status: db.ServiceStatusOngoing,
+	}
+	serviceID, nextDue := "s1", "2027-03-02"
+	req := entities.CreateVaccinationRequest{
+		Vaccine: "Rabies", GivenDate: "2026-03-02", NextDueDate: &nextDue, ServiceID: &serviceID,
+	}
+
+	newService := func(ctrl *gomock.Controller) (*VaccinationService, *mocks.MockIVaccinationRepository, *mocks.MockIPetRepository, *mocks.MockIServiceRepository, *mocks.MockIUnitOfWork) {
+		mockRepo := mocks.NewMockIVaccinationRepository(ctrl)
+		mockPet := mocks.NewMockIPetRepository(ctrl)
+		mockService := mocks.NewMockIServiceRepository(ctrl)
+		mockUow := mocks.NewMockIUnitOfWork(ctrl)
+		sv := &VaccinationService{Repo: mockRepo, PetRepo: mockPet, ServiceRepo: mockService, UnitOfWork: mockUow}
+		return sv, mockRepo, mockPet, mockService, mockUow
+	}
+
+	t.Run("doctor records a dose from their visit and replaces the previous one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockRepo, mockPet, mockService, mockUow := newService(ctrl)
+
+		tx := &repositories.Tx{}
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1"}, nil)
+		mockService.EXPECT().FindByID("s1").Return(visit, nil)
+		mockRepo.EXPECT().FindByPetID("pet-1").Return([]*entities.VaccinationModel{
+			{ID: "old-rabies", Vaccine: "rabies", GivenDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+			{ID: "old-dhpp", Vaccine: "DHPP", GivenDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		}, nil)
+		mockUow.EXPECT().Begin().Return(tx)
+		mockRepo.EXPECT().SupersedeTx(tx, []string{"old-rabies"})
+		var inserted entities.VaccinationModel
+		mockRepo.EXPECT().InsertTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, data entities.VaccinationModel) {
+			inserted = data
+		})
+		mockUow.EXPECT().Commit(tx).Return(nil)
+		mockRepo.EXPECT().FindByID(gomock.Any()).DoAndReturn(func(id string) (*entities.VaccinationModel, error) {
+			return &inserted, nil
+		})
+
+		got, err := sv.RecordVaccination("pet-1", "doc-1", "doctor", req)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if got.Superseded || got.DoctorID == nil || *got.DoctorID != "doc-1" || got.Kind != db.VaccinationKindVaccine {
+			t.Fatalf("unexpected dose %+v", got)
+		}
+		if got.NextDueDate == nil || !got.NextDueDate.Equal(time.Date(2027, 3, 2, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("unexpected next due %v", got.NextDueDate)
+		}
+	})
+
+	t.Run("back-filled older dose does not replace the current one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, mockRepo, mockPet, _, mockUow := newService(ctrl)
+
+		tx := &repositories.Tx{}
+		doctorID := "doc-2"
+		old := entities.CreateVaccinationRequest{Vaccine: "Rabies", GivenDate: "2024-03-01", DoctorID: &doctorID}
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1"}, nil)
+		mockRepo.EXPECT().FindByPetID("pet-1").Return([]*entities.VaccinationModel{
+			{ID: "current", Vaccine: "Rabies", GivenDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		}, nil)
+		mockUow.EXPECT().Begin().Return(tx)
+		mockRepo.EXPECT().SupersedeTx(tx, nil)
+		mockRepo.EXPECT().InsertTx(tx, gomock.Any()).Do(func(_ *repositories.Tx, data entities.VaccinationModel) {
+			if !data.Superseded || *data.DoctorID != "doc-2" || data.CreatedBy != "admin-1" {
+				t.Fatalf("unexpected dose %+v", data)
+			}
+		})
+		mockUow.EXPECT().Commit(tx).Return(nil)
+		mockRepo.EXPECT().FindByID(gomock.Any()).Return(&entities.VaccinationModel{}, nil)
+
+		if _, err := sv.RecordVaccination("pet-1", "admin-1", "admin", old); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("visit of another doctor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, _, mockPet, mockService, _ := newService(ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1"}, nil)
+		mockService.EXPECT().FindByID("s1").Return(visit, nil)
+
+		if _, err := sv.RecordVaccination("pet-1", "doc-2", "doctor", req); !errors.Is(err, ErrNotAssignedDoctor) {
+			t.Fatalf("expected ErrNotAssignedDoctor, got %v", err)
+		}
+	})
+
+	t.Run("next due not after given date", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		sv, _, mockPet, _, _ := newService(ctrl)
+
+		mockPet.EXPECT().FindPetByID("pet-1").Return(&entities.PetDataModel{PetID: "pet-1"}, nil)
+		sameDay := "2026-03-02"
+
+		if _, err := sv.RecordVaccination("pet-1", "doc-1", "doctor", entities.CreateVaccinationRequest{
+			Vaccine: "Rabies", GivenDate: "2026-03-02", NextDueDate: &sameDay,
+		}); !errors.Is(err, ErrInvalidVaccination) {
+			t.Fatalf("expected ErrInvalidVaccination, got %v", err)
+		}
+	})
+
+	t.Run("owners cannot record", func(t *testing.T) {
+		sv := &VaccinationService{}
+		if _, err := sv.RecordVaccination("pet-1", "owner-1", "owner", req); !errors.Is(err, ErrVaccinationForbidden) {
+			t.Fatalf("expected ErrVaccinationForbidden, got %v", err)
+		}
+	})
+}
+
+func TestVaccinationService_FindPetVaccinations(t *testing.T) {
+	pet := &entities.PetDataModel{PetID: "pet-1", OwnerID: "owner-1"}
+
+	tests := []struct {
+		name     string
+		userID   string
+		role     string
+		services []*entities.ServiceModel
+		wantErr  error
+	}{
+		{name: "owner of the pet", userID: "owner-1", role: "owner"},
+		{name: "another owner", userID: "owner-2", role: "owner", wantErr: ErrVaccinationForbidden},
+		{
+			name: "doctor who treated the pet", userID: "doc-1", role: "doctor",
+			services: []*entities.ServiceModel{{Sid: "s1", ServiceType: "mservice", StaffID: "doc-1", Status: db.ServiceStatusFinish}},
+		},
+		{
+			name: "doctor who never treated the pet", userID: "doc-2", role: "doctor", wantErr: ErrVaccinationForbidden,
+			services: []*entities.ServiceModel{{Sid: "s1", ServiceType: "mservice", StaffID: "doc-1", Status: db.ServiceStatusFinish}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockIVaccinationRepository(ctrl)
+			mockPet := mocks.NewMockIPetRepository(ctrl)
+			mockService := mocks.NewMockIServiceRepository(ctrl)
+			sv := &VaccinationService{Repo: mockRepo, PetRepo: mockPet, ServiceRepo: mockService}
+
+			mockPet.EXPECT().FindPetByID("pet-1").Return(pet, nil)
+			if tt.role == "doctor" {
+				mockService.EXPECT().FindByPetID("pet-1", timelineStatuses).Return(tt.services, nil)
+			}
+			if tt.wantErr == nil {
+				mockRepo.EXPECT().FindByPetID("pet-1").Return([]*entities.VaccinationModel{{ID: "v1"}}, nil)
+			}
+
+			_, err := sv.FindPetVaccinations("pet-1", tt.userID, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want %v got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVaccinationService_Reminders(t *testing.T) {
+	now := clockOn(scheduleMonday, 8, 0)
+	due := func(id string) *entities.VaccinationDueModel {
+		nextDue := scheduleMonday.AddDate(0, 0, 3)
+		return &entities.VaccinationDueModel{
+			VaccinationModel: entities.VaccinationModel{ID: id, Vaccine: "Rabies", NextDueDate: &nextDue},
+			OwnerEmail:       id + "@example.com",
+		}
+	}
+
+	t.Run("claims unreminded doses due within the window", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockIVaccinationRepository(ctrl)
+		sv := &VaccinationService{Repo: mockRepo, RemindDays: 7}
+
+		tx := &repositories.Tx{}
+		mockRepo.EXPECT().FindDue(time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), true).Return([]*entities.VaccinationDueModel{due("v1"), due("v2")}, nil)
+		mockRepo.EXPECT().ClaimRemindersTx(tx, []string{"v1", "v2"}, now)
+
+		got, err := sv.ClaimDueRemindersTx(tx, now)
+		if err != nil || len(got) != 2 {
+			t.Fatalf("unexpected result %v %v", got, err)
+		}
+	})
+
+	t.Run("nothing due leaves the tx empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockIVaccinationRepository(ctrl)
+		sv := &VaccinationService{Repo: mockRepo, RemindDays: 7}
+		mockRepo.EXPECT().FindDue(gomock.Any(), true).Return(nil, nil)
+
+		if got, err := sv.ClaimDueRemindersTx(&repositories.Tx{}, now); err != nil || got != nil {
+			t.Fatalf("unexpected result %v %v", got, err)
+		}
+	})
+
+	t.Run("failed email is released for the next round", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockIVaccinationRepository(ctrl)
+		var sent []string
+		sv := &VaccinationService{Repo: mockRepo, SendReminder: func(d *entities.VaccinationDueModel) error {
+			if d.ID == "v2" {
+				return errors.New("resend down")
+			}
+			sent = append(sent, d.OwnerEmail)
+			return nil
+		}}
+		mockRepo.EXPECT().ReleaseReminder("v2").Return(nil)
+
+		err := sv.SendReminders([]*entities.VaccinationDueModel{due("v1"), due("v2")})
+		if err == nil {
+			t.Fatalf("expected send error")
+		}
+		if len(sent) != 1 || sent[0] != "v1@example.com" {
+			t.Fatalf("unexpected sent %v", sent)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// httpClient sends requests to a remote backend with a timeout per attempt, retrying
+// network errors, 429 and 5xx with exponential backoff.
+type httpClient struct {
+	client  *http.Client
+	retries int
+	backoff time.Duration
+}
+
+func newHTTPClient(timeout time.Duration, retries int) *httpClient {
+	return &httpClient{
+		client:  &http.Client{Timeout: timeout},
+		retries: retries,
+		backoff: 200 * time.Millisecond,
+	}
+}
+
+// do builds a new request for every attempt so the body can be sent again. 404 comes back
+// as ErrObjectNotFound, other 4xx as an error with the response body.
+func (c *httpClient) do(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(c.backoff << (attempt - 1)):
+			}
+		}
+
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			lastErr = responseError(resp)
+		case resp.StatusCode == http.StatusNotFound:
+			resp.Body.Close()
+			return nil, ErrObjectNotFound
+		case resp.StatusCode >= 400:
+			return nil, responseError(resp)
+		default:
+			return resp, nil
+		}
+	}
+	return nil, lastErr
+}
+
+func responseError(resp *http.Response) error {
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
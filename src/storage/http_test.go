@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSupabaseStore_Retries(t *testing.T) {
+	newStore := func(handler http.HandlerFunc) *SupabaseStore {
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		client := newHTTPClient(time.Second, 2)
+		client.backoff = time.Millisecond
+		return NewSupabaseStore(server.URL, "service-key", "user-profile", client)
+	}
+
+	t.Run("upload is sent again after a 503", func(t *testing.T) {
+		var calls atomic.Int32
+		store := newStore(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != "picture" || r.Header.Get("Authorization") != "Bearer service-key" {
+				t.Errorf("unexpected request %q %v", body, r.Header)
+			}
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+
+		if err := store.Put(t.Context(), "u1/a.png", strings.NewReader("picture"), 7, "image/png"); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+		if calls.Load() != 2 {
+			t.Fatalf("expected 2 attempts, got %d", calls.Load())
+		}
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		var calls atomic.Int32
+		store := newStore(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		})
+
+		err := store.Put(t.Context(), "u1/a.png", strings.NewReader("picture"), 7, "image/png")
+		if err == nil || !strings.Contains(err.Error(), "401") || calls.Load() != 1 {
+			t.Fatalf("expected a single 401, got %v after %d calls", err, calls.Load())
+		}
+	})
+
+	t.Run("missing object", func(t *testing.T) {
+		store := newStore(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		if _, _, err := store.Get(t.Context(), "u1/a.png"); !errors.Is(err, ErrObjectNotFound) {
+			t.Fatalf("expected ErrObjectNotFound, got %v", err)
+		}
+		if err := store.Delete(t.Context(), "u1/a.png"); err != nil {
+			t.Fatalf("deleting a missing object should pass, got %v", err)
+		}
+	})
+}
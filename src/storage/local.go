@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStore keeps the files of a bucket on disk under <dir>/<bucket>. URLs point at
+// <baseURL>/<bucket> (the /files route of this server); signed ones carry an HMAC of the
+// key and expiry.
+type LocalStore struct {
+	Bucket  string
+	Root    string
+	BaseURL string
+	secret  []byte
+	now     func() time.Time
+}
+
+func NewLocalStore(dir, bucket, baseURL, secret string) *LocalStore {
+	return &LocalStore{
+		Bucket:  bucket,
+		Root:    filepath.Join(dir, bucket),
+		BaseURL: strings.TrimRight(baseURL, "/") + "/" + bucket,
+		secret:  []byte(secret),
+		now:     time.Now,
+	}
+}
+
+func (s *LocalStore) Put(_ context.Context, key string, body io.Reader, _ int64, _ string) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("storage -> Put: %v", err)
+	}
+
+	// เขียนลงไฟล์ชั่วคราวก่อน กันไฟล์ครึ่งๆ กลางๆ ถ้า upload ขาดกลางทาง
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("storage -> Put: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("storage -> Put: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("storage -> Put: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("storage -> Put: %v", err)
+	}
+
+	return nil
+}
+
+func (s *LocalStore) Get(_ context.Context, key string) (io.ReadCloser, *Object, error) {
+	file, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("storage -> Get: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("storage -> Get: %v", err)
+	}
+
+	return f, &Object{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  contentTypeByExt(key),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage -> Delete: %v", err)
+	}
+
+	return nil
+}
+
+func (s *LocalStore) List(_ context.Context, prefix string) ([]Object, error) {
+	objects := []Object{}
+	err := filepath.WalkDir(s.Root, func(file string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			return err
+		}
+		// ไฟล์ชั่วคราวระหว่าง Put ไม่นับ
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Root, file)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, Object{
+			Key:          key,
+			Size:         info.Size(),
+			ContentType:  contentTypeByExt(key),
+			LastModified: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage -> List: %v", err)
+	}
+
+	return objects, nil
+}
+
+func (s *LocalStore) SignedURL(_ context.Context, key string, expires time.Duration) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	expiresAt := strconv.FormatInt(s.now().Add(expires).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expiresAt)
+	query.Set("signature", s.sign(key, expiresAt))
+	return s.BaseURL + "/" + key + "?" + query.Encode(), nil
+}
+
+func (s *LocalStore) PublicURL(key string) string {
+	return s.BaseURL + "/" + key
+}
+
+// Verify checks the expiry and signature of a URL made by SignedURL.
+func (s *LocalStore) Verify(key, expires, signature string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.now().Unix() > expiresAt {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStore) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(s.Bucket + "/" + key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalStore_SignedURL(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "pet-files", "http://localhost:8080/api/v1/files/", "secret")
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	key := "pets/pet-1/a.pdf"
+	if err := store.Put(t.Context(), key, strings.NewReader("%PDF"), 4, "application/pdf"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	signed, err := store.SignedURL(t.Context(), key, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	u, _ := url.Parse(signed)
+	if u.Path != "/api/v1/files/pet-files/"+key {
+		t.Fatalf("unexpected url %s", signed)
+	}
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	t.Run("valid link reads the file", func(t *testing.T) {
+		if err := store.Verify(key, expires, signature); err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		body, object, err := store.Get(t.Context(), key)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		defer body.Close()
+		if data, _ := io.ReadAll(body); string(data) != "%PDF" || object.ContentType != "application/pdf" || object.Size != 4 {
+			t.Fatalf("unexpected object %q %+v", data, object)
+		}
+	})
+
+	t.Run("signature is bound to the key", func(t *testing.T) {
+		if err := store.Verify("pets/pet-2/a.pdf", expires, signature); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("expected ErrInvalidSignature, got %v", err)
+		}
+	})
+
+	t.Run("expired link", func(t *testing.T) {
+		store.now = func() time.Time { return now.Add(16 * time.Minute) }
+		defer func() { store.now = func() time.Time { return now } }()
+		if err := store.Verify(key, expires, signature); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("expected ErrInvalidSignature, got %v", err)
+		}
+	})
+
+	t.Run("keys cannot leave the root", func(t *testing.T) {
+		for _, bad := range []string{"../etc/passwd", "/etc/passwd", "pets/../../x", "pets//a", ""} {
+			if _, err := store.SignedURL(t.Context(), bad, time.Minute); !errors.Is(err, ErrInvalidKey) {
+				t.Fatalf("%q: expected ErrInvalidKey, got %v", bad, err)
+			}
+		}
+	})
+}
+
+func TestLocalStore_List(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "user-profile", "http://localhost:8080/api/v1/files", "secret")
+
+	if objects, err := store.List(t.Context(), "u1/"); err != nil || len(objects) != 0 {
+		t.Fatalf("empty bucket: %v %v", objects, err)
+	}
+	for _, key := range []string{"u1/a.png", "u1/b.jpg", "u2/c.png"} {
+		if err := store.Put(t.Context(), key, strings.NewReader("x"), 1, "image/png"); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+	}
+
+	objects, err := store.List(t.Context(), "u1/")
+	if err != nil || len(objects) != 2 || objects[0].Key != "u1/a.png" || objects[1].ContentType != "image/jpeg" {
+		t.Fatalf("unexpected objects %+v %v", objects, err)
+	}
+}
@@ -0,0 +1,344 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm      = "AWS4-HMAC-SHA256"
+	s3UnsignedBody   = "UNSIGNED-PAYLOAD"
+	s3MaxPresignTime = 7 * 24 * time.Hour
+)
+
+type S3Config struct {
+	// e.g. https://s3.ap-southeast-1.amazonaws.com, https://<account>.r2.cloudflarestorage.com
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	// MinIO และเจ้าอื่นที่ไม่มี bucket subdomain ต้องใช้ path style
+	PathStyle bool
+}
+
+// S3Store keeps files in an S3-compatible bucket, requests are signed with SigV4.
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	http     *httpClient
+	now      func() time.Time
+}
+
+func NewS3Store(config S3Config, client *httpClient) (*S3Store, error) {
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage -> NewS3Store: invalid endpoint %q", config.Endpoint)
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("storage -> NewS3Store: bucket and credentials are required")
+	}
+	return &S3Store{config: config, endpoint: endpoint, http: client, now: time.Now}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	nextBody, err := replayable(body)
+	if err != nil {
+		return fmt.Errorf("storage -> Put: %v", err)
+	}
+
+	resp, err := s.http.do(ctx, func() (*http.Request, error) {
+		body, err := nextBody()
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key, nil).String(), body)
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", contentType)
+		s.sign(req)
+		return req, nil
+	})
+	if err != nil {
+		return fmt.Errorf("storage -> Put: %w", err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.http.do(ctx, func() (*http.Request, error) {
+		return s.newSignedRequest(ctx, http.MethodGet, s.objectURL(key, nil))
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("storage -> Get: %w", err)
+	}
+
+	return resp.Body, objectFromHeader(key, resp), nil
+}
+
+// Delete succeeds when the key does not exist, S3 answers 204 either way.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.http.do(ctx, func() (*http.Request, error) {
+		return s.newSignedRequest(ctx, http.MethodDelete, s.objectURL(key, nil))
+	})
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("storage -> Delete: %w", err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *S3Store) List(ctx context.Context, prefix string) ([]Object, error) {
+	objects := []Object{}
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.http.do(ctx, func() (*http.Request, error) {
+			return s.newSignedRequest(ctx, http.MethodGet, s.bucketURL(query))
+		})
+		if err != nil {
+			return nil, fmt.Errorf("storage -> List: %w", err)
+		}
+		var result struct {
+			Contents []struct {
+				Key          string    `xml:"Key"`
+				Size         int64     `xml:"Size"`
+				LastModified time.Time `xml:"LastModified"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("storage -> List: %v", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, Object{
+				Key:          content.Key,
+				Size:         content.Size,
+				ContentType:  contentTypeByExt(content.Key),
+				LastModified: content.LastModified,
+			})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// SignedURL presigns a GET, S3 allows at most 7 days.
+func (s *S3Store) SignedURL(_ context.Context, key string, expires time.Duration) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if expires > s3MaxPresignTime {
+		expires = s3MaxPresignTime
+	}
+
+	now := s.now().UTC()
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.config.AccessKey+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	u := s.objectURL(key, nil)
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedBody,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(now, canonical))
+	u.RawQuery = canonicalQuery(query)
+
+	return u.String(), nil
+}
+
+// PublicURL only works when the bucket allows public reads.
+func (s *S3Store) PublicURL(key string) string {
+	return s.objectURL(key, nil).String()
+}
+
+func (s *S3Store) newSignedRequest(ctx context.Context, method string, u *url.URL) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req)
+	return req, nil
+}
+
+// sign adds a SigV4 Authorization header. The body is sent as UNSIGNED-PAYLOAD so uploads
+// do not need to be hashed first.
+func (s *S3Store) sign(req *http.Request) {
+	now := s.now().UTC()
+	req.Header.Set("x-amz-date", now.Format("20060102T150405Z"))
+	req.Header.Set("x-amz-content-sha256", s3UnsignedBody)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-date":           req.Header.Get("x-amz-date"),
+		"x-amz-content-sha256": s3UnsignedBody,
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headers["content-type"] = contentType
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		s3UnsignedBody,
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.config.AccessKey, s.scope(now), signedHeaders, s.signature(now, canonical)))
+}
+
+func (s *S3Store) signature(now time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format("20060102T150405Z"),
+		s.scope(now),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func (s *S3Store) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.config.Region + "/s3/aws4_request"
+}
+
+func (s *S3Store) bucketURL(query url.Values) *url.URL {
+	u := *s.endpoint
+	if s.config.PathStyle {
+		u.Path = "/" + s.config.Bucket + "/"
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = "/"
+	}
+	u.RawPath = ""
+	if query != nil {
+		u.RawQuery = canonicalQuery(query)
+	}
+	return &u
+}
+
+func (s *S3Store) objectURL(key string, query url.Values) *url.URL {
+	u := s.bucketURL(query)
+	u.Path += key
+	u.RawPath = u.Path[:len(u.Path)-len(key)] + s3EscapePath(key)
+	return u
+}
+
+// s3EscapePath encodes every byte but the unreserved ones, keeping the slashes.
+func s3EscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func s3Escape(value string) string {
+	var b strings.Builder
+	for _, c := range []byte(value) {
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrObjectNotFound   = errors.New("object not found")
+	ErrInvalidKey       = errors.New("invalid object key")
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+)
+
+// Object describes a stored file.
+type Object struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// ObjectStore keeps uploaded files in one bucket. Keys are slash separated paths such as
+// "pets/<petID>/<file>".
+type ObjectStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	Delete(ctx context.Context, key string) error
+	// List returns the objects whose key starts with prefix.
+	List(ctx context.Context, prefix string) ([]Object, error)
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// PublicStore is a store whose bucket can also be read without signing, like the profile
+// pictures that are linked straight from the user data.
+type PublicStore interface {
+	ObjectStore
+	PublicURL(key string) string
+}
+
+// NewFromEnv opens bucket on the backend chosen by STORAGE_DRIVER: "local" (default, for
+// dev), "supabase" or "s3" (any S3-compatible service). Env is read once here.
+func NewFromEnv(bucket string) (PublicStore, error) {
+	timeout := 30 * time.Second
+	if seconds, err := strconv.Atoi(os.Getenv("STORAGE_TIMEOUT_SECONDS")); err == nil && seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+	retries := 2
+	if n, err := strconv.Atoi(os.Getenv("STORAGE_RETRIES")); err == nil && n >= 0 {
+		retries = n
+	}
+
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		baseURL := os.Getenv("STORAGE_LOCAL_BASE_URL")
+		if baseURL == "" {
+			baseURL = "http://localhost:8080/api/v1/files"
+		}
+		secret := os.Getenv("STORAGE_SIGNING_KEY")
+		if secret == "" {
+			return nil, fmt.Errorf("storage -> NewFromEnv: STORAGE_SIGNING_KEY is required for the local driver")
+		}
+		return NewLocalStore(dir, bucket, baseURL, secret), nil
+	case "supabase":
+		return NewSupabaseStore(os.Getenv("SUPABASE_URL"), os.Getenv("SUPABASE_KEY"), bucket, newHTTPClient(timeout, retries)), nil
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			Bucket:    bucket,
+			PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+		}, newHTTPClient(timeout, retries))
+	default:
+		return nil, fmt.Errorf("storage -> NewFromEnv: unknown driver %q", driver)
+	}
+}
+
+// cleanKey rejects keys that would leave the bucket (absolute paths, "..").
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	cleaned := path.Clean(key)
+	if cleaned != key || cleaned == "." || strings.HasPrefix(cleaned, "../") || cleaned == ".." {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
+
+// replayable lets a retried upload send the body again: seekable bodies (uploaded form
+// files) are rewound, anything else is read into memory once.
+func replayable(body io.Reader) (func() (io.Reader, error), error) {
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		return func() (io.Reader, error) {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return seeker, nil
+		}, nil
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return func() (io.Reader, error) {
+		return bytes.NewReader(data), nil
+	}, nil
+}
+
+func contentTypeByExt(key string) string {
+	switch strings.ToLower(filepath.Ext(key)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".webp":
+		return "image/webp"
+	case ".pdf":
+		return "application/pdf"
+	default:
+		return "application/octet-stream"
+	}
+}
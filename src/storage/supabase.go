@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supabase list แบ่งหน้า สูงสุดครั้งละ 1000
+const supabaseListLimit = 1000
+
+// SupabaseStore keeps files in a Supabase Storage bucket.
+type SupabaseStore struct {
+	URL    string
+	Key    string // service_role key (NOT anon)
+	Bucket string
+	http   *httpClient
+}
+
+func NewSupabaseStore(url, key, bucket string, client *httpClient) *SupabaseStore {
+	return &SupabaseStore{
+		URL:    strings.TrimRight(url, "/"),
+		Key:    key,
+		Bucket: bucket,
+		http:   client,
+	}
+}
+
+func (s *SupabaseStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	nextBody, err := replayable(body)
+	if err != nil {
+		return fmt.Errorf("storage -> Put: %v", err)
+	}
+
+	resp, err := s.http.do(ctx, func() (*http.Request, error) {
+		body, err := nextBody()
+		if err != nil {
+			return nil, err
+		}
+		req, err := s.newRequest(ctx, http.MethodPost, s.objectURL(key), body)
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("x-upsert", "true")
+		return req, nil
+	})
+	if err != nil {
+		return fmt.Errorf("storage -> Put: %w", err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *SupabaseStore) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.http.do(ctx, func() (*http.Request, error) {
+		return s.newRequest(ctx, http.MethodGet, s.objectURL(key), nil)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("storage -> Get: %w", err)
+	}
+
+	return resp.Body, objectFromHeader(key, resp), nil
+}
+
+func (s *SupabaseStore) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.http.do(ctx, func() (*http.Request, error) {
+		return s.newRequest(ctx, http.MethodDelete, s.objectURL(key), nil)
+	})
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("storage -> Delete: %w", err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// List works on folders in Supabase, prefix is split into the folder and the start of
+// the names in it.
+func (s *SupabaseStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	folder, namePrefix := "", prefix
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		folder, namePrefix = prefix[:i], prefix[i+1:]
+	}
+
+	objects := []Object{}
+	for offset := 0; ; offset += supabaseListLimit {
+		body, err := json.Marshal(map[string]any{
+			"prefix": folder,
+			"search": namePrefix,
+			"limit":  supabaseListLimit,
+			"offset": offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("storage -> List: %v", err)
+		}
+		listURL := fmt.Sprintf("%s/storage/v1/object/list/%s", s.URL, s.Bucket)
+		resp, err := s.http.do(ctx, func() (*http.Request, error) {
+			req, err := s.newRequest(ctx, http.MethodPost, listURL, bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", "application/json")
+			return req, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("storage -> List: %w", err)
+		}
+
+		var files []struct {
+			ID        *string   `json:"id"`
+			Name      string    `json:"name"`
+			UpdatedAt time.Time `json:"updated_at"`
+			Metadata  *struct {
+				Size     int64  `json:"size"`
+				Mimetype string `json:"mimetype"`
+			} `json:"metadata"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&files)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("storage -> List: %v", err)
+		}
+
+		for _, f := range files {
+			// id เป็น null คือ folder ย่อย
+			if f.ID == nil || !strings.HasPrefix(f.Name, namePrefix) {
+				continue
+			}
+			object := Object{Key: path.Join(folder, f.Name), LastModified: f.UpdatedAt}
+			if f.Metadata != nil {
+				object.Size = f.Metadata.Size
+				object.ContentType = f.Metadata.Mimetype
+			}
+			objects = append(objects, object)
+		}
+		if len(files) < supabaseListLimit {
+			return objects, nil
+		}
+	}
+}
+
+func (s *SupabaseStore) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(map[string]int{"expiresIn": int(expires.Seconds())})
+	if err != nil {
+		return "", fmt.Errorf("storage -> SignedURL: %v", err)
+	}
+	signURL := fmt.Sprintf("%s/storage/v1/object/sign/%s/%s", s.URL, s.Bucket, key)
+
+	resp, err := s.http.do(ctx, func() (*http.Request, error) {
+		req, err := s.newRequest(ctx, http.MethodPost, signURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("storage -> SignedURL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var signed struct {
+		SignedURL string `json:"signedURL"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&signed); err != nil {
+		return "", fmt.Errorf("storage -> SignedURL: %v", err)
+	}
+	// signedURL ที่ได้กลับมาเป็น path ต่อจาก /storage/v1
+	return s.URL + "/storage/v1" + signed.SignedURL, nil
+}
+
+// PublicURL only works when the bucket is set to public in Supabase.
+func (s *SupabaseStore) PublicURL(key string) string {
+	return fmt.Sprintf("%s/storage/v1/object/public/%s/%s", s.URL, s.Bucket, key)
+}
+
+func (s *SupabaseStore) objectURL(key string) string {
+	return fmt.Sprintf("%s/storage/v1/object/%s/%s", s.URL, s.Bucket, key)
+}
+
+func (s *SupabaseStore) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.Key)
+	return req, nil
+}
+
+func objectFromHeader(key string, resp *http.Response) *Object {
+	object := &Object{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		object.Size = size
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.LastModified = modified
+	}
+	return object
+}
@@ -8,13 +8,17 @@ func CheckSameDate(LHSDate, RHSDate time.Time) bool {
 		LHSDate.Day() == RHSDate.Day()
 }
 
+// BusinessLocation is the time zone the clinic works in. Days, working hours and
+// pricing rules are always in Thai time, whatever the server or the client uses.
+var BusinessLocation = time.FixedZone("ICT", 7*60*60)
+
 func GetRDateRange(startDateStr, endDateStr string) (time.Time, time.Time, error) {
 	layout := "2006-01-02"
-	startDate, err := time.Parse(layout, startDateStr)
+	startDate, err := time.ParseInLocation(layout, startDateStr, BusinessLocation)
 	if err != nil {
 		return time.Time{}, time.Time{}, err
 	}
-	endDate, err := time.Parse(layout, endDateStr)
+	endDate, err := time.ParseInLocation(layout, endDateStr, BusinessLocation)
 	if err != nil {
 		return time.Time{}, time.Time{}, err
 	}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"time"
+
+	"github.com/resend/resend-go/v2"
+)
+
+// SendVaccinationReminderEmail tells the owner that a vaccine or preventive care of their
+// pet is coming due (or overdue).
+func SendVaccinationReminderEmail(toEmail, ownerName, petName, vaccine string, dueDate time.Time) error {
+	client := resend.NewClient(os.Getenv("RESEND_API_KEY"))
+
+	if petName == "" {
+		petName = "your pet"
+	}
+	params := &resend.SendEmailRequest{
+		From:    "LAMA Support <onboarding@resend.dev>",
+		To:      []string{toEmail},
+		Subject: fmt.Sprintf("%s is due for %s", petName, vaccine),
+		Html: fmt.Sprintf(`
+			<p>Hi %s,</p>
+			<p><b>%s</b> for <b>%s</b> is due on %s.</p>
+			<p>Book a visit with one of our doctors to keep it up to date.</p>`,
+			html.EscapeString(ownerName), html.EscapeString(vaccine), html.EscapeString(petName), dueDate.Format("2 Jan 2006")),
+	}
+
+	_, err := client.Emails.Send(params)
+	return err
+}